@@ -14,6 +14,7 @@ import (
 	"github.com/ilramdhan/pos-api/internal/config"
 	"github.com/ilramdhan/pos-api/internal/database"
 	"github.com/ilramdhan/pos-api/internal/router"
+	"github.com/ilramdhan/pos-api/internal/storage"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -53,21 +54,27 @@ func main() {
 	// Run seed if forced or if database is empty
 	if *forceSeed {
 		log.Println("Force seeding enabled, clearing and re-seeding database...")
-		clearDatabase(db.DB)
-		runSeed(db.DB)
+		clearDatabase(db.System)
+		runSeed(db.System)
 
 		// If seed-only, exit without starting server
 		if *seedOnly {
 			log.Println("Seed-only mode: exiting without starting server")
 			return
 		}
-	} else if shouldAutoSeed(db.DB) {
+	} else if shouldAutoSeed(db.System) {
 		log.Println("Database is empty, running auto-seed...")
-		runSeed(db.DB)
+		runSeed(db.System)
+	}
+
+	// Blob storage for uploaded files
+	store, err := storage.New(&cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
 	}
 
 	// Setup router
-	r := router.New(cfg, db)
+	r := router.New(cfg, db, store)
 
 	// Start server in goroutine
 	go func() {
@@ -0,0 +1,69 @@
+// Command license generates license signing keys and issues signed license keys.
+//
+//	go run ./cmd/license -keygen
+//	LICENSE_PRIVATE_KEY=... go run ./cmd/license -licensee "Kopi Kita" -plan pro -seats 10 -stores 1 -days 365
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+func main() {
+	keygen := flag.Bool("keygen", false, "Generate a new signing key pair")
+	licensee := flag.String("licensee", "", "Business the license is issued to")
+	plan := flag.String("plan", models.PlanPro, "Plan: free, pro or enterprise")
+	seats := flag.Int("seats", 5, "Active users allowed (0 = unlimited)")
+	stores := flag.Int("stores", 1, "Active stores allowed (0 = unlimited)")
+	days := flag.Int("days", 365, "Days until the license expires (0 = never)")
+	flag.Parse()
+
+	if *keygen {
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			log.Fatalf("Failed to generate key pair: %v", err)
+		}
+		fmt.Printf("LICENSE_PUBLIC_KEY=%s\n", base64.StdEncoding.EncodeToString(publicKey))
+		fmt.Printf("LICENSE_PRIVATE_KEY=%s\n", base64.StdEncoding.EncodeToString(privateKey))
+		return
+	}
+
+	encoded := os.Getenv("LICENSE_PRIVATE_KEY")
+	privateKey, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(privateKey) != ed25519.PrivateKeySize {
+		log.Fatal("LICENSE_PRIVATE_KEY must be set to a base64-encoded Ed25519 private key (see -keygen)")
+	}
+	if *licensee == "" {
+		log.Fatal("-licensee is required")
+	}
+	if _, ok := models.PlanFeatures[*plan]; !ok {
+		log.Fatalf("Unknown plan %q", *plan)
+	}
+
+	now := time.Now().UTC()
+	license := &utils.License{
+		Licensee: *licensee,
+		Plan:     *plan,
+		Seats:    *seats,
+		Stores:   *stores,
+		IssuedAt: now,
+	}
+	if *days > 0 {
+		license.ExpiresAt = now.AddDate(0, 0, *days)
+	}
+
+	key, err := utils.SignLicense(ed25519.PrivateKey(privateKey), license)
+	if err != nil {
+		log.Fatalf("Failed to sign license: %v", err)
+	}
+	fmt.Println(key)
+}
@@ -14,6 +14,13 @@ type Config struct {
 	Database  DatabaseConfig
 	RateLimit RateLimitConfig
 	CORS      CORSConfig
+	Loyalty   LoyaltyConfig
+	Trash     TrashConfig
+	Storage   StorageConfig
+	License   LicenseConfig
+	Terminal  TerminalConfig
+	MFA       MFAConfig
+	Login     LoginConfig
 }
 
 // AppConfig holds application-level configuration
@@ -22,6 +29,9 @@ type AppConfig struct {
 	Port    string
 	Name    string
 	Version string
+	// TrustedProxies are the proxies (IPs or CIDRs) whose X-Forwarded-For header gives the client's
+	// IP address; with none, the connection's address is used
+	TrustedProxies []string
 }
 
 // JWTConfig holds JWT authentication configuration
@@ -33,13 +43,17 @@ type JWTConfig struct {
 
 // DatabaseConfig holds PostgreSQL/Supabase database configuration
 type DatabaseConfig struct {
-	ConnectionString string
+	ConnectionString       string
+	RowLevelSecurity       bool   // Enforce tenant isolation with Postgres row-level security as well
+	SystemConnectionString string // Role with BYPASSRLS for work across tenants; required with row-level security
 }
 
 // RateLimitConfig holds rate limiter configuration
 type RateLimitConfig struct {
 	RPS   int
 	Burst int
+	// OverridesPerMinute limits how many manager approvals each user may request a minute
+	OverridesPerMinute int
 }
 
 // CORSConfig holds CORS configuration
@@ -47,6 +61,69 @@ type CORSConfig struct {
 	AllowedOrigins []string
 }
 
+// LoyaltyConfig holds loyalty program earn and redeem rules
+type LoyaltyConfig struct {
+	EarnAmount       float64 // Amount spent per earn unit
+	EarnPoints       int     // Points awarded per earn unit
+	PointValue       float64 // Currency value of one point when redeemed
+	ExpiryDays       int     // Days until earned points expire (0 = never)
+	SilverThreshold  float64 // Lifetime spend required for silver tier
+	SilverMultiplier float64
+	GoldThreshold    float64 // Lifetime spend required for gold tier
+	GoldMultiplier   float64
+}
+
+// TrashConfig holds soft-delete retention configuration
+type TrashConfig struct {
+	RetentionDays int // Days a deleted record stays restorable before it is purged (0 = keep forever)
+}
+
+// StorageConfig holds blob storage configuration for uploaded files such as product images
+type StorageConfig struct {
+	Driver       string // local or s3
+	LocalDir     string // Root directory of the local driver
+	PublicURL    string // URL prefix under which stored objects are served
+	MaxImageSize int64  // Maximum accepted image upload in bytes
+	S3Endpoint   string // Empty for AWS; e.g. http://localhost:9000 for MinIO
+	S3Region     string
+	S3Bucket     string
+	S3AccessKey  string
+	S3SecretKey  string
+	S3PathStyle  bool // Address the bucket in the path instead of the host name (required by MinIO)
+}
+
+// LicenseConfig holds license verification configuration
+type LicenseConfig struct {
+	PublicKey string // Base64 Ed25519 key that license keys are verified with (empty = licensing not enforced)
+}
+
+// TerminalConfig holds PIN sign-in rules and heartbeat timing for registered terminals
+type TerminalConfig struct {
+	SessionMinutes      int // Lifetime of a PIN session's token; PIN sessions cannot be refreshed
+	PINMaxAttempts      int // Wrong PINs in a row before the user's PIN sign-in is locked
+	TerminalMaxAttempts int // Wrong PINs in a row before the terminal's PIN sign-in is locked
+	LockoutMinutes      int // How long a locked user or terminal stays locked
+	HeartbeatSeconds    int // How often terminals are asked to send a heartbeat
+	OfflineSeconds      int // Time without a heartbeat after which a terminal counts as offline
+}
+
+// MFAConfig holds two-factor authentication settings
+type MFAConfig struct {
+	Issuer           string // Name authenticator apps show for the account; defaults to the app name
+	ChallengeMinutes int    // How long a sign-in waits for its second factor
+	MaxAttempts      int    // Wrong codes in a row before the user's second factor is locked
+	LockoutMinutes   int    // How long a locked second factor stays locked
+}
+
+// LoginConfig holds brute-force protection for password sign-in
+type LoginConfig struct {
+	MaxAttempts    int // Wrong passwords in a row before the account is locked
+	IPMaxAttempts  int // Failed sign-ins from one IP address before it is locked
+	LockoutMinutes int // How long a locked account or IP address stays locked
+	DelayAfter     int // Wrong passwords in a row before each further attempt is delayed
+	DelaySeconds   int // First delay; it doubles with every further wrong password
+}
+
 // Load loads configuration from environment variables using Viper
 func Load() *Config {
 	// Set up Viper
@@ -65,12 +142,18 @@ func Load() *Config {
 	// Get database connection string with fallback support for Zeabur
 	dbConn := getDBConnectionString()
 
+	mfaIssuer := viper.GetString("MFA_ISSUER")
+	if mfaIssuer == "" {
+		mfaIssuer = viper.GetString("APP_NAME")
+	}
+
 	return &Config{
 		App: AppConfig{
-			Env:     viper.GetString("APP_ENV"),
-			Port:    getPort(),
-			Name:    viper.GetString("APP_NAME"),
-			Version: viper.GetString("APP_VERSION"),
+			Env:            viper.GetString("APP_ENV"),
+			Port:           getPort(),
+			Name:           viper.GetString("APP_NAME"),
+			Version:        viper.GetString("APP_VERSION"),
+			TrustedProxies: parseList(viper.GetString("TRUSTED_PROXIES")),
 		},
 		JWT: JWTConfig{
 			Secret:             viper.GetString("JWT_SECRET"),
@@ -78,15 +161,67 @@ func Load() *Config {
 			RefreshExpiryHours: viper.GetInt("JWT_REFRESH_EXPIRY_HOURS"),
 		},
 		Database: DatabaseConfig{
-			ConnectionString: dbConn,
+			ConnectionString:       dbConn,
+			RowLevelSecurity:       viper.GetBool("DB_ROW_LEVEL_SECURITY"),
+			SystemConnectionString: viper.GetString("DB_SYSTEM_CONN"),
 		},
 		RateLimit: RateLimitConfig{
-			RPS:   viper.GetInt("RATE_LIMIT_RPS"),
-			Burst: viper.GetInt("RATE_LIMIT_BURST"),
+			RPS:                viper.GetInt("RATE_LIMIT_RPS"),
+			Burst:              viper.GetInt("RATE_LIMIT_BURST"),
+			OverridesPerMinute: viper.GetInt("RATE_LIMIT_OVERRIDES_PER_MINUTE"),
 		},
 		CORS: CORSConfig{
 			AllowedOrigins: parseOrigins(viper.GetString("CORS_ALLOWED_ORIGINS")),
 		},
+		Loyalty: LoyaltyConfig{
+			EarnAmount:       viper.GetFloat64("LOYALTY_EARN_AMOUNT"),
+			EarnPoints:       viper.GetInt("LOYALTY_EARN_POINTS"),
+			PointValue:       viper.GetFloat64("LOYALTY_POINT_VALUE"),
+			ExpiryDays:       viper.GetInt("LOYALTY_EXPIRY_DAYS"),
+			SilverThreshold:  viper.GetFloat64("LOYALTY_SILVER_THRESHOLD"),
+			SilverMultiplier: viper.GetFloat64("LOYALTY_SILVER_MULTIPLIER"),
+			GoldThreshold:    viper.GetFloat64("LOYALTY_GOLD_THRESHOLD"),
+			GoldMultiplier:   viper.GetFloat64("LOYALTY_GOLD_MULTIPLIER"),
+		},
+		Trash: TrashConfig{
+			RetentionDays: viper.GetInt("TRASH_RETENTION_DAYS"),
+		},
+		Storage: StorageConfig{
+			Driver:       viper.GetString("STORAGE_DRIVER"),
+			LocalDir:     viper.GetString("STORAGE_LOCAL_DIR"),
+			PublicURL:    strings.TrimSuffix(viper.GetString("STORAGE_PUBLIC_URL"), "/"),
+			MaxImageSize: viper.GetInt64("STORAGE_MAX_IMAGE_MB") << 20,
+			S3Endpoint:   viper.GetString("S3_ENDPOINT"),
+			S3Region:     viper.GetString("S3_REGION"),
+			S3Bucket:     viper.GetString("S3_BUCKET"),
+			S3AccessKey:  viper.GetString("S3_ACCESS_KEY"),
+			S3SecretKey:  viper.GetString("S3_SECRET_KEY"),
+			S3PathStyle:  viper.GetBool("S3_PATH_STYLE"),
+		},
+		License: LicenseConfig{
+			PublicKey: viper.GetString("LICENSE_PUBLIC_KEY"),
+		},
+		Terminal: TerminalConfig{
+			SessionMinutes:      viper.GetInt("TERMINAL_SESSION_MINUTES"),
+			PINMaxAttempts:      viper.GetInt("PIN_MAX_ATTEMPTS"),
+			TerminalMaxAttempts: viper.GetInt("TERMINAL_MAX_PIN_ATTEMPTS"),
+			LockoutMinutes:      viper.GetInt("PIN_LOCKOUT_MINUTES"),
+			HeartbeatSeconds:    viper.GetInt("TERMINAL_HEARTBEAT_SECONDS"),
+			OfflineSeconds:      viper.GetInt("TERMINAL_OFFLINE_SECONDS"),
+		},
+		MFA: MFAConfig{
+			Issuer:           mfaIssuer,
+			ChallengeMinutes: viper.GetInt("MFA_CHALLENGE_MINUTES"),
+			MaxAttempts:      viper.GetInt("MFA_MAX_ATTEMPTS"),
+			LockoutMinutes:   viper.GetInt("MFA_LOCKOUT_MINUTES"),
+		},
+		Login: LoginConfig{
+			MaxAttempts:    viper.GetInt("LOGIN_MAX_ATTEMPTS"),
+			IPMaxAttempts:  viper.GetInt("LOGIN_IP_MAX_ATTEMPTS"),
+			LockoutMinutes: viper.GetInt("LOGIN_LOCKOUT_MINUTES"),
+			DelayAfter:     viper.GetInt("LOGIN_DELAY_AFTER"),
+			DelaySeconds:   viper.GetInt("LOGIN_DELAY_SECONDS"),
+		},
 	}
 }
 
@@ -164,9 +299,54 @@ func setDefaults() {
 	// Rate limiter defaults
 	viper.SetDefault("RATE_LIMIT_RPS", 100)
 	viper.SetDefault("RATE_LIMIT_BURST", 200)
+	viper.SetDefault("RATE_LIMIT_OVERRIDES_PER_MINUTE", 10)
 
 	// CORS defaults
 	viper.SetDefault("CORS_ALLOWED_ORIGINS", "http://localhost:3000,http://localhost:5173")
+
+	// Loyalty program defaults (1 point per 10,000 spent, 1 point = 100 when redeemed)
+	viper.SetDefault("LOYALTY_EARN_AMOUNT", 10000)
+	viper.SetDefault("LOYALTY_EARN_POINTS", 1)
+	viper.SetDefault("LOYALTY_POINT_VALUE", 100)
+	viper.SetDefault("LOYALTY_EXPIRY_DAYS", 365)
+	viper.SetDefault("LOYALTY_SILVER_THRESHOLD", 1000000)
+	viper.SetDefault("LOYALTY_SILVER_MULTIPLIER", 1.25)
+	viper.SetDefault("LOYALTY_GOLD_THRESHOLD", 5000000)
+	viper.SetDefault("LOYALTY_GOLD_MULTIPLIER", 1.5)
+
+	// Soft-deleted records are purged after 30 days
+	viper.SetDefault("TRASH_RETENTION_DAYS", 30)
+
+	// Uploaded files are kept on local disk and served by the API under /media
+	viper.SetDefault("STORAGE_DRIVER", "local")
+	viper.SetDefault("STORAGE_LOCAL_DIR", "./uploads")
+	viper.SetDefault("STORAGE_PUBLIC_URL", "/media")
+	viper.SetDefault("STORAGE_MAX_IMAGE_MB", 5)
+	viper.SetDefault("S3_REGION", "us-east-1")
+	viper.SetDefault("S3_PATH_STYLE", true)
+
+	// PIN sessions last 15 minutes; 5 wrong PINs lock a user and 20 a terminal for 15 minutes
+	viper.SetDefault("TERMINAL_SESSION_MINUTES", 15)
+	viper.SetDefault("PIN_MAX_ATTEMPTS", 5)
+	viper.SetDefault("TERMINAL_MAX_PIN_ATTEMPTS", 20)
+	viper.SetDefault("PIN_LOCKOUT_MINUTES", 15)
+
+	// Terminals send a heartbeat every 30 seconds and are offline after missing about three
+	viper.SetDefault("TERMINAL_HEARTBEAT_SECONDS", 30)
+	viper.SetDefault("TERMINAL_OFFLINE_SECONDS", 90)
+
+	// Sign-ins wait 5 minutes for their second factor; 5 wrong codes lock it for 15 minutes
+	viper.SetDefault("MFA_CHALLENGE_MINUTES", 5)
+	viper.SetDefault("MFA_MAX_ATTEMPTS", 5)
+	viper.SetDefault("MFA_LOCKOUT_MINUTES", 15)
+
+	// From the 3rd wrong password each attempt waits 1s, doubling; the 10th locks the account
+	// and 50 failures lock an IP address, both for 15 minutes
+	viper.SetDefault("LOGIN_MAX_ATTEMPTS", 10)
+	viper.SetDefault("LOGIN_IP_MAX_ATTEMPTS", 50)
+	viper.SetDefault("LOGIN_LOCKOUT_MINUTES", 15)
+	viper.SetDefault("LOGIN_DELAY_AFTER", 3)
+	viper.SetDefault("LOGIN_DELAY_SECONDS", 1)
 }
 
 // parseOrigins parses comma-separated origins string into slice
@@ -174,12 +354,17 @@ func parseOrigins(origins string) []string {
 	if origins == "" {
 		return []string{"http://localhost:3000"}
 	}
-	parts := strings.Split(origins, ",")
+	return parseList(origins)
+}
+
+// parseList splits a comma-separated list, dropping empty entries
+func parseList(list string) []string {
+	parts := strings.Split(list, ",")
 	result := make([]string, 0, len(parts))
-	for _, origin := range parts {
-		origin = strings.TrimSpace(origin)
-		if origin != "" {
-			result = append(result, origin)
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			result = append(result, part)
 		}
 	}
 	return result
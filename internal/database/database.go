@@ -11,12 +11,19 @@ import (
 	"time"
 
 	"github.com/ilramdhan/pos-api/internal/config"
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/ilramdhan/pos-api/internal/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
 // Database wraps the sql.DB connection for PostgreSQL (Supabase)
 type Database struct {
 	*sql.DB
+	// System is the pool for work that spans tenants: migrations, seeding, sign-in lookups and
+	// background jobs. With row-level security it connects as a role that bypasses the
+	// policies; otherwise it is the same pool as DB.
+	System           *sql.DB
+	rowLevelSecurity bool
 }
 
 // New creates a new PostgreSQL/Supabase database connection
@@ -24,17 +31,53 @@ func New(cfg *config.DatabaseConfig) (*Database, error) {
 	if cfg.ConnectionString == "" {
 		return nil, fmt.Errorf("DB_CONN is required for PostgreSQL/Supabase connection")
 	}
+	if cfg.RowLevelSecurity && cfg.SystemConnectionString == "" {
+		return nil, fmt.Errorf("DB_SYSTEM_CONN is required when DB_ROW_LEVEL_SECURITY is enabled")
+	}
 
 	log.Println("Connecting to PostgreSQL/Supabase database using pgx driver...")
 	// Log connection string with password redacted for debugging
 	redactedConn := redactPassword(cfg.ConnectionString)
 	log.Printf("Connection string: %s", redactedConn)
 
+	var opts []stdlib.OptionOpenDB
+	if cfg.RowLevelSecurity {
+		// Row-level security policies read the tenant from the session, so set it from the
+		// request context whenever a connection is handed out
+		log.Println("Row-level security enabled for tenant isolation")
+		opts = append(opts, stdlib.OptionAfterConnect(setSessionTenant), stdlib.OptionResetSession(setSessionTenant))
+	}
+	db, err := open(cfg.ConnectionString, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	system := db
+	if cfg.RowLevelSecurity {
+		log.Printf("System connection string: %s", redactPassword(cfg.SystemConnectionString))
+		if system, err = open(cfg.SystemConnectionString); err != nil {
+			db.Close()
+			return nil, err
+		}
+		if err := checkBypassesRLS(system); err != nil {
+			db.Close()
+			system.Close()
+			return nil, err
+		}
+	}
+
+	log.Println("✓ PostgreSQL/Supabase database connected successfully")
+	return &Database{DB: db, System: system, rowLevelSecurity: cfg.RowLevelSecurity}, nil
+}
+
+// open opens and pings a connection pool
+func open(connString string, opts ...stdlib.OptionOpenDB) (*sql.DB, error) {
 	// Use "pgx" driver instead of "postgres" (lib/pq)
-	db, err := sql.Open("pgx", cfg.ConnectionString)
+	connConfig, err := pgx.ParseConfig(connString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open PostgreSQL database: %w", err)
 	}
+	db := stdlib.OpenDB(*connConfig, opts...)
 
 	// PostgreSQL connection pool settings optimized for Supabase
 	db.SetMaxOpenConns(25)
@@ -47,11 +90,32 @@ func New(cfg *config.DatabaseConfig) (*Database, error) {
 	defer cancel()
 
 	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
+	return db, nil
+}
+
+// checkBypassesRLS makes sure the system pool's role is not restricted by the row-level
+// security policies, which would hide every row from it
+func checkBypassesRLS(db *sql.DB) error {
+	var bypass bool
+	err := db.QueryRow(`SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user`).Scan(&bypass)
+	if err != nil {
+		return fmt.Errorf("failed to check the DB_SYSTEM_CONN role: %w", err)
+	}
+	if !bypass {
+		return fmt.Errorf("the DB_SYSTEM_CONN role must have BYPASSRLS")
+	}
+	return nil
+}
 
-	log.Println("✓ PostgreSQL/Supabase database connected successfully")
-	return &Database{DB: db}, nil
+// setSessionTenant sets the app.tenant_id session variable read by the row-level security
+// policies to the tenant of ctx. Connections used without a tenant get an empty value, which
+// matches no rows; work that spans tenants goes through the system pool instead.
+func setSessionTenant(ctx context.Context, conn *pgx.Conn) error {
+	_, err := conn.Exec(ctx, "SELECT set_config('app.tenant_id', $1, false)", utils.TenantFromContext(ctx))
+	return err
 }
 
 func redactPassword(connStr string) string {
@@ -65,26 +129,35 @@ func redactPassword(connStr string) string {
 	return u.String()
 }
 
-// Migrate runs database migrations from PostgreSQL migration file
+// Migrate runs database migrations from PostgreSQL migration file, and the row-level
+// security policies when they are enabled
 func (d *Database) Migrate(migrationsPath string) error {
-	migrationFile := filepath.Join(migrationsPath, "001_init_postgres.sql")
-
-	content, err := os.ReadFile(migrationFile)
-	if err != nil {
-		return fmt.Errorf("failed to read migration file: %w", err)
+	migrationFiles := []string{"001_init_postgres.sql"}
+	if d.rowLevelSecurity {
+		migrationFiles = append(migrationFiles, "002_row_level_security.sql")
 	}
 
-	// Execute migration
-	if _, err := d.Exec(string(content)); err != nil {
-		return fmt.Errorf("failed to execute migration: %w", err)
+	for _, name := range migrationFiles {
+		content, err := os.ReadFile(filepath.Join(migrationsPath, name))
+		if err != nil {
+			return fmt.Errorf("failed to read migration file: %w", err)
+		}
+
+		// Execute migration; backfills must see every tenant's rows
+		if _, err := d.System.Exec(string(content)); err != nil {
+			return fmt.Errorf("failed to execute migration %s: %w", name, err)
+		}
 	}
 
 	log.Println("✓ Database migrations applied successfully")
 	return nil
 }
 
-// Close closes the database connection
+// Close closes the database connections
 func (d *Database) Close() error {
+	if d.System != d.DB {
+		d.System.Close()
+	}
 	return d.DB.Close()
 }
 
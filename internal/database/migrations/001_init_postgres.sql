@@ -1,26 +1,72 @@
 -- PostgreSQL/Supabase Migration
 -- GoPOS API Database Schema v2.0
 
+-- Trigram matching for typo-tolerant product search
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Tenants (businesses); every record belongs to one tenant and is only visible to it
+CREATE TABLE IF NOT EXISTS tenants (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    license_key TEXT DEFAULT '',
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Records created before multi-tenancy belong to the default tenant
+INSERT INTO tenants (id, name) VALUES ('default', 'Default') ON CONFLICT (id) DO NOTHING;
+
 -- Users table
 CREATE TABLE IF NOT EXISTS users (
     id TEXT PRIMARY KEY,
-    email TEXT NOT NULL UNIQUE,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    email TEXT NOT NULL,
     password_hash TEXT NOT NULL,
+    pin_hash TEXT DEFAULT '',
+    totp_secret TEXT DEFAULT '',
+    totp_enabled BOOLEAN DEFAULT FALSE,
+    totp_last_step BIGINT DEFAULT 0,
     name TEXT NOT NULL,
     phone TEXT DEFAULT '',
-    role TEXT NOT NULL DEFAULT 'cashier' CHECK (role IN ('admin', 'manager', 'cashier')),
+    role TEXT NOT NULL DEFAULT 'cashier',
     is_active BOOLEAN DEFAULT TRUE,
+    deleted_at TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Stores (outlets); stock, prices, sales and staff can be kept per store
+CREATE TABLE IF NOT EXISTS stores (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    code TEXT NOT NULL,
+    name TEXT NOT NULL,
+    address TEXT DEFAULT '',
+    phone TEXT DEFAULT '',
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Stores a user works at; users without a store are not limited to one
+CREATE TABLE IF NOT EXISTS user_stores (
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    store_id TEXT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, store_id)
+);
+
 -- Categories table
 CREATE TABLE IF NOT EXISTS categories (
     id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
     name TEXT NOT NULL,
     description TEXT DEFAULT '',
-    slug TEXT NOT NULL UNIQUE,
+    slug TEXT NOT NULL,
     is_active BOOLEAN DEFAULT TRUE,
+    deleted_at TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -28,26 +74,44 @@ CREATE TABLE IF NOT EXISTS categories (
 -- Products table
 CREATE TABLE IF NOT EXISTS products (
     id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
     category_id TEXT NOT NULL REFERENCES categories(id) ON DELETE RESTRICT,
-    sku TEXT NOT NULL UNIQUE,
+    sku TEXT NOT NULL,
     name TEXT NOT NULL,
     description TEXT DEFAULT '',
     price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    cost_price DECIMAL(12, 2) NOT NULL DEFAULT 0,
     stock INTEGER NOT NULL DEFAULT 0,
     image_url TEXT DEFAULT '',
+    thumbnail_url TEXT DEFAULT '',
     is_active BOOLEAN DEFAULT TRUE,
+    deleted_at TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- Full-text document: SKU and name (weight A) and description (weight C), stemmed
+    -- for Indonesian and English plus unstemmed for prefix matching
+    search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', COALESCE(sku, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(name, '')), 'A') ||
+        setweight(to_tsvector('indonesian', COALESCE(name, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+        setweight(to_tsvector('indonesian', COALESCE(description, '')), 'C') ||
+        setweight(to_tsvector('english', COALESCE(description, '')), 'C')
+    ) STORED
 );
 
 -- Customers table
 CREATE TABLE IF NOT EXISTS customers (
     id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
     name TEXT NOT NULL,
     email TEXT DEFAULT '',
     phone TEXT DEFAULT '',
     address TEXT DEFAULT '',
     loyalty_points INTEGER DEFAULT 0,
+    phone_normalized TEXT DEFAULT '',
+    email_normalized TEXT DEFAULT '',
+    deleted_at TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -55,6 +119,7 @@ CREATE TABLE IF NOT EXISTS customers (
 -- Transactions table
 CREATE TABLE IF NOT EXISTS transactions (
     id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
     user_id TEXT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
     customer_id TEXT REFERENCES customers(id) ON DELETE SET NULL,
     invoice_number TEXT NOT NULL UNIQUE,
@@ -65,16 +130,34 @@ CREATE TABLE IF NOT EXISTS transactions (
     payment_method TEXT NOT NULL CHECK (payment_method IN ('cash', 'card', 'qris', 'transfer')),
     status TEXT NOT NULL DEFAULT 'completed' CHECK (status IN ('pending', 'completed', 'cancelled', 'refunded')),
     notes TEXT DEFAULT '',
+    voucher_code TEXT DEFAULT '',
+    points_earned INTEGER DEFAULT 0,
+    points_redeemed INTEGER DEFAULT 0,
+    store_id TEXT REFERENCES stores(id) ON DELETE RESTRICT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Store stock levels and price overrides; a NULL price sells at the product's price
+CREATE TABLE IF NOT EXISTS store_products (
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    store_id TEXT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    stock INTEGER NOT NULL DEFAULT 0,
+    price DECIMAL(10, 2) CHECK (price >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (store_id, product_id)
+);
+
 -- Transaction items table
 CREATE TABLE IF NOT EXISTS transaction_items (
     id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
     transaction_id TEXT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
     product_id TEXT NOT NULL,
     product_name TEXT NOT NULL,
+    variant_id TEXT,
+    variant_name TEXT DEFAULT '',
     unit_price DECIMAL(10, 2) NOT NULL,
     quantity INTEGER NOT NULL,
     subtotal DECIMAL(12, 2) NOT NULL,
@@ -84,6 +167,7 @@ CREATE TABLE IF NOT EXISTS transaction_items (
 -- Notifications table
 CREATE TABLE IF NOT EXISTS notifications (
     id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
     user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     type TEXT NOT NULL,
     title TEXT NOT NULL,
@@ -93,6 +177,498 @@ CREATE TABLE IF NOT EXISTS notifications (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Vouchers table
+CREATE TABLE IF NOT EXISTS vouchers (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    code TEXT NOT NULL,
+    batch_id TEXT NOT NULL,
+    discount_type TEXT NOT NULL CHECK (discount_type IN ('percentage', 'fixed')),
+    discount_value DECIMAL(12, 2) NOT NULL,
+    max_discount DECIMAL(12, 2) DEFAULT 0,
+    min_spend DECIMAL(12, 2) DEFAULT 0,
+    max_uses INTEGER NOT NULL DEFAULT 1,
+    uses_per_customer INTEGER NOT NULL DEFAULT 0,
+    used_count INTEGER NOT NULL DEFAULT 0,
+    valid_from TIMESTAMP,
+    expires_at TIMESTAMP,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Voucher redemptions table
+CREATE TABLE IF NOT EXISTS voucher_redemptions (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    voucher_id TEXT NOT NULL REFERENCES vouchers(id) ON DELETE CASCADE,
+    transaction_id TEXT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    customer_id TEXT REFERENCES customers(id) ON DELETE SET NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    discount_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Loyalty ledger table (every earn, redeem, adjustment and expiry)
+CREATE TABLE IF NOT EXISTS loyalty_ledger (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    customer_id TEXT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    transaction_id TEXT REFERENCES transactions(id) ON DELETE SET NULL,
+    type TEXT NOT NULL CHECK (type IN ('earn', 'redeem', 'adjust', 'expire')),
+    points INTEGER NOT NULL,
+    balance_after INTEGER NOT NULL,
+    remaining INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP,
+    note TEXT DEFAULT '',
+    created_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Customer merge audit table (merged customers are deleted, so no FK on merged_customer_id)
+CREATE TABLE IF NOT EXISTS customer_merges (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    target_customer_id TEXT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    merged_customer_id TEXT NOT NULL,
+    merged_name TEXT NOT NULL,
+    merged_email TEXT DEFAULT '',
+    merged_phone TEXT DEFAULT '',
+    points_merged INTEGER NOT NULL DEFAULT 0,
+    transactions_moved INTEGER NOT NULL DEFAULT 0,
+    merged_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Product variants (each with its own SKU, price and stock)
+CREATE TABLE IF NOT EXISTS product_variants (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    sku TEXT NOT NULL,
+    name TEXT NOT NULL,
+    price DECIMAL(10, 2) NOT NULL,
+    stock INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN DEFAULT TRUE,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Variant stock at each store; like store_products, a variant a store has never stocked has
+-- no stock there
+CREATE TABLE IF NOT EXISTS store_product_variants (
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    store_id TEXT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    variant_id TEXT NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    stock INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (store_id, variant_id)
+);
+
+-- Modifier groups and their modifiers (e.g. sugar level, toppings)
+CREATE TABLE IF NOT EXISTS modifier_groups (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    min_select INTEGER NOT NULL DEFAULT 0,
+    max_select INTEGER NOT NULL DEFAULT 0,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS modifiers (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    group_id TEXT NOT NULL REFERENCES modifier_groups(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    price_delta DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    is_active BOOLEAN DEFAULT TRUE,
+    sort_order INTEGER NOT NULL DEFAULT 0
+);
+
+-- Modifiers chosen for a transaction item, copied at the time of sale
+CREATE TABLE IF NOT EXISTS transaction_item_modifiers (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    transaction_item_id TEXT NOT NULL REFERENCES transaction_items(id) ON DELETE CASCADE,
+    modifier_id TEXT NOT NULL,
+    group_name TEXT NOT NULL,
+    name TEXT NOT NULL,
+    price_delta DECIMAL(10, 2) NOT NULL DEFAULT 0
+);
+
+-- Ingredients (raw materials consumed by recipes; stock is kept in the ingredient's unit)
+CREATE TABLE IF NOT EXISTS ingredients (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    sku TEXT NOT NULL,
+    name TEXT NOT NULL,
+    unit TEXT NOT NULL,
+    stock DECIMAL(14, 3) NOT NULL DEFAULT 0,
+    low_stock_threshold DECIMAL(14, 3) NOT NULL DEFAULT 0,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Recipe items (ingredients consumed by one unit of a product)
+CREATE TABLE IF NOT EXISTS recipe_items (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    ingredient_id TEXT NOT NULL REFERENCES ingredients(id) ON DELETE RESTRICT,
+    quantity DECIMAL(12, 3) NOT NULL,
+    unit TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Stock movements (every sale, return, restock, adjustment and waste of an ingredient, and
+-- goods received, counted and transferred between stores for a product or one of its variants)
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    ingredient_id TEXT REFERENCES ingredients(id) ON DELETE CASCADE,
+    product_id TEXT REFERENCES products(id) ON DELETE CASCADE,
+    variant_id TEXT REFERENCES product_variants(id) ON DELETE CASCADE,
+    store_id TEXT REFERENCES stores(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('sale', 'return', 'restock', 'adjustment', 'waste', 'transfer_out', 'transfer_in')),
+    quantity DECIMAL(14, 3) NOT NULL,
+    balance_after DECIMAL(14, 3) NOT NULL,
+    reference_id TEXT,
+    note TEXT DEFAULT '',
+    created_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Product barcodes (EAN-13, UPC-A, internal codes and weight/price embedded item prefixes)
+CREATE TABLE IF NOT EXISTS product_barcodes (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id TEXT REFERENCES product_variants(id) ON DELETE CASCADE,
+    code TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('ean13', 'upca', 'internal', 'variable')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Suppliers
+CREATE TABLE IF NOT EXISTS suppliers (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    name TEXT NOT NULL,
+    contact_name TEXT DEFAULT '',
+    email TEXT DEFAULT '',
+    phone TEXT DEFAULT '',
+    address TEXT DEFAULT '',
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Purchase orders
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    po_number TEXT NOT NULL UNIQUE,
+    supplier_id TEXT NOT NULL REFERENCES suppliers(id) ON DELETE RESTRICT,
+    store_id TEXT REFERENCES stores(id) ON DELETE RESTRICT,
+    status TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'ordered', 'partially_received', 'received', 'cancelled')),
+    expected_at TIMESTAMP,
+    notes TEXT DEFAULT '',
+    created_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    ordered_at TIMESTAMP,
+    received_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Purchase order lines; unit_cost is the expected cost until goods are received
+CREATE TABLE IF NOT EXISTS purchase_order_lines (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    purchase_order_id TEXT NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    product_id TEXT NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    received_quantity INTEGER NOT NULL DEFAULT 0,
+    unit_cost DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Stock takes (physical inventory counts) of one category or the whole store
+CREATE TABLE IF NOT EXISTS stock_takes (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    reference TEXT UNIQUE NOT NULL,
+    store_id TEXT REFERENCES stores(id) ON DELETE RESTRICT,
+    category_id TEXT REFERENCES categories(id) ON DELETE SET NULL,
+    status TEXT NOT NULL DEFAULT 'counting' CHECK (status IN ('counting', 'approved', 'cancelled')),
+    notes TEXT DEFAULT '',
+    created_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    approved_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    approved_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Products in a stock take. expected_stock is the system stock when the product was last counted,
+-- so sales made during the count do not show up as variance.
+CREATE TABLE IF NOT EXISTS stock_take_items (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    stock_take_id TEXT NOT NULL REFERENCES stock_takes(id) ON DELETE CASCADE,
+    product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    expected_stock INTEGER NOT NULL,
+    counted_quantity INTEGER CHECK (counted_quantity >= 0),
+    counted_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    counted_at TIMESTAMP,
+    UNIQUE (stock_take_id, product_id)
+);
+
+-- Stock transfers between stores (requested, shipped out of the source, received at the destination)
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    reference TEXT UNIQUE NOT NULL,
+    from_store_id TEXT NOT NULL REFERENCES stores(id) ON DELETE RESTRICT,
+    to_store_id TEXT NOT NULL REFERENCES stores(id) ON DELETE RESTRICT,
+    status TEXT NOT NULL DEFAULT 'requested' CHECK (status IN ('requested', 'in_transit', 'received', 'cancelled')),
+    notes TEXT DEFAULT '',
+    requested_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    shipped_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    shipped_at TIMESTAMP,
+    received_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    received_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (from_store_id <> to_store_id)
+);
+
+-- Products on a stock transfer; unit_cost is the cost price when shipped
+CREATE TABLE IF NOT EXISTS stock_transfer_items (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    transfer_id TEXT NOT NULL REFERENCES stock_transfers(id) ON DELETE CASCADE,
+    product_id TEXT NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    requested_quantity INTEGER NOT NULL CHECK (requested_quantity > 0),
+    shipped_quantity INTEGER CHECK (shipped_quantity >= 0),
+    received_quantity INTEGER CHECK (received_quantity >= 0),
+    unit_cost DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    UNIQUE (transfer_id, product_id)
+);
+
+-- Roles: custom roles of a tenant, and its changes to the built-in manager and cashier roles
+CREATE TABLE IF NOT EXISTS roles (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    name TEXT NOT NULL,
+    description TEXT DEFAULT '',
+    require_mfa BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Permissions granted by a role
+CREATE TABLE IF NOT EXISTS role_permissions (
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    role_id TEXT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission TEXT NOT NULL,
+    PRIMARY KEY (role_id, permission)
+);
+
+-- Manager approvals of sensitive actions; only a hash of the single-use token is kept
+CREATE TABLE IF NOT EXISTS overrides (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    token_hash TEXT NOT NULL UNIQUE,
+    action TEXT NOT NULL CHECK (action IN ('void', 'refund', 'discount', 'price_override', 'no_sale')),
+    reference_id TEXT DEFAULT '',
+    amount DECIMAL(12, 2),
+    terminal_id TEXT DEFAULT '',
+    requested_by TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    approved_by TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Registered POS terminals and kitchen displays; staff sign in to POS terminals with a PIN and
+-- every device reports in with heartbeats
+CREATE TABLE IF NOT EXISTS terminals (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    store_id TEXT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    device_type TEXT NOT NULL DEFAULT 'pos' CHECK (device_type IN ('pos', 'kds')),
+    key_hash TEXT NOT NULL UNIQUE,
+    is_active BOOLEAN DEFAULT TRUE,
+    last_used_at TIMESTAMP,
+    app_version TEXT DEFAULT '',
+    latency_ms INTEGER,
+    last_seen_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Consecutive failed sign-ins per user or terminal; keys are globally unique ids
+CREATE TABLE IF NOT EXISTS login_attempts (
+    key TEXT PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Carts parked at the POS, kept with the terminal (or store) they were held at
+CREATE TABLE IF NOT EXISTS held_transactions (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    store_id TEXT DEFAULT '',
+    terminal_id TEXT DEFAULT '',
+    hold_number TEXT NOT NULL,
+    customer_id TEXT REFERENCES customers(id) ON DELETE SET NULL,
+    customer_name TEXT DEFAULT '',
+    total_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    notes TEXT DEFAULT '',
+    held_by TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS held_transaction_items (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    held_transaction_id TEXT NOT NULL REFERENCES held_transactions(id) ON DELETE CASCADE,
+    product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id TEXT DEFAULT '',
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    position INTEGER NOT NULL DEFAULT 0
+);
+
+-- Single-use recovery codes for users with two-factor authentication
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Password sign-ins and lockouts; tenant and user are unknown for emails without an account
+CREATE TABLE IF NOT EXISTS login_events (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT REFERENCES tenants(id),
+    user_id TEXT REFERENCES users(id) ON DELETE SET NULL,
+    email TEXT NOT NULL,
+    event TEXT NOT NULL,
+    ip_address TEXT DEFAULT '',
+    user_agent TEXT DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Schema upgrades for databases created by earlier versions
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS voucher_code TEXT DEFAULT '';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS points_earned INTEGER DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS points_redeemed INTEGER DEFAULT 0;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS phone_normalized TEXT DEFAULT '';
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS email_normalized TEXT DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS variant_id TEXT;
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS variant_name TEXT DEFAULT '';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS thumbnail_url TEXT DEFAULT '';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS cost_price DECIMAL(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS product_id TEXT REFERENCES products(id) ON DELETE CASCADE;
+ALTER TABLE stock_movements ALTER COLUMN ingredient_id DROP NOT NULL;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(sku, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('indonesian', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('indonesian', COALESCE(description, '')), 'C') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'C')
+) STORED;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS store_id TEXT REFERENCES stores(id) ON DELETE RESTRICT;
+ALTER TABLE purchase_orders ADD COLUMN IF NOT EXISTS store_id TEXT REFERENCES stores(id) ON DELETE RESTRICT;
+ALTER TABLE stock_takes ADD COLUMN IF NOT EXISTS store_id TEXT REFERENCES stores(id) ON DELETE RESTRICT;
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS store_id TEXT REFERENCES stores(id) ON DELETE CASCADE;
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_type_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_type_check
+    CHECK (type IN ('sale', 'return', 'restock', 'adjustment', 'waste', 'transfer_out', 'transfer_in'));
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE user_stores ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE store_products ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE vouchers ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE voucher_redemptions ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE loyalty_ledger ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE customer_merges ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE modifier_groups ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE modifiers ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE transaction_item_modifiers ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE ingredients ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE recipe_items ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE product_barcodes ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE suppliers ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE purchase_orders ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE purchase_order_lines ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE stock_takes ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE stock_take_items ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE stock_transfers ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE stock_transfer_items ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS pin_hash TEXT DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret TEXT DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT DEFAULT 0;
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS require_mfa BOOLEAN DEFAULT FALSE;
+ALTER TABLE terminals ADD COLUMN IF NOT EXISTS device_type TEXT NOT NULL DEFAULT 'pos' CHECK (device_type IN ('pos', 'kds'));
+ALTER TABLE terminals ADD COLUMN IF NOT EXISTS app_version TEXT DEFAULT '';
+ALTER TABLE terminals ADD COLUMN IF NOT EXISTS latency_ms INTEGER;
+ALTER TABLE terminals ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP;
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS variant_id TEXT REFERENCES product_variants(id) ON DELETE CASCADE;
+ALTER TABLE overrides ADD COLUMN IF NOT EXISTS amount DECIMAL(12, 2);
+ALTER TABLE overrides ADD COLUMN IF NOT EXISTS terminal_id TEXT DEFAULT '';
+
+-- Users may hold custom roles as well as the built-in ones
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+
+-- Unique keys only apply to rows that are not in the trash (see partial unique indexes below)
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_slug_key;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_sku_key;
+
+-- Codes, SKUs and slugs are unique per tenant rather than globally
+ALTER TABLE stores DROP CONSTRAINT IF EXISTS stores_code_key;
+ALTER TABLE vouchers DROP CONSTRAINT IF EXISTS vouchers_code_key;
+ALTER TABLE ingredients DROP CONSTRAINT IF EXISTS ingredients_sku_key;
+ALTER TABLE product_barcodes DROP CONSTRAINT IF EXISTS product_barcodes_code_key;
+DROP INDEX IF EXISTS idx_categories_slug_active;
+DROP INDEX IF EXISTS idx_products_sku_active;
+DROP INDEX IF EXISTS idx_product_variants_sku;
+DROP INDEX IF EXISTS idx_customers_phone_normalized;
+DROP INDEX IF EXISTS idx_customers_email_normalized;
+
+-- Backfill normalized contact details (digits only, +62/62 rewritten to 0; lower-cased email)
+UPDATE customers SET email_normalized = LOWER(TRIM(email))
+WHERE email_normalized = '' AND COALESCE(email, '') <> '';
+UPDATE customers SET phone_normalized = REGEXP_REPLACE(
+    REGEXP_REPLACE(phone, '[^0-9]', '', 'g'), '^62(.)', '0\1')
+WHERE phone_normalized = '' AND COALESCE(phone, '') <> '';
+
 -- Indexes for performance
 CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
 CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
@@ -110,3 +686,73 @@ CREATE INDEX IF NOT EXISTS idx_transactions_date ON transactions(created_at);
 CREATE INDEX IF NOT EXISTS idx_transaction_items_transaction ON transaction_items(transaction_id);
 CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id);
 CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id, is_read);
+CREATE INDEX IF NOT EXISTS idx_vouchers_code ON vouchers(code);
+CREATE INDEX IF NOT EXISTS idx_vouchers_batch ON vouchers(batch_id);
+CREATE INDEX IF NOT EXISTS idx_voucher_redemptions_voucher ON voucher_redemptions(voucher_id);
+CREATE INDEX IF NOT EXISTS idx_voucher_redemptions_customer ON voucher_redemptions(voucher_id, customer_id);
+CREATE INDEX IF NOT EXISTS idx_loyalty_ledger_customer ON loyalty_ledger(customer_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_loyalty_ledger_transaction ON loyalty_ledger(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_loyalty_ledger_expiry ON loyalty_ledger(expires_at) WHERE remaining > 0;
+CREATE INDEX IF NOT EXISTS idx_transactions_customer_status ON transactions(customer_id, status, created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_tenant_phone_active ON customers(tenant_id, phone_normalized)
+    WHERE phone_normalized <> '' AND deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_tenant_email_active ON customers(tenant_id, email_normalized)
+    WHERE email_normalized <> '' AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_customer_merges_target ON customer_merges(target_customer_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_active ON users(email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_tenant_slug_active ON categories(tenant_id, slug) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_tenant_sku_active ON products(tenant_id, sku) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_deleted ON users(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_categories_deleted ON categories(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_products_deleted ON products(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_customers_deleted ON customers(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_tenant_sku ON product_variants(tenant_id, sku);
+CREATE INDEX IF NOT EXISTS idx_product_variants_product ON product_variants(product_id, sort_order);
+CREATE INDEX IF NOT EXISTS idx_modifier_groups_product ON modifier_groups(product_id, sort_order);
+CREATE INDEX IF NOT EXISTS idx_modifiers_group ON modifiers(group_id, sort_order);
+CREATE INDEX IF NOT EXISTS idx_transaction_item_modifiers_item ON transaction_item_modifiers(transaction_item_id);
+CREATE INDEX IF NOT EXISTS idx_recipe_items_product ON recipe_items(product_id);
+CREATE INDEX IF NOT EXISTS idx_recipe_items_ingredient ON recipe_items(ingredient_id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_ingredient ON stock_movements(ingredient_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_reference ON stock_movements(reference_id);
+CREATE INDEX IF NOT EXISTS idx_product_barcodes_product ON product_barcodes(product_id);
+CREATE INDEX IF NOT EXISTS idx_products_search ON products USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_products_sku_trgm ON products USING GIN (sku gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_categories_name_trgm ON categories USING GIN (name gin_trgm_ops);
+DROP INDEX IF EXISTS idx_transactions_keyset;
+CREATE INDEX IF NOT EXISTS idx_transactions_tenant_keyset ON transactions(tenant_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_tenant_keyset ON stock_movements(tenant_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_loyalty_ledger_tenant_keyset ON loyalty_ledger(tenant_id, customer_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product ON stock_movements(product_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_supplier ON purchase_orders(supplier_id, status);
+CREATE INDEX IF NOT EXISTS idx_purchase_order_lines_order ON purchase_order_lines(purchase_order_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_order_lines_product ON purchase_order_lines(product_id);
+CREATE INDEX IF NOT EXISTS idx_stock_takes_status ON stock_takes(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_user_stores_store ON user_stores(store_id);
+CREATE INDEX IF NOT EXISTS idx_store_products_product ON store_products(product_id);
+CREATE INDEX IF NOT EXISTS idx_store_product_variants_variant ON store_product_variants(variant_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_store ON transactions(store_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_store ON purchase_orders(store_id, status);
+CREATE INDEX IF NOT EXISTS idx_stock_takes_store ON stock_takes(store_id, status);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_from ON stock_transfers(from_store_id, status);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_to ON stock_transfers(to_store_id, status);
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_items_transfer ON stock_transfer_items(transfer_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stores_tenant_code ON stores(tenant_id, code);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_vouchers_tenant_code ON vouchers(tenant_id, code);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ingredients_tenant_sku ON ingredients(tenant_id, sku);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_barcodes_tenant_code ON product_barcodes(tenant_id, code);
+CREATE INDEX IF NOT EXISTS idx_users_tenant ON users(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_categories_tenant ON categories(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_products_tenant ON products(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_customers_tenant ON customers(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_tenant ON transactions(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_suppliers_tenant ON suppliers(tenant_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_tenant_name ON roles(tenant_id, name);
+CREATE INDEX IF NOT EXISTS idx_overrides_tenant ON overrides(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_terminals_store ON terminals(tenant_id, store_id);
+CREATE INDEX IF NOT EXISTS idx_held_transactions_place ON held_transactions(tenant_id, store_id, terminal_id);
+CREATE INDEX IF NOT EXISTS idx_held_transaction_items_held ON held_transaction_items(held_transaction_id);
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user ON mfa_recovery_codes(user_id);
+CREATE INDEX IF NOT EXISTS idx_login_events_tenant ON login_events(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_events_user ON login_events(user_id, created_at);
@@ -0,0 +1,30 @@
+-- Row-level security for tenant isolation (applied when DB_ROW_LEVEL_SECURITY is enabled)
+--
+-- Every query runs with the app.tenant_id session variable set to the tenant of the request.
+-- Rows of other tenants are neither visible nor writable, and a connection without a tenant
+-- sees no rows at all. Work that spans tenants (migrations, sign-in lookups and background
+-- jobs) runs on the DB_SYSTEM_CONN pool, whose role has BYPASSRLS. Repositories still filter
+-- by tenant, so this is a second line of defence. FORCE applies the policies to the table
+-- owner as well, but superusers and roles with BYPASSRLS are never restricted.
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'users', 'stores', 'user_stores', 'categories', 'products', 'customers', 'transactions',
+        'store_products', 'store_product_variants', 'transaction_items', 'notifications', 'vouchers', 'voucher_redemptions',
+        'loyalty_ledger', 'customer_merges', 'product_variants', 'modifier_groups', 'modifiers',
+        'transaction_item_modifiers', 'ingredients', 'recipe_items', 'stock_movements',
+        'product_barcodes', 'suppliers', 'purchase_orders', 'purchase_order_lines', 'stock_takes',
+        'stock_take_items', 'stock_transfers', 'stock_transfer_items', 'roles', 'role_permissions',
+        'overrides', 'terminals', 'held_transactions', 'held_transaction_items',
+        'mfa_recovery_codes', 'login_events'
+    ] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', t);
+        EXECUTE format(
+            'CREATE POLICY tenant_isolation ON %I USING '
+            '(tenant_id = NULLIF(current_setting(''app.tenant_id'', true), ''''))', t);
+    END LOOP;
+END $$;
@@ -1,9 +1,10 @@
 package dto
 
-// LoginRequest represents a login request
+// LoginRequest represents a login request. StoreID optionally picks the store to work at.
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
+	StoreID  string `json:"store_id"`
 }
 
 // RegisterRequest represents a registration request
@@ -30,6 +31,13 @@ type UpdateProfileRequest struct {
 	Phone string `json:"phone" validate:"omitempty,max=20"`
 }
 
+// SetPINRequest represents setting the current user's PIN, which approves other users'
+// actions at the POS. An empty PIN removes it.
+type SetPINRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	PIN             string `json:"pin" validate:"omitempty,numeric,min=4,max=6"`
+}
+
 // ForgotPasswordRequest represents a forgot password request
 type ForgotPasswordRequest struct {
 	Email string `json:"email" validate:"required,email"`
@@ -43,18 +51,29 @@ type ResetPasswordRequest struct {
 
 // AuthResponse represents an authentication response
 type AuthResponse struct {
-	User  UserResponse  `json:"user"`
-	Token TokenResponse `json:"token"`
+	User     UserResponse  `json:"user"`
+	Token    TokenResponse `json:"token"`
+	StoreID  string        `json:"store_id,omitempty"`
+	TenantID string        `json:"tenant_id,omitempty"`
+	// TerminalID is set for PIN sessions, which have no refresh token
+	TerminalID string `json:"terminal_id,omitempty"`
+	// MFA is set instead of a token when the password was right but a second factor is needed
+	MFA *MFAChallengeResponse `json:"mfa,omitempty"`
+	// RecoveryCodes are issued once, when two-factor authentication is enabled while signing in
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
 }
 
 // UserResponse represents user data in responses
 type UserResponse struct {
-	ID       string `json:"id"`
-	Email    string `json:"email"`
-	Name     string `json:"name"`
-	Phone    string `json:"phone,omitempty"`
-	Role     string `json:"role"`
-	IsActive bool   `json:"is_active"`
+	ID         string `json:"id"`
+	Email      string `json:"email"`
+	Name       string `json:"name"`
+	Phone      string `json:"phone,omitempty"`
+	Role       string `json:"role"`
+	IsActive   bool   `json:"is_active"`
+	MFAEnabled bool   `json:"mfa_enabled"`
+
+	Permissions []string `json:"permissions,omitempty"` // Granted by the role; only on /auth/me
 }
 
 // TokenResponse represents token data in responses
@@ -27,3 +27,12 @@ type CategoryResponse struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
+
+// CategoryActivityResponse represents one entry of the category activity log
+type CategoryActivityResponse struct {
+	ID           string    `json:"id"`
+	CategoryID   string    `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	Event        string    `json:"event"`
+	CreatedAt    time.Time `json:"created_at"`
+}
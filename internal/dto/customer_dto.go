@@ -22,21 +22,79 @@ type UpdateCustomerRequest struct {
 type UpdateLoyaltyPointsRequest struct {
 	Points    int    `json:"points" validate:"required,gt=0"`
 	Operation string `json:"operation" validate:"required,oneof=add deduct"`
+	Note      string `json:"note" validate:"max=255"`
+}
+
+// MergeCustomersRequest represents a request to fold duplicate customers into one
+type MergeCustomersRequest struct {
+	SourceIDs []string `json:"source_ids" validate:"required,min=1,max=20,dive,uuid"`
+}
+
+// CustomerDuplicateFilter represents the contact details to look up duplicates for
+type CustomerDuplicateFilter struct {
+	Phone string `form:"phone"`
+	Email string `form:"email"`
 }
 
 // CustomerResponse represents a customer in responses
 type CustomerResponse struct {
-	ID            string    `json:"id"`
-	Name          string    `json:"name"`
-	Email         string    `json:"email,omitempty"`
-	Phone         string    `json:"phone,omitempty"`
-	Address       string    `json:"address,omitempty"`
-	LoyaltyPoints int       `json:"loyalty_points"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            string                 `json:"id"`
+	Name          string                 `json:"name"`
+	Email         string                 `json:"email,omitempty"`
+	Phone         string                 `json:"phone,omitempty"`
+	Address       string                 `json:"address,omitempty"`
+	LoyaltyPoints int                    `json:"loyalty_points"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+	Stats         *CustomerStatsResponse `json:"stats,omitempty"`
+}
+
+// CustomerStatsResponse represents computed purchase metrics for a customer
+type CustomerStatsResponse struct {
+	LifetimeSpend    float64                           `json:"lifetime_spend"`
+	VisitCount       int                               `json:"visit_count"`
+	AverageBasket    float64                           `json:"average_basket"`
+	FirstVisitAt     *time.Time                        `json:"first_visit_at,omitempty"`
+	LastVisitAt      *time.Time                        `json:"last_visit_at,omitempty"`
+	Segment          string                            `json:"segment"`
+	FavoriteProducts []CustomerFavoriteProductResponse `json:"favorite_products,omitempty"`
+}
+
+// CustomerFavoriteProductResponse represents a frequently bought product
+type CustomerFavoriteProductResponse struct {
+	ProductID   string  `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Quantity    int     `json:"quantity"`
+	TotalAmount float64 `json:"total_amount"`
 }
 
 // CustomerListFilter represents filters for customer listing
 type CustomerListFilter struct {
-	Search string `form:"search"`
+	Search        string   `form:"search"`
+	Segment       string   `form:"segment" validate:"omitempty,oneof=new regular lapsed vip"`
+	MinSpend      *float64 `form:"min_spend" validate:"omitempty,gte=0"`
+	MaxSpend      *float64 `form:"max_spend" validate:"omitempty,gte=0"`
+	MinVisits     *int     `form:"min_visits" validate:"omitempty,gte=0"`
+	LastVisitFrom string   `form:"last_visit_from" validate:"omitempty,datetime=2006-01-02"`
+	LastVisitTo   string   `form:"last_visit_to" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// CustomerMergeResponse represents a customer merge audit record in responses
+type CustomerMergeResponse struct {
+	ID                string    `json:"id"`
+	TargetCustomerID  string    `json:"target_customer_id"`
+	MergedCustomerID  string    `json:"merged_customer_id"`
+	MergedName        string    `json:"merged_name"`
+	MergedEmail       string    `json:"merged_email,omitempty"`
+	MergedPhone       string    `json:"merged_phone,omitempty"`
+	PointsMerged      int       `json:"points_merged"`
+	TransactionsMoved int       `json:"transactions_moved"`
+	MergedBy          string    `json:"merged_by"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// MergeCustomersResponse represents the result of a customer merge
+type MergeCustomersResponse struct {
+	Customer *CustomerResponse        `json:"customer"`
+	Merges   []*CustomerMergeResponse `json:"merges"`
 }
@@ -0,0 +1,103 @@
+package dto
+
+import "time"
+
+// CreateIngredientRequest represents a request to create an ingredient
+type CreateIngredientRequest struct {
+	SKU               string  `json:"sku" validate:"required,min=2,max=50"`
+	Name              string  `json:"name" validate:"required,min=2,max=200"`
+	Unit              string  `json:"unit" validate:"required,oneof=mg g kg ml l pcs dz"`
+	Stock             float64 `json:"stock" validate:"gte=0"`
+	LowStockThreshold float64 `json:"low_stock_threshold" validate:"gte=0"`
+}
+
+// UpdateIngredientRequest represents a request to update an ingredient.
+// Stock changes go through AdjustIngredientStockRequest so every change is recorded.
+type UpdateIngredientRequest struct {
+	SKU               *string  `json:"sku" validate:"omitempty,min=2,max=50"`
+	Name              *string  `json:"name" validate:"omitempty,min=2,max=200"`
+	LowStockThreshold *float64 `json:"low_stock_threshold" validate:"omitempty,gte=0"`
+	IsActive          *bool    `json:"is_active"`
+}
+
+// AdjustIngredientStockRequest represents a manual stock movement. Restock adds to stock,
+// waste removes from it and adjustment applies a signed correction.
+type AdjustIngredientStockRequest struct {
+	Type     string  `json:"type" validate:"required,oneof=restock adjustment waste"`
+	Quantity float64 `json:"quantity" validate:"required,ne=0"`
+	Unit     string  `json:"unit" validate:"omitempty,oneof=mg g kg ml l pcs dz"`
+	Note     string  `json:"note" validate:"max=500"`
+}
+
+// IngredientResponse represents an ingredient in responses
+type IngredientResponse struct {
+	ID                string    `json:"id"`
+	SKU               string    `json:"sku"`
+	Name              string    `json:"name"`
+	Unit              string    `json:"unit"`
+	Stock             float64   `json:"stock"`
+	LowStockThreshold float64   `json:"low_stock_threshold"`
+	LowStock          bool      `json:"low_stock"`
+	IsActive          bool      `json:"is_active"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// IngredientListFilter represents filters for ingredient listing
+type IngredientListFilter struct {
+	Search   string `form:"search"`
+	LowStock *bool  `form:"low_stock"`
+	IsActive *bool  `form:"is_active"`
+}
+
+// StockMovementResponse represents a stock movement in responses
+type StockMovementResponse struct {
+	ID             string    `json:"id"`
+	IngredientID   string    `json:"ingredient_id"`
+	IngredientName string    `json:"ingredient_name"`
+	Type           string    `json:"type"`
+	Quantity       float64   `json:"quantity"`
+	Unit           string    `json:"unit"`
+	BalanceAfter   float64   `json:"balance_after"`
+	ReferenceID    *string   `json:"reference_id,omitempty"`
+	Note           string    `json:"note,omitempty"`
+	CreatedBy      string    `json:"created_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// RecipeItemRequest represents one ingredient line of a recipe
+type RecipeItemRequest struct {
+	IngredientID string  `json:"ingredient_id" validate:"required,uuid"`
+	Quantity     float64 `json:"quantity" validate:"required,gt=0"`
+	Unit         string  `json:"unit" validate:"required,oneof=mg g kg ml l pcs dz"`
+}
+
+// SetRecipeRequest represents a request to replace a product's recipe.
+// An empty item list removes the recipe.
+type SetRecipeRequest struct {
+	Items []RecipeItemRequest `json:"items" validate:"dive"`
+}
+
+// RecipeItemResponse represents a recipe line in responses
+type RecipeItemResponse struct {
+	IngredientID   string  `json:"ingredient_id"`
+	IngredientName string  `json:"ingredient_name"`
+	Quantity       float64 `json:"quantity"`
+	Unit           string  `json:"unit"`
+}
+
+// RecipeResponse represents a product's recipe in responses
+type RecipeResponse struct {
+	ProductID string                `json:"product_id"`
+	Items     []*RecipeItemResponse `json:"items"`
+	// Portions is how many units of the product current ingredient stock can make
+	Portions int `json:"portions"`
+}
+
+// UnitResponse represents a supported unit of measure
+type UnitResponse struct {
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+	Dimension string  `json:"dimension"`
+	Factor    float64 `json:"factor"`
+}
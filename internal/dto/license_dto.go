@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+// ActivateLicenseRequest represents a request to install a license key
+type ActivateLicenseRequest struct {
+	LicenseKey string `json:"license_key" validate:"required"`
+}
+
+// LicenseStatusResponse represents the current tenant's license and how much of it is used
+type LicenseStatusResponse struct {
+	Status     string     `json:"status"`
+	Enforced   bool       `json:"enforced"`
+	Licensee   string     `json:"licensee,omitempty"`
+	Plan       string     `json:"plan"`
+	Features   []string   `json:"features"`
+	Seats      int        `json:"seats"` // 0 = unlimited
+	SeatsUsed  int        `json:"seats_used"`
+	Stores     int        `json:"stores"` // 0 = unlimited
+	StoresUsed int        `json:"stores_used"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
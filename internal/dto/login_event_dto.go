@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+// ClientInfo identifies where a sign-in came from
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// LoginEventResponse represents an entry of the sign-in audit log
+type LoginEventResponse struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id,omitempty"`
+	Email     string    `json:"email"`
+	Event     string    `json:"event"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginEventFilter represents filters for listing sign-in events
+type LoginEventFilter struct {
+	UserID string `form:"user_id"`
+	Event  string `form:"event" validate:"omitempty,oneof=login_succeeded login_failed login_blocked mfa_challenged mfa_failed account_locked ip_locked account_unlocked approval_failed"`
+}
@@ -0,0 +1,46 @@
+package dto
+
+import "time"
+
+// LoyaltySummaryResponse represents a customer's loyalty standing
+type LoyaltySummaryResponse struct {
+	CustomerID      string     `json:"customer_id"`
+	Balance         int        `json:"balance"`
+	BalanceValue    float64    `json:"balance_value"`
+	Tier            string     `json:"tier"`
+	Multiplier      float64    `json:"multiplier"`
+	LifetimeSpend   float64    `json:"lifetime_spend"`
+	NextTier        string     `json:"next_tier,omitempty"`
+	SpendToNextTier float64    `json:"spend_to_next_tier,omitempty"`
+	ExpiringPoints  int        `json:"expiring_points"`
+	ExpiringBefore  *time.Time `json:"expiring_before,omitempty"`
+}
+
+// LoyaltyLedgerEntryResponse represents a loyalty ledger entry in responses
+type LoyaltyLedgerEntryResponse struct {
+	ID            string     `json:"id"`
+	TransactionID *string    `json:"transaction_id,omitempty"`
+	Type          string     `json:"type"`
+	Points        int        `json:"points"`
+	BalanceAfter  int        `json:"balance_after"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	Note          string     `json:"note,omitempty"`
+	CreatedBy     string     `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// LoyaltyTierResponse represents a loyalty tier and its earn multiplier
+type LoyaltyTierResponse struct {
+	Tier       string  `json:"tier"`
+	MinSpend   float64 `json:"min_spend"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+// LoyaltyRulesResponse represents the configured earn and redeem rules
+type LoyaltyRulesResponse struct {
+	EarnAmount float64               `json:"earn_amount"`
+	EarnPoints int                   `json:"earn_points"`
+	PointValue float64               `json:"point_value"`
+	ExpiryDays int                   `json:"expiry_days"`
+	Tiers      []LoyaltyTierResponse `json:"tiers"`
+}
@@ -0,0 +1,55 @@
+package dto
+
+// MFAStatusResponse describes the current user's two-factor authentication
+type MFAStatusResponse struct {
+	Enabled           bool `json:"enabled"`
+	Required          bool `json:"required"` // The user's role requires two-factor authentication
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// MFASetupResponse holds a new TOTP secret. ProvisioningURI is shown as a QR code for
+// authenticator apps; Secret is for entering by hand.
+type MFASetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// EnableMFARequest confirms enrollment with a code from the authenticator app
+type EnableMFARequest struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
+
+// DisableMFARequest represents turning two-factor authentication off. It takes the password
+// and either a current code or a recovery code.
+type DisableMFARequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	Code            string `json:"code" validate:"omitempty,numeric,len=6"`
+	RecoveryCode    string `json:"recovery_code" validate:"omitempty,max=20"`
+}
+
+// RecoveryCodesResponse lists newly issued recovery codes, which are only ever shown once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAChallengeResponse is returned by sign-in when a second factor is needed. Token is
+// exchanged at /auth/mfa/verify; when EnrollmentRequired is set the user's role requires
+// two-factor authentication and the user must first set it up with /auth/mfa/setup.
+type MFAChallengeResponse struct {
+	Token              string `json:"token"`
+	ExpiresIn          int64  `json:"expires_in"`
+	EnrollmentRequired bool   `json:"enrollment_required"`
+}
+
+// MFAChallengeRequest identifies a sign-in waiting for its second factor
+type MFAChallengeRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+}
+
+// VerifyMFARequest completes a sign-in with a code from the authenticator app or a recovery
+// code
+type VerifyMFARequest struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code" validate:"omitempty,numeric,len=6"`
+	RecoveryCode string `json:"recovery_code" validate:"omitempty,max=20"`
+}
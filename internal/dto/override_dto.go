@@ -0,0 +1,46 @@
+package dto
+
+import "time"
+
+// CreateOverrideRequest represents a manager approving an action on the requesting user's
+// terminal, with either their password or their PIN. ReferenceID limits the approval to one
+// transaction. Amount is the discount or unit price approved, and is required for discounts
+// and price overrides.
+type CreateOverrideRequest struct {
+	Action        string   `json:"action" validate:"required,oneof=void refund discount price_override no_sale"`
+	ReferenceID   string   `json:"reference_id" validate:"omitempty,uuid"`
+	Amount        *float64 `json:"amount" validate:"omitempty,gte=0"`
+	Reason        string   `json:"reason" validate:"max=255"`
+	ApproverEmail string   `json:"approver_email" validate:"required,email"`
+	Password      string   `json:"password" validate:"required_without=PIN"`
+	PIN           string   `json:"pin" validate:"omitempty,numeric,min=4,max=6"`
+}
+
+// OverrideResponse represents an issued approval. Token is only returned when it is issued.
+type OverrideResponse struct {
+	ID          string     `json:"id"`
+	Action      string     `json:"action"`
+	ReferenceID string     `json:"reference_id,omitempty"`
+	Amount      *float64   `json:"amount,omitempty"`
+	TerminalID  string     `json:"terminal_id,omitempty"`
+	RequestedBy string     `json:"requested_by"`
+	ApprovedBy  string     `json:"approved_by"`
+	Reason      string     `json:"reason"`
+	Token       string     `json:"token,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	UsedAt      *time.Time `json:"used_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// NoSaleRequest represents opening the cash drawer without a sale
+type NoSaleRequest struct {
+	Reason        string `json:"reason" validate:"max=255"`
+	OverrideToken string `json:"override_token"`
+}
+
+// OverrideListFilter represents filters for listing approvals
+type OverrideListFilter struct {
+	Action      string `form:"action" validate:"omitempty,oneof=void refund discount price_override no_sale"`
+	RequestedBy string `form:"requested_by"`
+	ApprovedBy  string `form:"approved_by"`
+}
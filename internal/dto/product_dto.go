@@ -25,30 +25,74 @@ type UpdateProductRequest struct {
 	IsActive    *bool    `json:"is_active"`
 }
 
-// UpdateStockRequest represents a request to update product stock
+// UpdateStockRequest represents a request to update product stock, at the caller's store when
+// signed in to one. Counted stock is corrected with a stock take, which records the adjustment.
 type UpdateStockRequest struct {
 	Quantity  int    `json:"quantity" validate:"gte=0"`
-	Operation string `json:"operation" validate:"required,oneof=add subtract set remove"`
+	Operation string `json:"operation" validate:"required,oneof=add subtract remove"`
 }
 
 // ProductResponse represents a product in responses
 type ProductResponse struct {
-	ID          string            `json:"id"`
-	CategoryID  string            `json:"category_id"`
-	SKU         string            `json:"sku"`
-	Name        string            `json:"name"`
-	Description string            `json:"description"`
-	Price       float64           `json:"price"`
-	Stock       int               `json:"stock"`
-	ImageURL    string            `json:"image_url,omitempty"`
-	IsActive    bool              `json:"is_active"`
-	CreatedAt   time.Time         `json:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at"`
-	Category    *CategoryResponse `json:"category,omitempty"`
-}
-
-// ProductListFilter represents filters for product listing
+	ID           string            `json:"id"`
+	CategoryID   string            `json:"category_id"`
+	SKU          string            `json:"sku"`
+	Name         string            `json:"name"`
+	Description  string            `json:"description"`
+	Price        float64           `json:"price"`
+	CostPrice    float64           `json:"cost_price"`
+	Stock        int               `json:"stock"`
+	ImageURL     string            `json:"image_url,omitempty"`
+	ThumbnailURL string            `json:"thumbnail_url,omitempty"`
+	IsActive     bool              `json:"is_active"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	Category     *CategoryResponse `json:"category,omitempty"`
+
+	Variants       []ProductVariantResponse `json:"variants,omitempty"`
+	ModifierGroups []ModifierGroupResponse  `json:"modifier_groups,omitempty"`
+	Barcodes       []ProductBarcodeResponse `json:"barcodes,omitempty"`
+}
+
+// ProductImportRow represents one CSV row of a product import. Empty stock, is_active and
+// description cells keep the existing value when the SKU already exists.
+type ProductImportRow struct {
+	CategorySlug string  `json:"category_slug" validate:"required"`
+	SKU          string  `json:"sku" validate:"required,min=3,max=50"`
+	Name         string  `json:"name" validate:"required,min=2,max=200"`
+	Description  *string `json:"description" validate:"omitempty,max=1000"`
+	Price        float64 `json:"price" validate:"gte=0"`
+	Stock        *int    `json:"stock" validate:"omitempty,gte=0"`
+	IsActive     *bool   `json:"is_active"`
+}
+
+// ProductImportQuery represents options for a product import
+type ProductImportQuery struct {
+	DryRun bool `form:"dry_run"`
+}
+
+// ProductImportRowError represents a problem with one field of an imported row.
+// Row is the line number in the CSV file, counting the header as line 1.
+type ProductImportRowError struct {
+	Row     int    `json:"row"`
+	SKU     string `json:"sku,omitempty"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ProductImportResponse represents the outcome of a product import
+type ProductImportResponse struct {
+	DryRun  bool                    `json:"dry_run"`
+	Total   int                     `json:"total"`
+	Created int                     `json:"created"`
+	Updated int                     `json:"updated"`
+	Errors  []ProductImportRowError `json:"errors"`
+}
+
+// ProductListFilter represents filters for product listing. With a store, stock and price are
+// the store's.
 type ProductListFilter struct {
+	StoreID    string  `form:"store_id"`
 	CategoryID string  `form:"category_id"`
 	Search     string  `form:"search"`
 	MinPrice   float64 `form:"min_price"`
@@ -56,3 +100,162 @@ type ProductListFilter struct {
 	InStock    *bool   `form:"in_stock"`
 	IsActive   *bool   `form:"is_active"`
 }
+
+// ProductStockMovementFilter represents filters for the product stock movement listing
+type ProductStockMovementFilter struct {
+	ProductID string `form:"product_id"`
+	StoreID   string `form:"store_id"`
+	Type      string `form:"type" validate:"omitempty,oneof=sale return restock adjustment waste transfer_out transfer_in"`
+}
+
+// ProductStockMovementResponse represents a change to a product's stock, or one of its variants'
+// stock, in responses
+type ProductStockMovementResponse struct {
+	ID             string    `json:"id"`
+	ProductID      string    `json:"product_id"`
+	ProductName    string    `json:"product_name"`
+	SKU            string    `json:"sku"`
+	VariantID      *string   `json:"variant_id,omitempty"`
+	VariantName    string    `json:"variant_name,omitempty"`
+	StoreID        *string   `json:"store_id,omitempty"`
+	Type           string    `json:"type"`
+	QuantityChange int       `json:"quantity_change"`
+	NewBalance     int       `json:"new_balance"`
+	ReferenceID    *string   `json:"reference_id,omitempty"`
+	Note           string    `json:"note,omitempty"`
+	User           string    `json:"user"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ProductSearchQuery represents query parameters for product search suggestions
+type ProductSearchQuery struct {
+	Q       string `form:"q" validate:"required,max=100"`
+	Limit   int    `form:"limit" validate:"omitempty,min=1,max=50"`
+	StoreID string `form:"store_id"`
+}
+
+// ProductSuggestion represents a product search suggestion for the POS search box
+type ProductSuggestion struct {
+	ID           string  `json:"id"`
+	SKU          string  `json:"sku"`
+	Name         string  `json:"name"`
+	Price        float64 `json:"price"`
+	Stock        int     `json:"stock"`
+	Available    bool    `json:"available"`
+	CategoryID   string  `json:"category_id"`
+	CategoryName string  `json:"category_name"`
+	ThumbnailURL string  `json:"thumbnail_url,omitempty"`
+}
+
+// CreateVariantRequest represents a request to add a variant to a product
+type CreateVariantRequest struct {
+	SKU       string  `json:"sku" validate:"required,min=3,max=50"`
+	Name      string  `json:"name" validate:"required,min=1,max=100"`
+	Price     float64 `json:"price" validate:"gte=0"`
+	Stock     int     `json:"stock" validate:"gte=0"`
+	SortOrder int     `json:"sort_order"`
+}
+
+// UpdateVariantRequest represents a request to update a product variant
+type UpdateVariantRequest struct {
+	SKU       string   `json:"sku" validate:"omitempty,min=3,max=50"`
+	Name      string   `json:"name" validate:"omitempty,min=1,max=100"`
+	Price     *float64 `json:"price" validate:"omitempty,gte=0"`
+	Stock     *int     `json:"stock" validate:"omitempty,gte=0"`
+	IsActive  *bool    `json:"is_active"`
+	SortOrder *int     `json:"sort_order"`
+}
+
+// ModifierGroupRequest represents a request to create or replace a modifier group.
+// A max_select of 0 allows any number of modifiers.
+type ModifierGroupRequest struct {
+	Name      string            `json:"name" validate:"required,min=1,max=100"`
+	MinSelect int               `json:"min_select" validate:"gte=0"`
+	MaxSelect int               `json:"max_select" validate:"gte=0"`
+	SortOrder int               `json:"sort_order"`
+	Modifiers []ModifierRequest `json:"modifiers" validate:"required,min=1,dive"`
+}
+
+// ModifierRequest represents a modifier within a modifier group request
+type ModifierRequest struct {
+	Name       string  `json:"name" validate:"required,min=1,max=100"`
+	PriceDelta float64 `json:"price_delta"`
+	IsActive   *bool   `json:"is_active"`
+}
+
+// ProductVariantResponse represents a product variant in responses
+type ProductVariantResponse struct {
+	ID        string  `json:"id"`
+	SKU       string  `json:"sku"`
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	Stock     int     `json:"stock"`
+	IsActive  bool    `json:"is_active"`
+	SortOrder int     `json:"sort_order"`
+}
+
+// ModifierGroupResponse represents a modifier group in responses
+type ModifierGroupResponse struct {
+	ID        string             `json:"id"`
+	Name      string             `json:"name"`
+	MinSelect int                `json:"min_select"`
+	MaxSelect int                `json:"max_select"`
+	SortOrder int                `json:"sort_order"`
+	Modifiers []ModifierResponse `json:"modifiers"`
+}
+
+// ModifierResponse represents a modifier in responses
+type ModifierResponse struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	PriceDelta float64 `json:"price_delta"`
+	IsActive   bool    `json:"is_active"`
+}
+
+// CreateBarcodeRequest represents a request to assign a barcode to a product or one of its variants.
+// Variable barcodes are the 7-digit 2x prefix and item code of scale-printed EAN-13 labels.
+type CreateBarcodeRequest struct {
+	Code      string  `json:"code" validate:"required,max=32"`
+	Type      string  `json:"type" validate:"required,oneof=ean13 upca internal variable"`
+	VariantID *string `json:"variant_id" validate:"omitempty,uuid"`
+}
+
+// BarcodeLabelQuery represents options for rendering a barcode label
+type BarcodeLabelQuery struct {
+	Format string `form:"format" validate:"omitempty,oneof=svg png"`
+	Scale  int    `form:"scale" validate:"omitempty,min=1,max=10"`
+	Height int    `form:"height" validate:"omitempty,min=20,max=600"`
+}
+
+// ProductBarcodeResponse represents a product barcode in responses
+type ProductBarcodeResponse struct {
+	ID        string  `json:"id"`
+	Code      string  `json:"code"`
+	Type      string  `json:"type"`
+	VariantID *string `json:"variant_id,omitempty"`
+}
+
+// EmbeddedBarcodeValue represents the weight or price carried by a scale-printed EAN-13 label
+type EmbeddedBarcodeValue struct {
+	Kind        string  `json:"kind"`
+	WeightGrams int     `json:"weight_grams,omitempty"`
+	Price       float64 `json:"price"`
+}
+
+// BarcodeLookupResponse represents the product matched by a scanned code
+type BarcodeLookupResponse struct {
+	Code     string                  `json:"code"`
+	Product  *ProductResponse        `json:"product"`
+	Variant  *ProductVariantResponse `json:"variant,omitempty"`
+	Embedded *EmbeddedBarcodeValue   `json:"embedded,omitempty"`
+}
+
+// ProductImageResponse represents an uploaded product image
+type ProductImageResponse struct {
+	ImageURL     string `json:"image_url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	ContentType  string `json:"content_type"`
+	Size         int    `json:"size"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
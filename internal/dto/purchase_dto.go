@@ -0,0 +1,128 @@
+package dto
+
+import "time"
+
+// CreateSupplierRequest represents a request to create a supplier
+type CreateSupplierRequest struct {
+	Name        string `json:"name" validate:"required,min=2,max=200"`
+	ContactName string `json:"contact_name" validate:"max=100"`
+	Email       string `json:"email" validate:"omitempty,email"`
+	Phone       string `json:"phone" validate:"omitempty,min=6,max=20"`
+	Address     string `json:"address" validate:"max=500"`
+}
+
+// UpdateSupplierRequest represents a request to update a supplier
+type UpdateSupplierRequest struct {
+	Name        *string `json:"name" validate:"omitempty,min=2,max=200"`
+	ContactName *string `json:"contact_name" validate:"omitempty,max=100"`
+	Email       *string `json:"email" validate:"omitempty,email"`
+	Phone       *string `json:"phone" validate:"omitempty,min=6,max=20"`
+	Address     *string `json:"address" validate:"omitempty,max=500"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+// SupplierResponse represents a supplier in responses
+type SupplierResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	ContactName string    `json:"contact_name"`
+	Email       string    `json:"email"`
+	Phone       string    `json:"phone"`
+	Address     string    `json:"address"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SupplierListFilter represents filters for supplier listing
+type SupplierListFilter struct {
+	Search   string `form:"search"`
+	IsActive *bool  `form:"is_active"`
+}
+
+// PurchaseOrderLineRequest represents one product line of a purchase order
+type PurchaseOrderLineRequest struct {
+	ProductID string  `json:"product_id" validate:"required,uuid"`
+	Quantity  int     `json:"quantity" validate:"required,gt=0"`
+	UnitCost  float64 `json:"unit_cost" validate:"gte=0"`
+}
+
+// CreatePurchaseOrderRequest represents a request to create a draft purchase order
+type CreatePurchaseOrderRequest struct {
+	SupplierID string                     `json:"supplier_id" validate:"required,uuid"`
+	StoreID    *string                    `json:"store_id"`
+	ExpectedAt *time.Time                 `json:"expected_at"`
+	Notes      string                     `json:"notes" validate:"max=500"`
+	Lines      []PurchaseOrderLineRequest `json:"lines" validate:"required,min=1,max=200,dive"`
+}
+
+// UpdatePurchaseOrderRequest represents a request to edit a draft purchase order. Lines,
+// when given, replace all existing lines.
+type UpdatePurchaseOrderRequest struct {
+	SupplierID *string                    `json:"supplier_id" validate:"omitempty,uuid"`
+	ExpectedAt *time.Time                 `json:"expected_at"`
+	Notes      *string                    `json:"notes" validate:"omitempty,max=500"`
+	Lines      []PurchaseOrderLineRequest `json:"lines" validate:"omitempty,max=200,dive"`
+}
+
+// GoodsReceiptLineRequest represents a quantity received against one purchase order line.
+// UnitCost is the actual cost per unit and defaults to the line's expected cost.
+type GoodsReceiptLineRequest struct {
+	LineID   string   `json:"line_id" validate:"required,uuid"`
+	Quantity int      `json:"quantity" validate:"required,gt=0"`
+	UnitCost *float64 `json:"unit_cost" validate:"omitempty,gte=0"`
+}
+
+// ReceivePurchaseOrderRequest represents a delivery received against a purchase order
+type ReceivePurchaseOrderRequest struct {
+	Lines []GoodsReceiptLineRequest `json:"lines" validate:"required,min=1,max=200,dive"`
+}
+
+// PurchaseOrderLineResponse represents a purchase order line in responses
+type PurchaseOrderLineResponse struct {
+	ID               string  `json:"id"`
+	ProductID        string  `json:"product_id"`
+	ProductName      string  `json:"product_name"`
+	ProductSKU       string  `json:"product_sku"`
+	Quantity         int     `json:"quantity"`
+	ReceivedQuantity int     `json:"received_quantity"`
+	UnitCost         float64 `json:"unit_cost"`
+	LineTotal        float64 `json:"line_total"`
+}
+
+// PurchaseOrderResponse represents a purchase order in responses
+type PurchaseOrderResponse struct {
+	ID           string                      `json:"id"`
+	PONumber     string                      `json:"po_number"`
+	SupplierID   string                      `json:"supplier_id"`
+	SupplierName string                      `json:"supplier_name"`
+	StoreID      *string                     `json:"store_id,omitempty"`
+	Status       string                      `json:"status"`
+	ExpectedAt   *time.Time                  `json:"expected_at,omitempty"`
+	Notes        string                      `json:"notes"`
+	ExpectedCost float64                     `json:"expected_cost"`
+	CreatedBy    string                      `json:"created_by,omitempty"`
+	OrderedAt    *time.Time                  `json:"ordered_at,omitempty"`
+	ReceivedAt   *time.Time                  `json:"received_at,omitempty"`
+	CreatedAt    time.Time                   `json:"created_at"`
+	UpdatedAt    time.Time                   `json:"updated_at"`
+	Lines        []PurchaseOrderLineResponse `json:"lines,omitempty"`
+}
+
+// PurchaseOrderListFilter represents filters for purchase order listing
+type PurchaseOrderListFilter struct {
+	StoreID    string `form:"store_id"`
+	SupplierID string `form:"supplier_id"`
+	Status     string `form:"status" validate:"omitempty,oneof=draft ordered partially_received received cancelled"`
+}
+
+// OutstandingPurchaseReport represents the open purchase orders of one supplier
+type OutstandingPurchaseReport struct {
+	SupplierID          string     `json:"supplier_id"`
+	SupplierName        string     `json:"supplier_name"`
+	OpenOrders          int        `json:"open_orders"`
+	OverdueOrders       int        `json:"overdue_orders"`
+	OutstandingQuantity int        `json:"outstanding_quantity"`
+	OutstandingValue    float64    `json:"outstanding_value"`
+	OldestOrderedAt     *time.Time `json:"oldest_ordered_at,omitempty"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+// CreateRoleRequest represents a request to create a custom role
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=50"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"required"`
+	RequireMFA  bool     `json:"require_mfa"`
+}
+
+// UpdateRoleRequest represents a request to change a role. Permissions replaces the role's
+// permissions when given. RequireMFA makes its users sign in with two-factor authentication.
+type UpdateRoleRequest struct {
+	Description *string  `json:"description" validate:"omitempty,max=255"`
+	Permissions []string `json:"permissions"`
+	RequireMFA  *bool    `json:"require_mfa"`
+}
+
+// RoleResponse represents a role in responses
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	RequireMFA  bool     `json:"require_mfa"`
+	BuiltIn     bool     `json:"built_in"`
+	Customized  bool     `json:"customized"` // A built-in role whose permissions the tenant changed
+}
@@ -0,0 +1,66 @@
+package dto
+
+import "time"
+
+// CreateStockTakeRequest represents a request to open a stock take. Without a category the
+// whole store is counted.
+type CreateStockTakeRequest struct {
+	StoreID    *string `json:"store_id"`
+	CategoryID *string `json:"category_id" validate:"omitempty,uuid"`
+	Notes      string  `json:"notes" validate:"max=500"`
+}
+
+// StockCountRequest represents the counted quantity of one product
+type StockCountRequest struct {
+	ProductID string `json:"product_id" validate:"required"`
+	Quantity  *int   `json:"quantity" validate:"required,gte=0"`
+}
+
+// SubmitStockCountsRequest represents counted quantities submitted from one device. In
+// "replace" mode (the default) a count overwrites the product's previous count; in "add" mode
+// it is added to it, for products counted in more than one place.
+type SubmitStockCountsRequest struct {
+	Mode  string              `json:"mode" validate:"omitempty,oneof=replace add"`
+	Items []StockCountRequest `json:"items" validate:"required,min=1,max=500,dive"`
+}
+
+// StockTakeItemResponse represents one product in a stock take
+type StockTakeItemResponse struct {
+	ProductID       string     `json:"product_id"`
+	ProductName     string     `json:"product_name"`
+	ProductSKU      string     `json:"product_sku"`
+	ExpectedStock   int        `json:"expected_stock"`
+	CountedQuantity *int       `json:"counted_quantity"`
+	Variance        *int       `json:"variance"`
+	VarianceValue   *float64   `json:"variance_value"`
+	CountedBy       string     `json:"counted_by,omitempty"`
+	CountedAt       *time.Time `json:"counted_at,omitempty"`
+}
+
+// StockTakeResponse represents a stock take in responses
+type StockTakeResponse struct {
+	ID               string                  `json:"id"`
+	Reference        string                  `json:"reference"`
+	StoreID          *string                 `json:"store_id,omitempty"`
+	CategoryID       *string                 `json:"category_id,omitempty"`
+	CategoryName     string                  `json:"category_name,omitempty"`
+	Status           string                  `json:"status"`
+	Notes            string                  `json:"notes"`
+	ItemCount        int                     `json:"item_count"`
+	CountedCount     int                     `json:"counted_count"`
+	VarianceQuantity int                     `json:"variance_quantity"`
+	VarianceValue    float64                 `json:"variance_value"`
+	CreatedBy        string                  `json:"created_by,omitempty"`
+	ApprovedBy       string                  `json:"approved_by,omitempty"`
+	ApprovedAt       *time.Time              `json:"approved_at,omitempty"`
+	CreatedAt        time.Time               `json:"created_at"`
+	UpdatedAt        time.Time               `json:"updated_at"`
+	Items            []StockTakeItemResponse `json:"items,omitempty"`
+}
+
+// StockTakeListFilter represents filters for stock take listing
+type StockTakeListFilter struct {
+	StoreID    string `form:"store_id"`
+	CategoryID string `form:"category_id"`
+	Status     string `form:"status" validate:"omitempty,oneof=counting approved cancelled"`
+}
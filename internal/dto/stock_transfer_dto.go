@@ -0,0 +1,98 @@
+package dto
+
+import "time"
+
+// StockTransferItemRequest represents a product and quantity requested on a transfer
+type StockTransferItemRequest struct {
+	ProductID string `json:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" validate:"required,gt=0"`
+}
+
+// CreateStockTransferRequest represents a request to move stock between stores. A user signed
+// in to a store requests stock for it when ToStoreID is left out.
+type CreateStockTransferRequest struct {
+	FromStoreID string                     `json:"from_store_id" validate:"required"`
+	ToStoreID   string                     `json:"to_store_id"`
+	Notes       string                     `json:"notes" validate:"max=500"`
+	Items       []StockTransferItemRequest `json:"items" validate:"required,min=1,max=500,dive"`
+}
+
+// TransferQuantityRequest represents the quantity of one product shipped or received
+type TransferQuantityRequest struct {
+	ProductID string `json:"product_id" validate:"required"`
+	Quantity  *int   `json:"quantity" validate:"required,gte=0"`
+}
+
+// ShipStockTransferRequest represents a shipment. Products that are left out ship the
+// requested quantity.
+type ShipStockTransferRequest struct {
+	Items []TransferQuantityRequest `json:"items" validate:"max=500,dive"`
+}
+
+// ReceiveStockTransferRequest represents goods received. Products that are left out are
+// received as shipped; a lower quantity is recorded as a discrepancy and a higher one is
+// rejected.
+type ReceiveStockTransferRequest struct {
+	Items []TransferQuantityRequest `json:"items" validate:"max=500,dive"`
+}
+
+// StockTransferItemResponse represents one product on a transfer
+type StockTransferItemResponse struct {
+	ProductID         string   `json:"product_id"`
+	ProductName       string   `json:"product_name"`
+	ProductSKU        string   `json:"product_sku"`
+	RequestedQuantity int      `json:"requested_quantity"`
+	ShippedQuantity   *int     `json:"shipped_quantity"`
+	ReceivedQuantity  *int     `json:"received_quantity"`
+	Discrepancy       *int     `json:"discrepancy"`
+	UnitCost          float64  `json:"unit_cost"`
+	DiscrepancyValue  *float64 `json:"discrepancy_value"`
+}
+
+// StockTransferResponse represents a stock transfer in responses
+type StockTransferResponse struct {
+	ID                  string                      `json:"id"`
+	Reference           string                      `json:"reference"`
+	FromStoreID         string                      `json:"from_store_id"`
+	FromStoreName       string                      `json:"from_store_name"`
+	ToStoreID           string                      `json:"to_store_id"`
+	ToStoreName         string                      `json:"to_store_name"`
+	Status              string                      `json:"status"`
+	Notes               string                      `json:"notes"`
+	ItemCount           int                         `json:"item_count"`
+	RequestedQuantity   int                         `json:"requested_quantity"`
+	ShippedQuantity     int                         `json:"shipped_quantity"`
+	ReceivedQuantity    int                         `json:"received_quantity"`
+	DiscrepancyQuantity int                         `json:"discrepancy_quantity"`
+	ShippedValue        float64                     `json:"shipped_value"`
+	RequestedBy         string                      `json:"requested_by,omitempty"`
+	ShippedBy           string                      `json:"shipped_by,omitempty"`
+	ShippedAt           *time.Time                  `json:"shipped_at,omitempty"`
+	ReceivedBy          string                      `json:"received_by,omitempty"`
+	ReceivedAt          *time.Time                  `json:"received_at,omitempty"`
+	CreatedAt           time.Time                   `json:"created_at"`
+	UpdatedAt           time.Time                   `json:"updated_at"`
+	Items               []StockTransferItemResponse `json:"items,omitempty"`
+}
+
+// StockTransferListFilter represents filters for stock transfer listing. StoreID matches
+// transfers out of or into the store.
+type StockTransferListFilter struct {
+	StoreID     string `form:"store_id"`
+	FromStoreID string `form:"from_store_id"`
+	ToStoreID   string `form:"to_store_id"`
+	Status      string `form:"status" validate:"omitempty,oneof=requested in_transit received cancelled"`
+}
+
+// InTransitTransferReport summarises stock shipped but not yet received between two stores,
+// valued at cost
+type InTransitTransferReport struct {
+	FromStoreID     string     `json:"from_store_id"`
+	FromStoreName   string     `json:"from_store_name"`
+	ToStoreID       string     `json:"to_store_id"`
+	ToStoreName     string     `json:"to_store_name"`
+	Transfers       int        `json:"transfers"`
+	Quantity        int        `json:"quantity"`
+	Value           float64    `json:"value"`
+	OldestShippedAt *time.Time `json:"oldest_shipped_at,omitempty"`
+}
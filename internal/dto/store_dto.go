@@ -0,0 +1,62 @@
+package dto
+
+import "time"
+
+// CreateStoreRequest represents a request to create a store
+type CreateStoreRequest struct {
+	Code    string `json:"code" validate:"required,min=2,max=20"`
+	Name    string `json:"name" validate:"required,min=2,max=200"`
+	Address string `json:"address" validate:"max=500"`
+	Phone   string `json:"phone" validate:"omitempty,min=6,max=20"`
+}
+
+// UpdateStoreRequest represents a request to update a store
+type UpdateStoreRequest struct {
+	Name     *string `json:"name" validate:"omitempty,min=2,max=200"`
+	Address  *string `json:"address" validate:"omitempty,max=500"`
+	Phone    *string `json:"phone" validate:"omitempty,min=6,max=20"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// StoreResponse represents a store in responses
+type StoreResponse struct {
+	ID        string    `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	Phone     string    `json:"phone"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StoreListFilter represents filters for store listing. Mine limits the list to the caller's
+// assigned stores; UserID is set from the caller, not bound from the query.
+type StoreListFilter struct {
+	Search   string `form:"search"`
+	IsActive *bool  `form:"is_active"`
+	Mine     bool   `form:"mine"`
+	UserID   string `form:"-"`
+}
+
+// StorePriceRequest sets or, with a null price, removes a product's price at a store
+type StorePriceRequest struct {
+	ProductID string   `json:"product_id" validate:"required"`
+	Price     *float64 `json:"price" validate:"omitempty,gte=0"`
+}
+
+// SetStorePricesRequest represents a request to override product prices at a store
+type SetStorePricesRequest struct {
+	Items []StorePriceRequest `json:"items" validate:"required,min=1,max=500,dive"`
+}
+
+// AssignUserStoresRequest replaces the stores a user works at. An empty list lets the user
+// work at any store.
+type AssignUserStoresRequest struct {
+	StoreIDs []string `json:"store_ids" validate:"max=50,dive,required"`
+}
+
+// SwitchStoreRequest represents a request to move the current session to another store
+type SwitchStoreRequest struct {
+	StoreID string `json:"store_id" validate:"required"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+// SystemHealthResponse reports the health of the API's dependencies and the tenant's terminals.
+// Status is ok, or degraded when a check failed.
+type SystemHealthResponse struct {
+	Status       string              `json:"status"`
+	Version      string              `json:"version"`
+	Uptime       int64               `json:"uptime_seconds"`
+	CheckedAt    time.Time           `json:"checked_at"`
+	Database     DatabaseHealth      `json:"database"`
+	Integrations []IntegrationHealth `json:"integrations"`
+	Terminals    []*TerminalResponse `json:"terminals"`
+	Online       int                 `json:"terminals_online"`
+	Offline      int                 `json:"terminals_offline"`
+}
+
+// DatabaseHealth reports a database ping and the connection pool
+type DatabaseHealth struct {
+	Status          string `json:"status"`
+	Error           string `json:"error,omitempty"`
+	LatencyMS       int64  `json:"latency_ms"`
+	OpenConnections int    `json:"open_connections"`
+	InUse           int    `json:"in_use"`
+	Idle            int    `json:"idle"`
+	MaxOpen         int    `json:"max_open_connections"`
+	WaitCount       int64  `json:"wait_count"`       // Connections waited for since start
+	WaitMS          int64  `json:"wait_duration_ms"` // Total time spent waiting for them
+}
+
+// IntegrationHealth reports a check of an external service
+type IntegrationHealth struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
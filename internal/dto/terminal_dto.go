@@ -0,0 +1,63 @@
+package dto
+
+import "time"
+
+// CreateTerminalRequest represents registering a POS terminal or kitchen display at a store.
+// Type defaults to pos.
+type CreateTerminalRequest struct {
+	StoreID string `json:"store_id" validate:"required,uuid"`
+	Name    string `json:"name" validate:"required,min=2,max=100"`
+	Type    string `json:"type" validate:"omitempty,oneof=pos kds"`
+}
+
+// UpdateTerminalRequest represents renaming, deactivating or reactivating a terminal
+type UpdateTerminalRequest struct {
+	Name     *string `json:"name" validate:"omitempty,min=2,max=100"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// TerminalResponse represents a terminal in responses. Key is only returned when it is
+// issued; the terminal sends it in the X-Terminal-Key header. Status is online, offline or
+// inactive.
+type TerminalResponse struct {
+	ID         string     `json:"id"`
+	StoreID    string     `json:"store_id"`
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	IsActive   bool       `json:"is_active"`
+	Status     string     `json:"status"`
+	Key        string     `json:"key,omitempty"`
+	AppVersion string     `json:"app_version"`
+	LatencyMS  *int       `json:"latency_ms,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// HeartbeatRequest is sent by a terminal every few seconds. LatencyMS is the round trip of its
+// previous heartbeat as the terminal measured it.
+type HeartbeatRequest struct {
+	AppVersion string `json:"app_version" validate:"max=50"`
+	LatencyMS  *int   `json:"latency_ms" validate:"omitempty,min=0,max=600000"`
+}
+
+// HeartbeatResponse tells the terminal when to send its next heartbeat
+type HeartbeatResponse struct {
+	TerminalID      string    `json:"terminal_id"`
+	ServerTime      time.Time `json:"server_time"`
+	NextHeartbeatIn int       `json:"next_heartbeat_in"` // Seconds
+}
+
+// TerminalUserResponse represents a user who can sign in to a terminal with their PIN
+type TerminalUserResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// PINLoginRequest represents a PIN sign-in on a registered terminal
+type PINLoginRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid"`
+	PIN    string `json:"pin" validate:"required,numeric,min=4,max=6"`
+}
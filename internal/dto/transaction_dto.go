@@ -8,18 +8,32 @@ type CreateTransactionRequest struct {
 	PaymentMethod  string                     `json:"payment_method" validate:"required,oneof=cash card ewallet"`
 	DiscountAmount float64                    `json:"discount_amount" validate:"gte=0"`
 	Notes          string                     `json:"notes" validate:"max=500"`
+	VoucherCode    string                     `json:"voucher_code" validate:"omitempty,max=50"`
+	RedeemPoints   int                        `json:"redeem_points" validate:"gte=0"`
 	Items          []CreateTransactionItemDTO `json:"items" validate:"required,min=1,dive"`
+	// DiscountOverrideToken is a manager's approval of discount_amount for users who may not
+	// give discounts themselves
+	DiscountOverrideToken string `json:"discount_override_token"`
 }
 
 // CreateTransactionItemDTO represents a line item in a transaction request
 type CreateTransactionItemDTO struct {
-	ProductID string `json:"product_id" validate:"required,uuid"`
-	Quantity  int    `json:"quantity" validate:"required,gt=0"`
+	ProductID   string   `json:"product_id" validate:"required,uuid"`
+	VariantID   string   `json:"variant_id" validate:"omitempty,uuid"`
+	ModifierIDs []string `json:"modifier_ids" validate:"omitempty,dive,uuid"`
+	Quantity    int      `json:"quantity" validate:"required,gt=0"`
+	// Barcode is the scanned code; weight or price embedded labels set the item price
+	Barcode string `json:"barcode" validate:"omitempty,max=32"`
+	// PriceOverride sells the item at another unit price; users who may not override prices
+	// need a manager's approval in OverrideToken
+	PriceOverride *float64 `json:"price_override" validate:"omitempty,gte=0"`
+	OverrideToken string   `json:"override_token"`
 }
 
 // UpdateTransactionStatusRequest represents a request to update transaction status
 type UpdateTransactionStatusRequest struct {
-	Status string `json:"status" validate:"required,oneof=completed cancelled refunded"`
+	Status        string `json:"status" validate:"required,oneof=completed cancelled refunded"`
+	OverrideToken string `json:"override_token"` // A manager's approval, for users who may not void or refund
 }
 
 // TransactionResponse represents a transaction in responses
@@ -35,6 +49,10 @@ type TransactionResponse struct {
 	PaymentMethod  string                    `json:"payment_method"`
 	Status         string                    `json:"status"`
 	Notes          string                    `json:"notes,omitempty"`
+	VoucherCode    string                    `json:"voucher_code,omitempty"`
+	PointsEarned   int                       `json:"points_earned"`
+	PointsRedeemed int                       `json:"points_redeemed"`
+	StoreID        *string                   `json:"store_id,omitempty"`
 	CreatedAt      time.Time                 `json:"created_at"`
 	UpdatedAt      time.Time                 `json:"updated_at"`
 	User           *UserResponse             `json:"user,omitempty"`
@@ -44,16 +62,28 @@ type TransactionResponse struct {
 
 // TransactionItemResponse represents a transaction item in responses
 type TransactionItemResponse struct {
-	ID          string  `json:"id"`
-	ProductID   string  `json:"product_id"`
-	ProductName string  `json:"product_name"`
-	UnitPrice   float64 `json:"unit_price"`
-	Quantity    int     `json:"quantity"`
-	Subtotal    float64 `json:"subtotal"`
+	ID          string                            `json:"id"`
+	ProductID   string                            `json:"product_id"`
+	ProductName string                            `json:"product_name"`
+	VariantID   *string                           `json:"variant_id,omitempty"`
+	VariantName string                            `json:"variant_name,omitempty"`
+	UnitPrice   float64                           `json:"unit_price"`
+	Quantity    int                               `json:"quantity"`
+	Subtotal    float64                           `json:"subtotal"`
+	Modifiers   []TransactionItemModifierResponse `json:"modifiers,omitempty"`
+}
+
+// TransactionItemModifierResponse represents a modifier chosen for a transaction item
+type TransactionItemModifierResponse struct {
+	ModifierID string  `json:"modifier_id"`
+	GroupName  string  `json:"group_name"`
+	Name       string  `json:"name"`
+	PriceDelta float64 `json:"price_delta"`
 }
 
 // TransactionListFilter represents filters for transaction listing
 type TransactionListFilter struct {
+	StoreID       string `form:"store_id"`
 	UserID        string `form:"user_id"`
 	CustomerID    string `form:"customer_id"`
 	Status        string `form:"status"`
@@ -92,3 +122,19 @@ type TopProductReport struct {
 	TotalSold   int     `json:"total_sold"`
 	TotalAmount float64 `json:"total_amount"`
 }
+
+// HoldTransactionRequest represents parking a cart at the POS
+type HoldTransactionRequest struct {
+	CustomerID   *string               `json:"customer_id" validate:"omitempty,uuid"`
+	CustomerName string                `json:"customer_name" validate:"max=200"`
+	Items        []HoldTransactionItem `json:"items" validate:"required,min=1,dive"`
+	TotalAmount  float64               `json:"total_amount" validate:"gte=0"`
+	Notes        string                `json:"notes" validate:"max=500"`
+}
+
+// HoldTransactionItem represents a line of a held cart
+type HoldTransactionItem struct {
+	ProductID string `json:"product_id" validate:"required"`
+	VariantID string `json:"variant_id" validate:"omitempty,uuid"`
+	Quantity  int    `json:"quantity" validate:"required,gt=0"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// TrashListFilter represents filters for listing soft-deleted records
+type TrashListFilter struct {
+	Type string `form:"type" validate:"omitempty,oneof=products categories customers users"`
+}
+
+// TrashItemResponse represents a soft-deleted record in responses
+type TrashItemResponse struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// TrashPurgeResponse represents the number of records permanently removed per type
+type TrashPurgeResponse struct {
+	DeletedBefore time.Time      `json:"deleted_before"`
+	Purged        map[string]int `json:"purged"`
+}
+
+// PurgeTrashRequest represents a request to permanently remove old soft-deleted records
+type PurgeTrashRequest struct {
+	OlderThanDays *int `json:"older_than_days" validate:"omitempty,gte=0"`
+}
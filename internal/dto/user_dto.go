@@ -6,14 +6,14 @@ type CreateUserRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8"`
 	Phone    string `json:"phone" validate:"omitempty"`
-	Role     string `json:"role" validate:"required,oneof=admin manager cashier"`
+	Role     string `json:"role" validate:"required,max=50"`
 }
 
 // UpdateUserRequest represents an update user request
 type UpdateUserRequest struct {
 	Name     string `json:"name" validate:"omitempty,min=2,max=100"`
 	Phone    string `json:"phone" validate:"omitempty"`
-	Role     string `json:"role" validate:"omitempty,oneof=admin manager cashier"`
+	Role     string `json:"role" validate:"omitempty,max=50"`
 	IsActive *bool  `json:"is_active" validate:"omitempty"`
 }
 
@@ -30,6 +30,7 @@ type UserListResponse struct {
 	Phone       string `json:"phone,omitempty"`
 	Role        string `json:"role"`
 	IsActive    bool   `json:"is_active"`
+	MFAEnabled  bool   `json:"mfa_enabled"`
 	LastLoginAt string `json:"last_login_at,omitempty"`
 	CreatedAt   string `json:"created_at"`
 }
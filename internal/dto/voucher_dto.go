@@ -0,0 +1,81 @@
+package dto
+
+import "time"
+
+// GenerateVouchersRequest represents a request to generate a batch of voucher codes
+type GenerateVouchersRequest struct {
+	Prefix          string     `json:"prefix" validate:"omitempty,alphanum,max=10"`
+	Quantity        int        `json:"quantity" validate:"required,gt=0,lte=1000"`
+	DiscountType    string     `json:"discount_type" validate:"required,oneof=percentage fixed"`
+	DiscountValue   float64    `json:"discount_value" validate:"required,gt=0"`
+	MaxDiscount     float64    `json:"max_discount" validate:"gte=0"`
+	MinSpend        float64    `json:"min_spend" validate:"gte=0"`
+	MaxUses         int        `json:"max_uses" validate:"gte=0"`
+	UsesPerCustomer int        `json:"uses_per_customer" validate:"gte=0"`
+	ValidFrom       *time.Time `json:"valid_from"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+}
+
+// UpdateVoucherRequest represents a request to update a voucher
+type UpdateVoucherRequest struct {
+	IsActive  *bool      `json:"is_active"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// ValidateVoucherRequest represents a request to check a voucher before checkout
+type ValidateVoucherRequest struct {
+	Code       string  `json:"code" validate:"required,max=50"`
+	Subtotal   float64 `json:"subtotal" validate:"gte=0"`
+	CustomerID *string `json:"customer_id" validate:"omitempty,uuid"`
+}
+
+// VoucherResponse represents a voucher in responses
+type VoucherResponse struct {
+	ID              string     `json:"id"`
+	Code            string     `json:"code"`
+	BatchID         string     `json:"batch_id"`
+	DiscountType    string     `json:"discount_type"`
+	DiscountValue   float64    `json:"discount_value"`
+	MaxDiscount     float64    `json:"max_discount"`
+	MinSpend        float64    `json:"min_spend"`
+	MaxUses         int        `json:"max_uses"`
+	UsesPerCustomer int        `json:"uses_per_customer"`
+	UsedCount       int        `json:"used_count"`
+	ValidFrom       *time.Time `json:"valid_from,omitempty"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	IsActive        bool       `json:"is_active"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// VoucherBatchResponse represents the result of a batch generation
+type VoucherBatchResponse struct {
+	BatchID  string             `json:"batch_id"`
+	Quantity int                `json:"quantity"`
+	Vouchers []*VoucherResponse `json:"vouchers"`
+}
+
+// VoucherValidationResponse represents the outcome of a voucher check
+type VoucherValidationResponse struct {
+	Code           string  `json:"code"`
+	DiscountAmount float64 `json:"discount_amount"`
+}
+
+// VoucherRedemptionResponse represents a voucher redemption in responses
+type VoucherRedemptionResponse struct {
+	ID             string    `json:"id"`
+	VoucherID      string    `json:"voucher_id"`
+	TransactionID  string    `json:"transaction_id"`
+	InvoiceNumber  string    `json:"invoice_number,omitempty"`
+	CustomerID     *string   `json:"customer_id,omitempty"`
+	UserID         string    `json:"user_id"`
+	DiscountAmount float64   `json:"discount_amount"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// VoucherListFilter represents filters for voucher listing
+type VoucherListFilter struct {
+	BatchID  string `form:"batch_id"`
+	Search   string `form:"search"`
+	IsActive *bool  `form:"is_active"`
+}
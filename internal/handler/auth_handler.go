@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -43,12 +45,91 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.Login(c.Request.Context(), &req)
+	resp, err := h.authService.Login(c.Request.Context(), &req, clientInfo(c))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusUnauthorized, err.Error())
+		respondLoginError(c, err)
 		return
 	}
 
+	// The password was right, but no session is issued until the second factor is verified
+	if resp.MFA != nil {
+		utils.SuccessResponse(c, http.StatusOK, "Two-factor authentication required", gin.H{
+			"user":         resp.User,
+			"mfa_required": true,
+			"mfa":          resp.MFA,
+			"store_id":     resp.StoreID,
+			"tenant_id":    resp.TenantID,
+		})
+		return
+	}
+
+	h.respondSignedIn(c, "Login successful", resp)
+}
+
+// SetupMFA handles POST /api/v1/auth/mfa/setup, which starts two-factor enrollment for a
+// sign-in whose user's role requires it
+func (h *AuthHandler) SetupMFA(c *gin.Context) {
+	var req dto.MFAChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	resp, err := h.authService.SetupMFA(c.Request.Context(), req.MFAToken)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Scan the QR code with your authenticator app", resp)
+}
+
+// VerifyMFA handles POST /api/v1/auth/mfa/verify
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req dto.VerifyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	resp, err := h.authService.VerifyMFA(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	h.respondSignedIn(c, "Login successful", resp)
+}
+
+// respondLoginError answers a failed password sign-in. Locked and delayed sign-ins get 429
+// with Retry-After.
+func respondLoginError(c *gin.Context, err error) {
+	var locked *service.LoginLockedError
+	if errors.As(err, &locked) {
+		c.Header("Retry-After", strconv.Itoa(locked.RetrySeconds()))
+		utils.ErrorResponse(c, http.StatusTooManyRequests, locked.Error())
+		return
+	}
+	utils.ErrorResponse(c, http.StatusUnauthorized, err.Error())
+}
+
+// clientInfo identifies the client of a sign-in for the audit log and IP lockout
+func clientInfo(c *gin.Context) dto.ClientInfo {
+	return dto.ClientInfo{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+// respondSignedIn sets the session cookies and returns the signed-in user
+func (h *AuthHandler) respondSignedIn(c *gin.Context, message string, resp *dto.AuthResponse) {
 	// Set httpOnly cookies
 	utils.SetAuthCookies(
 		c,
@@ -60,13 +141,19 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	)
 
 	// Return user info (tokens are in cookies, not in response for security)
-	utils.SuccessResponse(c, http.StatusOK, "Login successful", gin.H{
+	data := gin.H{
 		"user": resp.User,
 		"token": gin.H{
 			"expires_in": resp.Token.ExpiresIn,
 			"token_type": resp.Token.TokenType,
 		},
-	})
+		"store_id":  resp.StoreID,
+		"tenant_id": resp.TenantID,
+	}
+	if resp.RecoveryCodes != nil {
+		data["recovery_codes"] = resp.RecoveryCodes
+	}
+	utils.SuccessResponse(c, http.StatusOK, message, data)
 }
 
 // Register handles POST /api/v1/auth/register
@@ -104,6 +191,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 			"expires_in": resp.Token.ExpiresIn,
 			"token_type": resp.Token.TokenType,
 		},
+		"tenant_id": resp.TenantID,
 	})
 }
 
@@ -152,6 +240,33 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Profile updated successfully", resp)
 }
 
+// SetPIN handles PUT /api/v1/auth/me/pin
+func (h *AuthHandler) SetPIN(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.SetPINRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	if err := h.authService.SetPIN(c.Request.Context(), claims.UserID, &req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "PIN updated successfully", nil)
+}
+
 // GetActivityLog handles GET /api/v1/auth/me/activity
 func (h *AuthHandler) GetActivityLog(c *gin.Context) {
 	claims := middleware.GetCurrentUser(c)
@@ -160,18 +275,15 @@ func (h *AuthHandler) GetActivityLog(c *gin.Context) {
 		return
 	}
 
-	// Return mock activity data for now
-	activities := []gin.H{
-		{
-			"id":         "1",
-			"action":     "User Login",
-			"device_ip":  c.ClientIP() + " (Browser)",
-			"status":     "success",
-			"created_at": time.Now().Format(time.RFC3339),
-		},
+	pagination := utils.GetPagination(c)
+	events, total, err := h.authService.ActivityLog(c.Request.Context(), claims.UserID, pagination)
+	if err != nil {
+		utils.ListError(c, err)
+		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Activity log retrieved", activities)
+	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	utils.SuccessWithMeta(c, "Activity log retrieved", events, meta)
 }
 
 // Refresh handles POST /api/v1/auth/refresh
@@ -213,6 +325,51 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 	})
 }
 
+// SwitchStore handles POST /api/v1/auth/store
+func (h *AuthHandler) SwitchStore(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+	if claims.TerminalID != "" {
+		utils.Forbidden(c, "PIN sessions stay at the terminal's store")
+		return
+	}
+
+	var req dto.SwitchStoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	resp, err := h.authService.SwitchStore(c.Request.Context(), claims.UserID, req.StoreID)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SetAuthCookies(
+		c,
+		resp.AccessToken,
+		resp.RefreshToken,
+		h.jwtExpiry,
+		h.refreshExpiry,
+		h.cookieConfig,
+	)
+
+	utils.SuccessResponse(c, http.StatusOK, "Store switched successfully", gin.H{
+		"store_id":   req.StoreID,
+		"expires_in": resp.ExpiresIn,
+		"token_type": resp.TokenType,
+	})
+}
+
 // Logout handles POST /api/v1/auth/logout
 func (h *AuthHandler) Logout(c *gin.Context) {
 	// Clear cookies
@@ -25,7 +25,7 @@ func (h *CategoryHandler) List(c *gin.Context) {
 
 	categories, total, err := h.categoryService.List(c.Request.Context(), pagination)
 	if err != nil {
-		utils.InternalServerError(c, err.Error())
+		utils.ListError(c, err)
 		return
 	}
 
@@ -1,22 +1,28 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/middleware"
 	"github.com/ilramdhan/pos-api/internal/service"
 	"github.com/ilramdhan/pos-api/internal/utils"
 )
 
 // CustomerHandler handles customer endpoints
 type CustomerHandler struct {
-	customerService *service.CustomerService
+	customerService    *service.CustomerService
+	transactionService *service.TransactionService
 }
 
 // NewCustomerHandler creates a new customer handler
-func NewCustomerHandler(customerService *service.CustomerService) *CustomerHandler {
-	return &CustomerHandler{customerService: customerService}
+func NewCustomerHandler(customerService *service.CustomerService, transactionService *service.TransactionService) *CustomerHandler {
+	return &CustomerHandler{
+		customerService:    customerService,
+		transactionService: transactionService,
+	}
 }
 
 // List handles GET /api/v1/customers
@@ -29,9 +35,14 @@ func (h *CustomerHandler) List(c *gin.Context) {
 		return
 	}
 
+	if errors, ok := utils.Validate(&filter); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
 	customers, total, err := h.customerService.List(c.Request.Context(), filter, pagination)
 	if err != nil {
-		utils.InternalServerError(c, err.Error())
+		utils.ListError(c, err)
 		return
 	}
 
@@ -52,6 +63,49 @@ func (h *CustomerHandler) Get(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Customer retrieved successfully", customer)
 }
 
+// Stats handles GET /api/v1/customers/:id/stats
+func (h *CustomerHandler) Stats(c *gin.Context) {
+	id := c.Param("id")
+
+	stats, err := h.customerService.GetStats(c.Request.Context(), id)
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Customer stats retrieved successfully", stats)
+}
+
+// Transactions handles GET /api/v1/customers/:id/transactions
+func (h *CustomerHandler) Transactions(c *gin.Context) {
+	id := c.Param("id")
+	pagination := utils.GetPagination(c)
+
+	if _, err := h.customerService.GetByID(c.Request.Context(), id); err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	var filter dto.TransactionListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
+	}
+	filter.CustomerID = id
+
+	transactions, total, err := h.transactionService.List(c.Request.Context(), filter, pagination)
+	if err != nil {
+		utils.ListError(c, err)
+		return
+	}
+
+	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	if n := len(transactions); n > 0 {
+		meta.WithCursor(pagination, n, transactions[n-1].CreatedAt, transactions[n-1].ID)
+	}
+	utils.SuccessWithMeta(c, "Customer transactions retrieved successfully", transactions, meta)
+}
+
 // Create handles POST /api/v1/customers
 func (h *CustomerHandler) Create(c *gin.Context) {
 	var req dto.CreateCustomerRequest
@@ -67,6 +121,11 @@ func (h *CustomerHandler) Create(c *gin.Context) {
 
 	customer, err := h.customerService.Create(c.Request.Context(), &req)
 	if err != nil {
+		var dupErr *service.DuplicateCustomerError
+		if errors.As(err, &dupErr) {
+			utils.Conflict(c, dupErr.Error(), dupErr.Matches)
+			return
+		}
 		utils.BadRequest(c, err.Error())
 		return
 	}
@@ -91,6 +150,11 @@ func (h *CustomerHandler) Update(c *gin.Context) {
 
 	customer, err := h.customerService.Update(c.Request.Context(), id, &req)
 	if err != nil {
+		var dupErr *service.DuplicateCustomerError
+		if errors.As(err, &dupErr) {
+			utils.Conflict(c, dupErr.Error(), dupErr.Matches)
+			return
+		}
 		utils.BadRequest(c, err.Error())
 		return
 	}
@@ -109,3 +173,63 @@ func (h *CustomerHandler) Delete(c *gin.Context) {
 
 	utils.SuccessResponse(c, http.StatusOK, "Customer deleted successfully", nil)
 }
+
+// Duplicates handles GET /api/v1/customers/duplicates
+func (h *CustomerHandler) Duplicates(c *gin.Context) {
+	var filter dto.CustomerDuplicateFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	customers, err := h.customerService.FindDuplicates(c.Request.Context(), filter)
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Duplicate customers retrieved successfully", customers)
+}
+
+// Merge handles POST /api/v1/customers/:id/merge
+func (h *CustomerHandler) Merge(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id := c.Param("id")
+
+	var req dto.MergeCustomersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	result, err := h.customerService.Merge(c.Request.Context(), id, claims.UserID, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Customers merged successfully", result)
+}
+
+// Merges handles GET /api/v1/customers/:id/merges
+func (h *CustomerHandler) Merges(c *gin.Context) {
+	id := c.Param("id")
+
+	merges, err := h.customerService.ListMerges(c.Request.Context(), id)
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Customer merges retrieved successfully", merges)
+}
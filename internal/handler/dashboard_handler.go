@@ -18,6 +18,7 @@ type DashboardHandler struct {
 	categoryService    *service.CategoryService
 	customerService    *service.CustomerService
 	reportService      *service.ReportService
+	systemService      *service.SystemService
 }
 
 // NewDashboardHandler creates a new dashboard handler
@@ -27,6 +28,7 @@ func NewDashboardHandler(
 	categoryService *service.CategoryService,
 	customerService *service.CustomerService,
 	reportService *service.ReportService,
+	systemService *service.SystemService,
 ) *DashboardHandler {
 	return &DashboardHandler{
 		transactionService: transactionService,
@@ -34,6 +36,7 @@ func NewDashboardHandler(
 		categoryService:    categoryService,
 		customerService:    customerService,
 		reportService:      reportService,
+		systemService:      systemService,
 	}
 }
 
@@ -42,10 +45,11 @@ func (h *DashboardHandler) GetDashboardStats(c *gin.Context) {
 	ctx := c.Request.Context()
 	today := time.Now().Format("2006-01-02")
 	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	storeID := storeScope(c, c.Query("store_id"))
 
 	// Get today's sales
-	todaySales, _ := h.reportService.GetDailySales(ctx, today, today)
-	yesterdaySales, _ := h.reportService.GetDailySales(ctx, yesterday, yesterday)
+	todaySales, _ := h.reportService.GetDailySales(ctx, storeID, today, today)
+	yesterdaySales, _ := h.reportService.GetDailySales(ctx, storeID, yesterday, yesterday)
 
 	var todayAmount float64
 	var yesterdayAmount float64
@@ -155,22 +159,13 @@ func (h *DashboardHandler) GetRecentTransactions(c *gin.Context) {
 
 // GetSystemHealth handles GET /api/v1/system/health/detailed
 func (h *DashboardHandler) GetSystemHealth(c *gin.Context) {
-	utils.SuccessResponse(c, http.StatusOK, "System health retrieved", gin.H{
-		"terminals": []gin.H{
-			{"id": "POS-01", "type": "pos", "latency_ms": 35, "status": "online"},
-			{"id": "POS-02", "type": "pos", "latency_ms": 42, "status": "online"},
-			{"id": "KDS-01", "type": "kds", "latency_ms": 32, "status": "online"},
-		},
-		"servers": []gin.H{
-			{"id": "Server A", "uptime_percent": 99.9, "status": "online"},
-			{"id": "Server B", "uptime_percent": 99.5, "status": "online"},
-		},
-		"integrations": []gin.H{
-			{"name": "Payment Gateway", "status": "ok"},
-			{"name": "Email Service", "status": "ok"},
-			{"name": "SMS Gateway", "status": "ok"},
-		},
-	})
+	health, err := h.systemService.Health(c.Request.Context())
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "System health retrieved", health)
 }
 
 // GetCustomerStats handles GET /api/v1/customers/stats
@@ -207,38 +202,30 @@ func (h *DashboardHandler) GetProductStats(c *gin.Context) {
 
 // GetStockMovements handles GET /api/v1/products/stock-movements
 func (h *DashboardHandler) GetStockMovements(c *gin.Context) {
-	// Return mock data for stock movements
-	movements := []gin.H{
-		{
-			"id":              "1",
-			"product_id":      "p1",
-			"product_name":    "Kopi Susu Gula Aren",
-			"sku":             "BEV-002",
-			"type":            "sale",
-			"quantity_change": -2,
-			"new_balance":     148,
-			"user":            "Cashier",
-			"created_at":      time.Now().Add(-1 * time.Hour).Format(time.RFC3339),
-		},
-		{
-			"id":              "2",
-			"product_id":      "p2",
-			"product_name":    "Nasi Goreng Spesial",
-			"sku":             "FOOD-001",
-			"type":            "restock",
-			"quantity_change": 50,
-			"new_balance":     150,
-			"user":            "Manager",
-			"created_at":      time.Now().Add(-2 * time.Hour).Format(time.RFC3339),
-		},
+	pagination := utils.GetPagination(c)
+
+	var filter dto.ProductStockMovementFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
 	}
+	if errors, ok := utils.Validate(&filter); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+	filter.StoreID = storeScope(c, filter.StoreID)
 
-	utils.SuccessWithMeta(c, "Stock movements retrieved", movements, &utils.Meta{
-		Page:       1,
-		PerPage:    10,
-		Total:      2,
-		TotalPages: 1,
-	})
+	movements, total, err := h.productService.StockMovements(c.Request.Context(), filter, pagination)
+	if err != nil {
+		utils.ListError(c, err)
+		return
+	}
+
+	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	if n := len(movements); n > 0 {
+		meta.WithCursor(pagination, n, movements[n-1].CreatedAt, movements[n-1].ID)
+	}
+	utils.SuccessWithMeta(c, "Stock movements retrieved", movements, meta)
 }
 
 // GetCategoryStats handles GET /api/v1/categories/stats
@@ -261,17 +248,19 @@ func (h *DashboardHandler) GetCategoryStats(c *gin.Context) {
 
 // GetCategoryActivityLog handles GET /api/v1/categories/activity-log
 func (h *DashboardHandler) GetCategoryActivityLog(c *gin.Context) {
-	activities := []gin.H{
-		{
-			"id":         "1",
-			"event":      "Created \"Personal Care\"",
-			"user":       "Admin",
-			"details":    "New category added",
-			"created_at": time.Now().Add(-24 * time.Hour).Format(time.RFC3339),
-		},
+	pagination := utils.GetPagination(c)
+
+	activities, total, err := h.categoryService.ActivityLog(c.Request.Context(), pagination)
+	if err != nil {
+		utils.ListError(c, err)
+		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Category activity log retrieved", activities)
+	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	if n := len(activities); n > 0 {
+		meta.WithCursor(pagination, n, activities[n-1].CreatedAt, activities[n-1].ID)
+	}
+	utils.SuccessWithMeta(c, "Category activity log retrieved", activities, meta)
 }
 
 // GetTransactionStats handles GET /api/v1/transactions/stats
@@ -279,10 +268,11 @@ func (h *DashboardHandler) GetTransactionStats(c *gin.Context) {
 	ctx := c.Request.Context()
 	today := time.Now().Format("2006-01-02")
 	monthStart := time.Now().Format("2006-01") + "-01"
+	storeID := storeScope(c, c.Query("store_id"))
 
 	// Get today's transactions
-	todaySales, _ := h.reportService.GetDailySales(ctx, today, today)
-	monthSales, _ := h.reportService.GetDailySales(ctx, monthStart, today)
+	todaySales, _ := h.reportService.GetDailySales(ctx, storeID, today, today)
+	monthSales, _ := h.reportService.GetDailySales(ctx, storeID, monthStart, today)
 
 	var todayTx, todayRevenue int
 	var totalTx int
@@ -317,7 +307,7 @@ func (h *DashboardHandler) GetWeeklySales(c *gin.Context) {
 	dateFrom := c.DefaultQuery("date_from", time.Now().AddDate(0, 0, -28).Format("2006-01-02"))
 	dateTo := c.DefaultQuery("date_to", time.Now().Format("2006-01-02"))
 
-	dailySales, _ := h.reportService.GetDailySales(c.Request.Context(), dateFrom, dateTo)
+	dailySales, _ := h.reportService.GetDailySales(c.Request.Context(), storeScope(c, c.Query("store_id")), dateFrom, dateTo)
 
 	// Aggregate by week
 	weeklyData := make(map[string]*struct {
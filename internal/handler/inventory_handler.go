@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/middleware"
+	"github.com/ilramdhan/pos-api/internal/service"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// InventoryHandler handles ingredient, recipe and unit endpoints
+type InventoryHandler struct {
+	inventoryService *service.InventoryService
+}
+
+// NewInventoryHandler creates a new inventory handler
+func NewInventoryHandler(inventoryService *service.InventoryService) *InventoryHandler {
+	return &InventoryHandler{inventoryService: inventoryService}
+}
+
+// Units handles GET /api/v1/units
+func (h *InventoryHandler) Units(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Units retrieved successfully", h.inventoryService.Units())
+}
+
+// List handles GET /api/v1/ingredients
+func (h *InventoryHandler) List(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	var filter dto.IngredientListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	ingredients, total, err := h.inventoryService.ListIngredients(c.Request.Context(), filter, pagination)
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	utils.SuccessWithMeta(c, "Ingredients retrieved successfully", ingredients, meta)
+}
+
+// Get handles GET /api/v1/ingredients/:id
+func (h *InventoryHandler) Get(c *gin.Context) {
+	id := c.Param("id")
+
+	ingredient, err := h.inventoryService.GetIngredient(c.Request.Context(), id)
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ingredient retrieved successfully", ingredient)
+}
+
+// Create handles POST /api/v1/ingredients
+func (h *InventoryHandler) Create(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.CreateIngredientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	ingredient, err := h.inventoryService.CreateIngredient(c.Request.Context(), claims.UserID, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.CreatedResponse(c, "Ingredient created successfully", ingredient)
+}
+
+// Update handles PUT /api/v1/ingredients/:id
+func (h *InventoryHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.UpdateIngredientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	ingredient, err := h.inventoryService.UpdateIngredient(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ingredient updated successfully", ingredient)
+}
+
+// Delete handles DELETE /api/v1/ingredients/:id
+func (h *InventoryHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.inventoryService.DeleteIngredient(c.Request.Context(), id); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ingredient deleted successfully", nil)
+}
+
+// AdjustStock handles POST /api/v1/ingredients/:id/stock
+func (h *InventoryHandler) AdjustStock(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id := c.Param("id")
+
+	var req dto.AdjustIngredientStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	ingredient, err := h.inventoryService.AdjustStock(c.Request.Context(), id, claims.UserID, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ingredient stock updated successfully", ingredient)
+}
+
+// Movements handles GET /api/v1/ingredients/:id/movements
+func (h *InventoryHandler) Movements(c *gin.Context) {
+	id := c.Param("id")
+	pagination := utils.GetPagination(c)
+
+	movements, total, err := h.inventoryService.Movements(c.Request.Context(), id, pagination)
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		utils.ListError(c, err)
+		return
+	}
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	if n := len(movements); n > 0 {
+		meta.WithCursor(pagination, n, movements[n-1].CreatedAt, movements[n-1].ID)
+	}
+	utils.SuccessWithMeta(c, "Stock movements retrieved successfully", movements, meta)
+}
+
+// GetRecipe handles GET /api/v1/products/:id/recipe
+func (h *InventoryHandler) GetRecipe(c *gin.Context) {
+	id := c.Param("id")
+
+	recipe, err := h.inventoryService.GetRecipe(c.Request.Context(), id)
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Recipe retrieved successfully", recipe)
+}
+
+// SetRecipe handles PUT /api/v1/products/:id/recipe
+func (h *InventoryHandler) SetRecipe(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.SetRecipeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	recipe, err := h.inventoryService.SetRecipe(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Recipe updated successfully", recipe)
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/service"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// LicenseHandler handles license endpoints
+type LicenseHandler struct {
+	licenseService *service.LicenseService
+}
+
+// NewLicenseHandler creates a new license handler
+func NewLicenseHandler(licenseService *service.LicenseService) *LicenseHandler {
+	return &LicenseHandler{licenseService: licenseService}
+}
+
+// Status handles GET /api/v1/license
+func (h *LicenseHandler) Status(c *gin.Context) {
+	status, err := h.licenseService.Status(c.Request.Context())
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "License status retrieved successfully", status)
+}
+
+// Activate handles PUT /api/v1/license
+func (h *LicenseHandler) Activate(c *gin.Context) {
+	var req dto.ActivateLicenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	status, err := h.licenseService.Activate(c.Request.Context(), &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "License activated successfully", status)
+}
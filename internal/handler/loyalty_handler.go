@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/middleware"
+	"github.com/ilramdhan/pos-api/internal/service"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// LoyaltyHandler handles loyalty program endpoints
+type LoyaltyHandler struct {
+	loyaltyService *service.LoyaltyService
+}
+
+// NewLoyaltyHandler creates a new loyalty handler
+func NewLoyaltyHandler(loyaltyService *service.LoyaltyService) *LoyaltyHandler {
+	return &LoyaltyHandler{loyaltyService: loyaltyService}
+}
+
+// Rules handles GET /api/v1/loyalty/rules
+func (h *LoyaltyHandler) Rules(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Loyalty rules retrieved successfully", h.loyaltyService.Rules())
+}
+
+// Summary handles GET /api/v1/customers/:id/loyalty
+func (h *LoyaltyHandler) Summary(c *gin.Context) {
+	id := c.Param("id")
+
+	summary, err := h.loyaltyService.Summary(c.Request.Context(), id)
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Loyalty summary retrieved successfully", summary)
+}
+
+// Ledger handles GET /api/v1/customers/:id/loyalty/ledger
+func (h *LoyaltyHandler) Ledger(c *gin.Context) {
+	id := c.Param("id")
+	pagination := utils.GetPagination(c)
+
+	entries, total, err := h.loyaltyService.Ledger(c.Request.Context(), id, pagination)
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		utils.ListError(c, err)
+		return
+	}
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	if n := len(entries); n > 0 {
+		meta.WithCursor(pagination, n, entries[n-1].CreatedAt, entries[n-1].ID)
+	}
+	utils.SuccessWithMeta(c, "Loyalty ledger retrieved successfully", entries, meta)
+}
+
+// Adjust handles PATCH /api/v1/customers/:id/loyalty-points
+func (h *LoyaltyHandler) Adjust(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id := c.Param("id")
+
+	var req dto.UpdateLoyaltyPointsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	summary, err := h.loyaltyService.Adjust(c.Request.Context(), id, claims.UserID, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Loyalty points updated successfully", summary)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ilramdhan/pos-api/internal/service"
+	"github.com/ilramdhan/pos-api/internal/storage"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// multipartOverhead allows for form boundaries and headers on top of the image itself
+const multipartOverhead = 1 << 20
+
+// MediaHandler handles file upload and media serving endpoints
+type MediaHandler struct {
+	mediaService *service.MediaService
+}
+
+// NewMediaHandler creates a new media handler
+func NewMediaHandler(mediaService *service.MediaService) *MediaHandler {
+	return &MediaHandler{mediaService: mediaService}
+}
+
+// UploadProductImage handles POST /api/v1/products/:id/image
+func (h *MediaHandler) UploadProductImage(c *gin.Context) {
+	id := c.Param("id")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.mediaService.MaxImageSize()+multipartOverhead)
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, service.ErrImageTooLarge.Error())
+			return
+		}
+		utils.BadRequest(c, "Image file is required")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.BadRequest(c, "Failed to read image file")
+		return
+	}
+	defer file.Close()
+
+	result, err := h.mediaService.UploadProductImage(c.Request.Context(), id, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrImageTooLarge):
+			utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, err.Error())
+		case errors.Is(err, service.ErrUnsupportedImage):
+			utils.ErrorResponse(c, http.StatusUnsupportedMediaType, err.Error())
+		default:
+			utils.BadRequest(c, err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Product image uploaded successfully", result)
+}
+
+// DeleteProductImage handles DELETE /api/v1/products/:id/image
+func (h *MediaHandler) DeleteProductImage(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.mediaService.DeleteProductImage(c.Request.Context(), id); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Product image deleted successfully", nil)
+}
+
+// Serve handles GET /media/*key. Stored keys are content-addressed, so responses may be
+// cached by browsers and CDNs indefinitely.
+func (h *MediaHandler) Serve(c *gin.Context) {
+	obj, err := h.mediaService.Get(c.Request.Context(), c.Param("key"))
+	if errors.Is(err, storage.ErrNotFound) {
+		utils.NotFound(c, "File not found")
+		return
+	}
+	if err != nil {
+		utils.InternalServerError(c, "Failed to read file")
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("ETag", `"`+path.Base(obj.Key)+`"`)
+	c.Header("Content-Type", obj.ContentType)
+	c.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, path.Base(obj.Key), obj.LastModified, bytes.NewReader(obj.Data))
+}
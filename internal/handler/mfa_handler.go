@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/middleware"
+	"github.com/ilramdhan/pos-api/internal/service"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// MFAHandler handles two-factor authentication endpoints
+type MFAHandler struct {
+	mfaService *service.MFAService
+}
+
+// NewMFAHandler creates a new two-factor authentication handler
+func NewMFAHandler(mfaService *service.MFAService) *MFAHandler {
+	return &MFAHandler{mfaService: mfaService}
+}
+
+// Status handles GET /api/v1/auth/me/mfa
+func (h *MFAHandler) Status(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	status, err := h.mfaService.Status(c.Request.Context(), claims.UserID)
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Two-factor authentication status retrieved", status)
+}
+
+// Setup handles POST /api/v1/auth/me/mfa/setup
+func (h *MFAHandler) Setup(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	resp, err := h.mfaService.Setup(c.Request.Context(), claims.UserID)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Scan the QR code with your authenticator app", resp)
+}
+
+// Enable handles POST /api/v1/auth/me/mfa/enable
+func (h *MFAHandler) Enable(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.EnableMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	codes, err := h.mfaService.Enable(c.Request.Context(), claims.UserID, req.Code)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Two-factor authentication enabled; keep the recovery codes safe",
+		dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable handles DELETE /api/v1/auth/me/mfa
+func (h *MFAHandler) Disable(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.DisableMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	if err := h.mfaService.Disable(c.Request.Context(), claims.UserID, &req); err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+// RegenerateRecoveryCodes handles POST /api/v1/auth/me/mfa/recovery-codes
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.EnableMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(c.Request.Context(), claims.UserID, req.Code)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Recovery codes regenerated; the old ones no longer work",
+		dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Reset handles DELETE /api/v1/users/:id/mfa, for users who lost their authenticator
+func (h *MFAHandler) Reset(c *gin.Context) {
+	if err := h.mfaService.Reset(c.Request.Context(), c.Param("id")); err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Two-factor authentication reset", nil)
+}
+
+// respondMFAError reports a failed second-factor check
+func respondMFAError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrMFALocked):
+		utils.ErrorResponse(c, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, service.ErrInvalidMFACode), errors.Is(err, service.ErrInvalidMFAToken):
+		utils.Unauthorized(c, err.Error())
+	default:
+		utils.BadRequest(c, err.Error())
+	}
+}
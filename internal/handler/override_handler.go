@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/middleware"
+	"github.com/ilramdhan/pos-api/internal/service"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// OverrideHandler handles manager approval endpoints
+type OverrideHandler struct {
+	overrideService *service.OverrideService
+}
+
+// NewOverrideHandler creates a new override handler
+func NewOverrideHandler(overrideService *service.OverrideService) *OverrideHandler {
+	return &OverrideHandler{overrideService: overrideService}
+}
+
+// Create handles POST /api/v1/overrides. The current user requests the approval and the
+// approver signs it off on the same terminal.
+func (h *OverrideHandler) Create(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.CreateOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	override, err := h.overrideService.Approve(c.Request.Context(), claims.UserID, claims.TerminalID, &req, clientInfo(c))
+	if err != nil {
+		var locked *service.LoginLockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(locked.RetrySeconds()))
+			utils.ErrorResponse(c, http.StatusTooManyRequests, locked.Error())
+			return
+		}
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.CreatedResponse(c, "Override approved", override)
+}
+
+// List handles GET /api/v1/overrides
+func (h *OverrideHandler) List(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	var filter dto.OverrideListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	if errors, ok := utils.Validate(&filter); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	overrides, total, err := h.overrideService.List(c.Request.Context(), filter, pagination)
+	if err != nil {
+		utils.ListError(c, err)
+		return
+	}
+
+	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	utils.SuccessWithMeta(c, "Overrides retrieved successfully", overrides, meta)
+}
+
+// NoSale handles POST /api/v1/pos/no-sale
+func (h *OverrideHandler) NoSale(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.NoSaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	override, err := h.overrideService.NoSale(c.Request.Context(), claims.UserID, claims.Role, claims.TerminalID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrOverrideRequired) {
+			utils.Forbidden(c, err.Error())
+			return
+		}
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Cash drawer opened", override)
+}
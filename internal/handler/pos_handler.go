@@ -3,11 +3,9 @@ package handler
 import (
 	"fmt"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"github.com/ilramdhan/pos-api/internal/dto"
 	"github.com/ilramdhan/pos-api/internal/middleware"
 	"github.com/ilramdhan/pos-api/internal/service"
@@ -18,13 +16,17 @@ import (
 type POSHandler struct {
 	productService     *service.ProductService
 	transactionService *service.TransactionService
+	inventoryService   *service.InventoryService
+	heldService        *service.HeldTransactionService
 }
 
 // NewPOSHandler creates a new POS handler
-func NewPOSHandler(productService *service.ProductService, transactionService *service.TransactionService) *POSHandler {
+func NewPOSHandler(productService *service.ProductService, transactionService *service.TransactionService, inventoryService *service.InventoryService, heldService *service.HeldTransactionService) *POSHandler {
 	return &POSHandler{
 		productService:     productService,
 		transactionService: transactionService,
+		inventoryService:   inventoryService,
+		heldService:        heldService,
 	}
 }
 
@@ -33,10 +35,17 @@ func (h *POSHandler) GetProducts(c *gin.Context) {
 	categoryID := c.Query("category_id")
 	search := c.Query("search")
 
+	// Searches list the best matches first
+	pagination := utils.Pagination{Page: 1, PerPage: 100}
+	if search != "" {
+		pagination.Sort = "-relevance"
+	}
+
 	products, _, err := h.productService.List(c.Request.Context(), dto.ProductListFilter{
+		StoreID:    storeScope(c, c.Query("store_id")),
 		CategoryID: categoryID,
 		Search:     search,
-	}, utils.Pagination{Page: 1, PerPage: 100})
+	}, pagination)
 	if err != nil {
 		// Log error for debugging
 		fmt.Printf("[POS] Error fetching products: %v\n", err)
@@ -44,6 +53,18 @@ func (h *POSHandler) GetProducts(c *gin.Context) {
 		return
 	}
 
+	// Products with a recipe are limited by their ingredients rather than their own stock
+	productIDs := make([]string, 0, len(products))
+	for _, p := range products {
+		productIDs = append(productIDs, p.ID)
+	}
+	portions, err := h.inventoryService.Portions(c.Request.Context(), productIDs)
+	if err != nil {
+		fmt.Printf("[POS] Error fetching recipe stock: %v\n", err)
+		utils.InternalServerError(c, "Failed to fetch products: "+err.Error())
+		return
+	}
+
 	// Initialize as empty slice (not nil) to return [] instead of null in JSON
 	posProducts := make([]gin.H, 0)
 	for _, p := range products {
@@ -51,31 +72,111 @@ func (h *POSHandler) GetProducts(c *gin.Context) {
 		if p.Category != nil {
 			categoryName = p.Category.Name
 		}
+		// Option lists are always arrays so the POS can render the option tree without null checks
+		variants := p.Variants
+		if variants == nil {
+			variants = []dto.ProductVariantResponse{}
+		}
+		modifierGroups := p.ModifierGroups
+		if modifierGroups == nil {
+			modifierGroups = []dto.ModifierGroupResponse{}
+		}
+		barcodes := p.Barcodes
+		if barcodes == nil {
+			barcodes = []dto.ProductBarcodeResponse{}
+		}
+		stock := p.Stock
+		if n, ok := portions[p.ID]; ok {
+			stock = n
+		}
 		posProducts = append(posProducts, gin.H{
-			"id":            p.ID,
-			"name":          p.Name,
-			"sku":           p.SKU,
-			"price":         p.Price,
-			"stock":         p.Stock,
-			"category_id":   p.CategoryID,
-			"category_name": categoryName,
-			"image_url":     p.ImageURL,
+			"id":              p.ID,
+			"name":            p.Name,
+			"sku":             p.SKU,
+			"price":           p.Price,
+			"stock":           stock,
+			"available":       stock > 0,
+			"category_id":     p.CategoryID,
+			"category_name":   categoryName,
+			"image_url":       p.ImageURL,
+			"thumbnail_url":   p.ThumbnailURL,
+			"variants":        variants,
+			"modifier_groups": modifierGroups,
+			"barcodes":        barcodes,
 		})
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Products retrieved", posProducts)
 }
 
+// SearchProducts handles GET /api/v1/products/search
+func (h *POSHandler) SearchProducts(c *gin.Context) {
+	var query dto.ProductSearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	if errors, ok := utils.Validate(&query); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	query.StoreID = storeScope(c, query.StoreID)
+	suggestions, err := h.productService.Search(c.Request.Context(), query)
+	if err != nil {
+		utils.InternalServerError(c, "Failed to search products")
+		return
+	}
+
+	// Products with a recipe are limited by their ingredients rather than their own stock
+	productIDs := make([]string, 0, len(suggestions))
+	for _, s := range suggestions {
+		productIDs = append(productIDs, s.ID)
+	}
+	portions, err := h.inventoryService.Portions(c.Request.Context(), productIDs)
+	if err != nil {
+		utils.InternalServerError(c, "Failed to search products")
+		return
+	}
+	for _, s := range suggestions {
+		if n, ok := portions[s.ID]; ok {
+			s.Stock = n
+			s.Available = n > 0
+		}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Products found", suggestions)
+}
+
+// LookupBarcode handles GET /api/v1/pos/products/barcode/:code
+func (h *POSHandler) LookupBarcode(c *gin.Context) {
+	code := c.Param("code")
+
+	result, err := h.productService.LookupBarcode(c.Request.Context(), code)
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Product found", result)
+}
+
 // CreateTransaction handles POST /api/v1/pos/transactions
 func (h *POSHandler) CreateTransaction(c *gin.Context) {
 	var req struct {
 		CustomerID    *string `json:"customer_id"`
 		PaymentMethod string  `json:"payment_method"`
 		Items         []struct {
-			ProductID string  `json:"product_id"`
-			Quantity  int     `json:"quantity"`
-			UnitPrice float64 `json:"unit_price"`
-			Discount  float64 `json:"discount"`
+			ProductID     string   `json:"product_id"`
+			VariantID     string   `json:"variant_id"`
+			ModifierIDs   []string `json:"modifier_ids"`
+			Barcode       string   `json:"barcode"`
+			Quantity      int      `json:"quantity"`
+			UnitPrice     float64  `json:"unit_price"`
+			Discount      float64  `json:"discount"`
+			PriceOverride *float64 `json:"price_override"`
+			OverrideToken string   `json:"override_token"`
 		} `json:"items"`
 		Subtotal       float64 `json:"subtotal"`
 		TaxAmount      float64 `json:"tax_amount"`
@@ -84,6 +185,10 @@ func (h *POSHandler) CreateTransaction(c *gin.Context) {
 		AmountPaid     float64 `json:"amount_paid"`
 		ChangeAmount   float64 `json:"change_amount"`
 		Notes          string  `json:"notes"`
+		VoucherCode    string  `json:"voucher_code"`
+		RedeemPoints   int     `json:"redeem_points"`
+
+		DiscountOverrideToken string `json:"discount_override_token"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -103,50 +208,56 @@ func (h *POSHandler) CreateTransaction(c *gin.Context) {
 		PaymentMethod:  req.PaymentMethod,
 		DiscountAmount: req.DiscountAmount,
 		Notes:          req.Notes,
+		VoucherCode:    req.VoucherCode,
+		RedeemPoints:   req.RedeemPoints,
+
+		DiscountOverrideToken: req.DiscountOverrideToken,
 	}
 
 	for _, item := range req.Items {
 		txReq.Items = append(txReq.Items, dto.CreateTransactionItemDTO{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
+			ProductID:     item.ProductID,
+			VariantID:     item.VariantID,
+			ModifierIDs:   item.ModifierIDs,
+			Barcode:       item.Barcode,
+			Quantity:      item.Quantity,
+			PriceOverride: item.PriceOverride,
+			OverrideToken: item.OverrideToken,
 		})
 	}
 
 	// Create transaction
-	tx, err := h.transactionService.Create(c.Request.Context(), claims.UserID, txReq)
+	tx, err := h.transactionService.Create(c.Request.Context(), claims.UserID, claims.Role, claims.StoreID, claims.TerminalID, txReq)
 	if err != nil {
-		utils.BadRequest(c, err.Error())
+		respondTransactionError(c, err)
 		return
 	}
 
 	utils.CreatedResponse(c, "Transaction created successfully", gin.H{
-		"id":             tx.ID,
-		"invoice_number": tx.InvoiceNumber,
-		"status":         tx.Status,
-		"total_amount":   tx.TotalAmount,
-		"created_at":     tx.CreatedAt.Format(time.RFC3339),
+		"id":              tx.ID,
+		"invoice_number":  tx.InvoiceNumber,
+		"status":          tx.Status,
+		"total_amount":    tx.TotalAmount,
+		"voucher_code":    tx.VoucherCode,
+		"points_earned":   tx.PointsEarned,
+		"points_redeemed": tx.PointsRedeemed,
+		"created_at":      tx.CreatedAt.Format(time.RFC3339),
 	})
 }
 
-// HoldTransaction represents a held transaction
-type HoldTransaction struct {
-	ID           string    `json:"id"`
-	HoldNumber   string    `json:"hold_number"`
-	CustomerName string    `json:"customer_name"`
-	ItemsCount   int       `json:"items_count"`
-	TotalAmount  float64   `json:"total_amount"`
-	CreatedAt    time.Time `json:"created_at"`
-}
-
-// Mock storage for held transactions (in production, use database)
-var heldTransactions = make(map[string]*HoldTransaction)
-var holdCounter = 0
-
-// GetHeldTransactions handles GET /api/v1/pos/hold
+// GetHeldTransactions handles GET /api/v1/pos/hold. It lists the carts held at the caller's
+// terminal, or at their store outside PIN sessions.
 func (h *POSHandler) GetHeldTransactions(c *gin.Context) {
-	var holds []*HoldTransaction
-	for _, hold := range heldTransactions {
-		holds = append(holds, hold)
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	holds, err := h.heldService.List(c.Request.Context(), claims.StoreID, claims.TerminalID)
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Held transactions retrieved", holds)
@@ -154,40 +265,44 @@ func (h *POSHandler) GetHeldTransactions(c *gin.Context) {
 
 // HoldTransactionCreate handles POST /api/v1/pos/hold
 func (h *POSHandler) HoldTransactionCreate(c *gin.Context) {
-	var req struct {
-		CustomerID   *string `json:"customer_id"`
-		CustomerName string  `json:"customer_name"`
-		Items        []struct {
-			ProductID string `json:"product_id"`
-			Quantity  int    `json:"quantity"`
-		} `json:"items"`
-		TotalAmount float64 `json:"total_amount"`
-		Notes       string  `json:"notes"`
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
 	}
 
+	var req dto.HoldTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.BadRequest(c, "Invalid request body")
 		return
 	}
 
-	holdCounter++
-	hold := &HoldTransaction{
-		ID:           uuid.New().String(),
-		HoldNumber:   "HOLD-" + strconv.Itoa(holdCounter),
-		CustomerName: req.CustomerName,
-		ItemsCount:   len(req.Items),
-		TotalAmount:  req.TotalAmount,
-		CreatedAt:    time.Now(),
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
 	}
 
-	heldTransactions[hold.ID] = hold
+	hold, err := h.heldService.Hold(c.Request.Context(), claims.UserID, claims.StoreID, claims.TerminalID, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
 
 	utils.CreatedResponse(c, "Transaction held successfully", hold)
 }
 
 // DeleteHeldTransaction handles DELETE /api/v1/pos/hold/:id
 func (h *POSHandler) DeleteHeldTransaction(c *gin.Context) {
-	id := c.Param("id")
-	delete(heldTransactions, id)
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.heldService.Delete(c.Request.Context(), c.Param("id"), claims.StoreID, claims.TerminalID); err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
 	utils.SuccessResponse(c, http.StatusOK, "Held transaction deleted", nil)
 }
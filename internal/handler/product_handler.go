@@ -1,10 +1,16 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/middleware"
+	"github.com/ilramdhan/pos-api/internal/models"
 	"github.com/ilramdhan/pos-api/internal/service"
 	"github.com/ilramdhan/pos-api/internal/utils"
 )
@@ -12,11 +18,61 @@ import (
 // ProductHandler handles product endpoints
 type ProductHandler struct {
 	productService *service.ProductService
+	roleService    *service.RoleService
 }
 
 // NewProductHandler creates a new product handler
-func NewProductHandler(productService *service.ProductService) *ProductHandler {
-	return &ProductHandler{productService: productService}
+func NewProductHandler(productService *service.ProductService, roleService *service.RoleService) *ProductHandler {
+	return &ProductHandler{productService: productService, roleService: roleService}
+}
+
+// priceChangeAllowed reports whether the caller may set the price of a product, or of one of its
+// variants, and responds with 403 if not. Resending the current price needs no permission.
+func (h *ProductHandler) priceChangeAllowed(c *gin.Context, productID, variantID string, price float64) bool {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return false
+	}
+
+	allowed, err := h.roleService.HasPermission(c.Request.Context(), claims.Role, models.PermProductsPriceUpdate)
+	if err != nil {
+		utils.InternalServerError(c, "Failed to check permissions")
+		return false
+	}
+	if allowed {
+		return true
+	}
+
+	// Unknown products and variants are reported by the update itself
+	product, err := h.productService.GetByID(c.Request.Context(), productID, "")
+	if errors.Is(err, service.ErrProductNotFound) {
+		return true
+	}
+	if err != nil {
+		utils.InternalServerError(c, "Failed to check the current price")
+		return false
+	}
+	if variantID == "" && price == product.Price {
+		return true
+	}
+	if variantID != "" {
+		known := false
+		for _, v := range product.Variants {
+			if v.ID == variantID {
+				known = true
+				if price == v.Price {
+					return true
+				}
+			}
+		}
+		if !known {
+			return true
+		}
+	}
+
+	utils.Forbidden(c, "You don't have permission to change prices")
+	return false
 }
 
 // List handles GET /api/v1/products
@@ -28,10 +84,16 @@ func (h *ProductHandler) List(c *gin.Context) {
 		utils.BadRequest(c, "Invalid query parameters")
 		return
 	}
+	filter.StoreID = storeScope(c, filter.StoreID)
+
+	// Searches are ranked by relevance unless a sort is given
+	if filter.Search != "" && c.Query("sort") == "" {
+		pagination.Sort = "-relevance"
+	}
 
 	products, total, err := h.productService.List(c.Request.Context(), filter, pagination)
 	if err != nil {
-		utils.InternalServerError(c, err.Error())
+		utils.ListError(c, err)
 		return
 	}
 
@@ -43,7 +105,7 @@ func (h *ProductHandler) List(c *gin.Context) {
 func (h *ProductHandler) Get(c *gin.Context) {
 	id := c.Param("id")
 
-	product, err := h.productService.GetByID(c.Request.Context(), id)
+	product, err := h.productService.GetByID(c.Request.Context(), id, storeScope(c, c.Query("store_id")))
 	if err != nil {
 		utils.NotFound(c, err.Error())
 		return
@@ -89,6 +151,10 @@ func (h *ProductHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if req.Price != nil && !h.priceChangeAllowed(c, id, "", *req.Price) {
+		return
+	}
+
 	product, err := h.productService.Update(c.Request.Context(), id, &req)
 	if err != nil {
 		utils.BadRequest(c, err.Error())
@@ -112,6 +178,12 @@ func (h *ProductHandler) Delete(c *gin.Context) {
 
 // UpdateStock handles PATCH /api/v1/products/:id/stock
 func (h *ProductHandler) UpdateStock(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
 	id := c.Param("id")
 
 	var req dto.UpdateStockRequest
@@ -125,7 +197,7 @@ func (h *ProductHandler) UpdateStock(c *gin.Context) {
 		return
 	}
 
-	product, err := h.productService.UpdateStock(c.Request.Context(), id, &req)
+	product, err := h.productService.UpdateStock(c.Request.Context(), id, storeScope(c, c.Query("store_id")), claims.UserID, &req)
 	if err != nil {
 		utils.BadRequest(c, err.Error())
 		return
@@ -133,3 +205,310 @@ func (h *ProductHandler) UpdateStock(c *gin.Context) {
 
 	utils.SuccessResponse(c, http.StatusOK, "Stock updated successfully", product)
 }
+
+// CreateVariant handles POST /api/v1/products/:id/variants
+func (h *ProductHandler) CreateVariant(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.CreateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	variant, err := h.productService.CreateVariant(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.CreatedResponse(c, "Variant created successfully", variant)
+}
+
+// UpdateVariant handles PUT /api/v1/products/:id/variants/:variantId
+func (h *ProductHandler) UpdateVariant(c *gin.Context) {
+	id := c.Param("id")
+	variantID := c.Param("variantId")
+
+	var req dto.UpdateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	if req.Price != nil && !h.priceChangeAllowed(c, id, variantID, *req.Price) {
+		return
+	}
+
+	variant, err := h.productService.UpdateVariant(c.Request.Context(), id, variantID, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Variant updated successfully", variant)
+}
+
+// UpdateVariantStock handles PATCH /api/v1/products/:id/variants/:variantId/stock
+func (h *ProductHandler) UpdateVariantStock(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.UpdateStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	variant, err := h.productService.UpdateVariantStock(c.Request.Context(), c.Param("id"), c.Param("variantId"),
+		storeScope(c, c.Query("store_id")), claims.UserID, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stock updated successfully", variant)
+}
+
+// DeleteVariant handles DELETE /api/v1/products/:id/variants/:variantId
+func (h *ProductHandler) DeleteVariant(c *gin.Context) {
+	id := c.Param("id")
+	variantID := c.Param("variantId")
+
+	if err := h.productService.DeleteVariant(c.Request.Context(), id, variantID); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Variant deleted successfully", nil)
+}
+
+// CreateModifierGroup handles POST /api/v1/products/:id/modifier-groups
+func (h *ProductHandler) CreateModifierGroup(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.ModifierGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	group, err := h.productService.CreateModifierGroup(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.CreatedResponse(c, "Modifier group created successfully", group)
+}
+
+// UpdateModifierGroup handles PUT /api/v1/products/:id/modifier-groups/:groupId
+func (h *ProductHandler) UpdateModifierGroup(c *gin.Context) {
+	id := c.Param("id")
+	groupID := c.Param("groupId")
+
+	var req dto.ModifierGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	group, err := h.productService.UpdateModifierGroup(c.Request.Context(), id, groupID, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Modifier group updated successfully", group)
+}
+
+// DeleteModifierGroup handles DELETE /api/v1/products/:id/modifier-groups/:groupId
+func (h *ProductHandler) DeleteModifierGroup(c *gin.Context) {
+	id := c.Param("id")
+	groupID := c.Param("groupId")
+
+	if err := h.productService.DeleteModifierGroup(c.Request.Context(), id, groupID); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Modifier group deleted successfully", nil)
+}
+
+// CreateBarcode handles POST /api/v1/products/:id/barcodes
+func (h *ProductHandler) CreateBarcode(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.CreateBarcodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	barcode, err := h.productService.CreateBarcode(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.CreatedResponse(c, "Barcode created successfully", barcode)
+}
+
+// DeleteBarcode handles DELETE /api/v1/products/:id/barcodes/:barcodeId
+func (h *ProductHandler) DeleteBarcode(c *gin.Context) {
+	id := c.Param("id")
+	barcodeID := c.Param("barcodeId")
+
+	if err := h.productService.DeleteBarcode(c.Request.Context(), id, barcodeID); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Barcode deleted successfully", nil)
+}
+
+// BarcodeLabel handles GET /api/v1/products/:id/barcodes/:barcodeId/label
+func (h *ProductHandler) BarcodeLabel(c *gin.Context) {
+	id := c.Param("id")
+	barcodeID := c.Param("barcodeId")
+
+	var query dto.BarcodeLabelQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	if errors, ok := utils.Validate(&query); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	label, contentType, err := h.productService.BarcodeLabel(c.Request.Context(), id, barcodeID, query)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, label)
+}
+
+// maxImportSize is the largest CSV file accepted by a product import
+const maxImportSize = 5 << 20
+
+// Import handles POST /api/v1/products/import
+func (h *ProductHandler) Import(c *gin.Context) {
+	var query dto.ProductImportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	// Accept either a multipart upload in the "file" field or a raw text/csv body
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	var body io.Reader = c.Request.Body
+	if c.ContentType() == "multipart/form-data" {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			utils.BadRequest(c, "CSV file is required")
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			utils.BadRequest(c, "Failed to read CSV file")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+	canChangePrices, err := h.roleService.HasPermission(c.Request.Context(), claims.Role, models.PermProductsPriceUpdate)
+	if err != nil {
+		utils.InternalServerError(c, "Failed to check permissions")
+		return
+	}
+	canChangeStock, err := h.roleService.HasPermission(c.Request.Context(), claims.Role, models.PermInventoryManage)
+	if err != nil {
+		utils.InternalServerError(c, "Failed to check permissions")
+		return
+	}
+
+	result, err := h.productService.ImportCSV(c.Request.Context(), body, service.ProductImportOptions{
+		DryRun:          query.DryRun,
+		CanChangePrices: canChangePrices,
+		CanChangeStock:  canChangeStock,
+		StoreID:         storeScope(c, c.Query("store_id")),
+		UserID:          claims.UserID,
+	})
+	if err != nil {
+		var importErr *service.ProductImportError
+		if errors.As(err, &importErr) {
+			utils.UnprocessableEntity(c, importErr.Error(), importErr.Result)
+			return
+		}
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	message := "Products imported successfully"
+	if query.DryRun {
+		message = "Import checked; no products were saved"
+	}
+	utils.SuccessResponse(c, http.StatusOK, message, result)
+}
+
+// Export handles GET /api/v1/products/export
+func (h *ProductHandler) Export(c *gin.Context) {
+	var filter dto.ProductListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	data, err := h.productService.ExportCSV(c.Request.Context(), filter)
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("products-%s.csv", time.Now().Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
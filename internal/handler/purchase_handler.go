@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/middleware"
+	"github.com/ilramdhan/pos-api/internal/service"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// PurchaseHandler handles supplier and purchase order endpoints
+type PurchaseHandler struct {
+	purchaseService *service.PurchaseService
+}
+
+// NewPurchaseHandler creates a new purchase handler
+func NewPurchaseHandler(purchaseService *service.PurchaseService) *PurchaseHandler {
+	return &PurchaseHandler{purchaseService: purchaseService}
+}
+
+// ListSuppliers handles GET /api/v1/suppliers
+func (h *PurchaseHandler) ListSuppliers(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	var filter dto.SupplierListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	suppliers, total, err := h.purchaseService.ListSuppliers(c.Request.Context(), filter, pagination)
+	if err != nil {
+		utils.ListError(c, err)
+		return
+	}
+
+	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	utils.SuccessWithMeta(c, "Suppliers retrieved successfully", suppliers, meta)
+}
+
+// GetSupplier handles GET /api/v1/suppliers/:id
+func (h *PurchaseHandler) GetSupplier(c *gin.Context) {
+	id := c.Param("id")
+
+	supplier, err := h.purchaseService.GetSupplier(c.Request.Context(), id)
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Supplier retrieved successfully", supplier)
+}
+
+// CreateSupplier handles POST /api/v1/suppliers
+func (h *PurchaseHandler) CreateSupplier(c *gin.Context) {
+	var req dto.CreateSupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	supplier, err := h.purchaseService.CreateSupplier(c.Request.Context(), &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.CreatedResponse(c, "Supplier created successfully", supplier)
+}
+
+// UpdateSupplier handles PUT /api/v1/suppliers/:id
+func (h *PurchaseHandler) UpdateSupplier(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.UpdateSupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	supplier, err := h.purchaseService.UpdateSupplier(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Supplier updated successfully", supplier)
+}
+
+// DeleteSupplier handles DELETE /api/v1/suppliers/:id
+func (h *PurchaseHandler) DeleteSupplier(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.purchaseService.DeleteSupplier(c.Request.Context(), id); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Supplier deleted successfully", nil)
+}
+
+// ListPurchaseOrders handles GET /api/v1/purchase-orders
+func (h *PurchaseHandler) ListPurchaseOrders(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	var filter dto.PurchaseOrderListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	if errors, ok := utils.Validate(&filter); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	filter.StoreID = storeScope(c, filter.StoreID)
+
+	orders, total, err := h.purchaseService.ListPurchaseOrders(c.Request.Context(), filter, pagination)
+	if err != nil {
+		utils.ListError(c, err)
+		return
+	}
+
+	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	utils.SuccessWithMeta(c, "Purchase orders retrieved successfully", orders, meta)
+}
+
+// GetPurchaseOrder handles GET /api/v1/purchase-orders/:id
+func (h *PurchaseHandler) GetPurchaseOrder(c *gin.Context) {
+	id := c.Param("id")
+
+	order, err := h.purchaseService.GetPurchaseOrder(c.Request.Context(), id)
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Purchase order retrieved successfully", order)
+}
+
+// CreatePurchaseOrder handles POST /api/v1/purchase-orders
+func (h *PurchaseHandler) CreatePurchaseOrder(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.CreatePurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	if claims.StoreID != "" {
+		req.StoreID = &claims.StoreID
+	}
+
+	order, err := h.purchaseService.CreatePurchaseOrder(c.Request.Context(), claims.UserID, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.CreatedResponse(c, "Purchase order created successfully", order)
+}
+
+// UpdatePurchaseOrder handles PUT /api/v1/purchase-orders/:id
+func (h *PurchaseHandler) UpdatePurchaseOrder(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.UpdatePurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	order, err := h.purchaseService.UpdatePurchaseOrder(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Purchase order updated successfully", order)
+}
+
+// SubmitPurchaseOrder handles POST /api/v1/purchase-orders/:id/order
+func (h *PurchaseHandler) SubmitPurchaseOrder(c *gin.Context) {
+	order, err := h.purchaseService.SubmitPurchaseOrder(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Purchase order placed successfully", order)
+}
+
+// CancelPurchaseOrder handles POST /api/v1/purchase-orders/:id/cancel
+func (h *PurchaseHandler) CancelPurchaseOrder(c *gin.Context) {
+	order, err := h.purchaseService.CancelPurchaseOrder(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Purchase order cancelled successfully", order)
+}
+
+// ReceivePurchaseOrder handles POST /api/v1/purchase-orders/:id/receive
+func (h *PurchaseHandler) ReceivePurchaseOrder(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.ReceivePurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	order, err := h.purchaseService.ReceivePurchaseOrder(c.Request.Context(), c.Param("id"), claims.UserID, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Goods received successfully", order)
+}
@@ -33,7 +33,7 @@ func (h *ReportHandler) DailySales(c *gin.Context) {
 		dateTo = time.Now().Format("2006-01-02")
 	}
 
-	reports, err := h.reportService.GetDailySales(c.Request.Context(), dateFrom, dateTo)
+	reports, err := h.reportService.GetDailySales(c.Request.Context(), storeScope(c, c.Query("store_id")), dateFrom, dateTo)
 	if err != nil {
 		utils.InternalServerError(c, err.Error())
 		return
@@ -55,7 +55,7 @@ func (h *ReportHandler) MonthlySales(c *gin.Context) {
 		dateTo = time.Now().Format("2006-01-02")
 	}
 
-	reports, err := h.reportService.GetMonthlySales(c.Request.Context(), dateFrom, dateTo)
+	reports, err := h.reportService.GetMonthlySales(c.Request.Context(), storeScope(c, c.Query("store_id")), dateFrom, dateTo)
 	if err != nil {
 		utils.InternalServerError(c, err.Error())
 		return
@@ -80,7 +80,7 @@ func (h *ReportHandler) TopProducts(c *gin.Context) {
 		dateTo = time.Now().Format("2006-01-02")
 	}
 
-	reports, err := h.reportService.GetTopProducts(c.Request.Context(), limit, dateFrom, dateTo)
+	reports, err := h.reportService.GetTopProducts(c.Request.Context(), storeScope(c, c.Query("store_id")), limit, dateFrom, dateTo)
 	if err != nil {
 		utils.InternalServerError(c, err.Error())
 		return
@@ -88,3 +88,25 @@ func (h *ReportHandler) TopProducts(c *gin.Context) {
 
 	utils.SuccessResponse(c, http.StatusOK, "Top products report retrieved successfully", reports)
 }
+
+// OutstandingPurchases handles GET /api/v1/reports/purchase-orders/outstanding
+func (h *ReportHandler) OutstandingPurchases(c *gin.Context) {
+	reports, err := h.reportService.GetOutstandingPurchases(c.Request.Context(), storeScope(c, c.Query("store_id")))
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Outstanding purchase orders report retrieved successfully", reports)
+}
+
+// InTransitTransfers handles GET /api/v1/reports/stock-transfers/in-transit
+func (h *ReportHandler) InTransitTransfers(c *gin.Context) {
+	reports, err := h.reportService.GetInTransitTransfers(c.Request.Context(), storeScope(c, c.Query("store_id")))
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "In-transit stock transfers report retrieved successfully", reports)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/service"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// RoleHandler handles role endpoints
+type RoleHandler struct {
+	roleService *service.RoleService
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleService *service.RoleService) *RoleHandler {
+	return &RoleHandler{roleService: roleService}
+}
+
+// Permissions handles GET /api/v1/roles/permissions
+func (h *RoleHandler) Permissions(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Permissions retrieved successfully", models.AllPermissions)
+}
+
+// List handles GET /api/v1/roles
+func (h *RoleHandler) List(c *gin.Context) {
+	roles, err := h.roleService.List(c.Request.Context())
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Roles retrieved successfully", roles)
+}
+
+// Get handles GET /api/v1/roles/:name
+func (h *RoleHandler) Get(c *gin.Context) {
+	role, err := h.roleService.Get(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Role retrieved successfully", role)
+}
+
+// Create handles POST /api/v1/roles
+func (h *RoleHandler) Create(c *gin.Context) {
+	var req dto.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	role, err := h.roleService.Create(c.Request.Context(), &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.CreatedResponse(c, "Role created successfully", role)
+}
+
+// Update handles PUT /api/v1/roles/:name
+func (h *RoleHandler) Update(c *gin.Context) {
+	var req dto.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	role, err := h.roleService.Update(c.Request.Context(), c.Param("name"), &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Role updated successfully", role)
+}
+
+// Delete handles DELETE /api/v1/roles/:name
+func (h *RoleHandler) Delete(c *gin.Context) {
+	if err := h.roleService.Delete(c.Request.Context(), c.Param("name")); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Role deleted successfully", nil)
+}
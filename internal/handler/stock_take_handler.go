@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/middleware"
+	"github.com/ilramdhan/pos-api/internal/service"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// StockTakeHandler handles stock take (physical inventory count) endpoints
+type StockTakeHandler struct {
+	stockTakeService *service.StockTakeService
+}
+
+// NewStockTakeHandler creates a new stock take handler
+func NewStockTakeHandler(stockTakeService *service.StockTakeService) *StockTakeHandler {
+	return &StockTakeHandler{stockTakeService: stockTakeService}
+}
+
+// List handles GET /api/v1/stock-takes
+func (h *StockTakeHandler) List(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	var filter dto.StockTakeListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	if errors, ok := utils.Validate(&filter); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	filter.StoreID = storeScope(c, filter.StoreID)
+
+	takes, total, err := h.stockTakeService.ListStockTakes(c.Request.Context(), filter, pagination)
+	if err != nil {
+		utils.ListError(c, err)
+		return
+	}
+
+	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	utils.SuccessWithMeta(c, "Stock takes retrieved successfully", takes, meta)
+}
+
+// Get handles GET /api/v1/stock-takes/:id
+func (h *StockTakeHandler) Get(c *gin.Context) {
+	take, err := h.stockTakeService.GetStockTake(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stock take retrieved successfully", take)
+}
+
+// Create handles POST /api/v1/stock-takes
+func (h *StockTakeHandler) Create(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.CreateStockTakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	if claims.StoreID != "" {
+		req.StoreID = &claims.StoreID
+	}
+
+	take, err := h.stockTakeService.OpenStockTake(c.Request.Context(), claims.UserID, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.CreatedResponse(c, "Stock take opened successfully", take)
+}
+
+// SubmitCounts handles POST /api/v1/stock-takes/:id/counts
+func (h *StockTakeHandler) SubmitCounts(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.SubmitStockCountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	take, err := h.stockTakeService.SubmitCounts(c.Request.Context(), c.Param("id"), claims.UserID, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Counts recorded successfully", take)
+}
+
+// Approve handles POST /api/v1/stock-takes/:id/approve
+func (h *StockTakeHandler) Approve(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	take, err := h.stockTakeService.ApproveStockTake(c.Request.Context(), c.Param("id"), claims.UserID)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stock take approved successfully", take)
+}
+
+// Cancel handles POST /api/v1/stock-takes/:id/cancel
+func (h *StockTakeHandler) Cancel(c *gin.Context) {
+	take, err := h.stockTakeService.CancelStockTake(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stock take cancelled successfully", take)
+}
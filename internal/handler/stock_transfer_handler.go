@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/middleware"
+	"github.com/ilramdhan/pos-api/internal/service"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// StockTransferHandler handles stock transfer endpoints
+type StockTransferHandler struct {
+	stockTransferService *service.StockTransferService
+}
+
+// NewStockTransferHandler creates a new stock transfer handler
+func NewStockTransferHandler(stockTransferService *service.StockTransferService) *StockTransferHandler {
+	return &StockTransferHandler{stockTransferService: stockTransferService}
+}
+
+// List handles GET /api/v1/stock-transfers
+func (h *StockTransferHandler) List(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	var filter dto.StockTransferListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	if errors, ok := utils.Validate(&filter); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	filter.StoreID = storeScope(c, filter.StoreID)
+
+	transfers, total, err := h.stockTransferService.ListTransfers(c.Request.Context(), filter, pagination)
+	if err != nil {
+		utils.ListError(c, err)
+		return
+	}
+
+	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	utils.SuccessWithMeta(c, "Stock transfers retrieved successfully", transfers, meta)
+}
+
+// Get handles GET /api/v1/stock-transfers/:id
+func (h *StockTransferHandler) Get(c *gin.Context) {
+	transfer, err := h.stockTransferService.GetTransfer(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stock transfer retrieved successfully", transfer)
+}
+
+// Create handles POST /api/v1/stock-transfers
+func (h *StockTransferHandler) Create(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.CreateStockTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	transfer, err := h.stockTransferService.RequestTransfer(c.Request.Context(), claims.UserID, claims.StoreID, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.CreatedResponse(c, "Stock transfer requested successfully", transfer)
+}
+
+// Ship handles POST /api/v1/stock-transfers/:id/ship
+func (h *StockTransferHandler) Ship(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.ShipStockTransferRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BadRequest(c, "Invalid request body")
+			return
+		}
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	transfer, err := h.stockTransferService.ShipTransfer(c.Request.Context(), c.Param("id"), claims.UserID, claims.StoreID, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stock transfer shipped successfully", transfer)
+}
+
+// Receive handles POST /api/v1/stock-transfers/:id/receive
+func (h *StockTransferHandler) Receive(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.ReceiveStockTransferRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BadRequest(c, "Invalid request body")
+			return
+		}
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	transfer, err := h.stockTransferService.ReceiveTransfer(c.Request.Context(), c.Param("id"), claims.UserID, claims.StoreID, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stock transfer received successfully", transfer)
+}
+
+// Cancel handles POST /api/v1/stock-transfers/:id/cancel
+func (h *StockTransferHandler) Cancel(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	transfer, err := h.stockTransferService.CancelTransfer(c.Request.Context(), c.Param("id"), claims.StoreID)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stock transfer cancelled successfully", transfer)
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/middleware"
+	"github.com/ilramdhan/pos-api/internal/service"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// StoreHandler handles store endpoints
+type StoreHandler struct {
+	storeService *service.StoreService
+}
+
+// NewStoreHandler creates a new store handler
+func NewStoreHandler(storeService *service.StoreService) *StoreHandler {
+	return &StoreHandler{storeService: storeService}
+}
+
+// storeScope returns the store a request works on. Users signed in to a store are always
+// limited to it; everyone else may pick a store, or none for every store combined.
+func storeScope(c *gin.Context, requested string) string {
+	if claims := middleware.GetCurrentUser(c); claims != nil && claims.StoreID != "" {
+		return claims.StoreID
+	}
+	return requested
+}
+
+// List handles GET /api/v1/stores
+func (h *StoreHandler) List(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	var filter dto.StoreListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
+	}
+	if filter.Mine {
+		filter.UserID = middleware.GetCurrentUser(c).UserID
+	}
+
+	stores, total, err := h.storeService.ListStores(c.Request.Context(), filter, pagination)
+	if err != nil {
+		utils.ListError(c, err)
+		return
+	}
+
+	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	utils.SuccessWithMeta(c, "Stores retrieved successfully", stores, meta)
+}
+
+// Get handles GET /api/v1/stores/:id
+func (h *StoreHandler) Get(c *gin.Context) {
+	id := c.Param("id")
+
+	store, err := h.storeService.GetStore(c.Request.Context(), id)
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Store retrieved successfully", store)
+}
+
+// Create handles POST /api/v1/stores
+func (h *StoreHandler) Create(c *gin.Context) {
+	var req dto.CreateStoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	store, err := h.storeService.CreateStore(c.Request.Context(), &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.CreatedResponse(c, "Store created successfully", store)
+}
+
+// Update handles PUT /api/v1/stores/:id
+func (h *StoreHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.UpdateStoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	store, err := h.storeService.UpdateStore(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Store updated successfully", store)
+}
+
+// SetPrices handles PUT /api/v1/stores/:id/prices
+func (h *StoreHandler) SetPrices(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.SetStorePricesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	if err := h.storeService.SetPrices(c.Request.Context(), id, &req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Store prices updated successfully", nil)
+}
+
+// AssignUserStores handles PUT /api/v1/users/:id/stores
+func (h *StoreHandler) AssignUserStores(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.AssignUserStoresRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	stores, err := h.storeService.AssignUserStores(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User stores updated successfully", stores)
+}
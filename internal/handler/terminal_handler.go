@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ilramdhan/pos-api/internal/config"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/service"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// TerminalKeyHeader carries the key a registered terminal identifies itself with
+const TerminalKeyHeader = "X-Terminal-Key"
+
+// TerminalHandler handles terminal registration and PIN sign-in endpoints
+type TerminalHandler struct {
+	terminalService *service.TerminalService
+	cookieConfig    utils.CookieConfig
+}
+
+// NewTerminalHandler creates a new terminal handler
+func NewTerminalHandler(terminalService *service.TerminalService, cfg *config.Config) *TerminalHandler {
+	return &TerminalHandler{
+		terminalService: terminalService,
+		cookieConfig:    utils.DefaultCookieConfig(cfg.IsProduction()),
+	}
+}
+
+// List handles GET /api/v1/terminals
+func (h *TerminalHandler) List(c *gin.Context) {
+	terminals, err := h.terminalService.List(c.Request.Context(), c.Query("store_id"))
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Terminals retrieved successfully", terminals)
+}
+
+// Create handles POST /api/v1/terminals
+func (h *TerminalHandler) Create(c *gin.Context) {
+	var req dto.CreateTerminalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	terminal, err := h.terminalService.Create(c.Request.Context(), &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.CreatedResponse(c, "Terminal registered successfully", terminal)
+}
+
+// Update handles PUT /api/v1/terminals/:id
+func (h *TerminalHandler) Update(c *gin.Context) {
+	var req dto.UpdateTerminalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	terminal, err := h.terminalService.Update(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Terminal updated successfully", terminal)
+}
+
+// RegenerateKey handles POST /api/v1/terminals/:id/key
+func (h *TerminalHandler) RegenerateKey(c *gin.Context) {
+	terminal, err := h.terminalService.RegenerateKey(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Terminal key regenerated successfully", terminal)
+}
+
+// Delete handles DELETE /api/v1/terminals/:id
+func (h *TerminalHandler) Delete(c *gin.Context) {
+	if err := h.terminalService.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Terminal deleted successfully", nil)
+}
+
+// Users handles GET /api/v1/auth/terminal/users, which lists who can sign in to the terminal
+// sending the X-Terminal-Key header
+func (h *TerminalHandler) Users(c *gin.Context) {
+	users, err := h.terminalService.Users(c.Request.Context(), c.GetHeader(TerminalKeyHeader))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Terminal users retrieved successfully", users)
+}
+
+// PINLogin handles POST /api/v1/auth/pin-login. Signing in replaces the terminal's current
+// session, which is how users switch on a shared terminal.
+func (h *TerminalHandler) PINLogin(c *gin.Context) {
+	var req dto.PINLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	resp, err := h.terminalService.PINLogin(c.Request.Context(), c.GetHeader(TerminalKeyHeader), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	utils.SetAccessCookie(c, resp.Token.AccessToken, h.terminalService.SessionDuration(), h.cookieConfig)
+
+	utils.SuccessResponse(c, http.StatusOK, "Login successful", gin.H{
+		"user": resp.User,
+		"token": gin.H{
+			"expires_in": resp.Token.ExpiresIn,
+			"token_type": resp.Token.TokenType,
+		},
+		"store_id":    resp.StoreID,
+		"tenant_id":   resp.TenantID,
+		"terminal_id": resp.TerminalID,
+	})
+}
+
+// Heartbeat handles POST /api/v1/terminal/heartbeat, which the terminal sending the
+// X-Terminal-Key header calls every few seconds to show it is online
+func (h *TerminalHandler) Heartbeat(c *gin.Context) {
+	var req dto.HeartbeatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	resp, err := h.terminalService.Heartbeat(c.Request.Context(), c.GetHeader(TerminalKeyHeader), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Heartbeat recorded", resp)
+}
+
+// respondError reports a failed terminal sign-in
+func (h *TerminalHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrPINLocked):
+		utils.ErrorResponse(c, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, service.ErrPINNotAllowed):
+		utils.Forbidden(c, err.Error())
+	case errors.Is(err, service.ErrInvalidTerminal), errors.Is(err, service.ErrInvalidPIN):
+		utils.Unauthorized(c, err.Error())
+	default:
+		utils.BadRequest(c, err.Error())
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -29,14 +30,18 @@ func (h *TransactionHandler) List(c *gin.Context) {
 		utils.BadRequest(c, "Invalid query parameters")
 		return
 	}
+	filter.StoreID = storeScope(c, filter.StoreID)
 
 	transactions, total, err := h.transactionService.List(c.Request.Context(), filter, pagination)
 	if err != nil {
-		utils.InternalServerError(c, err.Error())
+		utils.ListError(c, err)
 		return
 	}
 
 	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	if n := len(transactions); n > 0 {
+		meta.WithCursor(pagination, n, transactions[n-1].CreatedAt, transactions[n-1].ID)
+	}
 	utils.SuccessWithMeta(c, "Transactions retrieved successfully", transactions, meta)
 }
 
@@ -72,9 +77,9 @@ func (h *TransactionHandler) Create(c *gin.Context) {
 		return
 	}
 
-	transaction, err := h.transactionService.Create(c.Request.Context(), claims.UserID, &req)
+	transaction, err := h.transactionService.Create(c.Request.Context(), claims.UserID, claims.Role, claims.StoreID, claims.TerminalID, &req)
 	if err != nil {
-		utils.BadRequest(c, err.Error())
+		respondTransactionError(c, err)
 		return
 	}
 
@@ -83,6 +88,12 @@ func (h *TransactionHandler) Create(c *gin.Context) {
 
 // UpdateStatus handles PATCH /api/v1/transactions/:id/status
 func (h *TransactionHandler) UpdateStatus(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
 	id := c.Param("id")
 
 	var req dto.UpdateTransactionStatusRequest
@@ -96,11 +107,21 @@ func (h *TransactionHandler) UpdateStatus(c *gin.Context) {
 		return
 	}
 
-	transaction, err := h.transactionService.UpdateStatus(c.Request.Context(), id, &req)
+	transaction, err := h.transactionService.UpdateStatus(c.Request.Context(), claims.UserID, claims.Role, claims.TerminalID, id, &req)
 	if err != nil {
-		utils.BadRequest(c, err.Error())
+		respondTransactionError(c, err)
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Transaction status updated successfully", transaction)
 }
+
+// respondTransactionError reports a failed sale or status change; actions that need a manager's
+// approval are forbidden rather than invalid
+func respondTransactionError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrOverrideRequired) {
+		utils.Forbidden(c, err.Error())
+		return
+	}
+	utils.BadRequest(c, err.Error())
+}
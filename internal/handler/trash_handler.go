@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/service"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// TrashHandler handles soft-deleted record endpoints
+type TrashHandler struct {
+	trashService *service.TrashService
+}
+
+// NewTrashHandler creates a new trash handler
+func NewTrashHandler(trashService *service.TrashService) *TrashHandler {
+	return &TrashHandler{trashService: trashService}
+}
+
+// List handles GET /api/v1/trash
+func (h *TrashHandler) List(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	var filter dto.TrashListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	if errors, ok := utils.Validate(&filter); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	items, total, err := h.trashService.List(c.Request.Context(), filter, pagination)
+	if err != nil {
+		utils.InternalServerError(c, "Failed to retrieve trash")
+		return
+	}
+
+	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	utils.SuccessWithMeta(c, "Trash retrieved successfully", items, meta)
+}
+
+// Restore returns a handler for POST /api/v1/{entityType}/:id/restore
+func (h *TrashHandler) Restore(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		if err := h.trashService.Restore(c.Request.Context(), entityType, id); err != nil {
+			utils.BadRequest(c, err.Error())
+			return
+		}
+
+		utils.SuccessResponse(c, http.StatusOK, "Record restored successfully", gin.H{"id": id, "type": entityType})
+	}
+}
+
+// Purge handles POST /api/v1/trash/purge
+func (h *TrashHandler) Purge(c *gin.Context) {
+	var req dto.PurgeTrashRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BadRequest(c, "Invalid request body")
+			return
+		}
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	resp, err := h.trashService.Purge(c.Request.Context(), req.OlderThanDays)
+	if err != nil {
+		utils.InternalServerError(c, "Failed to purge trash")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Trash purged successfully", resp)
+}
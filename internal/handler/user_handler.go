@@ -12,22 +12,24 @@ import (
 
 // UserHandler handles user management endpoints
 type UserHandler struct {
-	userService *service.UserService
+	userService   *service.UserService
+	loginSecurity *service.LoginSecurityService
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(userService *service.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService *service.UserService, loginSecurity *service.LoginSecurityService) *UserHandler {
+	return &UserHandler{userService: userService, loginSecurity: loginSecurity}
 }
 
 // List handles GET /api/v1/users
 func (h *UserHandler) List(c *gin.Context) {
 	pagination := utils.GetPagination(c)
 	role := c.Query("role")
+	storeID := storeScope(c, c.Query("store_id"))
 
-	users, total, err := h.userService.List(c.Request.Context(), role, pagination)
+	users, total, err := h.userService.List(c.Request.Context(), role, storeID, pagination)
 	if err != nil {
-		utils.InternalServerError(c, err.Error())
+		utils.ListError(c, err)
 		return
 	}
 
@@ -129,3 +131,38 @@ func (h *UserHandler) ResetPassword(c *gin.Context) {
 
 	utils.SuccessResponse(c, http.StatusOK, "Password reset successfully", nil)
 }
+
+// Unlock handles POST /api/v1/users/:id/unlock, which lifts a user's sign-in lockouts
+func (h *UserHandler) Unlock(c *gin.Context) {
+	if err := h.loginSecurity.Unlock(c.Request.Context(), c.Param("id"), clientInfo(c)); err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User unlocked successfully", nil)
+}
+
+// LoginEvents handles GET /api/v1/users/login-events
+func (h *UserHandler) LoginEvents(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	var filter dto.LoginEventFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	if errors, ok := utils.Validate(&filter); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	events, total, err := h.loginSecurity.ListEvents(c.Request.Context(), filter, pagination)
+	if err != nil {
+		utils.ListError(c, err)
+		return
+	}
+
+	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	utils.SuccessWithMeta(c, "Login events retrieved successfully", events, meta)
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/middleware"
+	"github.com/ilramdhan/pos-api/internal/service"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// VoucherHandler handles voucher endpoints
+type VoucherHandler struct {
+	voucherService *service.VoucherService
+}
+
+// NewVoucherHandler creates a new voucher handler
+func NewVoucherHandler(voucherService *service.VoucherService) *VoucherHandler {
+	return &VoucherHandler{voucherService: voucherService}
+}
+
+// List handles GET /api/v1/vouchers
+func (h *VoucherHandler) List(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	var filter dto.VoucherListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	vouchers, total, err := h.voucherService.List(c.Request.Context(), filter, pagination)
+	if err != nil {
+		utils.ListError(c, err)
+		return
+	}
+
+	meta := utils.NewMeta(pagination.Page, pagination.PerPage, total)
+	utils.SuccessWithMeta(c, "Vouchers retrieved successfully", vouchers, meta)
+}
+
+// Get handles GET /api/v1/vouchers/:id
+func (h *VoucherHandler) Get(c *gin.Context) {
+	id := c.Param("id")
+
+	voucher, err := h.voucherService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Voucher retrieved successfully", voucher)
+}
+
+// Generate handles POST /api/v1/vouchers/batch
+func (h *VoucherHandler) Generate(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	if claims == nil {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.GenerateVouchersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	batch, err := h.voucherService.GenerateBatch(c.Request.Context(), claims.UserID, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.CreatedResponse(c, "Vouchers generated successfully", batch)
+}
+
+// Update handles PUT /api/v1/vouchers/:id
+func (h *VoucherHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.UpdateVoucherRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	voucher, err := h.voucherService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Voucher updated successfully", voucher)
+}
+
+// Redemptions handles GET /api/v1/vouchers/:id/redemptions
+func (h *VoucherHandler) Redemptions(c *gin.Context) {
+	id := c.Param("id")
+
+	redemptions, err := h.voucherService.ListRedemptions(c.Request.Context(), id)
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Voucher redemptions retrieved successfully", redemptions)
+}
+
+// Validate handles POST /api/v1/pos/vouchers/validate
+func (h *VoucherHandler) Validate(c *gin.Context) {
+	var req dto.ValidateVoucherRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	if errors, ok := utils.Validate(&req); !ok {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	result, err := h.voucherService.Validate(c.Request.Context(), &req)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Voucher is valid", result)
+}
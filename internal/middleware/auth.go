@@ -50,44 +50,9 @@ func AuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
 			return
 		}
 
-		// Store claims in context
+		// Store claims in context and scope the request to the user's tenant
 		c.Set(UserContextKey, claims)
-		c.Next()
-	}
-}
-
-// RequireRole creates a middleware that requires specific roles
-func RequireRole(roles ...string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		claims, exists := c.Get(UserContextKey)
-		if !exists {
-			utils.Unauthorized(c, "User not authenticated")
-			c.Abort()
-			return
-		}
-
-		userClaims, ok := claims.(*utils.JWTClaims)
-		if !ok {
-			utils.Unauthorized(c, "Invalid user claims")
-			c.Abort()
-			return
-		}
-
-		// Check if user role is in the allowed roles
-		allowed := false
-		for _, role := range roles {
-			if userClaims.Role == role {
-				allowed = true
-				break
-			}
-		}
-
-		if !allowed {
-			utils.Forbidden(c, "You don't have permission to access this resource")
-			c.Abort()
-			return
-		}
-
+		c.Request = c.Request.WithContext(utils.WithTenant(c.Request.Context(), claims.TenantID))
 		c.Next()
 	}
 }
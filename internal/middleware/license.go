@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// FeatureChecker reports whether the current tenant's plan includes a feature
+type FeatureChecker interface {
+	HasFeature(ctx context.Context, feature string) (bool, error)
+}
+
+// RequireFeature creates a middleware that requires the tenant's plan to include a feature
+func RequireFeature(checker FeatureChecker, feature string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, err := checker.HasFeature(c.Request.Context(), feature)
+		if err != nil {
+			utils.InternalServerError(c, "Failed to check license")
+			c.Abort()
+			return
+		}
+
+		if !allowed {
+			utils.Forbidden(c, "Your plan does not include this feature; upgrade your license to use it")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// PermissionChecker reports whether a role of the current tenant grants a permission
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, role, permission string) (bool, error)
+}
+
+// RequirePermission creates a middleware that requires the user's role to grant a permission
+func RequirePermission(checker PermissionChecker, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userClaims := GetCurrentUser(c)
+		if userClaims == nil {
+			utils.Unauthorized(c, "User not authenticated")
+			c.Abort()
+			return
+		}
+
+		allowed, err := checker.HasPermission(c.Request.Context(), userClaims.Role, permission)
+		if err != nil {
+			utils.InternalServerError(c, "Failed to check permissions")
+			c.Abort()
+			return
+		}
+
+		if !allowed {
+			utils.Forbidden(c, "You don't have permission to access this resource")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
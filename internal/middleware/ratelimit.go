@@ -26,6 +26,16 @@ func NewIPRateLimiter(rps int, burst int) *IPRateLimiter {
 	}
 }
 
+// NewPerMinuteRateLimiter creates a rate limiter that allows perMinute requests a minute per key,
+// all of which may come at once
+func NewPerMinuteRateLimiter(perMinute int) *IPRateLimiter {
+	return &IPRateLimiter{
+		limiters: make(map[string]*rate.Limiter),
+		rps:      rate.Limit(float64(perMinute) / 60),
+		burst:    perMinute,
+	}
+}
+
 // getLimiter returns the rate limiter for the given IP address
 func (i *IPRateLimiter) getLimiter(ip string) *rate.Limiter {
 	i.mu.RLock()
@@ -76,6 +86,25 @@ func RateLimitMiddleware(limiter *IPRateLimiter) gin.HandlerFunc {
 	}
 }
 
+// UserRateLimitMiddleware rate limits each signed-in user separately, so users sharing a
+// store's IP address do not use up each other's requests. It must run after AuthMiddleware.
+func UserRateLimitMiddleware(limiter *IPRateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.ClientIP()
+		if claims := GetCurrentUser(c); claims != nil {
+			key = claims.UserID
+		}
+
+		if !limiter.getLimiter(key).Allow() {
+			utils.TooManyRequests(c)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // StartCleanup starts a background goroutine to clean up old limiters
 func (i *IPRateLimiter) StartCleanup(interval, maxAge time.Duration) {
 	go func() {
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+)
+
+// ProductBarcode is a scannable code assigned to a product or one of its variants
+type ProductBarcode struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"product_id"`
+	VariantID *string   `json:"variant_id,omitempty"`
+	Code      string    `json:"code"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Barcode type constants
+const (
+	BarcodeEAN13    = "ean13"
+	BarcodeUPCA     = "upca"
+	BarcodeInternal = "internal"
+	// BarcodeVariable registers the first 7 digits (prefix 2x plus a 5-digit item code) of
+	// EAN-13 labels printed by scales, which carry a weight or price in the following 5 digits
+	BarcodeVariable = "variable"
+)
+
+// Embedded barcode value kinds
+const (
+	EmbeddedWeight = "weight"
+	EmbeddedPrice  = "price"
+)
+
+// VariableBarcodePrefixLength is the number of leading digits that identify the item on a
+// weight or price embedded EAN-13 label
+const VariableBarcodePrefixLength = 7
+
+// IsVariableBarcodePrefix reports whether an EAN-13 code starts with a 2x prefix reserved for
+// in-store weight or price embedded labels
+func IsVariableBarcodePrefix(code string) bool {
+	return len(code) > 1 && code[0] == '2'
+}
+
+// EmbeddedKind returns what the value digits of a variable EAN-13 code hold: prefixes 20-24
+// carry the price in whole currency units and 25-29 the weight in grams.
+func EmbeddedKind(code string) string {
+	if code[1] <= '4' {
+		return EmbeddedPrice
+	}
+	return EmbeddedWeight
+}
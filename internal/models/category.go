@@ -14,3 +14,12 @@ type Category struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
+
+// CategoryActivity is one entry of a category's change history
+type CategoryActivity struct {
+	ID           string    `json:"id"`
+	CategoryID   string    `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	Event        string    `json:"event"`
+	CreatedAt    time.Time `json:"created_at"`
+}
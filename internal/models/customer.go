@@ -14,8 +14,66 @@ type Customer struct {
 	LoyaltyPoints int       `json:"loyalty_points"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Normalized contact details used for duplicate detection
+	PhoneNormalized string `json:"-"`
+	EmailNormalized string `json:"-"`
+
+	// Joined fields
+	Stats *CustomerStats `json:"stats,omitempty"`
+}
+
+// CustomerStats holds purchase metrics computed from a customer's completed transactions
+type CustomerStats struct {
+	LifetimeSpend float64    `json:"lifetime_spend"`
+	VisitCount    int        `json:"visit_count"`
+	AverageBasket float64    `json:"average_basket"`
+	FirstVisitAt  *time.Time `json:"first_visit_at,omitempty"`
+	LastVisitAt   *time.Time `json:"last_visit_at,omitempty"`
+	Segment       string     `json:"segment"`
+}
+
+// CustomerMerge is the audit record of a customer folded into another
+type CustomerMerge struct {
+	ID                string    `json:"id"`
+	TargetCustomerID  string    `json:"target_customer_id"`
+	MergedCustomerID  string    `json:"merged_customer_id"`
+	MergedName        string    `json:"merged_name"`
+	MergedEmail       string    `json:"merged_email,omitempty"`
+	MergedPhone       string    `json:"merged_phone,omitempty"`
+	PointsMerged      int       `json:"points_merged"`
+	TransactionsMoved int       `json:"transactions_moved"`
+	MergedBy          string    `json:"merged_by"`
+	CreatedAt         time.Time `json:"created_at"`
 }
 
+// CustomerFavoriteProduct represents a product a customer buys most often
+type CustomerFavoriteProduct struct {
+	ProductID   string  `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Quantity    int     `json:"quantity"`
+	TotalAmount float64 `json:"total_amount"`
+}
+
+// Customer segment constants (RFM-style: recency, frequency, monetary)
+const (
+	SegmentNew     = "new"
+	SegmentRegular = "regular"
+	SegmentLapsed  = "lapsed"
+	SegmentVIP     = "vip"
+)
+
+// Segment thresholds
+const (
+	// SegmentNewDays is how long after joining or a first purchase a customer counts as new
+	SegmentNewDays = 30
+	// SegmentLapsedDays is how long without a purchase before a customer counts as lapsed
+	SegmentLapsedDays = 90
+	// SegmentVIPVisits and SegmentVIPSpend must both be reached for the VIP segment
+	SegmentVIPVisits = 10
+	SegmentVIPSpend  = 5000000
+)
+
 // AddLoyaltyPoints adds points to the customer
 func (c *Customer) AddLoyaltyPoints(points int) {
 	c.LoyaltyPoints += points
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// HeldTransaction is a cart parked at the POS to be finished later. A cart held on a terminal
+// stays with the terminal, so the next user signing in to it can pick it up.
+type HeldTransaction struct {
+	ID           string                `json:"id"`
+	StoreID      string                `json:"store_id,omitempty"`
+	TerminalID   string                `json:"terminal_id,omitempty"`
+	HoldNumber   string                `json:"hold_number"`
+	CustomerID   *string               `json:"customer_id,omitempty"`
+	CustomerName string                `json:"customer_name"`
+	TotalAmount  float64               `json:"total_amount"`
+	Notes        string                `json:"notes"`
+	HeldBy       string                `json:"held_by"`
+	Items        []HeldTransactionItem `json:"items"`
+	ItemsCount   int                   `json:"items_count"`
+	CreatedAt    time.Time             `json:"created_at"`
+}
+
+// HeldTransactionItem is a line of a held cart
+type HeldTransactionItem struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id,omitempty"`
+	Quantity  int    `json:"quantity"`
+}
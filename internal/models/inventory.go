@@ -0,0 +1,116 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Ingredient is a raw material consumed by product recipes. Stock is tracked in Unit.
+type Ingredient struct {
+	ID                string    `json:"id"`
+	SKU               string    `json:"sku"`
+	Name              string    `json:"name"`
+	Unit              string    `json:"unit"`
+	Stock             float64   `json:"stock"`
+	LowStockThreshold float64   `json:"low_stock_threshold"`
+	IsActive          bool      `json:"is_active"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// IsLowStock checks if the ingredient is at or below its low stock threshold
+func (i *Ingredient) IsLowStock() bool {
+	return i.Stock <= i.LowStockThreshold
+}
+
+// RecipeItem is the amount of an ingredient consumed by one unit of a product
+type RecipeItem struct {
+	ID           string    `json:"id"`
+	ProductID    string    `json:"product_id"`
+	IngredientID string    `json:"ingredient_id"`
+	Quantity     float64   `json:"quantity"`
+	Unit         string    `json:"unit"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// Joined fields
+	Ingredient *Ingredient `json:"ingredient,omitempty"`
+}
+
+// StockMovement records a change to an ingredient's stock, or to a product's stock when
+// ProductID is set (at StoreID, if any). Quantity is signed and expressed in the ingredient's
+// unit, or in units of the product.
+type StockMovement struct {
+	ID           string    `json:"id"`
+	IngredientID string    `json:"ingredient_id"`
+	ProductID    string    `json:"product_id,omitempty"`
+	VariantID    *string   `json:"variant_id,omitempty"` // Set when the product's stock is kept on a variant
+	StoreID      *string   `json:"store_id,omitempty"`
+	Type         string    `json:"type"`
+	Quantity     float64   `json:"quantity"`
+	BalanceAfter float64   `json:"balance_after"`
+	ReferenceID  *string   `json:"reference_id,omitempty"`
+	Note         string    `json:"note,omitempty"`
+	CreatedBy    string    `json:"created_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// Joined fields
+	IngredientName string `json:"ingredient_name,omitempty"`
+	Unit           string `json:"unit,omitempty"`
+	ProductName    string `json:"product_name,omitempty"`
+	SKU            string `json:"sku,omitempty"`
+	VariantName    string `json:"variant_name,omitempty"`
+	CreatedByName  string `json:"created_by_name,omitempty"`
+}
+
+// Stock movement type constants
+const (
+	MovementSale        = "sale"
+	MovementReturn      = "return"
+	MovementRestock     = "restock"
+	MovementAdjustment  = "adjustment"
+	MovementWaste       = "waste"
+	MovementTransferOut = "transfer_out"
+	MovementTransferIn  = "transfer_in"
+)
+
+// Unit is a unit of measure. Factor converts a quantity to the base unit of its dimension.
+type Unit struct {
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+	Dimension string  `json:"dimension"`
+	Factor    float64 `json:"factor"`
+}
+
+// Unit dimension constants
+const (
+	DimensionMass   = "mass"
+	DimensionVolume = "volume"
+	DimensionCount  = "count"
+)
+
+// Units lists the supported units of measure by code
+var Units = map[string]Unit{
+	"mg":  {Code: "mg", Name: "Milligram", Dimension: DimensionMass, Factor: 0.001},
+	"g":   {Code: "g", Name: "Gram", Dimension: DimensionMass, Factor: 1},
+	"kg":  {Code: "kg", Name: "Kilogram", Dimension: DimensionMass, Factor: 1000},
+	"ml":  {Code: "ml", Name: "Millilitre", Dimension: DimensionVolume, Factor: 1},
+	"l":   {Code: "l", Name: "Litre", Dimension: DimensionVolume, Factor: 1000},
+	"pcs": {Code: "pcs", Name: "Piece", Dimension: DimensionCount, Factor: 1},
+	"dz":  {Code: "dz", Name: "Dozen", Dimension: DimensionCount, Factor: 12},
+}
+
+// ConvertQuantity converts a quantity between two units of the same dimension
+func ConvertQuantity(quantity float64, from, to string) (float64, error) {
+	fromUnit, ok := Units[from]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", from)
+	}
+	toUnit, ok := Units[to]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", to)
+	}
+	if fromUnit.Dimension != toUnit.Dimension {
+		return 0, fmt.Errorf("cannot convert %s to %s", from, to)
+	}
+	return quantity * fromUnit.Factor / toUnit.Factor, nil
+}
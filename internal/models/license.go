@@ -0,0 +1,47 @@
+package models
+
+// Plans a license can grant. Tenants without a valid license are on the free plan.
+const (
+	PlanFree       = "free"
+	PlanPro        = "pro"
+	PlanEnterprise = "enterprise"
+)
+
+// Features that are only available on some plans
+const (
+	FeatureExport          = "export"           // CSV exports
+	FeatureAdvancedReports = "advanced_reports" // Sales, product and category reports
+	FeatureMultiStore      = "multi_store"      // More than one store and stock transfers between them
+)
+
+// PlanFeatures lists the features included in each plan
+var PlanFeatures = map[string][]string{
+	PlanFree:       {},
+	PlanPro:        {FeatureExport, FeatureAdvancedReports},
+	PlanEnterprise: {FeatureExport, FeatureAdvancedReports, FeatureMultiStore},
+}
+
+// Limits of the free plan
+const (
+	FreePlanSeats  = 3
+	FreePlanStores = 1
+)
+
+// License statuses
+const (
+	LicenseActive     = "active"
+	LicenseExpired    = "expired"
+	LicenseInvalid    = "invalid"
+	LicenseMissing    = "missing"
+	LicenseUnenforced = "unenforced" // No license public key is configured; nothing is limited
+)
+
+// PlanHasFeature reports whether a plan includes a feature
+func PlanHasFeature(plan, feature string) bool {
+	for _, f := range PlanFeatures[plan] {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginEvent records a password sign-in, a failed one or a lockout, or a failed override
+// approval. TenantID and UserID are empty for emails that have no account.
+type LoginEvent struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id,omitempty"`
+	UserID    string    `json:"user_id,omitempty"`
+	Email     string    `json:"email"`
+	Event     string    `json:"event"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Login events
+const (
+	LoginSucceeded       = "login_succeeded"
+	LoginFailed          = "login_failed"
+	LoginBlocked         = "login_blocked"  // Attempted while the account or IP address was locked or delayed
+	LoginMFAChallenged   = "mfa_challenged" // The password was right and a second factor was asked for
+	LoginMFAFailed       = "mfa_failed"
+	LoginAccountLocked   = "account_locked"
+	LoginIPLocked        = "ip_locked"
+	LoginAccountUnlocked = "account_unlocked"
+	LoginApprovalFailed  = "approval_failed" // A wrong password or PIN was given to approve an override
+)
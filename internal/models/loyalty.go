@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// LoyaltyLedgerEntry records a single change to a customer's loyalty point balance
+type LoyaltyLedgerEntry struct {
+	ID            string     `json:"id"`
+	CustomerID    string     `json:"customer_id"`
+	TransactionID *string    `json:"transaction_id,omitempty"`
+	Type          string     `json:"type"`
+	Points        int        `json:"points"`
+	BalanceAfter  int        `json:"balance_after"`
+	Remaining     int        `json:"-"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	Note          string     `json:"note,omitempty"`
+	CreatedBy     string     `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Loyalty ledger entry type constants
+const (
+	LoyaltyEarn   = "earn"
+	LoyaltyRedeem = "redeem"
+	LoyaltyAdjust = "adjust"
+	LoyaltyExpire = "expire"
+)
+
+// Loyalty tier constants
+const (
+	TierMember = "member"
+	TierSilver = "silver"
+	TierGold   = "gold"
+)
+
+// IsCredit checks if the entry adds points to the balance
+func (e *LoyaltyLedgerEntry) IsCredit() bool {
+	return e.Points > 0
+}
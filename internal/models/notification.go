@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// Notification is a message shown to a user in the app
+type Notification struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"-"`
+	UserID    string    `json:"-"`
+	Type      string    `json:"type"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	IsRead    bool      `json:"is_read"`
+	ActionURL string    `json:"action_url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Notification types
+const (
+	NotificationSystem   = "system"
+	NotificationLowStock = "low_stock"
+	NotificationSecurity = "security"
+)
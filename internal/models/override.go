@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+)
+
+// Override is a manager's approval of a sensitive action for a user who may not perform it
+// alone. The approver signs in on the user's terminal and the user receives a single-use
+// token, which the action then presents from the same terminal. Discounts and price overrides
+// are approved for one amount.
+type Override struct {
+	ID          string     `json:"id"`
+	TenantID    string     `json:"tenant_id"`
+	Action      string     `json:"action"`
+	ReferenceID string     `json:"reference_id,omitempty"` // The transaction the approval was used on
+	Amount      *float64   `json:"amount,omitempty"`       // The approved discount or unit price
+	TerminalID  string     `json:"terminal_id,omitempty"`  // The terminal the approval was given on
+	RequestedBy string     `json:"requested_by"`
+	ApprovedBy  string     `json:"approved_by"`
+	Reason      string     `json:"reason"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	UsedAt      *time.Time `json:"used_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// OverrideUse is an override token presented for an action. It is used up on ReferenceID in
+// the same database transaction as the action, so a failed action leaves the token unused.
+// It only matches an approval of the same amount given on the same terminal.
+type OverrideUse struct {
+	TokenHash   string
+	Action      string
+	RequestedBy string
+	ReferenceID string
+	Amount      *float64
+	TerminalID  string
+}
+
+// Actions that can be approved by override
+const (
+	OverrideVoid          = "void"
+	OverrideRefund        = "refund"
+	OverrideDiscount      = "discount"
+	OverridePriceOverride = "price_override"
+	OverrideNoSale        = "no_sale"
+)
+
+// OverridePermissions maps each action to the permission that allows it without approval, and
+// that an approver must hold
+var OverridePermissions = map[string]string{
+	OverrideVoid:          PermTransactionsVoid,
+	OverrideRefund:        PermTransactionsRefund,
+	OverrideDiscount:      PermTransactionsDiscount,
+	OverridePriceOverride: PermPriceOverride,
+	OverrideNoSale:        PermNoSale,
+}
+
+// OverrideNeedsAmount reports whether approvals of an action are given for one amount
+func OverrideNeedsAmount(action string) bool {
+	return action == OverrideDiscount || action == OverridePriceOverride
+}
+
+// OverrideTokenTTL is how long an override token can be used after it is issued
+const OverrideTokenTTL = 5 * time.Minute
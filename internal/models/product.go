@@ -6,20 +6,30 @@ import (
 
 // Product represents a product in the inventory
 type Product struct {
-	ID          string    `json:"id"`
-	CategoryID  string    `json:"category_id"`
-	SKU         string    `json:"sku"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	Stock       int       `json:"stock"`
-	ImageURL    string    `json:"image_url,omitempty"`
-	IsActive    bool      `json:"is_active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID           string    `json:"id"`
+	CategoryID   string    `json:"category_id"`
+	SKU          string    `json:"sku"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	Price        float64   `json:"price"`
+	CostPrice    float64   `json:"cost_price"`
+	Stock        int       `json:"stock"`
+	ImageURL     string    `json:"image_url,omitempty"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	IsActive     bool      `json:"is_active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 
 	// Joined fields
-	Category *Category `json:"category,omitempty"`
+	Category       *Category        `json:"category,omitempty"`
+	Variants       []ProductVariant `json:"variants,omitempty"`
+	ModifierGroups []ModifierGroup  `json:"modifier_groups,omitempty"`
+	Barcodes       []ProductBarcode `json:"barcodes,omitempty"`
+}
+
+// HasVariants reports whether the product is sold through its variants
+func (p *Product) HasVariants() bool {
+	return len(p.Variants) > 0
 }
 
 // IsInStock checks if the product has available stock
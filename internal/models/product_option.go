@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+)
+
+// ProductVariant is a sellable version of a product with its own SKU, price and stock,
+// such as a size of a drink
+type ProductVariant struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"product_id"`
+	SKU       string    `json:"sku"`
+	Name      string    `json:"name"`
+	Price     float64   `json:"price"`
+	Stock     int       `json:"stock"`
+	IsActive  bool      `json:"is_active"`
+	SortOrder int       `json:"sort_order"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HasSufficientStock checks if there's enough stock for a given quantity
+func (v *ProductVariant) HasSufficientStock(quantity int) bool {
+	return v.Stock >= quantity
+}
+
+// ModifierGroup is a set of add-ons or preferences offered with a product, such as sugar level
+// or toppings. MaxSelect of zero means any number of modifiers may be chosen.
+type ModifierGroup struct {
+	ID        string     `json:"id"`
+	ProductID string     `json:"product_id"`
+	Name      string     `json:"name"`
+	MinSelect int        `json:"min_select"`
+	MaxSelect int        `json:"max_select"`
+	SortOrder int        `json:"sort_order"`
+	Modifiers []Modifier `json:"modifiers"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Modifier is a single choice within a modifier group and the amount it adds to the unit price
+type Modifier struct {
+	ID         string  `json:"id"`
+	GroupID    string  `json:"group_id"`
+	Name       string  `json:"name"`
+	PriceDelta float64 `json:"price_delta"`
+	IsActive   bool    `json:"is_active"`
+	SortOrder  int     `json:"sort_order"`
+}
+
+// TransactionItemModifier records a modifier chosen for a transaction item at the time of sale
+type TransactionItemModifier struct {
+	ID                string  `json:"id"`
+	TransactionItemID string  `json:"transaction_item_id"`
+	ModifierID        string  `json:"modifier_id"`
+	GroupName         string  `json:"group_name"`
+	Name              string  `json:"name"`
+	PriceDelta        float64 `json:"price_delta"`
+}
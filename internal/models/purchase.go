@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+)
+
+// Supplier is a vendor that purchase orders are placed with
+type Supplier struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	ContactName string    `json:"contact_name"`
+	Email       string    `json:"email"`
+	Phone       string    `json:"phone"`
+	Address     string    `json:"address"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// PurchaseOrder is an order for products from a supplier. Stock only changes when its
+// lines are received.
+type PurchaseOrder struct {
+	ID         string              `json:"id"`
+	PONumber   string              `json:"po_number"`
+	SupplierID string              `json:"supplier_id"`
+	StoreID    *string             `json:"store_id,omitempty"`
+	Status     string              `json:"status"`
+	ExpectedAt *time.Time          `json:"expected_at,omitempty"`
+	Notes      string              `json:"notes"`
+	CreatedBy  string              `json:"created_by"`
+	OrderedAt  *time.Time          `json:"ordered_at,omitempty"`
+	ReceivedAt *time.Time          `json:"received_at,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+	Lines      []PurchaseOrderLine `json:"lines,omitempty"`
+
+	// Joined fields
+	SupplierName string  `json:"supplier_name,omitempty"`
+	ExpectedCost float64 `json:"expected_cost"`
+}
+
+// PurchaseOrderLine is the quantity of one product on a purchase order. UnitCost is the
+// expected cost per unit.
+type PurchaseOrderLine struct {
+	ID               string    `json:"id"`
+	PurchaseOrderID  string    `json:"purchase_order_id"`
+	ProductID        string    `json:"product_id"`
+	Quantity         int       `json:"quantity"`
+	ReceivedQuantity int       `json:"received_quantity"`
+	UnitCost         float64   `json:"unit_cost"`
+	CreatedAt        time.Time `json:"created_at"`
+
+	// Joined fields
+	ProductName string `json:"product_name,omitempty"`
+	ProductSKU  string `json:"product_sku,omitempty"`
+}
+
+// Outstanding returns the quantity still to be received
+func (l *PurchaseOrderLine) Outstanding() int {
+	return l.Quantity - l.ReceivedQuantity
+}
+
+// GoodsReceipt is a quantity of one purchase order line received into stock at UnitCost
+type GoodsReceipt struct {
+	LineID   string
+	Quantity int
+	UnitCost float64
+}
+
+// Purchase order status constants
+const (
+	PurchaseOrderDraft             = "draft"
+	PurchaseOrderOrdered           = "ordered"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived          = "received"
+	PurchaseOrderCancelled         = "cancelled"
+)
+
+// IsReceivable reports whether goods can be received against the order
+func (o *PurchaseOrder) IsReceivable() bool {
+	return o.Status == PurchaseOrderOrdered || o.Status == PurchaseOrderPartiallyReceived
+}
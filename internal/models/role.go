@@ -0,0 +1,152 @@
+package models
+
+import (
+	"time"
+)
+
+// Role is a named bundle of permissions assigned to users. The built-in admin, manager and
+// cashier roles exist in every tenant; tenants can change the permissions of manager and
+// cashier and add roles of their own.
+type Role struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	RequireMFA  bool      `json:"require_mfa"` // Users holding the role must use two-factor authentication
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Permissions checked by the API
+const (
+	PermUsersManage          = "users.manage"
+	PermRolesManage          = "roles.manage"
+	PermCategoriesManage     = "categories.manage"
+	PermCategoriesDelete     = "categories.delete"
+	PermProductsManage       = "products.manage"
+	PermProductsDelete       = "products.delete"
+	PermProductsExport       = "products.export"
+	PermProductsPriceUpdate  = "products.price.update"
+	PermInventoryManage      = "inventory.manage"
+	PermStockTakesManage     = "stock_takes.manage"
+	PermStockTransfersManage = "stock_transfers.manage"
+	PermCustomersDelete      = "customers.delete"
+	PermCustomersMerge       = "customers.merge"
+	PermCustomersMergesView  = "customers.merges.view"
+	PermLoyaltyAdjust        = "loyalty.adjust"
+	PermTransactionsRefund   = "transactions.refund"
+	PermTransactionsVoid     = "transactions.void"
+	PermTransactionsDiscount = "transactions.discount"
+	PermPriceOverride        = "transactions.price_override"
+	PermNoSale               = "pos.no_sale"
+	PermOverridesView        = "overrides.view"
+	PermVouchersManage       = "vouchers.manage"
+	PermStoresManage         = "stores.manage"
+	PermTerminalsManage      = "terminals.manage"
+	PermPurchasingManage     = "purchasing.manage"
+	PermTrashManage          = "trash.manage"
+	PermReportsView          = "reports.view"
+	PermReportsInventory     = "reports.inventory"
+	PermLicenseView          = "license.view"
+	PermLicenseManage        = "license.manage"
+	PermSystemHealth         = "system.health"
+)
+
+// PermissionInfo describes a permission for role editors
+type PermissionInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// AllPermissions lists every permission in display order
+var AllPermissions = []PermissionInfo{
+	{PermUsersManage, "Manage users and their stores"},
+	{PermRolesManage, "Manage roles and their permissions"},
+	{PermCategoriesManage, "Create and edit categories"},
+	{PermCategoriesDelete, "Delete categories"},
+	{PermProductsManage, "Create, edit and import products, variants, modifiers, barcodes, images and recipes"},
+	{PermProductsDelete, "Delete products"},
+	{PermProductsExport, "Export products"},
+	{PermProductsPriceUpdate, "Change product and store prices"},
+	{PermInventoryManage, "Manage ingredients and their stock"},
+	{PermStockTakesManage, "Open, approve and cancel stock takes"},
+	{PermStockTransfersManage, "Request, ship and receive stock transfers"},
+	{PermCustomersDelete, "Delete customers"},
+	{PermCustomersMerge, "Merge duplicate customers"},
+	{PermCustomersMergesView, "View customer merge history"},
+	{PermLoyaltyAdjust, "Adjust customers' loyalty points"},
+	{PermTransactionsRefund, "Refund transactions"},
+	{PermTransactionsVoid, "Void (cancel) transactions"},
+	{PermTransactionsDiscount, "Give manual discounts on sales"},
+	{PermPriceOverride, "Sell items at a price other than their own"},
+	{PermNoSale, "Open the cash drawer without a sale"},
+	{PermOverridesView, "View manager approvals"},
+	{PermVouchersManage, "Generate and manage vouchers"},
+	{PermStoresManage, "Create and edit stores"},
+	{PermTerminalsManage, "Register POS terminals and manage their keys"},
+	{PermPurchasingManage, "Manage suppliers and purchase orders"},
+	{PermTrashManage, "View, restore and purge deleted records"},
+	{PermReportsView, "View sales, product and category reports"},
+	{PermReportsInventory, "View purchasing and in-transit stock reports"},
+	{PermLicenseView, "View the license and its usage"},
+	{PermLicenseManage, "Activate license keys"},
+	{PermSystemHealth, "View the database, storage and terminal health report"},
+}
+
+// managerPermissions are the default permissions of the manager role
+var managerPermissions = []string{
+	PermCategoriesManage,
+	PermProductsManage,
+	PermProductsExport,
+	PermProductsPriceUpdate,
+	PermInventoryManage,
+	PermStockTakesManage,
+	PermStockTransfersManage,
+	PermCustomersDelete,
+	PermCustomersMergesView,
+	PermLoyaltyAdjust,
+	PermTransactionsRefund,
+	PermTransactionsVoid,
+	PermTransactionsDiscount,
+	PermPriceOverride,
+	PermNoSale,
+	PermOverridesView,
+	PermVouchersManage,
+	PermPurchasingManage,
+	PermReportsInventory,
+	PermLicenseView,
+}
+
+// BuiltInRoles maps the built-in roles to their default permissions. Admins always hold every
+// permission so that a tenant cannot lock itself out.
+var BuiltInRoles = map[string][]string{
+	RoleAdmin:   PermissionNames(),
+	RoleManager: managerPermissions,
+	RoleCashier: {},
+}
+
+// PermissionNames returns the names of all permissions
+func PermissionNames() []string {
+	names := make([]string, len(AllPermissions))
+	for i, p := range AllPermissions {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// IsPermission reports whether name is a known permission
+func IsPermission(name string) bool {
+	for _, p := range AllPermissions {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBuiltInRole reports whether name is one of the built-in roles
+func IsBuiltInRole(name string) bool {
+	_, ok := BuiltInRoles[name]
+	return ok
+}
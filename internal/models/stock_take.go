@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+)
+
+// StockTake is a physical inventory count of one category, or of the whole store when
+// CategoryID is nil. Stock only changes when it is approved.
+type StockTake struct {
+	ID         string          `json:"id"`
+	Reference  string          `json:"reference"`
+	StoreID    *string         `json:"store_id,omitempty"`
+	CategoryID *string         `json:"category_id,omitempty"`
+	Status     string          `json:"status"`
+	Notes      string          `json:"notes"`
+	CreatedBy  string          `json:"created_by"`
+	ApprovedBy string          `json:"approved_by,omitempty"`
+	ApprovedAt *time.Time      `json:"approved_at,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	Items      []StockTakeItem `json:"items,omitempty"`
+
+	// Joined fields
+	CategoryName     string  `json:"category_name,omitempty"`
+	ItemCount        int     `json:"item_count"`
+	CountedCount     int     `json:"counted_count"`
+	VarianceQuantity int     `json:"variance_quantity"`
+	VarianceValue    float64 `json:"variance_value"`
+}
+
+// StockTakeItem is one product in a stock take. ExpectedStock is the system stock when the
+// product was last counted, or when the stock take was opened if it has not been counted yet.
+type StockTakeItem struct {
+	ID              string     `json:"id"`
+	StockTakeID     string     `json:"stock_take_id"`
+	ProductID       string     `json:"product_id"`
+	ExpectedStock   int        `json:"expected_stock"`
+	CountedQuantity *int       `json:"counted_quantity,omitempty"`
+	CountedBy       string     `json:"counted_by,omitempty"`
+	CountedAt       *time.Time `json:"counted_at,omitempty"`
+
+	// Joined fields
+	ProductName string  `json:"product_name,omitempty"`
+	ProductSKU  string  `json:"product_sku,omitempty"`
+	CostPrice   float64 `json:"cost_price"`
+}
+
+// Variance returns counted minus expected stock, or nil if the product has not been counted
+func (i *StockTakeItem) Variance() *int {
+	if i.CountedQuantity == nil {
+		return nil
+	}
+	variance := *i.CountedQuantity - i.ExpectedStock
+	return &variance
+}
+
+// StockCount is a counted quantity of one product submitted to a stock take
+type StockCount struct {
+	ProductID string
+	Quantity  int
+}
+
+// Stock take status constants
+const (
+	StockTakeCounting  = "counting"
+	StockTakeApproved  = "approved"
+	StockTakeCancelled = "cancelled"
+)
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+)
+
+// StockTransfer moves stock from one store to another. Stock leaves the source store when the
+// transfer is shipped and arrives at the destination when it is received.
+type StockTransfer struct {
+	ID          string              `json:"id"`
+	Reference   string              `json:"reference"`
+	FromStoreID string              `json:"from_store_id"`
+	ToStoreID   string              `json:"to_store_id"`
+	Status      string              `json:"status"`
+	Notes       string              `json:"notes"`
+	RequestedBy string              `json:"requested_by"`
+	ShippedBy   string              `json:"shipped_by,omitempty"`
+	ShippedAt   *time.Time          `json:"shipped_at,omitempty"`
+	ReceivedBy  string              `json:"received_by,omitempty"`
+	ReceivedAt  *time.Time          `json:"received_at,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	Items       []StockTransferItem `json:"items,omitempty"`
+
+	// Joined fields
+	FromStoreName       string  `json:"from_store_name,omitempty"`
+	ToStoreName         string  `json:"to_store_name,omitempty"`
+	ItemCount           int     `json:"item_count"`
+	RequestedQuantity   int     `json:"requested_quantity"`
+	ShippedQuantity     int     `json:"shipped_quantity"`
+	ReceivedQuantity    int     `json:"received_quantity"`
+	DiscrepancyQuantity int     `json:"discrepancy_quantity"`
+	ShippedValue        float64 `json:"shipped_value"`
+}
+
+// StockTransferItem is one product on a transfer. UnitCost is the product's cost price when it
+// was shipped.
+type StockTransferItem struct {
+	ID                string  `json:"id"`
+	TransferID        string  `json:"transfer_id"`
+	ProductID         string  `json:"product_id"`
+	RequestedQuantity int     `json:"requested_quantity"`
+	ShippedQuantity   *int    `json:"shipped_quantity,omitempty"`
+	ReceivedQuantity  *int    `json:"received_quantity,omitempty"`
+	UnitCost          float64 `json:"unit_cost"`
+
+	// Joined fields
+	ProductName string `json:"product_name,omitempty"`
+	ProductSKU  string `json:"product_sku,omitempty"`
+}
+
+// Discrepancy returns received minus shipped quantity, or nil until the item is received
+func (i *StockTransferItem) Discrepancy() *int {
+	if i.ShippedQuantity == nil || i.ReceivedQuantity == nil {
+		return nil
+	}
+	discrepancy := *i.ReceivedQuantity - *i.ShippedQuantity
+	return &discrepancy
+}
+
+// TransferQuantity is the quantity of one product shipped or received on a transfer
+type TransferQuantity struct {
+	ProductID string
+	Quantity  int
+}
+
+// Stock transfer status constants
+const (
+	TransferRequested = "requested"
+	TransferInTransit = "in_transit"
+	TransferReceived  = "received"
+	TransferCancelled = "cancelled"
+)
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// Store is an outlet with its own stock levels, prices, sales and staff
+type Store struct {
+	ID        string    `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	Phone     string    `json:"phone"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StorePrice overrides a product's price at one store. A nil price removes the override.
+type StorePrice struct {
+	ProductID string   `json:"product_id"`
+	Price     *float64 `json:"price"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// Tenant is a business using the system. Every record belongs to exactly one tenant and is
+// only visible to that tenant's users.
+type Tenant struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	LicenseKey string    `json:"-"`
+	IsActive   bool      `json:"is_active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+)
+
+// Terminal is a registered POS device or kitchen display at a store. Staff sign in to POS
+// terminals with their PIN; only a hash of the terminal's key is kept. Terminals report in
+// with heartbeats carrying their app version and measured latency.
+type Terminal struct {
+	ID         string     `json:"id"`
+	TenantID   string     `json:"tenant_id"`
+	StoreID    string     `json:"store_id"`
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	IsActive   bool       `json:"is_active"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"` // Last PIN sign-in
+	AppVersion string     `json:"app_version"`
+	LatencyMS  *int       `json:"latency_ms,omitempty"`   // As measured by the terminal at its last heartbeat
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"` // Last heartbeat
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Terminal types
+const (
+	TerminalTypePOS = "pos"
+	TerminalTypeKDS = "kds" // Kitchen display
+)
+
+// Terminal statuses
+const (
+	TerminalOnline   = "online"
+	TerminalOffline  = "offline"
+	TerminalInactive = "inactive"
+)
+
+// Status reports whether the terminal is online: active and heard from within offlineAfter
+func (t *Terminal) Status(now time.Time, offlineAfter time.Duration) string {
+	if !t.IsActive {
+		return TerminalInactive
+	}
+	if t.LastSeenAt == nil || now.Sub(*t.LastSeenAt) > offlineAfter {
+		return TerminalOffline
+	}
+	return TerminalOnline
+}
+
+// LoginAttempt counts consecutive failed sign-ins for a key such as a user or a terminal.
+// A successful sign-in clears it.
+type LoginAttempt struct {
+	Key         string     `json:"key"`
+	Failures    int        `json:"failures"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// IsLocked reports whether sign-ins for the key are locked at now
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return a != nil && a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
@@ -17,6 +17,10 @@ type Transaction struct {
 	PaymentMethod  string    `json:"payment_method"`
 	Status         string    `json:"status"`
 	Notes          string    `json:"notes,omitempty"`
+	VoucherCode    string    `json:"voucher_code,omitempty"`
+	PointsEarned   int       `json:"points_earned"`
+	PointsRedeemed int       `json:"points_redeemed"`
+	StoreID        *string   `json:"store_id,omitempty"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 
@@ -24,6 +28,17 @@ type Transaction struct {
 	User     *User             `json:"user,omitempty"`
 	Customer *Customer         `json:"customer,omitempty"`
 	Items    []TransactionItem `json:"items,omitempty"`
+
+	// Redemption is written atomically with the transaction when a voucher is applied
+	Redemption *VoucherRedemption `json:"-"`
+	// LoyaltyEntries are posted to the loyalty ledger in the same database transaction
+	LoyaltyEntries []*LoyaltyLedgerEntry `json:"-"`
+	// StockMovements change product, variant and recipe ingredient stock in the same database
+	// transaction
+	StockMovements []*StockMovement `json:"-"`
+	// Overrides are the manager approvals the sale or status change uses up, in the same
+	// database transaction
+	Overrides []*OverrideUse `json:"-"`
 }
 
 // TransactionItem represents a line item in a transaction
@@ -32,13 +47,16 @@ type TransactionItem struct {
 	TransactionID string    `json:"transaction_id"`
 	ProductID     string    `json:"product_id"`
 	ProductName   string    `json:"product_name"`
+	VariantID     *string   `json:"variant_id,omitempty"`
+	VariantName   string    `json:"variant_name,omitempty"`
 	UnitPrice     float64   `json:"unit_price"`
 	Quantity      int       `json:"quantity"`
 	Subtotal      float64   `json:"subtotal"`
 	CreatedAt     time.Time `json:"created_at"`
 
 	// Joined fields
-	Product *Product `json:"product,omitempty"`
+	Product   *Product                  `json:"product,omitempty"`
+	Modifiers []TransactionItemModifier `json:"modifiers,omitempty"`
 }
 
 // Transaction status constants
@@ -63,7 +81,7 @@ func (t *Transaction) IsCompleted() bool {
 
 // IsCancellable checks if the transaction can be cancelled
 func (t *Transaction) IsCancellable() bool {
-	return t.Status == StatusPending
+	return t.Status == StatusPending || t.Status == StatusCompleted
 }
 
 // IsRefundable checks if the transaction can be refunded
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// TrashItem represents a soft-deleted record awaiting restore or purge
+type TrashItem struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// Trash item type constants
+const (
+	TrashProducts   = "products"
+	TrashCategories = "categories"
+	TrashCustomers  = "customers"
+	TrashUsers      = "users"
+)
@@ -4,11 +4,16 @@ import (
 	"time"
 )
 
-// User represents a system user (admin, manager, or cashier)
+// User represents a system user. Role names a built-in role (admin, manager or cashier) or a
+// custom role of the tenant.
 type User struct {
 	ID           string    `json:"id"`
+	TenantID     string    `json:"tenant_id"`
 	Email        string    `json:"email"`
 	PasswordHash string    `json:"-"`
+	PINHash      string    `json:"-"` // Empty when the user has not set a PIN
+	TOTPSecret   string    `json:"-"` // Set once the user starts enrolling in two-factor authentication
+	TOTPEnabled  bool      `json:"mfa_enabled"`
 	Name         string    `json:"name"`
 	Phone        string    `json:"phone"`
 	Role         string    `json:"role"`
@@ -17,7 +22,7 @@ type User struct {
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
-// UserRole constants
+// Built-in roles
 const (
 	RoleAdmin   = "admin"
 	RoleManager = "manager"
@@ -38,18 +43,3 @@ func (u *User) IsManager() bool {
 func (u *User) IsCashier() bool {
 	return u.Role == RoleCashier
 }
-
-// CanManageProducts checks if the user can create/update/delete products
-func (u *User) CanManageProducts() bool {
-	return u.Role == RoleAdmin || u.Role == RoleManager
-}
-
-// CanViewReports checks if the user can view reports
-func (u *User) CanViewReports() bool {
-	return u.Role == RoleAdmin || u.Role == RoleManager
-}
-
-// CanDeleteRecords checks if the user can delete records
-func (u *User) CanDeleteRecords() bool {
-	return u.Role == RoleAdmin
-}
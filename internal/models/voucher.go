@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+)
+
+// Voucher represents a discount code that can be redeemed at checkout
+type Voucher struct {
+	ID              string     `json:"id"`
+	Code            string     `json:"code"`
+	BatchID         string     `json:"batch_id"`
+	DiscountType    string     `json:"discount_type"`
+	DiscountValue   float64    `json:"discount_value"`
+	MaxDiscount     float64    `json:"max_discount"`
+	MinSpend        float64    `json:"min_spend"`
+	MaxUses         int        `json:"max_uses"`
+	UsesPerCustomer int        `json:"uses_per_customer"`
+	UsedCount       int        `json:"used_count"`
+	ValidFrom       *time.Time `json:"valid_from,omitempty"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	IsActive        bool       `json:"is_active"`
+	CreatedBy       string     `json:"created_by"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// VoucherRedemption records a single use of a voucher on a transaction
+type VoucherRedemption struct {
+	ID             string    `json:"id"`
+	VoucherID      string    `json:"voucher_id"`
+	TransactionID  string    `json:"transaction_id"`
+	CustomerID     *string   `json:"customer_id,omitempty"`
+	UserID         string    `json:"user_id"`
+	DiscountAmount float64   `json:"discount_amount"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	// Joined fields
+	InvoiceNumber string `json:"invoice_number,omitempty"`
+}
+
+// Voucher discount type constants
+const (
+	DiscountTypePercentage = "percentage"
+	DiscountTypeFixed      = "fixed"
+)
+
+// IsExpired checks if the voucher has passed its expiry time
+func (v *Voucher) IsExpired(now time.Time) bool {
+	return v.ExpiresAt != nil && now.After(*v.ExpiresAt)
+}
+
+// IsStarted checks if the voucher validity period has begun
+func (v *Voucher) IsStarted(now time.Time) bool {
+	return v.ValidFrom == nil || !now.Before(*v.ValidFrom)
+}
+
+// IsExhausted checks if the voucher has reached its usage cap (0 means unlimited)
+func (v *Voucher) IsExhausted() bool {
+	return v.MaxUses > 0 && v.UsedCount >= v.MaxUses
+}
+
+// CalculateDiscount returns the discount for the given subtotal, never exceeding it
+func (v *Voucher) CalculateDiscount(subtotal float64) float64 {
+	var discount float64
+	switch v.DiscountType {
+	case DiscountTypePercentage:
+		discount = subtotal * v.DiscountValue / 100
+		if v.MaxDiscount > 0 && discount > v.MaxDiscount {
+			discount = v.MaxDiscount
+		}
+	case DiscountTypeFixed:
+		discount = v.DiscountValue
+	}
+	if discount > subtotal {
+		discount = subtotal
+	}
+	return discount
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"time"
 
 	"github.com/ilramdhan/pos-api/internal/models"
 	"github.com/ilramdhan/pos-api/internal/utils"
@@ -19,11 +21,11 @@ func NewCategoryRepository(db *sql.DB) CategoryRepository {
 
 func (r *categoryRepository) Create(ctx context.Context, category *models.Category) error {
 	query := `
-		INSERT INTO categories (id, name, description, slug, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO categories (id, tenant_id, name, description, slug, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 	_, err := r.db.ExecContext(ctx, query,
-		category.ID, category.Name, category.Description, category.Slug,
+		category.ID, utils.TenantID(ctx), category.Name, category.Description, category.Slug,
 		category.IsActive, category.CreatedAt, category.UpdatedAt,
 	)
 	return err
@@ -32,10 +34,10 @@ func (r *categoryRepository) Create(ctx context.Context, category *models.Catego
 func (r *categoryRepository) GetByID(ctx context.Context, id string) (*models.Category, error) {
 	query := `
 		SELECT id, name, description, slug, is_active, created_at, updated_at
-		FROM categories WHERE id = $1
+		FROM categories WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
 	`
 	category := &models.Category{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)).Scan(
 		&category.ID, &category.Name, &category.Description, &category.Slug,
 		&category.IsActive, &category.CreatedAt, &category.UpdatedAt,
 	)
@@ -48,10 +50,10 @@ func (r *categoryRepository) GetByID(ctx context.Context, id string) (*models.Ca
 func (r *categoryRepository) GetBySlug(ctx context.Context, slug string) (*models.Category, error) {
 	query := `
 		SELECT id, name, description, slug, is_active, created_at, updated_at
-		FROM categories WHERE slug = $1
+		FROM categories WHERE slug = $1 AND tenant_id = $2 AND deleted_at IS NULL
 	`
 	category := &models.Category{}
-	err := r.db.QueryRowContext(ctx, query, slug).Scan(
+	err := r.db.QueryRowContext(ctx, query, slug, utils.TenantID(ctx)).Scan(
 		&category.ID, &category.Name, &category.Description, &category.Slug,
 		&category.IsActive, &category.CreatedAt, &category.UpdatedAt,
 	)
@@ -64,26 +66,46 @@ func (r *categoryRepository) GetBySlug(ctx context.Context, slug string) (*model
 func (r *categoryRepository) Update(ctx context.Context, category *models.Category) error {
 	query := `
 		UPDATE categories SET name = $1, description = $2, slug = $3, is_active = $4, updated_at = $5
-		WHERE id = $6
+		WHERE id = $6 AND tenant_id = $7
 	`
 	_, err := r.db.ExecContext(ctx, query,
 		category.Name, category.Description, category.Slug, category.IsActive,
-		category.UpdatedAt, category.ID,
+		category.UpdatedAt, category.ID, utils.TenantID(ctx),
 	)
 	return err
 }
 
 func (r *categoryRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM categories WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query := `UPDATE categories SET deleted_at = $1 WHERE id = $2 AND tenant_id = $3 AND deleted_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), id, utils.TenantID(ctx))
 	return err
 }
 
+func (r *categoryRepository) CountProducts(ctx context.Context, id string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM products WHERE category_id = $1 AND tenant_id = $2 AND deleted_at IS NULL`
+	err := r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)).Scan(&count)
+	return count, err
+}
+
+// categorySortFields are the sort keys accepted by category listings
+var categorySortFields = utils.SortFields{
+	"name":       "name",
+	"slug":       "slug",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
 func (r *categoryRepository) List(ctx context.Context, pagination utils.Pagination) ([]*models.Category, int, error) {
+	orderBy, err := pagination.OrderClause(categorySortFields, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
 	// Get total count
 	var total int
-	countQuery := `SELECT COUNT(*) FROM categories`
-	if err := r.db.QueryRowContext(ctx, countQuery).Scan(&total); err != nil {
+	countQuery := `SELECT COUNT(*) FROM categories WHERE tenant_id = $1 AND deleted_at IS NULL`
+	if err := r.db.QueryRowContext(ctx, countQuery, utils.TenantID(ctx)).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
@@ -91,10 +113,11 @@ func (r *categoryRepository) List(ctx context.Context, pagination utils.Paginati
 	query := `
 		SELECT id, name, description, slug, is_active, created_at, updated_at
 		FROM categories
-		ORDER BY ` + pagination.OrderBy() + `
-		LIMIT $1 OFFSET $2
+		WHERE tenant_id = $1 AND deleted_at IS NULL
+		ORDER BY ` + orderBy + `
+		LIMIT $2 OFFSET $3
 	`
-	rows, err := r.db.QueryContext(ctx, query, pagination.Limit(), pagination.Offset())
+	rows, err := r.db.QueryContext(ctx, query, utils.TenantID(ctx), pagination.Limit(), pagination.Offset())
 	if err != nil {
 		return nil, 0, err
 	}
@@ -114,3 +137,63 @@ func (r *categoryRepository) List(ctx context.Context, pagination utils.Paginati
 
 	return categories, total, rows.Err()
 }
+
+// categoryActivity derives a change history from the categories' own timestamps, deleted
+// categories included. Only the latest edit of each category is visible.
+const categoryActivity = `
+	FROM (
+		SELECT id || ':created' AS id, id AS category_id, name, 'created' AS event, created_at
+		FROM categories WHERE tenant_id = $1
+		UNION ALL
+		SELECT id || ':updated', id, name, 'updated', updated_at
+		FROM categories WHERE tenant_id = $1 AND updated_at > created_at
+		UNION ALL
+		SELECT id || ':deleted', id, name, 'deleted', deleted_at
+		FROM categories WHERE tenant_id = $1 AND deleted_at IS NOT NULL
+	) a`
+
+// ListActivity lists category creations, edits and deletions, newest first
+func (r *categoryRepository) ListActivity(ctx context.Context, pagination utils.Pagination) ([]*models.CategoryActivity, int, error) {
+	args := []interface{}{utils.TenantID(ctx)}
+	total, err := countRows(ctx, r.db, categoryActivity, args, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	whereClause := ""
+	keyset, keysetArgs, err := keysetCondition(pagination, "a.created_at", "a.id", 2)
+	if err != nil {
+		return nil, 0, err
+	}
+	if keyset != "" {
+		whereClause = "WHERE " + keyset
+		args = append(args, keysetArgs...)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT a.id, a.category_id, a.name, a.event, a.created_at
+		%s
+		%s
+		ORDER BY a.created_at DESC, a.id DESC
+		LIMIT $%d OFFSET $%d
+	`, categoryActivity, whereClause, len(args)+1, len(args)+2)
+	args = append(args, pagination.Limit(), pagination.Offset())
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var activities []*models.CategoryActivity
+	for rows.Next() {
+		activity := &models.CategoryActivity{}
+		if err := rows.Scan(
+			&activity.ID, &activity.CategoryID, &activity.CategoryName, &activity.Event, &activity.CreatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		activities = append(activities, activity)
+	}
+
+	return activities, total, rows.Err()
+}
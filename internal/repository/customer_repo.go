@@ -3,13 +3,40 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/ilramdhan/pos-api/internal/dto"
 	"github.com/ilramdhan/pos-api/internal/models"
 	"github.com/ilramdhan/pos-api/internal/utils"
 )
 
+// ErrCustomerNotFound is returned when a customer row to lock or merge does not exist
+var ErrCustomerNotFound = errors.New("customer not found")
+
+// ErrDuplicateCustomer is returned when a write collides with another customer's normalized phone or email
+var ErrDuplicateCustomer = errors.New("a customer with the same phone or email already exists")
+
+// uniqueViolation is the SQLSTATE Postgres reports when a unique index rejects a row
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether err is a Postgres unique index violation from either driver
+func isUniqueViolation(err error) bool {
+	var pgErr interface{ SQLState() string }
+	return errors.As(err, &pgErr) && pgErr.SQLState() == uniqueViolation
+}
+
+// customerWriteError maps a unique index violation on the normalized contact details to ErrDuplicateCustomer
+func customerWriteError(err error) error {
+	if isUniqueViolation(err) {
+		return ErrDuplicateCustomer
+	}
+	return err
+}
+
 type customerRepository struct {
 	db *sql.DB
 }
@@ -21,23 +48,25 @@ func NewCustomerRepository(db *sql.DB) CustomerRepository {
 
 func (r *customerRepository) Create(ctx context.Context, customer *models.Customer) error {
 	query := `
-		INSERT INTO customers (id, name, email, phone, address, loyalty_points, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO customers (id, tenant_id, name, email, phone, address, loyalty_points, phone_normalized, email_normalized,
+		                       created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 	_, err := r.db.ExecContext(ctx, query,
-		customer.ID, customer.Name, customer.Email, customer.Phone, customer.Address,
-		customer.LoyaltyPoints, customer.CreatedAt, customer.UpdatedAt,
+		customer.ID, utils.TenantID(ctx), customer.Name, customer.Email, customer.Phone, customer.Address,
+		customer.LoyaltyPoints, customer.PhoneNormalized, customer.EmailNormalized,
+		customer.CreatedAt, customer.UpdatedAt,
 	)
-	return err
+	return customerWriteError(err)
 }
 
 func (r *customerRepository) GetByID(ctx context.Context, id string) (*models.Customer, error) {
 	query := `
 		SELECT id, name, email, phone, address, loyalty_points, created_at, updated_at
-		FROM customers WHERE id = $1
+		FROM customers WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
 	`
 	customer := &models.Customer{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)).Scan(
 		&customer.ID, &customer.Name, &customer.Email, &customer.Phone, &customer.Address,
 		&customer.LoyaltyPoints, &customer.CreatedAt, &customer.UpdatedAt,
 	)
@@ -49,55 +78,267 @@ func (r *customerRepository) GetByID(ctx context.Context, id string) (*models.Cu
 
 func (r *customerRepository) Update(ctx context.Context, customer *models.Customer) error {
 	query := `
-		UPDATE customers SET name = $1, email = $2, phone = $3, address = $4, updated_at = $5
-		WHERE id = $6
+		UPDATE customers SET name = $1, email = $2, phone = $3, address = $4,
+		       phone_normalized = $5, email_normalized = $6, updated_at = $7
+		WHERE id = $8 AND tenant_id = $9
 	`
 	_, err := r.db.ExecContext(ctx, query,
 		customer.Name, customer.Email, customer.Phone, customer.Address,
-		customer.UpdatedAt, customer.ID,
+		customer.PhoneNormalized, customer.EmailNormalized, customer.UpdatedAt, customer.ID, utils.TenantID(ctx),
 	)
-	return err
+	return customerWriteError(err)
 }
 
 func (r *customerRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM customers WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query := `UPDATE customers SET deleted_at = $1 WHERE id = $2 AND tenant_id = $3 AND deleted_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), id, utils.TenantID(ctx))
 	return err
 }
 
+func (r *customerRepository) FindDuplicates(ctx context.Context, phoneNormalized, emailNormalized, excludeID string) ([]*models.Customer, error) {
+	if phoneNormalized == "" && emailNormalized == "" {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id, name, email, phone, address, loyalty_points, created_at, updated_at
+		FROM customers
+		WHERE id <> $1 AND tenant_id = $4 AND deleted_at IS NULL
+		  AND (($2 <> '' AND phone_normalized = $2) OR ($3 <> '' AND email_normalized = $3))
+		ORDER BY created_at
+	`
+	rows, err := r.db.QueryContext(ctx, query, excludeID, phoneNormalized, emailNormalized, utils.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var customers []*models.Customer
+	for rows.Next() {
+		customer := &models.Customer{}
+		if err := rows.Scan(
+			&customer.ID, &customer.Name, &customer.Email, &customer.Phone, &customer.Address,
+			&customer.LoyaltyPoints, &customer.CreatedAt, &customer.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		customers = append(customers, customer)
+	}
+
+	return customers, rows.Err()
+}
+
+func (r *customerRepository) Merge(ctx context.Context, targetID string, merges []*models.CustomerMerge) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenantID := utils.TenantID(ctx)
+	target := &models.Customer{}
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, name, email, phone, address, loyalty_points FROM customers
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL FOR UPDATE
+	`, targetID, tenantID).Scan(&target.ID, &target.Name, &target.Email, &target.Phone, &target.Address, &target.LoyaltyPoints)
+	if err == sql.ErrNoRows {
+		return ErrCustomerNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, merge := range merges {
+		source := &models.Customer{}
+		err := tx.QueryRowContext(ctx, `
+			SELECT id, name, email, phone, address, loyalty_points FROM customers
+			WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL FOR UPDATE
+		`, merge.MergedCustomerID, tenantID).Scan(&source.ID, &source.Name, &source.Email, &source.Phone, &source.Address, &source.LoyaltyPoints)
+		if err == sql.ErrNoRows {
+			return ErrCustomerNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// Re-point purchase history, voucher usage and loyalty history to the surviving customer
+		result, err := tx.ExecContext(ctx, `UPDATE transactions SET customer_id = $1 WHERE customer_id = $2`, targetID, source.ID)
+		if err != nil {
+			return err
+		}
+		moved, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE voucher_redemptions SET customer_id = $1 WHERE customer_id = $2`, targetID, source.ID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE loyalty_ledger SET customer_id = $1 WHERE customer_id = $2`, targetID, source.ID); err != nil {
+			return err
+		}
+
+		// Moved ledger credits keep their expiry, so the merge entry itself carries nothing to expire
+		if source.LoyaltyPoints > 0 {
+			target.LoyaltyPoints += source.LoyaltyPoints
+			if err := insertLoyaltyEntry(ctx, tx, &models.LoyaltyLedgerEntry{
+				ID:           uuid.New().String(),
+				CustomerID:   targetID,
+				Type:         models.LoyaltyAdjust,
+				Points:       source.LoyaltyPoints,
+				BalanceAfter: target.LoyaltyPoints,
+				Note:         fmt.Sprintf("Merged from customer %s (%s)", source.Name, source.ID),
+				CreatedBy:    merge.MergedBy,
+				CreatedAt:    merge.CreatedAt,
+			}); err != nil {
+				return err
+			}
+		}
+
+		// Keep contact details the surviving record is missing
+		if target.Email == "" {
+			target.Email = source.Email
+		}
+		if target.Phone == "" {
+			target.Phone = source.Phone
+		}
+		if target.Address == "" {
+			target.Address = source.Address
+		}
+
+		merge.TargetCustomerID = targetID
+		merge.MergedName = source.Name
+		merge.MergedEmail = source.Email
+		merge.MergedPhone = source.Phone
+		merge.PointsMerged = source.LoyaltyPoints
+		merge.TransactionsMoved = int(moved)
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO customer_merges (id, tenant_id, target_customer_id, merged_customer_id, merged_name, merged_email,
+			                             merged_phone, points_merged, transactions_moved, merged_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`,
+			merge.ID, tenantID, merge.TargetCustomerID, merge.MergedCustomerID, merge.MergedName, merge.MergedEmail,
+			merge.MergedPhone, merge.PointsMerged, merge.TransactionsMoved, merge.MergedBy, merge.CreatedAt,
+		); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM customers WHERE id = $1`, source.ID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE customers SET email = $1, phone = $2, address = $3, phone_normalized = $4, email_normalized = $5
+		WHERE id = $6
+	`,
+		target.Email, target.Phone, target.Address,
+		utils.NormalizePhone(target.Phone), utils.NormalizeEmail(target.Email), targetID,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *customerRepository) ListMerges(ctx context.Context, customerID string) ([]*models.CustomerMerge, error) {
+	query := `
+		SELECT id, target_customer_id, merged_customer_id, merged_name, COALESCE(merged_email, ''),
+		       COALESCE(merged_phone, ''), points_merged, transactions_moved, COALESCE(merged_by, ''), created_at
+		FROM customer_merges
+		WHERE target_customer_id = $1 AND tenant_id = $2
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, customerID, utils.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var merges []*models.CustomerMerge
+	for rows.Next() {
+		merge := &models.CustomerMerge{}
+		if err := rows.Scan(
+			&merge.ID, &merge.TargetCustomerID, &merge.MergedCustomerID, &merge.MergedName, &merge.MergedEmail,
+			&merge.MergedPhone, &merge.PointsMerged, &merge.TransactionsMoved, &merge.MergedBy, &merge.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		merges = append(merges, merge)
+	}
+
+	return merges, rows.Err()
+}
+
 func (r *customerRepository) UpdateLoyaltyPoints(ctx context.Context, id string, points int) error {
-	query := `UPDATE customers SET loyalty_points = $1 WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, points, id)
+	query := `UPDATE customers SET loyalty_points = $1 WHERE id = $2 AND tenant_id = $3`
+	_, err := r.db.ExecContext(ctx, query, points, id, utils.TenantID(ctx))
 	return err
 }
 
 func (r *customerRepository) List(ctx context.Context, filter dto.CustomerListFilter, pagination utils.Pagination) ([]*models.Customer, int, error) {
-	var args []interface{}
-	whereClause := ""
-	argIndex := 1
+	orderBy, err := pagination.OrderClause(customerSortFields, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	conditions := []string{"tenant_id = $1"}
+	args := []interface{}{utils.TenantID(ctx)}
+	argIndex := 2
 
 	if filter.Search != "" {
-		whereClause = fmt.Sprintf("WHERE (name ILIKE $%d OR email ILIKE $%d OR phone ILIKE $%d)", argIndex, argIndex+1, argIndex+2)
+		conditions = append(conditions, fmt.Sprintf("(name ILIKE $%d OR email ILIKE $%d OR phone ILIKE $%d)", argIndex, argIndex+1, argIndex+2))
 		searchTerm := "%" + filter.Search + "%"
 		args = append(args, searchTerm, searchTerm, searchTerm)
 		argIndex += 3
 	}
+	if filter.Segment != "" {
+		conditions = append(conditions, fmt.Sprintf("segment = $%d", argIndex))
+		args = append(args, filter.Segment)
+		argIndex++
+	}
+	if filter.MinSpend != nil {
+		conditions = append(conditions, fmt.Sprintf("lifetime_spend >= $%d", argIndex))
+		args = append(args, *filter.MinSpend)
+		argIndex++
+	}
+	if filter.MaxSpend != nil {
+		conditions = append(conditions, fmt.Sprintf("lifetime_spend <= $%d", argIndex))
+		args = append(args, *filter.MaxSpend)
+		argIndex++
+	}
+	if filter.MinVisits != nil {
+		conditions = append(conditions, fmt.Sprintf("visit_count >= $%d", argIndex))
+		args = append(args, *filter.MinVisits)
+		argIndex++
+	}
+	if filter.LastVisitFrom != "" {
+		conditions = append(conditions, fmt.Sprintf("DATE(last_visit_at) >= $%d", argIndex))
+		args = append(args, filter.LastVisitFrom)
+		argIndex++
+	}
+	if filter.LastVisitTo != "" {
+		conditions = append(conditions, fmt.Sprintf("DATE(last_visit_at) <= $%d", argIndex))
+		args = append(args, filter.LastVisitTo)
+		argIndex++
+	}
+
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
 
 	// Get total count
 	var total int
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM customers %s`, whereClause)
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM (%s) cs %s`, customerWithStatsQuery, whereClause)
 	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
 	// Get paginated results
 	query := fmt.Sprintf(`
-		SELECT id, name, email, phone, address, loyalty_points, created_at, updated_at
-		FROM customers
+		SELECT %s
+		FROM (%s) cs
 		%s
 		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, pagination.OrderBy(), argIndex, argIndex+1)
+	`, customerWithStatsColumns, customerWithStatsQuery, whereClause, orderBy, argIndex, argIndex+1)
 
 	args = append(args, pagination.Limit(), pagination.Offset())
 	rows, err := r.db.QueryContext(ctx, query, args...)
@@ -108,11 +349,8 @@ func (r *customerRepository) List(ctx context.Context, filter dto.CustomerListFi
 
 	var customers []*models.Customer
 	for rows.Next() {
-		customer := &models.Customer{}
-		if err := rows.Scan(
-			&customer.ID, &customer.Name, &customer.Email, &customer.Phone, &customer.Address,
-			&customer.LoyaltyPoints, &customer.CreatedAt, &customer.UpdatedAt,
-		); err != nil {
+		customer, err := scanCustomerWithStats(rows)
+		if err != nil {
 			return nil, 0, err
 		}
 		customers = append(customers, customer)
@@ -120,3 +358,110 @@ func (r *customerRepository) List(ctx context.Context, filter dto.CustomerListFi
 
 	return customers, total, rows.Err()
 }
+
+func (r *customerRepository) GetStats(ctx context.Context, id string) (*models.CustomerStats, error) {
+	query := fmt.Sprintf(`SELECT %s FROM (%s) cs WHERE id = $1 AND tenant_id = $2`, customerWithStatsColumns, customerWithStatsQuery)
+	customer, err := scanCustomerWithStats(r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return customer.Stats, nil
+}
+
+func (r *customerRepository) GetFavoriteProducts(ctx context.Context, id string, limit int) ([]*models.CustomerFavoriteProduct, error) {
+	query := `
+		SELECT ti.product_id, ti.product_name, SUM(ti.quantity) AS quantity, SUM(ti.subtotal) AS total_amount
+		FROM transaction_items ti
+		JOIN transactions t ON ti.transaction_id = t.id
+		WHERE t.customer_id = $1 AND t.tenant_id = $2 AND t.status = 'completed'
+		GROUP BY ti.product_id, ti.product_name
+		ORDER BY quantity DESC, total_amount DESC
+		LIMIT $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, id, utils.TenantID(ctx), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []*models.CustomerFavoriteProduct
+	for rows.Next() {
+		product := &models.CustomerFavoriteProduct{}
+		if err := rows.Scan(&product.ProductID, &product.ProductName, &product.Quantity, &product.TotalAmount); err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	return products, rows.Err()
+}
+
+// customerSortFields maps accepted sort keys to columns of customerWithStatsQuery; metrics
+// of customers without purchases sort last
+var customerSortFields = utils.SortFields{
+	"name":           "name",
+	"email":          "email",
+	"loyalty_points": "loyalty_points",
+	"created_at":     "created_at",
+	"updated_at":     "updated_at",
+	"lifetime_spend": "lifetime_spend",
+	"visit_count":    "visit_count",
+	"average_basket": "average_basket",
+	"last_visit_at":  "last_visit_at",
+}
+
+// customerWithStatsQuery joins each customer with metrics from their completed transactions
+// and classifies them into an RFM-style segment
+var customerWithStatsQuery = fmt.Sprintf(`
+		SELECT c.id, c.tenant_id, c.name, c.email, c.phone, c.address, c.loyalty_points, c.created_at, c.updated_at,
+		       COALESCE(s.lifetime_spend, 0) AS lifetime_spend,
+		       COALESCE(s.visit_count, 0) AS visit_count,
+		       CASE WHEN COALESCE(s.visit_count, 0) > 0 THEN s.lifetime_spend / s.visit_count ELSE 0 END AS average_basket,
+		       s.first_visit_at, s.last_visit_at,
+		       CASE
+		           WHEN COALESCE(s.visit_count, 0) = 0 AND c.created_at >= NOW() - INTERVAL '%[1]d days' THEN '%[5]s'
+		           WHEN COALESCE(s.visit_count, 0) = 0 THEN '%[7]s'
+		           WHEN s.last_visit_at < NOW() - INTERVAL '%[2]d days' THEN '%[7]s'
+		           WHEN s.visit_count >= %[3]d AND s.lifetime_spend >= %[4]d THEN '%[8]s'
+		           WHEN s.first_visit_at >= NOW() - INTERVAL '%[1]d days' THEN '%[5]s'
+		           ELSE '%[6]s'
+		       END AS segment
+		FROM customers c
+		LEFT JOIN (
+		    SELECT customer_id, SUM(total_amount) AS lifetime_spend, COUNT(*) AS visit_count,
+		           MIN(created_at) AS first_visit_at, MAX(created_at) AS last_visit_at
+		    FROM transactions
+		    WHERE status = 'completed' AND customer_id IS NOT NULL
+		    GROUP BY customer_id
+		) s ON s.customer_id = c.id
+		WHERE c.deleted_at IS NULL`,
+	models.SegmentNewDays, models.SegmentLapsedDays, models.SegmentVIPVisits, models.SegmentVIPSpend,
+	models.SegmentNew, models.SegmentRegular, models.SegmentLapsed, models.SegmentVIP,
+)
+
+const customerWithStatsColumns = `id, name, email, phone, address, loyalty_points, created_at, updated_at,
+		       lifetime_spend, visit_count, average_basket, first_visit_at, last_visit_at, segment`
+
+func scanCustomerWithStats(row interface{ Scan(...interface{}) error }) (*models.Customer, error) {
+	customer := &models.Customer{Stats: &models.CustomerStats{}}
+	var firstVisit, lastVisit sql.NullTime
+	err := row.Scan(
+		&customer.ID, &customer.Name, &customer.Email, &customer.Phone, &customer.Address,
+		&customer.LoyaltyPoints, &customer.CreatedAt, &customer.UpdatedAt,
+		&customer.Stats.LifetimeSpend, &customer.Stats.VisitCount, &customer.Stats.AverageBasket,
+		&firstVisit, &lastVisit, &customer.Stats.Segment,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if firstVisit.Valid {
+		customer.Stats.FirstVisitAt = &firstVisit.Time
+	}
+	if lastVisit.Valid {
+		customer.Stats.LastVisitAt = &lastVisit.Time
+	}
+	return customer, nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+type heldTransactionRepository struct {
+	db *sql.DB
+}
+
+// NewHeldTransactionRepository creates a new held transaction repository
+func NewHeldTransactionRepository(db *sql.DB) HeldTransactionRepository {
+	return &heldTransactionRepository{db: db}
+}
+
+func (r *heldTransactionRepository) Create(ctx context.Context, held *models.HeldTransaction) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenantID := utils.TenantID(ctx)
+	query := `
+		INSERT INTO held_transactions (id, tenant_id, store_id, terminal_id, hold_number, customer_id,
+			customer_name, total_amount, notes, held_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err = tx.ExecContext(ctx, query,
+		held.ID, tenantID, held.StoreID, held.TerminalID, held.HoldNumber, held.CustomerID,
+		held.CustomerName, held.TotalAmount, held.Notes, held.HeldBy, held.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	itemQuery := `
+		INSERT INTO held_transaction_items (id, tenant_id, held_transaction_id, product_id, variant_id, quantity, position)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	for i, item := range held.Items {
+		_, err = tx.ExecContext(ctx, itemQuery,
+			uuid.New().String(), tenantID, held.ID, item.ProductID, item.VariantID, item.Quantity, i,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// List lists the carts held at a terminal, or at a store outside any terminal, oldest first
+func (r *heldTransactionRepository) List(ctx context.Context, storeID, terminalID string) ([]*models.HeldTransaction, error) {
+	tenantID := utils.TenantID(ctx)
+	query := `
+		SELECT id, COALESCE(store_id, ''), COALESCE(terminal_id, ''), hold_number, customer_id,
+			COALESCE(customer_name, ''), total_amount, COALESCE(notes, ''), held_by, created_at
+		FROM held_transactions
+		WHERE tenant_id = $1 AND COALESCE(store_id, '') = $2 AND COALESCE(terminal_id, '') = $3
+		ORDER BY created_at
+	`
+	rows, err := r.db.QueryContext(ctx, query, tenantID, storeID, terminalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	held := []*models.HeldTransaction{}
+	byID := make(map[string]*models.HeldTransaction)
+	for rows.Next() {
+		h := &models.HeldTransaction{Items: []models.HeldTransactionItem{}}
+		var customerID sql.NullString
+		if err := rows.Scan(
+			&h.ID, &h.StoreID, &h.TerminalID, &h.HoldNumber, &customerID,
+			&h.CustomerName, &h.TotalAmount, &h.Notes, &h.HeldBy, &h.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if customerID.Valid {
+			h.CustomerID = &customerID.String
+		}
+		held = append(held, h)
+		byID[h.ID] = h
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(held) == 0 {
+		return held, nil
+	}
+
+	itemQuery := `
+		SELECT i.held_transaction_id, i.product_id, COALESCE(i.variant_id, ''), i.quantity
+		FROM held_transaction_items i
+		JOIN held_transactions h ON h.id = i.held_transaction_id
+		WHERE h.tenant_id = $1 AND COALESCE(h.store_id, '') = $2 AND COALESCE(h.terminal_id, '') = $3
+		ORDER BY i.position
+	`
+	itemRows, err := r.db.QueryContext(ctx, itemQuery, tenantID, storeID, terminalID)
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var heldID string
+		var item models.HeldTransactionItem
+		if err := itemRows.Scan(&heldID, &item.ProductID, &item.VariantID, &item.Quantity); err != nil {
+			return nil, err
+		}
+		if h := byID[heldID]; h != nil {
+			h.Items = append(h.Items, item)
+			h.ItemsCount++
+		}
+	}
+	return held, itemRows.Err()
+}
+
+// Delete removes a cart held at the given terminal or store and reports whether it existed
+func (r *heldTransactionRepository) Delete(ctx context.Context, id, storeID, terminalID string) (bool, error) {
+	query := `
+		DELETE FROM held_transactions
+		WHERE id = $1 AND tenant_id = $2 AND COALESCE(store_id, '') = $3 AND COALESCE(terminal_id, '') = $4
+	`
+	result, err := r.db.ExecContext(ctx, query, id, utils.TenantID(ctx), storeID, terminalID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
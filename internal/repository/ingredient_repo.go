@@ -0,0 +1,359 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// ErrInsufficientIngredient is returned when a movement would take an ingredient's stock below zero
+var ErrInsufficientIngredient = errors.New("insufficient ingredient stock")
+
+type ingredientRepository struct {
+	db *sql.DB
+}
+
+// NewIngredientRepository creates a new ingredient, recipe and stock movement repository
+func NewIngredientRepository(db *sql.DB) IngredientRepository {
+	return &ingredientRepository{db: db}
+}
+
+const ingredientColumns = `id, sku, name, unit, stock, low_stock_threshold, is_active, created_at, updated_at`
+
+func scanIngredient(row interface{ Scan(...interface{}) error }) (*models.Ingredient, error) {
+	ingredient := &models.Ingredient{}
+	err := row.Scan(
+		&ingredient.ID, &ingredient.SKU, &ingredient.Name, &ingredient.Unit, &ingredient.Stock,
+		&ingredient.LowStockThreshold, &ingredient.IsActive, &ingredient.CreatedAt, &ingredient.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return ingredient, nil
+}
+
+const stockMovementColumns = `m.id, m.ingredient_id, m.type, m.quantity, m.balance_after, m.reference_id,
+		       COALESCE(m.note, ''), COALESCE(m.created_by, ''), m.created_at, i.name, i.unit`
+
+func scanStockMovement(row interface{ Scan(...interface{}) error }) (*models.StockMovement, error) {
+	movement := &models.StockMovement{}
+	var referenceID sql.NullString
+	err := row.Scan(
+		&movement.ID, &movement.IngredientID, &movement.Type, &movement.Quantity, &movement.BalanceAfter,
+		&referenceID, &movement.Note, &movement.CreatedBy, &movement.CreatedAt, &movement.IngredientName, &movement.Unit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if referenceID.Valid {
+		movement.ReferenceID = &referenceID.String
+	}
+	return movement, nil
+}
+
+func (r *ingredientRepository) Create(ctx context.Context, ingredient *models.Ingredient) error {
+	query := `
+		INSERT INTO ingredients (id, tenant_id, sku, name, unit, stock, low_stock_threshold, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		ingredient.ID, utils.TenantID(ctx), ingredient.SKU, ingredient.Name, ingredient.Unit, ingredient.Stock,
+		ingredient.LowStockThreshold, ingredient.IsActive, ingredient.CreatedAt, ingredient.UpdatedAt,
+	)
+	return err
+}
+
+func (r *ingredientRepository) GetByID(ctx context.Context, id string) (*models.Ingredient, error) {
+	query := `SELECT ` + ingredientColumns + ` FROM ingredients WHERE id = $1 AND tenant_id = $2`
+	ingredient, err := scanIngredient(r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return ingredient, err
+}
+
+func (r *ingredientRepository) GetBySKU(ctx context.Context, sku string) (*models.Ingredient, error) {
+	query := `SELECT ` + ingredientColumns + ` FROM ingredients WHERE sku = $1 AND tenant_id = $2`
+	ingredient, err := scanIngredient(r.db.QueryRowContext(ctx, query, sku, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return ingredient, err
+}
+
+// Update updates an ingredient's details. Stock only changes through stock movements.
+func (r *ingredientRepository) Update(ctx context.Context, ingredient *models.Ingredient) error {
+	query := `
+		UPDATE ingredients
+		SET sku = $1, name = $2, low_stock_threshold = $3, is_active = $4, updated_at = $5
+		WHERE id = $6 AND tenant_id = $7
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		ingredient.SKU, ingredient.Name, ingredient.LowStockThreshold, ingredient.IsActive,
+		ingredient.UpdatedAt, ingredient.ID, utils.TenantID(ctx),
+	)
+	return err
+}
+
+func (r *ingredientRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM ingredients WHERE id = $1 AND tenant_id = $2`, id, utils.TenantID(ctx))
+	return err
+}
+
+func (r *ingredientRepository) CountRecipeUsage(ctx context.Context, id string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM recipe_items WHERE ingredient_id = $1 AND tenant_id = $2`, id, utils.TenantID(ctx),
+	).Scan(&count)
+	return count, err
+}
+
+func (r *ingredientRepository) List(ctx context.Context, filter dto.IngredientListFilter, pagination utils.Pagination) ([]*models.Ingredient, int, error) {
+	conditions := []string{"tenant_id = $1"}
+	args := []interface{}{utils.TenantID(ctx)}
+	argIndex := 2
+
+	if filter.Search != "" {
+		conditions = append(conditions, fmt.Sprintf("(name ILIKE $%d OR sku ILIKE $%d)", argIndex, argIndex))
+		args = append(args, "%"+filter.Search+"%")
+		argIndex++
+	}
+	if filter.LowStock != nil {
+		if *filter.LowStock {
+			conditions = append(conditions, "stock <= low_stock_threshold")
+		} else {
+			conditions = append(conditions, "stock > low_stock_threshold")
+		}
+	}
+	if filter.IsActive != nil {
+		conditions = append(conditions, fmt.Sprintf("is_active = $%d", argIndex))
+		args = append(args, *filter.IsActive)
+		argIndex++
+	}
+
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
+	// Get total count
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM ingredients %s`, whereClause)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM ingredients
+		%s
+		ORDER BY name
+		LIMIT $%d OFFSET $%d
+	`, ingredientColumns, whereClause, argIndex, argIndex+1)
+
+	args = append(args, pagination.Limit(), pagination.Offset())
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var ingredients []*models.Ingredient
+	for rows.Next() {
+		ingredient, err := scanIngredient(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		ingredients = append(ingredients, ingredient)
+	}
+
+	return ingredients, total, rows.Err()
+}
+
+func (r *ingredientRepository) PostMovements(ctx context.Context, movements []*models.StockMovement) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, movement := range movements {
+		if err := postStockMovement(ctx, tx, movement); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *ingredientRepository) ListMovements(ctx context.Context, ingredientID string, pagination utils.Pagination) ([]*models.StockMovement, int, error) {
+	whereClause := "WHERE m.ingredient_id = $1 AND m.tenant_id = $2"
+	args := []interface{}{ingredientID, utils.TenantID(ctx)}
+	total, err := countRows(ctx, r.db, "FROM stock_movements m "+whereClause, args, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	keyset, keysetArgs, err := keysetCondition(pagination, "m.created_at", "m.id", 3)
+	if err != nil {
+		return nil, 0, err
+	}
+	if keyset != "" {
+		whereClause += " AND " + keyset
+		args = append(args, keysetArgs...)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT `+stockMovementColumns+`
+		FROM stock_movements m
+		JOIN ingredients i ON m.ingredient_id = i.id
+		%s
+		ORDER BY m.created_at DESC, m.id DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, len(args)+1, len(args)+2)
+	args = append(args, pagination.Limit(), pagination.Offset())
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var movements []*models.StockMovement
+	for rows.Next() {
+		movement, err := scanStockMovement(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, total, rows.Err()
+}
+
+func (r *ingredientRepository) ListMovementsByReference(ctx context.Context, referenceID string) ([]*models.StockMovement, error) {
+	query := `
+		SELECT ` + stockMovementColumns + `
+		FROM stock_movements m
+		JOIN ingredients i ON m.ingredient_id = i.id
+		WHERE m.reference_id = $1 AND m.tenant_id = $2
+		ORDER BY m.created_at, m.id
+	`
+	rows, err := r.db.QueryContext(ctx, query, referenceID, utils.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []*models.StockMovement
+	for rows.Next() {
+		movement, err := scanStockMovement(rows)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, rows.Err()
+}
+
+func (r *ingredientRepository) GetRecipes(ctx context.Context, productIDs []string) ([]*models.RecipeItem, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT r.id, r.product_id, r.ingredient_id, r.quantity, r.unit, r.created_at,
+		       i.id, i.sku, i.name, i.unit, i.stock, i.low_stock_threshold, i.is_active, i.created_at, i.updated_at
+		FROM recipe_items r
+		JOIN ingredients i ON r.ingredient_id = i.id
+		WHERE r.tenant_id = $1 AND r.product_id IN (%s)
+		ORDER BY r.product_id, i.name
+	`, inPlaceholders(2, len(productIDs)))
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{utils.TenantID(ctx)}, stringArgs(productIDs)...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.RecipeItem
+	for rows.Next() {
+		item := &models.RecipeItem{Ingredient: &models.Ingredient{}}
+		if err := rows.Scan(
+			&item.ID, &item.ProductID, &item.IngredientID, &item.Quantity, &item.Unit, &item.CreatedAt,
+			&item.Ingredient.ID, &item.Ingredient.SKU, &item.Ingredient.Name, &item.Ingredient.Unit,
+			&item.Ingredient.Stock, &item.Ingredient.LowStockThreshold, &item.Ingredient.IsActive,
+			&item.Ingredient.CreatedAt, &item.Ingredient.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *ingredientRepository) ReplaceRecipe(ctx context.Context, productID string, items []*models.RecipeItem) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenantID := utils.TenantID(ctx)
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recipe_items WHERE product_id = $1 AND tenant_id = $2`, productID, tenantID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO recipe_items (id, tenant_id, product_id, ingredient_id, quantity, unit, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	for _, item := range items {
+		if _, err := tx.ExecContext(ctx, query,
+			item.ID, tenantID, item.ProductID, item.IngredientID, item.Quantity, item.Unit, item.CreatedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// postStockMovement applies a movement to an ingredient's or a product's stock and records it
+// inside the caller's database transaction. Stock never goes below zero.
+func postStockMovement(ctx context.Context, tx *sql.Tx, movement *models.StockMovement) error {
+	if movement.ProductID != "" {
+		return postProductMovement(ctx, tx, movement)
+	}
+
+	tenantID := utils.TenantID(ctx)
+	err := tx.QueryRowContext(ctx, `
+		UPDATE ingredients SET stock = stock + $1, updated_at = $2
+		WHERE id = $3 AND tenant_id = $4 AND stock + $1 >= 0
+		RETURNING stock
+	`, movement.Quantity, movement.CreatedAt, movement.IngredientID, tenantID).Scan(&movement.BalanceAfter)
+	if err == sql.ErrNoRows {
+		return ErrInsufficientIngredient
+	}
+	if err != nil {
+		return err
+	}
+
+	var createdBy *string
+	if movement.CreatedBy != "" {
+		createdBy = &movement.CreatedBy
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO stock_movements (id, tenant_id, ingredient_id, type, quantity, balance_after, reference_id, note, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`,
+		movement.ID, tenantID, movement.IngredientID, movement.Type, movement.Quantity, movement.BalanceAfter,
+		movement.ReferenceID, movement.Note, createdBy, movement.CreatedAt,
+	)
+	return err
+}
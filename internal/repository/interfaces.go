@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/ilramdhan/pos-api/internal/dto"
 	"github.com/ilramdhan/pos-api/internal/models"
@@ -15,7 +16,77 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	Update(ctx context.Context, user *models.User) error
 	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, role string, pagination utils.Pagination) ([]*models.User, int, error)
+	List(ctx context.Context, role, storeID string, pagination utils.Pagination) ([]*models.User, int, error)
+	CountActive(ctx context.Context) (int, error)
+	SetPIN(ctx context.Context, id, pinHash string, now time.Time) error
+	ListPINUsers(ctx context.Context, storeID string) ([]*models.User, error)
+	SetTOTP(ctx context.Context, id, secret string, enabled bool, now time.Time) error
+	UseTOTPStep(ctx context.Context, id string, step int64) (bool, error)
+}
+
+// MFARecoveryCodeRepository defines the interface for two-factor recovery code data access
+type MFARecoveryCodeRepository interface {
+	Replace(ctx context.Context, userID string, codeHashes []string, now time.Time) error
+	Use(ctx context.Context, userID, codeHash string, now time.Time) (bool, error)
+	CountUnused(ctx context.Context, userID string) (int, error)
+}
+
+// TerminalRepository defines the interface for POS terminal data access
+type TerminalRepository interface {
+	Create(ctx context.Context, terminal *models.Terminal, keyHash string) error
+	GetByID(ctx context.Context, id string) (*models.Terminal, error)
+	GetByKeyHash(ctx context.Context, keyHash string) (*models.Terminal, error)
+	Update(ctx context.Context, terminal *models.Terminal) error
+	SetKeyHash(ctx context.Context, id, keyHash string, now time.Time) error
+	SetLastUsed(ctx context.Context, id string, now time.Time) error
+	SetHeartbeat(ctx context.Context, id, appVersion string, latencyMS *int, now time.Time) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, storeID string) ([]*models.Terminal, error)
+}
+
+// LoginAttemptRepository defines the interface for failed sign-in tracking
+type LoginAttemptRepository interface {
+	Get(ctx context.Context, key string) (*models.LoginAttempt, error)
+	RecordFailure(ctx context.Context, key string, now time.Time) (int, error)
+	Lock(ctx context.Context, key string, until, now time.Time) error
+	Delay(ctx context.Context, key string, until, now time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+// LoginEventRepository defines the interface for the sign-in audit log
+type LoginEventRepository interface {
+	Create(ctx context.Context, event *models.LoginEvent) error
+	List(ctx context.Context, filter dto.LoginEventFilter, pagination utils.Pagination) ([]*models.LoginEvent, int, error)
+}
+
+// NotificationRepository defines the interface for users' in-app notifications
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *models.Notification) error
+	List(ctx context.Context, userID string, unreadOnly bool, limit int) ([]*models.Notification, error)
+	CountUnread(ctx context.Context, userID string) (int, error)
+	MarkRead(ctx context.Context, id, userID string) error
+	MarkAllRead(ctx context.Context, userID string) error
+	Delete(ctx context.Context, id, userID string) error
+}
+
+// HeldTransactionRepository defines the interface for held cart data access
+type HeldTransactionRepository interface {
+	Create(ctx context.Context, held *models.HeldTransaction) error
+	List(ctx context.Context, storeID, terminalID string) ([]*models.HeldTransaction, error)
+	Delete(ctx context.Context, id, storeID, terminalID string) (bool, error)
+}
+
+// StoreRepository defines the interface for store, store staff and store price data access
+type StoreRepository interface {
+	Create(ctx context.Context, store *models.Store) error
+	GetByID(ctx context.Context, id string) (*models.Store, error)
+	GetByCode(ctx context.Context, code string) (*models.Store, error)
+	Update(ctx context.Context, store *models.Store) error
+	List(ctx context.Context, filter dto.StoreListFilter, pagination utils.Pagination) ([]*models.Store, int, error)
+	ListUserStores(ctx context.Context, userID string) ([]*models.Store, error)
+	SetUserStores(ctx context.Context, userID string, storeIDs []string, now time.Time) error
+	SetPrices(ctx context.Context, storeID string, prices []models.StorePrice, now time.Time) error
+	CountActive(ctx context.Context) (int, error)
 }
 
 // CategoryRepository defines the interface for category data access
@@ -26,6 +97,8 @@ type CategoryRepository interface {
 	Update(ctx context.Context, category *models.Category) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context, pagination utils.Pagination) ([]*models.Category, int, error)
+	CountProducts(ctx context.Context, id string) (int, error)
+	ListActivity(ctx context.Context, pagination utils.Pagination) ([]*models.CategoryActivity, int, error)
 }
 
 // ProductRepository defines the interface for product data access
@@ -35,8 +108,51 @@ type ProductRepository interface {
 	GetBySKU(ctx context.Context, sku string) (*models.Product, error)
 	Update(ctx context.Context, product *models.Product) error
 	Delete(ctx context.Context, id string) error
-	UpdateStock(ctx context.Context, id string, quantity int) error
+	PostMovement(ctx context.Context, movement *models.StockMovement) error
+	ApplyStoreLevels(ctx context.Context, storeID string, products []*models.Product) error
+	ListMovements(ctx context.Context, filter dto.ProductStockMovementFilter, pagination utils.Pagination) ([]*models.StockMovement, int, error)
+	UpdateImage(ctx context.Context, id, imageURL, thumbnailURL string) error
 	List(ctx context.Context, filter dto.ProductListFilter, pagination utils.Pagination) ([]*models.Product, int, error)
+	Search(ctx context.Context, search string, limit int) ([]*models.Product, error)
+	ImportBatch(ctx context.Context, creates, updates []*models.Product, movements []*models.StockMovement) error
+}
+
+// ProductOptionRepository defines the interface for product variant, modifier and barcode data access
+type ProductOptionRepository interface {
+	CreateVariant(ctx context.Context, variant *models.ProductVariant) error
+	GetVariant(ctx context.Context, id string) (*models.ProductVariant, error)
+	GetVariantBySKU(ctx context.Context, sku string) (*models.ProductVariant, error)
+	UpdateVariant(ctx context.Context, variant *models.ProductVariant) error
+	UpdateVariantStock(ctx context.Context, id string, stock int) error
+	ApplyStoreStock(ctx context.Context, storeID string, variants []*models.ProductVariant) error
+	DeleteVariant(ctx context.Context, id string) error
+	ListVariants(ctx context.Context, productIDs []string) ([]*models.ProductVariant, error)
+	CreateModifierGroup(ctx context.Context, group *models.ModifierGroup) error
+	GetModifierGroup(ctx context.Context, id string) (*models.ModifierGroup, error)
+	UpdateModifierGroup(ctx context.Context, group *models.ModifierGroup) error
+	DeleteModifierGroup(ctx context.Context, id string) error
+	ListModifierGroups(ctx context.Context, productIDs []string) ([]*models.ModifierGroup, error)
+	CreateBarcode(ctx context.Context, barcode *models.ProductBarcode) error
+	GetBarcode(ctx context.Context, id string) (*models.ProductBarcode, error)
+	GetBarcodeByCode(ctx context.Context, code string) (*models.ProductBarcode, error)
+	DeleteBarcode(ctx context.Context, id string) error
+	ListBarcodes(ctx context.Context, productIDs []string) ([]*models.ProductBarcode, error)
+}
+
+// IngredientRepository defines the interface for ingredient, recipe and stock movement data access
+type IngredientRepository interface {
+	Create(ctx context.Context, ingredient *models.Ingredient) error
+	GetByID(ctx context.Context, id string) (*models.Ingredient, error)
+	GetBySKU(ctx context.Context, sku string) (*models.Ingredient, error)
+	Update(ctx context.Context, ingredient *models.Ingredient) error
+	Delete(ctx context.Context, id string) error
+	CountRecipeUsage(ctx context.Context, id string) (int, error)
+	List(ctx context.Context, filter dto.IngredientListFilter, pagination utils.Pagination) ([]*models.Ingredient, int, error)
+	PostMovements(ctx context.Context, movements []*models.StockMovement) error
+	ListMovements(ctx context.Context, ingredientID string, pagination utils.Pagination) ([]*models.StockMovement, int, error)
+	ListMovementsByReference(ctx context.Context, referenceID string) ([]*models.StockMovement, error)
+	GetRecipes(ctx context.Context, productIDs []string) ([]*models.RecipeItem, error)
+	ReplaceRecipe(ctx context.Context, productID string, items []*models.RecipeItem) error
 }
 
 // CustomerRepository defines the interface for customer data access
@@ -47,6 +163,11 @@ type CustomerRepository interface {
 	Delete(ctx context.Context, id string) error
 	UpdateLoyaltyPoints(ctx context.Context, id string, points int) error
 	List(ctx context.Context, filter dto.CustomerListFilter, pagination utils.Pagination) ([]*models.Customer, int, error)
+	GetStats(ctx context.Context, id string) (*models.CustomerStats, error)
+	GetFavoriteProducts(ctx context.Context, id string, limit int) ([]*models.CustomerFavoriteProduct, error)
+	FindDuplicates(ctx context.Context, phoneNormalized, emailNormalized, excludeID string) ([]*models.Customer, error)
+	Merge(ctx context.Context, targetID string, merges []*models.CustomerMerge) error
+	ListMerges(ctx context.Context, customerID string) ([]*models.CustomerMerge, error)
 }
 
 // TransactionRepository defines the interface for transaction data access
@@ -54,11 +175,11 @@ type TransactionRepository interface {
 	Create(ctx context.Context, transaction *models.Transaction) error
 	GetByID(ctx context.Context, id string) (*models.Transaction, error)
 	GetByInvoiceNumber(ctx context.Context, invoiceNumber string) (*models.Transaction, error)
-	UpdateStatus(ctx context.Context, id string, status string) error
+	UpdateStatus(ctx context.Context, transaction *models.Transaction, status string, now time.Time) error
 	List(ctx context.Context, filter dto.TransactionListFilter, pagination utils.Pagination) ([]*models.Transaction, int, error)
-	GetDailySales(ctx context.Context, dateFrom, dateTo string) ([]dto.DailySalesReport, error)
-	GetMonthlySales(ctx context.Context, dateFrom, dateTo string) ([]dto.MonthlySalesReport, error)
-	GetTopProducts(ctx context.Context, limit int, dateFrom, dateTo string) ([]dto.TopProductReport, error)
+	GetDailySales(ctx context.Context, storeID, dateFrom, dateTo string) ([]dto.DailySalesReport, error)
+	GetMonthlySales(ctx context.Context, storeID, dateFrom, dateTo string) ([]dto.MonthlySalesReport, error)
+	GetTopProducts(ctx context.Context, storeID string, limit int, dateFrom, dateTo string) ([]dto.TopProductReport, error)
 }
 
 // TransactionItemRepository defines the interface for transaction item data access
@@ -66,3 +187,99 @@ type TransactionItemRepository interface {
 	Create(ctx context.Context, item *models.TransactionItem) error
 	GetByTransactionID(ctx context.Context, transactionID string) ([]*models.TransactionItem, error)
 }
+
+// VoucherRepository defines the interface for voucher data access
+type VoucherRepository interface {
+	CreateBatch(ctx context.Context, vouchers []*models.Voucher) error
+	GetByID(ctx context.Context, id string) (*models.Voucher, error)
+	GetByCode(ctx context.Context, code string) (*models.Voucher, error)
+	Update(ctx context.Context, voucher *models.Voucher) error
+	CountCustomerRedemptions(ctx context.Context, voucherID, customerID string) (int, error)
+	List(ctx context.Context, filter dto.VoucherListFilter, pagination utils.Pagination) ([]*models.Voucher, int, error)
+	ListRedemptions(ctx context.Context, voucherID string) ([]*models.VoucherRedemption, error)
+}
+
+// LoyaltyRepository defines loyalty ledger data access methods
+type LoyaltyRepository interface {
+	Post(ctx context.Context, entry *models.LoyaltyLedgerEntry) error
+	ListByCustomer(ctx context.Context, customerID string, pagination utils.Pagination) ([]*models.LoyaltyLedgerEntry, int, error)
+	ListByTransaction(ctx context.Context, transactionID string) ([]*models.LoyaltyLedgerEntry, error)
+	GetExpiringPoints(ctx context.Context, customerID string, before time.Time) (int, error)
+	GetLifetimeSpend(ctx context.Context, customerID string) (float64, error)
+	ExpirePoints(ctx context.Context, now time.Time) (int, error)
+}
+
+// SupplierRepository defines the interface for supplier data access
+type SupplierRepository interface {
+	Create(ctx context.Context, supplier *models.Supplier) error
+	GetByID(ctx context.Context, id string) (*models.Supplier, error)
+	Update(ctx context.Context, supplier *models.Supplier) error
+	Delete(ctx context.Context, id string) error
+	CountPurchaseOrders(ctx context.Context, id string) (int, error)
+	List(ctx context.Context, filter dto.SupplierListFilter, pagination utils.Pagination) ([]*models.Supplier, int, error)
+}
+
+// PurchaseOrderRepository defines the interface for purchase order and goods receiving data access
+type PurchaseOrderRepository interface {
+	Create(ctx context.Context, order *models.PurchaseOrder) error
+	GetByID(ctx context.Context, id string) (*models.PurchaseOrder, error)
+	Update(ctx context.Context, order *models.PurchaseOrder) error
+	UpdateStatus(ctx context.Context, id, from, to string, now time.Time) error
+	Receive(ctx context.Context, orderID string, receipts []models.GoodsReceipt, userID string, now time.Time) error
+	List(ctx context.Context, filter dto.PurchaseOrderListFilter, pagination utils.Pagination) ([]*models.PurchaseOrder, int, error)
+	GetOutstandingBySupplier(ctx context.Context, storeID string, now time.Time) ([]dto.OutstandingPurchaseReport, error)
+}
+
+// StockTakeRepository defines the interface for stock take (physical inventory count) data access
+type StockTakeRepository interface {
+	Create(ctx context.Context, take *models.StockTake) error
+	GetByID(ctx context.Context, id string) (*models.StockTake, error)
+	HasOpen(ctx context.Context, storeID, categoryID *string) (bool, error)
+	SubmitCounts(ctx context.Context, id string, counts []models.StockCount, add bool, userID string, now time.Time) error
+	Approve(ctx context.Context, id, userID string, now time.Time) error
+	Cancel(ctx context.Context, id string, now time.Time) error
+	List(ctx context.Context, filter dto.StockTakeListFilter, pagination utils.Pagination) ([]*models.StockTake, int, error)
+}
+
+// StockTransferRepository defines the interface for stock transfers between stores
+type StockTransferRepository interface {
+	Create(ctx context.Context, transfer *models.StockTransfer) error
+	GetByID(ctx context.Context, id string) (*models.StockTransfer, error)
+	Ship(ctx context.Context, id string, quantities []models.TransferQuantity, userID string, now time.Time) error
+	Receive(ctx context.Context, id string, quantities []models.TransferQuantity, userID string, now time.Time) error
+	Cancel(ctx context.Context, id string, now time.Time) error
+	List(ctx context.Context, filter dto.StockTransferListFilter, pagination utils.Pagination) ([]*models.StockTransfer, int, error)
+	GetInTransit(ctx context.Context, storeID string) ([]dto.InTransitTransferReport, error)
+}
+
+// TrashRepository defines the interface for listing, restoring and purging soft-deleted records
+type TrashRepository interface {
+	List(ctx context.Context, entityType string, pagination utils.Pagination) ([]*models.TrashItem, int, error)
+	Restore(ctx context.Context, entityType, id string) error
+	Purge(ctx context.Context, before time.Time) (map[string]int, error)
+	PurgeAllTenants(ctx context.Context, before time.Time) (map[string]int, error)
+}
+
+// TenantRepository defines the interface for tenant data access
+type TenantRepository interface {
+	Create(ctx context.Context, tenant *models.Tenant, admin *models.User) error
+	GetByID(ctx context.Context, id string) (*models.Tenant, error)
+	UpdateLicense(ctx context.Context, id, licenseKey string, now time.Time) error
+}
+
+// RoleRepository defines the interface for the tenant's stored roles
+type RoleRepository interface {
+	Create(ctx context.Context, role *models.Role) error
+	GetByName(ctx context.Context, name string) (*models.Role, error)
+	List(ctx context.Context) ([]*models.Role, error)
+	Update(ctx context.Context, role *models.Role) error
+	Delete(ctx context.Context, id string) error
+	CountUsers(ctx context.Context, name string) (int, error)
+}
+
+// OverrideRepository defines the interface for manager approvals and their tokens
+type OverrideRepository interface {
+	Create(ctx context.Context, override *models.Override, tokenHash string) error
+	Use(ctx context.Context, use *models.OverrideUse, now time.Time) (*models.Override, error)
+	List(ctx context.Context, filter dto.OverrideListFilter, pagination utils.Pagination) ([]*models.Override, int, error)
+}
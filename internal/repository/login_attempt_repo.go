@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ilramdhan/pos-api/internal/models"
+)
+
+type loginAttemptRepository struct {
+	db *sql.DB
+}
+
+// NewLoginAttemptRepository creates a new login attempt repository. Keys are not tenant data:
+// they name users and terminals by their globally unique ids.
+func NewLoginAttemptRepository(db *sql.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+func (r *loginAttemptRepository) Get(ctx context.Context, key string) (*models.LoginAttempt, error) {
+	query := `SELECT key, failures, locked_until, updated_at FROM login_attempts WHERE key = $1`
+	attempt := &models.LoginAttempt{}
+	var lockedUntil sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, key).Scan(&attempt.Key, &attempt.Failures, &lockedUntil, &attempt.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if lockedUntil.Valid {
+		attempt.LockedUntil = &lockedUntil.Time
+	}
+	return attempt, nil
+}
+
+// RecordFailure counts a failed sign-in and returns the number of failures in a row
+func (r *loginAttemptRepository) RecordFailure(ctx context.Context, key string, now time.Time) (int, error) {
+	query := `
+		INSERT INTO login_attempts (key, failures, updated_at) VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET failures = login_attempts.failures + 1, updated_at = $2
+		RETURNING failures
+	`
+	var failures int
+	err := r.db.QueryRowContext(ctx, query, key, now).Scan(&failures)
+	return failures, err
+}
+
+// Lock locks sign-ins for the key until the given time and starts counting failures afresh
+func (r *loginAttemptRepository) Lock(ctx context.Context, key string, until, now time.Time) error {
+	query := `UPDATE login_attempts SET failures = 0, locked_until = $1, updated_at = $2 WHERE key = $3`
+	_, err := r.db.ExecContext(ctx, query, until, now, key)
+	return err
+}
+
+// Delay makes sign-ins for the key wait until the given time, keeping its failures counted
+func (r *loginAttemptRepository) Delay(ctx context.Context, key string, until, now time.Time) error {
+	query := `UPDATE login_attempts SET locked_until = $1, updated_at = $2 WHERE key = $3`
+	_, err := r.db.ExecContext(ctx, query, until, now, key)
+	return err
+}
+
+// Reset clears the failures and any lock of the key
+func (r *loginAttemptRepository) Reset(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM login_attempts WHERE key = $1`, key)
+	return err
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+type loginEventRepository struct {
+	db *sql.DB
+}
+
+// NewLoginEventRepository creates a new login event repository
+func NewLoginEventRepository(db *sql.DB) LoginEventRepository {
+	return &loginEventRepository{db: db}
+}
+
+const loginEventColumns = `id, COALESCE(tenant_id, ''), COALESCE(user_id, ''), email, event,
+	COALESCE(ip_address, ''), COALESCE(user_agent, ''), created_at`
+
+func scanLoginEvent(row interface{ Scan(...interface{}) error }) (*models.LoginEvent, error) {
+	event := &models.LoginEvent{}
+	err := row.Scan(
+		&event.ID, &event.TenantID, &event.UserID, &event.Email, &event.Event,
+		&event.IPAddress, &event.UserAgent, &event.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// Create records an event under its own tenant; sign-ins happen before there is a tenant to
+// take from the context
+func (r *loginEventRepository) Create(ctx context.Context, event *models.LoginEvent) error {
+	query := `
+		INSERT INTO login_events (id, tenant_id, user_id, email, event, ip_address, user_agent, created_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6, $7, $8)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		event.ID, event.TenantID, event.UserID, event.Email, event.Event,
+		event.IPAddress, event.UserAgent, event.CreatedAt,
+	)
+	return err
+}
+
+func (r *loginEventRepository) List(ctx context.Context, filter dto.LoginEventFilter, pagination utils.Pagination) ([]*models.LoginEvent, int, error) {
+	conditions := "tenant_id = $1"
+	args := []interface{}{utils.TenantID(ctx)}
+	argIndex := 2
+
+	if filter.UserID != "" {
+		conditions += fmt.Sprintf(" AND user_id = $%d", argIndex)
+		args = append(args, filter.UserID)
+		argIndex++
+	}
+	if filter.Event != "" {
+		conditions += fmt.Sprintf(" AND event = $%d", argIndex)
+		args = append(args, filter.Event)
+		argIndex++
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM login_events WHERE `+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM login_events WHERE %s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d`,
+		loginEventColumns, conditions, argIndex, argIndex+1)
+	args = append(args, pagination.Limit(), pagination.Offset())
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var events []*models.LoginEvent
+	for rows.Next() {
+		event, err := scanLoginEvent(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		events = append(events, event)
+	}
+
+	return events, total, rows.Err()
+}
@@ -0,0 +1,369 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// ErrInsufficientPoints is returned when a debit would take a loyalty balance below zero
+var ErrInsufficientPoints = errors.New("insufficient loyalty points")
+
+type loyaltyRepository struct {
+	db *sql.DB
+}
+
+// NewLoyaltyRepository creates a new loyalty repository
+func NewLoyaltyRepository(db *sql.DB) LoyaltyRepository {
+	return &loyaltyRepository{db: db}
+}
+
+const loyaltyLedgerColumns = `id, customer_id, transaction_id, type, points, balance_after, remaining,
+		       expires_at, COALESCE(note, ''), COALESCE(created_by, ''), created_at`
+
+func scanLoyaltyEntry(row interface{ Scan(...interface{}) error }) (*models.LoyaltyLedgerEntry, error) {
+	entry := &models.LoyaltyLedgerEntry{}
+	var transactionID sql.NullString
+	var expiresAt sql.NullTime
+	err := row.Scan(
+		&entry.ID, &entry.CustomerID, &transactionID, &entry.Type, &entry.Points, &entry.BalanceAfter,
+		&entry.Remaining, &expiresAt, &entry.Note, &entry.CreatedBy, &entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if transactionID.Valid {
+		entry.TransactionID = &transactionID.String
+	}
+	if expiresAt.Valid {
+		entry.ExpiresAt = &expiresAt.Time
+	}
+	return entry, nil
+}
+
+func (r *loyaltyRepository) Post(ctx context.Context, entry *models.LoyaltyLedgerEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := postLoyaltyEntry(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *loyaltyRepository) ListByCustomer(ctx context.Context, customerID string, pagination utils.Pagination) ([]*models.LoyaltyLedgerEntry, int, error) {
+	whereClause := "WHERE customer_id = $1 AND tenant_id = $2"
+	args := []interface{}{customerID, utils.TenantID(ctx)}
+	total, err := countRows(ctx, r.db, "FROM loyalty_ledger "+whereClause, args, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	keyset, keysetArgs, err := keysetCondition(pagination, "created_at", "id", 3)
+	if err != nil {
+		return nil, 0, err
+	}
+	if keyset != "" {
+		whereClause += " AND " + keyset
+		args = append(args, keysetArgs...)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT `+loyaltyLedgerColumns+`
+		FROM loyalty_ledger
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, len(args)+1, len(args)+2)
+	args = append(args, pagination.Limit(), pagination.Offset())
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entries []*models.LoyaltyLedgerEntry
+	for rows.Next() {
+		entry, err := scanLoyaltyEntry(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, total, rows.Err()
+}
+
+func (r *loyaltyRepository) ListByTransaction(ctx context.Context, transactionID string) ([]*models.LoyaltyLedgerEntry, error) {
+	query := `SELECT ` + loyaltyLedgerColumns + ` FROM loyalty_ledger WHERE transaction_id = $1 AND tenant_id = $2 ORDER BY created_at`
+	rows, err := r.db.QueryContext(ctx, query, transactionID, utils.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.LoyaltyLedgerEntry
+	for rows.Next() {
+		entry, err := scanLoyaltyEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *loyaltyRepository) GetExpiringPoints(ctx context.Context, customerID string, before time.Time) (int, error) {
+	var points int
+	query := `
+		SELECT COALESCE(SUM(remaining), 0) FROM loyalty_ledger
+		WHERE customer_id = $1 AND tenant_id = $3 AND remaining > 0 AND expires_at IS NOT NULL AND expires_at <= $2
+	`
+	err := r.db.QueryRowContext(ctx, query, customerID, before, utils.TenantID(ctx)).Scan(&points)
+	return points, err
+}
+
+func (r *loyaltyRepository) GetLifetimeSpend(ctx context.Context, customerID string) (float64, error) {
+	var spend float64
+	query := `SELECT COALESCE(SUM(total_amount), 0) FROM transactions WHERE customer_id = $1 AND tenant_id = $2 AND status = 'completed'`
+	err := r.db.QueryRowContext(ctx, query, customerID, utils.TenantID(ctx)).Scan(&spend)
+	return spend, err
+}
+
+// ExpirePoints expires due credits of every tenant. Each customer's balance is locked before
+// their ledger entries, in the same order as sales, so expiry never deadlocks with a sale.
+func (r *loyaltyRepository) ExpirePoints(ctx context.Context, now time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT tenant_id, customer_id FROM loyalty_ledger
+		WHERE remaining > 0 AND expires_at IS NOT NULL AND expires_at <= $1
+		ORDER BY customer_id
+	`, now)
+	if err != nil {
+		return 0, err
+	}
+
+	type dueCustomer struct {
+		tenantID, customerID string
+	}
+	var customers []dueCustomer
+	for rows.Next() {
+		var c dueCustomer
+		if err := rows.Scan(&c.tenantID, &c.customerID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		customers = append(customers, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, c := range customers {
+		// Each customer's points are expired on behalf of the tenant they belong to
+		ctx := utils.WithTenant(ctx, c.tenantID)
+		points, err := expireCustomerPoints(ctx, tx, c.customerID, now)
+		if err != nil {
+			return 0, err
+		}
+		expired += points
+	}
+
+	return expired, tx.Commit()
+}
+
+// expireCustomerPoints locks a customer's balance, then their due credits, and writes off what
+// is left of the credits inside the caller's database transaction
+func expireCustomerPoints(ctx context.Context, tx *sql.Tx, customerID string, now time.Time) (int, error) {
+	balance, err := lockLoyaltyBalance(ctx, tx, customerID)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, remaining FROM loyalty_ledger
+		WHERE customer_id = $1 AND tenant_id = $2 AND remaining > 0 AND expires_at IS NOT NULL AND expires_at <= $3
+		ORDER BY expires_at
+		FOR UPDATE
+	`, customerID, utils.TenantID(ctx), now)
+	if err != nil {
+		return 0, err
+	}
+
+	type dueEntry struct {
+		id        string
+		remaining int
+	}
+	var due []dueEntry
+	for rows.Next() {
+		var e dueEntry
+		if err := rows.Scan(&e.id, &e.remaining); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		due = append(due, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, e := range due {
+		if _, err := tx.ExecContext(ctx, `UPDATE loyalty_ledger SET remaining = 0 WHERE id = $1`, e.id); err != nil {
+			return 0, err
+		}
+
+		// Balances adjusted outside the ledger may hold fewer points than the entry has left
+		points := e.remaining
+		if points > balance {
+			points = balance
+		}
+		if points == 0 {
+			continue
+		}
+
+		entry := &models.LoyaltyLedgerEntry{
+			ID:           uuid.New().String(),
+			CustomerID:   customerID,
+			Type:         models.LoyaltyExpire,
+			Points:       -points,
+			BalanceAfter: balance - points,
+			Note:         "Points expired",
+			CreatedAt:    now,
+		}
+		if err := insertLoyaltyEntry(ctx, tx, entry); err != nil {
+			return 0, err
+		}
+		balance -= points
+		expired += points
+	}
+
+	return expired, nil
+}
+
+// postLoyaltyEntry locks the customer's balance and writes a ledger entry inside the caller's
+// database transaction. Debits consume the oldest unexpired credits first.
+func postLoyaltyEntry(ctx context.Context, tx *sql.Tx, entry *models.LoyaltyLedgerEntry) error {
+	balance, err := lockLoyaltyBalance(ctx, tx, entry.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	if entry.Points < 0 {
+		if balance+entry.Points < 0 {
+			return ErrInsufficientPoints
+		}
+		if err := consumeLoyaltyCredits(ctx, tx, entry.CustomerID, -entry.Points); err != nil {
+			return err
+		}
+		entry.Remaining = 0
+	} else {
+		entry.Remaining = entry.Points
+	}
+
+	entry.BalanceAfter = balance + entry.Points
+	return insertLoyaltyEntry(ctx, tx, entry)
+}
+
+func lockLoyaltyBalance(ctx context.Context, tx *sql.Tx, customerID string) (int, error) {
+	var balance int
+	err := tx.QueryRowContext(ctx,
+		`SELECT loyalty_points FROM customers WHERE id = $1 AND tenant_id = $2 FOR UPDATE`, customerID, utils.TenantID(ctx),
+	).Scan(&balance)
+	if err == sql.ErrNoRows {
+		return 0, ErrCustomerNotFound
+	}
+	return balance, err
+}
+
+func consumeLoyaltyCredits(ctx context.Context, tx *sql.Tx, customerID string, points int) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, remaining FROM loyalty_ledger
+		WHERE customer_id = $1 AND tenant_id = $2 AND remaining > 0
+		ORDER BY expires_at NULLS LAST, created_at
+		FOR UPDATE
+	`, customerID, utils.TenantID(ctx))
+	if err != nil {
+		return err
+	}
+
+	type credit struct {
+		id        string
+		remaining int
+	}
+	var credits []credit
+	for rows.Next() {
+		var c credit
+		if err := rows.Scan(&c.id, &c.remaining); err != nil {
+			rows.Close()
+			return err
+		}
+		credits = append(credits, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range credits {
+		if points == 0 {
+			break
+		}
+		take := c.remaining
+		if take > points {
+			take = points
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE loyalty_ledger SET remaining = remaining - $1 WHERE id = $2`, take, c.id,
+		); err != nil {
+			return err
+		}
+		points -= take
+	}
+
+	return nil
+}
+
+func insertLoyaltyEntry(ctx context.Context, tx *sql.Tx, entry *models.LoyaltyLedgerEntry) error {
+	var createdBy *string
+	if entry.CreatedBy != "" {
+		createdBy = &entry.CreatedBy
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO loyalty_ledger (id, tenant_id, customer_id, transaction_id, type, points, balance_after, remaining,
+		                            expires_at, note, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`,
+		entry.ID, utils.TenantID(ctx), entry.CustomerID, entry.TransactionID, entry.Type, entry.Points, entry.BalanceAfter,
+		entry.Remaining, entry.ExpiresAt, entry.Note, createdBy, entry.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE customers SET loyalty_points = $1, updated_at = $2 WHERE id = $3`,
+		entry.BalanceAfter, entry.CreatedAt, entry.CustomerID,
+	)
+	return err
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+type mfaRecoveryCodeRepository struct {
+	db *sql.DB
+}
+
+// NewMFARecoveryCodeRepository creates a new recovery code repository
+func NewMFARecoveryCodeRepository(db *sql.DB) MFARecoveryCodeRepository {
+	return &mfaRecoveryCodeRepository{db: db}
+}
+
+// Replace replaces all of the user's recovery codes; no hashes removes them
+func (r *mfaRecoveryCodeRepository) Replace(ctx context.Context, userID string, codeHashes []string, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenantID := utils.TenantID(ctx)
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM mfa_recovery_codes WHERE user_id = $1 AND tenant_id = $2`, userID, tenantID,
+	); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO mfa_recovery_codes (id, tenant_id, user_id, code_hash, created_at) VALUES ($1, $2, $3, $4, $5)`,
+			uuid.New().String(), tenantID, userID, hash, now,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Use marks an unused recovery code of the user as used. It reports false when the user has
+// no such code.
+func (r *mfaRecoveryCodeRepository) Use(ctx context.Context, userID, codeHash string, now time.Time) (bool, error) {
+	query := `
+		UPDATE mfa_recovery_codes SET used_at = $1
+		WHERE user_id = $2 AND code_hash = $3 AND tenant_id = $4 AND used_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, now, userID, codeHash, utils.TenantID(ctx))
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// CountUnused counts the user's recovery codes that are left
+func (r *mfaRecoveryCodeRepository) CountUnused(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = $1 AND tenant_id = $2 AND used_at IS NULL`,
+		userID, utils.TenantID(ctx),
+	).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+const notificationColumns = `id, tenant_id, user_id, type, title, message, COALESCE(is_read, FALSE),
+	COALESCE(action_url, ''), created_at`
+
+func scanNotification(row interface{ Scan(...interface{}) error }) (*models.Notification, error) {
+	notification := &models.Notification{}
+	err := row.Scan(
+		&notification.ID, &notification.TenantID, &notification.UserID, &notification.Type, &notification.Title,
+		&notification.Message, &notification.IsRead, &notification.ActionURL, &notification.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return notification, nil
+}
+
+func (r *notificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	query := `
+		INSERT INTO notifications (id, tenant_id, user_id, type, title, message, is_read, action_url, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		notification.ID, utils.TenantID(ctx), notification.UserID, notification.Type, notification.Title,
+		notification.Message, notification.IsRead, notification.ActionURL, notification.CreatedAt,
+	)
+	return err
+}
+
+// List returns the user's newest notifications, up to limit
+func (r *notificationRepository) List(ctx context.Context, userID string, unreadOnly bool, limit int) ([]*models.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE user_id = $1 AND tenant_id = $2`
+	if unreadOnly {
+		query += ` AND is_read = FALSE`
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT $3`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, utils.TenantID(ctx), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*models.Notification
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, rows.Err()
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND tenant_id = $2 AND is_read = FALSE`,
+		userID, utils.TenantID(ctx),
+	).Scan(&count)
+	return count, err
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, id, userID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET is_read = TRUE WHERE id = $1 AND user_id = $2 AND tenant_id = $3`,
+		id, userID, utils.TenantID(ctx),
+	)
+	return err
+}
+
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET is_read = TRUE WHERE user_id = $1 AND tenant_id = $2 AND is_read = FALSE`,
+		userID, utils.TenantID(ctx),
+	)
+	return err
+}
+
+func (r *notificationRepository) Delete(ctx context.Context, id, userID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM notifications WHERE id = $1 AND user_id = $2 AND tenant_id = $3`,
+		id, userID, utils.TenantID(ctx),
+	)
+	return err
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// ErrInvalidOverride is returned when an override token cannot be used for an action
+var ErrInvalidOverride = errors.New("override token is invalid, expired or already used")
+
+type overrideRepository struct {
+	db *sql.DB
+}
+
+// NewOverrideRepository creates a new override repository
+func NewOverrideRepository(db *sql.DB) OverrideRepository {
+	return &overrideRepository{db: db}
+}
+
+const overrideColumns = `id, tenant_id, action, COALESCE(reference_id, ''), amount, COALESCE(terminal_id, ''),
+	requested_by, approved_by, COALESCE(reason, ''), expires_at, used_at, created_at`
+
+func scanOverride(row interface{ Scan(...interface{}) error }) (*models.Override, error) {
+	override := &models.Override{}
+	var amount sql.NullFloat64
+	var usedAt sql.NullTime
+	err := row.Scan(
+		&override.ID, &override.TenantID, &override.Action, &override.ReferenceID, &amount, &override.TerminalID,
+		&override.RequestedBy, &override.ApprovedBy, &override.Reason, &override.ExpiresAt, &usedAt, &override.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Valid {
+		override.Amount = &amount.Float64
+	}
+	if usedAt.Valid {
+		override.UsedAt = &usedAt.Time
+	}
+	return override, nil
+}
+
+func (r *overrideRepository) Create(ctx context.Context, override *models.Override, tokenHash string) error {
+	query := `
+		INSERT INTO overrides (id, tenant_id, token_hash, action, reference_id, amount, terminal_id,
+			requested_by, approved_by, reason, expires_at, used_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		override.ID, utils.TenantID(ctx), tokenHash, override.Action, override.ReferenceID, override.Amount,
+		override.TerminalID, override.RequestedBy, override.ApprovedBy, override.Reason, override.ExpiresAt,
+		override.UsedAt, override.CreatedAt,
+	)
+	return err
+}
+
+// useOverrideQuery marks an unexpired, unused token as used for a reference. Tokens limited to
+// another reference, approved for another amount or given on another terminal do not match.
+const useOverrideQuery = `
+	UPDATE overrides SET used_at = $1, reference_id = $2
+	WHERE token_hash = $3 AND tenant_id = $4 AND action = $5 AND requested_by = $6
+	  AND used_at IS NULL AND expires_at > $1 AND COALESCE(reference_id, '') IN ('', $2)
+	  AND amount IS NOT DISTINCT FROM $7 AND COALESCE(terminal_id, '') = $8
+`
+
+// Use marks an unexpired, unused token as used for the use's reference and returns its
+// approval. It returns nil if the token was not issued to the user for the action, amount and
+// terminal, is limited to another reference, has expired or has been used.
+func (r *overrideRepository) Use(ctx context.Context, use *models.OverrideUse, now time.Time) (*models.Override, error) {
+	override, err := scanOverride(r.db.QueryRowContext(ctx, useOverrideQuery+` RETURNING `+overrideColumns,
+		now, use.ReferenceID, use.TokenHash, utils.TenantID(ctx), use.Action, use.RequestedBy, use.Amount, use.TerminalID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return override, err
+}
+
+// useOverride uses up an override token inside the caller's database transaction, so the token
+// is only spent if the action it approves is written. It returns ErrInvalidOverride if the
+// token cannot be used.
+func useOverride(ctx context.Context, tx *sql.Tx, use *models.OverrideUse, now time.Time) error {
+	result, err := tx.ExecContext(ctx, useOverrideQuery,
+		now, use.ReferenceID, use.TokenHash, utils.TenantID(ctx), use.Action, use.RequestedBy, use.Amount, use.TerminalID,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrInvalidOverride
+	}
+	return nil
+}
+
+func (r *overrideRepository) List(ctx context.Context, filter dto.OverrideListFilter, pagination utils.Pagination) ([]*models.Override, int, error) {
+	conditions := "tenant_id = $1"
+	args := []interface{}{utils.TenantID(ctx)}
+	argIndex := 2
+
+	if filter.Action != "" {
+		conditions += fmt.Sprintf(" AND action = $%d", argIndex)
+		args = append(args, filter.Action)
+		argIndex++
+	}
+	if filter.RequestedBy != "" {
+		conditions += fmt.Sprintf(" AND requested_by = $%d", argIndex)
+		args = append(args, filter.RequestedBy)
+		argIndex++
+	}
+	if filter.ApprovedBy != "" {
+		conditions += fmt.Sprintf(" AND approved_by = $%d", argIndex)
+		args = append(args, filter.ApprovedBy)
+		argIndex++
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM overrides WHERE `+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM overrides WHERE %s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d`,
+		overrideColumns, conditions, argIndex, argIndex+1)
+	args = append(args, pagination.Limit(), pagination.Offset())
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var overrides []*models.Override
+	for rows.Next() {
+		override, err := scanOverride(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		overrides = append(overrides, override)
+	}
+
+	return overrides, total, rows.Err()
+}
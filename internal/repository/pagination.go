@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// countRows counts the rows of "SELECT ... <from>". Cursor requests that did not ask for an
+// exact total get the planner's row estimate instead, which avoids a full count on large
+// tables.
+func countRows(ctx context.Context, db *sql.DB, from string, args []interface{}, pagination utils.Pagination) (int, error) {
+	var total int
+	if !pagination.EstimateTotal() {
+		err := db.QueryRowContext(ctx, "SELECT COUNT(*) "+from, args...).Scan(&total)
+		return total, err
+	}
+
+	var plan []byte
+	if err := db.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) SELECT 1 "+from, args...).Scan(&plan); err != nil {
+		return 0, err
+	}
+	var explain []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &explain); err != nil {
+		return 0, fmt.Errorf("failed to read query plan: %w", err)
+	}
+	if len(explain) == 0 {
+		return 0, errors.New("failed to read query plan: empty plan")
+	}
+	return int(explain[0].Plan.Rows), nil
+}
+
+// keysetCondition returns the condition that continues a (createdAt, id) descending list
+// after the request's cursor, with its arguments starting at $argIndex. Offset requests get
+// an empty condition.
+func keysetCondition(pagination utils.Pagination, createdAt, id string, argIndex int) (string, []interface{}, error) {
+	after, err := pagination.After()
+	if err != nil || after == nil {
+		return "", nil, err
+	}
+	condition := fmt.Sprintf("(%s, %s) < ($%d, $%d)", createdAt, id, argIndex, argIndex+1)
+	return condition, []interface{}{after.CreatedAt, after.ID}, nil
+}
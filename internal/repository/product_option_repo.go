@@ -0,0 +1,461 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+type productOptionRepository struct {
+	db *sql.DB
+}
+
+// NewProductOptionRepository creates a new product variant and modifier repository
+func NewProductOptionRepository(db *sql.DB) ProductOptionRepository {
+	return &productOptionRepository{db: db}
+}
+
+const productVariantColumns = `id, product_id, sku, name, price, stock, is_active, sort_order, created_at, updated_at`
+
+func scanProductVariant(row interface{ Scan(...interface{}) error }) (*models.ProductVariant, error) {
+	variant := &models.ProductVariant{}
+	err := row.Scan(
+		&variant.ID, &variant.ProductID, &variant.SKU, &variant.Name, &variant.Price, &variant.Stock,
+		&variant.IsActive, &variant.SortOrder, &variant.CreatedAt, &variant.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+// inPlaceholders returns "$start, $start+1, ..." for n arguments
+func inPlaceholders(start, n int) string {
+	placeholders := make([]string, n)
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", start+i)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+func (r *productOptionRepository) CreateVariant(ctx context.Context, variant *models.ProductVariant) error {
+	query := `
+		INSERT INTO product_variants (id, tenant_id, product_id, sku, name, price, stock, is_active, sort_order, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		variant.ID, utils.TenantID(ctx), variant.ProductID, variant.SKU, variant.Name, variant.Price, variant.Stock,
+		variant.IsActive, variant.SortOrder, variant.CreatedAt, variant.UpdatedAt,
+	)
+	return err
+}
+
+func (r *productOptionRepository) GetVariant(ctx context.Context, id string) (*models.ProductVariant, error) {
+	query := `SELECT ` + productVariantColumns + ` FROM product_variants WHERE id = $1 AND tenant_id = $2`
+	variant, err := scanProductVariant(r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return variant, err
+}
+
+func (r *productOptionRepository) GetVariantBySKU(ctx context.Context, sku string) (*models.ProductVariant, error) {
+	query := `SELECT ` + productVariantColumns + ` FROM product_variants WHERE sku = $1 AND tenant_id = $2`
+	variant, err := scanProductVariant(r.db.QueryRowContext(ctx, query, sku, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return variant, err
+}
+
+func (r *productOptionRepository) UpdateVariant(ctx context.Context, variant *models.ProductVariant) error {
+	query := `
+		UPDATE product_variants
+		SET sku = $1, name = $2, price = $3, stock = $4, is_active = $5, sort_order = $6, updated_at = $7
+		WHERE id = $8 AND tenant_id = $9
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		variant.SKU, variant.Name, variant.Price, variant.Stock, variant.IsActive, variant.SortOrder,
+		variant.UpdatedAt, variant.ID, utils.TenantID(ctx),
+	)
+	return err
+}
+
+func (r *productOptionRepository) UpdateVariantStock(ctx context.Context, id string, stock int) error {
+	query := `UPDATE product_variants SET stock = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND tenant_id = $3`
+	_, err := r.db.ExecContext(ctx, query, stock, id, utils.TenantID(ctx))
+	return err
+}
+
+// changeVariantStock changes a variant's stock, or its stock at a store, by a signed quantity
+// inside the caller's database transaction and returns the new stock. It returns sql.ErrNoRows
+// when stock would go below zero.
+func changeVariantStock(ctx context.Context, tx *sql.Tx, variantID string, storeID *string, quantity int, now time.Time) (int, error) {
+	tenantID := utils.TenantID(ctx)
+
+	var balance int
+	var err error
+	switch {
+	case storeID == nil:
+		err = tx.QueryRowContext(ctx, `
+			UPDATE product_variants SET stock = stock + $1, updated_at = $2
+			WHERE id = $3 AND tenant_id = $4 AND stock + $1 >= 0
+			RETURNING stock
+		`, quantity, now, variantID, tenantID).Scan(&balance)
+	case quantity < 0:
+		err = tx.QueryRowContext(ctx, `
+			UPDATE store_product_variants SET stock = stock + $1, updated_at = $2
+			WHERE store_id = $3 AND variant_id = $4 AND tenant_id = $5 AND stock + $1 >= 0
+			RETURNING stock
+		`, quantity, now, *storeID, variantID, tenantID).Scan(&balance)
+	default:
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO store_product_variants (tenant_id, store_id, variant_id, stock, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (store_id, variant_id) DO UPDATE
+			SET stock = store_product_variants.stock + EXCLUDED.stock, updated_at = EXCLUDED.updated_at
+			RETURNING stock
+		`, tenantID, *storeID, variantID, quantity, now).Scan(&balance)
+	}
+	return balance, err
+}
+
+// ApplyStoreStock replaces the stock of the given variants with their stock at a store.
+// Variants the store has never stocked have no stock there.
+func (r *productOptionRepository) ApplyStoreStock(ctx context.Context, storeID string, variants []*models.ProductVariant) error {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(variants))
+	for _, variant := range variants {
+		ids = append(ids, variant.ID)
+	}
+
+	query := fmt.Sprintf(
+		`SELECT variant_id, stock FROM store_product_variants WHERE store_id = $1 AND tenant_id = $2 AND variant_id IN (%s)`,
+		inPlaceholders(3, len(ids)),
+	)
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{storeID, utils.TenantID(ctx)}, stringArgs(ids)...)...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	stock := make(map[string]int, len(variants))
+	for rows.Next() {
+		var variantID string
+		var level int
+		if err := rows.Scan(&variantID, &level); err != nil {
+			return err
+		}
+		stock[variantID] = level
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, variant := range variants {
+		variant.Stock = stock[variant.ID]
+	}
+	return nil
+}
+
+func (r *productOptionRepository) DeleteVariant(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM product_variants WHERE id = $1 AND tenant_id = $2`, id, utils.TenantID(ctx))
+	return err
+}
+
+func (r *productOptionRepository) ListVariants(ctx context.Context, productIDs []string) ([]*models.ProductVariant, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM product_variants
+		WHERE tenant_id = $1 AND product_id IN (%s)
+		ORDER BY product_id, sort_order, name
+	`, productVariantColumns, inPlaceholders(2, len(productIDs)))
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{utils.TenantID(ctx)}, stringArgs(productIDs)...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var variants []*models.ProductVariant
+	for rows.Next() {
+		variant, err := scanProductVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, variant)
+	}
+
+	return variants, rows.Err()
+}
+
+func (r *productOptionRepository) CreateModifierGroup(ctx context.Context, group *models.ModifierGroup) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenantID := utils.TenantID(ctx)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO modifier_groups (id, tenant_id, product_id, name, min_select, max_select, sort_order, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, group.ID, tenantID, group.ProductID, group.Name, group.MinSelect, group.MaxSelect, group.SortOrder, group.CreatedAt, group.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := insertModifiers(ctx, tx, tenantID, group.Modifiers); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *productOptionRepository) GetModifierGroup(ctx context.Context, id string) (*models.ModifierGroup, error) {
+	group := &models.ModifierGroup{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, product_id, name, min_select, max_select, sort_order, created_at, updated_at
+		FROM modifier_groups WHERE id = $1 AND tenant_id = $2
+	`, id, utils.TenantID(ctx)).Scan(
+		&group.ID, &group.ProductID, &group.Name, &group.MinSelect, &group.MaxSelect,
+		&group.SortOrder, &group.CreatedAt, &group.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	modifiers, err := r.listModifiers(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	group.Modifiers = modifiers[id]
+
+	return group, nil
+}
+
+// UpdateModifierGroup updates a group and replaces its modifiers. Past sales keep their copy of
+// the modifier names and prices in transaction_item_modifiers.
+func (r *productOptionRepository) UpdateModifierGroup(ctx context.Context, group *models.ModifierGroup) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenantID := utils.TenantID(ctx)
+	_, err = tx.ExecContext(ctx, `
+		UPDATE modifier_groups
+		SET name = $1, min_select = $2, max_select = $3, sort_order = $4, updated_at = $5
+		WHERE id = $6 AND tenant_id = $7
+	`, group.Name, group.MinSelect, group.MaxSelect, group.SortOrder, group.UpdatedAt, group.ID, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM modifiers WHERE group_id = $1 AND tenant_id = $2`, group.ID, tenantID); err != nil {
+		return err
+	}
+
+	if err := insertModifiers(ctx, tx, tenantID, group.Modifiers); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *productOptionRepository) DeleteModifierGroup(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM modifier_groups WHERE id = $1 AND tenant_id = $2`, id, utils.TenantID(ctx))
+	return err
+}
+
+func (r *productOptionRepository) ListModifierGroups(ctx context.Context, productIDs []string) ([]*models.ModifierGroup, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, product_id, name, min_select, max_select, sort_order, created_at, updated_at
+		FROM modifier_groups
+		WHERE tenant_id = $1 AND product_id IN (%s)
+		ORDER BY product_id, sort_order, name
+	`, inPlaceholders(2, len(productIDs)))
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{utils.TenantID(ctx)}, stringArgs(productIDs)...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []*models.ModifierGroup
+	var groupIDs []string
+	for rows.Next() {
+		group := &models.ModifierGroup{}
+		if err := rows.Scan(
+			&group.ID, &group.ProductID, &group.Name, &group.MinSelect, &group.MaxSelect,
+			&group.SortOrder, &group.CreatedAt, &group.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+		groupIDs = append(groupIDs, group.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	modifiers, err := r.listModifiers(ctx, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		group.Modifiers = modifiers[group.ID]
+	}
+
+	return groups, nil
+}
+
+// listModifiers returns the modifiers of the given groups keyed by group ID
+const productBarcodeColumns = `id, product_id, variant_id, code, type, created_at`
+
+func scanProductBarcode(row interface{ Scan(...interface{}) error }) (*models.ProductBarcode, error) {
+	barcode := &models.ProductBarcode{}
+	var variantID sql.NullString
+	if err := row.Scan(
+		&barcode.ID, &barcode.ProductID, &variantID, &barcode.Code, &barcode.Type, &barcode.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if variantID.Valid {
+		barcode.VariantID = &variantID.String
+	}
+	return barcode, nil
+}
+
+func (r *productOptionRepository) CreateBarcode(ctx context.Context, barcode *models.ProductBarcode) error {
+	query := `
+		INSERT INTO product_barcodes (id, tenant_id, product_id, variant_id, code, type, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		barcode.ID, utils.TenantID(ctx), barcode.ProductID, barcode.VariantID, barcode.Code, barcode.Type, barcode.CreatedAt,
+	)
+	return err
+}
+
+func (r *productOptionRepository) GetBarcode(ctx context.Context, id string) (*models.ProductBarcode, error) {
+	query := `SELECT ` + productBarcodeColumns + ` FROM product_barcodes WHERE id = $1 AND tenant_id = $2`
+	barcode, err := scanProductBarcode(r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return barcode, err
+}
+
+func (r *productOptionRepository) GetBarcodeByCode(ctx context.Context, code string) (*models.ProductBarcode, error) {
+	query := `SELECT ` + productBarcodeColumns + ` FROM product_barcodes WHERE code = $1 AND tenant_id = $2`
+	barcode, err := scanProductBarcode(r.db.QueryRowContext(ctx, query, code, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return barcode, err
+}
+
+func (r *productOptionRepository) DeleteBarcode(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM product_barcodes WHERE id = $1 AND tenant_id = $2`, id, utils.TenantID(ctx))
+	return err
+}
+
+func (r *productOptionRepository) ListBarcodes(ctx context.Context, productIDs []string) ([]*models.ProductBarcode, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM product_barcodes
+		WHERE tenant_id = $1 AND product_id IN (%s)
+		ORDER BY product_id, created_at
+	`, productBarcodeColumns, inPlaceholders(2, len(productIDs)))
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{utils.TenantID(ctx)}, stringArgs(productIDs)...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var barcodes []*models.ProductBarcode
+	for rows.Next() {
+		barcode, err := scanProductBarcode(rows)
+		if err != nil {
+			return nil, err
+		}
+		barcodes = append(barcodes, barcode)
+	}
+
+	return barcodes, rows.Err()
+}
+
+func (r *productOptionRepository) listModifiers(ctx context.Context, groupIDs []string) (map[string][]models.Modifier, error) {
+	modifiers := make(map[string][]models.Modifier)
+	if len(groupIDs) == 0 {
+		return modifiers, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, group_id, name, price_delta, is_active, sort_order
+		FROM modifiers
+		WHERE group_id IN (%s)
+		ORDER BY group_id, sort_order, name
+	`, inPlaceholders(1, len(groupIDs)))
+	rows, err := r.db.QueryContext(ctx, query, stringArgs(groupIDs)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var modifier models.Modifier
+		if err := rows.Scan(
+			&modifier.ID, &modifier.GroupID, &modifier.Name, &modifier.PriceDelta, &modifier.IsActive, &modifier.SortOrder,
+		); err != nil {
+			return nil, err
+		}
+		modifiers[modifier.GroupID] = append(modifiers[modifier.GroupID], modifier)
+	}
+
+	return modifiers, rows.Err()
+}
+
+func insertModifiers(ctx context.Context, tx *sql.Tx, tenantID string, modifiers []models.Modifier) error {
+	query := `
+		INSERT INTO modifiers (id, tenant_id, group_id, name, price_delta, is_active, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	for _, modifier := range modifiers {
+		if _, err := tx.ExecContext(ctx, query,
+			modifier.ID, tenantID, modifier.GroupID, modifier.Name, modifier.PriceDelta, modifier.IsActive, modifier.SortOrder,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
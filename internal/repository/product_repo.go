@@ -3,14 +3,20 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/ilramdhan/pos-api/internal/dto"
 	"github.com/ilramdhan/pos-api/internal/models"
 	"github.com/ilramdhan/pos-api/internal/utils"
 )
 
+// ErrInsufficientStock is returned when a sale would take a product's or variant's stock below zero
+var ErrInsufficientStock = errors.New("insufficient stock")
+
 type productRepository struct {
 	db *sql.DB
 }
@@ -22,11 +28,11 @@ func NewProductRepository(db *sql.DB) ProductRepository {
 
 func (r *productRepository) Create(ctx context.Context, product *models.Product) error {
 	query := `
-		INSERT INTO products (id, category_id, sku, name, description, price, stock, image_url, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO products (id, tenant_id, category_id, sku, name, description, price, stock, image_url, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 	_, err := r.db.ExecContext(ctx, query,
-		product.ID, product.CategoryID, product.SKU, product.Name, product.Description,
+		product.ID, utils.TenantID(ctx), product.CategoryID, product.SKU, product.Name, product.Description,
 		product.Price, product.Stock, product.ImageURL, product.IsActive,
 		product.CreatedAt, product.UpdatedAt,
 	)
@@ -35,18 +41,18 @@ func (r *productRepository) Create(ctx context.Context, product *models.Product)
 
 func (r *productRepository) GetByID(ctx context.Context, id string) (*models.Product, error) {
 	query := `
-		SELECT p.id, p.category_id, p.sku, p.name, COALESCE(p.description, ''), p.price, p.stock, COALESCE(p.image_url, ''), p.is_active, p.created_at, p.updated_at,
+		SELECT p.id, p.category_id, p.sku, p.name, COALESCE(p.description, ''), p.price, p.cost_price, p.stock, COALESCE(p.image_url, ''), COALESCE(p.thumbnail_url, ''), p.is_active, p.created_at, p.updated_at,
 		       c.id, c.name, COALESCE(c.description, ''), c.slug, c.is_active, c.created_at, c.updated_at
 		FROM products p
 		LEFT JOIN categories c ON p.category_id = c.id
-		WHERE p.id = $1
+		WHERE p.id = $1 AND p.tenant_id = $2 AND p.deleted_at IS NULL
 	`
 	product := &models.Product{}
 	category := &models.Category{}
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)).Scan(
 		&product.ID, &product.CategoryID, &product.SKU, &product.Name, &product.Description,
-		&product.Price, &product.Stock, &product.ImageURL, &product.IsActive,
+		&product.Price, &product.CostPrice, &product.Stock, &product.ImageURL, &product.ThumbnailURL, &product.IsActive,
 		&product.CreatedAt, &product.UpdatedAt,
 		&category.ID, &category.Name, &category.Description, &category.Slug,
 		&category.IsActive, &category.CreatedAt, &category.UpdatedAt,
@@ -64,13 +70,13 @@ func (r *productRepository) GetByID(ctx context.Context, id string) (*models.Pro
 
 func (r *productRepository) GetBySKU(ctx context.Context, sku string) (*models.Product, error) {
 	query := `
-		SELECT id, category_id, sku, name, COALESCE(description, ''), price, stock, COALESCE(image_url, ''), is_active, created_at, updated_at
-		FROM products WHERE sku = $1
+		SELECT id, category_id, sku, name, COALESCE(description, ''), price, cost_price, stock, COALESCE(image_url, ''), COALESCE(thumbnail_url, ''), is_active, created_at, updated_at
+		FROM products WHERE sku = $1 AND tenant_id = $2 AND deleted_at IS NULL
 	`
 	product := &models.Product{}
-	err := r.db.QueryRowContext(ctx, query, sku).Scan(
+	err := r.db.QueryRowContext(ctx, query, sku, utils.TenantID(ctx)).Scan(
 		&product.ID, &product.CategoryID, &product.SKU, &product.Name, &product.Description,
-		&product.Price, &product.Stock, &product.ImageURL, &product.IsActive,
+		&product.Price, &product.CostPrice, &product.Stock, &product.ImageURL, &product.ThumbnailURL, &product.IsActive,
 		&product.CreatedAt, &product.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -83,57 +89,331 @@ func (r *productRepository) Update(ctx context.Context, product *models.Product)
 	query := `
 		UPDATE products SET category_id = $1, sku = $2, name = $3, description = $4, price = $5, 
 		       stock = $6, image_url = $7, is_active = $8, updated_at = $9
-		WHERE id = $10
+		WHERE id = $10 AND tenant_id = $11
 	`
 	_, err := r.db.ExecContext(ctx, query,
 		product.CategoryID, product.SKU, product.Name, product.Description,
 		product.Price, product.Stock, product.ImageURL, product.IsActive,
-		product.UpdatedAt, product.ID,
+		product.UpdatedAt, product.ID, utils.TenantID(ctx),
 	)
 	return err
 }
 
+// ImportBatch creates and updates products and posts their stock adjustments in a single
+// database transaction. Updates leave stock alone; it only changes through the movements.
+func (r *productRepository) ImportBatch(ctx context.Context, creates, updates []*models.Product, movements []*models.StockMovement) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenantID := utils.TenantID(ctx)
+	insertQuery := `
+		INSERT INTO products (id, tenant_id, category_id, sku, name, description, price, stock, image_url, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	for _, product := range creates {
+		if _, err := tx.ExecContext(ctx, insertQuery,
+			product.ID, tenantID, product.CategoryID, product.SKU, product.Name, product.Description,
+			product.Price, product.Stock, product.ImageURL, product.IsActive,
+			product.CreatedAt, product.UpdatedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	updateQuery := `
+		UPDATE products SET category_id = $1, name = $2, description = $3, price = $4,
+		       is_active = $5, updated_at = $6
+		WHERE id = $7 AND tenant_id = $8 AND deleted_at IS NULL
+	`
+	for _, product := range updates {
+		if _, err := tx.ExecContext(ctx, updateQuery,
+			product.CategoryID, product.Name, product.Description, product.Price,
+			product.IsActive, product.UpdatedAt, product.ID, tenantID,
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, movement := range movements {
+		if err := postProductMovement(ctx, tx, movement); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UpdateImage sets the product's image and thumbnail URLs
+func (r *productRepository) UpdateImage(ctx context.Context, id, imageURL, thumbnailURL string) error {
+	query := `UPDATE products SET image_url = $1, thumbnail_url = $2, updated_at = $3 WHERE id = $4 AND tenant_id = $5`
+	_, err := r.db.ExecContext(ctx, query, imageURL, thumbnailURL, time.Now(), id, utils.TenantID(ctx))
+	return err
+}
+
 func (r *productRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM products WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query := `UPDATE products SET deleted_at = $1 WHERE id = $2 AND tenant_id = $3 AND deleted_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), id, utils.TenantID(ctx))
 	return err
 }
 
-func (r *productRepository) UpdateStock(ctx context.Context, id string, quantity int) error {
-	query := `UPDATE products SET stock = $1 WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, quantity, id)
+// PostMovement applies a single movement to a product's stock and records it
+func (r *productRepository) PostMovement(ctx context.Context, movement *models.StockMovement) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := postProductMovement(ctx, tx, movement); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// postProductMovement applies a movement to a product's or variant's stock, or its stock at the
+// movement's store, and records it inside the caller's database transaction. Stock never goes
+// below zero.
+func postProductMovement(ctx context.Context, tx *sql.Tx, movement *models.StockMovement) error {
+	tenantID := utils.TenantID(ctx)
+	quantity := int(movement.Quantity)
+
+	var balance int
+	var err error
+	switch {
+	case movement.VariantID != nil:
+		balance, err = changeVariantStock(ctx, tx, *movement.VariantID, movement.StoreID, quantity, movement.CreatedAt)
+	case movement.StoreID == nil:
+		err = tx.QueryRowContext(ctx, `
+			UPDATE products SET stock = stock + $1, updated_at = $2
+			WHERE id = $3 AND tenant_id = $4 AND stock + $1 >= 0
+			RETURNING stock
+		`, quantity, movement.CreatedAt, movement.ProductID, tenantID).Scan(&balance)
+	case quantity < 0:
+		err = tx.QueryRowContext(ctx, `
+			UPDATE store_products SET stock = stock + $1, updated_at = $2
+			WHERE store_id = $3 AND product_id = $4 AND tenant_id = $5 AND stock + $1 >= 0
+			RETURNING stock
+		`, quantity, movement.CreatedAt, *movement.StoreID, movement.ProductID, tenantID).Scan(&balance)
+	default:
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO store_products (tenant_id, store_id, product_id, stock, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (store_id, product_id) DO UPDATE
+			SET stock = store_products.stock + EXCLUDED.stock, updated_at = EXCLUDED.updated_at
+			RETURNING stock
+		`, tenantID, *movement.StoreID, movement.ProductID, quantity, movement.CreatedAt).Scan(&balance)
+	}
+	if err == sql.ErrNoRows {
+		if movement.VariantID != nil {
+			return fmt.Errorf("%w: variant %s", ErrInsufficientStock, *movement.VariantID)
+		}
+		return fmt.Errorf("%w: %s", ErrInsufficientStock, movement.ProductID)
+	}
+	if err != nil {
+		return err
+	}
+	movement.BalanceAfter = float64(balance)
+
+	var createdBy *string
+	if movement.CreatedBy != "" {
+		createdBy = &movement.CreatedBy
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO stock_movements (id, tenant_id, product_id, variant_id, store_id, type, quantity, balance_after,
+			reference_id, note, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`,
+		movement.ID, tenantID, movement.ProductID, movement.VariantID, movement.StoreID, movement.Type, quantity,
+		balance, movement.ReferenceID, movement.Note, createdBy, movement.CreatedAt,
+	)
 	return err
 }
 
+// ListMovements lists changes to product stock, newest first
+func (r *productRepository) ListMovements(ctx context.Context, filter dto.ProductStockMovementFilter, pagination utils.Pagination) ([]*models.StockMovement, int, error) {
+	whereClause := "WHERE m.product_id IS NOT NULL AND m.tenant_id = $1"
+	args := []interface{}{utils.TenantID(ctx)}
+	if filter.ProductID != "" {
+		args = append(args, filter.ProductID)
+		whereClause += fmt.Sprintf(" AND m.product_id = $%d", len(args))
+	}
+	if filter.StoreID != "" {
+		args = append(args, filter.StoreID)
+		whereClause += fmt.Sprintf(" AND m.store_id = $%d", len(args))
+	}
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		whereClause += fmt.Sprintf(" AND m.type = $%d", len(args))
+	}
+
+	total, err := countRows(ctx, r.db, "FROM stock_movements m "+whereClause, args, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	keyset, keysetArgs, err := keysetCondition(pagination, "m.created_at", "m.id", len(args)+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	if keyset != "" {
+		whereClause += " AND " + keyset
+		args = append(args, keysetArgs...)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT m.id, m.product_id, m.variant_id, m.store_id, m.type, m.quantity, m.balance_after, m.reference_id,
+		       COALESCE(m.note, ''), COALESCE(m.created_by, ''), m.created_at, p.name, p.sku, COALESCE(v.name, ''),
+		       COALESCE(u.name, '')
+		FROM stock_movements m
+		JOIN products p ON m.product_id = p.id
+		LEFT JOIN product_variants v ON m.variant_id = v.id
+		LEFT JOIN users u ON m.created_by = u.id
+		%s
+		ORDER BY m.created_at DESC, m.id DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, len(args)+1, len(args)+2)
+	args = append(args, pagination.Limit(), pagination.Offset())
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var movements []*models.StockMovement
+	for rows.Next() {
+		movement := &models.StockMovement{}
+		var variantID, storeID, referenceID sql.NullString
+		if err := rows.Scan(
+			&movement.ID, &movement.ProductID, &variantID, &storeID, &movement.Type, &movement.Quantity, &movement.BalanceAfter,
+			&referenceID, &movement.Note, &movement.CreatedBy, &movement.CreatedAt,
+			&movement.ProductName, &movement.SKU, &movement.VariantName, &movement.CreatedByName,
+		); err != nil {
+			return nil, 0, err
+		}
+		if variantID.Valid {
+			movement.VariantID = &variantID.String
+		}
+		if storeID.Valid {
+			movement.StoreID = &storeID.String
+		}
+		if referenceID.Valid {
+			movement.ReferenceID = &referenceID.String
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, total, rows.Err()
+}
+
+// ApplyStoreLevels replaces the stock of the given products with their stock at a store, and
+// their price with the store's price where it has one. Products the store has never stocked
+// have no stock there.
+func (r *productRepository) ApplyStoreLevels(ctx context.Context, storeID string, products []*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+
+	query := fmt.Sprintf(
+		`SELECT product_id, stock, price FROM store_products WHERE store_id = $1 AND tenant_id = $2 AND product_id IN (%s)`,
+		inPlaceholders(3, len(ids)),
+	)
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{storeID, utils.TenantID(ctx)}, stringArgs(ids)...)...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type storeLevel struct {
+		stock int
+		price sql.NullFloat64
+	}
+	levels := make(map[string]storeLevel, len(products))
+	for rows.Next() {
+		var productID string
+		var level storeLevel
+		if err := rows.Scan(&productID, &level.stock, &level.price); err != nil {
+			return err
+		}
+		levels[productID] = level
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		level := levels[product.ID]
+		product.Stock = level.stock
+		if level.price.Valid {
+			product.Price = level.price.Float64
+		}
+	}
+	return nil
+}
+
+// productSortFields are the sort keys accepted by product listings
+var productSortFields = utils.SortFields{
+	"name":       "p.name",
+	"sku":        "p.sku",
+	"price":      "p.price",
+	"stock":      "p.stock",
+	"category":   "c.name",
+	"created_at": "p.created_at",
+	"updated_at": "p.updated_at",
+}
+
 func (r *productRepository) List(ctx context.Context, filter dto.ProductListFilter, pagination utils.Pagination) ([]*models.Product, int, error) {
-	// Build where clause
-	var conditions []string
-	var args []interface{}
-	argIndex := 1
+	// Build where clause; products in the trash are never listed
+	conditions := []string{"p.tenant_id = $1", "p.deleted_at IS NULL"}
+	args := []interface{}{utils.TenantID(ctx)}
+	argIndex := 2
+
+	// Listed for a store, products show the store's stock and price
+	priceExpr, stockExpr := "p.price", "p.stock"
+	storeJoin := ""
+	sortFields := productSortFields
+	if filter.StoreID != "" {
+		storeJoin = fmt.Sprintf("LEFT JOIN store_products sp ON sp.product_id = p.id AND sp.store_id = $%d", argIndex)
+		args = append(args, filter.StoreID)
+		argIndex++
+		priceExpr, stockExpr = "COALESCE(sp.price, p.price)", "COALESCE(sp.stock, 0)"
+		sortFields = sortFields.With("price", priceExpr).With("stock", stockExpr)
+	}
 
 	if filter.CategoryID != "" {
 		conditions = append(conditions, fmt.Sprintf("p.category_id = $%d", argIndex))
 		args = append(args, filter.CategoryID)
 		argIndex++
 	}
+	rank := ""
 	if filter.Search != "" {
-		conditions = append(conditions, fmt.Sprintf("(p.name ILIKE $%d OR p.sku ILIKE $%d)", argIndex, argIndex+1))
-		searchTerm := "%" + filter.Search + "%"
-		args = append(args, searchTerm, searchTerm)
-		argIndex += 2
+		var condition string
+		condition, rank = productSearchClause(argIndex)
+		conditions = append(conditions, condition)
+		args = append(args, productSearchArgs(filter.Search)...)
+		argIndex += 3
 	}
 	if filter.MinPrice > 0 {
-		conditions = append(conditions, fmt.Sprintf("p.price >= $%d", argIndex))
+		conditions = append(conditions, fmt.Sprintf("%s >= $%d", priceExpr, argIndex))
 		args = append(args, filter.MinPrice)
 		argIndex++
 	}
 	if filter.MaxPrice > 0 {
-		conditions = append(conditions, fmt.Sprintf("p.price <= $%d", argIndex))
+		conditions = append(conditions, fmt.Sprintf("%s <= $%d", priceExpr, argIndex))
 		args = append(args, filter.MaxPrice)
 		argIndex++
 	}
 	if filter.InStock != nil && *filter.InStock {
-		conditions = append(conditions, "p.stock > 0")
+		conditions = append(conditions, stockExpr+" > 0")
 	}
 	if filter.IsActive != nil {
 		conditions = append(conditions, fmt.Sprintf("p.is_active = $%d", argIndex))
@@ -141,28 +421,35 @@ func (r *productRepository) List(ctx context.Context, filter dto.ProductListFilt
 		argIndex++
 	}
 
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
-	}
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
 
 	// Get total count
 	var total int
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM products p %s`, whereClause)
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM products p LEFT JOIN categories c ON p.category_id = c.id %s %s`, storeJoin, whereClause)
 	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
+	// Relevance is only a sort key when searching
+	if rank != "" {
+		sortFields = sortFields.With("relevance", rank)
+	}
+	orderBy, err := pagination.OrderClause(sortFields, "p.id")
+	if err != nil {
+		return nil, 0, err
+	}
+
 	// Build paginated query
 	query := fmt.Sprintf(`
-		SELECT p.id, p.category_id, p.sku, p.name, COALESCE(p.description, ''), p.price, p.stock, COALESCE(p.image_url, ''), p.is_active, p.created_at, p.updated_at,
+		SELECT p.id, p.category_id, p.sku, p.name, COALESCE(p.description, ''), %s, p.cost_price, %s, COALESCE(p.image_url, ''), COALESCE(p.thumbnail_url, ''), p.is_active, p.created_at, p.updated_at,
 		       c.id, c.name, COALESCE(c.description, ''), c.slug, c.is_active, c.created_at, c.updated_at
 		FROM products p
 		LEFT JOIN categories c ON p.category_id = c.id
 		%s
-		ORDER BY p.%s
+		%s
+		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, pagination.OrderBy(), argIndex, argIndex+1)
+	`, priceExpr, stockExpr, storeJoin, whereClause, orderBy, argIndex, argIndex+1)
 
 	args = append(args, pagination.Limit(), pagination.Offset())
 	rows, err := r.db.QueryContext(ctx, query, args...)
@@ -173,34 +460,106 @@ func (r *productRepository) List(ctx context.Context, filter dto.ProductListFilt
 
 	var products []*models.Product
 	for rows.Next() {
-		product := &models.Product{}
-		var catID, catName, catDesc, catSlug sql.NullString
-		var catIsActive sql.NullBool
-		var catCreatedAt, catUpdatedAt sql.NullTime
-
-		if err := rows.Scan(
-			&product.ID, &product.CategoryID, &product.SKU, &product.Name, &product.Description,
-			&product.Price, &product.Stock, &product.ImageURL, &product.IsActive,
-			&product.CreatedAt, &product.UpdatedAt,
-			&catID, &catName, &catDesc, &catSlug,
-			&catIsActive, &catCreatedAt, &catUpdatedAt,
-		); err != nil {
+		product, err := scanProductWithCategory(rows)
+		if err != nil {
 			return nil, 0, err
 		}
+		products = append(products, product)
+	}
 
-		if catID.Valid {
-			product.Category = &models.Category{
-				ID:          catID.String,
-				Name:        catName.String,
-				Description: catDesc.String,
-				Slug:        catSlug.String,
-				IsActive:    catIsActive.Bool,
-				CreatedAt:   catCreatedAt.Time,
-				UpdatedAt:   catUpdatedAt.Time,
-			}
+	return products, total, rows.Err()
+}
+
+// Search returns the active products best matching a search term, most relevant first
+func (r *productRepository) Search(ctx context.Context, search string, limit int) ([]*models.Product, error) {
+	condition, rank := productSearchClause(1)
+	query := fmt.Sprintf(`
+		SELECT p.id, p.category_id, p.sku, p.name, COALESCE(p.description, ''), p.price, p.cost_price, p.stock, COALESCE(p.image_url, ''), COALESCE(p.thumbnail_url, ''), p.is_active, p.created_at, p.updated_at,
+		       c.id, c.name, COALESCE(c.description, ''), c.slug, c.is_active, c.created_at, c.updated_at
+		FROM products p
+		LEFT JOIN categories c ON p.category_id = c.id
+		WHERE p.tenant_id = $4 AND p.deleted_at IS NULL AND p.is_active = TRUE AND %s
+		ORDER BY %s DESC, p.name, p.id
+		LIMIT $5
+	`, condition, rank)
+
+	args := append(productSearchArgs(search), utils.TenantID(ctx), limit)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []*models.Product
+	for rows.Next() {
+		product, err := scanProductWithCategory(rows)
+		if err != nil {
+			return nil, err
 		}
 		products = append(products, product)
 	}
+	return products, rows.Err()
+}
 
-	return products, total, rows.Err()
+// productSearchClause builds the match condition and relevance expression of a product
+// search over the three arguments from productSearchArgs, starting at argIndex. A product
+// matches on its full-text document (stemmed words or word prefixes), on a trigram
+// word-similarity with its name or category name (typo tolerance), or on a SKU substring.
+// Expects products aliased p and categories joined as c.
+func productSearchClause(argIndex int) (condition, rank string) {
+	term, prefix, pattern := argIndex, argIndex+1, argIndex+2
+	tsQuery := fmt.Sprintf(
+		"(to_tsquery('simple', $%d) || websearch_to_tsquery('indonesian', $%d) || websearch_to_tsquery('english', $%d))",
+		prefix, term, term,
+	)
+	condition = fmt.Sprintf(
+		"(p.search_vector @@ %s OR $%d <%% p.name OR $%d <%% c.name OR p.sku ILIKE $%d)",
+		tsQuery, term, term, pattern,
+	)
+	rank = fmt.Sprintf(
+		"(ts_rank(p.search_vector, %s) + word_similarity($%d, p.name) + 0.5 * word_similarity($%d, COALESCE(c.name, '')) + CASE WHEN LOWER(p.sku) = LOWER($%d) THEN 1 ELSE 0 END)",
+		tsQuery, term, term, term,
+	)
+	return condition, rank
+}
+
+// productSearchArgs returns the search term, its prefix tsquery and its SKU pattern
+func productSearchArgs(search string) []interface{} {
+	words := strings.FieldsFunc(strings.ToLower(search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return []interface{}{search, strings.Join(words, " & "), "%" + search + "%"}
+}
+
+func scanProductWithCategory(row interface{ Scan(...interface{}) error }) (*models.Product, error) {
+	product := &models.Product{}
+	var catID, catName, catDesc, catSlug sql.NullString
+	var catIsActive sql.NullBool
+	var catCreatedAt, catUpdatedAt sql.NullTime
+
+	if err := row.Scan(
+		&product.ID, &product.CategoryID, &product.SKU, &product.Name, &product.Description,
+		&product.Price, &product.CostPrice, &product.Stock, &product.ImageURL, &product.ThumbnailURL, &product.IsActive,
+		&product.CreatedAt, &product.UpdatedAt,
+		&catID, &catName, &catDesc, &catSlug,
+		&catIsActive, &catCreatedAt, &catUpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if catID.Valid {
+		product.Category = &models.Category{
+			ID:          catID.String,
+			Name:        catName.String,
+			Description: catDesc.String,
+			Slug:        catSlug.String,
+			IsActive:    catIsActive.Bool,
+			CreatedAt:   catCreatedAt.Time,
+			UpdatedAt:   catUpdatedAt.Time,
+		}
+	}
+	return product, nil
 }
@@ -0,0 +1,445 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+var (
+	// ErrPurchaseOrderStatusChanged is returned when a purchase order changed status concurrently
+	ErrPurchaseOrderStatusChanged = errors.New("purchase order status has changed; reload and try again")
+	// ErrPurchaseOrderNotReceivable is returned when goods are received against an order that is
+	// not ordered or partially received
+	ErrPurchaseOrderNotReceivable = errors.New("goods can only be received against ordered purchase orders")
+	// ErrOverReceipt is returned when a receipt exceeds the quantity still outstanding on a line
+	ErrOverReceipt = errors.New("received quantity exceeds the quantity outstanding")
+)
+
+type purchaseOrderRepository struct {
+	db *sql.DB
+}
+
+// NewPurchaseOrderRepository creates a new purchase order repository
+func NewPurchaseOrderRepository(db *sql.DB) PurchaseOrderRepository {
+	return &purchaseOrderRepository{db: db}
+}
+
+const purchaseOrderColumns = `po.id, po.po_number, po.supplier_id, po.store_id, po.status, po.expected_at, COALESCE(po.notes, ''),
+		       COALESCE(po.created_by, ''), po.ordered_at, po.received_at, po.created_at, po.updated_at,
+		       s.name, COALESCE((SELECT SUM(l.quantity * l.unit_cost) FROM purchase_order_lines l WHERE l.purchase_order_id = po.id), 0)`
+
+func scanPurchaseOrder(row interface{ Scan(...interface{}) error }) (*models.PurchaseOrder, error) {
+	order := &models.PurchaseOrder{}
+	var storeID sql.NullString
+	var expectedAt, orderedAt, receivedAt sql.NullTime
+	err := row.Scan(
+		&order.ID, &order.PONumber, &order.SupplierID, &storeID, &order.Status, &expectedAt, &order.Notes,
+		&order.CreatedBy, &orderedAt, &receivedAt, &order.CreatedAt, &order.UpdatedAt,
+		&order.SupplierName, &order.ExpectedCost,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if storeID.Valid {
+		order.StoreID = &storeID.String
+	}
+	if expectedAt.Valid {
+		order.ExpectedAt = &expectedAt.Time
+	}
+	if orderedAt.Valid {
+		order.OrderedAt = &orderedAt.Time
+	}
+	if receivedAt.Valid {
+		order.ReceivedAt = &receivedAt.Time
+	}
+	return order, nil
+}
+
+func (r *purchaseOrderRepository) Create(ctx context.Context, order *models.PurchaseOrder) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var createdBy *string
+	if order.CreatedBy != "" {
+		createdBy = &order.CreatedBy
+	}
+	tenantID := utils.TenantID(ctx)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO purchase_orders (id, tenant_id, po_number, supplier_id, store_id, status, expected_at, notes, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		order.ID, tenantID, order.PONumber, order.SupplierID, order.StoreID, order.Status, order.ExpectedAt, order.Notes,
+		createdBy, order.CreatedAt, order.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := insertPurchaseOrderLines(ctx, tx, tenantID, order.Lines); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *purchaseOrderRepository) GetByID(ctx context.Context, id string) (*models.PurchaseOrder, error) {
+	query := `
+		SELECT ` + purchaseOrderColumns + `
+		FROM purchase_orders po
+		JOIN suppliers s ON po.supplier_id = s.id
+		WHERE po.id = $1 AND po.tenant_id = $2
+	`
+	order, err := scanPurchaseOrder(r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT l.id, l.purchase_order_id, l.product_id, l.quantity, l.received_quantity, l.unit_cost, l.created_at,
+		       p.name, p.sku
+		FROM purchase_order_lines l
+		JOIN products p ON l.product_id = p.id
+		WHERE l.purchase_order_id = $1
+		ORDER BY l.created_at, l.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line models.PurchaseOrderLine
+		if err := rows.Scan(
+			&line.ID, &line.PurchaseOrderID, &line.ProductID, &line.Quantity, &line.ReceivedQuantity,
+			&line.UnitCost, &line.CreatedAt, &line.ProductName, &line.ProductSKU,
+		); err != nil {
+			return nil, err
+		}
+		order.Lines = append(order.Lines, line)
+	}
+
+	return order, rows.Err()
+}
+
+// Update saves a draft purchase order's header and replaces its lines
+func (r *purchaseOrderRepository) Update(ctx context.Context, order *models.PurchaseOrder) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenantID := utils.TenantID(ctx)
+	result, err := tx.ExecContext(ctx, `
+		UPDATE purchase_orders SET supplier_id = $1, expected_at = $2, notes = $3, updated_at = $4
+		WHERE id = $5 AND tenant_id = $6 AND status = $7
+	`, order.SupplierID, order.ExpectedAt, order.Notes, order.UpdatedAt, order.ID, tenantID, models.PurchaseOrderDraft)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrPurchaseOrderStatusChanged
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM purchase_order_lines WHERE purchase_order_id = $1`, order.ID); err != nil {
+		return err
+	}
+	if err := insertPurchaseOrderLines(ctx, tx, tenantID, order.Lines); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateStatus moves a purchase order from one status to another, recording when it was
+// ordered. It fails with ErrPurchaseOrderStatusChanged if the order is no longer in from.
+func (r *purchaseOrderRepository) UpdateStatus(ctx context.Context, id, from, to string, now time.Time) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE purchase_orders
+		SET status = $1::text,
+		    ordered_at = CASE WHEN $1::text = 'ordered' THEN $2 ELSE ordered_at END,
+		    updated_at = $2
+		WHERE id = $3 AND tenant_id = $4 AND status = $5
+	`, to, now, id, utils.TenantID(ctx), from)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrPurchaseOrderStatusChanged
+	}
+	return nil
+}
+
+// Receive books goods received against a purchase order in one database transaction: each
+// receipt adds to its line's received quantity and the product's stock (at the order's store,
+// if it has one), records a restock stock movement and folds the unit cost into the product's
+// moving-average cost price. The order becomes partially received, or received once nothing
+// is outstanding.
+func (r *purchaseOrderRepository) Receive(ctx context.Context, orderID string, receipts []models.GoodsReceipt, userID string, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenantID := utils.TenantID(ctx)
+	var status, poNumber string
+	var storeID sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT status, po_number, store_id FROM purchase_orders WHERE id = $1 AND tenant_id = $2 FOR UPDATE
+	`, orderID, tenantID).Scan(&status, &poNumber, &storeID)
+	if err != nil {
+		return err
+	}
+	if status != models.PurchaseOrderOrdered && status != models.PurchaseOrderPartiallyReceived {
+		return ErrPurchaseOrderNotReceivable
+	}
+
+	var createdBy *string
+	if userID != "" {
+		createdBy = &userID
+	}
+
+	for _, receipt := range receipts {
+		var productID string
+		err := tx.QueryRowContext(ctx, `
+			UPDATE purchase_order_lines SET received_quantity = received_quantity + $1::int
+			WHERE id = $2 AND purchase_order_id = $3 AND received_quantity + $1::int <= quantity
+			RETURNING product_id
+		`, receipt.Quantity, receipt.LineID, orderID).Scan(&productID)
+		if err == sql.ErrNoRows {
+			return ErrOverReceipt
+		}
+		if err != nil {
+			return err
+		}
+
+		// Stock on hand before this receipt, in every store, keeps its cost; negative stock is
+		// not averaged
+		_, err = tx.ExecContext(ctx, `
+			UPDATE products p
+			SET cost_price = CASE
+			        WHEN o.on_hand > 0 THEN ROUND((o.on_hand * p.cost_price + $1::int * $2::numeric) / (o.on_hand + $1::int), 2)
+			        ELSE $2::numeric
+			    END,
+			    updated_at = $3
+			FROM (
+			    SELECT GREATEST(stock, 0) + COALESCE((
+			        SELECT SUM(GREATEST(sp.stock, 0)) FROM store_products sp WHERE sp.product_id = $4
+			    ), 0) AS on_hand
+			    FROM products WHERE id = $4
+			) o
+			WHERE p.id = $4
+		`, receipt.Quantity, receipt.UnitCost, now, productID)
+		if err != nil {
+			return err
+		}
+
+		var balance int
+		if storeID.Valid {
+			err = tx.QueryRowContext(ctx, `
+				INSERT INTO store_products (tenant_id, store_id, product_id, stock, updated_at)
+				VALUES ($1, $2, $3, $4, $5)
+				ON CONFLICT (store_id, product_id) DO UPDATE
+				SET stock = store_products.stock + EXCLUDED.stock, updated_at = EXCLUDED.updated_at
+				RETURNING stock
+			`, tenantID, storeID.String, productID, receipt.Quantity, now).Scan(&balance)
+		} else {
+			err = tx.QueryRowContext(ctx, `
+				UPDATE products SET stock = stock + $1 WHERE id = $2 RETURNING stock
+			`, receipt.Quantity, productID).Scan(&balance)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_movements (id, tenant_id, product_id, store_id, type, quantity, balance_after, reference_id, note, created_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`, uuid.New().String(), tenantID, productID, storeID, models.MovementRestock, receipt.Quantity, balance, orderID, poNumber, createdBy, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	var outstanding int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(quantity - received_quantity), 0) FROM purchase_order_lines WHERE purchase_order_id = $1
+	`, orderID).Scan(&outstanding)
+	if err != nil {
+		return err
+	}
+
+	status = models.PurchaseOrderPartiallyReceived
+	var receivedAt *time.Time
+	if outstanding == 0 {
+		status = models.PurchaseOrderReceived
+		receivedAt = &now
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE purchase_orders SET status = $1, received_at = $2, updated_at = $3 WHERE id = $4
+	`, status, receivedAt, now, orderID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// purchaseOrderSortFields are the sort keys accepted by purchase order listings
+var purchaseOrderSortFields = utils.SortFields{
+	"po_number":   "po.po_number",
+	"status":      "po.status",
+	"supplier":    "s.name",
+	"expected_at": "po.expected_at",
+	"ordered_at":  "po.ordered_at",
+	"created_at":  "po.created_at",
+	"updated_at":  "po.updated_at",
+}
+
+func (r *purchaseOrderRepository) List(ctx context.Context, filter dto.PurchaseOrderListFilter, pagination utils.Pagination) ([]*models.PurchaseOrder, int, error) {
+	orderBy, err := pagination.OrderClause(purchaseOrderSortFields, "po.id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	conditions := []string{"po.tenant_id = $1"}
+	args := []interface{}{utils.TenantID(ctx)}
+	argIndex := 2
+
+	if filter.StoreID != "" {
+		conditions = append(conditions, fmt.Sprintf("po.store_id = $%d", argIndex))
+		args = append(args, filter.StoreID)
+		argIndex++
+	}
+	if filter.SupplierID != "" {
+		conditions = append(conditions, fmt.Sprintf("po.supplier_id = $%d", argIndex))
+		args = append(args, filter.SupplierID)
+		argIndex++
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("po.status = $%d", argIndex))
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
+	// Get total count
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM purchase_orders po %s`, whereClause)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM purchase_orders po
+		JOIN suppliers s ON po.supplier_id = s.id
+		%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, purchaseOrderColumns, whereClause, orderBy, argIndex, argIndex+1)
+
+	args = append(args, pagination.Limit(), pagination.Offset())
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var orders []*models.PurchaseOrder
+	for rows.Next() {
+		order, err := scanPurchaseOrder(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, total, rows.Err()
+}
+
+// GetOutstandingBySupplier summarises ordered and partially received purchase orders per
+// supplier, largest outstanding value first. Orders expected before now are overdue. An empty
+// storeID covers every store.
+func (r *purchaseOrderRepository) GetOutstandingBySupplier(ctx context.Context, storeID string, now time.Time) ([]dto.OutstandingPurchaseReport, error) {
+	query := `
+		SELECT s.id, s.name,
+		       COUNT(DISTINCT po.id),
+		       COUNT(DISTINCT po.id) FILTER (WHERE po.expected_at < $1),
+		       COALESCE(SUM(l.quantity - l.received_quantity), 0),
+		       COALESCE(SUM((l.quantity - l.received_quantity) * l.unit_cost), 0),
+		       MIN(po.ordered_at)
+		FROM suppliers s
+		JOIN purchase_orders po ON po.supplier_id = s.id
+		JOIN purchase_order_lines l ON l.purchase_order_id = po.id
+		WHERE po.tenant_id = $3 AND po.status IN ('ordered', 'partially_received')
+		  AND ($2 = '' OR po.store_id = $2)
+		GROUP BY s.id, s.name
+		ORDER BY 6 DESC, s.name
+	`
+	rows, err := r.db.QueryContext(ctx, query, now, storeID, utils.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []dto.OutstandingPurchaseReport
+	for rows.Next() {
+		var report dto.OutstandingPurchaseReport
+		var oldest sql.NullTime
+		if err := rows.Scan(
+			&report.SupplierID, &report.SupplierName, &report.OpenOrders, &report.OverdueOrders,
+			&report.OutstandingQuantity, &report.OutstandingValue, &oldest,
+		); err != nil {
+			return nil, err
+		}
+		if oldest.Valid {
+			report.OldestOrderedAt = &oldest.Time
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
+
+func insertPurchaseOrderLines(ctx context.Context, tx *sql.Tx, tenantID string, lines []models.PurchaseOrderLine) error {
+	query := `
+		INSERT INTO purchase_order_lines (id, tenant_id, purchase_order_id, product_id, quantity, received_quantity, unit_cost, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	for _, line := range lines {
+		if _, err := tx.ExecContext(ctx, query,
+			line.ID, tenantID, line.PurchaseOrderID, line.ProductID, line.Quantity, line.ReceivedQuantity,
+			line.UnitCost, line.CreatedAt,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
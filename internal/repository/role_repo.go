@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+type roleRepository struct {
+	db *sql.DB
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(db *sql.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+const roleColumns = `id, tenant_id, name, COALESCE(description, ''), COALESCE(require_mfa, FALSE), created_at, updated_at`
+
+func scanRole(row interface{ Scan(...interface{}) error }) (*models.Role, error) {
+	role := &models.Role{Permissions: []string{}}
+	err := row.Scan(&role.ID, &role.TenantID, &role.Name, &role.Description, &role.RequireMFA, &role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (r *roleRepository) Create(ctx context.Context, role *models.Role) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenantID := utils.TenantID(ctx)
+	query := `
+		INSERT INTO roles (id, tenant_id, name, description, require_mfa, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	if _, err := tx.ExecContext(ctx, query,
+		role.ID, tenantID, role.Name, role.Description, role.RequireMFA, role.CreatedAt, role.UpdatedAt,
+	); err != nil {
+		return err
+	}
+	if err := insertRolePermissions(ctx, tx, tenantID, role); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	query := `SELECT ` + roleColumns + ` FROM roles WHERE name = $1 AND tenant_id = $2`
+	role, err := scanRole(r.db.QueryRowContext(ctx, query, name, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT permission FROM role_permissions WHERE role_id = $1 AND tenant_id = $2 ORDER BY permission`,
+		role.ID, utils.TenantID(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, err
+		}
+		role.Permissions = append(role.Permissions, permission)
+	}
+
+	return role, rows.Err()
+}
+
+// List returns the tenant's stored roles with their permissions
+func (r *roleRepository) List(ctx context.Context) ([]*models.Role, error) {
+	tenantID := utils.TenantID(ctx)
+	rows, err := r.db.QueryContext(ctx, `SELECT `+roleColumns+` FROM roles WHERE tenant_id = $1 ORDER BY name`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []*models.Role
+	byID := make(map[string]*models.Role)
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+		byID[role.ID] = role
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	permRows, err := r.db.QueryContext(ctx,
+		`SELECT role_id, permission FROM role_permissions WHERE tenant_id = $1 ORDER BY permission`, tenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer permRows.Close()
+
+	for permRows.Next() {
+		var roleID, permission string
+		if err := permRows.Scan(&roleID, &permission); err != nil {
+			return nil, err
+		}
+		if role, ok := byID[roleID]; ok {
+			role.Permissions = append(role.Permissions, permission)
+		}
+	}
+
+	return roles, permRows.Err()
+}
+
+// Update saves a role's description and two-factor requirement and replaces its permissions
+func (r *roleRepository) Update(ctx context.Context, role *models.Role) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenantID := utils.TenantID(ctx)
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE roles SET description = $1, require_mfa = $2, updated_at = $3 WHERE id = $4 AND tenant_id = $5`,
+		role.Description, role.RequireMFA, role.UpdatedAt, role.ID, tenantID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = $1 AND tenant_id = $2`, role.ID, tenantID); err != nil {
+		return err
+	}
+	if err := insertRolePermissions(ctx, tx, tenantID, role); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *roleRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM roles WHERE id = $1 AND tenant_id = $2`, id, utils.TenantID(ctx))
+	return err
+}
+
+// CountUsers counts the tenant's users holding a role, including deleted ones that can be restored
+func (r *roleRepository) CountUsers(ctx context.Context, name string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM users WHERE role = $1 AND tenant_id = $2`, name, utils.TenantID(ctx),
+	).Scan(&count)
+	return count, err
+}
+
+func insertRolePermissions(ctx context.Context, tx *sql.Tx, tenantID string, role *models.Role) error {
+	for _, permission := range role.Permissions {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO role_permissions (tenant_id, role_id, permission) VALUES ($1, $2, $3)`,
+			tenantID, role.ID, permission,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
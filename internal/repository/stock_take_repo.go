@@ -0,0 +1,435 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+var (
+	// ErrStockTakeNotOpen is returned when a stock take is no longer being counted
+	ErrStockTakeNotOpen = errors.New("stock take is no longer open for counting")
+	// ErrNotInStockTake is returned when a count is submitted for a product outside the stock take
+	ErrNotInStockTake = errors.New("product is not part of this stock take")
+	// ErrEmptyStockTake is returned when a stock take would have no products to count
+	ErrEmptyStockTake = errors.New("there are no products to count")
+)
+
+type stockTakeRepository struct {
+	db *sql.DB
+}
+
+// NewStockTakeRepository creates a new stock take repository
+func NewStockTakeRepository(db *sql.DB) StockTakeRepository {
+	return &stockTakeRepository{db: db}
+}
+
+const stockTakeColumns = `st.id, st.reference, st.store_id, st.category_id, st.status, COALESCE(st.notes, ''), COALESCE(st.created_by, ''),
+		       COALESCE(st.approved_by, ''), st.approved_at, st.created_at, st.updated_at, COALESCE(c.name, ''),
+		       totals.item_count, totals.counted_count, totals.variance_quantity, totals.variance_value`
+
+// stockTakeFrom joins each stock take with its category and count and variance totals
+const stockTakeFrom = `
+		FROM stock_takes st
+		LEFT JOIN categories c ON st.category_id = c.id
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS item_count,
+			       COUNT(i.counted_quantity) AS counted_count,
+			       COALESCE(SUM(i.counted_quantity - i.expected_stock), 0) AS variance_quantity,
+			       COALESCE(SUM((i.counted_quantity - i.expected_stock) * p.cost_price), 0) AS variance_value
+			FROM stock_take_items i
+			JOIN products p ON i.product_id = p.id
+			WHERE i.stock_take_id = st.id
+		) totals`
+
+func scanStockTake(row interface{ Scan(...interface{}) error }) (*models.StockTake, error) {
+	take := &models.StockTake{}
+	var storeID, categoryID sql.NullString
+	var approvedAt sql.NullTime
+	err := row.Scan(
+		&take.ID, &take.Reference, &storeID, &categoryID, &take.Status, &take.Notes, &take.CreatedBy,
+		&take.ApprovedBy, &approvedAt, &take.CreatedAt, &take.UpdatedAt, &take.CategoryName,
+		&take.ItemCount, &take.CountedCount, &take.VarianceQuantity, &take.VarianceValue,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if storeID.Valid {
+		take.StoreID = &storeID.String
+	}
+	if categoryID.Valid {
+		take.CategoryID = &categoryID.String
+	}
+	if approvedAt.Valid {
+		take.ApprovedAt = &approvedAt.Time
+	}
+	return take, nil
+}
+
+// Create opens a stock take and adds every product in its category, or in the store, with
+// the current system stock as the expected stock. A stock take of a store counts the store's
+// stock.
+func (r *stockTakeRepository) Create(ctx context.Context, take *models.StockTake) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var createdBy *string
+	if take.CreatedBy != "" {
+		createdBy = &take.CreatedBy
+	}
+	tenantID := utils.TenantID(ctx)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO stock_takes (id, tenant_id, reference, store_id, category_id, status, notes, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, take.ID, tenantID, take.Reference, take.StoreID, take.CategoryID, take.Status, take.Notes, createdBy, take.CreatedAt, take.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT p.id, CASE WHEN $2::text IS NULL THEN p.stock ELSE COALESCE(sp.stock, 0) END
+		FROM products p
+		LEFT JOIN store_products sp ON sp.product_id = p.id AND sp.store_id = $2::text
+		WHERE p.tenant_id = $3 AND p.deleted_at IS NULL AND ($1::text IS NULL OR p.category_id = $1::text)
+	`, take.CategoryID, take.StoreID, tenantID)
+	if err != nil {
+		return err
+	}
+	var items []models.StockTakeItem
+	for rows.Next() {
+		item := models.StockTakeItem{ID: uuid.New().String(), StockTakeID: take.ID}
+		if err := rows.Scan(&item.ProductID, &item.ExpectedStock); err != nil {
+			rows.Close()
+			return err
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return ErrEmptyStockTake
+	}
+
+	for _, item := range items {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO stock_take_items (id, tenant_id, stock_take_id, product_id, expected_stock)
+			VALUES ($1, $2, $3, $4, $5)
+		`, item.ID, tenantID, item.StockTakeID, item.ProductID, item.ExpectedStock); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *stockTakeRepository) GetByID(ctx context.Context, id string) (*models.StockTake, error) {
+	query := `SELECT ` + stockTakeColumns + stockTakeFrom + ` WHERE st.id = $1 AND st.tenant_id = $2`
+	take, err := scanStockTake(r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT i.id, i.stock_take_id, i.product_id, i.expected_stock, i.counted_quantity,
+		       COALESCE(i.counted_by, ''), i.counted_at, p.name, p.sku, p.cost_price
+		FROM stock_take_items i
+		JOIN products p ON i.product_id = p.id
+		WHERE i.stock_take_id = $1
+		ORDER BY p.name, i.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.StockTakeItem
+		var counted sql.NullInt64
+		var countedAt sql.NullTime
+		if err := rows.Scan(
+			&item.ID, &item.StockTakeID, &item.ProductID, &item.ExpectedStock, &counted,
+			&item.CountedBy, &countedAt, &item.ProductName, &item.ProductSKU, &item.CostPrice,
+		); err != nil {
+			return nil, err
+		}
+		if counted.Valid {
+			quantity := int(counted.Int64)
+			item.CountedQuantity = &quantity
+		}
+		if countedAt.Valid {
+			item.CountedAt = &countedAt.Time
+		}
+		take.Items = append(take.Items, item)
+	}
+
+	return take, rows.Err()
+}
+
+// HasOpen reports whether a stock take of the same store that overlaps the given category is
+// being counted. A whole-store stock take (nil category) overlaps every other.
+func (r *stockTakeRepository) HasOpen(ctx context.Context, storeID, categoryID *string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM stock_takes
+			WHERE tenant_id = $4 AND status = $1 AND store_id IS NOT DISTINCT FROM $3::text
+			  AND ($2::text IS NULL OR category_id IS NULL OR category_id = $2::text)
+		)
+	`, models.StockTakeCounting, categoryID, storeID, utils.TenantID(ctx)).Scan(&exists)
+	return exists, err
+}
+
+// SubmitCounts records counted quantities. Each count also refreshes the product's expected
+// stock to the system stock at that moment, so sales made while counting are not variance.
+// Devices may submit concurrently: the stock take is only share-locked, and approval waits
+// for submissions in flight.
+func (r *stockTakeRepository) SubmitCounts(ctx context.Context, id string, counts []models.StockCount, add bool, userID string, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	var storeID sql.NullString
+	err = tx.QueryRowContext(ctx, `SELECT status, store_id FROM stock_takes WHERE id = $1 AND tenant_id = $2 FOR SHARE`, id, utils.TenantID(ctx)).
+		Scan(&status, &storeID)
+	if err != nil {
+		return err
+	}
+	if status != models.StockTakeCounting {
+		return ErrStockTakeNotOpen
+	}
+
+	var countedBy *string
+	if userID != "" {
+		countedBy = &userID
+	}
+
+	for _, count := range counts {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE stock_take_items i
+			SET counted_quantity = CASE WHEN $1::boolean THEN COALESCE(i.counted_quantity, 0) + $2::int ELSE $2::int END,
+			    expected_stock = CASE WHEN $7::text IS NULL THEN p.stock ELSE COALESCE(sp.stock, 0) END,
+			    counted_by = $3,
+			    counted_at = $4
+			FROM products p
+			LEFT JOIN store_products sp ON sp.product_id = p.id AND sp.store_id = $7::text
+			WHERE p.id = i.product_id AND i.stock_take_id = $5 AND i.product_id = $6
+		`, add, count.Quantity, countedBy, now, id, count.ProductID, storeID)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return fmt.Errorf("%w: %s", ErrNotInStockTake, count.ProductID)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Approve closes a stock take in one database transaction. Each counted product's variance is
+// added to its current stock, which keeps sales made since the count, and recorded as an
+// adjustment stock movement. Products that were not counted are left unchanged.
+func (r *stockTakeRepository) Approve(ctx context.Context, id, userID string, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenantID := utils.TenantID(ctx)
+	var status, reference string
+	var storeID sql.NullString
+	err = tx.QueryRowContext(ctx, `SELECT status, reference, store_id FROM stock_takes WHERE id = $1 AND tenant_id = $2 FOR UPDATE`, id, tenantID).
+		Scan(&status, &reference, &storeID)
+	if err != nil {
+		return err
+	}
+	if status != models.StockTakeCounting {
+		return ErrStockTakeNotOpen
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT product_id, counted_quantity - expected_stock
+		FROM stock_take_items
+		WHERE stock_take_id = $1 AND counted_quantity IS NOT NULL AND counted_quantity <> expected_stock
+		ORDER BY product_id
+	`, id)
+	if err != nil {
+		return err
+	}
+	type variance struct {
+		productID string
+		quantity  int
+	}
+	var variances []variance
+	for rows.Next() {
+		var v variance
+		if err := rows.Scan(&v.productID, &v.quantity); err != nil {
+			rows.Close()
+			return err
+		}
+		variances = append(variances, v)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var createdBy *string
+	if userID != "" {
+		createdBy = &userID
+	}
+
+	for _, v := range variances {
+		var balance int
+		var err error
+		if storeID.Valid {
+			err = tx.QueryRowContext(ctx, `
+				INSERT INTO store_products (tenant_id, store_id, product_id, stock, updated_at)
+				VALUES ($5, $1, $2, GREATEST($3::int, 0), $4)
+				ON CONFLICT (store_id, product_id) DO UPDATE
+				SET stock = GREATEST(store_products.stock + $3::int, 0), updated_at = EXCLUDED.updated_at
+				RETURNING stock
+			`, storeID.String, v.productID, v.quantity, now, tenantID).Scan(&balance)
+		} else {
+			err = tx.QueryRowContext(ctx, `
+				UPDATE products SET stock = GREATEST(stock + $1, 0), updated_at = $2
+				WHERE id = $3
+				RETURNING stock
+			`, v.quantity, now, v.productID).Scan(&balance)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_movements (id, tenant_id, product_id, store_id, type, quantity, balance_after, reference_id, note, created_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`, uuid.New().String(), tenantID, v.productID, storeID, models.MovementAdjustment, v.quantity, balance, id, reference, createdBy, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE stock_takes SET status = $1, approved_by = $2, approved_at = $3, updated_at = $3 WHERE id = $4
+	`, models.StockTakeApproved, createdBy, now, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Cancel abandons a stock take that is being counted without changing stock
+func (r *stockTakeRepository) Cancel(ctx context.Context, id string, now time.Time) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE stock_takes SET status = $1, updated_at = $2 WHERE id = $3 AND tenant_id = $4 AND status = $5
+	`, models.StockTakeCancelled, now, id, utils.TenantID(ctx), models.StockTakeCounting)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrStockTakeNotOpen
+	}
+	return nil
+}
+
+// stockTakeSortFields are the sort keys accepted by stock take listings
+var stockTakeSortFields = utils.SortFields{
+	"reference":   "st.reference",
+	"status":      "st.status",
+	"category":    "c.name",
+	"approved_at": "st.approved_at",
+	"created_at":  "st.created_at",
+	"updated_at":  "st.updated_at",
+}
+
+func (r *stockTakeRepository) List(ctx context.Context, filter dto.StockTakeListFilter, pagination utils.Pagination) ([]*models.StockTake, int, error) {
+	orderBy, err := pagination.OrderClause(stockTakeSortFields, "st.id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	conditions := []string{"st.tenant_id = $1"}
+	args := []interface{}{utils.TenantID(ctx)}
+	argIndex := 2
+
+	if filter.StoreID != "" {
+		conditions = append(conditions, fmt.Sprintf("st.store_id = $%d", argIndex))
+		args = append(args, filter.StoreID)
+		argIndex++
+	}
+	if filter.CategoryID != "" {
+		conditions = append(conditions, fmt.Sprintf("st.category_id = $%d", argIndex))
+		args = append(args, filter.CategoryID)
+		argIndex++
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("st.status = $%d", argIndex))
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
+	// Get total count
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM stock_takes st %s`, whereClause)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	query := fmt.Sprintf(`
+		SELECT %s
+		%s
+		%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, stockTakeColumns, stockTakeFrom, whereClause, orderBy, argIndex, argIndex+1)
+
+	args = append(args, pagination.Limit(), pagination.Offset())
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var takes []*models.StockTake
+	for rows.Next() {
+		take, err := scanStockTake(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		takes = append(takes, take)
+	}
+
+	return takes, total, rows.Err()
+}
@@ -0,0 +1,436 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+var (
+	// ErrTransferNotRequested is returned when a transfer has already been shipped or cancelled
+	ErrTransferNotRequested = errors.New("stock transfer has already been shipped or cancelled")
+	// ErrTransferNotInTransit is returned when a transfer that is not on its way is received
+	ErrTransferNotInTransit = errors.New("stock transfer is not in transit")
+	// ErrInsufficientStoreStock is returned when the source store has too little stock to ship
+	ErrInsufficientStoreStock = errors.New("not enough stock at the source store")
+)
+
+type stockTransferRepository struct {
+	db *sql.DB
+}
+
+// NewStockTransferRepository creates a new stock transfer repository
+func NewStockTransferRepository(db *sql.DB) StockTransferRepository {
+	return &stockTransferRepository{db: db}
+}
+
+const stockTransferColumns = `t.id, t.reference, t.from_store_id, t.to_store_id, t.status, COALESCE(t.notes, ''),
+		       COALESCE(t.requested_by, ''), COALESCE(t.shipped_by, ''), t.shipped_at, COALESCE(t.received_by, ''),
+		       t.received_at, t.created_at, t.updated_at, fs.name, ts.name,
+		       totals.item_count, totals.requested_quantity, totals.shipped_quantity, totals.received_quantity,
+		       totals.discrepancy_quantity, totals.shipped_value`
+
+// stockTransferFrom joins each transfer with its store names and quantity totals
+const stockTransferFrom = `
+		FROM stock_transfers t
+		JOIN stores fs ON t.from_store_id = fs.id
+		JOIN stores ts ON t.to_store_id = ts.id
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS item_count,
+			       COALESCE(SUM(i.requested_quantity), 0) AS requested_quantity,
+			       COALESCE(SUM(i.shipped_quantity), 0) AS shipped_quantity,
+			       COALESCE(SUM(i.received_quantity), 0) AS received_quantity,
+			       COALESCE(SUM(i.received_quantity - i.shipped_quantity), 0) AS discrepancy_quantity,
+			       COALESCE(SUM(i.shipped_quantity * i.unit_cost), 0) AS shipped_value
+			FROM stock_transfer_items i
+			WHERE i.transfer_id = t.id
+		) totals`
+
+func scanStockTransfer(row interface{ Scan(...interface{}) error }) (*models.StockTransfer, error) {
+	transfer := &models.StockTransfer{}
+	var shippedAt, receivedAt sql.NullTime
+	err := row.Scan(
+		&transfer.ID, &transfer.Reference, &transfer.FromStoreID, &transfer.ToStoreID, &transfer.Status, &transfer.Notes,
+		&transfer.RequestedBy, &transfer.ShippedBy, &shippedAt, &transfer.ReceivedBy,
+		&receivedAt, &transfer.CreatedAt, &transfer.UpdatedAt, &transfer.FromStoreName, &transfer.ToStoreName,
+		&transfer.ItemCount, &transfer.RequestedQuantity, &transfer.ShippedQuantity, &transfer.ReceivedQuantity,
+		&transfer.DiscrepancyQuantity, &transfer.ShippedValue,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if shippedAt.Valid {
+		transfer.ShippedAt = &shippedAt.Time
+	}
+	if receivedAt.Valid {
+		transfer.ReceivedAt = &receivedAt.Time
+	}
+	return transfer, nil
+}
+
+// Create records a requested transfer with its products
+func (r *stockTransferRepository) Create(ctx context.Context, transfer *models.StockTransfer) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var requestedBy *string
+	if transfer.RequestedBy != "" {
+		requestedBy = &transfer.RequestedBy
+	}
+	tenantID := utils.TenantID(ctx)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO stock_transfers (id, tenant_id, reference, from_store_id, to_store_id, status, notes, requested_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, transfer.ID, tenantID, transfer.Reference, transfer.FromStoreID, transfer.ToStoreID, transfer.Status, transfer.Notes,
+		requestedBy, transfer.CreatedAt, transfer.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range transfer.Items {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO stock_transfer_items (id, tenant_id, transfer_id, product_id, requested_quantity)
+			VALUES ($1, $2, $3, $4, $5)
+		`, item.ID, tenantID, transfer.ID, item.ProductID, item.RequestedQuantity); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *stockTransferRepository) GetByID(ctx context.Context, id string) (*models.StockTransfer, error) {
+	query := `SELECT ` + stockTransferColumns + stockTransferFrom + ` WHERE t.id = $1 AND t.tenant_id = $2`
+	transfer, err := scanStockTransfer(r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT i.id, i.transfer_id, i.product_id, i.requested_quantity, i.shipped_quantity,
+		       i.received_quantity, i.unit_cost, p.name, p.sku
+		FROM stock_transfer_items i
+		JOIN products p ON i.product_id = p.id
+		WHERE i.transfer_id = $1
+		ORDER BY p.name, i.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.StockTransferItem
+		var shipped, received sql.NullInt64
+		if err := rows.Scan(
+			&item.ID, &item.TransferID, &item.ProductID, &item.RequestedQuantity, &shipped,
+			&received, &item.UnitCost, &item.ProductName, &item.ProductSKU,
+		); err != nil {
+			return nil, err
+		}
+		if shipped.Valid {
+			quantity := int(shipped.Int64)
+			item.ShippedQuantity = &quantity
+		}
+		if received.Valid {
+			quantity := int(received.Int64)
+			item.ReceivedQuantity = &quantity
+		}
+		transfer.Items = append(transfer.Items, item)
+	}
+
+	return transfer, rows.Err()
+}
+
+// Ship takes the shipped quantities out of the source store's stock in one database
+// transaction, records transfer_out stock movements and puts the transfer in transit. Each
+// item keeps the product's cost price at the time so stock in transit can be valued.
+func (r *stockTransferRepository) Ship(ctx context.Context, id string, quantities []models.TransferQuantity, userID string, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenantID := utils.TenantID(ctx)
+	var status, reference, storeID string
+	err = tx.QueryRowContext(ctx, `SELECT status, reference, from_store_id FROM stock_transfers WHERE id = $1 AND tenant_id = $2 FOR UPDATE`, id, tenantID).
+		Scan(&status, &reference, &storeID)
+	if err != nil {
+		return err
+	}
+	if status != models.TransferRequested {
+		return ErrTransferNotRequested
+	}
+
+	var shippedBy *string
+	if userID != "" {
+		shippedBy = &userID
+	}
+
+	for _, q := range quantities {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE stock_transfer_items i SET shipped_quantity = $1, unit_cost = p.cost_price
+			FROM products p
+			WHERE p.id = i.product_id AND i.transfer_id = $2 AND i.product_id = $3
+		`, q.Quantity, id, q.ProductID); err != nil {
+			return err
+		}
+		if q.Quantity == 0 {
+			continue
+		}
+
+		var balance int
+		err := tx.QueryRowContext(ctx, `
+			UPDATE store_products SET stock = stock - $1, updated_at = $2
+			WHERE store_id = $3 AND product_id = $4 AND stock >= $1
+			RETURNING stock
+		`, q.Quantity, now, storeID, q.ProductID).Scan(&balance)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: %s", ErrInsufficientStoreStock, q.ProductID)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_movements (id, tenant_id, product_id, store_id, type, quantity, balance_after, reference_id, note, created_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`, uuid.New().String(), tenantID, q.ProductID, storeID, models.MovementTransferOut, -q.Quantity, balance, id, reference, shippedBy, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE stock_transfers SET status = $1, shipped_by = $2, shipped_at = $3, updated_at = $3 WHERE id = $4
+	`, models.TransferInTransit, shippedBy, now, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Receive adds the received quantities to the destination store's stock in one database
+// transaction, records transfer_in stock movements and closes the transfer. Quantities below
+// what was shipped stay on the items as discrepancies; the service rejects higher ones.
+func (r *stockTransferRepository) Receive(ctx context.Context, id string, quantities []models.TransferQuantity, userID string, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenantID := utils.TenantID(ctx)
+	var status, reference, storeID string
+	err = tx.QueryRowContext(ctx, `SELECT status, reference, to_store_id FROM stock_transfers WHERE id = $1 AND tenant_id = $2 FOR UPDATE`, id, tenantID).
+		Scan(&status, &reference, &storeID)
+	if err != nil {
+		return err
+	}
+	if status != models.TransferInTransit {
+		return ErrTransferNotInTransit
+	}
+
+	var receivedBy *string
+	if userID != "" {
+		receivedBy = &userID
+	}
+
+	for _, q := range quantities {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE stock_transfer_items SET received_quantity = $1 WHERE transfer_id = $2 AND product_id = $3
+		`, q.Quantity, id, q.ProductID); err != nil {
+			return err
+		}
+		if q.Quantity == 0 {
+			continue
+		}
+
+		var balance int
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO store_products (tenant_id, store_id, product_id, stock, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (store_id, product_id) DO UPDATE
+			SET stock = store_products.stock + EXCLUDED.stock, updated_at = EXCLUDED.updated_at
+			RETURNING stock
+		`, tenantID, storeID, q.ProductID, q.Quantity, now).Scan(&balance)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_movements (id, tenant_id, product_id, store_id, type, quantity, balance_after, reference_id, note, created_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`, uuid.New().String(), tenantID, q.ProductID, storeID, models.MovementTransferIn, q.Quantity, balance, id, reference, receivedBy, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE stock_transfers SET status = $1, received_by = $2, received_at = $3, updated_at = $3 WHERE id = $4
+	`, models.TransferReceived, receivedBy, now, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Cancel withdraws a transfer that has not been shipped
+func (r *stockTransferRepository) Cancel(ctx context.Context, id string, now time.Time) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE stock_transfers SET status = $1, updated_at = $2 WHERE id = $3 AND tenant_id = $4 AND status = $5
+	`, models.TransferCancelled, now, id, utils.TenantID(ctx), models.TransferRequested)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrTransferNotRequested
+	}
+	return nil
+}
+
+// stockTransferSortFields are the sort keys accepted by stock transfer listings
+var stockTransferSortFields = utils.SortFields{
+	"reference":   "t.reference",
+	"status":      "t.status",
+	"from_store":  "fs.name",
+	"to_store":    "ts.name",
+	"shipped_at":  "t.shipped_at",
+	"received_at": "t.received_at",
+	"created_at":  "t.created_at",
+	"updated_at":  "t.updated_at",
+}
+
+func (r *stockTransferRepository) List(ctx context.Context, filter dto.StockTransferListFilter, pagination utils.Pagination) ([]*models.StockTransfer, int, error) {
+	orderBy, err := pagination.OrderClause(stockTransferSortFields, "t.id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	conditions := []string{"t.tenant_id = $1"}
+	args := []interface{}{utils.TenantID(ctx)}
+	argIndex := 2
+
+	if filter.StoreID != "" {
+		conditions = append(conditions, fmt.Sprintf("(t.from_store_id = $%d OR t.to_store_id = $%d)", argIndex, argIndex))
+		args = append(args, filter.StoreID)
+		argIndex++
+	}
+	if filter.FromStoreID != "" {
+		conditions = append(conditions, fmt.Sprintf("t.from_store_id = $%d", argIndex))
+		args = append(args, filter.FromStoreID)
+		argIndex++
+	}
+	if filter.ToStoreID != "" {
+		conditions = append(conditions, fmt.Sprintf("t.to_store_id = $%d", argIndex))
+		args = append(args, filter.ToStoreID)
+		argIndex++
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("t.status = $%d", argIndex))
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
+	// Get total count
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM stock_transfers t %s`, whereClause)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	query := fmt.Sprintf(`
+		SELECT %s
+		%s
+		%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, stockTransferColumns, stockTransferFrom, whereClause, orderBy, argIndex, argIndex+1)
+
+	args = append(args, pagination.Limit(), pagination.Offset())
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var transfers []*models.StockTransfer
+	for rows.Next() {
+		transfer, err := scanStockTransfer(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, total, rows.Err()
+}
+
+// GetInTransit summarises transfers that are on their way per pair of stores, valued at the
+// cost when shipped, largest value first. An empty storeID covers every store; otherwise
+// transfers out of and into the store are included.
+func (r *stockTransferRepository) GetInTransit(ctx context.Context, storeID string) ([]dto.InTransitTransferReport, error) {
+	query := `
+		SELECT t.from_store_id, fs.name, t.to_store_id, ts.name,
+		       COUNT(DISTINCT t.id),
+		       COALESCE(SUM(i.shipped_quantity), 0),
+		       COALESCE(SUM(i.shipped_quantity * i.unit_cost), 0),
+		       MIN(t.shipped_at)
+		FROM stock_transfers t
+		JOIN stores fs ON t.from_store_id = fs.id
+		JOIN stores ts ON t.to_store_id = ts.id
+		JOIN stock_transfer_items i ON i.transfer_id = t.id
+		WHERE t.tenant_id = $3 AND t.status = $1
+		  AND ($2 = '' OR t.from_store_id = $2 OR t.to_store_id = $2)
+		GROUP BY t.from_store_id, fs.name, t.to_store_id, ts.name
+		ORDER BY 7 DESC, fs.name, ts.name
+	`
+	rows, err := r.db.QueryContext(ctx, query, models.TransferInTransit, storeID, utils.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []dto.InTransitTransferReport
+	for rows.Next() {
+		var report dto.InTransitTransferReport
+		var oldest sql.NullTime
+		if err := rows.Scan(
+			&report.FromStoreID, &report.FromStoreName, &report.ToStoreID, &report.ToStoreName,
+			&report.Transfers, &report.Quantity, &report.Value, &oldest,
+		); err != nil {
+			return nil, err
+		}
+		if oldest.Valid {
+			report.OldestShippedAt = &oldest.Time
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
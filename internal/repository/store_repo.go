@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+type storeRepository struct {
+	db *sql.DB
+}
+
+// NewStoreRepository creates a new store repository
+func NewStoreRepository(db *sql.DB) StoreRepository {
+	return &storeRepository{db: db}
+}
+
+const storeColumns = `id, code, name, COALESCE(address, ''), COALESCE(phone, ''), is_active, created_at, updated_at`
+
+func scanStore(row interface{ Scan(...interface{}) error }) (*models.Store, error) {
+	store := &models.Store{}
+	err := row.Scan(
+		&store.ID, &store.Code, &store.Name, &store.Address, &store.Phone,
+		&store.IsActive, &store.CreatedAt, &store.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (r *storeRepository) Create(ctx context.Context, store *models.Store) error {
+	query := `
+		INSERT INTO stores (id, tenant_id, code, name, address, phone, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		store.ID, utils.TenantID(ctx), store.Code, store.Name, store.Address, store.Phone,
+		store.IsActive, store.CreatedAt, store.UpdatedAt,
+	)
+	return err
+}
+
+func (r *storeRepository) GetByID(ctx context.Context, id string) (*models.Store, error) {
+	query := `SELECT ` + storeColumns + ` FROM stores WHERE id = $1 AND tenant_id = $2`
+	store, err := scanStore(r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return store, err
+}
+
+func (r *storeRepository) GetByCode(ctx context.Context, code string) (*models.Store, error) {
+	query := `SELECT ` + storeColumns + ` FROM stores WHERE code = $1 AND tenant_id = $2`
+	store, err := scanStore(r.db.QueryRowContext(ctx, query, code, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return store, err
+}
+
+func (r *storeRepository) Update(ctx context.Context, store *models.Store) error {
+	query := `
+		UPDATE stores SET name = $1, address = $2, phone = $3, is_active = $4, updated_at = $5
+		WHERE id = $6 AND tenant_id = $7
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		store.Name, store.Address, store.Phone, store.IsActive, store.UpdatedAt, store.ID, utils.TenantID(ctx),
+	)
+	return err
+}
+
+// CountActive counts the tenant's active stores
+func (r *storeRepository) CountActive(ctx context.Context) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM stores WHERE tenant_id = $1 AND is_active = TRUE`
+	err := r.db.QueryRowContext(ctx, query, utils.TenantID(ctx)).Scan(&count)
+	return count, err
+}
+
+// storeSortFields are the sort keys accepted by store listings
+var storeSortFields = utils.SortFields{
+	"code":       "code",
+	"name":       "name",
+	"created_at": "created_at",
+}
+
+func (r *storeRepository) List(ctx context.Context, filter dto.StoreListFilter, pagination utils.Pagination) ([]*models.Store, int, error) {
+	orderBy, err := pagination.OrderClause(storeSortFields, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	conditions := []string{"tenant_id = $1"}
+	args := []interface{}{utils.TenantID(ctx)}
+	argIndex := 2
+
+	if filter.Search != "" {
+		conditions = append(conditions, fmt.Sprintf("(name ILIKE $%d OR code ILIKE $%d)", argIndex, argIndex))
+		args = append(args, "%"+filter.Search+"%")
+		argIndex++
+	}
+	if filter.IsActive != nil {
+		conditions = append(conditions, fmt.Sprintf("is_active = $%d", argIndex))
+		args = append(args, *filter.IsActive)
+		argIndex++
+	}
+	if filter.UserID != "" {
+		conditions = append(conditions, fmt.Sprintf("id IN (SELECT store_id FROM user_stores WHERE user_id = $%d)", argIndex))
+		args = append(args, filter.UserID)
+		argIndex++
+	}
+
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
+	// Get total count
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM stores %s`, whereClause)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM stores
+		%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, storeColumns, whereClause, orderBy, argIndex, argIndex+1)
+
+	args = append(args, pagination.Limit(), pagination.Offset())
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var stores []*models.Store
+	for rows.Next() {
+		store, err := scanStore(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		stores = append(stores, store)
+	}
+
+	return stores, total, rows.Err()
+}
+
+// ListUserStores returns the stores a user is assigned to, ordered by code
+func (r *storeRepository) ListUserStores(ctx context.Context, userID string) ([]*models.Store, error) {
+	query := `
+		SELECT s.id, s.code, s.name, COALESCE(s.address, ''), COALESCE(s.phone, ''), s.is_active, s.created_at, s.updated_at
+		FROM stores s
+		JOIN user_stores us ON us.store_id = s.id
+		WHERE us.user_id = $1 AND s.tenant_id = $2
+		ORDER BY s.code
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, utils.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stores []*models.Store
+	for rows.Next() {
+		store, err := scanStore(rows)
+		if err != nil {
+			return nil, err
+		}
+		stores = append(stores, store)
+	}
+	return stores, rows.Err()
+}
+
+// SetUserStores replaces the stores a user is assigned to
+func (r *storeRepository) SetUserStores(ctx context.Context, userID string, storeIDs []string, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenantID := utils.TenantID(ctx)
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_stores WHERE user_id = $1 AND tenant_id = $2`, userID, tenantID); err != nil {
+		return err
+	}
+	for _, storeID := range storeIDs {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO user_stores (tenant_id, user_id, store_id, created_at) VALUES ($1, $2, $3, $4)`,
+			tenantID, userID, storeID, now,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SetPrices sets or removes store price overrides, keeping the store's stock levels
+func (r *storeRepository) SetPrices(ctx context.Context, storeID string, prices []models.StorePrice, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO store_products (tenant_id, store_id, product_id, stock, price, updated_at)
+		VALUES ($1, $2, $3, 0, $4, $5)
+		ON CONFLICT (store_id, product_id) DO UPDATE SET price = EXCLUDED.price, updated_at = EXCLUDED.updated_at
+	`
+	for _, price := range prices {
+		if _, err := tx.ExecContext(ctx, query, utils.TenantID(ctx), storeID, price.ProductID, price.Price, now); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+type supplierRepository struct {
+	db *sql.DB
+}
+
+// NewSupplierRepository creates a new supplier repository
+func NewSupplierRepository(db *sql.DB) SupplierRepository {
+	return &supplierRepository{db: db}
+}
+
+const supplierColumns = `id, name, COALESCE(contact_name, ''), COALESCE(email, ''), COALESCE(phone, ''),
+		       COALESCE(address, ''), is_active, created_at, updated_at`
+
+func scanSupplier(row interface{ Scan(...interface{}) error }) (*models.Supplier, error) {
+	supplier := &models.Supplier{}
+	err := row.Scan(
+		&supplier.ID, &supplier.Name, &supplier.ContactName, &supplier.Email, &supplier.Phone,
+		&supplier.Address, &supplier.IsActive, &supplier.CreatedAt, &supplier.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return supplier, nil
+}
+
+func (r *supplierRepository) Create(ctx context.Context, supplier *models.Supplier) error {
+	query := `
+		INSERT INTO suppliers (id, tenant_id, name, contact_name, email, phone, address, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		supplier.ID, utils.TenantID(ctx), supplier.Name, supplier.ContactName, supplier.Email, supplier.Phone,
+		supplier.Address, supplier.IsActive, supplier.CreatedAt, supplier.UpdatedAt,
+	)
+	return err
+}
+
+func (r *supplierRepository) GetByID(ctx context.Context, id string) (*models.Supplier, error) {
+	query := `SELECT ` + supplierColumns + ` FROM suppliers WHERE id = $1 AND tenant_id = $2`
+	supplier, err := scanSupplier(r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return supplier, err
+}
+
+func (r *supplierRepository) Update(ctx context.Context, supplier *models.Supplier) error {
+	query := `
+		UPDATE suppliers SET name = $1, contact_name = $2, email = $3, phone = $4, address = $5,
+		       is_active = $6, updated_at = $7
+		WHERE id = $8 AND tenant_id = $9
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		supplier.Name, supplier.ContactName, supplier.Email, supplier.Phone, supplier.Address,
+		supplier.IsActive, supplier.UpdatedAt, supplier.ID, utils.TenantID(ctx),
+	)
+	return err
+}
+
+func (r *supplierRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM suppliers WHERE id = $1 AND tenant_id = $2`, id, utils.TenantID(ctx))
+	return err
+}
+
+func (r *supplierRepository) CountPurchaseOrders(ctx context.Context, id string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM purchase_orders WHERE supplier_id = $1 AND tenant_id = $2`
+	err := r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)).Scan(&count)
+	return count, err
+}
+
+// supplierSortFields are the sort keys accepted by supplier listings
+var supplierSortFields = utils.SortFields{
+	"name":       "name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+func (r *supplierRepository) List(ctx context.Context, filter dto.SupplierListFilter, pagination utils.Pagination) ([]*models.Supplier, int, error) {
+	orderBy, err := pagination.OrderClause(supplierSortFields, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	conditions := []string{"tenant_id = $1"}
+	args := []interface{}{utils.TenantID(ctx)}
+	argIndex := 2
+
+	if filter.Search != "" {
+		conditions = append(conditions, fmt.Sprintf("(name ILIKE $%d OR contact_name ILIKE $%d)", argIndex, argIndex))
+		args = append(args, "%"+filter.Search+"%")
+		argIndex++
+	}
+	if filter.IsActive != nil {
+		conditions = append(conditions, fmt.Sprintf("is_active = $%d", argIndex))
+		args = append(args, *filter.IsActive)
+		argIndex++
+	}
+
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
+	// Get total count
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM suppliers %s`, whereClause)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM suppliers
+		%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, supplierColumns, whereClause, orderBy, argIndex, argIndex+1)
+
+	args = append(args, pagination.Limit(), pagination.Offset())
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var suppliers []*models.Supplier
+	for rows.Next() {
+		supplier, err := scanSupplier(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		suppliers = append(suppliers, supplier)
+	}
+
+	return suppliers, total, rows.Err()
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ilramdhan/pos-api/internal/models"
+)
+
+type tenantRepository struct {
+	db *sql.DB
+}
+
+// NewTenantRepository creates a new tenant repository
+func NewTenantRepository(db *sql.DB) TenantRepository {
+	return &tenantRepository{db: db}
+}
+
+// Create creates a tenant and its first admin in a single database transaction
+func (r *tenantRepository) Create(ctx context.Context, tenant *models.Tenant, admin *models.User) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO tenants (id, name, license_key, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	if _, err := tx.ExecContext(ctx, query,
+		tenant.ID, tenant.Name, tenant.LicenseKey, tenant.IsActive, tenant.CreatedAt, tenant.UpdatedAt,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, insertUserQuery,
+		admin.ID, tenant.ID, admin.Email, admin.PasswordHash, admin.Name, admin.Phone, admin.Role,
+		admin.IsActive, admin.CreatedAt, admin.UpdatedAt,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *tenantRepository) GetByID(ctx context.Context, id string) (*models.Tenant, error) {
+	tenant := &models.Tenant{}
+	query := `SELECT id, name, COALESCE(license_key, ''), is_active, created_at, updated_at FROM tenants WHERE id = $1`
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&tenant.ID, &tenant.Name, &tenant.LicenseKey, &tenant.IsActive, &tenant.CreatedAt, &tenant.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+func (r *tenantRepository) UpdateLicense(ctx context.Context, id, licenseKey string, now time.Time) error {
+	query := `UPDATE tenants SET license_key = $1, updated_at = $2 WHERE id = $3`
+	_, err := r.db.ExecContext(ctx, query, licenseKey, now, id)
+	return err
+}
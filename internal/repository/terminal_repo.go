@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+type terminalRepository struct {
+	db *sql.DB
+}
+
+// NewTerminalRepository creates a new terminal repository
+func NewTerminalRepository(db *sql.DB) TerminalRepository {
+	return &terminalRepository{db: db}
+}
+
+const terminalColumns = `id, tenant_id, store_id, name, device_type, is_active, last_used_at,
+	COALESCE(app_version, ''), latency_ms, last_seen_at, created_at, updated_at`
+
+func scanTerminal(row interface{ Scan(...interface{}) error }) (*models.Terminal, error) {
+	terminal := &models.Terminal{}
+	var lastUsedAt, lastSeenAt sql.NullTime
+	var latency sql.NullInt64
+	err := row.Scan(
+		&terminal.ID, &terminal.TenantID, &terminal.StoreID, &terminal.Name, &terminal.Type, &terminal.IsActive,
+		&lastUsedAt, &terminal.AppVersion, &latency, &lastSeenAt, &terminal.CreatedAt, &terminal.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		terminal.LastUsedAt = &lastUsedAt.Time
+	}
+	if latency.Valid {
+		ms := int(latency.Int64)
+		terminal.LatencyMS = &ms
+	}
+	if lastSeenAt.Valid {
+		terminal.LastSeenAt = &lastSeenAt.Time
+	}
+	return terminal, nil
+}
+
+func (r *terminalRepository) Create(ctx context.Context, terminal *models.Terminal, keyHash string) error {
+	query := `
+		INSERT INTO terminals (id, tenant_id, store_id, name, device_type, key_hash, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		terminal.ID, utils.TenantID(ctx), terminal.StoreID, terminal.Name, terminal.Type, keyHash, terminal.IsActive,
+		terminal.CreatedAt, terminal.UpdatedAt,
+	)
+	return err
+}
+
+func (r *terminalRepository) GetByID(ctx context.Context, id string) (*models.Terminal, error) {
+	query := `SELECT ` + terminalColumns + ` FROM terminals WHERE id = $1 AND tenant_id = $2`
+	terminal, err := scanTerminal(r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return terminal, err
+}
+
+// GetByKeyHash finds a terminal of any tenant by its key; terminals identify themselves with
+// the key before anyone has signed in
+func (r *terminalRepository) GetByKeyHash(ctx context.Context, keyHash string) (*models.Terminal, error) {
+	query := `SELECT ` + terminalColumns + ` FROM terminals WHERE key_hash = $1`
+	terminal, err := scanTerminal(r.db.QueryRowContext(ctx, query, keyHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return terminal, err
+}
+
+func (r *terminalRepository) Update(ctx context.Context, terminal *models.Terminal) error {
+	query := `UPDATE terminals SET name = $1, is_active = $2, updated_at = $3 WHERE id = $4 AND tenant_id = $5`
+	_, err := r.db.ExecContext(ctx, query,
+		terminal.Name, terminal.IsActive, terminal.UpdatedAt, terminal.ID, utils.TenantID(ctx),
+	)
+	return err
+}
+
+// SetKeyHash replaces the terminal's key, signing out the device that held the old one
+func (r *terminalRepository) SetKeyHash(ctx context.Context, id, keyHash string, now time.Time) error {
+	query := `UPDATE terminals SET key_hash = $1, updated_at = $2 WHERE id = $3 AND tenant_id = $4`
+	_, err := r.db.ExecContext(ctx, query, keyHash, now, id, utils.TenantID(ctx))
+	return err
+}
+
+// SetLastUsed records a PIN sign-in on the terminal
+func (r *terminalRepository) SetLastUsed(ctx context.Context, id string, now time.Time) error {
+	query := `UPDATE terminals SET last_used_at = $1 WHERE id = $2 AND tenant_id = $3`
+	_, err := r.db.ExecContext(ctx, query, now, id, utils.TenantID(ctx))
+	return err
+}
+
+// SetHeartbeat records a heartbeat from the terminal. A heartbeat without a latency keeps the
+// last one reported.
+func (r *terminalRepository) SetHeartbeat(ctx context.Context, id, appVersion string, latencyMS *int, now time.Time) error {
+	query := `
+		UPDATE terminals SET app_version = $1, latency_ms = COALESCE($2, latency_ms), last_seen_at = $3
+		WHERE id = $4 AND tenant_id = $5
+	`
+	_, err := r.db.ExecContext(ctx, query, appVersion, latencyMS, now, id, utils.TenantID(ctx))
+	return err
+}
+
+func (r *terminalRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM terminals WHERE id = $1 AND tenant_id = $2`
+	_, err := r.db.ExecContext(ctx, query, id, utils.TenantID(ctx))
+	return err
+}
+
+// List lists the tenant's terminals, optionally only those of one store
+func (r *terminalRepository) List(ctx context.Context, storeID string) ([]*models.Terminal, error) {
+	query := `SELECT ` + terminalColumns + ` FROM terminals WHERE tenant_id = $1 AND ($2 = '' OR store_id = $2) ORDER BY name`
+	rows, err := r.db.QueryContext(ctx, query, utils.TenantID(ctx), storeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	terminals := []*models.Terminal{}
+	for rows.Next() {
+		terminal, err := scanTerminal(rows)
+		if err != nil {
+			return nil, err
+		}
+		terminals = append(terminals, terminal)
+	}
+	return terminals, rows.Err()
+}
@@ -3,14 +3,19 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ilramdhan/pos-api/internal/dto"
 	"github.com/ilramdhan/pos-api/internal/models"
 	"github.com/ilramdhan/pos-api/internal/utils"
 )
 
+// ErrTransactionStatusChanged is returned when a transaction's status was changed by another request
+var ErrTransactionStatusChanged = errors.New("transaction status was changed by another request")
+
 type transactionRepository struct {
 	db *sql.DB
 }
@@ -28,34 +33,78 @@ func (r *transactionRepository) Create(ctx context.Context, transaction *models.
 	defer tx.Rollback()
 
 	// Insert transaction
+	tenantID := utils.TenantID(ctx)
 	query := `
-		INSERT INTO transactions (id, user_id, customer_id, invoice_number, subtotal, tax_amount, 
-		                         discount_amount, total_amount, payment_method, status, notes, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		INSERT INTO transactions (id, tenant_id, user_id, customer_id, invoice_number, subtotal, tax_amount, 
+		                         discount_amount, total_amount, payment_method, status, notes, voucher_code,
+		                         points_earned, points_redeemed, store_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 	`
 	_, err = tx.ExecContext(ctx, query,
-		transaction.ID, transaction.UserID, transaction.CustomerID, transaction.InvoiceNumber,
+		transaction.ID, tenantID, transaction.UserID, transaction.CustomerID, transaction.InvoiceNumber,
 		transaction.Subtotal, transaction.TaxAmount, transaction.DiscountAmount, transaction.TotalAmount,
-		transaction.PaymentMethod, transaction.Status, transaction.Notes,
-		transaction.CreatedAt, transaction.UpdatedAt,
+		transaction.PaymentMethod, transaction.Status, transaction.Notes, transaction.VoucherCode,
+		transaction.PointsEarned, transaction.PointsRedeemed, transaction.StoreID, transaction.CreatedAt, transaction.UpdatedAt,
 	)
 	if err != nil {
 		return err
 	}
 
+	// Use up manager approvals in the same database transaction
+	for _, use := range transaction.Overrides {
+		if err := useOverride(ctx, tx, use, transaction.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	// Redeem voucher in the same database transaction
+	if transaction.Redemption != nil {
+		if err := redeemVoucher(ctx, tx, transaction.Redemption); err != nil {
+			return err
+		}
+	}
+
+	// Post loyalty redemptions and earnings in the same database transaction
+	for _, entry := range transaction.LoyaltyEntries {
+		if err := postLoyaltyEntry(ctx, tx, entry); err != nil {
+			return err
+		}
+	}
+
+	// Take product, variant and recipe ingredient stock in the same database transaction
+	for _, movement := range transaction.StockMovements {
+		if err := postStockMovement(ctx, tx, movement); err != nil {
+			return err
+		}
+	}
+
 	// Insert transaction items
 	itemQuery := `
-		INSERT INTO transaction_items (id, transaction_id, product_id, product_name, unit_price, quantity, subtotal, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO transaction_items (id, tenant_id, transaction_id, product_id, product_name, variant_id, variant_name,
+		                               unit_price, quantity, subtotal, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	modifierQuery := `
+		INSERT INTO transaction_item_modifiers (id, tenant_id, transaction_item_id, modifier_id, group_name, name, price_delta)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 	for _, item := range transaction.Items {
 		_, err = tx.ExecContext(ctx, itemQuery,
-			item.ID, item.TransactionID, item.ProductID, item.ProductName,
+			item.ID, tenantID, item.TransactionID, item.ProductID, item.ProductName, item.VariantID, item.VariantName,
 			item.UnitPrice, item.Quantity, item.Subtotal, item.CreatedAt,
 		)
 		if err != nil {
 			return err
 		}
+
+		for _, modifier := range item.Modifiers {
+			_, err = tx.ExecContext(ctx, modifierQuery,
+				modifier.ID, tenantID, item.ID, modifier.ModifierID, modifier.GroupName, modifier.Name, modifier.PriceDelta,
+			)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return tx.Commit()
@@ -64,22 +113,24 @@ func (r *transactionRepository) Create(ctx context.Context, transaction *models.
 func (r *transactionRepository) GetByID(ctx context.Context, id string) (*models.Transaction, error) {
 	query := `
 		SELECT t.id, t.user_id, t.customer_id, t.invoice_number, t.subtotal, t.tax_amount,
-		       t.discount_amount, t.total_amount, t.payment_method, t.status, t.notes, t.created_at, t.updated_at,
+		       t.discount_amount, t.total_amount, t.payment_method, t.status, t.notes, COALESCE(t.voucher_code, ''),
+		       t.points_earned, t.points_redeemed, t.store_id, t.created_at, t.updated_at,
 		       u.id, u.email, u.name, u.role, u.is_active
 		FROM transactions t
 		LEFT JOIN users u ON t.user_id = u.id
-		WHERE t.id = $1
+		WHERE t.id = $1 AND t.tenant_id = $2
 	`
 
 	transaction := &models.Transaction{}
 	user := &models.User{}
-	var customerID sql.NullString
+	var customerID, storeID sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)).Scan(
 		&transaction.ID, &transaction.UserID, &customerID, &transaction.InvoiceNumber,
 		&transaction.Subtotal, &transaction.TaxAmount, &transaction.DiscountAmount,
 		&transaction.TotalAmount, &transaction.PaymentMethod, &transaction.Status,
-		&transaction.Notes, &transaction.CreatedAt, &transaction.UpdatedAt,
+		&transaction.Notes, &transaction.VoucherCode,
+		&transaction.PointsEarned, &transaction.PointsRedeemed, &storeID, &transaction.CreatedAt, &transaction.UpdatedAt,
 		&user.ID, &user.Email, &user.Name, &user.Role, &user.IsActive,
 	)
 	if err == sql.ErrNoRows {
@@ -92,11 +143,15 @@ func (r *transactionRepository) GetByID(ctx context.Context, id string) (*models
 	if customerID.Valid {
 		transaction.CustomerID = &customerID.String
 	}
+	if storeID.Valid {
+		transaction.StoreID = &storeID.String
+	}
 	transaction.User = user
 
 	// Get transaction items
 	itemQuery := `
-		SELECT id, transaction_id, product_id, product_name, unit_price, quantity, subtotal, created_at
+		SELECT id, transaction_id, product_id, product_name, variant_id, COALESCE(variant_name, ''),
+		       unit_price, quantity, subtotal, created_at
 		FROM transaction_items WHERE transaction_id = $1
 	`
 	rows, err := r.db.QueryContext(ctx, itemQuery, id)
@@ -107,32 +162,71 @@ func (r *transactionRepository) GetByID(ctx context.Context, id string) (*models
 
 	for rows.Next() {
 		item := models.TransactionItem{}
+		var variantID sql.NullString
 		if err := rows.Scan(
-			&item.ID, &item.TransactionID, &item.ProductID, &item.ProductName,
+			&item.ID, &item.TransactionID, &item.ProductID, &item.ProductName, &variantID, &item.VariantName,
 			&item.UnitPrice, &item.Quantity, &item.Subtotal, &item.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
+		if variantID.Valid {
+			item.VariantID = &variantID.String
+		}
 		transaction.Items = append(transaction.Items, item)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get the modifiers chosen for each item
+	modifierQuery := `
+		SELECT m.id, m.transaction_item_id, m.modifier_id, m.group_name, m.name, m.price_delta
+		FROM transaction_item_modifiers m
+		JOIN transaction_items ti ON m.transaction_item_id = ti.id
+		WHERE ti.transaction_id = $1
+	`
+	modifierRows, err := r.db.QueryContext(ctx, modifierQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	defer modifierRows.Close()
+
+	itemIndex := make(map[string]int, len(transaction.Items))
+	for i, item := range transaction.Items {
+		itemIndex[item.ID] = i
+	}
+	for modifierRows.Next() {
+		var modifier models.TransactionItemModifier
+		if err := modifierRows.Scan(
+			&modifier.ID, &modifier.TransactionItemID, &modifier.ModifierID, &modifier.GroupName,
+			&modifier.Name, &modifier.PriceDelta,
+		); err != nil {
+			return nil, err
+		}
+		if i, ok := itemIndex[modifier.TransactionItemID]; ok {
+			transaction.Items[i].Modifiers = append(transaction.Items[i].Modifiers, modifier)
+		}
+	}
 
-	return transaction, rows.Err()
+	return transaction, modifierRows.Err()
 }
 
 func (r *transactionRepository) GetByInvoiceNumber(ctx context.Context, invoiceNumber string) (*models.Transaction, error) {
 	query := `
 		SELECT id, user_id, customer_id, invoice_number, subtotal, tax_amount,
-		       discount_amount, total_amount, payment_method, status, notes, created_at, updated_at
-		FROM transactions WHERE invoice_number = $1
+		       discount_amount, total_amount, payment_method, status, notes, COALESCE(voucher_code, ''),
+		       points_earned, points_redeemed, store_id, created_at, updated_at
+		FROM transactions WHERE invoice_number = $1 AND tenant_id = $2
 	`
 	transaction := &models.Transaction{}
-	var customerID sql.NullString
+	var customerID, storeID sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, invoiceNumber).Scan(
+	err := r.db.QueryRowContext(ctx, query, invoiceNumber, utils.TenantID(ctx)).Scan(
 		&transaction.ID, &transaction.UserID, &customerID, &transaction.InvoiceNumber,
 		&transaction.Subtotal, &transaction.TaxAmount, &transaction.DiscountAmount,
 		&transaction.TotalAmount, &transaction.PaymentMethod, &transaction.Status,
-		&transaction.Notes, &transaction.CreatedAt, &transaction.UpdatedAt,
+		&transaction.Notes, &transaction.VoucherCode,
+		&transaction.PointsEarned, &transaction.PointsRedeemed, &storeID, &transaction.CreatedAt, &transaction.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -140,20 +234,82 @@ func (r *transactionRepository) GetByInvoiceNumber(ctx context.Context, invoiceN
 	if customerID.Valid {
 		transaction.CustomerID = &customerID.String
 	}
+	if storeID.Valid {
+		transaction.StoreID = &storeID.String
+	}
 	return transaction, err
 }
 
-func (r *transactionRepository) UpdateStatus(ctx context.Context, id string, status string) error {
-	query := `UPDATE transactions SET status = $1 WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, status, id)
-	return err
+// UpdateStatus moves a transaction from the status it was read with to a new one, and uses up
+// its approvals and applies its loyalty entries, stock movements and variant stock changes in
+// the same database transaction. It returns ErrTransactionStatusChanged if another request changed the status
+// first, so reversals are applied once.
+func (r *transactionRepository) UpdateStatus(ctx context.Context, transaction *models.Transaction, status string, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE transactions SET status = $1, updated_at = $2 WHERE id = $3 AND tenant_id = $4 AND status = $5
+	`, status, now, transaction.ID, utils.TenantID(ctx), transaction.Status)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrTransactionStatusChanged
+	}
+
+	for _, use := range transaction.Overrides {
+		if err := useOverride(ctx, tx, use, now); err != nil {
+			return err
+		}
+	}
+	for _, entry := range transaction.LoyaltyEntries {
+		if err := postLoyaltyEntry(ctx, tx, entry); err != nil {
+			return err
+		}
+	}
+	for _, movement := range transaction.StockMovements {
+		if err := postStockMovement(ctx, tx, movement); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// transactionSortFields are the sort keys accepted by transaction listings
+var transactionSortFields = utils.SortFields{
+	"invoice_number": "t.invoice_number",
+	"subtotal":       "t.subtotal",
+	"total_amount":   "t.total_amount",
+	"payment_method": "t.payment_method",
+	"status":         "t.status",
+	"created_at":     "t.created_at",
+	"updated_at":     "t.updated_at",
 }
 
 func (r *transactionRepository) List(ctx context.Context, filter dto.TransactionListFilter, pagination utils.Pagination) ([]*models.Transaction, int, error) {
-	var conditions []string
-	var args []interface{}
-	argIndex := 1
+	orderBy, err := pagination.OrderClause(transactionSortFields, "t.id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	conditions := []string{"t.tenant_id = $1"}
+	args := []interface{}{utils.TenantID(ctx)}
+	argIndex := 2
 
+	if filter.StoreID != "" {
+		conditions = append(conditions, fmt.Sprintf("t.store_id = $%d", argIndex))
+		args = append(args, filter.StoreID)
+		argIndex++
+	}
 	if filter.UserID != "" {
 		conditions = append(conditions, fmt.Sprintf("t.user_id = $%d", argIndex))
 		args = append(args, filter.UserID)
@@ -185,27 +341,36 @@ func (r *transactionRepository) List(ctx context.Context, filter dto.Transaction
 		argIndex++
 	}
 
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
-	}
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
 
 	// Get total count
-	var total int
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM transactions t %s`, whereClause)
-	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+	total, err := countRows(ctx, r.db, "FROM transactions t "+whereClause, args, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Continue after the cursor, if any; the total covers the whole filtered list
+	keyset, keysetArgs, err := keysetCondition(pagination, "t.created_at", "t.id", argIndex)
+	if err != nil {
 		return nil, 0, err
 	}
+	if keyset != "" {
+		conditions = append(conditions, keyset)
+		args = append(args, keysetArgs...)
+		argIndex += len(keysetArgs)
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
 
 	// Get paginated results
 	query := fmt.Sprintf(`
 		SELECT t.id, t.user_id, t.customer_id, t.invoice_number, t.subtotal, t.tax_amount,
-		       t.discount_amount, t.total_amount, t.payment_method, t.status, t.notes, t.created_at, t.updated_at
+		       t.discount_amount, t.total_amount, t.payment_method, t.status, t.notes, COALESCE(t.voucher_code, ''),
+		       t.points_earned, t.points_redeemed, t.store_id, t.created_at, t.updated_at
 		FROM transactions t
 		%s
-		ORDER BY t.%s
+		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, pagination.OrderBy(), argIndex, argIndex+1)
+	`, whereClause, orderBy, argIndex, argIndex+1)
 
 	args = append(args, pagination.Limit(), pagination.Offset())
 	rows, err := r.db.QueryContext(ctx, query, args...)
@@ -217,25 +382,29 @@ func (r *transactionRepository) List(ctx context.Context, filter dto.Transaction
 	var transactions []*models.Transaction
 	for rows.Next() {
 		transaction := &models.Transaction{}
-		var customerID sql.NullString
+		var customerID, storeID sql.NullString
 		if err := rows.Scan(
 			&transaction.ID, &transaction.UserID, &customerID, &transaction.InvoiceNumber,
 			&transaction.Subtotal, &transaction.TaxAmount, &transaction.DiscountAmount,
 			&transaction.TotalAmount, &transaction.PaymentMethod, &transaction.Status,
-			&transaction.Notes, &transaction.CreatedAt, &transaction.UpdatedAt,
+			&transaction.Notes, &transaction.VoucherCode,
+			&transaction.PointsEarned, &transaction.PointsRedeemed, &storeID, &transaction.CreatedAt, &transaction.UpdatedAt,
 		); err != nil {
 			return nil, 0, err
 		}
 		if customerID.Valid {
 			transaction.CustomerID = &customerID.String
 		}
+		if storeID.Valid {
+			transaction.StoreID = &storeID.String
+		}
 		transactions = append(transactions, transaction)
 	}
 
 	return transactions, total, rows.Err()
 }
 
-func (r *transactionRepository) GetDailySales(ctx context.Context, dateFrom, dateTo string) ([]dto.DailySalesReport, error) {
+func (r *transactionRepository) GetDailySales(ctx context.Context, storeID, dateFrom, dateTo string) ([]dto.DailySalesReport, error) {
 	query := `
 		SELECT DATE(t.created_at) as date, 
 		       COUNT(*) as total_transactions,
@@ -245,11 +414,13 @@ func (r *transactionRepository) GetDailySales(ctx context.Context, dateFrom, dat
 		LEFT JOIN transaction_items ti ON t.id = ti.transaction_id
 		WHERE t.status = 'completed'
 		  AND DATE(t.created_at) >= $1 AND DATE(t.created_at) <= $2
+		  AND ($3 = '' OR t.store_id = $3)
+		  AND t.tenant_id = $4
 		GROUP BY DATE(t.created_at)
 		ORDER BY date DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, dateFrom, dateTo)
+	rows, err := r.db.QueryContext(ctx, query, dateFrom, dateTo, storeID, utils.TenantID(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -271,7 +442,7 @@ func (r *transactionRepository) GetDailySales(ctx context.Context, dateFrom, dat
 	return reports, rows.Err()
 }
 
-func (r *transactionRepository) GetMonthlySales(ctx context.Context, dateFrom, dateTo string) ([]dto.MonthlySalesReport, error) {
+func (r *transactionRepository) GetMonthlySales(ctx context.Context, storeID, dateFrom, dateTo string) ([]dto.MonthlySalesReport, error) {
 	query := `
 		SELECT TO_CHAR(t.created_at, 'YYYY-MM') as month,
 		       COUNT(*) as total_transactions,
@@ -281,11 +452,13 @@ func (r *transactionRepository) GetMonthlySales(ctx context.Context, dateFrom, d
 		LEFT JOIN transaction_items ti ON t.id = ti.transaction_id
 		WHERE t.status = 'completed'
 		  AND DATE(t.created_at) >= $1 AND DATE(t.created_at) <= $2
+		  AND ($3 = '' OR t.store_id = $3)
+		  AND t.tenant_id = $4
 		GROUP BY TO_CHAR(t.created_at, 'YYYY-MM')
 		ORDER BY month DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, dateFrom, dateTo)
+	rows, err := r.db.QueryContext(ctx, query, dateFrom, dateTo, storeID, utils.TenantID(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -307,7 +480,7 @@ func (r *transactionRepository) GetMonthlySales(ctx context.Context, dateFrom, d
 	return reports, rows.Err()
 }
 
-func (r *transactionRepository) GetTopProducts(ctx context.Context, limit int, dateFrom, dateTo string) ([]dto.TopProductReport, error) {
+func (r *transactionRepository) GetTopProducts(ctx context.Context, storeID string, limit int, dateFrom, dateTo string) ([]dto.TopProductReport, error) {
 	query := `
 		SELECT ti.product_id, ti.product_name, p.sku,
 		       SUM(ti.quantity) as total_sold,
@@ -317,12 +490,14 @@ func (r *transactionRepository) GetTopProducts(ctx context.Context, limit int, d
 		LEFT JOIN products p ON ti.product_id = p.id
 		WHERE t.status = 'completed'
 		  AND DATE(t.created_at) >= $1 AND DATE(t.created_at) <= $2
+		  AND ($3 = '' OR t.store_id = $3)
+		  AND t.tenant_id = $5
 		GROUP BY ti.product_id, ti.product_name, p.sku
 		ORDER BY total_sold DESC
-		LIMIT $3
+		LIMIT $4
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, dateFrom, dateTo, limit)
+	rows, err := r.db.QueryContext(ctx, query, dateFrom, dateTo, storeID, limit, utils.TenantID(ctx))
 	if err != nil {
 		return nil, err
 	}
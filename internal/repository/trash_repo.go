@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+var (
+	// ErrNotInTrash is returned when restoring a record that does not exist or is not deleted
+	ErrNotInTrash = errors.New("record not found in trash")
+	// ErrRestoreConflict is returned when an active record already holds the restored record's unique key
+	ErrRestoreConflict = errors.New("an active record with the same unique key already exists")
+	// ErrRestoreCategoryDeleted is returned when restoring a product whose category is still in the trash
+	ErrRestoreCategoryDeleted = errors.New("product category is deleted; restore the category first")
+)
+
+// trashTable describes a soft-deletable table. uniqueColumn is the column that must stay unique
+// among a tenant's active rows, or among all active rows when globalUnique is set; it is empty
+// when the table has none.
+type trashTable struct {
+	table        string
+	uniqueColumn string
+	globalUnique bool
+}
+
+// trashTypes lists the soft-deletable entity types in listing order
+var trashTypes = []string{models.TrashProducts, models.TrashCategories, models.TrashCustomers, models.TrashUsers}
+
+var trashTables = map[string]trashTable{
+	models.TrashProducts:   {table: "products", uniqueColumn: "sku"},
+	models.TrashCategories: {table: "categories", uniqueColumn: "slug"},
+	models.TrashCustomers:  {table: "customers"},
+	models.TrashUsers:      {table: "users", uniqueColumn: "email", globalUnique: true},
+}
+
+// trashPurgeQueries permanently delete rows older than $1 that nothing references any more.
+// They cover every tenant; Purge narrows each one to the caller's tenant through alias.
+// Products go first so that their categories become purgeable in the same run; products,
+// customers and users that appear in sales, purchase, transfer, stock, loyalty or approval
+// history are kept so reports and audit trails stay intact.
+var trashPurgeQueries = []struct {
+	entityType string
+	alias      string
+	query      string
+}{
+	{models.TrashProducts, "p", `
+		DELETE FROM products p
+		WHERE p.deleted_at IS NOT NULL AND p.deleted_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM transaction_items ti WHERE ti.product_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM purchase_order_lines pl WHERE pl.product_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM stock_movements sm WHERE sm.product_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM stock_take_items sti WHERE sti.product_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM stock_transfer_items sfi WHERE sfi.product_id = p.id)`},
+	{models.TrashCategories, "c", `
+		DELETE FROM categories c
+		WHERE c.deleted_at IS NOT NULL AND c.deleted_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM products p WHERE p.category_id = c.id)`},
+	{models.TrashCustomers, "c", `
+		DELETE FROM customers c
+		WHERE c.deleted_at IS NOT NULL AND c.deleted_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.customer_id = c.id)
+		  AND NOT EXISTS (SELECT 1 FROM loyalty_ledger ll WHERE ll.customer_id = c.id)
+		  AND NOT EXISTS (SELECT 1 FROM customer_merges cm WHERE cm.target_customer_id = c.id)`},
+	{models.TrashUsers, "u", `
+		DELETE FROM users u
+		WHERE u.deleted_at IS NOT NULL AND u.deleted_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.user_id = u.id)
+		  AND NOT EXISTS (SELECT 1 FROM voucher_redemptions vr WHERE vr.user_id = u.id)
+		  AND NOT EXISTS (SELECT 1 FROM overrides o WHERE o.requested_by = u.id OR o.approved_by = u.id)`},
+}
+
+type trashRepository struct {
+	db *sql.DB
+}
+
+// NewTrashRepository creates a new trash repository
+func NewTrashRepository(db *sql.DB) TrashRepository {
+	return &trashRepository{db: db}
+}
+
+func (r *trashRepository) List(ctx context.Context, entityType string, pagination utils.Pagination) ([]*models.TrashItem, int, error) {
+	types := trashTypes
+	if entityType != "" {
+		types = []string{entityType}
+	}
+
+	var selects []string
+	for _, t := range types {
+		table, ok := trashTables[t]
+		if !ok {
+			return nil, 0, fmt.Errorf("unknown trash type %q", t)
+		}
+		selects = append(selects, fmt.Sprintf(
+			`SELECT id, '%s' AS type, name, deleted_at FROM %s WHERE tenant_id = $1 AND deleted_at IS NOT NULL`, t, table.table,
+		))
+	}
+	union := strings.Join(selects, " UNION ALL ")
+	tenantID := utils.TenantID(ctx)
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM (` + union + `) trash`
+	if err := r.db.QueryRowContext(ctx, countQuery, tenantID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT id, type, name, deleted_at FROM (` + union + `) trash
+		ORDER BY deleted_at DESC, id
+		LIMIT $2 OFFSET $3`
+	rows, err := r.db.QueryContext(ctx, query, tenantID, pagination.Limit(), pagination.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var items []*models.TrashItem
+	for rows.Next() {
+		item := &models.TrashItem{}
+		if err := rows.Scan(&item.ID, &item.Type, &item.Name, &item.DeletedAt); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+
+	return items, total, rows.Err()
+}
+
+func (r *trashRepository) Restore(ctx context.Context, entityType, id string) error {
+	table, ok := trashTables[entityType]
+	if !ok {
+		return fmt.Errorf("unknown trash type %q", entityType)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var deletedAt sql.NullTime
+	err = tx.QueryRowContext(ctx,
+		fmt.Sprintf(`SELECT deleted_at FROM %s WHERE id = $1 AND tenant_id = $2 FOR UPDATE`, table.table), id, utils.TenantID(ctx),
+	).Scan(&deletedAt)
+	if err == sql.ErrNoRows || (err == nil && !deletedAt.Valid) {
+		return ErrNotInTrash
+	}
+	if err != nil {
+		return err
+	}
+
+	if table.uniqueColumn != "" {
+		var conflict bool
+		scope := " AND a.tenant_id = d.tenant_id"
+		if table.globalUnique {
+			scope = ""
+		}
+		query := fmt.Sprintf(`
+			SELECT EXISTS (
+				SELECT 1 FROM %[1]s a JOIN %[1]s d ON a.%[2]s = d.%[2]s%[3]s
+				WHERE d.id = $1 AND a.id <> d.id AND a.deleted_at IS NULL
+			)
+		`, table.table, table.uniqueColumn, scope)
+		if err := tx.QueryRowContext(ctx, query, id).Scan(&conflict); err != nil {
+			return err
+		}
+		if conflict {
+			return ErrRestoreConflict
+		}
+	}
+
+	if entityType == models.TrashProducts {
+		var categoryActive bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT c.deleted_at IS NULL FROM products p
+			JOIN categories c ON p.category_id = c.id
+			WHERE p.id = $1
+		`, id).Scan(&categoryActive)
+		if err != nil {
+			return err
+		}
+		if !categoryActive {
+			return ErrRestoreCategoryDeleted
+		}
+	}
+
+	// Unique indexes the check above does not cover, such as customer contact details, still reject the row
+	query := fmt.Sprintf(`UPDATE %s SET deleted_at = NULL, updated_at = $1 WHERE id = $2`, table.table)
+	if _, err := tx.ExecContext(ctx, query, time.Now(), id); err != nil {
+		if isUniqueViolation(err) {
+			return ErrRestoreConflict
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Purge permanently removes the caller's tenant's purgeable records deleted before the cutoff
+func (r *trashRepository) Purge(ctx context.Context, before time.Time) (map[string]int, error) {
+	return r.purge(ctx, before, utils.TenantID(ctx))
+}
+
+// PurgeAllTenants permanently removes purgeable records deleted before the cutoff in every
+// tenant. It is meant for the retention job only.
+func (r *trashRepository) PurgeAllTenants(ctx context.Context, before time.Time) (map[string]int, error) {
+	return r.purge(ctx, before, "")
+}
+
+// purge runs the purge queries, limited to tenantID unless it is empty
+func (r *trashRepository) purge(ctx context.Context, before time.Time, tenantID string) (map[string]int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	purged := make(map[string]int, len(trashPurgeQueries))
+	for _, p := range trashPurgeQueries {
+		query, args := p.query, []interface{}{before}
+		if tenantID != "" {
+			query += fmt.Sprintf(" AND %s.tenant_id = $2", p.alias)
+			args = append(args, tenantID)
+		}
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		purged[p.entityType] = int(affected)
+	}
+
+	return purged, tx.Commit()
+}
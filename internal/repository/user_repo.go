@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/ilramdhan/pos-api/internal/models"
 	"github.com/ilramdhan/pos-api/internal/utils"
@@ -18,13 +19,35 @@ func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
+const userColumns = `id, tenant_id, email, password_hash, COALESCE(pin_hash, ''), COALESCE(totp_secret, ''),
+	COALESCE(totp_enabled, FALSE), name, COALESCE(phone, ''), role, is_active, created_at, updated_at`
+
+func scanUser(row interface{ Scan(...interface{}) error }) (*models.User, error) {
+	user := &models.User{}
+	err := row.Scan(
+		&user.ID, &user.TenantID, &user.Email, &user.PasswordHash, &user.PINHash, &user.TOTPSecret,
+		&user.TOTPEnabled, &user.Name, &user.Phone, &user.Role, &user.IsActive, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// insertUserQuery inserts a user; tenants use it for their first admin
+const insertUserQuery = `
+	INSERT INTO users (id, tenant_id, email, password_hash, name, phone, role, is_active, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+`
+
+// Create creates a user in the user's tenant, or in the tenant of ctx when the user has none
 func (r *userRepository) Create(ctx context.Context, user *models.User) error {
-	query := `
-		INSERT INTO users (id, email, password_hash, name, phone, role, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	`
-	_, err := r.db.ExecContext(ctx, query,
-		user.ID, user.Email, user.PasswordHash, user.Name, user.Phone, user.Role,
+	if user.TenantID == "" {
+		user.TenantID = utils.TenantID(ctx)
+	}
+
+	_, err := r.db.ExecContext(ctx, insertUserQuery,
+		user.ID, user.TenantID, user.Email, user.PasswordHash, user.Name, user.Phone, user.Role,
 		user.IsActive, user.CreatedAt, user.UpdatedAt,
 	)
 	return err
@@ -32,30 +55,24 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 
 func (r *userRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
 	query := `
-		SELECT id, email, password_hash, name, COALESCE(phone, '') as phone, role, is_active, created_at, updated_at
-		FROM users WHERE id = $1
+		SELECT ` + userColumns + `
+		FROM users WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
 	`
-	user := &models.User{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.Phone, &user.Role,
-		&user.IsActive, &user.CreatedAt, &user.UpdatedAt,
-	)
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	return user, err
 }
 
+// GetByEmail finds a user of any tenant; email addresses are unique across tenants so users
+// can sign in without naming their business
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT id, email, password_hash, name, COALESCE(phone, '') as phone, role, is_active, created_at, updated_at
-		FROM users WHERE email = $1
+		SELECT ` + userColumns + `
+		FROM users WHERE email = $1 AND deleted_at IS NULL
 	`
-	user := &models.User{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.Phone, &user.Role,
-		&user.IsActive, &user.CreatedAt, &user.UpdatedAt,
-	)
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, email))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -65,31 +82,119 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.
 func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 	query := `
 		UPDATE users SET email = $1, name = $2, phone = $3, role = $4, is_active = $5, updated_at = $6
-		WHERE id = $7
+		WHERE id = $7 AND tenant_id = $8
 	`
 	_, err := r.db.ExecContext(ctx, query,
-		user.Email, user.Name, user.Phone, user.Role, user.IsActive, user.UpdatedAt, user.ID,
+		user.Email, user.Name, user.Phone, user.Role, user.IsActive, user.UpdatedAt, user.ID, utils.TenantID(ctx),
 	)
 	return err
 }
 
+// SetPIN replaces the user's PIN hash; an empty hash removes the PIN
+func (r *userRepository) SetPIN(ctx context.Context, id, pinHash string, now time.Time) error {
+	query := `UPDATE users SET pin_hash = $1, updated_at = $2 WHERE id = $3 AND tenant_id = $4`
+	_, err := r.db.ExecContext(ctx, query, pinHash, now, id, utils.TenantID(ctx))
+	return err
+}
+
+// SetTOTP replaces the user's TOTP secret and whether two-factor authentication is on. Codes
+// already used stay used only while the secret is unchanged.
+func (r *userRepository) SetTOTP(ctx context.Context, id, secret string, enabled bool, now time.Time) error {
+	query := `
+		UPDATE users SET totp_secret = $1, totp_enabled = $2, totp_last_step = 0, updated_at = $3
+		WHERE id = $4 AND tenant_id = $5
+	`
+	_, err := r.db.ExecContext(ctx, query, secret, enabled, now, id, utils.TenantID(ctx))
+	return err
+}
+
+// UseTOTPStep records the time step of a TOTP code the user signed in with. It reports false
+// when a code of that step or a later one was already used, so codes cannot be replayed.
+func (r *userRepository) UseTOTPStep(ctx context.Context, id string, step int64) (bool, error) {
+	query := `
+		UPDATE users SET totp_last_step = $1
+		WHERE id = $2 AND tenant_id = $3 AND COALESCE(totp_last_step, 0) < $1
+	`
+	result, err := r.db.ExecContext(ctx, query, step, id, utils.TenantID(ctx))
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// ListPINUsers lists the active users with a PIN who may work at a store: those assigned to it
+// and those not limited to any store
+func (r *userRepository) ListPINUsers(ctx context.Context, storeID string) ([]*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE tenant_id = $1 AND deleted_at IS NULL AND is_active = TRUE AND COALESCE(pin_hash, '') <> ''
+		  AND (id IN (SELECT user_id FROM user_stores WHERE store_id = $2)
+		       OR id NOT IN (SELECT user_id FROM user_stores))
+		ORDER BY name
+	`
+	rows, err := r.db.QueryContext(ctx, query, utils.TenantID(ctx), storeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
 func (r *userRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM users WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query := `UPDATE users SET deleted_at = $1 WHERE id = $2 AND tenant_id = $3 AND deleted_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), id, utils.TenantID(ctx))
 	return err
 }
 
-func (r *userRepository) List(ctx context.Context, role string, pagination utils.Pagination) ([]*models.User, int, error) {
+// CountActive counts the tenant's active users, who each take a license seat
+func (r *userRepository) CountActive(ctx context.Context) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM users WHERE tenant_id = $1 AND is_active = TRUE AND deleted_at IS NULL`
+	err := r.db.QueryRowContext(ctx, query, utils.TenantID(ctx)).Scan(&count)
+	return count, err
+}
+
+// userSortFields are the sort keys accepted by user listings
+var userSortFields = utils.SortFields{
+	"name":       "name",
+	"email":      "email",
+	"role":       "role",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+func (r *userRepository) List(ctx context.Context, role, storeID string, pagination utils.Pagination) ([]*models.User, int, error) {
+	orderBy, err := pagination.OrderClause(userSortFields, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
 	// Build where clause
-	whereClause := ""
-	var args []interface{}
-	argIndex := 1
+	whereClause := "WHERE tenant_id = $1 AND deleted_at IS NULL"
+	args := []interface{}{utils.TenantID(ctx)}
+	argIndex := 2
 
 	if role != "" {
-		whereClause = fmt.Sprintf("WHERE role = $%d", argIndex)
+		whereClause += fmt.Sprintf(" AND role = $%d", argIndex)
 		args = append(args, role)
 		argIndex++
 	}
+	if storeID != "" {
+		whereClause += fmt.Sprintf(" AND id IN (SELECT user_id FROM user_stores WHERE store_id = $%d)", argIndex)
+		args = append(args, storeID)
+		argIndex++
+	}
 
 	// Get total count
 	var total int
@@ -100,11 +205,11 @@ func (r *userRepository) List(ctx context.Context, role string, pagination utils
 
 	// Get paginated results
 	query := fmt.Sprintf(`
-		SELECT id, email, password_hash, name, COALESCE(phone, '') as phone, role, is_active, created_at, updated_at
+		SELECT `+userColumns+`
 		FROM users %s
 		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, pagination.OrderBy(), argIndex, argIndex+1)
+	`, whereClause, orderBy, argIndex, argIndex+1)
 
 	args = append(args, pagination.Limit(), pagination.Offset())
 	rows, err := r.db.QueryContext(ctx, query, args...)
@@ -115,11 +220,8 @@ func (r *userRepository) List(ctx context.Context, role string, pagination utils
 
 	var users []*models.User
 	for rows.Next() {
-		user := &models.User{}
-		if err := rows.Scan(
-			&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.Phone, &user.Role,
-			&user.IsActive, &user.CreatedAt, &user.UpdatedAt,
-		); err != nil {
+		user, err := scanUser(rows)
+		if err != nil {
 			return nil, 0, err
 		}
 		users = append(users, user)
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+var (
+	// ErrVoucherUnavailable is returned when a voucher is inactive or fully used at redemption time
+	ErrVoucherUnavailable = errors.New("voucher is no longer available")
+	// ErrVoucherCustomerLimit is returned when a customer has used a voucher the maximum number of times
+	ErrVoucherCustomerLimit = errors.New("voucher usage limit reached for this customer")
+)
+
+type voucherRepository struct {
+	db *sql.DB
+}
+
+// NewVoucherRepository creates a new voucher repository
+func NewVoucherRepository(db *sql.DB) VoucherRepository {
+	return &voucherRepository{db: db}
+}
+
+const voucherColumns = `id, code, batch_id, discount_type, discount_value, max_discount, min_spend,
+		       max_uses, uses_per_customer, used_count, valid_from, expires_at, is_active,
+		       COALESCE(created_by, ''), created_at, updated_at`
+
+func scanVoucher(row interface{ Scan(...interface{}) error }) (*models.Voucher, error) {
+	voucher := &models.Voucher{}
+	var validFrom, expiresAt sql.NullTime
+	err := row.Scan(
+		&voucher.ID, &voucher.Code, &voucher.BatchID, &voucher.DiscountType, &voucher.DiscountValue,
+		&voucher.MaxDiscount, &voucher.MinSpend, &voucher.MaxUses, &voucher.UsesPerCustomer,
+		&voucher.UsedCount, &validFrom, &expiresAt, &voucher.IsActive,
+		&voucher.CreatedBy, &voucher.CreatedAt, &voucher.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if validFrom.Valid {
+		voucher.ValidFrom = &validFrom.Time
+	}
+	if expiresAt.Valid {
+		voucher.ExpiresAt = &expiresAt.Time
+	}
+	return voucher, nil
+}
+
+func (r *voucherRepository) CreateBatch(ctx context.Context, vouchers []*models.Voucher) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tenantID := utils.TenantID(ctx)
+	query := `
+		INSERT INTO vouchers (id, tenant_id, code, batch_id, discount_type, discount_value, max_discount, min_spend,
+		                      max_uses, uses_per_customer, used_count, valid_from, expires_at, is_active,
+		                      created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`
+	for _, v := range vouchers {
+		_, err = tx.ExecContext(ctx, query,
+			v.ID, tenantID, v.Code, v.BatchID, v.DiscountType, v.DiscountValue, v.MaxDiscount, v.MinSpend,
+			v.MaxUses, v.UsesPerCustomer, v.UsedCount, v.ValidFrom, v.ExpiresAt, v.IsActive,
+			v.CreatedBy, v.CreatedAt, v.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *voucherRepository) GetByID(ctx context.Context, id string) (*models.Voucher, error) {
+	query := `SELECT ` + voucherColumns + ` FROM vouchers WHERE id = $1 AND tenant_id = $2`
+	voucher, err := scanVoucher(r.db.QueryRowContext(ctx, query, id, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return voucher, err
+}
+
+func (r *voucherRepository) GetByCode(ctx context.Context, code string) (*models.Voucher, error) {
+	query := `SELECT ` + voucherColumns + ` FROM vouchers WHERE code = $1 AND tenant_id = $2`
+	voucher, err := scanVoucher(r.db.QueryRowContext(ctx, query, code, utils.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return voucher, err
+}
+
+func (r *voucherRepository) Update(ctx context.Context, voucher *models.Voucher) error {
+	query := `UPDATE vouchers SET is_active = $1, expires_at = $2, updated_at = $3 WHERE id = $4 AND tenant_id = $5`
+	_, err := r.db.ExecContext(ctx, query, voucher.IsActive, voucher.ExpiresAt, voucher.UpdatedAt, voucher.ID, utils.TenantID(ctx))
+	return err
+}
+
+func (r *voucherRepository) CountCustomerRedemptions(ctx context.Context, voucherID, customerID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM voucher_redemptions WHERE voucher_id = $1 AND customer_id = $2 AND tenant_id = $3`
+	err := r.db.QueryRowContext(ctx, query, voucherID, customerID, utils.TenantID(ctx)).Scan(&count)
+	return count, err
+}
+
+// voucherSortFields are the sort keys accepted by voucher listings
+var voucherSortFields = utils.SortFields{
+	"code":           "code",
+	"discount_value": "discount_value",
+	"used_count":     "used_count",
+	"valid_from":     "valid_from",
+	"expires_at":     "expires_at",
+	"created_at":     "created_at",
+	"updated_at":     "updated_at",
+}
+
+func (r *voucherRepository) List(ctx context.Context, filter dto.VoucherListFilter, pagination utils.Pagination) ([]*models.Voucher, int, error) {
+	orderBy, err := pagination.OrderClause(voucherSortFields, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	conditions := []string{"tenant_id = $1"}
+	args := []interface{}{utils.TenantID(ctx)}
+	argIndex := 2
+
+	if filter.BatchID != "" {
+		conditions = append(conditions, fmt.Sprintf("batch_id = $%d", argIndex))
+		args = append(args, filter.BatchID)
+		argIndex++
+	}
+	if filter.Search != "" {
+		conditions = append(conditions, fmt.Sprintf("code ILIKE $%d", argIndex))
+		args = append(args, "%"+filter.Search+"%")
+		argIndex++
+	}
+	if filter.IsActive != nil {
+		conditions = append(conditions, fmt.Sprintf("is_active = $%d", argIndex))
+		args = append(args, *filter.IsActive)
+		argIndex++
+	}
+
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
+	// Get total count
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM vouchers %s`, whereClause)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM vouchers
+		%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, voucherColumns, whereClause, orderBy, argIndex, argIndex+1)
+
+	args = append(args, pagination.Limit(), pagination.Offset())
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var vouchers []*models.Voucher
+	for rows.Next() {
+		voucher, err := scanVoucher(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		vouchers = append(vouchers, voucher)
+	}
+
+	return vouchers, total, rows.Err()
+}
+
+func (r *voucherRepository) ListRedemptions(ctx context.Context, voucherID string) ([]*models.VoucherRedemption, error) {
+	query := `
+		SELECT vr.id, vr.voucher_id, vr.transaction_id, vr.customer_id, vr.user_id, vr.discount_amount,
+		       vr.created_at, COALESCE(t.invoice_number, '')
+		FROM voucher_redemptions vr
+		LEFT JOIN transactions t ON vr.transaction_id = t.id
+		WHERE vr.voucher_id = $1 AND vr.tenant_id = $2
+		ORDER BY vr.created_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, voucherID, utils.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var redemptions []*models.VoucherRedemption
+	for rows.Next() {
+		redemption := &models.VoucherRedemption{}
+		var customerID sql.NullString
+		if err := rows.Scan(
+			&redemption.ID, &redemption.VoucherID, &redemption.TransactionID, &customerID,
+			&redemption.UserID, &redemption.DiscountAmount, &redemption.CreatedAt, &redemption.InvoiceNumber,
+		); err != nil {
+			return nil, err
+		}
+		if customerID.Valid {
+			redemption.CustomerID = &customerID.String
+		}
+		redemptions = append(redemptions, redemption)
+	}
+
+	return redemptions, rows.Err()
+}
+
+// redeemVoucher locks the voucher row and records the redemption inside the caller's
+// database transaction, so two checkouts cannot consume the same remaining use.
+func redeemVoucher(ctx context.Context, tx *sql.Tx, redemption *models.VoucherRedemption) error {
+	tenantID := utils.TenantID(ctx)
+	var maxUses, usedCount, usesPerCustomer int
+	var isActive bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT max_uses, used_count, uses_per_customer, is_active
+		FROM vouchers WHERE id = $1 AND tenant_id = $2
+		FOR UPDATE
+	`, redemption.VoucherID, tenantID).Scan(&maxUses, &usedCount, &usesPerCustomer, &isActive)
+	if err == sql.ErrNoRows {
+		return ErrVoucherUnavailable
+	}
+	if err != nil {
+		return err
+	}
+
+	if !isActive || (maxUses > 0 && usedCount >= maxUses) {
+		return ErrVoucherUnavailable
+	}
+
+	if usesPerCustomer > 0 && redemption.CustomerID != nil {
+		var customerUses int
+		err := tx.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM voucher_redemptions WHERE voucher_id = $1 AND customer_id = $2`,
+			redemption.VoucherID, *redemption.CustomerID,
+		).Scan(&customerUses)
+		if err != nil {
+			return err
+		}
+		if customerUses >= usesPerCustomer {
+			return ErrVoucherCustomerLimit
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE vouchers SET used_count = used_count + 1, updated_at = $1 WHERE id = $2`,
+		redemption.CreatedAt, redemption.VoucherID,
+	); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO voucher_redemptions (id, tenant_id, voucher_id, transaction_id, customer_id, user_id, discount_amount, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		redemption.ID, tenantID, redemption.VoucherID, redemption.TransactionID, redemption.CustomerID,
+		redemption.UserID, redemption.DiscountAmount, redemption.CreatedAt,
+	)
+	return err
+}
@@ -1,6 +1,7 @@
 package router
 
 import (
+	"log"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,6 +12,7 @@ import (
 	"github.com/ilramdhan/pos-api/internal/models"
 	"github.com/ilramdhan/pos-api/internal/repository"
 	"github.com/ilramdhan/pos-api/internal/service"
+	"github.com/ilramdhan/pos-api/internal/storage"
 	"github.com/ilramdhan/pos-api/internal/utils"
 )
 
@@ -21,7 +23,7 @@ type Router struct {
 }
 
 // New creates and configures a new router
-func New(cfg *config.Config, db *database.Database) *Router {
+func New(cfg *config.Config, db *database.Database, store storage.BlobStore) *Router {
 	// Set Gin mode
 	if cfg.IsProduction() {
 		gin.SetMode(gin.ReleaseMode)
@@ -29,6 +31,12 @@ func New(cfg *config.Config, db *database.Database) *Router {
 
 	engine := gin.New()
 
+	// Client IPs drive rate limits, IP lockouts and the sign-in audit log, so forwarded
+	// addresses are only believed from the configured proxies
+	if err := engine.SetTrustedProxies(cfg.App.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Global middleware
 	engine.Use(gin.Recovery())
 	engine.Use(middleware.LoggerMiddleware())
@@ -40,6 +48,10 @@ func New(cfg *config.Config, db *database.Database) *Router {
 	rateLimiter.StartCleanup(5*time.Minute, 10*time.Minute)
 	engine.Use(middleware.RateLimitMiddleware(rateLimiter))
 
+	// Approvals check a manager's password or PIN, so each user may only request a few
+	overrideLimiter := middleware.NewPerMinuteRateLimiter(cfg.RateLimit.OverridesPerMinute)
+	overrideLimiter.StartCleanup(5*time.Minute, 10*time.Minute)
+
 	// JWT Manager (convert hours to duration)
 	jwtManager := utils.NewJWTManager(
 		cfg.JWT.Secret,
@@ -51,25 +63,72 @@ func New(cfg *config.Config, db *database.Database) *Router {
 	userRepo := repository.NewUserRepository(db.DB)
 	categoryRepo := repository.NewCategoryRepository(db.DB)
 	productRepo := repository.NewProductRepository(db.DB)
+	productOptionRepo := repository.NewProductOptionRepository(db.DB)
 	customerRepo := repository.NewCustomerRepository(db.DB)
 	transactionRepo := repository.NewTransactionRepository(db.DB)
+	voucherRepo := repository.NewVoucherRepository(db.DB)
+	loyaltyRepo := repository.NewLoyaltyRepository(db.DB)
+	trashRepo := repository.NewTrashRepository(db.DB)
+	ingredientRepo := repository.NewIngredientRepository(db.DB)
+	supplierRepo := repository.NewSupplierRepository(db.DB)
+	purchaseOrderRepo := repository.NewPurchaseOrderRepository(db.DB)
+	stockTakeRepo := repository.NewStockTakeRepository(db.DB)
+	storeRepo := repository.NewStoreRepository(db.DB)
+	stockTransferRepo := repository.NewStockTransferRepository(db.DB)
+	tenantRepo := repository.NewTenantRepository(db.DB)
+	roleRepo := repository.NewRoleRepository(db.DB)
+	overrideRepo := repository.NewOverrideRepository(db.DB)
+	terminalRepo := repository.NewTerminalRepository(db.DB)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db.DB)
+	heldTransactionRepo := repository.NewHeldTransactionRepository(db.DB)
+	mfaRecoveryCodeRepo := repository.NewMFARecoveryCodeRepository(db.DB)
+	notificationRepo := repository.NewNotificationRepository(db.DB)
+
+	// Sign-in lookups, login events and the background jobs work across tenants, so they use
+	// the system pool, which row-level security does not restrict
+	accountRepo := repository.NewUserRepository(db.System)
+	terminalKeyRepo := repository.NewTerminalRepository(db.System)
+	loginEventRepo := repository.NewLoginEventRepository(db.System)
+	jobLoyaltyRepo := repository.NewLoyaltyRepository(db.System)
+	jobCustomerRepo := repository.NewCustomerRepository(db.System)
+	jobTrashRepo := repository.NewTrashRepository(db.System)
 
 	// Services
-	authService := service.NewAuthService(userRepo, jwtManager)
-	userService := service.NewUserService(userRepo)
+	roleService := service.NewRoleService(roleRepo)
+	licenseService := service.NewLicenseService(tenantRepo, userRepo, storeRepo, cfg.License)
+	mfaService := service.NewMFAService(userRepo, mfaRecoveryCodeRepo, loginAttemptRepo, roleService, cfg.MFA)
+	notificationService := service.NewNotificationService(notificationRepo)
+	loginSecurityService := service.NewLoginSecurityService(loginAttemptRepo, loginEventRepo, userRepo, notificationService, cfg.Login, cfg.Terminal)
+	authService := service.NewAuthService(userRepo, accountRepo, storeRepo, tenantRepo, roleService, licenseService, mfaService, loginSecurityService, jwtManager)
+	userService := service.NewUserService(userRepo, roleService, licenseService)
 	categoryService := service.NewCategoryService(categoryRepo)
-	productService := service.NewProductService(productRepo, categoryRepo)
+	productService := service.NewProductService(productRepo, categoryRepo, productOptionRepo)
 	customerService := service.NewCustomerService(customerRepo)
-	transactionService := service.NewTransactionService(transactionRepo, productRepo, customerRepo)
-	reportService := service.NewReportService(transactionRepo)
+	loyaltyService := service.NewLoyaltyService(loyaltyRepo, customerRepo, cfg.Loyalty)
+	service.NewLoyaltyService(jobLoyaltyRepo, jobCustomerRepo, cfg.Loyalty).StartExpiryJob(time.Hour)
+	inventoryService := service.NewInventoryService(ingredientRepo, productRepo)
+	overrideService := service.NewOverrideService(overrideRepo, userRepo, roleService, loginSecurityService)
+	transactionService := service.NewTransactionService(transactionRepo, productRepo, productOptionRepo, customerRepo, voucherRepo, loyaltyService, inventoryService, overrideService)
+	reportService := service.NewReportService(transactionRepo, purchaseOrderRepo, stockTransferRepo)
+	voucherService := service.NewVoucherService(voucherRepo)
+	trashService := service.NewTrashService(trashRepo, licenseService, cfg.Trash.RetentionDays)
+	service.NewTrashService(jobTrashRepo, licenseService, cfg.Trash.RetentionDays).StartPurgeJob(24 * time.Hour)
+	mediaService := service.NewMediaService(productRepo, store, cfg.Storage)
+	purchaseService := service.NewPurchaseService(supplierRepo, purchaseOrderRepo, productRepo, storeRepo)
+	stockTakeService := service.NewStockTakeService(stockTakeRepo, categoryRepo, storeRepo)
+	storeService := service.NewStoreService(storeRepo, userRepo, productRepo, licenseService)
+	stockTransferService := service.NewStockTransferService(stockTransferRepo, storeRepo, productRepo)
+	terminalService := service.NewTerminalService(terminalRepo, terminalKeyRepo, userRepo, storeRepo, loginAttemptRepo, mfaService, jwtManager, cfg.Terminal)
+	heldTransactionService := service.NewHeldTransactionService(heldTransactionRepo)
+	systemService := service.NewSystemService(db.DB, store, terminalService, cfg)
 
 	// Handlers
 	healthHandler := handler.NewHealthHandler(cfg)
 	authHandler := handler.NewAuthHandler(authService, cfg)
-	userHandler := handler.NewUserHandler(userService)
+	userHandler := handler.NewUserHandler(userService, loginSecurityService)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
-	productHandler := handler.NewProductHandler(productService)
-	customerHandler := handler.NewCustomerHandler(customerService)
+	productHandler := handler.NewProductHandler(productService, roleService)
+	customerHandler := handler.NewCustomerHandler(customerService, transactionService)
 	transactionHandler := handler.NewTransactionHandler(transactionService)
 	reportHandler := handler.NewReportHandler(reportService)
 	dashboardHandler := handler.NewDashboardHandler(
@@ -78,14 +137,37 @@ func New(cfg *config.Config, db *database.Database) *Router {
 		categoryService,
 		customerService,
 		reportService,
+		systemService,
 	)
-	posHandler := handler.NewPOSHandler(productService, transactionService)
-	notificationHandler := handler.NewNotificationHandler(db.DB)
+	posHandler := handler.NewPOSHandler(productService, transactionService, inventoryService, heldTransactionService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	voucherHandler := handler.NewVoucherHandler(voucherService)
+	loyaltyHandler := handler.NewLoyaltyHandler(loyaltyService)
+	trashHandler := handler.NewTrashHandler(trashService)
+	inventoryHandler := handler.NewInventoryHandler(inventoryService)
+	mediaHandler := handler.NewMediaHandler(mediaService)
+	purchaseHandler := handler.NewPurchaseHandler(purchaseService)
+	stockTakeHandler := handler.NewStockTakeHandler(stockTakeService)
+	storeHandler := handler.NewStoreHandler(storeService)
+	stockTransferHandler := handler.NewStockTransferHandler(stockTransferService)
+	licenseHandler := handler.NewLicenseHandler(licenseService)
+	roleHandler := handler.NewRoleHandler(roleService)
+	overrideHandler := handler.NewOverrideHandler(overrideService)
+	terminalHandler := handler.NewTerminalHandler(terminalService, cfg)
+	mfaHandler := handler.NewMFAHandler(mfaService)
+
+	// can requires the user's role to grant a permission
+	can := func(permission string) gin.HandlerFunc {
+		return middleware.RequirePermission(roleService, permission)
+	}
 
 	// Routes
 	// Health check (public)
 	engine.GET("/health", healthHandler.Check)
 
+	// Uploaded media (public, served from the blob store)
+	engine.GET("/media/*key", mediaHandler.Serve)
+
 	// API v1
 	v1 := engine.Group("/api/v1")
 	{
@@ -101,8 +183,19 @@ func New(cfg *config.Config, db *database.Database) *Router {
 			auth.POST("/logout", authHandler.Logout)
 			auth.POST("/forgot-password", authHandler.ForgotPassword)
 			auth.POST("/reset-password", authHandler.ResetPassword)
+
+			// Second step of signing in with two-factor authentication
+			auth.POST("/mfa/setup", authHandler.SetupMFA)
+			auth.POST("/mfa/verify", authHandler.VerifyMFA)
+
+			// PIN sign-in on registered terminals (identified by the X-Terminal-Key header)
+			auth.GET("/terminal/users", terminalHandler.Users)
+			auth.POST("/pin-login", terminalHandler.PINLogin)
 		}
 
+		// Terminal heartbeats (identified by the X-Terminal-Key header)
+		v1.POST("/terminal/heartbeat", terminalHandler.Heartbeat)
+
 		// Protected routes
 		protected := v1.Group("")
 		protected.Use(middleware.AuthMiddleware(jwtManager))
@@ -111,17 +204,59 @@ func New(cfg *config.Config, db *database.Database) *Router {
 			protected.GET("/auth/me", authHandler.Me)
 			protected.PUT("/auth/me", authHandler.UpdateProfile)
 			protected.GET("/auth/me/activity", authHandler.GetActivityLog)
+			protected.PUT("/auth/me/pin", authHandler.SetPIN)
+			protected.GET("/auth/me/mfa", mfaHandler.Status)
+			protected.POST("/auth/me/mfa/setup", mfaHandler.Setup)
+			protected.POST("/auth/me/mfa/enable", mfaHandler.Enable)
+			protected.POST("/auth/me/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+			protected.DELETE("/auth/me/mfa", mfaHandler.Disable)
+			protected.POST("/auth/store", authHandler.SwitchStore)
 
-			// User Management (Admin only)
+			// User Management
 			users := protected.Group("/users")
-			users.Use(middleware.RequireRole(models.RoleAdmin))
+			users.Use(can(models.PermUsersManage))
 			{
 				users.GET("", userHandler.List)
+				users.GET("/login-events", userHandler.LoginEvents)
 				users.GET("/:id", userHandler.Get)
 				users.POST("", userHandler.Create)
 				users.PUT("/:id", userHandler.Update)
 				users.DELETE("/:id", userHandler.Delete)
 				users.PUT("/:id/reset-password", userHandler.ResetPassword)
+				users.DELETE("/:id/mfa", mfaHandler.Reset)
+				users.POST("/:id/unlock", userHandler.Unlock)
+				users.PUT("/:id/stores", storeHandler.AssignUserStores)
+				users.POST("/:id/restore", trashHandler.Restore(models.TrashUsers))
+			}
+
+			// Roles and the permissions they grant
+			roles := protected.Group("/roles")
+			roles.Use(can(models.PermRolesManage))
+			{
+				roles.GET("", roleHandler.List)
+				roles.GET("/permissions", roleHandler.Permissions)
+				roles.GET("/:name", roleHandler.Get)
+				roles.POST("", roleHandler.Create)
+				roles.PUT("/:name", roleHandler.Update)
+				roles.DELETE("/:name", roleHandler.Delete)
+			}
+
+			// Registered POS terminals
+			terminals := protected.Group("/terminals")
+			terminals.Use(can(models.PermTerminalsManage))
+			{
+				terminals.GET("", terminalHandler.List)
+				terminals.POST("", terminalHandler.Create)
+				terminals.PUT("/:id", terminalHandler.Update)
+				terminals.POST("/:id/key", terminalHandler.RegenerateKey)
+				terminals.DELETE("/:id", terminalHandler.Delete)
+			}
+
+			// Manager approvals for voids, refunds, discounts, price overrides and no-sale
+			overrides := protected.Group("/overrides")
+			{
+				overrides.POST("", middleware.UserRateLimitMiddleware(overrideLimiter), overrideHandler.Create)
+				overrides.GET("", can(models.PermOverridesView), overrideHandler.List)
 			}
 
 			// Notifications (supports both PUT and PATCH/POST for FE compatibility)
@@ -141,10 +276,13 @@ func New(cfg *config.Config, db *database.Database) *Router {
 			pos := protected.Group("/pos")
 			{
 				pos.GET("/products", posHandler.GetProducts)
+				pos.GET("/products/barcode/:code", posHandler.LookupBarcode)
 				pos.POST("/transactions", posHandler.CreateTransaction)
 				pos.GET("/hold", posHandler.GetHeldTransactions)
 				pos.POST("/hold", posHandler.HoldTransactionCreate)
 				pos.DELETE("/hold/:id", posHandler.DeleteHeldTransaction)
+				pos.POST("/vouchers/validate", voucherHandler.Validate)
+				pos.POST("/no-sale", overrideHandler.NoSale)
 			}
 
 			// Categories
@@ -154,33 +292,105 @@ func New(cfg *config.Config, db *database.Database) *Router {
 				categories.GET("/stats", dashboardHandler.GetCategoryStats)
 				categories.GET("/activity-log", dashboardHandler.GetCategoryActivityLog)
 				categories.GET("/:id", categoryHandler.Get)
-				categories.POST("", middleware.RequireRole(models.RoleAdmin, models.RoleManager), categoryHandler.Create)
-				categories.PUT("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleManager), categoryHandler.Update)
-				categories.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), categoryHandler.Delete)
+				categories.POST("", can(models.PermCategoriesManage), categoryHandler.Create)
+				categories.PUT("/:id", can(models.PermCategoriesManage), categoryHandler.Update)
+				categories.DELETE("/:id", can(models.PermCategoriesDelete), categoryHandler.Delete)
+				categories.POST("/:id/restore", can(models.PermTrashManage), trashHandler.Restore(models.TrashCategories))
 			}
 
 			// Products
 			products := protected.Group("/products")
 			{
 				products.GET("", productHandler.List)
+				products.GET("/search", posHandler.SearchProducts)
 				products.GET("/stats", dashboardHandler.GetProductStats)
 				products.GET("/stock-movements", dashboardHandler.GetStockMovements)
+				products.GET("/export", can(models.PermProductsExport), middleware.RequireFeature(licenseService, models.FeatureExport), productHandler.Export)
+				products.POST("/import", can(models.PermProductsManage), productHandler.Import)
 				products.GET("/:id", productHandler.Get)
-				products.POST("", middleware.RequireRole(models.RoleAdmin, models.RoleManager), productHandler.Create)
-				products.PUT("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleManager), productHandler.Update)
-				products.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), productHandler.Delete)
-				products.PATCH("/:id/stock", productHandler.UpdateStock)
+				products.POST("", can(models.PermProductsManage), productHandler.Create)
+				products.PUT("/:id", can(models.PermProductsManage), productHandler.Update)
+				products.DELETE("/:id", can(models.PermProductsDelete), productHandler.Delete)
+				products.POST("/:id/restore", can(models.PermTrashManage), trashHandler.Restore(models.TrashProducts))
+				products.PATCH("/:id/stock", can(models.PermInventoryManage), productHandler.UpdateStock)
+				products.POST("/:id/image", can(models.PermProductsManage), mediaHandler.UploadProductImage)
+				products.DELETE("/:id/image", can(models.PermProductsManage), mediaHandler.DeleteProductImage)
+				products.POST("/:id/variants", can(models.PermProductsManage), productHandler.CreateVariant)
+				products.PUT("/:id/variants/:variantId", can(models.PermProductsManage), productHandler.UpdateVariant)
+				products.PATCH("/:id/variants/:variantId/stock", can(models.PermInventoryManage), productHandler.UpdateVariantStock)
+				products.DELETE("/:id/variants/:variantId", can(models.PermProductsManage), productHandler.DeleteVariant)
+				products.POST("/:id/modifier-groups", can(models.PermProductsManage), productHandler.CreateModifierGroup)
+				products.PUT("/:id/modifier-groups/:groupId", can(models.PermProductsManage), productHandler.UpdateModifierGroup)
+				products.DELETE("/:id/modifier-groups/:groupId", can(models.PermProductsManage), productHandler.DeleteModifierGroup)
+				products.POST("/:id/barcodes", can(models.PermProductsManage), productHandler.CreateBarcode)
+				products.DELETE("/:id/barcodes/:barcodeId", can(models.PermProductsManage), productHandler.DeleteBarcode)
+				products.GET("/:id/barcodes/:barcodeId/label", productHandler.BarcodeLabel)
+				products.GET("/:id/recipe", inventoryHandler.GetRecipe)
+				products.PUT("/:id/recipe", can(models.PermProductsManage), inventoryHandler.SetRecipe)
+			}
+
+			// Ingredients (recipe inventory)
+			ingredients := protected.Group("/ingredients")
+			{
+				ingredients.GET("", inventoryHandler.List)
+				ingredients.GET("/:id", inventoryHandler.Get)
+				ingredients.GET("/:id/movements", inventoryHandler.Movements)
+				ingredients.POST("", can(models.PermInventoryManage), inventoryHandler.Create)
+				ingredients.PUT("/:id", can(models.PermInventoryManage), inventoryHandler.Update)
+				ingredients.DELETE("/:id", can(models.PermInventoryManage), inventoryHandler.Delete)
+				ingredients.POST("/:id/stock", can(models.PermInventoryManage), inventoryHandler.AdjustStock)
+			}
+
+			// Stock takes: staff count, managers open, approve and cancel
+			stockTakes := protected.Group("/stock-takes")
+			{
+				stockTakes.GET("", stockTakeHandler.List)
+				stockTakes.GET("/:id", stockTakeHandler.Get)
+				stockTakes.POST("/:id/counts", stockTakeHandler.SubmitCounts)
+				stockTakes.POST("", can(models.PermStockTakesManage), stockTakeHandler.Create)
+				stockTakes.POST("/:id/approve", can(models.PermStockTakesManage), stockTakeHandler.Approve)
+				stockTakes.POST("/:id/cancel", can(models.PermStockTakesManage), stockTakeHandler.Cancel)
 			}
 
+			// Stock transfers between stores
+			stockTransfers := protected.Group("/stock-transfers")
+			stockTransfers.Use(can(models.PermStockTransfersManage), middleware.RequireFeature(licenseService, models.FeatureMultiStore))
+			{
+				stockTransfers.GET("", stockTransferHandler.List)
+				stockTransfers.GET("/:id", stockTransferHandler.Get)
+				stockTransfers.POST("", stockTransferHandler.Create)
+				stockTransfers.POST("/:id/ship", stockTransferHandler.Ship)
+				stockTransfers.POST("/:id/receive", stockTransferHandler.Receive)
+				stockTransfers.POST("/:id/cancel", stockTransferHandler.Cancel)
+			}
+
+			// Units of measure
+			protected.GET("/units", inventoryHandler.Units)
+
 			// Customers
 			customers := protected.Group("/customers")
 			{
 				customers.GET("", customerHandler.List)
 				customers.GET("/stats", dashboardHandler.GetCustomerStats)
+				customers.GET("/duplicates", customerHandler.Duplicates)
 				customers.GET("/:id", customerHandler.Get)
 				customers.POST("", customerHandler.Create)
 				customers.PUT("/:id", customerHandler.Update)
-				customers.DELETE("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleManager), customerHandler.Delete)
+				customers.DELETE("/:id", can(models.PermCustomersDelete), customerHandler.Delete)
+				customers.POST("/:id/restore", can(models.PermTrashManage), trashHandler.Restore(models.TrashCustomers))
+				customers.GET("/:id/stats", customerHandler.Stats)
+				customers.POST("/:id/merge", can(models.PermCustomersMerge), customerHandler.Merge)
+				customers.GET("/:id/merges", can(models.PermCustomersMergesView), customerHandler.Merges)
+				customers.GET("/:id/transactions", customerHandler.Transactions)
+				customers.GET("/:id/loyalty", loyaltyHandler.Summary)
+				customers.GET("/:id/loyalty/ledger", loyaltyHandler.Ledger)
+				customers.PATCH("/:id/loyalty-points", can(models.PermLoyaltyAdjust), loyaltyHandler.Adjust)
+			}
+
+			// Loyalty program
+			loyalty := protected.Group("/loyalty")
+			{
+				loyalty.GET("/rules", loyaltyHandler.Rules)
 			}
 
 			// Transactions
@@ -191,7 +401,68 @@ func New(cfg *config.Config, db *database.Database) *Router {
 				transactions.GET("/stats", dashboardHandler.GetTransactionStats)
 				transactions.GET("/:id", transactionHandler.Get)
 				transactions.POST("", transactionHandler.Create)
-				transactions.PATCH("/:id/status", middleware.RequireRole(models.RoleAdmin, models.RoleManager), transactionHandler.UpdateStatus)
+				// Voids and refunds need permission or a manager's approval
+				transactions.PATCH("/:id/status", transactionHandler.UpdateStatus)
+			}
+
+			// Vouchers
+			vouchers := protected.Group("/vouchers")
+			vouchers.Use(can(models.PermVouchersManage))
+			{
+				vouchers.GET("", voucherHandler.List)
+				vouchers.POST("/batch", voucherHandler.Generate)
+				vouchers.GET("/:id", voucherHandler.Get)
+				vouchers.PUT("/:id", voucherHandler.Update)
+				vouchers.GET("/:id/redemptions", voucherHandler.Redemptions)
+			}
+
+			// License status and activation
+			license := protected.Group("/license")
+			{
+				license.GET("", can(models.PermLicenseView), licenseHandler.Status)
+				license.PUT("", can(models.PermLicenseManage), licenseHandler.Activate)
+			}
+
+			// Stores: everyone can look them up
+			stores := protected.Group("/stores")
+			{
+				stores.GET("", storeHandler.List)
+				stores.GET("/:id", storeHandler.Get)
+				stores.POST("", can(models.PermStoresManage), storeHandler.Create)
+				stores.PUT("/:id", can(models.PermStoresManage), storeHandler.Update)
+				stores.PUT("/:id/prices", can(models.PermProductsPriceUpdate), storeHandler.SetPrices)
+			}
+
+			// Suppliers
+			suppliers := protected.Group("/suppliers")
+			suppliers.Use(can(models.PermPurchasingManage))
+			{
+				suppliers.GET("", purchaseHandler.ListSuppliers)
+				suppliers.POST("", purchaseHandler.CreateSupplier)
+				suppliers.GET("/:id", purchaseHandler.GetSupplier)
+				suppliers.PUT("/:id", purchaseHandler.UpdateSupplier)
+				suppliers.DELETE("/:id", purchaseHandler.DeleteSupplier)
+			}
+
+			// Purchase orders and goods receiving
+			purchaseOrders := protected.Group("/purchase-orders")
+			purchaseOrders.Use(can(models.PermPurchasingManage))
+			{
+				purchaseOrders.GET("", purchaseHandler.ListPurchaseOrders)
+				purchaseOrders.POST("", purchaseHandler.CreatePurchaseOrder)
+				purchaseOrders.GET("/:id", purchaseHandler.GetPurchaseOrder)
+				purchaseOrders.PUT("/:id", purchaseHandler.UpdatePurchaseOrder)
+				purchaseOrders.POST("/:id/order", purchaseHandler.SubmitPurchaseOrder)
+				purchaseOrders.POST("/:id/cancel", purchaseHandler.CancelPurchaseOrder)
+				purchaseOrders.POST("/:id/receive", purchaseHandler.ReceivePurchaseOrder)
+			}
+
+			// Trash
+			trash := protected.Group("/trash")
+			trash.Use(can(models.PermTrashManage))
+			{
+				trash.GET("", trashHandler.List)
+				trash.POST("/purge", trashHandler.Purge)
 			}
 
 			// Reports
@@ -201,18 +472,21 @@ func New(cfg *config.Config, db *database.Database) *Router {
 				reports.GET("/dashboard/stats", dashboardHandler.GetDashboardStats)
 				reports.GET("/sales/realtime", dashboardHandler.GetRealtimeSales)
 
-				// Detailed reports (admin only)
-				reports.GET("/sales/daily", middleware.RequireRole(models.RoleAdmin), reportHandler.DailySales)
-				reports.GET("/sales/weekly", middleware.RequireRole(models.RoleAdmin), dashboardHandler.GetWeeklySales)
-				reports.GET("/sales/monthly", middleware.RequireRole(models.RoleAdmin), reportHandler.MonthlySales)
-				reports.GET("/products/top", middleware.RequireRole(models.RoleAdmin), reportHandler.TopProducts)
-				reports.GET("/categories/performance", middleware.RequireRole(models.RoleAdmin), dashboardHandler.GetCategoryPerformance)
+				// Detailed reports (on plans with advanced reports)
+				advanced := middleware.RequireFeature(licenseService, models.FeatureAdvancedReports)
+				reports.GET("/sales/daily", can(models.PermReportsView), advanced, reportHandler.DailySales)
+				reports.GET("/sales/weekly", can(models.PermReportsView), advanced, dashboardHandler.GetWeeklySales)
+				reports.GET("/sales/monthly", can(models.PermReportsView), advanced, reportHandler.MonthlySales)
+				reports.GET("/products/top", can(models.PermReportsView), advanced, reportHandler.TopProducts)
+				reports.GET("/categories/performance", can(models.PermReportsView), advanced, dashboardHandler.GetCategoryPerformance)
+				reports.GET("/purchase-orders/outstanding", can(models.PermReportsInventory), reportHandler.OutstandingPurchases)
+				reports.GET("/stock-transfers/in-transit", can(models.PermReportsInventory), middleware.RequireFeature(licenseService, models.FeatureMultiStore), reportHandler.InTransitTransfers)
 			}
 
 			// System
 			system := protected.Group("/system")
 			{
-				system.GET("/health/detailed", dashboardHandler.GetSystemHealth)
+				system.GET("/health/detailed", can(models.PermSystemHealth), dashboardHandler.GetSystemHealth)
 			}
 		}
 	}
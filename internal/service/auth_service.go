@@ -15,26 +15,45 @@ import (
 
 // AuthService handles authentication operations
 type AuthService struct {
-	userRepo   repository.UserRepository
-	jwtManager *utils.JWTManager
+	userRepo       repository.UserRepository
+	accountRepo    repository.UserRepository // finds users by email across tenants
+	storeRepo      repository.StoreRepository
+	tenantRepo     repository.TenantRepository
+	roleService    *RoleService
+	licenseService *LicenseService
+	mfaService     *MFAService
+	loginSecurity  *LoginSecurityService
+	jwtManager     *utils.JWTManager
 }
 
 // NewAuthService creates a new auth service
-func NewAuthService(userRepo repository.UserRepository, jwtManager *utils.JWTManager) *AuthService {
+func NewAuthService(userRepo, accountRepo repository.UserRepository, storeRepo repository.StoreRepository, tenantRepo repository.TenantRepository, roleService *RoleService, licenseService *LicenseService, mfaService *MFAService, loginSecurity *LoginSecurityService, jwtManager *utils.JWTManager) *AuthService {
 	return &AuthService{
-		userRepo:   userRepo,
-		jwtManager: jwtManager,
+		userRepo:       userRepo,
+		accountRepo:    accountRepo,
+		storeRepo:      storeRepo,
+		tenantRepo:     tenantRepo,
+		roleService:    roleService,
+		licenseService: licenseService,
+		mfaService:     mfaService,
+		loginSecurity:  loginSecurity,
+		jwtManager:     jwtManager,
 	}
 }
 
-// Login authenticates a user and returns a token pair
-func (s *AuthService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.AuthResponse, error) {
-	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+// Login authenticates a user and returns a token pair. Users with two-factor authentication,
+// or whose role requires it, get an MFA challenge instead, which VerifyMFA exchanges for the
+// token pair. Failed attempts are delayed and then locked out per account and IP address.
+func (s *AuthService) Login(ctx context.Context, req *dto.LoginRequest, client dto.ClientInfo) (*dto.AuthResponse, error) {
+	user, err := s.accountRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.loginSecurity.Check(ctx, user, req.Email, client); err != nil {
+		return nil, err
+	}
 	if user == nil {
-		return nil, errors.New("invalid email or password")
+		return nil, s.loginSecurity.Failed(ctx, nil, req.Email, client)
 	}
 
 	if !user.IsActive {
@@ -42,35 +61,149 @@ func (s *AuthService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.Au
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return nil, errors.New("invalid email or password")
+		return nil, s.loginSecurity.Failed(ctx, user, req.Email, client)
+	}
+
+	// Emails are unique across tenants; everything after this is the user's tenant
+	ctx = utils.WithTenant(ctx, user.TenantID)
+
+	storeID, err := resolveStore(ctx, s.storeRepo, user.ID, req.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	required, err := s.mfaService.Required(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled || required {
+		if err := s.loginSecurity.Challenged(ctx, user, client); err != nil {
+			return nil, err
+		}
+		expiry := s.mfaService.ChallengeDuration()
+		token, err := s.jwtManager.GenerateMFAToken(user, storeID, expiry)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.AuthResponse{
+			User: toAuthUserResponse(user),
+			MFA: &dto.MFAChallengeResponse{
+				Token:              token,
+				ExpiresIn:          int64(expiry.Seconds()),
+				EnrollmentRequired: !user.TOTPEnabled,
+			},
+			StoreID:  storeID,
+			TenantID: user.TenantID,
+		}, nil
+	}
+
+	resp, err := s.signIn(ctx, user, storeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loginSecurity.Succeeded(ctx, user, client); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SetupMFA starts two-factor enrollment for a sign-in whose user's role requires it but who
+// has not enrolled yet
+func (s *AuthService) SetupMFA(ctx context.Context, mfaToken string) (*dto.MFASetupResponse, error) {
+	claims, err := s.jwtManager.ValidateMFAToken(mfaToken)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+	return s.mfaService.Setup(utils.WithTenant(ctx, claims.TenantID), claims.UserID)
+}
+
+// VerifyMFA completes a sign-in with its second factor and returns the token pair. A user
+// enrolling while signing in confirms enrollment with the code and also gets recovery codes.
+func (s *AuthService) VerifyMFA(ctx context.Context, req *dto.VerifyMFARequest, client dto.ClientInfo) (*dto.AuthResponse, error) {
+	claims, err := s.jwtManager.ValidateMFAToken(req.MFAToken)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	ctx = utils.WithTenant(ctx, claims.TenantID)
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	if !user.IsActive {
+		return nil, errors.New("user account is deactivated")
+	}
+
+	var recoveryCodes []string
+	if user.TOTPEnabled {
+		err = s.mfaService.Verify(ctx, user, req.Code, req.RecoveryCode)
+	} else {
+		recoveryCodes, err = s.mfaService.Enable(ctx, user.ID, req.Code)
+		user.TOTPEnabled = err == nil
+	}
+	if errors.Is(err, ErrInvalidMFACode) || errors.Is(err, ErrMFALocked) {
+		s.loginSecurity.MFAFailed(ctx, user, client)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	tokenPair, err := s.jwtManager.GenerateTokenPair(user)
+	storeID, err := resolveStore(ctx, s.storeRepo, user.ID, claims.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.signIn(ctx, user, storeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loginSecurity.Succeeded(ctx, user, client); err != nil {
+		return nil, err
+	}
+	resp.RecoveryCodes = recoveryCodes
+	return resp, nil
+}
+
+// signIn issues the token pair for a user who passed every sign-in check
+func (s *AuthService) signIn(ctx context.Context, user *models.User, storeID string) (*dto.AuthResponse, error) {
+	tokenPair, err := s.jwtManager.GenerateTokenPair(user, storeID)
 	if err != nil {
 		return nil, err
 	}
 
 	return &dto.AuthResponse{
-		User: dto.UserResponse{
-			ID:       user.ID,
-			Email:    user.Email,
-			Name:     user.Name,
-			Role:     user.Role,
-			IsActive: user.IsActive,
-		},
+		User: toAuthUserResponse(user),
 		Token: dto.TokenResponse{
 			AccessToken:  tokenPair.AccessToken,
 			RefreshToken: tokenPair.RefreshToken,
 			ExpiresIn:    tokenPair.ExpiresIn,
 			TokenType:    tokenPair.TokenType,
 		},
+		StoreID:  storeID,
+		TenantID: user.TenantID,
 	}, nil
 }
 
-// Register creates a new user account
+func toAuthUserResponse(user *models.User) dto.UserResponse {
+	return dto.UserResponse{
+		ID:         user.ID,
+		Email:      user.Email,
+		Name:       user.Name,
+		Role:       user.Role,
+		IsActive:   user.IsActive,
+		MFAEnabled: user.TOTPEnabled,
+	}
+}
+
+// Register creates a new user account. With a business name it also creates a tenant for the
+// business, licensed with the license key if one is given, and makes the user its admin;
+// otherwise the user joins the default tenant as a cashier and takes one of its seats.
 func (s *AuthService) Register(ctx context.Context, req *dto.RegisterRequest) (*dto.AuthResponse, error) {
 	// Check if email already exists
-	existing, err := s.userRepo.GetByEmail(ctx, req.Email)
+	existing, err := s.accountRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		return nil, err
 	}
@@ -83,10 +216,9 @@ func (s *AuthService) Register(ctx context.Context, req *dto.RegisterRequest) (*
 		return nil, errors.New("passwords do not match")
 	}
 
-	// Set default role if not specified
-	role := req.Role
-	if role == "" {
-		role = models.RoleCashier
+	// Only a business's own admin hands out other roles
+	if req.BusinessName == "" && req.Role != "" && req.Role != models.RoleCashier {
+		return nil, errors.New("new users join as cashiers; ask an admin for another role")
 	}
 
 	// Hash password
@@ -98,20 +230,48 @@ func (s *AuthService) Register(ctx context.Context, req *dto.RegisterRequest) (*
 	now := time.Now()
 	user := &models.User{
 		ID:           uuid.New().String(),
+		TenantID:     utils.DefaultTenantID,
 		Email:        req.Email,
 		PasswordHash: string(hashedPassword),
 		Name:         req.Name,
-		Role:         role,
+		Role:         models.RoleCashier,
 		IsActive:     true,
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
 
-	if err := s.userRepo.Create(ctx, user); err != nil {
-		return nil, err
+	if req.BusinessName != "" {
+		if req.LicenseKey != "" {
+			if _, err := s.licenseService.Verify(req.LicenseKey, req.BusinessName); err != nil {
+				return nil, err
+			}
+		}
+		tenant := &models.Tenant{
+			ID:         uuid.New().String(),
+			Name:       req.BusinessName,
+			LicenseKey: req.LicenseKey,
+			IsActive:   true,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		user.TenantID = tenant.ID
+		user.Role = models.RoleAdmin
+
+		// The business and its admin are created together, so a failed signup leaves no tenant
+		if err := s.tenantRepo.Create(ctx, tenant, user); err != nil {
+			return nil, err
+		}
+	} else {
+		ctx = utils.WithTenant(ctx, user.TenantID)
+		if err := s.licenseService.CheckSeat(ctx); err != nil {
+			return nil, err
+		}
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return nil, err
+		}
 	}
 
-	tokenPair, err := s.jwtManager.GenerateTokenPair(user)
+	tokenPair, err := s.jwtManager.GenerateTokenPair(user, "")
 	if err != nil {
 		return nil, err
 	}
@@ -130,6 +290,7 @@ func (s *AuthService) Register(ctx context.Context, req *dto.RegisterRequest) (*
 			ExpiresIn:    tokenPair.ExpiresIn,
 			TokenType:    tokenPair.TokenType,
 		},
+		TenantID: user.TenantID,
 	}, nil
 }
 
@@ -139,7 +300,11 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*d
 	if err != nil {
 		return nil, errors.New("invalid refresh token")
 	}
+	if claims.TerminalID != "" {
+		return nil, errors.New("PIN sessions cannot be refreshed")
+	}
 
+	ctx = utils.WithTenant(ctx, claims.TenantID)
 	user, err := s.userRepo.GetByID(ctx, claims.UserID)
 	if err != nil {
 		return nil, err
@@ -152,7 +317,56 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*d
 		return nil, errors.New("user account is deactivated")
 	}
 
-	tokenPair, err := s.jwtManager.GenerateTokenPair(user)
+	// Sessions end once the user's role requires two-factor authentication they have not set up
+	if !user.TOTPEnabled {
+		required, err := s.mfaService.Required(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		if required {
+			return nil, errors.New("two-factor authentication is required, sign in again")
+		}
+	}
+
+	// Keep the session's store as long as the user may still work there
+	storeID, err := resolveStore(ctx, s.storeRepo, user.ID, claims.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenPair, err := s.jwtManager.GenerateTokenPair(user, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenResponse{
+		AccessToken:  tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+		ExpiresIn:    tokenPair.ExpiresIn,
+		TokenType:    tokenPair.TokenType,
+	}, nil
+}
+
+// SwitchStore issues a new token pair for the current user at another store
+func (s *AuthService) SwitchStore(ctx context.Context, userID, storeID string) (*dto.TokenResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !user.IsActive {
+		return nil, errors.New("user account is deactivated")
+	}
+
+	storeID, err = resolveStore(ctx, s.storeRepo, user.ID, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenPair, err := s.jwtManager.GenerateTokenPair(user, storeID)
 	if err != nil {
 		return nil, err
 	}
@@ -175,16 +389,56 @@ func (s *AuthService) GetCurrentUser(ctx context.Context, userID string) (*dto.U
 		return nil, errors.New("user not found")
 	}
 
+	permissions, err := s.roleService.Permissions(ctx, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
 	return &dto.UserResponse{
-		ID:       user.ID,
-		Email:    user.Email,
-		Name:     user.Name,
-		Phone:    user.Phone,
-		Role:     user.Role,
-		IsActive: user.IsActive,
+		ID:          user.ID,
+		Email:       user.Email,
+		Name:        user.Name,
+		Phone:       user.Phone,
+		Role:        user.Role,
+		IsActive:    user.IsActive,
+		MFAEnabled:  user.TOTPEnabled,
+		Permissions: permissions,
 	}, nil
 }
 
+// ActivityLog lists the current user's sign-ins, failed attempts and lockouts
+func (s *AuthService) ActivityLog(ctx context.Context, userID string, pagination utils.Pagination) ([]*dto.LoginEventResponse, int, error) {
+	return s.loginSecurity.ListEvents(ctx, dto.LoginEventFilter{UserID: userID}, pagination)
+}
+
+// SetPIN sets or removes the current user's PIN after checking their password. Users with
+// two-factor authentication, or whose role requires it, cannot set one.
+func (s *AuthService) SetPIN(ctx context.Context, userID string, req *dto.SetPINRequest) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)) != nil {
+		return errors.New("current password is incorrect")
+	}
+
+	pinHash := ""
+	if req.PIN != "" {
+		if err := s.mfaService.AllowsPIN(ctx, user); err != nil {
+			return err
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.PIN), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		pinHash = string(hash)
+	}
+	return s.userRepo.SetPIN(ctx, userID, pinHash, time.Now())
+}
+
 // UpdateProfile updates the current user's profile
 func (s *AuthService) UpdateProfile(ctx context.Context, userID string, req *dto.UpdateProfileRequest) (*dto.UserResponse, error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
@@ -117,6 +117,14 @@ func (s *CategoryService) Delete(ctx context.Context, id string) error {
 		return errors.New("category not found")
 	}
 
+	products, err := s.categoryRepo.CountProducts(ctx, id)
+	if err != nil {
+		return err
+	}
+	if products > 0 {
+		return errors.New("category still has products")
+	}
+
 	return s.categoryRepo.Delete(ctx, id)
 }
 
@@ -135,6 +143,27 @@ func (s *CategoryService) List(ctx context.Context, pagination utils.Pagination)
 	return responses, total, nil
 }
 
+// ActivityLog lists category creations, edits and deletions, newest first
+func (s *CategoryService) ActivityLog(ctx context.Context, pagination utils.Pagination) ([]*dto.CategoryActivityResponse, int, error) {
+	activities, total, err := s.categoryRepo.ListActivity(ctx, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*dto.CategoryActivityResponse, 0, len(activities))
+	for _, activity := range activities {
+		responses = append(responses, &dto.CategoryActivityResponse{
+			ID:           activity.ID,
+			CategoryID:   activity.CategoryID,
+			CategoryName: activity.CategoryName,
+			Event:        activity.Event,
+			CreatedAt:    activity.CreatedAt,
+		})
+	}
+
+	return responses, total, nil
+}
+
 func (s *CategoryService) toResponse(category *models.Category) *dto.CategoryResponse {
 	return &dto.CategoryResponse{
 		ID:          category.ID,
@@ -12,6 +12,18 @@ import (
 	"github.com/ilramdhan/pos-api/internal/utils"
 )
 
+// favoriteProductsLimit is the number of favorite products returned with customer stats
+const favoriteProductsLimit = 5
+
+// DuplicateCustomerError is returned when a customer with the same phone or email already exists
+type DuplicateCustomerError struct {
+	Matches []*dto.CustomerResponse
+}
+
+func (e *DuplicateCustomerError) Error() string {
+	return "a customer with the same phone or email already exists"
+}
+
 // CustomerService handles customer operations
 type CustomerService struct {
 	customerRepo repository.CustomerRepository
@@ -36,12 +48,19 @@ func (s *CustomerService) Create(ctx context.Context, req *dto.CreateCustomerReq
 		LoyaltyPoints: 0,
 		CreatedAt:     now,
 		UpdatedAt:     now,
+
+		PhoneNormalized: utils.NormalizePhone(req.Phone),
+		EmailNormalized: utils.NormalizeEmail(req.Email),
 	}
 
-	if err := s.customerRepo.Create(ctx, customer); err != nil {
+	if err := s.checkDuplicates(ctx, customer); err != nil {
 		return nil, err
 	}
 
+	if err := s.customerRepo.Create(ctx, customer); err != nil {
+		return nil, s.duplicateError(ctx, customer, err)
+	}
+
 	return s.toResponse(customer), nil
 }
 
@@ -72,12 +91,18 @@ func (s *CustomerService) Update(ctx context.Context, id string, req *dto.Update
 	customer.Email = req.Email
 	customer.Phone = req.Phone
 	customer.Address = req.Address
+	customer.PhoneNormalized = utils.NormalizePhone(req.Phone)
+	customer.EmailNormalized = utils.NormalizeEmail(req.Email)
 	customer.UpdatedAt = time.Now()
 
-	if err := s.customerRepo.Update(ctx, customer); err != nil {
+	if err := s.checkDuplicates(ctx, customer); err != nil {
 		return nil, err
 	}
 
+	if err := s.customerRepo.Update(ctx, customer); err != nil {
+		return nil, s.duplicateError(ctx, customer, err)
+	}
+
 	return s.toResponse(customer), nil
 }
 
@@ -94,8 +119,71 @@ func (s *CustomerService) Delete(ctx context.Context, id string) error {
 	return s.customerRepo.Delete(ctx, id)
 }
 
-// UpdateLoyaltyPoints updates a customer's loyalty points
-func (s *CustomerService) UpdateLoyaltyPoints(ctx context.Context, id string, req *dto.UpdateLoyaltyPointsRequest) (*dto.CustomerResponse, error) {
+// FindDuplicates lists customers whose normalized phone or email match the given details
+func (s *CustomerService) FindDuplicates(ctx context.Context, filter dto.CustomerDuplicateFilter) ([]*dto.CustomerResponse, error) {
+	customers, err := s.customerRepo.FindDuplicates(ctx, utils.NormalizePhone(filter.Phone), utils.NormalizeEmail(filter.Email), "")
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.CustomerResponse, 0, len(customers))
+	for _, customer := range customers {
+		responses = append(responses, s.toResponse(customer))
+	}
+
+	return responses, nil
+}
+
+// Merge folds duplicate customers into the target customer. Transactions, voucher usage and
+// loyalty history move to the target, points are summed, and the duplicates are deleted.
+func (s *CustomerService) Merge(ctx context.Context, targetID, userID string, req *dto.MergeCustomersRequest) (*dto.MergeCustomersResponse, error) {
+	target, err := s.customerRepo.GetByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, errors.New("customer not found")
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool, len(req.SourceIDs))
+	merges := make([]*models.CustomerMerge, 0, len(req.SourceIDs))
+	for _, sourceID := range req.SourceIDs {
+		if sourceID == targetID {
+			return nil, errors.New("cannot merge a customer into itself")
+		}
+		if seen[sourceID] {
+			continue
+		}
+		seen[sourceID] = true
+
+		merges = append(merges, &models.CustomerMerge{
+			ID:               uuid.New().String(),
+			MergedCustomerID: sourceID,
+			MergedBy:         userID,
+			CreatedAt:        now,
+		})
+	}
+
+	if err := s.customerRepo.Merge(ctx, targetID, merges); err != nil {
+		return nil, err
+	}
+
+	customer, err := s.GetByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.MergeCustomersResponse{Customer: customer}
+	for _, merge := range merges {
+		resp.Merges = append(resp.Merges, s.toMergeResponse(merge))
+	}
+
+	return resp, nil
+}
+
+// ListMerges lists the merge audit records of a customer
+func (s *CustomerService) ListMerges(ctx context.Context, id string) ([]*dto.CustomerMergeResponse, error) {
 	customer, err := s.customerRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -104,25 +192,45 @@ func (s *CustomerService) UpdateLoyaltyPoints(ctx context.Context, id string, re
 		return nil, errors.New("customer not found")
 	}
 
-	var newPoints int
-	switch req.Operation {
-	case "add":
-		newPoints = customer.LoyaltyPoints + req.Points
-	case "deduct":
-		newPoints = customer.LoyaltyPoints - req.Points
-		if newPoints < 0 {
-			return nil, errors.New("insufficient loyalty points")
-		}
-	default:
-		return nil, errors.New("invalid operation")
+	merges, err := s.customerRepo.ListMerges(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.customerRepo.UpdateLoyaltyPoints(ctx, id, newPoints); err != nil {
+	responses := make([]*dto.CustomerMergeResponse, 0, len(merges))
+	for _, merge := range merges {
+		responses = append(responses, s.toMergeResponse(merge))
+	}
+
+	return responses, nil
+}
+
+// GetStats retrieves purchase metrics, segment and favorite products for a customer
+func (s *CustomerService) GetStats(ctx context.Context, id string) (*dto.CustomerStatsResponse, error) {
+	stats, err := s.customerRepo.GetStats(ctx, id)
+	if err != nil {
 		return nil, err
 	}
+	if stats == nil {
+		return nil, errors.New("customer not found")
+	}
 
-	customer.LoyaltyPoints = newPoints
-	return s.toResponse(customer), nil
+	favorites, err := s.customerRepo.GetFavoriteProducts(ctx, id, favoriteProductsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := s.toStatsResponse(stats)
+	for _, product := range favorites {
+		resp.FavoriteProducts = append(resp.FavoriteProducts, dto.CustomerFavoriteProductResponse{
+			ProductID:   product.ProductID,
+			ProductName: product.ProductName,
+			Quantity:    product.Quantity,
+			TotalAmount: product.TotalAmount,
+		})
+	}
+
+	return resp, nil
 }
 
 // List lists customers with pagination and filters
@@ -150,5 +258,64 @@ func (s *CustomerService) toResponse(customer *models.Customer) *dto.CustomerRes
 		LoyaltyPoints: customer.LoyaltyPoints,
 		CreatedAt:     customer.CreatedAt,
 		UpdatedAt:     customer.UpdatedAt,
+		Stats:         s.toStatsResponse(customer.Stats),
+	}
+}
+
+func (s *CustomerService) toStatsResponse(stats *models.CustomerStats) *dto.CustomerStatsResponse {
+	if stats == nil {
+		return nil
+	}
+	return &dto.CustomerStatsResponse{
+		LifetimeSpend: stats.LifetimeSpend,
+		VisitCount:    stats.VisitCount,
+		AverageBasket: stats.AverageBasket,
+		FirstVisitAt:  stats.FirstVisitAt,
+		LastVisitAt:   stats.LastVisitAt,
+		Segment:       stats.Segment,
+	}
+}
+
+func (s *CustomerService) toMergeResponse(merge *models.CustomerMerge) *dto.CustomerMergeResponse {
+	return &dto.CustomerMergeResponse{
+		ID:                merge.ID,
+		TargetCustomerID:  merge.TargetCustomerID,
+		MergedCustomerID:  merge.MergedCustomerID,
+		MergedName:        merge.MergedName,
+		MergedEmail:       merge.MergedEmail,
+		MergedPhone:       merge.MergedPhone,
+		PointsMerged:      merge.PointsMerged,
+		TransactionsMoved: merge.TransactionsMoved,
+		MergedBy:          merge.MergedBy,
+		CreatedAt:         merge.CreatedAt,
+	}
+}
+
+// checkDuplicates rejects a customer whose normalized phone or email is already on file
+func (s *CustomerService) checkDuplicates(ctx context.Context, customer *models.Customer) error {
+	matches, err := s.customerRepo.FindDuplicates(ctx, customer.PhoneNormalized, customer.EmailNormalized, customer.ID)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	dupErr := &DuplicateCustomerError{}
+	for _, match := range matches {
+		dupErr.Matches = append(dupErr.Matches, s.toResponse(match))
+	}
+	return dupErr
+}
+
+// duplicateError turns a unique index violation from a concurrent write into a DuplicateCustomerError
+// listing the customer that won the race
+func (s *CustomerService) duplicateError(ctx context.Context, customer *models.Customer, err error) error {
+	if !errors.Is(err, repository.ErrDuplicateCustomer) {
+		return err
+	}
+	if dupErr := s.checkDuplicates(ctx, customer); dupErr != nil {
+		return dupErr
 	}
+	return &DuplicateCustomerError{}
 }
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/repository"
+)
+
+// HeldTransactionService parks carts at the POS. Carts belong to the terminal they were held
+// at (or, outside PIN sessions, to the store), not to the user, so switching users on a
+// terminal keeps them.
+type HeldTransactionService struct {
+	heldRepo repository.HeldTransactionRepository
+}
+
+// NewHeldTransactionService creates a new held transaction service
+func NewHeldTransactionService(heldRepo repository.HeldTransactionRepository) *HeldTransactionService {
+	return &HeldTransactionService{heldRepo: heldRepo}
+}
+
+// Hold parks a cart at the caller's terminal or store
+func (s *HeldTransactionService) Hold(ctx context.Context, userID, storeID, terminalID string, req *dto.HoldTransactionRequest) (*models.HeldTransaction, error) {
+	id := uuid.New().String()
+	held := &models.HeldTransaction{
+		ID:           id,
+		StoreID:      storeID,
+		TerminalID:   terminalID,
+		HoldNumber:   "HOLD-" + strings.ToUpper(id[:8]),
+		CustomerName: req.CustomerName,
+		TotalAmount:  req.TotalAmount,
+		Notes:        req.Notes,
+		HeldBy:       userID,
+		ItemsCount:   len(req.Items),
+		CreatedAt:    time.Now(),
+	}
+	if req.CustomerID != nil && *req.CustomerID != "" {
+		held.CustomerID = req.CustomerID
+	}
+	for _, item := range req.Items {
+		held.Items = append(held.Items, models.HeldTransactionItem{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	if err := s.heldRepo.Create(ctx, held); err != nil {
+		return nil, err
+	}
+	return held, nil
+}
+
+// List lists the carts held at the caller's terminal or store
+func (s *HeldTransactionService) List(ctx context.Context, storeID, terminalID string) ([]*models.HeldTransaction, error) {
+	return s.heldRepo.List(ctx, storeID, terminalID)
+}
+
+// Delete removes a held cart once it is resumed or abandoned
+func (s *HeldTransactionService) Delete(ctx context.Context, id, storeID, terminalID string) error {
+	deleted, err := s.heldRepo.Delete(ctx, id, storeID, terminalID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("held transaction not found")
+	}
+	return nil
+}
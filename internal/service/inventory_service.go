@@ -0,0 +1,499 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/repository"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// quantityPrecision matches the three decimal places ingredient quantities are stored with
+const quantityPrecision = 1000
+
+// InventoryService handles ingredients, recipes and ingredient stock movements
+type InventoryService struct {
+	ingredientRepo repository.IngredientRepository
+	productRepo    repository.ProductRepository
+}
+
+// NewInventoryService creates a new inventory service
+func NewInventoryService(ingredientRepo repository.IngredientRepository, productRepo repository.ProductRepository) *InventoryService {
+	return &InventoryService{
+		ingredientRepo: ingredientRepo,
+		productRepo:    productRepo,
+	}
+}
+
+// Units lists the supported units of measure
+func (s *InventoryService) Units() []*dto.UnitResponse {
+	responses := make([]*dto.UnitResponse, 0, len(models.Units))
+	for _, unit := range models.Units {
+		responses = append(responses, &dto.UnitResponse{
+			Code:      unit.Code,
+			Name:      unit.Name,
+			Dimension: unit.Dimension,
+			Factor:    unit.Factor,
+		})
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		if responses[i].Dimension != responses[j].Dimension {
+			return responses[i].Dimension < responses[j].Dimension
+		}
+		return responses[i].Factor < responses[j].Factor
+	})
+	return responses
+}
+
+// CreateIngredient creates a new ingredient. Opening stock is recorded as a restock movement.
+func (s *InventoryService) CreateIngredient(ctx context.Context, userID string, req *dto.CreateIngredientRequest) (*dto.IngredientResponse, error) {
+	existing, err := s.ingredientRepo.GetBySKU(ctx, req.SKU)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("SKU already exists")
+	}
+
+	now := time.Now()
+	ingredient := &models.Ingredient{
+		ID:                uuid.New().String(),
+		SKU:               req.SKU,
+		Name:              req.Name,
+		Unit:              req.Unit,
+		LowStockThreshold: roundQuantity(req.LowStockThreshold),
+		IsActive:          true,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	if err := s.ingredientRepo.Create(ctx, ingredient); err != nil {
+		return nil, err
+	}
+
+	if stock := roundQuantity(req.Stock); stock > 0 {
+		if err := s.ingredientRepo.PostMovements(ctx, []*models.StockMovement{{
+			ID:           uuid.New().String(),
+			IngredientID: ingredient.ID,
+			Type:         models.MovementRestock,
+			Quantity:     stock,
+			Note:         "Opening stock",
+			CreatedBy:    userID,
+			CreatedAt:    now,
+		}}); err != nil {
+			return nil, err
+		}
+		ingredient.Stock = stock
+	}
+
+	return s.toIngredientResponse(ingredient), nil
+}
+
+// GetIngredient retrieves an ingredient by ID
+func (s *InventoryService) GetIngredient(ctx context.Context, id string) (*dto.IngredientResponse, error) {
+	ingredient, err := s.ingredientRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ingredient == nil {
+		return nil, errors.New("ingredient not found")
+	}
+
+	return s.toIngredientResponse(ingredient), nil
+}
+
+// UpdateIngredient updates an ingredient's details
+func (s *InventoryService) UpdateIngredient(ctx context.Context, id string, req *dto.UpdateIngredientRequest) (*dto.IngredientResponse, error) {
+	ingredient, err := s.ingredientRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ingredient == nil {
+		return nil, errors.New("ingredient not found")
+	}
+
+	if req.SKU != nil && *req.SKU != ingredient.SKU {
+		existing, err := s.ingredientRepo.GetBySKU(ctx, *req.SKU)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, errors.New("SKU already exists")
+		}
+		ingredient.SKU = *req.SKU
+	}
+	if req.Name != nil {
+		ingredient.Name = *req.Name
+	}
+	if req.LowStockThreshold != nil {
+		ingredient.LowStockThreshold = roundQuantity(*req.LowStockThreshold)
+	}
+	if req.IsActive != nil {
+		ingredient.IsActive = *req.IsActive
+	}
+	ingredient.UpdatedAt = time.Now()
+
+	if err := s.ingredientRepo.Update(ctx, ingredient); err != nil {
+		return nil, err
+	}
+
+	return s.toIngredientResponse(ingredient), nil
+}
+
+// DeleteIngredient deletes an ingredient that no recipe uses
+func (s *InventoryService) DeleteIngredient(ctx context.Context, id string) error {
+	ingredient, err := s.ingredientRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if ingredient == nil {
+		return errors.New("ingredient not found")
+	}
+
+	count, err := s.ingredientRepo.CountRecipeUsage(ctx, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("ingredient is used in recipes")
+	}
+
+	return s.ingredientRepo.Delete(ctx, id)
+}
+
+// ListIngredients lists ingredients with pagination and filters
+func (s *InventoryService) ListIngredients(ctx context.Context, filter dto.IngredientListFilter, pagination utils.Pagination) ([]*dto.IngredientResponse, int, error) {
+	ingredients, total, err := s.ingredientRepo.List(ctx, filter, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var responses []*dto.IngredientResponse
+	for _, ingredient := range ingredients {
+		responses = append(responses, s.toIngredientResponse(ingredient))
+	}
+
+	return responses, total, nil
+}
+
+// AdjustStock records a restock, waste or manual adjustment of an ingredient's stock
+func (s *InventoryService) AdjustStock(ctx context.Context, id, userID string, req *dto.AdjustIngredientStockRequest) (*dto.IngredientResponse, error) {
+	ingredient, err := s.ingredientRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ingredient == nil {
+		return nil, errors.New("ingredient not found")
+	}
+
+	quantity := req.Quantity
+	if req.Unit != "" && req.Unit != ingredient.Unit {
+		quantity, err = models.ConvertQuantity(quantity, req.Unit, ingredient.Unit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	quantity = roundQuantity(quantity)
+
+	switch req.Type {
+	case models.MovementRestock:
+		if quantity <= 0 {
+			return nil, errors.New("restock quantity must be positive")
+		}
+	case models.MovementWaste:
+		if quantity <= 0 {
+			return nil, errors.New("waste quantity must be positive")
+		}
+		quantity = -quantity
+	case models.MovementAdjustment:
+		if quantity == 0 {
+			return nil, errors.New("adjustment quantity is too small")
+		}
+	default:
+		return nil, errors.New("invalid movement type")
+	}
+
+	if err := s.ingredientRepo.PostMovements(ctx, []*models.StockMovement{{
+		ID:           uuid.New().String(),
+		IngredientID: ingredient.ID,
+		Type:         req.Type,
+		Quantity:     quantity,
+		Note:         req.Note,
+		CreatedBy:    userID,
+		CreatedAt:    time.Now(),
+	}}); err != nil {
+		return nil, err
+	}
+
+	return s.GetIngredient(ctx, id)
+}
+
+// Movements lists an ingredient's stock movements, newest first
+func (s *InventoryService) Movements(ctx context.Context, id string, pagination utils.Pagination) ([]*dto.StockMovementResponse, int, error) {
+	ingredient, err := s.ingredientRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if ingredient == nil {
+		return nil, 0, errors.New("ingredient not found")
+	}
+
+	movements, total, err := s.ingredientRepo.ListMovements(ctx, id, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var responses []*dto.StockMovementResponse
+	for _, movement := range movements {
+		responses = append(responses, &dto.StockMovementResponse{
+			ID:             movement.ID,
+			IngredientID:   movement.IngredientID,
+			IngredientName: movement.IngredientName,
+			Type:           movement.Type,
+			Quantity:       movement.Quantity,
+			Unit:           movement.Unit,
+			BalanceAfter:   movement.BalanceAfter,
+			ReferenceID:    movement.ReferenceID,
+			Note:           movement.Note,
+			CreatedBy:      movement.CreatedBy,
+			CreatedAt:      movement.CreatedAt,
+		})
+	}
+
+	return responses, total, nil
+}
+
+// GetRecipe returns a product's recipe and how many portions current stock can make
+func (s *InventoryService) GetRecipe(ctx context.Context, productID string) (*dto.RecipeResponse, error) {
+	product, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("product not found")
+	}
+
+	recipes, err := s.recipesFor(ctx, []string{productID})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toRecipeResponse(productID, recipes[productID]), nil
+}
+
+// SetRecipe replaces a product's recipe. Each quantity must be convertible to its ingredient's unit.
+func (s *InventoryService) SetRecipe(ctx context.Context, productID string, req *dto.SetRecipeRequest) (*dto.RecipeResponse, error) {
+	product, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("product not found")
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool, len(req.Items))
+	items := make([]*models.RecipeItem, 0, len(req.Items))
+	for _, itemReq := range req.Items {
+		if seen[itemReq.IngredientID] {
+			return nil, fmt.Errorf("ingredient %s listed more than once", itemReq.IngredientID)
+		}
+		seen[itemReq.IngredientID] = true
+
+		ingredient, err := s.ingredientRepo.GetByID(ctx, itemReq.IngredientID)
+		if err != nil {
+			return nil, err
+		}
+		if ingredient == nil {
+			return nil, fmt.Errorf("ingredient %s not found", itemReq.IngredientID)
+		}
+
+		converted, err := models.ConvertQuantity(itemReq.Quantity, itemReq.Unit, ingredient.Unit)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", ingredient.Name, err)
+		}
+		if roundQuantity(converted) == 0 {
+			return nil, fmt.Errorf("%s: quantity is too small for unit %s", ingredient.Name, ingredient.Unit)
+		}
+
+		items = append(items, &models.RecipeItem{
+			ID:           uuid.New().String(),
+			ProductID:    productID,
+			IngredientID: ingredient.ID,
+			Quantity:     roundQuantity(itemReq.Quantity),
+			Unit:         itemReq.Unit,
+			CreatedAt:    now,
+		})
+	}
+
+	if err := s.ingredientRepo.ReplaceRecipe(ctx, productID, items); err != nil {
+		return nil, err
+	}
+
+	return s.GetRecipe(ctx, productID)
+}
+
+// Portions returns how many units of each product with a recipe current ingredient stock can
+// make. Products without a recipe are left out.
+func (s *InventoryService) Portions(ctx context.Context, productIDs []string) (map[string]int, error) {
+	recipes, err := s.recipesFor(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	portions := make(map[string]int, len(recipes))
+	for productID, items := range recipes {
+		portions[productID] = recipePortions(items)
+	}
+	return portions, nil
+}
+
+// recipesFor loads the recipes of the given products keyed by product ID
+func (s *InventoryService) recipesFor(ctx context.Context, productIDs []string) (map[string][]*models.RecipeItem, error) {
+	items, err := s.ingredientRepo.GetRecipes(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	recipes := make(map[string][]*models.RecipeItem)
+	for _, item := range items {
+		recipes[item.ProductID] = append(recipes[item.ProductID], item)
+	}
+	return recipes, nil
+}
+
+// saleMovements builds the ingredient deductions for the recipe items of a sale and checks
+// that every ingredient has enough stock for the whole cart.
+func (s *InventoryService) saleMovements(recipes map[string][]*models.RecipeItem, items []models.TransactionItem, transactionID, userID string, now time.Time) ([]*models.StockMovement, error) {
+	required := make(map[string]float64)
+	ingredients := make(map[string]*models.Ingredient)
+	var order []string
+
+	for _, item := range items {
+		for _, recipeItem := range recipes[item.ProductID] {
+			ingredient := recipeItem.Ingredient
+			if !ingredient.IsActive {
+				return nil, fmt.Errorf("ingredient %s for product %s is not available", ingredient.Name, item.ProductName)
+			}
+			perUnit, err := models.ConvertQuantity(recipeItem.Quantity, recipeItem.Unit, ingredient.Unit)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := ingredients[ingredient.ID]; !ok {
+				ingredients[ingredient.ID] = ingredient
+				order = append(order, ingredient.ID)
+			}
+			required[ingredient.ID] += perUnit * float64(item.Quantity)
+			if roundQuantity(required[ingredient.ID]) > ingredient.Stock {
+				return nil, fmt.Errorf("insufficient %s for product %s", ingredient.Name, item.ProductName)
+			}
+		}
+	}
+
+	movements := make([]*models.StockMovement, 0, len(order))
+	for _, ingredientID := range order {
+		movements = append(movements, &models.StockMovement{
+			ID:           uuid.New().String(),
+			IngredientID: ingredientID,
+			Type:         models.MovementSale,
+			Quantity:     -roundQuantity(required[ingredientID]),
+			ReferenceID:  &transactionID,
+			CreatedBy:    userID,
+			CreatedAt:    now,
+		})
+	}
+	return movements, nil
+}
+
+// returnMovements builds the movements that put back the ingredients deducted by a cancelled
+// or refunded sale
+func (s *InventoryService) returnMovements(ctx context.Context, transaction *models.Transaction, userID string, now time.Time) ([]*models.StockMovement, error) {
+	sold, err := s.ingredientRepo.ListMovementsByReference(ctx, transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var movements []*models.StockMovement
+	for _, movement := range sold {
+		if movement.Type != models.MovementSale {
+			continue
+		}
+		movements = append(movements, &models.StockMovement{
+			ID:           uuid.New().String(),
+			IngredientID: movement.IngredientID,
+			Type:         models.MovementReturn,
+			Quantity:     -movement.Quantity,
+			ReferenceID:  &transaction.ID,
+			Note:         fmt.Sprintf("Returned from %s", transaction.InvoiceNumber),
+			CreatedBy:    userID,
+			CreatedAt:    now,
+		})
+	}
+	return movements, nil
+}
+
+// recipePortions returns how many units of a product its ingredients' stock can make
+func recipePortions(items []*models.RecipeItem) int {
+	portions := math.MaxInt32
+	for _, item := range items {
+		if !item.Ingredient.IsActive {
+			return 0
+		}
+		perUnit, err := models.ConvertQuantity(item.Quantity, item.Unit, item.Ingredient.Unit)
+		if err != nil || perUnit <= 0 {
+			return 0
+		}
+		// The small tolerance absorbs float error when stock is an exact multiple
+		if n := int(math.Floor(item.Ingredient.Stock/perUnit + 1e-9)); n < portions {
+			portions = n
+		}
+	}
+	if portions == math.MaxInt32 {
+		return 0
+	}
+	return portions
+}
+
+// roundQuantity rounds a quantity to the precision ingredient stock is stored with
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*quantityPrecision) / quantityPrecision
+}
+
+func (s *InventoryService) toIngredientResponse(ingredient *models.Ingredient) *dto.IngredientResponse {
+	return &dto.IngredientResponse{
+		ID:                ingredient.ID,
+		SKU:               ingredient.SKU,
+		Name:              ingredient.Name,
+		Unit:              ingredient.Unit,
+		Stock:             ingredient.Stock,
+		LowStockThreshold: ingredient.LowStockThreshold,
+		LowStock:          ingredient.IsLowStock(),
+		IsActive:          ingredient.IsActive,
+		CreatedAt:         ingredient.CreatedAt,
+		UpdatedAt:         ingredient.UpdatedAt,
+	}
+}
+
+func (s *InventoryService) toRecipeResponse(productID string, items []*models.RecipeItem) *dto.RecipeResponse {
+	resp := &dto.RecipeResponse{
+		ProductID: productID,
+		Items:     []*dto.RecipeItemResponse{},
+		Portions:  recipePortions(items),
+	}
+	for _, item := range items {
+		resp.Items = append(resp.Items, &dto.RecipeItemResponse{
+			IngredientID:   item.IngredientID,
+			IngredientName: item.Ingredient.Name,
+			Quantity:       item.Quantity,
+			Unit:           item.Unit,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ilramdhan/pos-api/internal/config"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/repository"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// LicenseService verifies tenants' license keys and enforces their plan, seat and store limits.
+// Without a configured public key licensing is not enforced.
+type LicenseService struct {
+	tenantRepo repository.TenantRepository
+	userRepo   repository.UserRepository
+	storeRepo  repository.StoreRepository
+	publicKey  ed25519.PublicKey
+	enforced   bool
+}
+
+// NewLicenseService creates a new license service
+func NewLicenseService(tenantRepo repository.TenantRepository, userRepo repository.UserRepository, storeRepo repository.StoreRepository, cfg config.LicenseConfig) *LicenseService {
+	s := &LicenseService{
+		tenantRepo: tenantRepo,
+		userRepo:   userRepo,
+		storeRepo:  storeRepo,
+		enforced:   cfg.PublicKey != "",
+	}
+	if s.enforced {
+		publicKey, err := utils.ParseLicensePublicKey(cfg.PublicKey)
+		if err != nil {
+			// Keep enforcing: every license is rejected and tenants fall back to the free plan
+			log.Printf("Warning: %v; all license keys will be rejected", err)
+		}
+		s.publicKey = publicKey
+	}
+	return s
+}
+
+// licenseTerms are the limits a tenant currently works under
+type licenseTerms struct {
+	status  string
+	license *utils.License
+	plan    string
+	seats   int // 0 = unlimited
+	stores  int // 0 = unlimited
+}
+
+// Verify checks a license key before it is installed for the named business, rejecting expired
+// licenses and licenses issued to another business
+func (s *LicenseService) Verify(key, businessName string) (*utils.License, error) {
+	if !s.enforced {
+		return nil, nil
+	}
+	license, err := utils.ParseLicense(key, s.publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := models.PlanFeatures[license.Plan]; !ok {
+		return nil, fmt.Errorf("license plan %q is not supported", license.Plan)
+	}
+	if license.Expired(time.Now()) {
+		return nil, errors.New("license key has expired")
+	}
+	if !licensedTo(license, businessName) {
+		return nil, fmt.Errorf("license key is issued to %q, not to this business", license.Licensee)
+	}
+	return license, nil
+}
+
+// licensedTo reports whether a license was issued to the named business
+func licensedTo(license *utils.License, businessName string) bool {
+	return strings.EqualFold(strings.TrimSpace(license.Licensee), strings.TrimSpace(businessName))
+}
+
+// terms works out the current tenant's limits. Tenants whose license is missing, invalid or
+// expired are on the free plan.
+func (s *LicenseService) terms(ctx context.Context) (*licenseTerms, error) {
+	if !s.enforced {
+		return &licenseTerms{status: models.LicenseUnenforced}, nil
+	}
+
+	free := &licenseTerms{
+		status: models.LicenseMissing,
+		plan:   models.PlanFree,
+		seats:  models.FreePlanSeats,
+		stores: models.FreePlanStores,
+	}
+
+	tenant, err := s.tenantRepo.GetByID(ctx, utils.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil || tenant.LicenseKey == "" {
+		return free, nil
+	}
+
+	license, err := utils.ParseLicense(tenant.LicenseKey, s.publicKey)
+	if err != nil {
+		free.status = models.LicenseInvalid
+		return free, nil
+	}
+	if _, ok := models.PlanFeatures[license.Plan]; !ok || !licensedTo(license, tenant.Name) {
+		free.status = models.LicenseInvalid
+		return free, nil
+	}
+	if license.Expired(time.Now()) {
+		free.status = models.LicenseExpired
+		free.license = license
+		return free, nil
+	}
+
+	terms := &licenseTerms{
+		status:  models.LicenseActive,
+		license: license,
+		plan:    license.Plan,
+		seats:   license.Seats,
+		stores:  license.Stores,
+	}
+	if !models.PlanHasFeature(license.Plan, models.FeatureMultiStore) && (terms.stores == 0 || terms.stores > 1) {
+		terms.stores = 1
+	}
+	return terms, nil
+}
+
+// HasFeature reports whether the current tenant's plan includes a feature
+func (s *LicenseService) HasFeature(ctx context.Context, feature string) (bool, error) {
+	terms, err := s.terms(ctx)
+	if err != nil {
+		return false, err
+	}
+	if terms.status == models.LicenseUnenforced {
+		return true, nil
+	}
+	return models.PlanHasFeature(terms.plan, feature), nil
+}
+
+// CheckSeat fails if the current tenant has no license seat left for another active user
+func (s *LicenseService) CheckSeat(ctx context.Context) error {
+	terms, err := s.terms(ctx)
+	if err != nil {
+		return err
+	}
+	if terms.seats == 0 {
+		return nil
+	}
+
+	used, err := s.userRepo.CountActive(ctx)
+	if err != nil {
+		return err
+	}
+	if used >= terms.seats {
+		return fmt.Errorf("seat limit reached: your license allows %d active users", terms.seats)
+	}
+	return nil
+}
+
+// CheckStore fails if the current tenant may not open another active store
+func (s *LicenseService) CheckStore(ctx context.Context) error {
+	terms, err := s.terms(ctx)
+	if err != nil {
+		return err
+	}
+	if terms.stores == 0 {
+		return nil
+	}
+
+	used, err := s.storeRepo.CountActive(ctx)
+	if err != nil {
+		return err
+	}
+	if used >= terms.stores {
+		return fmt.Errorf("store limit reached: your license allows %d active stores", terms.stores)
+	}
+	return nil
+}
+
+// Status reports the current tenant's license and how much of it is used
+func (s *LicenseService) Status(ctx context.Context) (*dto.LicenseStatusResponse, error) {
+	terms, err := s.terms(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seatsUsed, err := s.userRepo.CountActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	storesUsed, err := s.storeRepo.CountActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.LicenseStatusResponse{
+		Status:     terms.status,
+		Enforced:   s.enforced,
+		Plan:       terms.plan,
+		Features:   models.PlanFeatures[terms.plan],
+		Seats:      terms.seats,
+		SeatsUsed:  seatsUsed,
+		Stores:     terms.stores,
+		StoresUsed: storesUsed,
+	}
+	if !s.enforced {
+		resp.Features = models.PlanFeatures[models.PlanEnterprise]
+	}
+	if resp.Features == nil {
+		resp.Features = []string{}
+	}
+	if terms.license != nil {
+		resp.Licensee = terms.license.Licensee
+		if !terms.license.ExpiresAt.IsZero() {
+			resp.ExpiresAt = &terms.license.ExpiresAt
+		}
+	}
+
+	return resp, nil
+}
+
+// Activate installs a license key for the current tenant. The key must be issued to the
+// tenant's business.
+func (s *LicenseService) Activate(ctx context.Context, req *dto.ActivateLicenseRequest) (*dto.LicenseStatusResponse, error) {
+	if !s.enforced {
+		return nil, errors.New("licensing is not enabled on this server")
+	}
+
+	tenant, err := s.tenantRepo.GetByID(ctx, utils.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return nil, errors.New("business not found")
+	}
+	if _, err := s.Verify(req.LicenseKey, tenant.Name); err != nil {
+		return nil, err
+	}
+
+	if err := s.tenantRepo.UpdateLicense(ctx, tenant.ID, req.LicenseKey, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.Status(ctx)
+}
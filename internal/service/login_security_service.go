@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/config"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/repository"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// ErrInvalidCredentials is returned for unknown emails and wrong passwords alike
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// LoginLockedError is returned while password sign-in is locked or delayed for an account or
+// IP address after failed attempts
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed sign-in attempts, try again in %d seconds", e.RetrySeconds())
+}
+
+// RetrySeconds is RetryAfter in whole seconds, rounded up
+func (e *LoginLockedError) RetrySeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// LoginSecurityService protects password sign-in against brute force. Failures are counted per
+// account (by email, so unknown emails are treated the same) and per IP address. Wrong passwords
+// for an account are delayed progressively and then lock it; too many failures from one IP
+// address lock the address. Every attempt is recorded in the sign-in audit log.
+type LoginSecurityService struct {
+	attemptRepo         repository.LoginAttemptRepository
+	eventRepo           repository.LoginEventRepository
+	userRepo            repository.UserRepository
+	notificationService *NotificationService
+	cfg                 config.LoginConfig
+	terminalCfg         config.TerminalConfig
+}
+
+// NewLoginSecurityService creates a new sign-in protection service
+func NewLoginSecurityService(
+	attemptRepo repository.LoginAttemptRepository,
+	eventRepo repository.LoginEventRepository,
+	userRepo repository.UserRepository,
+	notificationService *NotificationService,
+	cfg config.LoginConfig,
+	terminalCfg config.TerminalConfig,
+) *LoginSecurityService {
+	return &LoginSecurityService{
+		attemptRepo:         attemptRepo,
+		eventRepo:           eventRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		cfg:                 cfg,
+		terminalCfg:         terminalCfg,
+	}
+}
+
+// Check returns a LoginLockedError if sign-in is locked or delayed for the email or the IP
+// address. user is nil for emails without an account.
+func (s *LoginSecurityService) Check(ctx context.Context, user *models.User, email string, client dto.ClientInfo) error {
+	now := time.Now()
+	for _, key := range s.keys(email, client) {
+		attempt, err := s.attemptRepo.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if attempt.IsLocked(now) {
+			s.record(ctx, user, email, models.LoginBlocked, client, now)
+			return &LoginLockedError{RetryAfter: attempt.LockedUntil.Sub(now)}
+		}
+	}
+	return nil
+}
+
+// Failed counts a wrong password, or an unknown email, and returns the error to answer it with:
+// ErrInvalidCredentials, or a LoginLockedError if this failure locked the account or the IP
+// address. Later attempts are delayed from the LOGIN_DELAY_AFTER-th failure in a row, doubling
+// each time.
+func (s *LoginSecurityService) Failed(ctx context.Context, user *models.User, email string, client dto.ClientInfo) error {
+	now := time.Now()
+	s.record(ctx, user, email, models.LoginFailed, client, now)
+
+	lockout := time.Duration(s.cfg.LockoutMinutes) * time.Minute
+	emailKey := loginEmailKey(email)
+	failures, err := s.recordFailure(ctx, emailKey, now)
+	if err != nil {
+		return err
+	}
+	if client.IPAddress != "" {
+		ipKey := loginIPKey(client.IPAddress)
+		ipFailures, err := s.recordFailure(ctx, ipKey, now)
+		if err != nil {
+			return err
+		}
+		if s.cfg.IPMaxAttempts > 0 && ipFailures >= s.cfg.IPMaxAttempts {
+			if err := s.attemptRepo.Lock(ctx, ipKey, now.Add(lockout), now); err != nil {
+				return err
+			}
+			s.record(ctx, user, email, models.LoginIPLocked, client, now)
+			return &LoginLockedError{RetryAfter: lockout}
+		}
+	}
+
+	if s.cfg.MaxAttempts > 0 && failures >= s.cfg.MaxAttempts {
+		if err := s.attemptRepo.Lock(ctx, emailKey, now.Add(lockout), now); err != nil {
+			return err
+		}
+		s.record(ctx, user, email, models.LoginAccountLocked, client, now)
+		if user != nil {
+			s.notifyLocked(ctx, user, failures, client)
+		}
+		return &LoginLockedError{RetryAfter: lockout}
+	}
+
+	if s.cfg.DelayAfter > 0 && failures >= s.cfg.DelayAfter {
+		delay := lockout
+		if shift := failures - s.cfg.DelayAfter; shift < 30 {
+			if d := time.Duration(s.cfg.DelaySeconds) * time.Second << uint(shift); d < lockout {
+				delay = d
+			}
+		}
+		if err := s.attemptRepo.Delay(ctx, emailKey, now.Add(delay), now); err != nil {
+			return err
+		}
+	}
+	return ErrInvalidCredentials
+}
+
+// Challenged records a right password for a user who still has to pass the second factor; the
+// account's failures are cleared
+func (s *LoginSecurityService) Challenged(ctx context.Context, user *models.User, client dto.ClientInfo) error {
+	s.record(ctx, user, user.Email, models.LoginMFAChallenged, client, time.Now())
+	return s.attemptRepo.Reset(ctx, loginEmailKey(user.Email))
+}
+
+// Succeeded records a sign-in and clears the account's failures. Failures from the IP address
+// still count, so one valid account cannot be used to keep guessing others.
+func (s *LoginSecurityService) Succeeded(ctx context.Context, user *models.User, client dto.ClientInfo) error {
+	s.record(ctx, user, user.Email, models.LoginSucceeded, client, time.Now())
+	return s.attemptRepo.Reset(ctx, loginEmailKey(user.Email))
+}
+
+// MFAFailed records a wrong second factor; the MFA service counts and locks those itself
+func (s *LoginSecurityService) MFAFailed(ctx context.Context, user *models.User, client dto.ClientInfo) {
+	s.record(ctx, user, user.Email, models.LoginMFAFailed, client, time.Now())
+}
+
+// CheckApproval returns a LoginLockedError while the credential an approver signs an override
+// off with is locked: their PIN sign-in for PIN approvals, otherwise their password sign-in.
+// approver is nil for emails without an account.
+func (s *LoginSecurityService) CheckApproval(ctx context.Context, approver *models.User, email string, pin bool, client dto.ClientInfo) error {
+	now := time.Now()
+	key, _, _ := s.approvalKey(approver, email, pin)
+	attempt, err := s.attemptRepo.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if attempt.IsLocked(now) {
+		s.record(ctx, approver, email, models.LoginBlocked, client, now)
+		return &LoginLockedError{RetryAfter: attempt.LockedUntil.Sub(now)}
+	}
+	return nil
+}
+
+// ApprovalFailed records a wrong approver password or PIN and counts it as a failed sign-in
+// with that credential, so overrides cannot be used to guess it. It returns a LoginLockedError
+// if this failure locked the approver's sign-in.
+func (s *LoginSecurityService) ApprovalFailed(ctx context.Context, approver *models.User, email string, pin bool, client dto.ClientInfo) error {
+	now := time.Now()
+	s.record(ctx, approver, email, models.LoginApprovalFailed, client, now)
+
+	key, maxAttempts, lockout := s.approvalKey(approver, email, pin)
+	failures, err := s.recordFailure(ctx, key, now)
+	if err != nil {
+		return err
+	}
+	if maxAttempts > 0 && failures >= maxAttempts {
+		if err := s.attemptRepo.Lock(ctx, key, now.Add(lockout), now); err != nil {
+			return err
+		}
+		s.record(ctx, approver, email, models.LoginAccountLocked, client, now)
+		if approver != nil {
+			s.notifyLocked(ctx, approver, failures, client)
+		}
+		return &LoginLockedError{RetryAfter: lockout}
+	}
+	return nil
+}
+
+// ApprovalSucceeded clears the failures of the credential an approver signed an override off with
+func (s *LoginSecurityService) ApprovalSucceeded(ctx context.Context, approver *models.User, pin bool) error {
+	key, _, _ := s.approvalKey(approver, approver.Email, pin)
+	return s.attemptRepo.Reset(ctx, key)
+}
+
+// Unlock lifts the locks and delays of a user's password, PIN and second factor sign-ins
+func (s *LoginSecurityService) Unlock(ctx context.Context, userID string, client dto.ClientInfo) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	for _, key := range []string{loginEmailKey(user.Email), pinUserKey(user.ID), "mfa:user:" + user.ID} {
+		if err := s.attemptRepo.Reset(ctx, key); err != nil {
+			return err
+		}
+	}
+	s.record(ctx, user, user.Email, models.LoginAccountUnlocked, client, time.Now())
+	return nil
+}
+
+// ListEvents lists the tenant's sign-in audit log
+func (s *LoginSecurityService) ListEvents(ctx context.Context, filter dto.LoginEventFilter, pagination utils.Pagination) ([]*dto.LoginEventResponse, int, error) {
+	events, total, err := s.eventRepo.List(ctx, filter, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := []*dto.LoginEventResponse{}
+	for _, event := range events {
+		result = append(result, &dto.LoginEventResponse{
+			ID:        event.ID,
+			UserID:    event.UserID,
+			Email:     event.Email,
+			Event:     event.Event,
+			IPAddress: event.IPAddress,
+			UserAgent: event.UserAgent,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+	return result, total, nil
+}
+
+// keys returns the attempt keys a sign-in counts against
+func (s *LoginSecurityService) keys(email string, client dto.ClientInfo) []string {
+	keys := []string{loginEmailKey(email)}
+	if client.IPAddress != "" {
+		keys = append(keys, loginIPKey(client.IPAddress))
+	}
+	return keys
+}
+
+// approvalKey returns the attempt key an approval counts against, with the failures that lock it
+// and for how long. PINs share the PIN sign-in's key and limit, passwords the password sign-in's.
+func (s *LoginSecurityService) approvalKey(approver *models.User, email string, pin bool) (string, int, time.Duration) {
+	if pin && approver != nil {
+		return pinUserKey(approver.ID), s.terminalCfg.PINMaxAttempts, time.Duration(s.terminalCfg.LockoutMinutes) * time.Minute
+	}
+	return loginEmailKey(email), s.cfg.MaxAttempts, time.Duration(s.cfg.LockoutMinutes) * time.Minute
+}
+
+// recordFailure counts a failure for the key. Failures older than the lockout are forgotten,
+// so occasional typos over days never add up to a lock.
+func (s *LoginSecurityService) recordFailure(ctx context.Context, key string, now time.Time) (int, error) {
+	attempt, err := s.attemptRepo.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	window := time.Duration(s.cfg.LockoutMinutes) * time.Minute
+	if attempt != nil && !attempt.IsLocked(now) && now.Sub(attempt.UpdatedAt) > window {
+		if err := s.attemptRepo.Reset(ctx, key); err != nil {
+			return 0, err
+		}
+	}
+	return s.attemptRepo.RecordFailure(ctx, key, now)
+}
+
+// record writes an event to the audit log. Sign-in must not fail because of the log, so errors
+// are only logged.
+func (s *LoginSecurityService) record(ctx context.Context, user *models.User, email, event string, client dto.ClientInfo, now time.Time) {
+	entry := &models.LoginEvent{
+		ID:        uuid.New().String(),
+		Email:     strings.ToLower(strings.TrimSpace(email)),
+		Event:     event,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		CreatedAt: now,
+	}
+	if user != nil {
+		entry.TenantID = user.TenantID
+		entry.UserID = user.ID
+	}
+	if err := s.eventRepo.Create(ctx, entry); err != nil {
+		log.Printf("Failed to record login event %s for %s: %v", event, entry.Email, err)
+	}
+}
+
+// notifyLocked tells the user and their tenant's admins that the account was locked
+func (s *LoginSecurityService) notifyLocked(ctx context.Context, user *models.User, failures int, client dto.ClientInfo) {
+	ctx = utils.WithTenant(ctx, user.TenantID)
+	pagination := utils.DefaultPagination
+	pagination.PerPage = utils.MaxPerPage
+	admins, _, err := s.userRepo.List(ctx, models.RoleAdmin, "", pagination)
+	if err != nil {
+		log.Printf("Failed to list admins of tenant %s: %v", user.TenantID, err)
+	}
+
+	recipients := []string{user.ID}
+	for _, admin := range admins {
+		if admin.ID != user.ID && admin.IsActive {
+			recipients = append(recipients, admin.ID)
+		}
+	}
+
+	message := fmt.Sprintf("%s was locked after %d failed sign-in attempts from %s. It unlocks in %d minutes, or an admin can unlock it now.",
+		user.Email, failures, client.IPAddress, s.cfg.LockoutMinutes)
+	if err := s.notificationService.Notify(ctx, recipients, models.NotificationSecurity, "Account locked", message, "/users/"+user.ID); err != nil {
+		log.Printf("Failed to notify about locked user %s: %v", user.ID, err)
+	}
+}
+
+func loginEmailKey(email string) string {
+	return "login:email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func loginIPKey(ip string) string {
+	return "login:ip:" + ip
+}
+
+func pinUserKey(userID string) string {
+	return "pin:user:" + userID
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/config"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/repository"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// loyaltyExpiryNoticeDays is how far ahead the summary reports points about to expire
+const loyaltyExpiryNoticeDays = 30
+
+// LoyaltyService handles loyalty program operations
+type LoyaltyService struct {
+	loyaltyRepo  repository.LoyaltyRepository
+	customerRepo repository.CustomerRepository
+	rules        config.LoyaltyConfig
+}
+
+// NewLoyaltyService creates a new loyalty service
+func NewLoyaltyService(
+	loyaltyRepo repository.LoyaltyRepository,
+	customerRepo repository.CustomerRepository,
+	rules config.LoyaltyConfig,
+) *LoyaltyService {
+	return &LoyaltyService{
+		loyaltyRepo:  loyaltyRepo,
+		customerRepo: customerRepo,
+		rules:        rules,
+	}
+}
+
+// Rules returns the configured earn and redeem rules
+func (s *LoyaltyService) Rules() *dto.LoyaltyRulesResponse {
+	return &dto.LoyaltyRulesResponse{
+		EarnAmount: s.rules.EarnAmount,
+		EarnPoints: s.rules.EarnPoints,
+		PointValue: s.rules.PointValue,
+		ExpiryDays: s.rules.ExpiryDays,
+		Tiers: []dto.LoyaltyTierResponse{
+			{Tier: models.TierMember, MinSpend: 0, Multiplier: 1},
+			{Tier: models.TierSilver, MinSpend: s.rules.SilverThreshold, Multiplier: s.rules.SilverMultiplier},
+			{Tier: models.TierGold, MinSpend: s.rules.GoldThreshold, Multiplier: s.rules.GoldMultiplier},
+		},
+	}
+}
+
+// Summary returns a customer's balance, tier and points about to expire
+func (s *LoyaltyService) Summary(ctx context.Context, customerID string) (*dto.LoyaltySummaryResponse, error) {
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, errors.New("customer not found")
+	}
+
+	spend, err := s.loyaltyRepo.GetLifetimeSpend(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	expiringBefore := time.Now().AddDate(0, 0, loyaltyExpiryNoticeDays)
+	expiring, err := s.loyaltyRepo.GetExpiringPoints(ctx, customerID, expiringBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	tier, multiplier := s.tierFor(spend)
+	resp := &dto.LoyaltySummaryResponse{
+		CustomerID:     customer.ID,
+		Balance:        customer.LoyaltyPoints,
+		BalanceValue:   float64(customer.LoyaltyPoints) * s.rules.PointValue,
+		Tier:           tier,
+		Multiplier:     multiplier,
+		LifetimeSpend:  spend,
+		ExpiringPoints: expiring,
+	}
+	if expiring > 0 {
+		resp.ExpiringBefore = &expiringBefore
+	}
+
+	switch tier {
+	case models.TierMember:
+		resp.NextTier = models.TierSilver
+		resp.SpendToNextTier = s.rules.SilverThreshold - spend
+	case models.TierSilver:
+		resp.NextTier = models.TierGold
+		resp.SpendToNextTier = s.rules.GoldThreshold - spend
+	}
+
+	return resp, nil
+}
+
+// Ledger lists a customer's loyalty ledger entries, newest first
+func (s *LoyaltyService) Ledger(ctx context.Context, customerID string, pagination utils.Pagination) ([]*dto.LoyaltyLedgerEntryResponse, int, error) {
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if customer == nil {
+		return nil, 0, errors.New("customer not found")
+	}
+
+	entries, total, err := s.loyaltyRepo.ListByCustomer(ctx, customerID, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*dto.LoyaltyLedgerEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, &dto.LoyaltyLedgerEntryResponse{
+			ID:            entry.ID,
+			TransactionID: entry.TransactionID,
+			Type:          entry.Type,
+			Points:        entry.Points,
+			BalanceAfter:  entry.BalanceAfter,
+			ExpiresAt:     entry.ExpiresAt,
+			Note:          entry.Note,
+			CreatedBy:     entry.CreatedBy,
+			CreatedAt:     entry.CreatedAt,
+		})
+	}
+
+	return responses, total, nil
+}
+
+// Adjust manually adds or deducts points and records the adjustment in the ledger
+func (s *LoyaltyService) Adjust(ctx context.Context, customerID, userID string, req *dto.UpdateLoyaltyPointsRequest) (*dto.LoyaltySummaryResponse, error) {
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, errors.New("customer not found")
+	}
+
+	now := time.Now()
+	entry := &models.LoyaltyLedgerEntry{
+		ID:         uuid.New().String(),
+		CustomerID: customerID,
+		Type:       models.LoyaltyAdjust,
+		Note:       req.Note,
+		CreatedBy:  userID,
+		CreatedAt:  now,
+	}
+
+	switch req.Operation {
+	case "add":
+		entry.Points = req.Points
+		entry.ExpiresAt = s.expiryFrom(now)
+	case "deduct":
+		if !customer.DeductLoyaltyPoints(req.Points) {
+			return nil, repository.ErrInsufficientPoints
+		}
+		entry.Points = -req.Points
+	default:
+		return nil, errors.New("invalid operation")
+	}
+
+	if err := s.loyaltyRepo.Post(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return s.Summary(ctx, customerID)
+}
+
+// ExpirePoints expires all points past their expiry date and returns how many were removed
+func (s *LoyaltyService) ExpirePoints(ctx context.Context) (int, error) {
+	return s.loyaltyRepo.ExpirePoints(ctx, time.Now())
+}
+
+// StartExpiryJob starts a background goroutine that periodically expires points
+func (s *LoyaltyService) StartExpiryJob(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			expired, err := s.ExpirePoints(context.Background())
+			if err != nil {
+				log.Printf("Loyalty point expiry failed: %v", err)
+				continue
+			}
+			if expired > 0 {
+				log.Printf("Expired %d loyalty points", expired)
+			}
+		}
+	}()
+}
+
+// redeemEntry prices a point redemption against the amount due and builds its ledger entry.
+// The balance is checked again under a row lock when the entry is posted.
+func (s *LoyaltyService) redeemEntry(ctx context.Context, customerID *string, points int, amountDue float64, transactionID, userID string, now time.Time) (*models.LoyaltyLedgerEntry, float64, error) {
+	if customerID == nil || *customerID == "" {
+		return nil, 0, errors.New("redeeming points requires a customer")
+	}
+	if s.rules.PointValue <= 0 {
+		return nil, 0, errors.New("point redemption is disabled")
+	}
+
+	customer, err := s.customerRepo.GetByID(ctx, *customerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if customer == nil {
+		return nil, 0, errors.New("customer not found")
+	}
+	if !customer.DeductLoyaltyPoints(points) {
+		return nil, 0, repository.ErrInsufficientPoints
+	}
+
+	value := float64(points) * s.rules.PointValue
+	if value > amountDue {
+		return nil, 0, fmt.Errorf("redeemed points (%.2f) exceed the amount due (%.2f)", value, amountDue)
+	}
+
+	return &models.LoyaltyLedgerEntry{
+		ID:            uuid.New().String(),
+		CustomerID:    *customerID,
+		TransactionID: &transactionID,
+		Type:          models.LoyaltyRedeem,
+		Points:        -points,
+		CreatedBy:     userID,
+		CreatedAt:     now,
+	}, value, nil
+}
+
+// earnEntry calculates points earned on the amount paid at the customer's current tier.
+// It returns nil when the purchase earns no points.
+func (s *LoyaltyService) earnEntry(ctx context.Context, customerID string, amountPaid float64, transactionID, userID string, now time.Time) (*models.LoyaltyLedgerEntry, error) {
+	if s.rules.EarnAmount <= 0 || s.rules.EarnPoints <= 0 || amountPaid <= 0 {
+		return nil, nil
+	}
+
+	spend, err := s.loyaltyRepo.GetLifetimeSpend(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	_, multiplier := s.tierFor(spend)
+
+	units := math.Floor(amountPaid / s.rules.EarnAmount)
+	points := int(math.Floor(units * float64(s.rules.EarnPoints) * multiplier))
+	if points <= 0 {
+		return nil, nil
+	}
+
+	return &models.LoyaltyLedgerEntry{
+		ID:            uuid.New().String(),
+		CustomerID:    customerID,
+		TransactionID: &transactionID,
+		Type:          models.LoyaltyEarn,
+		Points:        points,
+		ExpiresAt:     s.expiryFrom(now),
+		CreatedBy:     userID,
+		CreatedAt:     now,
+	}, nil
+}
+
+// reversalEntries returns the ledger entries that give back redeemed points and take back
+// earned points for a cancelled or refunded transaction. Earned points already spent are not
+// clawed back. The entries are posted with the status change.
+func (s *LoyaltyService) reversalEntries(ctx context.Context, transaction *models.Transaction) ([]*models.LoyaltyLedgerEntry, error) {
+	if transaction.CustomerID == nil || *transaction.CustomerID == "" {
+		return nil, nil
+	}
+
+	entries, err := s.loyaltyRepo.ListByTransaction(ctx, transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var earned, redeemed int
+	for _, entry := range entries {
+		switch entry.Type {
+		case models.LoyaltyEarn:
+			earned += entry.Points
+		case models.LoyaltyRedeem:
+			redeemed -= entry.Points
+		}
+	}
+
+	now := time.Now()
+	var reversal []*models.LoyaltyLedgerEntry
+	if redeemed > 0 {
+		reversal = append(reversal, &models.LoyaltyLedgerEntry{
+			ID:            uuid.New().String(),
+			CustomerID:    *transaction.CustomerID,
+			TransactionID: &transaction.ID,
+			Type:          models.LoyaltyAdjust,
+			Points:        redeemed,
+			ExpiresAt:     s.expiryFrom(now),
+			Note:          fmt.Sprintf("Points returned for %s", transaction.InvoiceNumber),
+			CreatedAt:     now,
+		})
+	}
+
+	if earned > 0 {
+		customer, err := s.customerRepo.GetByID(ctx, *transaction.CustomerID)
+		if err != nil || customer == nil {
+			return nil, err
+		}
+		// The returned points are posted first
+		if balance := customer.LoyaltyPoints + redeemed; earned > balance {
+			earned = balance
+		}
+		if earned > 0 {
+			reversal = append(reversal, &models.LoyaltyLedgerEntry{
+				ID:            uuid.New().String(),
+				CustomerID:    *transaction.CustomerID,
+				TransactionID: &transaction.ID,
+				Type:          models.LoyaltyAdjust,
+				Points:        -earned,
+				Note:          fmt.Sprintf("Earned points reversed for %s", transaction.InvoiceNumber),
+				CreatedAt:     now,
+			})
+		}
+	}
+
+	return reversal, nil
+}
+
+// tierFor returns the tier and earn multiplier reached by a lifetime spend
+func (s *LoyaltyService) tierFor(spend float64) (string, float64) {
+	switch {
+	case s.rules.GoldThreshold > 0 && spend >= s.rules.GoldThreshold:
+		return models.TierGold, s.rules.GoldMultiplier
+	case s.rules.SilverThreshold > 0 && spend >= s.rules.SilverThreshold:
+		return models.TierSilver, s.rules.SilverMultiplier
+	default:
+		return models.TierMember, 1
+	}
+}
+
+func (s *LoyaltyService) expiryFrom(now time.Time) *time.Time {
+	if s.rules.ExpiryDays <= 0 {
+		return nil
+	}
+	expiresAt := now.AddDate(0, 0, s.rules.ExpiryDays)
+	return &expiresAt
+}
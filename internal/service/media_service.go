@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/ilramdhan/pos-api/internal/config"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/repository"
+	"github.com/ilramdhan/pos-api/internal/storage"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+const (
+	// thumbnailSize is the bounding box, in pixels, of generated thumbnails
+	thumbnailSize = 320
+	// maxImagePixels rejects images that would take excessive memory to decode
+	maxImagePixels = 40_000_000
+)
+
+var (
+	// ErrImageTooLarge is returned when an upload exceeds the size or dimension limits
+	ErrImageTooLarge = errors.New("image is too large")
+	// ErrUnsupportedImage is returned when an upload is not a JPEG, PNG or GIF image
+	ErrUnsupportedImage = errors.New("unsupported image type; upload a JPEG, PNG or GIF")
+)
+
+// imageExtensions maps accepted (sniffed) content types to stored file extensions
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// MediaService handles uploaded files such as product images
+type MediaService struct {
+	productRepo  repository.ProductRepository
+	store        storage.BlobStore
+	publicURL    string
+	maxImageSize int64
+}
+
+// NewMediaService creates a new media service
+func NewMediaService(productRepo repository.ProductRepository, store storage.BlobStore, cfg config.StorageConfig) *MediaService {
+	return &MediaService{
+		productRepo:  productRepo,
+		store:        store,
+		publicURL:    cfg.PublicURL,
+		maxImageSize: cfg.MaxImageSize,
+	}
+}
+
+// MaxImageSize returns the maximum accepted image upload in bytes
+func (s *MediaService) MaxImageSize() int64 {
+	return s.maxImageSize
+}
+
+// UploadProductImage stores a product image and its thumbnail and points the product at them.
+// Objects are keyed by content hash, so their URLs can be cached indefinitely.
+func (s *MediaService) UploadProductImage(ctx context.Context, productID string, r io.Reader) (*dto.ProductImageResponse, error) {
+	product, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("product not found")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, s.maxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxImageSize {
+		return nil, fmt.Errorf("%w; the limit is %d MB", ErrImageTooLarge, s.maxImageSize>>20)
+	}
+	if len(data) == 0 {
+		return nil, errors.New("image file is empty")
+	}
+
+	// Trust the bytes, not the client-supplied content type or file name
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedImage
+	}
+
+	imgConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if imgConfig.Width*imgConfig.Height > maxImagePixels {
+		return nil, fmt.Errorf("%w; images may have at most %d megapixels", ErrImageTooLarge, maxImagePixels/1_000_000)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("image could not be decoded")
+	}
+
+	// JPEG thumbnails for photos; PNG keeps transparency for PNG and GIF sources
+	var thumb bytes.Buffer
+	thumbExt, thumbType := ".jpg", "image/jpeg"
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&thumb, utils.Thumbnail(img, thumbnailSize), &jpeg.Options{Quality: 85})
+	} else {
+		thumbExt, thumbType = ".png", "image/png"
+		err = png.Encode(&thumb, utils.Thumbnail(img, thumbnailSize))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:8])
+	imageKey := fmt.Sprintf("products/%s/%s%s", product.ID, hash, ext)
+	thumbKey := fmt.Sprintf("products/%s/%s_thumb%s", product.ID, hash, thumbExt)
+
+	if err := s.store.Put(ctx, imageKey, data, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store image: %w", err)
+	}
+	if err := s.store.Put(ctx, thumbKey, thumb.Bytes(), thumbType); err != nil {
+		return nil, fmt.Errorf("failed to store thumbnail: %w", err)
+	}
+
+	imageURL, thumbURL := s.url(imageKey), s.url(thumbKey)
+	if err := s.productRepo.UpdateImage(ctx, product.ID, imageURL, thumbURL); err != nil {
+		return nil, err
+	}
+
+	// Re-uploading the same file yields the same keys, which must not be removed
+	if product.ImageURL != imageURL {
+		s.removeObject(ctx, product.ImageURL)
+	}
+	if product.ThumbnailURL != thumbURL {
+		s.removeObject(ctx, product.ThumbnailURL)
+	}
+
+	return &dto.ProductImageResponse{
+		ImageURL:     imageURL,
+		ThumbnailURL: thumbURL,
+		ContentType:  contentType,
+		Size:         len(data),
+		Width:        imgConfig.Width,
+		Height:       imgConfig.Height,
+	}, nil
+}
+
+// DeleteProductImage clears a product's image and removes the stored objects
+func (s *MediaService) DeleteProductImage(ctx context.Context, productID string) error {
+	product, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return errors.New("product not found")
+	}
+
+	if err := s.productRepo.UpdateImage(ctx, product.ID, "", ""); err != nil {
+		return err
+	}
+	s.removeObject(ctx, product.ImageURL)
+	s.removeObject(ctx, product.ThumbnailURL)
+	return nil
+}
+
+// Get returns a stored object for serving; invalid keys are reported as not found
+func (s *MediaService) Get(ctx context.Context, key string) (*storage.Object, error) {
+	key, err := storage.CleanKey(key)
+	if err != nil {
+		return nil, storage.ErrNotFound
+	}
+	return s.store.Get(ctx, key)
+}
+
+func (s *MediaService) url(key string) string {
+	return s.publicURL + "/" + key
+}
+
+// removeObject deletes the object behind a URL if it lives in this store. Failures are
+// only logged: the product already points at its new image.
+func (s *MediaService) removeObject(ctx context.Context, url string) {
+	key, ok := strings.CutPrefix(url, s.publicURL+"/")
+	if url == "" || !ok {
+		return
+	}
+	if err := s.store.Delete(ctx, key); err != nil {
+		log.Printf("Failed to delete stored object %s: %v", key, err)
+	}
+}
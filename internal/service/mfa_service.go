@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ilramdhan/pos-api/internal/config"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/repository"
+	"github.com/ilramdhan/pos-api/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// mfaRecoveryCodeCount is how many recovery codes are issued at a time
+const mfaRecoveryCodeCount = 10
+
+var (
+	// ErrInvalidMFACode is returned for authenticator and recovery codes that do not match
+	ErrInvalidMFACode = errors.New("invalid authentication code")
+	// ErrInvalidMFAToken is returned for sign-in challenge tokens that are invalid or expired
+	ErrInvalidMFAToken = errors.New("invalid or expired MFA token")
+	// ErrMFALocked is returned while a user's second factor is locked after too many wrong codes
+	ErrMFALocked = errors.New("too many invalid codes, try again later")
+)
+
+// recoveryCodeEncoding spells recovery codes in lowercase letters and digits
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// MFAService enrolls users in TOTP two-factor authentication and checks their codes
+type MFAService struct {
+	userRepo     repository.UserRepository
+	recoveryRepo repository.MFARecoveryCodeRepository
+	attemptRepo  repository.LoginAttemptRepository
+	roleService  *RoleService
+	cfg          config.MFAConfig
+}
+
+// NewMFAService creates a new two-factor authentication service
+func NewMFAService(
+	userRepo repository.UserRepository,
+	recoveryRepo repository.MFARecoveryCodeRepository,
+	attemptRepo repository.LoginAttemptRepository,
+	roleService *RoleService,
+	cfg config.MFAConfig,
+) *MFAService {
+	return &MFAService{
+		userRepo:     userRepo,
+		recoveryRepo: recoveryRepo,
+		attemptRepo:  attemptRepo,
+		roleService:  roleService,
+		cfg:          cfg,
+	}
+}
+
+// ChallengeDuration is how long a sign-in waits for its second factor
+func (s *MFAService) ChallengeDuration() time.Duration {
+	return time.Duration(s.cfg.ChallengeMinutes) * time.Minute
+}
+
+// Required reports whether the user's role requires two-factor authentication
+func (s *MFAService) Required(ctx context.Context, user *models.User) (bool, error) {
+	return s.roleService.RequiresMFA(ctx, user.Role)
+}
+
+// AllowsPIN returns ErrPINNotAllowed if the user has two-factor authentication or their role
+// requires it, since a PIN would let them sign in with a single factor
+func (s *MFAService) AllowsPIN(ctx context.Context, user *models.User) error {
+	if user.TOTPEnabled {
+		return ErrPINNotAllowed
+	}
+	required, err := s.Required(ctx, user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrPINNotAllowed
+	}
+	return nil
+}
+
+// Status describes a user's two-factor authentication
+func (s *MFAService) Status(ctx context.Context, userID string) (*dto.MFAStatusResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	required, err := s.Required(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	left := 0
+	if user.TOTPEnabled {
+		if left, err = s.recoveryRepo.CountUnused(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dto.MFAStatusResponse{Enabled: user.TOTPEnabled, Required: required, RecoveryCodesLeft: left}, nil
+}
+
+// Setup starts enrollment with a new secret. It replaces any secret from an enrollment that was
+// never confirmed; two-factor authentication stays off until Enable.
+func (s *MFAService) Setup(ctx context.Context, userID string) (*dto.MFASetupResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.SetTOTP(ctx, user.ID, secret, false, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return &dto.MFASetupResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(s.cfg.Issuer, user.Email, secret),
+	}, nil
+}
+
+// Enable confirms enrollment with a code from the authenticator app, turns two-factor
+// authentication on and issues recovery codes
+func (s *MFAService) Enable(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, errors.New("set up two-factor authentication first")
+	}
+
+	if err := s.Verify(ctx, user, code, ""); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.userRepo.SetTOTP(ctx, user.ID, user.TOTPSecret, true, now); err != nil {
+		return nil, err
+	}
+	// The enrolling code counts as used
+	if step, ok := utils.MatchTOTP(user.TOTPSecret, code, now); ok {
+		if _, err := s.userRepo.UseTOTPStep(ctx, user.ID, step); err != nil {
+			return nil, err
+		}
+	}
+	return s.issueRecoveryCodes(ctx, user.ID, now)
+}
+
+// Disable turns the current user's two-factor authentication off after checking their password
+// and a code. Users whose role requires two-factor authentication cannot turn it off.
+func (s *MFAService) Disable(ctx context.Context, userID string, req *dto.DisableMFARequest) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)) != nil {
+		return errors.New("current password is incorrect")
+	}
+	required, err := s.Required(ctx, user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return errors.New("your role requires two-factor authentication")
+	}
+
+	if err := s.Verify(ctx, user, req.Code, req.RecoveryCode); err != nil {
+		return err
+	}
+	return s.clear(ctx, user.ID)
+}
+
+// RegenerateRecoveryCodes replaces the current user's recovery codes after checking a code
+// from their authenticator app
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := s.Verify(ctx, user, code, ""); err != nil {
+		return nil, err
+	}
+	return s.issueRecoveryCodes(ctx, user.ID, time.Now())
+}
+
+// Reset turns a user's two-factor authentication off for them, such as when they lost their
+// authenticator. If their role requires it, they enroll again at their next sign-in.
+func (s *MFAService) Reset(ctx context.Context, userID string) error {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return err
+	}
+	return s.clear(ctx, userID)
+}
+
+// Verify checks a user's second factor: a code from their authenticator app, which cannot be
+// used twice, or one of their recovery codes. Wrong codes count towards locking it.
+func (s *MFAService) Verify(ctx context.Context, user *models.User, code, recoveryCode string) error {
+	if code == "" && recoveryCode == "" {
+		return errors.New("an authentication code or recovery code is required")
+	}
+
+	now := time.Now()
+	key := "mfa:user:" + user.ID
+	attempt, err := s.attemptRepo.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if attempt.IsLocked(now) {
+		return ErrMFALocked
+	}
+
+	ok := false
+	if code != "" {
+		step, matched := utils.MatchTOTP(user.TOTPSecret, code, now)
+		// Enrollment has not recorded any code yet, so nothing can be replayed
+		if matched && user.TOTPEnabled {
+			matched, err = s.userRepo.UseTOTPStep(ctx, user.ID, step)
+			if err != nil {
+				return err
+			}
+		}
+		ok = matched
+	} else if user.TOTPEnabled {
+		ok, err = s.recoveryRepo.Use(ctx, user.ID, hashSecretToken(normalizeRecoveryCode(recoveryCode)), now)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !ok {
+		failures, err := s.attemptRepo.RecordFailure(ctx, key, now)
+		if err != nil {
+			return err
+		}
+		if s.cfg.MaxAttempts > 0 && failures >= s.cfg.MaxAttempts {
+			lockout := time.Duration(s.cfg.LockoutMinutes) * time.Minute
+			if err := s.attemptRepo.Lock(ctx, key, now.Add(lockout), now); err != nil {
+				return err
+			}
+		}
+		return ErrInvalidMFACode
+	}
+	if attempt != nil {
+		return s.attemptRepo.Reset(ctx, key)
+	}
+	return nil
+}
+
+// issueRecoveryCodes replaces the user's recovery codes with new ones and returns them
+func (s *MFAService) issueRecoveryCodes(ctx context.Context, userID string, now time.Time) ([]string, error) {
+	codes := make([]string, mfaRecoveryCodeCount)
+	hashes := make([]string, mfaRecoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		code := recoveryCodeEncoding.EncodeToString(raw)
+		codes[i] = code[:4] + "-" + code[4:]
+		hashes[i] = hashSecretToken(code)
+	}
+
+	if err := s.recoveryRepo.Replace(ctx, userID, hashes, now); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// clear turns two-factor authentication off and drops the secret and recovery codes
+func (s *MFAService) clear(ctx context.Context, userID string) error {
+	now := time.Now()
+	if err := s.userRepo.SetTOTP(ctx, userID, "", false, now); err != nil {
+		return err
+	}
+	return s.recoveryRepo.Replace(ctx, userID, nil, now)
+}
+
+func (s *MFAService) getUser(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+// normalizeRecoveryCode accepts recovery codes typed with any case, spaces or dashes
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/repository"
+)
+
+// notificationListLimit is how many notifications a user sees at most
+const notificationListLimit = 50
+
+// NotificationService handles users' in-app notifications
+type NotificationService struct {
+	notificationRepo repository.NotificationRepository
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(notificationRepo repository.NotificationRepository) *NotificationService {
+	return &NotificationService{notificationRepo: notificationRepo}
+}
+
+// Notify sends the same notification to each of the users
+func (s *NotificationService) Notify(ctx context.Context, userIDs []string, notificationType, title, message, actionURL string) error {
+	now := time.Now()
+	for _, userID := range userIDs {
+		notification := &models.Notification{
+			ID:        uuid.New().String(),
+			UserID:    userID,
+			Type:      notificationType,
+			Title:     title,
+			Message:   message,
+			ActionURL: actionURL,
+			CreatedAt: now,
+		}
+		if err := s.notificationRepo.Create(ctx, notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns the user's newest notifications and how many of all of them are unread
+func (s *NotificationService) List(ctx context.Context, userID string, unreadOnly bool) ([]*models.Notification, int, error) {
+	notifications, err := s.notificationRepo.List(ctx, userID, unreadOnly, notificationListLimit)
+	if err != nil {
+		return nil, 0, err
+	}
+	unread, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if notifications == nil {
+		notifications = []*models.Notification{}
+	}
+	return notifications, unread, nil
+}
+
+// MarkRead marks one of the user's notifications as read
+func (s *NotificationService) MarkRead(ctx context.Context, id, userID string) error {
+	return s.notificationRepo.MarkRead(ctx, id, userID)
+}
+
+// MarkAllRead marks all of the user's notifications as read
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID string) error {
+	return s.notificationRepo.MarkAllRead(ctx, userID)
+}
+
+// Delete deletes one of the user's notifications
+func (s *NotificationService) Delete(ctx context.Context, id, userID string) error {
+	return s.notificationRepo.Delete(ctx, id, userID)
+}
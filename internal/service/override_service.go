@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/repository"
+	"github.com/ilramdhan/pos-api/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrOverrideRequired is returned when an action needs a manager's approval
+	ErrOverrideRequired = errors.New("manager approval required")
+	// ErrInvalidOverride is returned for override tokens that cannot be used for an action
+	ErrInvalidOverride = repository.ErrInvalidOverride
+	// ErrInvalidApprover is returned for unknown approvers and wrong approver passwords or PINs
+	ErrInvalidApprover = errors.New("invalid approver credentials")
+)
+
+// OverrideService issues manager approvals for sensitive actions and checks them when the
+// actions are performed
+type OverrideService struct {
+	overrideRepo  repository.OverrideRepository
+	userRepo      repository.UserRepository
+	roleService   *RoleService
+	loginSecurity *LoginSecurityService
+}
+
+// NewOverrideService creates a new override service
+func NewOverrideService(overrideRepo repository.OverrideRepository, userRepo repository.UserRepository, roleService *RoleService, loginSecurity *LoginSecurityService) *OverrideService {
+	return &OverrideService{
+		overrideRepo:  overrideRepo,
+		userRepo:      userRepo,
+		roleService:   roleService,
+		loginSecurity: loginSecurity,
+	}
+}
+
+// newSecretToken generates a random token, such as an override token or a terminal key
+func newSecretToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// hashSecretToken hashes a token for storage; tokens are random, so a fast hash suffices
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Approve checks the approver's password or PIN and permission, and issues a single-use token
+// for the requesting user on their terminal. Wrong passwords and PINs count towards locking the
+// approver's sign-in with them, and are refused while it is locked.
+func (s *OverrideService) Approve(ctx context.Context, requestedBy, terminalID string, req *dto.CreateOverrideRequest, client dto.ClientInfo) (*dto.OverrideResponse, error) {
+	permission := models.OverridePermissions[req.Action]
+	if models.OverrideNeedsAmount(req.Action) && req.Amount == nil {
+		return nil, errors.New("amount is required to approve a discount or price override")
+	}
+	if !models.OverrideNeedsAmount(req.Action) && req.Amount != nil {
+		return nil, errors.New("amount can only be approved for a discount or price override")
+	}
+
+	approver, err := s.userRepo.GetByEmail(ctx, req.ApproverEmail)
+	if err != nil {
+		return nil, err
+	}
+	if approver != nil && approver.TenantID != utils.TenantID(ctx) {
+		approver = nil
+	}
+
+	usePIN := req.PIN != ""
+	if err := s.loginSecurity.CheckApproval(ctx, approver, req.ApproverEmail, usePIN, client); err != nil {
+		return nil, err
+	}
+
+	valid := approver != nil && approver.IsActive
+	if valid && usePIN {
+		valid = approver.PINHash != "" && bcrypt.CompareHashAndPassword([]byte(approver.PINHash), []byte(req.PIN)) == nil
+	} else if valid {
+		valid = bcrypt.CompareHashAndPassword([]byte(approver.PasswordHash), []byte(req.Password)) == nil
+	}
+	if !valid {
+		if err := s.loginSecurity.ApprovalFailed(ctx, approver, req.ApproverEmail, usePIN, client); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidApprover
+	}
+	if err := s.loginSecurity.ApprovalSucceeded(ctx, approver, usePIN); err != nil {
+		return nil, err
+	}
+	if approver.ID == requestedBy {
+		return nil, errors.New("another user must approve the action")
+	}
+
+	allowed, err := s.roleService.HasPermission(ctx, approver.Role, permission)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New("approver is not allowed to approve this action")
+	}
+
+	token, err := newSecretToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	override := &models.Override{
+		ID:          uuid.New().String(),
+		Action:      req.Action,
+		ReferenceID: req.ReferenceID,
+		Amount:      req.Amount,
+		TerminalID:  terminalID,
+		RequestedBy: requestedBy,
+		ApprovedBy:  approver.ID,
+		Reason:      req.Reason,
+		ExpiresAt:   now.Add(models.OverrideTokenTTL),
+		CreatedAt:   now,
+	}
+	if err := s.overrideRepo.Create(ctx, override, hashSecretToken(token)); err != nil {
+		return nil, err
+	}
+
+	resp := toOverrideResponse(override)
+	resp.Token = token
+	return resp, nil
+}
+
+// Authorize allows a user to perform an action their role grants; otherwise the user must
+// present an override token issued to them for it on their terminal, and for amount when the
+// action has one. It returns the token's use, which the action's write uses up on referenceID
+// in the same database transaction, or nil when no approval is needed.
+func (s *OverrideService) Authorize(ctx context.Context, userID, role, terminalID, action, referenceID, token string, amount *float64) (*models.OverrideUse, error) {
+	allowed, err := s.roleService.HasPermission(ctx, role, models.OverridePermissions[action])
+	if err != nil {
+		return nil, err
+	}
+	if allowed {
+		return nil, nil
+	}
+	if token == "" {
+		return nil, ErrOverrideRequired
+	}
+
+	return &models.OverrideUse{
+		TokenHash:   hashSecretToken(token),
+		Action:      action,
+		RequestedBy: userID,
+		ReferenceID: referenceID,
+		Amount:      amount,
+		TerminalID:  terminalID,
+	}, nil
+}
+
+// NoSale records opening the cash drawer without a sale. Users allowed to do so alone are
+// recorded as approving it themselves.
+func (s *OverrideService) NoSale(ctx context.Context, userID, role, terminalID string, req *dto.NoSaleRequest) (*dto.OverrideResponse, error) {
+	use, err := s.Authorize(ctx, userID, role, terminalID, models.OverrideNoSale, "", req.OverrideToken, nil)
+	if err != nil {
+		return nil, err
+	}
+	if use != nil {
+		// Opening the drawer writes nothing else, so the token is used up on its own
+		override, err := s.overrideRepo.Use(ctx, use, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if override == nil {
+			return nil, ErrInvalidOverride
+		}
+		return toOverrideResponse(override), nil
+	}
+
+	// The record gets a token nobody holds, so it cannot be used again
+	token, err := newSecretToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	override := &models.Override{
+		ID:          uuid.New().String(),
+		Action:      models.OverrideNoSale,
+		RequestedBy: userID,
+		TerminalID:  terminalID,
+		ApprovedBy:  userID,
+		Reason:      req.Reason,
+		ExpiresAt:   now,
+		UsedAt:      &now,
+		CreatedAt:   now,
+	}
+	if err := s.overrideRepo.Create(ctx, override, hashSecretToken(token)); err != nil {
+		return nil, err
+	}
+	return toOverrideResponse(override), nil
+}
+
+// List lists approvals, newest first
+func (s *OverrideService) List(ctx context.Context, filter dto.OverrideListFilter, pagination utils.Pagination) ([]*dto.OverrideResponse, int, error) {
+	overrides, total, err := s.overrideRepo.List(ctx, filter, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := []*dto.OverrideResponse{}
+	for _, override := range overrides {
+		result = append(result, toOverrideResponse(override))
+	}
+	return result, total, nil
+}
+
+func toOverrideResponse(override *models.Override) *dto.OverrideResponse {
+	return &dto.OverrideResponse{
+		ID:          override.ID,
+		Action:      override.Action,
+		ReferenceID: override.ReferenceID,
+		Amount:      override.Amount,
+		TerminalID:  override.TerminalID,
+		RequestedBy: override.RequestedBy,
+		ApprovedBy:  override.ApprovedBy,
+		Reason:      override.Reason,
+		ExpiresAt:   override.ExpiresAt,
+		UsedAt:      override.UsedAt,
+		CreatedAt:   override.CreatedAt,
+	}
+}
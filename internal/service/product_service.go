@@ -1,8 +1,15 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
 	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,17 +19,46 @@ import (
 	"github.com/ilramdhan/pos-api/internal/utils"
 )
 
+// maxImportRows caps the number of rows accepted by a single product import
+const maxImportRows = 5000
+
+// productCSVColumns is the column order of product exports; imports accept any order
+var productCSVColumns = []string{"category_slug", "sku", "name", "description", "price", "stock", "is_active"}
+
+// ErrProductNotFound is returned for products that do not exist in the tenant
+var ErrProductNotFound = errors.New("product not found")
+
+// ProductImportOptions control what an import may do
+type ProductImportOptions struct {
+	DryRun          bool   // Only report what would happen
+	CanChangePrices bool   // Existing products may get a new price
+	CanChangeStock  bool   // Existing products' stock may be adjusted
+	StoreID         string // Adjust stock at this store rather than the shared stock
+	UserID          string // Who the stock adjustments are recorded for
+}
+
+// ProductImportError is returned when an import has invalid rows; nothing is written
+type ProductImportError struct {
+	Result *dto.ProductImportResponse
+}
+
+func (e *ProductImportError) Error() string {
+	return fmt.Sprintf("import has %d invalid field(s); no products were saved", len(e.Result.Errors))
+}
+
 // ProductService handles product operations
 type ProductService struct {
 	productRepo  repository.ProductRepository
 	categoryRepo repository.CategoryRepository
+	optionRepo   repository.ProductOptionRepository
 }
 
 // NewProductService creates a new product service
-func NewProductService(productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository) *ProductService {
+func NewProductService(productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, optionRepo repository.ProductOptionRepository) *ProductService {
 	return &ProductService{
 		productRepo:  productRepo,
 		categoryRepo: categoryRepo,
+		optionRepo:   optionRepo,
 	}
 }
 
@@ -38,13 +74,9 @@ func (s *ProductService) Create(ctx context.Context, req *dto.CreateProductReque
 	}
 
 	// Check SKU uniqueness
-	existing, err := s.productRepo.GetBySKU(ctx, req.SKU)
-	if err != nil {
+	if err := s.checkSKU(ctx, req.SKU); err != nil {
 		return nil, err
 	}
-	if existing != nil {
-		return nil, errors.New("SKU already exists")
-	}
 
 	now := time.Now()
 	product := &models.Product{
@@ -69,14 +101,23 @@ func (s *ProductService) Create(ctx context.Context, req *dto.CreateProductReque
 	return s.toResponse(product), nil
 }
 
-// GetByID retrieves a product by ID
-func (s *ProductService) GetByID(ctx context.Context, id string) (*dto.ProductResponse, error) {
+// GetByID retrieves a product by ID, with its stock and price at a store when storeID is set
+func (s *ProductService) GetByID(ctx context.Context, id, storeID string) (*dto.ProductResponse, error) {
 	product, err := s.productRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	if product == nil {
-		return nil, errors.New("product not found")
+		return nil, ErrProductNotFound
+	}
+	if storeID != "" {
+		if err := s.productRepo.ApplyStoreLevels(ctx, storeID, []*models.Product{product}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.attachOptions(ctx, storeID, []*models.Product{product}); err != nil {
+		return nil, err
 	}
 
 	return s.toResponse(product), nil
@@ -107,13 +148,9 @@ func (s *ProductService) Update(ctx context.Context, id string, req *dto.UpdateP
 
 	// Check SKU uniqueness if changed
 	if req.SKU != "" && product.SKU != req.SKU {
-		existing, err := s.productRepo.GetBySKU(ctx, req.SKU)
-		if err != nil {
+		if err := s.checkSKU(ctx, req.SKU); err != nil {
 			return nil, err
 		}
-		if existing != nil {
-			return nil, errors.New("SKU already exists")
-		}
 		product.SKU = req.SKU
 	}
 
@@ -161,8 +198,9 @@ func (s *ProductService) Delete(ctx context.Context, id string) error {
 	return s.productRepo.Delete(ctx, id)
 }
 
-// UpdateStock updates product stock
-func (s *ProductService) UpdateStock(ctx context.Context, id string, req *dto.UpdateStockRequest) (*dto.ProductResponse, error) {
+// UpdateStock adds to or subtracts from product stock, or the product's stock at a store when
+// storeID is set, and records the change as an adjustment movement
+func (s *ProductService) UpdateStock(ctx context.Context, id, storeID, userID string, req *dto.UpdateStockRequest) (*dto.ProductResponse, error) {
 	product, err := s.productRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -171,29 +209,76 @@ func (s *ProductService) UpdateStock(ctx context.Context, id string, req *dto.Up
 		return nil, errors.New("product not found")
 	}
 
-	var newStock int
+	var quantity int
 	switch req.Operation {
 	case "add":
-		newStock = product.Stock + req.Quantity
+		quantity = req.Quantity
 	case "subtract":
-		newStock = product.Stock - req.Quantity
-		if newStock < 0 {
-			return nil, errors.New("insufficient stock")
-		}
-	case "set":
-		newStock = req.Quantity
+		quantity = -req.Quantity
 	default:
 		return nil, errors.New("invalid operation")
 	}
+	if quantity == 0 {
+		return nil, errors.New("quantity must be positive")
+	}
+
+	movement := &models.StockMovement{
+		ID:        uuid.New().String(),
+		ProductID: product.ID,
+		Type:      models.MovementAdjustment,
+		Quantity:  float64(quantity),
+		Note:      "Manual stock " + req.Operation,
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+	}
+	if storeID != "" {
+		movement.StoreID = &storeID
+		if err := s.productRepo.ApplyStoreLevels(ctx, storeID, []*models.Product{product}); err != nil {
+			return nil, err
+		}
+	}
 
-	if err := s.productRepo.UpdateStock(ctx, id, newStock); err != nil {
+	if err := s.productRepo.PostMovement(ctx, movement); err != nil {
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			return nil, errors.New("insufficient stock")
+		}
 		return nil, err
 	}
 
-	product.Stock = newStock
+	product.Stock = int(movement.BalanceAfter)
 	return s.toResponse(product), nil
 }
 
+// StockMovements lists changes to product stock, newest first
+func (s *ProductService) StockMovements(ctx context.Context, filter dto.ProductStockMovementFilter, pagination utils.Pagination) ([]*dto.ProductStockMovementResponse, int, error) {
+	movements, total, err := s.productRepo.ListMovements(ctx, filter, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*dto.ProductStockMovementResponse, 0, len(movements))
+	for _, movement := range movements {
+		responses = append(responses, &dto.ProductStockMovementResponse{
+			ID:             movement.ID,
+			ProductID:      movement.ProductID,
+			ProductName:    movement.ProductName,
+			SKU:            movement.SKU,
+			VariantID:      movement.VariantID,
+			VariantName:    movement.VariantName,
+			StoreID:        movement.StoreID,
+			Type:           movement.Type,
+			QuantityChange: int(movement.Quantity),
+			NewBalance:     int(movement.BalanceAfter),
+			ReferenceID:    movement.ReferenceID,
+			Note:           movement.Note,
+			User:           movement.CreatedByName,
+			CreatedAt:      movement.CreatedAt,
+		})
+	}
+
+	return responses, total, nil
+}
+
 // List lists products with pagination and filters
 func (s *ProductService) List(ctx context.Context, filter dto.ProductListFilter, pagination utils.Pagination) ([]*dto.ProductResponse, int, error) {
 	products, total, err := s.productRepo.List(ctx, filter, pagination)
@@ -201,6 +286,10 @@ func (s *ProductService) List(ctx context.Context, filter dto.ProductListFilter,
 		return nil, 0, err
 	}
 
+	if err := s.attachOptions(ctx, filter.StoreID, products); err != nil {
+		return nil, 0, err
+	}
+
 	var responses []*dto.ProductResponse
 	for _, product := range products {
 		responses = append(responses, s.toResponse(product))
@@ -209,19 +298,952 @@ func (s *ProductService) List(ctx context.Context, filter dto.ProductListFilter,
 	return responses, total, nil
 }
 
+// Search returns product suggestions for a search term, most relevant first. Matching is
+// full-text (Indonesian and English stemming, word prefixes) with trigram typo tolerance
+// over product and category names.
+func (s *ProductService) Search(ctx context.Context, query dto.ProductSearchQuery) ([]*dto.ProductSuggestion, error) {
+	term := strings.TrimSpace(query.Q)
+	suggestions := []*dto.ProductSuggestion{}
+	if term == "" {
+		return suggestions, nil
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = 10
+	}
+
+	products, err := s.productRepo.Search(ctx, term, limit)
+	if err != nil {
+		return nil, err
+	}
+	if query.StoreID != "" {
+		if err := s.productRepo.ApplyStoreLevels(ctx, query.StoreID, products); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, product := range products {
+		suggestion := &dto.ProductSuggestion{
+			ID:           product.ID,
+			SKU:          product.SKU,
+			Name:         product.Name,
+			Price:        product.Price,
+			Stock:        product.Stock,
+			Available:    product.Stock > 0,
+			CategoryID:   product.CategoryID,
+			ThumbnailURL: product.ThumbnailURL,
+		}
+		if product.Category != nil {
+			suggestion.CategoryName = product.Category.Name
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, nil
+}
+
+// ImportCSV creates or updates products from CSV rows keyed by SKU. Every row is validated
+// before anything is written and all changes are saved in one database transaction. Rows that
+// change an existing product's price are rejected unless prices may be changed. A new stock level
+// for an existing product is posted as an adjustment of the difference, which needs permission
+// to change stock.
+func (s *ProductService) ImportCSV(ctx context.Context, r io.Reader, opts ProductImportOptions) (*dto.ProductImportResponse, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	// Short rows are allowed; missing trailing cells count as empty
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, required := range []string{"category_slug", "sku", "name", "price"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV is missing the %s column", required)
+		}
+	}
+
+	result := &dto.ProductImportResponse{DryRun: opts.DryRun, Errors: []dto.ProductImportRowError{}}
+	categories := make(map[string]*models.Category)
+	seen := make(map[string]int)
+	var creates, updates []*models.Product
+	var movements []*models.StockMovement
+	now := time.Now()
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+		if result.Total == maxImportRows {
+			return nil, fmt.Errorf("CSV has more than %d rows", maxImportRows)
+		}
+		result.Total++
+
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := dto.ProductImportRow{
+			CategorySlug: cell("category_slug"),
+			SKU:          cell("sku"),
+			Name:         cell("name"),
+		}
+		rowError := func(field, message string) {
+			result.Errors = append(result.Errors, dto.ProductImportRowError{
+				Row: line, SKU: row.SKU, Field: field, Message: message,
+			})
+		}
+
+		// Parse typed cells; empty optional cells keep existing values
+		failed := len(result.Errors)
+		if price, err := strconv.ParseFloat(cell("price"), 64); err == nil {
+			row.Price = price
+		} else {
+			rowError("price", "Must be a number")
+		}
+		if v := cell("stock"); v != "" {
+			if stock, err := strconv.Atoi(v); err == nil {
+				row.Stock = &stock
+			} else {
+				rowError("stock", "Must be a whole number")
+			}
+		}
+		if v := cell("is_active"); v != "" {
+			if isActive, err := strconv.ParseBool(strings.ToLower(v)); err == nil {
+				row.IsActive = &isActive
+			} else {
+				rowError("is_active", "Must be true or false")
+			}
+		}
+		if _, ok := columns["description"]; ok {
+			if v := cell("description"); v != "" {
+				row.Description = &v
+			}
+		}
+
+		if fieldErrors, ok := utils.Validate(&row); !ok {
+			for _, fieldError := range fieldErrors {
+				rowError(fieldError.Field, fieldError.Message)
+			}
+		}
+		if len(result.Errors) > failed {
+			continue
+		}
+
+		if firstLine, ok := seen[row.SKU]; ok {
+			rowError("sku", fmt.Sprintf("Duplicate of row %d", firstLine))
+			continue
+		}
+		seen[row.SKU] = line
+
+		category, ok := categories[row.CategorySlug]
+		if !ok {
+			category, err = s.categoryRepo.GetBySlug(ctx, row.CategorySlug)
+			if err != nil {
+				return nil, err
+			}
+			categories[row.CategorySlug] = category
+		}
+		if category == nil {
+			rowError("category_slug", "Category not found")
+			continue
+		}
+
+		existing, err := s.productRepo.GetBySKU(ctx, row.SKU)
+		if err != nil {
+			return nil, err
+		}
+
+		if existing == nil {
+			variant, err := s.optionRepo.GetVariantBySKU(ctx, row.SKU)
+			if err != nil {
+				return nil, err
+			}
+			if variant != nil {
+				rowError("sku", "SKU already used by a product variant")
+				continue
+			}
+
+			product := &models.Product{
+				ID:         uuid.New().String(),
+				CategoryID: category.ID,
+				SKU:        row.SKU,
+				Name:       row.Name,
+				Price:      row.Price,
+				IsActive:   true,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			}
+			if row.Description != nil {
+				product.Description = *row.Description
+			}
+			if row.Stock != nil {
+				product.Stock = *row.Stock
+			}
+			if row.IsActive != nil {
+				product.IsActive = *row.IsActive
+			}
+			creates = append(creates, product)
+			continue
+		}
+
+		if row.Price != existing.Price && !opts.CanChangePrices {
+			rowError("price", "You don't have permission to change prices")
+			continue
+		}
+
+		if row.Stock != nil {
+			movement, err := s.importAdjustment(ctx, existing, *row.Stock, opts, now)
+			if err != nil {
+				return nil, err
+			}
+			if movement != nil {
+				if !opts.CanChangeStock {
+					rowError("stock", "You don't have permission to change stock")
+					continue
+				}
+				movements = append(movements, movement)
+			}
+		}
+
+		existing.CategoryID = category.ID
+		existing.Name = row.Name
+		existing.Price = row.Price
+		if row.Description != nil {
+			existing.Description = *row.Description
+		}
+		if row.IsActive != nil {
+			existing.IsActive = *row.IsActive
+		}
+		existing.UpdatedAt = now
+		updates = append(updates, existing)
+	}
+
+	if result.Total == 0 {
+		return nil, errors.New("CSV has no product rows")
+	}
+
+	result.Created = len(creates)
+	result.Updated = len(updates)
+
+	if len(result.Errors) > 0 {
+		if opts.DryRun {
+			return result, nil
+		}
+		return nil, &ProductImportError{Result: result}
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if err := s.productRepo.ImportBatch(ctx, creates, updates, movements); err != nil {
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			return nil, errors.New("stock changed during the import; please try again")
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// importAdjustment returns the adjustment that brings an existing product's stock, at the
+// import's store if it has one, to the imported level, or nil if the level is unchanged
+func (s *ProductService) importAdjustment(ctx context.Context, product *models.Product, stock int, opts ProductImportOptions, now time.Time) (*models.StockMovement, error) {
+	current := product.Stock
+	if opts.StoreID != "" {
+		// Store levels also carry store prices, so they are read into a copy
+		level := *product
+		if err := s.productRepo.ApplyStoreLevels(ctx, opts.StoreID, []*models.Product{&level}); err != nil {
+			return nil, err
+		}
+		current = level.Stock
+	}
+	if stock == current {
+		return nil, nil
+	}
+
+	movement := &models.StockMovement{
+		ID:        uuid.New().String(),
+		ProductID: product.ID,
+		Type:      models.MovementAdjustment,
+		Quantity:  float64(stock - current),
+		Note:      "Stock import",
+		CreatedBy: opts.UserID,
+		CreatedAt: now,
+	}
+	if opts.StoreID != "" {
+		movement.StoreID = &opts.StoreID
+	}
+	return movement, nil
+}
+
+// ExportCSV writes all products matching the filter as CSV in the import column layout
+func (s *ProductService) ExportCSV(ctx context.Context, filter dto.ProductListFilter) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(productCSVColumns); err != nil {
+		return nil, err
+	}
+
+	pagination := utils.Pagination{Page: 1, PerPage: utils.MaxPerPage, Sort: "created_at", Order: "asc"}
+	for {
+		products, total, err := s.productRepo.List(ctx, filter, pagination)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, product := range products {
+			categorySlug := ""
+			if product.Category != nil {
+				categorySlug = product.Category.Slug
+			}
+			if err := writer.Write([]string{
+				categorySlug,
+				product.SKU,
+				product.Name,
+				product.Description,
+				strconv.FormatFloat(product.Price, 'f', -1, 64),
+				strconv.Itoa(product.Stock),
+				strconv.FormatBool(product.IsActive),
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		if pagination.Page*pagination.PerPage >= total {
+			break
+		}
+		pagination.Page++
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// CreateVariant adds a variant to a product
+func (s *ProductService) CreateVariant(ctx context.Context, productID string, req *dto.CreateVariantRequest) (*dto.ProductVariantResponse, error) {
+	product, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("product not found")
+	}
+
+	if err := s.checkSKU(ctx, req.SKU); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	variant := &models.ProductVariant{
+		ID:        uuid.New().String(),
+		ProductID: productID,
+		SKU:       req.SKU,
+		Name:      req.Name,
+		Price:     req.Price,
+		Stock:     req.Stock,
+		IsActive:  true,
+		SortOrder: req.SortOrder,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.optionRepo.CreateVariant(ctx, variant); err != nil {
+		return nil, err
+	}
+
+	return s.toVariantResponse(variant), nil
+}
+
+// UpdateVariant updates a product variant
+func (s *ProductService) UpdateVariant(ctx context.Context, productID, variantID string, req *dto.UpdateVariantRequest) (*dto.ProductVariantResponse, error) {
+	variant, err := s.optionRepo.GetVariant(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if variant == nil || variant.ProductID != productID {
+		return nil, errors.New("variant not found")
+	}
+
+	if req.SKU != "" && req.SKU != variant.SKU {
+		if err := s.checkSKU(ctx, req.SKU); err != nil {
+			return nil, err
+		}
+		variant.SKU = req.SKU
+	}
+	if req.Name != "" {
+		variant.Name = req.Name
+	}
+	if req.Price != nil {
+		variant.Price = *req.Price
+	}
+	if req.Stock != nil {
+		variant.Stock = *req.Stock
+	}
+	if req.IsActive != nil {
+		variant.IsActive = *req.IsActive
+	}
+	if req.SortOrder != nil {
+		variant.SortOrder = *req.SortOrder
+	}
+	variant.UpdatedAt = time.Now()
+
+	if err := s.optionRepo.UpdateVariant(ctx, variant); err != nil {
+		return nil, err
+	}
+
+	return s.toVariantResponse(variant), nil
+}
+
+// UpdateVariantStock adds or subtracts stock of a product's variant, at the store when storeID
+// is set, and records the change as an adjustment movement
+func (s *ProductService) UpdateVariantStock(ctx context.Context, productID, variantID, storeID, userID string, req *dto.UpdateStockRequest) (*dto.ProductVariantResponse, error) {
+	variant, err := s.optionRepo.GetVariant(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if variant == nil || variant.ProductID != productID {
+		return nil, errors.New("variant not found")
+	}
+
+	var quantity int
+	switch req.Operation {
+	case "add":
+		quantity = req.Quantity
+	case "subtract":
+		quantity = -req.Quantity
+	default:
+		return nil, errors.New("invalid operation")
+	}
+	if quantity == 0 {
+		return nil, errors.New("quantity must be positive")
+	}
+
+	movement := &models.StockMovement{
+		ID:        uuid.New().String(),
+		ProductID: productID,
+		VariantID: &variant.ID,
+		Type:      models.MovementAdjustment,
+		Quantity:  float64(quantity),
+		Note:      "Manual stock " + req.Operation,
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+	}
+	if storeID != "" {
+		movement.StoreID = &storeID
+	}
+
+	if err := s.productRepo.PostMovement(ctx, movement); err != nil {
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			return nil, errors.New("insufficient stock")
+		}
+		return nil, err
+	}
+
+	variant.Stock = int(movement.BalanceAfter)
+	return s.toVariantResponse(variant), nil
+}
+
+// DeleteVariant removes a variant from a product
+func (s *ProductService) DeleteVariant(ctx context.Context, productID, variantID string) error {
+	variant, err := s.optionRepo.GetVariant(ctx, variantID)
+	if err != nil {
+		return err
+	}
+	if variant == nil || variant.ProductID != productID {
+		return errors.New("variant not found")
+	}
+
+	return s.optionRepo.DeleteVariant(ctx, variantID)
+}
+
+// CreateModifierGroup adds a modifier group with its modifiers to a product
+func (s *ProductService) CreateModifierGroup(ctx context.Context, productID string, req *dto.ModifierGroupRequest) (*dto.ModifierGroupResponse, error) {
+	product, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("product not found")
+	}
+
+	now := time.Now()
+	group := &models.ModifierGroup{
+		ID:        uuid.New().String(),
+		ProductID: productID,
+		CreatedAt: now,
+	}
+	if err := s.applyModifierGroup(group, req, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.optionRepo.CreateModifierGroup(ctx, group); err != nil {
+		return nil, err
+	}
+
+	return s.toModifierGroupResponse(group), nil
+}
+
+// UpdateModifierGroup updates a modifier group and replaces its modifiers
+func (s *ProductService) UpdateModifierGroup(ctx context.Context, productID, groupID string, req *dto.ModifierGroupRequest) (*dto.ModifierGroupResponse, error) {
+	group, err := s.optionRepo.GetModifierGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil || group.ProductID != productID {
+		return nil, errors.New("modifier group not found")
+	}
+
+	if err := s.applyModifierGroup(group, req, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.optionRepo.UpdateModifierGroup(ctx, group); err != nil {
+		return nil, err
+	}
+
+	return s.toModifierGroupResponse(group), nil
+}
+
+// DeleteModifierGroup removes a modifier group and its modifiers from a product
+func (s *ProductService) DeleteModifierGroup(ctx context.Context, productID, groupID string) error {
+	group, err := s.optionRepo.GetModifierGroup(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if group == nil || group.ProductID != productID {
+		return errors.New("modifier group not found")
+	}
+
+	return s.optionRepo.DeleteModifierGroup(ctx, groupID)
+}
+
+// CreateBarcode assigns a barcode to a product or one of its variants
+func (s *ProductService) CreateBarcode(ctx context.Context, productID string, req *dto.CreateBarcodeRequest) (*dto.ProductBarcodeResponse, error) {
+	product, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("product not found")
+	}
+
+	if err := validateBarcode(req.Code, req.Type); err != nil {
+		return nil, err
+	}
+
+	if req.VariantID != nil {
+		variant, err := s.optionRepo.GetVariant(ctx, *req.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		if variant == nil || variant.ProductID != productID {
+			return nil, errors.New("variant not found")
+		}
+	}
+
+	existing, err := s.optionRepo.GetBarcodeByCode(ctx, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("barcode already exists")
+	}
+
+	barcode := &models.ProductBarcode{
+		ID:        uuid.New().String(),
+		ProductID: productID,
+		VariantID: req.VariantID,
+		Code:      req.Code,
+		Type:      req.Type,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.optionRepo.CreateBarcode(ctx, barcode); err != nil {
+		return nil, err
+	}
+
+	return s.toBarcodeResponse(barcode), nil
+}
+
+// DeleteBarcode removes a barcode from a product
+func (s *ProductService) DeleteBarcode(ctx context.Context, productID, barcodeID string) error {
+	barcode, err := s.optionRepo.GetBarcode(ctx, barcodeID)
+	if err != nil {
+		return err
+	}
+	if barcode == nil || barcode.ProductID != productID {
+		return errors.New("barcode not found")
+	}
+
+	return s.optionRepo.DeleteBarcode(ctx, barcodeID)
+}
+
+// BarcodeLabel renders a printable label for a product barcode and returns it with its content type
+func (s *ProductService) BarcodeLabel(ctx context.Context, productID, barcodeID string, query dto.BarcodeLabelQuery) ([]byte, string, error) {
+	barcode, err := s.optionRepo.GetBarcode(ctx, barcodeID)
+	if err != nil {
+		return nil, "", err
+	}
+	if barcode == nil || barcode.ProductID != productID {
+		return nil, "", errors.New("barcode not found")
+	}
+
+	var modules []bool
+	switch barcode.Type {
+	case models.BarcodeEAN13, models.BarcodeUPCA:
+		modules, err = utils.EncodeEAN13(barcode.Code)
+	case models.BarcodeInternal:
+		modules, err = utils.EncodeCode128(barcode.Code)
+	default:
+		return nil, "", errors.New("labels for variable barcodes are printed by the scale")
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	scale := query.Scale
+	if scale == 0 {
+		scale = 2
+	}
+	height := query.Height
+	if height == 0 {
+		height = 60
+	}
+
+	if query.Format == "png" {
+		data, err := utils.BarcodePNG(modules, scale, height)
+		return data, "image/png", err
+	}
+	return utils.BarcodeSVG(modules, barcode.Code, scale, height), "image/svg+xml", nil
+}
+
+// LookupBarcode finds the product for a scanned code. Scale-printed EAN-13 labels also
+// return the weight or price they carry.
+func (s *ProductService) LookupBarcode(ctx context.Context, code string) (*dto.BarcodeLookupResponse, error) {
+	match, err := matchBarcode(ctx, s.productRepo, s.optionRepo, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachOptions(ctx, "", []*models.Product{match.product}); err != nil {
+		return nil, err
+	}
+
+	resp := &dto.BarcodeLookupResponse{
+		Code:     code,
+		Product:  s.toResponse(match.product),
+		Embedded: match.embedded,
+	}
+	if match.variant != nil {
+		resp.Variant = s.toVariantResponse(match.variant)
+	}
+	return resp, nil
+}
+
+// barcodeMatch is the product, optional variant and embedded value a scanned code resolves to
+type barcodeMatch struct {
+	product  *models.Product
+	variant  *models.ProductVariant
+	embedded *dto.EmbeddedBarcodeValue
+}
+
+// matchBarcode resolves a scanned code to a product. Codes are matched exactly first, then
+// as UPC-A/EAN-13 equivalents, then as scale-printed labels by their 2x item prefix, and
+// finally against product SKUs.
+func matchBarcode(ctx context.Context, productRepo repository.ProductRepository, optionRepo repository.ProductOptionRepository, code string) (*barcodeMatch, error) {
+	candidates := []string{code}
+	if len(code) == 12 && utils.IsDigits(code) {
+		candidates = append(candidates, "0"+code)
+	}
+	if len(code) == 13 && code[0] == '0' && utils.IsDigits(code) {
+		candidates = append(candidates, code[1:])
+	}
+
+	var barcode *models.ProductBarcode
+	for _, candidate := range candidates {
+		found, err := optionRepo.GetBarcodeByCode(ctx, candidate)
+		if err != nil {
+			return nil, err
+		}
+		if found != nil && found.Type != models.BarcodeVariable {
+			barcode = found
+			break
+		}
+	}
+
+	var embedded *dto.EmbeddedBarcodeValue
+	var embeddedValue int
+	if barcode == nil && len(code) == 13 && models.IsVariableBarcodePrefix(code) && utils.ValidGTIN(code) {
+		found, err := optionRepo.GetBarcodeByCode(ctx, code[:models.VariableBarcodePrefixLength])
+		if err != nil {
+			return nil, err
+		}
+		if found != nil && found.Type == models.BarcodeVariable {
+			barcode = found
+			embeddedValue, _ = strconv.Atoi(code[models.VariableBarcodePrefixLength:12])
+			embedded = &dto.EmbeddedBarcodeValue{Kind: models.EmbeddedKind(code)}
+		}
+	}
+
+	if barcode == nil {
+		// Labels printed from a SKU scan as the SKU itself
+		product, err := productRepo.GetBySKU(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		if product != nil {
+			product, err = productRepo.GetByID(ctx, product.ID)
+			if err != nil {
+				return nil, err
+			}
+			return &barcodeMatch{product: product}, nil
+		}
+		variant, err := optionRepo.GetVariantBySKU(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		if variant == nil {
+			return nil, errors.New("barcode not found")
+		}
+		barcode = &models.ProductBarcode{ProductID: variant.ProductID, VariantID: &variant.ID}
+	}
+
+	product, err := productRepo.GetByID(ctx, barcode.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("barcode not found")
+	}
+
+	match := &barcodeMatch{product: product, embedded: embedded}
+	basePrice := product.Price
+	if barcode.VariantID != nil {
+		variant, err := optionRepo.GetVariant(ctx, *barcode.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		if variant == nil {
+			return nil, errors.New("barcode not found")
+		}
+		match.variant = variant
+		basePrice = variant.Price
+	}
+
+	if embedded != nil {
+		switch embedded.Kind {
+		case models.EmbeddedPrice:
+			embedded.Price = float64(embeddedValue)
+		case models.EmbeddedWeight:
+			// Products sold by weight are priced per kilogram
+			embedded.WeightGrams = embeddedValue
+			embedded.Price = math.Round(basePrice*float64(embeddedValue)/1000*100) / 100
+		}
+	}
+
+	return match, nil
+}
+
+// validateBarcode checks a code's length, characters and check digit against its type
+func validateBarcode(code, barcodeType string) error {
+	switch barcodeType {
+	case models.BarcodeEAN13:
+		if len(code) != 13 || !utils.ValidGTIN(code) {
+			return errors.New("invalid EAN-13 barcode")
+		}
+	case models.BarcodeUPCA:
+		if len(code) != 12 || !utils.ValidGTIN(code) {
+			return errors.New("invalid UPC-A barcode")
+		}
+	case models.BarcodeVariable:
+		if len(code) != models.VariableBarcodePrefixLength || !utils.IsDigits(code) || !models.IsVariableBarcodePrefix(code) {
+			return fmt.Errorf("variable barcodes are the first %d digits of a 2x EAN-13 label", models.VariableBarcodePrefixLength)
+		}
+	case models.BarcodeInternal:
+		for i := 0; i < len(code); i++ {
+			if code[i] < 32 || code[i] > 126 {
+				return errors.New("internal barcodes may only contain printable ASCII characters")
+			}
+		}
+	default:
+		return errors.New("invalid barcode type")
+	}
+	return nil
+}
+
+// checkSKU rejects a SKU already used by a product or a variant
+func (s *ProductService) checkSKU(ctx context.Context, sku string) error {
+	existing, err := s.productRepo.GetBySKU(ctx, sku)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return errors.New("SKU already exists")
+	}
+
+	variant, err := s.optionRepo.GetVariantBySKU(ctx, sku)
+	if err != nil {
+		return err
+	}
+	if variant != nil {
+		return errors.New("SKU already exists")
+	}
+
+	return nil
+}
+
+// applyModifierGroup validates a modifier group request and copies it onto the group
+func (s *ProductService) applyModifierGroup(group *models.ModifierGroup, req *dto.ModifierGroupRequest, now time.Time) error {
+	if req.MaxSelect > 0 && req.MinSelect > req.MaxSelect {
+		return errors.New("min_select cannot be greater than max_select")
+	}
+	if req.MinSelect > len(req.Modifiers) {
+		return errors.New("min_select cannot be greater than the number of modifiers")
+	}
+
+	group.Name = req.Name
+	group.MinSelect = req.MinSelect
+	group.MaxSelect = req.MaxSelect
+	group.SortOrder = req.SortOrder
+	group.UpdatedAt = now
+	group.Modifiers = make([]models.Modifier, 0, len(req.Modifiers))
+	for i, modifierReq := range req.Modifiers {
+		isActive := true
+		if modifierReq.IsActive != nil {
+			isActive = *modifierReq.IsActive
+		}
+		group.Modifiers = append(group.Modifiers, models.Modifier{
+			ID:         uuid.New().String(),
+			GroupID:    group.ID,
+			Name:       modifierReq.Name,
+			PriceDelta: modifierReq.PriceDelta,
+			IsActive:   isActive,
+			SortOrder:  i,
+		})
+	}
+
+	return nil
+}
+
+// attachOptions loads the variants, modifier groups and barcodes of the given products, with
+// variant stock at a store when storeID is set
+func (s *ProductService) attachOptions(ctx context.Context, storeID string, products []*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*models.Product, len(products))
+	ids := make([]string, 0, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+		ids = append(ids, product.ID)
+	}
+
+	variants, err := s.optionRepo.ListVariants(ctx, ids)
+	if err != nil {
+		return err
+	}
+	if storeID != "" {
+		if err := s.optionRepo.ApplyStoreStock(ctx, storeID, variants); err != nil {
+			return err
+		}
+	}
+	for _, variant := range variants {
+		byID[variant.ProductID].Variants = append(byID[variant.ProductID].Variants, *variant)
+	}
+
+	groups, err := s.optionRepo.ListModifierGroups(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		byID[group.ProductID].ModifierGroups = append(byID[group.ProductID].ModifierGroups, *group)
+	}
+
+	barcodes, err := s.optionRepo.ListBarcodes(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, barcode := range barcodes {
+		byID[barcode.ProductID].Barcodes = append(byID[barcode.ProductID].Barcodes, *barcode)
+	}
+
+	return nil
+}
+
+func (s *ProductService) toVariantResponse(variant *models.ProductVariant) *dto.ProductVariantResponse {
+	return &dto.ProductVariantResponse{
+		ID:        variant.ID,
+		SKU:       variant.SKU,
+		Name:      variant.Name,
+		Price:     variant.Price,
+		Stock:     variant.Stock,
+		IsActive:  variant.IsActive,
+		SortOrder: variant.SortOrder,
+	}
+}
+
+func (s *ProductService) toBarcodeResponse(barcode *models.ProductBarcode) *dto.ProductBarcodeResponse {
+	return &dto.ProductBarcodeResponse{
+		ID:        barcode.ID,
+		Code:      barcode.Code,
+		Type:      barcode.Type,
+		VariantID: barcode.VariantID,
+	}
+}
+
+func (s *ProductService) toModifierGroupResponse(group *models.ModifierGroup) *dto.ModifierGroupResponse {
+	resp := &dto.ModifierGroupResponse{
+		ID:        group.ID,
+		Name:      group.Name,
+		MinSelect: group.MinSelect,
+		MaxSelect: group.MaxSelect,
+		SortOrder: group.SortOrder,
+		Modifiers: make([]dto.ModifierResponse, 0, len(group.Modifiers)),
+	}
+	for _, modifier := range group.Modifiers {
+		resp.Modifiers = append(resp.Modifiers, dto.ModifierResponse{
+			ID:         modifier.ID,
+			Name:       modifier.Name,
+			PriceDelta: modifier.PriceDelta,
+			IsActive:   modifier.IsActive,
+		})
+	}
+	return resp
+}
+
 func (s *ProductService) toResponse(product *models.Product) *dto.ProductResponse {
 	resp := &dto.ProductResponse{
-		ID:          product.ID,
-		CategoryID:  product.CategoryID,
-		SKU:         product.SKU,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		ImageURL:    product.ImageURL,
-		IsActive:    product.IsActive,
-		CreatedAt:   product.CreatedAt,
-		UpdatedAt:   product.UpdatedAt,
+		ID:           product.ID,
+		CategoryID:   product.CategoryID,
+		SKU:          product.SKU,
+		Name:         product.Name,
+		Description:  product.Description,
+		Price:        product.Price,
+		CostPrice:    product.CostPrice,
+		Stock:        product.Stock,
+		ImageURL:     product.ImageURL,
+		ThumbnailURL: product.ThumbnailURL,
+		IsActive:     product.IsActive,
+		CreatedAt:    product.CreatedAt,
+		UpdatedAt:    product.UpdatedAt,
 	}
 
 	if product.Category != nil {
@@ -236,5 +1258,15 @@ func (s *ProductService) toResponse(product *models.Product) *dto.ProductRespons
 		}
 	}
 
+	for i := range product.Variants {
+		resp.Variants = append(resp.Variants, *s.toVariantResponse(&product.Variants[i]))
+	}
+	for i := range product.ModifierGroups {
+		resp.ModifierGroups = append(resp.ModifierGroups, *s.toModifierGroupResponse(&product.ModifierGroups[i]))
+	}
+	for i := range product.Barcodes {
+		resp.Barcodes = append(resp.Barcodes, *s.toBarcodeResponse(&product.Barcodes[i]))
+	}
+
 	return resp
 }
@@ -0,0 +1,446 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/repository"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// PurchaseService handles suppliers, purchase orders and goods receiving
+type PurchaseService struct {
+	supplierRepo      repository.SupplierRepository
+	purchaseOrderRepo repository.PurchaseOrderRepository
+	productRepo       repository.ProductRepository
+	storeRepo         repository.StoreRepository
+}
+
+// NewPurchaseService creates a new purchase service
+func NewPurchaseService(supplierRepo repository.SupplierRepository, purchaseOrderRepo repository.PurchaseOrderRepository, productRepo repository.ProductRepository, storeRepo repository.StoreRepository) *PurchaseService {
+	return &PurchaseService{
+		supplierRepo:      supplierRepo,
+		purchaseOrderRepo: purchaseOrderRepo,
+		productRepo:       productRepo,
+		storeRepo:         storeRepo,
+	}
+}
+
+// CreateSupplier creates a new supplier
+func (s *PurchaseService) CreateSupplier(ctx context.Context, req *dto.CreateSupplierRequest) (*dto.SupplierResponse, error) {
+	now := time.Now()
+	supplier := &models.Supplier{
+		ID:          uuid.New().String(),
+		Name:        req.Name,
+		ContactName: req.ContactName,
+		Email:       req.Email,
+		Phone:       req.Phone,
+		Address:     req.Address,
+		IsActive:    true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.supplierRepo.Create(ctx, supplier); err != nil {
+		return nil, err
+	}
+
+	return s.toSupplierResponse(supplier), nil
+}
+
+// GetSupplier gets a supplier by ID
+func (s *PurchaseService) GetSupplier(ctx context.Context, id string) (*dto.SupplierResponse, error) {
+	supplier, err := s.supplierRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, errors.New("supplier not found")
+	}
+
+	return s.toSupplierResponse(supplier), nil
+}
+
+// UpdateSupplier updates a supplier's details
+func (s *PurchaseService) UpdateSupplier(ctx context.Context, id string, req *dto.UpdateSupplierRequest) (*dto.SupplierResponse, error) {
+	supplier, err := s.supplierRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, errors.New("supplier not found")
+	}
+
+	if req.Name != nil {
+		supplier.Name = *req.Name
+	}
+	if req.ContactName != nil {
+		supplier.ContactName = *req.ContactName
+	}
+	if req.Email != nil {
+		supplier.Email = *req.Email
+	}
+	if req.Phone != nil {
+		supplier.Phone = *req.Phone
+	}
+	if req.Address != nil {
+		supplier.Address = *req.Address
+	}
+	if req.IsActive != nil {
+		supplier.IsActive = *req.IsActive
+	}
+	supplier.UpdatedAt = time.Now()
+
+	if err := s.supplierRepo.Update(ctx, supplier); err != nil {
+		return nil, err
+	}
+
+	return s.toSupplierResponse(supplier), nil
+}
+
+// DeleteSupplier deletes a supplier that has no purchase orders. Suppliers with purchase
+// history are deactivated instead so the history stays intact.
+func (s *PurchaseService) DeleteSupplier(ctx context.Context, id string) error {
+	supplier, err := s.supplierRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if supplier == nil {
+		return errors.New("supplier not found")
+	}
+
+	count, err := s.supplierRepo.CountPurchaseOrders(ctx, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("supplier has purchase orders; deactivate it instead")
+	}
+
+	return s.supplierRepo.Delete(ctx, id)
+}
+
+// ListSuppliers lists suppliers with pagination and filters
+func (s *PurchaseService) ListSuppliers(ctx context.Context, filter dto.SupplierListFilter, pagination utils.Pagination) ([]*dto.SupplierResponse, int, error) {
+	suppliers, total, err := s.supplierRepo.List(ctx, filter, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*dto.SupplierResponse, 0, len(suppliers))
+	for _, supplier := range suppliers {
+		responses = append(responses, s.toSupplierResponse(supplier))
+	}
+
+	return responses, total, nil
+}
+
+// CreatePurchaseOrder creates a draft purchase order with the supplier. Goods for an order
+// with a store are received into that store's stock.
+func (s *PurchaseService) CreatePurchaseOrder(ctx context.Context, userID string, req *dto.CreatePurchaseOrderRequest) (*dto.PurchaseOrderResponse, error) {
+	if err := s.checkSupplier(ctx, req.SupplierID); err != nil {
+		return nil, err
+	}
+	if err := checkStore(ctx, s.storeRepo, req.StoreID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	orderID := uuid.New().String()
+	lines, err := s.buildLines(ctx, orderID, req.Lines, now)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &models.PurchaseOrder{
+		ID:         orderID,
+		PONumber:   fmt.Sprintf("PO-%s-%s", now.Format("20060102"), orderID[:8]),
+		SupplierID: req.SupplierID,
+		StoreID:    req.StoreID,
+		Status:     models.PurchaseOrderDraft,
+		ExpectedAt: req.ExpectedAt,
+		Notes:      req.Notes,
+		CreatedBy:  userID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Lines:      lines,
+	}
+
+	if err := s.purchaseOrderRepo.Create(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrder(ctx, orderID)
+}
+
+// GetPurchaseOrder gets a purchase order with its lines
+func (s *PurchaseService) GetPurchaseOrder(ctx context.Context, id string) (*dto.PurchaseOrderResponse, error) {
+	order, err := s.purchaseOrderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.New("purchase order not found")
+	}
+
+	return s.toPurchaseOrderResponse(order), nil
+}
+
+// UpdatePurchaseOrder edits a draft purchase order
+func (s *PurchaseService) UpdatePurchaseOrder(ctx context.Context, id string, req *dto.UpdatePurchaseOrderRequest) (*dto.PurchaseOrderResponse, error) {
+	order, err := s.purchaseOrderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.New("purchase order not found")
+	}
+	if order.Status != models.PurchaseOrderDraft {
+		return nil, errors.New("only draft purchase orders can be edited")
+	}
+
+	now := time.Now()
+	if req.SupplierID != nil && *req.SupplierID != order.SupplierID {
+		if err := s.checkSupplier(ctx, *req.SupplierID); err != nil {
+			return nil, err
+		}
+		order.SupplierID = *req.SupplierID
+	}
+	if req.ExpectedAt != nil {
+		order.ExpectedAt = req.ExpectedAt
+	}
+	if req.Notes != nil {
+		order.Notes = *req.Notes
+	}
+	if req.Lines != nil {
+		lines, err := s.buildLines(ctx, order.ID, req.Lines, now)
+		if err != nil {
+			return nil, err
+		}
+		order.Lines = lines
+	}
+	order.UpdatedAt = now
+
+	if err := s.purchaseOrderRepo.Update(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrder(ctx, id)
+}
+
+// SubmitPurchaseOrder marks a draft purchase order as ordered with the supplier
+func (s *PurchaseService) SubmitPurchaseOrder(ctx context.Context, id string) (*dto.PurchaseOrderResponse, error) {
+	order, err := s.purchaseOrderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.New("purchase order not found")
+	}
+	if order.Status != models.PurchaseOrderDraft {
+		return nil, errors.New("only draft purchase orders can be ordered")
+	}
+	if len(order.Lines) == 0 {
+		return nil, errors.New("purchase order has no lines")
+	}
+
+	if err := s.purchaseOrderRepo.UpdateStatus(ctx, id, order.Status, models.PurchaseOrderOrdered, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrder(ctx, id)
+}
+
+// CancelPurchaseOrder cancels a draft or ordered purchase order. Orders with received goods
+// cannot be cancelled.
+func (s *PurchaseService) CancelPurchaseOrder(ctx context.Context, id string) (*dto.PurchaseOrderResponse, error) {
+	order, err := s.purchaseOrderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.New("purchase order not found")
+	}
+	if order.Status != models.PurchaseOrderDraft && order.Status != models.PurchaseOrderOrdered {
+		return nil, fmt.Errorf("a %s purchase order cannot be cancelled", order.Status)
+	}
+
+	if err := s.purchaseOrderRepo.UpdateStatus(ctx, id, order.Status, models.PurchaseOrderCancelled, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrder(ctx, id)
+}
+
+// ReceivePurchaseOrder books goods received against an ordered purchase order. Stock is
+// increased, a stock movement is recorded per line and each product's cost price becomes
+// the moving average of the stock on hand and the received goods.
+func (s *PurchaseService) ReceivePurchaseOrder(ctx context.Context, id, userID string, req *dto.ReceivePurchaseOrderRequest) (*dto.PurchaseOrderResponse, error) {
+	order, err := s.purchaseOrderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.New("purchase order not found")
+	}
+	if !order.IsReceivable() {
+		return nil, repository.ErrPurchaseOrderNotReceivable
+	}
+
+	lines := make(map[string]*models.PurchaseOrderLine, len(order.Lines))
+	for i := range order.Lines {
+		lines[order.Lines[i].ID] = &order.Lines[i]
+	}
+
+	receipts := make([]models.GoodsReceipt, 0, len(req.Lines))
+	seen := make(map[string]bool, len(req.Lines))
+	for _, item := range req.Lines {
+		line, ok := lines[item.LineID]
+		if !ok {
+			return nil, fmt.Errorf("line %s is not on this purchase order", item.LineID)
+		}
+		if seen[item.LineID] {
+			return nil, fmt.Errorf("line %s appears more than once", item.LineID)
+		}
+		seen[item.LineID] = true
+		if item.Quantity > line.Outstanding() {
+			return nil, fmt.Errorf("%w: %d of %s outstanding", repository.ErrOverReceipt, line.Outstanding(), line.ProductName)
+		}
+
+		unitCost := line.UnitCost
+		if item.UnitCost != nil {
+			unitCost = math.Round(*item.UnitCost*100) / 100
+		}
+		receipts = append(receipts, models.GoodsReceipt{
+			LineID:   line.ID,
+			Quantity: item.Quantity,
+			UnitCost: unitCost,
+		})
+	}
+
+	if err := s.purchaseOrderRepo.Receive(ctx, id, receipts, userID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrder(ctx, id)
+}
+
+// ListPurchaseOrders lists purchase orders with pagination and filters
+func (s *PurchaseService) ListPurchaseOrders(ctx context.Context, filter dto.PurchaseOrderListFilter, pagination utils.Pagination) ([]*dto.PurchaseOrderResponse, int, error) {
+	orders, total, err := s.purchaseOrderRepo.List(ctx, filter, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*dto.PurchaseOrderResponse, 0, len(orders))
+	for _, order := range orders {
+		responses = append(responses, s.toPurchaseOrderResponse(order))
+	}
+
+	return responses, total, nil
+}
+
+// checkSupplier ensures a supplier exists and accepts new orders
+func (s *PurchaseService) checkSupplier(ctx context.Context, id string) error {
+	supplier, err := s.supplierRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if supplier == nil {
+		return errors.New("supplier not found")
+	}
+	if !supplier.IsActive {
+		return errors.New("supplier is inactive")
+	}
+	return nil
+}
+
+// buildLines validates requested lines and turns them into purchase order lines. Each
+// product may appear on one line only.
+func (s *PurchaseService) buildLines(ctx context.Context, orderID string, items []dto.PurchaseOrderLineRequest, now time.Time) ([]models.PurchaseOrderLine, error) {
+	if len(items) == 0 {
+		return nil, errors.New("purchase order needs at least one line")
+	}
+
+	lines := make([]models.PurchaseOrderLine, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		if seen[item.ProductID] {
+			return nil, fmt.Errorf("product %s appears on more than one line", item.ProductID)
+		}
+		seen[item.ProductID] = true
+
+		product, err := s.productRepo.GetByID(ctx, item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if product == nil {
+			return nil, fmt.Errorf("product %s not found", item.ProductID)
+		}
+
+		lines = append(lines, models.PurchaseOrderLine{
+			ID:              uuid.New().String(),
+			PurchaseOrderID: orderID,
+			ProductID:       product.ID,
+			Quantity:        item.Quantity,
+			UnitCost:        math.Round(item.UnitCost*100) / 100,
+			// Keep the requested line order
+			CreatedAt: now.Add(time.Duration(i) * time.Microsecond),
+		})
+	}
+	return lines, nil
+}
+
+func (s *PurchaseService) toSupplierResponse(supplier *models.Supplier) *dto.SupplierResponse {
+	return &dto.SupplierResponse{
+		ID:          supplier.ID,
+		Name:        supplier.Name,
+		ContactName: supplier.ContactName,
+		Email:       supplier.Email,
+		Phone:       supplier.Phone,
+		Address:     supplier.Address,
+		IsActive:    supplier.IsActive,
+		CreatedAt:   supplier.CreatedAt,
+		UpdatedAt:   supplier.UpdatedAt,
+	}
+}
+
+func (s *PurchaseService) toPurchaseOrderResponse(order *models.PurchaseOrder) *dto.PurchaseOrderResponse {
+	resp := &dto.PurchaseOrderResponse{
+		ID:           order.ID,
+		PONumber:     order.PONumber,
+		SupplierID:   order.SupplierID,
+		SupplierName: order.SupplierName,
+		StoreID:      order.StoreID,
+		Status:       order.Status,
+		ExpectedAt:   order.ExpectedAt,
+		Notes:        order.Notes,
+		ExpectedCost: order.ExpectedCost,
+		CreatedBy:    order.CreatedBy,
+		OrderedAt:    order.OrderedAt,
+		ReceivedAt:   order.ReceivedAt,
+		CreatedAt:    order.CreatedAt,
+		UpdatedAt:    order.UpdatedAt,
+	}
+
+	for _, line := range order.Lines {
+		resp.Lines = append(resp.Lines, dto.PurchaseOrderLineResponse{
+			ID:               line.ID,
+			ProductID:        line.ProductID,
+			ProductName:      line.ProductName,
+			ProductSKU:       line.ProductSKU,
+			Quantity:         line.Quantity,
+			ReceivedQuantity: line.ReceivedQuantity,
+			UnitCost:         line.UnitCost,
+			LineTotal:        float64(line.Quantity) * line.UnitCost,
+		})
+	}
+
+	return resp
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/ilramdhan/pos-api/internal/dto"
 	"github.com/ilramdhan/pos-api/internal/repository"
@@ -9,33 +10,63 @@ import (
 
 // ReportService handles report generation
 type ReportService struct {
-	transactionRepo repository.TransactionRepository
+	transactionRepo   repository.TransactionRepository
+	purchaseOrderRepo repository.PurchaseOrderRepository
+	stockTransferRepo repository.StockTransferRepository
 }
 
 // NewReportService creates a new report service
-func NewReportService(transactionRepo repository.TransactionRepository) *ReportService {
+func NewReportService(transactionRepo repository.TransactionRepository, purchaseOrderRepo repository.PurchaseOrderRepository, stockTransferRepo repository.StockTransferRepository) *ReportService {
 	return &ReportService{
-		transactionRepo: transactionRepo,
+		transactionRepo:   transactionRepo,
+		purchaseOrderRepo: purchaseOrderRepo,
+		stockTransferRepo: stockTransferRepo,
 	}
 }
 
-// GetDailySales returns daily sales summary
-func (s *ReportService) GetDailySales(ctx context.Context, dateFrom, dateTo string) ([]dto.DailySalesReport, error) {
-	return s.transactionRepo.GetDailySales(ctx, dateFrom, dateTo)
+// GetDailySales returns daily sales summary of one store, or of every store when storeID is empty
+func (s *ReportService) GetDailySales(ctx context.Context, storeID, dateFrom, dateTo string) ([]dto.DailySalesReport, error) {
+	return s.transactionRepo.GetDailySales(ctx, storeID, dateFrom, dateTo)
 }
 
-// GetMonthlySales returns monthly sales summary
-func (s *ReportService) GetMonthlySales(ctx context.Context, dateFrom, dateTo string) ([]dto.MonthlySalesReport, error) {
-	return s.transactionRepo.GetMonthlySales(ctx, dateFrom, dateTo)
+// GetMonthlySales returns monthly sales summary of one store, or of every store when storeID is empty
+func (s *ReportService) GetMonthlySales(ctx context.Context, storeID, dateFrom, dateTo string) ([]dto.MonthlySalesReport, error) {
+	return s.transactionRepo.GetMonthlySales(ctx, storeID, dateFrom, dateTo)
 }
 
-// GetTopProducts returns top selling products
-func (s *ReportService) GetTopProducts(ctx context.Context, limit int, dateFrom, dateTo string) ([]dto.TopProductReport, error) {
+// GetTopProducts returns top selling products of one store, or of every store when storeID is empty
+func (s *ReportService) GetTopProducts(ctx context.Context, storeID string, limit int, dateFrom, dateTo string) ([]dto.TopProductReport, error) {
 	if limit <= 0 {
 		limit = 10
 	}
 	if limit > 100 {
 		limit = 100
 	}
-	return s.transactionRepo.GetTopProducts(ctx, limit, dateFrom, dateTo)
+	return s.transactionRepo.GetTopProducts(ctx, storeID, limit, dateFrom, dateTo)
+}
+
+// GetOutstandingPurchases returns open purchase orders summarised per supplier, for one store
+// or for every store when storeID is empty
+func (s *ReportService) GetOutstandingPurchases(ctx context.Context, storeID string) ([]dto.OutstandingPurchaseReport, error) {
+	reports, err := s.purchaseOrderRepo.GetOutstandingBySupplier(ctx, storeID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if reports == nil {
+		reports = []dto.OutstandingPurchaseReport{}
+	}
+	return reports, nil
+}
+
+// GetInTransitTransfers returns stock shipped between stores but not yet received, valued at
+// cost, for transfers out of or into one store, or for every store when storeID is empty
+func (s *ReportService) GetInTransitTransfers(ctx context.Context, storeID string) ([]dto.InTransitTransferReport, error) {
+	reports, err := s.stockTransferRepo.GetInTransit(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	if reports == nil {
+		reports = []dto.InTransitTransferReport{}
+	}
+	return reports, nil
 }
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/repository"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// permissionCacheTTL bounds how long other API instances keep using a role's old permissions
+// after it changes; the instance that made the change drops its cached copy at once.
+const permissionCacheTTL = time.Minute
+
+// builtInRoleDescriptions describe the built-in roles
+var builtInRoleDescriptions = map[string]string{
+	models.RoleAdmin:   "Full access to the business",
+	models.RoleManager: "Runs a store: catalogue, stock, purchasing and refunds",
+	models.RoleCashier: "Sells at the POS",
+}
+
+// builtInRoleOrder lists the built-in roles in display order
+var builtInRoleOrder = []string{models.RoleAdmin, models.RoleManager, models.RoleCashier}
+
+// RoleService manages roles and resolves the permissions they grant
+type RoleService struct {
+	roleRepo repository.RoleRepository
+
+	mu    sync.RWMutex
+	cache map[string]cachedPermissions
+}
+
+// cachedPermissions are the permissions a role granted, and whether it required two-factor
+// authentication, when they were looked up
+type cachedPermissions struct {
+	permissions map[string]bool
+	requireMFA  bool
+	expiresAt   time.Time
+}
+
+// NewRoleService creates a new role service
+func NewRoleService(roleRepo repository.RoleRepository) *RoleService {
+	return &RoleService{
+		roleRepo: roleRepo,
+		cache:    make(map[string]cachedPermissions),
+	}
+}
+
+func permissionCacheKey(ctx context.Context, role string) string {
+	return utils.TenantID(ctx) + "/" + role
+}
+
+// lookup returns what the current tenant's role grants. A tenant's stored role takes
+// precedence over the built-in defaults; unknown roles grant nothing. The admin role always
+// grants every permission, but its two-factor requirement is the tenant's to set.
+func (s *RoleService) lookup(ctx context.Context, role string) (cachedPermissions, error) {
+	key := permissionCacheKey(ctx, role)
+
+	s.mu.RLock()
+	cached, ok := s.cache[key]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached, nil
+	}
+
+	names := models.BuiltInRoles[role]
+	stored, err := s.roleRepo.GetByName(ctx, role)
+	if err != nil {
+		return cachedPermissions{}, err
+	}
+	requireMFA := false
+	if stored != nil {
+		requireMFA = stored.RequireMFA
+		if role != models.RoleAdmin {
+			names = stored.Permissions
+		}
+	}
+
+	permissions := make(map[string]bool, len(names))
+	for _, name := range names {
+		permissions[name] = true
+	}
+
+	cached = cachedPermissions{permissions: permissions, requireMFA: requireMFA, expiresAt: time.Now().Add(permissionCacheTTL)}
+	s.mu.Lock()
+	s.cache[key] = cached
+	s.mu.Unlock()
+
+	return cached, nil
+}
+
+// invalidate drops the current tenant's cached permissions for a role
+func (s *RoleService) invalidate(ctx context.Context, role string) {
+	s.mu.Lock()
+	delete(s.cache, permissionCacheKey(ctx, role))
+	s.mu.Unlock()
+}
+
+// HasPermission reports whether a role of the current tenant grants a permission
+func (s *RoleService) HasPermission(ctx context.Context, role, permission string) (bool, error) {
+	cached, err := s.lookup(ctx, role)
+	if err != nil {
+		return false, err
+	}
+	return cached.permissions[permission], nil
+}
+
+// RequiresMFA reports whether a role of the current tenant must use two-factor authentication
+func (s *RoleService) RequiresMFA(ctx context.Context, role string) (bool, error) {
+	cached, err := s.lookup(ctx, role)
+	if err != nil {
+		return false, err
+	}
+	return cached.requireMFA, nil
+}
+
+// Permissions lists the permissions a role of the current tenant grants
+func (s *RoleService) Permissions(ctx context.Context, role string) ([]string, error) {
+	cached, err := s.lookup(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, p := range models.AllPermissions {
+		if cached.permissions[p.Name] {
+			names = append(names, p.Name)
+		}
+	}
+	return names, nil
+}
+
+// Exists reports whether a role can be assigned to the current tenant's users
+func (s *RoleService) Exists(ctx context.Context, name string) (bool, error) {
+	if models.IsBuiltInRole(name) {
+		return true, nil
+	}
+	role, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	return role != nil, nil
+}
+
+// List returns the built-in roles followed by the tenant's custom roles
+func (s *RoleService) List(ctx context.Context) ([]*dto.RoleResponse, error) {
+	stored, err := s.roleRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*models.Role, len(stored))
+	for _, role := range stored {
+		byName[role.Name] = role
+	}
+
+	var result []*dto.RoleResponse
+	for _, name := range builtInRoleOrder {
+		result = append(result, toRoleResponse(name, byName[name]))
+	}
+	for _, role := range stored {
+		if !models.IsBuiltInRole(role.Name) {
+			result = append(result, toRoleResponse(role.Name, role))
+		}
+	}
+	return result, nil
+}
+
+// Get returns a role by name
+func (s *RoleService) Get(ctx context.Context, name string) (*dto.RoleResponse, error) {
+	stored, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil && !models.IsBuiltInRole(name) {
+		return nil, errors.New("role not found")
+	}
+	return toRoleResponse(name, stored), nil
+}
+
+// Create creates a custom role
+func (s *RoleService) Create(ctx context.Context, req *dto.CreateRoleRequest) (*dto.RoleResponse, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if models.IsBuiltInRole(name) {
+		return nil, fmt.Errorf("%s is a built-in role", name)
+	}
+	existing, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("role already exists")
+	}
+
+	permissions, err := validatePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	role := &models.Role{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Description: req.Description,
+		Permissions: permissions,
+		RequireMFA:  req.RequireMFA,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.roleRepo.Create(ctx, role); err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx, name)
+
+	return toRoleResponse(name, role), nil
+}
+
+// Update changes a role's description, permissions or two-factor requirement. Changing a
+// built-in role stores the tenant's own copy of it; of the admin role, only the two-factor
+// requirement can be changed.
+func (s *RoleService) Update(ctx context.Context, name string, req *dto.UpdateRoleRequest) (*dto.RoleResponse, error) {
+	if name == models.RoleAdmin && (req.Permissions != nil || req.Description != nil) {
+		return nil, errors.New("the admin role always has every permission")
+	}
+
+	role, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	create := role == nil
+	if create {
+		if !models.IsBuiltInRole(name) {
+			return nil, errors.New("role not found")
+		}
+		role = &models.Role{
+			ID:          uuid.New().String(),
+			Name:        name,
+			Description: builtInRoleDescriptions[name],
+			Permissions: models.BuiltInRoles[name],
+			CreatedAt:   now,
+		}
+	}
+
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+	if req.Permissions != nil {
+		permissions, err := validatePermissions(req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		role.Permissions = permissions
+	}
+	if req.RequireMFA != nil {
+		role.RequireMFA = *req.RequireMFA
+	}
+	role.UpdatedAt = now
+
+	if create {
+		err = s.roleRepo.Create(ctx, role)
+	} else {
+		err = s.roleRepo.Update(ctx, role)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx, name)
+
+	return toRoleResponse(name, role), nil
+}
+
+// Delete removes a custom role that no user holds. Deleting a changed built-in role restores
+// its default permissions and drops its two-factor requirement.
+func (s *RoleService) Delete(ctx context.Context, name string) error {
+	role, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		if models.IsBuiltInRole(name) {
+			return errors.New("built-in roles cannot be deleted")
+		}
+		return errors.New("role not found")
+	}
+
+	if !models.IsBuiltInRole(name) {
+		users, err := s.roleRepo.CountUsers(ctx, name)
+		if err != nil {
+			return err
+		}
+		if users > 0 {
+			return fmt.Errorf("role is assigned to %d users", users)
+		}
+	}
+
+	if err := s.roleRepo.Delete(ctx, role.ID); err != nil {
+		return err
+	}
+	s.invalidate(ctx, name)
+	return nil
+}
+
+// validatePermissions rejects unknown permissions and drops duplicates
+func validatePermissions(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	permissions := []string{}
+	for _, name := range names {
+		if !models.IsPermission(name) {
+			return nil, fmt.Errorf("unknown permission: %s", name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			permissions = append(permissions, name)
+		}
+	}
+	return permissions, nil
+}
+
+// toRoleResponse describes a role from the tenant's stored copy, falling back to the built-in
+// defaults when there is none
+func toRoleResponse(name string, stored *models.Role) *dto.RoleResponse {
+	resp := &dto.RoleResponse{
+		Name:        name,
+		Description: builtInRoleDescriptions[name],
+		Permissions: models.BuiltInRoles[name],
+		BuiltIn:     models.IsBuiltInRole(name),
+	}
+	if stored != nil {
+		resp.RequireMFA = stored.RequireMFA
+	}
+	if stored != nil && name != models.RoleAdmin {
+		resp.Description = stored.Description
+		resp.Permissions = stored.Permissions
+		resp.Customized = resp.BuiltIn
+	}
+	if resp.Permissions == nil {
+		resp.Permissions = []string{}
+	}
+	return resp
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/repository"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// StockTakeService handles stock takes: physical counts of product stock that are approved
+// into adjustment stock movements
+type StockTakeService struct {
+	stockTakeRepo repository.StockTakeRepository
+	categoryRepo  repository.CategoryRepository
+	storeRepo     repository.StoreRepository
+}
+
+// NewStockTakeService creates a new stock take service
+func NewStockTakeService(stockTakeRepo repository.StockTakeRepository, categoryRepo repository.CategoryRepository, storeRepo repository.StoreRepository) *StockTakeService {
+	return &StockTakeService{
+		stockTakeRepo: stockTakeRepo,
+		categoryRepo:  categoryRepo,
+		storeRepo:     storeRepo,
+	}
+}
+
+// OpenStockTake starts counting a category, or the whole store. Overlapping stock takes of the
+// same store cannot be counted at the same time.
+func (s *StockTakeService) OpenStockTake(ctx context.Context, userID string, req *dto.CreateStockTakeRequest) (*dto.StockTakeResponse, error) {
+	if err := checkStore(ctx, s.storeRepo, req.StoreID); err != nil {
+		return nil, err
+	}
+	if req.CategoryID != nil {
+		category, err := s.categoryRepo.GetByID(ctx, *req.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		if category == nil {
+			return nil, errors.New("category not found")
+		}
+	}
+
+	open, err := s.stockTakeRepo.HasOpen(ctx, req.StoreID, req.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, errors.New("an overlapping stock take is already being counted")
+	}
+
+	now := time.Now()
+	id := uuid.New().String()
+	take := &models.StockTake{
+		ID:         id,
+		Reference:  fmt.Sprintf("ST-%s-%s", now.Format("20060102"), id[:8]),
+		StoreID:    req.StoreID,
+		CategoryID: req.CategoryID,
+		Status:     models.StockTakeCounting,
+		Notes:      req.Notes,
+		CreatedBy:  userID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := s.stockTakeRepo.Create(ctx, take); err != nil {
+		return nil, err
+	}
+
+	return s.GetStockTake(ctx, id)
+}
+
+// GetStockTake gets a stock take with its products and variances
+func (s *StockTakeService) GetStockTake(ctx context.Context, id string) (*dto.StockTakeResponse, error) {
+	take, err := s.stockTakeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if take == nil {
+		return nil, errors.New("stock take not found")
+	}
+
+	return s.toResponse(take), nil
+}
+
+// SubmitCounts records counted quantities from one device
+func (s *StockTakeService) SubmitCounts(ctx context.Context, id, userID string, req *dto.SubmitStockCountsRequest) (*dto.StockTakeResponse, error) {
+	take, err := s.stockTakeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if take == nil {
+		return nil, errors.New("stock take not found")
+	}
+	if take.Status != models.StockTakeCounting {
+		return nil, repository.ErrStockTakeNotOpen
+	}
+
+	counts := make([]models.StockCount, 0, len(req.Items))
+	seen := make(map[string]bool, len(req.Items))
+	for _, item := range req.Items {
+		if seen[item.ProductID] {
+			return nil, fmt.Errorf("product %s is counted more than once", item.ProductID)
+		}
+		seen[item.ProductID] = true
+		counts = append(counts, models.StockCount{ProductID: item.ProductID, Quantity: *item.Quantity})
+	}
+	// A consistent lock order keeps concurrent submissions from deadlocking
+	sort.Slice(counts, func(i, j int) bool { return counts[i].ProductID < counts[j].ProductID })
+
+	if err := s.stockTakeRepo.SubmitCounts(ctx, id, counts, req.Mode == "add", userID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.GetStockTake(ctx, id)
+}
+
+// ApproveStockTake posts the counted variances as adjustment stock movements and closes the
+// stock take
+func (s *StockTakeService) ApproveStockTake(ctx context.Context, id, userID string) (*dto.StockTakeResponse, error) {
+	take, err := s.stockTakeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if take == nil {
+		return nil, errors.New("stock take not found")
+	}
+	if take.CountedCount == 0 {
+		return nil, errors.New("no products have been counted")
+	}
+
+	if err := s.stockTakeRepo.Approve(ctx, id, userID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.GetStockTake(ctx, id)
+}
+
+// CancelStockTake abandons a stock take without changing stock
+func (s *StockTakeService) CancelStockTake(ctx context.Context, id string) (*dto.StockTakeResponse, error) {
+	take, err := s.stockTakeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if take == nil {
+		return nil, errors.New("stock take not found")
+	}
+
+	if err := s.stockTakeRepo.Cancel(ctx, id, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.GetStockTake(ctx, id)
+}
+
+// ListStockTakes lists stock takes with pagination and filters
+func (s *StockTakeService) ListStockTakes(ctx context.Context, filter dto.StockTakeListFilter, pagination utils.Pagination) ([]*dto.StockTakeResponse, int, error) {
+	takes, total, err := s.stockTakeRepo.List(ctx, filter, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*dto.StockTakeResponse, 0, len(takes))
+	for _, take := range takes {
+		responses = append(responses, s.toResponse(take))
+	}
+
+	return responses, total, nil
+}
+
+func (s *StockTakeService) toResponse(take *models.StockTake) *dto.StockTakeResponse {
+	resp := &dto.StockTakeResponse{
+		ID:               take.ID,
+		Reference:        take.Reference,
+		StoreID:          take.StoreID,
+		CategoryID:       take.CategoryID,
+		CategoryName:     take.CategoryName,
+		Status:           take.Status,
+		Notes:            take.Notes,
+		ItemCount:        take.ItemCount,
+		CountedCount:     take.CountedCount,
+		VarianceQuantity: take.VarianceQuantity,
+		VarianceValue:    take.VarianceValue,
+		CreatedBy:        take.CreatedBy,
+		ApprovedBy:       take.ApprovedBy,
+		ApprovedAt:       take.ApprovedAt,
+		CreatedAt:        take.CreatedAt,
+		UpdatedAt:        take.UpdatedAt,
+	}
+
+	for i := range take.Items {
+		item := &take.Items[i]
+		itemResp := dto.StockTakeItemResponse{
+			ProductID:       item.ProductID,
+			ProductName:     item.ProductName,
+			ProductSKU:      item.ProductSKU,
+			ExpectedStock:   item.ExpectedStock,
+			CountedQuantity: item.CountedQuantity,
+			Variance:        item.Variance(),
+			CountedBy:       item.CountedBy,
+			CountedAt:       item.CountedAt,
+		}
+		if itemResp.Variance != nil {
+			value := float64(*itemResp.Variance) * item.CostPrice
+			itemResp.VarianceValue = &value
+		}
+		resp.Items = append(resp.Items, itemResp)
+	}
+
+	return resp
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/repository"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// StockTransferService handles stock transfers between stores: requested, shipped by the
+// source store and received by the destination store
+type StockTransferService struct {
+	stockTransferRepo repository.StockTransferRepository
+	storeRepo         repository.StoreRepository
+	productRepo       repository.ProductRepository
+}
+
+// NewStockTransferService creates a new stock transfer service
+func NewStockTransferService(stockTransferRepo repository.StockTransferRepository, storeRepo repository.StoreRepository, productRepo repository.ProductRepository) *StockTransferService {
+	return &StockTransferService{
+		stockTransferRepo: stockTransferRepo,
+		storeRepo:         storeRepo,
+		productRepo:       productRepo,
+	}
+}
+
+// RequestTransfer records a request to move stock between two stores. storeID is the store
+// the user is signed in to, if any; such users may only request transfers involving it.
+func (s *StockTransferService) RequestTransfer(ctx context.Context, userID, storeID string, req *dto.CreateStockTransferRequest) (*dto.StockTransferResponse, error) {
+	toStoreID := req.ToStoreID
+	if toStoreID == "" {
+		toStoreID = storeID
+	}
+	if toStoreID == "" {
+		return nil, errors.New("destination store is required")
+	}
+	if req.FromStoreID == toStoreID {
+		return nil, errors.New("source and destination store must differ")
+	}
+	if storeID != "" && storeID != req.FromStoreID && storeID != toStoreID {
+		return nil, errors.New("transfer must involve your store")
+	}
+	if err := checkStore(ctx, s.storeRepo, &req.FromStoreID); err != nil {
+		return nil, err
+	}
+	if err := checkStore(ctx, s.storeRepo, &toStoreID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	id := uuid.New().String()
+	transfer := &models.StockTransfer{
+		ID:          id,
+		Reference:   fmt.Sprintf("TRF-%s-%s", now.Format("20060102"), id[:8]),
+		FromStoreID: req.FromStoreID,
+		ToStoreID:   toStoreID,
+		Status:      models.TransferRequested,
+		Notes:       req.Notes,
+		RequestedBy: userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	seen := make(map[string]bool, len(req.Items))
+	for _, item := range req.Items {
+		if seen[item.ProductID] {
+			return nil, fmt.Errorf("product %s is listed more than once", item.ProductID)
+		}
+		seen[item.ProductID] = true
+
+		product, err := s.productRepo.GetByID(ctx, item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if product == nil {
+			return nil, fmt.Errorf("product %s not found", item.ProductID)
+		}
+		transfer.Items = append(transfer.Items, models.StockTransferItem{
+			ID:                uuid.New().String(),
+			TransferID:        id,
+			ProductID:         item.ProductID,
+			RequestedQuantity: item.Quantity,
+		})
+	}
+
+	if err := s.stockTransferRepo.Create(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	return s.GetTransfer(ctx, id)
+}
+
+// GetTransfer gets a stock transfer with its products
+func (s *StockTransferService) GetTransfer(ctx context.Context, id string) (*dto.StockTransferResponse, error) {
+	transfer, err := s.stockTransferRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, errors.New("stock transfer not found")
+	}
+
+	return s.toResponse(transfer), nil
+}
+
+// ShipTransfer sends a requested transfer from the source store. Products ship the requested
+// quantity unless the request says otherwise, and never more.
+func (s *StockTransferService) ShipTransfer(ctx context.Context, id, userID, storeID string, req *dto.ShipStockTransferRequest) (*dto.StockTransferResponse, error) {
+	transfer, err := s.stockTransferRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, errors.New("stock transfer not found")
+	}
+	if transfer.Status != models.TransferRequested {
+		return nil, repository.ErrTransferNotRequested
+	}
+	if storeID != "" && storeID != transfer.FromStoreID {
+		return nil, errors.New("only the source store can ship this transfer")
+	}
+
+	quantities := make(map[string]int, len(transfer.Items))
+	for _, item := range transfer.Items {
+		quantities[item.ProductID] = item.RequestedQuantity
+	}
+	if err := applyTransferQuantities(quantities, req.Items); err != nil {
+		return nil, err
+	}
+
+	shipped := 0
+	for _, item := range transfer.Items {
+		if quantities[item.ProductID] > item.RequestedQuantity {
+			return nil, fmt.Errorf("cannot ship more than requested of product %s", item.ProductID)
+		}
+		shipped += quantities[item.ProductID]
+	}
+	if shipped == 0 {
+		return nil, errors.New("nothing to ship; cancel the transfer instead")
+	}
+
+	if err := s.stockTransferRepo.Ship(ctx, id, sortedTransferQuantities(quantities), userID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.GetTransfer(ctx, id)
+}
+
+// ReceiveTransfer books a transfer in at the destination store. Products are received as
+// shipped unless the request says otherwise; a shortage is kept as a discrepancy, but no
+// more than was shipped can be received.
+func (s *StockTransferService) ReceiveTransfer(ctx context.Context, id, userID, storeID string, req *dto.ReceiveStockTransferRequest) (*dto.StockTransferResponse, error) {
+	transfer, err := s.stockTransferRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, errors.New("stock transfer not found")
+	}
+	if transfer.Status != models.TransferInTransit {
+		return nil, repository.ErrTransferNotInTransit
+	}
+	if storeID != "" && storeID != transfer.ToStoreID {
+		return nil, errors.New("only the destination store can receive this transfer")
+	}
+
+	quantities := make(map[string]int, len(transfer.Items))
+	for _, item := range transfer.Items {
+		quantities[item.ProductID] = 0
+		if item.ShippedQuantity != nil {
+			quantities[item.ProductID] = *item.ShippedQuantity
+		}
+	}
+	if err := applyTransferQuantities(quantities, req.Items); err != nil {
+		return nil, err
+	}
+	for _, item := range transfer.Items {
+		shipped := 0
+		if item.ShippedQuantity != nil {
+			shipped = *item.ShippedQuantity
+		}
+		if quantities[item.ProductID] > shipped {
+			return nil, fmt.Errorf("cannot receive more than shipped of product %s", item.ProductID)
+		}
+	}
+
+	if err := s.stockTransferRepo.Receive(ctx, id, sortedTransferQuantities(quantities), userID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.GetTransfer(ctx, id)
+}
+
+// CancelTransfer withdraws a transfer that has not been shipped
+func (s *StockTransferService) CancelTransfer(ctx context.Context, id, storeID string) (*dto.StockTransferResponse, error) {
+	transfer, err := s.stockTransferRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, errors.New("stock transfer not found")
+	}
+	if storeID != "" && storeID != transfer.FromStoreID && storeID != transfer.ToStoreID {
+		return nil, errors.New("transfer must involve your store")
+	}
+
+	if err := s.stockTransferRepo.Cancel(ctx, id, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.GetTransfer(ctx, id)
+}
+
+// ListTransfers lists stock transfers with pagination and filters
+func (s *StockTransferService) ListTransfers(ctx context.Context, filter dto.StockTransferListFilter, pagination utils.Pagination) ([]*dto.StockTransferResponse, int, error) {
+	transfers, total, err := s.stockTransferRepo.List(ctx, filter, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*dto.StockTransferResponse, 0, len(transfers))
+	for _, transfer := range transfers {
+		responses = append(responses, s.toResponse(transfer))
+	}
+
+	return responses, total, nil
+}
+
+// applyTransferQuantities overrides the default quantity of products on a transfer
+func applyTransferQuantities(quantities map[string]int, items []dto.TransferQuantityRequest) error {
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		if _, ok := quantities[item.ProductID]; !ok {
+			return fmt.Errorf("product %s is not on this transfer", item.ProductID)
+		}
+		if seen[item.ProductID] {
+			return fmt.Errorf("product %s is listed more than once", item.ProductID)
+		}
+		seen[item.ProductID] = true
+		quantities[item.ProductID] = *item.Quantity
+	}
+	return nil
+}
+
+// sortedTransferQuantities orders quantities by product so concurrent transfers lock stock rows
+// in the same order
+func sortedTransferQuantities(quantities map[string]int) []models.TransferQuantity {
+	result := make([]models.TransferQuantity, 0, len(quantities))
+	for productID, quantity := range quantities {
+		result = append(result, models.TransferQuantity{ProductID: productID, Quantity: quantity})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ProductID < result[j].ProductID })
+	return result
+}
+
+func (s *StockTransferService) toResponse(transfer *models.StockTransfer) *dto.StockTransferResponse {
+	resp := &dto.StockTransferResponse{
+		ID:                  transfer.ID,
+		Reference:           transfer.Reference,
+		FromStoreID:         transfer.FromStoreID,
+		FromStoreName:       transfer.FromStoreName,
+		ToStoreID:           transfer.ToStoreID,
+		ToStoreName:         transfer.ToStoreName,
+		Status:              transfer.Status,
+		Notes:               transfer.Notes,
+		ItemCount:           transfer.ItemCount,
+		RequestedQuantity:   transfer.RequestedQuantity,
+		ShippedQuantity:     transfer.ShippedQuantity,
+		ReceivedQuantity:    transfer.ReceivedQuantity,
+		DiscrepancyQuantity: transfer.DiscrepancyQuantity,
+		ShippedValue:        transfer.ShippedValue,
+		RequestedBy:         transfer.RequestedBy,
+		ShippedBy:           transfer.ShippedBy,
+		ShippedAt:           transfer.ShippedAt,
+		ReceivedBy:          transfer.ReceivedBy,
+		ReceivedAt:          transfer.ReceivedAt,
+		CreatedAt:           transfer.CreatedAt,
+		UpdatedAt:           transfer.UpdatedAt,
+	}
+
+	for i := range transfer.Items {
+		item := &transfer.Items[i]
+		itemResp := dto.StockTransferItemResponse{
+			ProductID:         item.ProductID,
+			ProductName:       item.ProductName,
+			ProductSKU:        item.ProductSKU,
+			RequestedQuantity: item.RequestedQuantity,
+			ShippedQuantity:   item.ShippedQuantity,
+			ReceivedQuantity:  item.ReceivedQuantity,
+			Discrepancy:       item.Discrepancy(),
+			UnitCost:          item.UnitCost,
+		}
+		if itemResp.Discrepancy != nil {
+			value := float64(*itemResp.Discrepancy) * item.UnitCost
+			itemResp.DiscrepancyValue = &value
+		}
+		resp.Items = append(resp.Items, itemResp)
+	}
+
+	return resp
+}
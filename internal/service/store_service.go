@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/repository"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// StoreService handles stores, store prices and staff assignments
+type StoreService struct {
+	storeRepo      repository.StoreRepository
+	userRepo       repository.UserRepository
+	productRepo    repository.ProductRepository
+	licenseService *LicenseService
+}
+
+// NewStoreService creates a new store service
+func NewStoreService(storeRepo repository.StoreRepository, userRepo repository.UserRepository, productRepo repository.ProductRepository, licenseService *LicenseService) *StoreService {
+	return &StoreService{
+		storeRepo:      storeRepo,
+		userRepo:       userRepo,
+		productRepo:    productRepo,
+		licenseService: licenseService,
+	}
+}
+
+// CreateStore creates a new store
+func (s *StoreService) CreateStore(ctx context.Context, req *dto.CreateStoreRequest) (*dto.StoreResponse, error) {
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	existing, err := s.storeRepo.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("store code already exists")
+	}
+	if err := s.licenseService.CheckStore(ctx); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	store := &models.Store{
+		ID:        uuid.New().String(),
+		Code:      code,
+		Name:      req.Name,
+		Address:   req.Address,
+		Phone:     req.Phone,
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.storeRepo.Create(ctx, store); err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(store), nil
+}
+
+// GetStore gets a store by ID
+func (s *StoreService) GetStore(ctx context.Context, id string) (*dto.StoreResponse, error) {
+	store, err := s.storeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		return nil, errors.New("store not found")
+	}
+
+	return s.toResponse(store), nil
+}
+
+// UpdateStore updates a store's details
+func (s *StoreService) UpdateStore(ctx context.Context, id string, req *dto.UpdateStoreRequest) (*dto.StoreResponse, error) {
+	store, err := s.storeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		return nil, errors.New("store not found")
+	}
+
+	if req.Name != nil {
+		store.Name = *req.Name
+	}
+	if req.Address != nil {
+		store.Address = *req.Address
+	}
+	if req.Phone != nil {
+		store.Phone = *req.Phone
+	}
+	if req.IsActive != nil {
+		if *req.IsActive && !store.IsActive {
+			if err := s.licenseService.CheckStore(ctx); err != nil {
+				return nil, err
+			}
+		}
+		store.IsActive = *req.IsActive
+	}
+	store.UpdatedAt = time.Now()
+
+	if err := s.storeRepo.Update(ctx, store); err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(store), nil
+}
+
+// ListStores lists stores with pagination and filters
+func (s *StoreService) ListStores(ctx context.Context, filter dto.StoreListFilter, pagination utils.Pagination) ([]*dto.StoreResponse, int, error) {
+	stores, total, err := s.storeRepo.List(ctx, filter, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*dto.StoreResponse, 0, len(stores))
+	for _, store := range stores {
+		responses = append(responses, s.toResponse(store))
+	}
+
+	return responses, total, nil
+}
+
+// SetPrices overrides product prices at a store. Items with a null price fall back to the
+// product's own price again.
+func (s *StoreService) SetPrices(ctx context.Context, storeID string, req *dto.SetStorePricesRequest) error {
+	store, err := s.storeRepo.GetByID(ctx, storeID)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return errors.New("store not found")
+	}
+
+	prices := make([]models.StorePrice, 0, len(req.Items))
+	seen := make(map[string]bool, len(req.Items))
+	for _, item := range req.Items {
+		if seen[item.ProductID] {
+			return fmt.Errorf("product %s is listed more than once", item.ProductID)
+		}
+		seen[item.ProductID] = true
+
+		product, err := s.productRepo.GetByID(ctx, item.ProductID)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return fmt.Errorf("product %s not found", item.ProductID)
+		}
+		prices = append(prices, models.StorePrice{ProductID: item.ProductID, Price: item.Price})
+	}
+
+	return s.storeRepo.SetPrices(ctx, storeID, prices, time.Now())
+}
+
+// AssignUserStores replaces the stores a user works at
+func (s *StoreService) AssignUserStores(ctx context.Context, userID string, req *dto.AssignUserStoresRequest) ([]*dto.StoreResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	storeIDs := make([]string, 0, len(req.StoreIDs))
+	seen := make(map[string]bool, len(req.StoreIDs))
+	for _, storeID := range req.StoreIDs {
+		if seen[storeID] {
+			continue
+		}
+		seen[storeID] = true
+
+		store, err := s.storeRepo.GetByID(ctx, storeID)
+		if err != nil {
+			return nil, err
+		}
+		if store == nil {
+			return nil, fmt.Errorf("store %s not found", storeID)
+		}
+		storeIDs = append(storeIDs, storeID)
+	}
+
+	if err := s.storeRepo.SetUserStores(ctx, userID, storeIDs, time.Now()); err != nil {
+		return nil, err
+	}
+
+	stores, err := s.storeRepo.ListUserStores(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.StoreResponse, 0, len(stores))
+	for _, store := range stores {
+		responses = append(responses, s.toResponse(store))
+	}
+
+	return responses, nil
+}
+
+func (s *StoreService) toResponse(store *models.Store) *dto.StoreResponse {
+	return &dto.StoreResponse{
+		ID:        store.ID,
+		Code:      store.Code,
+		Name:      store.Name,
+		Address:   store.Address,
+		Phone:     store.Phone,
+		IsActive:  store.IsActive,
+		CreatedAt: store.CreatedAt,
+		UpdatedAt: store.UpdatedAt,
+	}
+}
+
+// checkStore verifies that an optional store reference points at an active store
+func checkStore(ctx context.Context, storeRepo repository.StoreRepository, storeID *string) error {
+	if storeID == nil {
+		return nil
+	}
+
+	store, err := storeRepo.GetByID(ctx, *storeID)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return errors.New("store not found")
+	}
+	if !store.IsActive {
+		return errors.New("store is inactive")
+	}
+
+	return nil
+}
+
+// resolveStore picks the store a user signs in to. A requested store must be active and, for
+// users with store assignments, one of their stores. Without a request the user's first active
+// store is used; users without assignments then work without a store.
+func resolveStore(ctx context.Context, storeRepo repository.StoreRepository, userID, requested string) (string, error) {
+	assigned, err := storeRepo.ListUserStores(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if requested == "" {
+		for _, store := range assigned {
+			if store.IsActive {
+				return store.ID, nil
+			}
+		}
+		if len(assigned) > 0 {
+			return "", errors.New("none of the user's stores is active")
+		}
+		return "", nil
+	}
+
+	if err := checkStore(ctx, storeRepo, &requested); err != nil {
+		return "", err
+	}
+	if len(assigned) == 0 {
+		return requested, nil
+	}
+	for _, store := range assigned {
+		if store.ID == requested {
+			return requested, nil
+		}
+	}
+
+	return "", errors.New("user is not assigned to this store")
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ilramdhan/pos-api/internal/config"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/storage"
+)
+
+// healthCheckTimeout bounds each dependency check of the health report
+const healthCheckTimeout = 3 * time.Second
+
+// Health statuses
+const (
+	healthOK       = "ok"
+	healthError    = "error"
+	healthDegraded = "degraded"
+)
+
+// SystemService reports the health of the database, storage and registered terminals
+type SystemService struct {
+	db              *sql.DB
+	store           storage.BlobStore
+	terminalService *TerminalService
+	cfg             *config.Config
+	startedAt       time.Time
+}
+
+// NewSystemService creates a new system health service
+func NewSystemService(db *sql.DB, store storage.BlobStore, terminalService *TerminalService, cfg *config.Config) *SystemService {
+	return &SystemService{
+		db:              db,
+		store:           store,
+		terminalService: terminalService,
+		cfg:             cfg,
+		startedAt:       time.Now(),
+	}
+}
+
+// Health pings the database and storage and reports each of the tenant's terminals as online
+// or offline from their heartbeats
+func (s *SystemService) Health(ctx context.Context) (*dto.SystemHealthResponse, error) {
+	now := time.Now()
+	resp := &dto.SystemHealthResponse{
+		Status:    healthOK,
+		Version:   s.cfg.App.Version,
+		Uptime:    int64(now.Sub(s.startedAt).Seconds()),
+		CheckedAt: now,
+		Database:  s.checkDatabase(ctx),
+		Integrations: []dto.IntegrationHealth{
+			s.checkStorage(ctx),
+		},
+	}
+	if resp.Database.Status != healthOK {
+		resp.Status = healthDegraded
+		// Terminals cannot be listed without the database
+		resp.Terminals = []*dto.TerminalResponse{}
+		return resp, nil
+	}
+	for _, integration := range resp.Integrations {
+		if integration.Status != healthOK {
+			resp.Status = healthDegraded
+		}
+	}
+
+	terminals, err := s.terminalService.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	resp.Terminals = terminals
+	for _, terminal := range terminals {
+		switch terminal.Status {
+		case models.TerminalOnline:
+			resp.Online++
+		case models.TerminalOffline:
+			resp.Offline++
+		}
+	}
+	return resp, nil
+}
+
+// checkDatabase pings the database and reads the connection pool statistics
+func (s *SystemService) checkDatabase(ctx context.Context) dto.DatabaseHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := s.db.PingContext(ctx)
+	stats := s.db.Stats()
+	health := dto.DatabaseHealth{
+		Status:          healthOK,
+		LatencyMS:       time.Since(start).Milliseconds(),
+		OpenConnections: stats.OpenConnections,
+		InUse:           stats.InUse,
+		Idle:            stats.Idle,
+		MaxOpen:         stats.MaxOpenConnections,
+		WaitCount:       stats.WaitCount,
+		WaitMS:          stats.WaitDuration.Milliseconds(),
+	}
+	if err != nil {
+		health.Status = healthError
+		health.Error = err.Error()
+	}
+	return health
+}
+
+// checkStorage reads an object that does not exist, which needs a working connection to the
+// store but no data in it
+func (s *SystemService) checkStorage(ctx context.Context) dto.IntegrationHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err := s.store.Get(ctx, "health/check")
+	health := dto.IntegrationHealth{
+		Name:      "storage (" + s.cfg.Storage.Driver + ")",
+		Status:    healthOK,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		health.Status = healthError
+		health.Error = err.Error()
+	}
+	return health
+}
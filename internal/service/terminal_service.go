@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/config"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/repository"
+	"github.com/ilramdhan/pos-api/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrInvalidTerminal is returned for terminal keys that are unknown or deactivated
+	ErrInvalidTerminal = errors.New("terminal is not registered or is inactive")
+	// ErrInvalidPIN is returned when a PIN sign-in fails
+	ErrInvalidPIN = errors.New("invalid user or PIN")
+	// ErrPINLocked is returned while a user's or terminal's PIN sign-in is locked
+	ErrPINLocked = errors.New("too many wrong PINs, try again later")
+	// ErrPINNotAllowed is returned for PIN sign-ins of users who must use two-factor authentication
+	ErrPINNotAllowed = errors.New("PIN sign-in is not available with two-factor authentication; sign in with your password")
+)
+
+// TerminalService registers POS terminals and kitchen displays, keeps track of their
+// heartbeats and signs staff in to POS terminals with their PIN
+type TerminalService struct {
+	terminalRepo repository.TerminalRepository
+	keyRepo      repository.TerminalRepository // finds terminals by key across tenants
+	userRepo     repository.UserRepository
+	storeRepo    repository.StoreRepository
+	attemptRepo  repository.LoginAttemptRepository
+	mfaService   *MFAService
+	jwtManager   *utils.JWTManager
+	cfg          config.TerminalConfig
+}
+
+// NewTerminalService creates a new terminal service
+func NewTerminalService(
+	terminalRepo repository.TerminalRepository,
+	keyRepo repository.TerminalRepository,
+	userRepo repository.UserRepository,
+	storeRepo repository.StoreRepository,
+	attemptRepo repository.LoginAttemptRepository,
+	mfaService *MFAService,
+	jwtManager *utils.JWTManager,
+	cfg config.TerminalConfig,
+) *TerminalService {
+	return &TerminalService{
+		terminalRepo: terminalRepo,
+		keyRepo:      keyRepo,
+		userRepo:     userRepo,
+		storeRepo:    storeRepo,
+		attemptRepo:  attemptRepo,
+		mfaService:   mfaService,
+		jwtManager:   jwtManager,
+		cfg:          cfg,
+	}
+}
+
+// SessionDuration is the lifetime of a PIN session's token
+func (s *TerminalService) SessionDuration() time.Duration {
+	return time.Duration(s.cfg.SessionMinutes) * time.Minute
+}
+
+// Create registers a terminal at a store and issues its key
+func (s *TerminalService) Create(ctx context.Context, req *dto.CreateTerminalRequest) (*dto.TerminalResponse, error) {
+	if err := checkStore(ctx, s.storeRepo, &req.StoreID); err != nil {
+		return nil, err
+	}
+
+	key, err := newSecretToken()
+	if err != nil {
+		return nil, err
+	}
+
+	terminalType := req.Type
+	if terminalType == "" {
+		terminalType = models.TerminalTypePOS
+	}
+
+	now := time.Now()
+	terminal := &models.Terminal{
+		ID:        uuid.New().String(),
+		StoreID:   req.StoreID,
+		Name:      req.Name,
+		Type:      terminalType,
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.terminalRepo.Create(ctx, terminal, hashSecretToken(key)); err != nil {
+		return nil, err
+	}
+
+	resp := s.toTerminalResponse(terminal)
+	resp.Key = key
+	return resp, nil
+}
+
+// List lists terminals, optionally only those of one store
+func (s *TerminalService) List(ctx context.Context, storeID string) ([]*dto.TerminalResponse, error) {
+	terminals, err := s.terminalRepo.List(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*dto.TerminalResponse{}
+	for _, terminal := range terminals {
+		result = append(result, s.toTerminalResponse(terminal))
+	}
+	return result, nil
+}
+
+// Update renames, deactivates or reactivates a terminal
+func (s *TerminalService) Update(ctx context.Context, id string, req *dto.UpdateTerminalRequest) (*dto.TerminalResponse, error) {
+	terminal, err := s.terminalRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if terminal == nil {
+		return nil, errors.New("terminal not found")
+	}
+
+	if req.Name != nil {
+		terminal.Name = *req.Name
+	}
+	if req.IsActive != nil {
+		terminal.IsActive = *req.IsActive
+	}
+	terminal.UpdatedAt = time.Now()
+
+	if err := s.terminalRepo.Update(ctx, terminal); err != nil {
+		return nil, err
+	}
+	return s.toTerminalResponse(terminal), nil
+}
+
+// RegenerateKey issues a new key for a terminal; the old key stops working at once
+func (s *TerminalService) RegenerateKey(ctx context.Context, id string) (*dto.TerminalResponse, error) {
+	terminal, err := s.terminalRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if terminal == nil {
+		return nil, errors.New("terminal not found")
+	}
+
+	key, err := newSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	terminal.UpdatedAt = time.Now()
+	if err := s.terminalRepo.SetKeyHash(ctx, id, hashSecretToken(key), terminal.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	resp := s.toTerminalResponse(terminal)
+	resp.Key = key
+	return resp, nil
+}
+
+// Delete removes a terminal
+func (s *TerminalService) Delete(ctx context.Context, id string) error {
+	terminal, err := s.terminalRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if terminal == nil {
+		return errors.New("terminal not found")
+	}
+	return s.terminalRepo.Delete(ctx, id)
+}
+
+// terminal finds the active terminal holding a key and scopes ctx to its tenant
+func (s *TerminalService) terminal(ctx context.Context, key string) (*models.Terminal, context.Context, error) {
+	if key == "" {
+		return nil, ctx, ErrInvalidTerminal
+	}
+	terminal, err := s.keyRepo.GetByKeyHash(ctx, hashSecretToken(key))
+	if err != nil {
+		return nil, ctx, err
+	}
+	if terminal == nil || !terminal.IsActive {
+		return nil, ctx, ErrInvalidTerminal
+	}
+	return terminal, utils.WithTenant(ctx, terminal.TenantID), nil
+}
+
+// Heartbeat records that the terminal holding the key is up, with its app version and latency
+func (s *TerminalService) Heartbeat(ctx context.Context, key string, req *dto.HeartbeatRequest) (*dto.HeartbeatResponse, error) {
+	terminal, ctx, err := s.terminal(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.terminalRepo.SetHeartbeat(ctx, terminal.ID, req.AppVersion, req.LatencyMS, now); err != nil {
+		return nil, err
+	}
+	return &dto.HeartbeatResponse{
+		TerminalID:      terminal.ID,
+		ServerTime:      now,
+		NextHeartbeatIn: s.cfg.HeartbeatSeconds,
+	}, nil
+}
+
+// Users lists the users who can sign in to a terminal with their PIN
+func (s *TerminalService) Users(ctx context.Context, key string) ([]*dto.TerminalUserResponse, error) {
+	terminal, ctx, err := s.terminal(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.userRepo.ListPINUsers(ctx, terminal.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*dto.TerminalUserResponse{}
+	for _, user := range users {
+		result = append(result, &dto.TerminalUserResponse{ID: user.ID, Name: user.Name, Role: user.Role})
+	}
+	return result, nil
+}
+
+// PINLogin signs a user in to a terminal with their PIN. The session is bound to the terminal
+// and its store and cannot be refreshed. Wrong PINs lock the user's PIN sign-in, and that of
+// the terminal, for a while once they reach the configured limits. A PIN is a single factor, so
+// users with two-factor authentication, or whose role requires it, cannot sign in with one.
+func (s *TerminalService) PINLogin(ctx context.Context, key string, req *dto.PINLoginRequest) (*dto.AuthResponse, error) {
+	terminal, ctx, err := s.terminal(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	terminalKey := "pin:terminal:" + terminal.ID
+	userKey := pinUserKey(req.UserID)
+	for _, key := range []string{terminalKey, userKey} {
+		attempt, err := s.attemptRepo.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if attempt.IsLocked(now) {
+			return nil, ErrPINLocked
+		}
+	}
+
+	user, err := s.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive || user.PINHash == "" ||
+		bcrypt.CompareHashAndPassword([]byte(user.PINHash), []byte(req.PIN)) != nil {
+		if err := s.recordFailure(ctx, terminalKey, s.cfg.TerminalMaxAttempts, now); err != nil {
+			return nil, err
+		}
+		if user != nil {
+			if err := s.recordFailure(ctx, userKey, s.cfg.PINMaxAttempts, now); err != nil {
+				return nil, err
+			}
+		}
+		return nil, ErrInvalidPIN
+	}
+
+	for _, key := range []string{terminalKey, userKey} {
+		if err := s.attemptRepo.Reset(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.mfaService.AllowsPIN(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// The user must be allowed to work at the terminal's store
+	storeID, err := resolveStore(ctx, s.storeRepo, user.ID, terminal.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.terminalRepo.SetLastUsed(ctx, terminal.ID, now); err != nil {
+		return nil, err
+	}
+
+	expiry := s.SessionDuration()
+	accessToken, err := s.jwtManager.GenerateTerminalToken(user, storeID, terminal.ID, expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthResponse{
+		User: dto.UserResponse{
+			ID:       user.ID,
+			Email:    user.Email,
+			Name:     user.Name,
+			Role:     user.Role,
+			IsActive: user.IsActive,
+		},
+		Token: dto.TokenResponse{
+			AccessToken: accessToken,
+			ExpiresIn:   int64(expiry.Seconds()),
+			TokenType:   "Bearer",
+		},
+		StoreID:    storeID,
+		TenantID:   user.TenantID,
+		TerminalID: terminal.ID,
+	}, nil
+}
+
+// recordFailure counts a wrong PIN for a key and locks the key once it reaches maxAttempts
+func (s *TerminalService) recordFailure(ctx context.Context, key string, maxAttempts int, now time.Time) error {
+	failures, err := s.attemptRepo.RecordFailure(ctx, key, now)
+	if err != nil {
+		return err
+	}
+	if maxAttempts > 0 && failures >= maxAttempts {
+		lockout := time.Duration(s.cfg.LockoutMinutes) * time.Minute
+		return s.attemptRepo.Lock(ctx, key, now.Add(lockout), now)
+	}
+	return nil
+}
+
+func (s *TerminalService) toTerminalResponse(terminal *models.Terminal) *dto.TerminalResponse {
+	return &dto.TerminalResponse{
+		ID:         terminal.ID,
+		StoreID:    terminal.StoreID,
+		Name:       terminal.Name,
+		Type:       terminal.Type,
+		IsActive:   terminal.IsActive,
+		Status:     terminal.Status(time.Now(), time.Duration(s.cfg.OfflineSeconds)*time.Second),
+		AppVersion: terminal.AppVersion,
+		LatencyMS:  terminal.LatencyMS,
+		LastUsedAt: terminal.LastUsedAt,
+		LastSeenAt: terminal.LastSeenAt,
+		CreatedAt:  terminal.CreatedAt,
+		UpdatedAt:  terminal.UpdatedAt,
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,39 +18,86 @@ import (
 const (
 	// TaxRate is the default tax rate (10%)
 	TaxRate = 0.10
-	// LoyaltyPointsPerTransaction is points earned per transaction
-	LoyaltyPointsPerTransaction = 10
 )
 
 // TransactionService handles transaction operations
 type TransactionService struct {
-	transactionRepo repository.TransactionRepository
-	productRepo     repository.ProductRepository
-	customerRepo    repository.CustomerRepository
+	transactionRepo  repository.TransactionRepository
+	productRepo      repository.ProductRepository
+	optionRepo       repository.ProductOptionRepository
+	customerRepo     repository.CustomerRepository
+	voucherRepo      repository.VoucherRepository
+	loyaltyService   *LoyaltyService
+	inventoryService *InventoryService
+	overrideService  *OverrideService
 }
 
 // NewTransactionService creates a new transaction service
 func NewTransactionService(
 	transactionRepo repository.TransactionRepository,
 	productRepo repository.ProductRepository,
+	optionRepo repository.ProductOptionRepository,
 	customerRepo repository.CustomerRepository,
+	voucherRepo repository.VoucherRepository,
+	loyaltyService *LoyaltyService,
+	inventoryService *InventoryService,
+	overrideService *OverrideService,
 ) *TransactionService {
 	return &TransactionService{
-		transactionRepo: transactionRepo,
-		productRepo:     productRepo,
-		customerRepo:    customerRepo,
+		transactionRepo:  transactionRepo,
+		productRepo:      productRepo,
+		optionRepo:       optionRepo,
+		customerRepo:     customerRepo,
+		voucherRepo:      voucherRepo,
+		loyaltyService:   loyaltyService,
+		inventoryService: inventoryService,
+		overrideService:  overrideService,
 	}
 }
 
-// Create creates a new transaction (sale)
-func (s *TransactionService) Create(ctx context.Context, userID string, req *dto.CreateTransactionRequest) (*dto.TransactionResponse, error) {
+// pendingApproval is an action in a sale that needs permission or a manager's approval
+type pendingApproval struct {
+	action string
+	token  string
+	amount float64 // The discount or unit price to approve
+}
+
+// Create creates a new transaction (sale). A sale at a store takes the store's stock and sells
+// at the store's prices. Manual discounts and price overrides need the role's permission or a
+// manager's approval.
+func (s *TransactionService) Create(ctx context.Context, userID, role, storeID, terminalID string, req *dto.CreateTransactionRequest) (*dto.TransactionResponse, error) {
 	now := time.Now()
 	transactionID := uuid.New().String()
 	invoiceNumber := fmt.Sprintf("INV-%s-%s", now.Format("20060102"), transactionID[:8])
 
+	hasCustomer := req.CustomerID != nil && *req.CustomerID != ""
+	if hasCustomer {
+		customer, err := s.customerRepo.GetByID(ctx, *req.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+		if customer == nil {
+			return nil, errors.New("customer not found")
+		}
+	}
+
+	// Products with a recipe draw stock from their ingredients instead of their own count
+	productIDs := make([]string, 0, len(req.Items))
+	for _, itemReq := range req.Items {
+		productIDs = append(productIDs, itemReq.ProductID)
+	}
+	recipes, err := s.inventoryService.recipesFor(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build transaction items and calculate totals
 	var items []models.TransactionItem
+	var approvals []pendingApproval
 	var subtotal float64
+	productStock := make(map[string]int)
+	variantStock := make(map[string]int)
+	variantProducts := make(map[string]string)
 
 	for _, itemReq := range req.Items {
 		product, err := s.productRepo.GetByID(ctx, itemReq.ProductID)
@@ -61,33 +110,165 @@ func (s *TransactionService) Create(ctx context.Context, userID string, req *dto
 		if !product.IsActive {
 			return nil, fmt.Errorf("product %s is not available", product.Name)
 		}
-		if !product.HasSufficientStock(itemReq.Quantity) {
-			return nil, fmt.Errorf("insufficient stock for product %s", product.Name)
+		if storeID != "" {
+			if err := s.productRepo.ApplyStoreLevels(ctx, storeID, []*models.Product{product}); err != nil {
+				return nil, err
+			}
+		}
+
+		// A scanned code picks the variant and may carry the price of a weighed item
+		var embedded *dto.EmbeddedBarcodeValue
+		if itemReq.Barcode != "" {
+			match, err := matchBarcode(ctx, s.productRepo, s.optionRepo, itemReq.Barcode)
+			if err != nil {
+				return nil, err
+			}
+			if match.product.ID != product.ID {
+				return nil, fmt.Errorf("barcode %s does not belong to product %s", itemReq.Barcode, product.Name)
+			}
+			if match.variant != nil {
+				if itemReq.VariantID != "" && itemReq.VariantID != match.variant.ID {
+					return nil, fmt.Errorf("barcode %s does not belong to the selected variant", itemReq.Barcode)
+				}
+				itemReq.VariantID = match.variant.ID
+			}
+			embedded = match.embedded
+		}
+
+		itemID := uuid.New().String()
+		variant, modifiers, unitPrice, err := s.resolveOptions(ctx, product, itemReq, itemID)
+		if err != nil {
+			return nil, err
+		}
+		if embedded != nil {
+			unitPrice = embedded.Price
+			for _, modifier := range modifiers {
+				unitPrice += modifier.PriceDelta
+			}
+		}
+		if itemReq.PriceOverride != nil && *itemReq.PriceOverride != unitPrice {
+			unitPrice = *itemReq.PriceOverride
+			approvals = append(approvals, pendingApproval{models.OverridePriceOverride, itemReq.OverrideToken, unitPrice})
+		}
+
+		// Products sold by variant keep stock on the variant. Stock is taken when the sale is
+		// saved; checking it here reports a short item by name.
+		if variant != nil {
+			if storeID != "" {
+				if err := s.optionRepo.ApplyStoreStock(ctx, storeID, []*models.ProductVariant{variant}); err != nil {
+					return nil, err
+				}
+			}
+			variantProducts[variant.ID] = product.ID
+			variantStock[variant.ID] -= itemReq.Quantity
+			if variant.Stock+variantStock[variant.ID] < 0 {
+				return nil, fmt.Errorf("insufficient stock for product %s (%s)", product.Name, variant.Name)
+			}
+		} else if len(recipes[product.ID]) == 0 {
+			productStock[product.ID] -= itemReq.Quantity
+			if product.Stock+productStock[product.ID] < 0 {
+				return nil, fmt.Errorf("insufficient stock for product %s", product.Name)
+			}
 		}
 
-		itemSubtotal := product.Price * float64(itemReq.Quantity)
+		itemSubtotal := unitPrice * float64(itemReq.Quantity)
 		item := models.TransactionItem{
-			ID:            uuid.New().String(),
+			ID:            itemID,
 			TransactionID: transactionID,
 			ProductID:     product.ID,
 			ProductName:   product.Name,
-			UnitPrice:     product.Price,
+			UnitPrice:     unitPrice,
 			Quantity:      itemReq.Quantity,
 			Subtotal:      itemSubtotal,
 			CreatedAt:     now,
+			Modifiers:     modifiers,
+		}
+		if variant != nil {
+			item.VariantID = &variant.ID
+			item.VariantName = variant.Name
 		}
 		items = append(items, item)
 		subtotal += itemSubtotal
+	}
 
-		// Update product stock
-		newStock := product.Stock - itemReq.Quantity
-		if err := s.productRepo.UpdateStock(ctx, product.ID, newStock); err != nil {
+	var transactionStoreID *string
+	if storeID != "" {
+		transactionStoreID = &storeID
+	}
+
+	stockMovements, err := s.inventoryService.saleMovements(recipes, items, transactionID, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	stockMovements = append(stockMovements,
+		productMovements(productStock, transactionStoreID, models.MovementSale, transactionID, invoiceNumber, userID, now)...)
+	stockMovements = append(stockMovements,
+		variantMovements(variantStock, variantProducts, transactionStoreID, models.MovementSale, transactionID, invoiceNumber, userID, now)...)
+
+	// Discounts together never take more than the amount due
+	taxAmount := subtotal * TaxRate
+	discountAmount := req.DiscountAmount
+	if discountAmount > subtotal+taxAmount {
+		return nil, fmt.Errorf("discount (%.2f) exceeds the amount due (%.2f)", discountAmount, subtotal+taxAmount)
+	}
+	if discountAmount > 0 {
+		approvals = append(approvals, pendingApproval{models.OverrideDiscount, req.DiscountOverrideToken, discountAmount})
+	}
+
+	// Apply voucher discount, up to what is left to pay; the usage is locked and recorded when
+	// the transaction is saved
+	var redemption *models.VoucherRedemption
+	var voucherCode string
+	if req.VoucherCode != "" {
+		voucher, voucherDiscount, err := resolveVoucher(ctx, s.voucherRepo, req.VoucherCode, subtotal, req.CustomerID, now)
+		if err != nil {
 			return nil, err
 		}
+		remaining := subtotal + taxAmount - discountAmount
+		if remaining <= 0 {
+			return nil, errors.New("nothing is left to pay to use the voucher on")
+		}
+		voucherDiscount = math.Min(voucherDiscount, remaining)
+		voucherCode = voucher.Code
+		discountAmount += voucherDiscount
+		redemption = &models.VoucherRedemption{
+			ID:             uuid.New().String(),
+			VoucherID:      voucher.ID,
+			TransactionID:  transactionID,
+			CustomerID:     req.CustomerID,
+			UserID:         userID,
+			DiscountAmount: voucherDiscount,
+			CreatedAt:      now,
+		}
 	}
 
-	taxAmount := subtotal * TaxRate
-	totalAmount := subtotal + taxAmount - req.DiscountAmount
+	// Redeem loyalty points against the amount still due; the balance is locked when saved
+	var loyaltyEntries []*models.LoyaltyLedgerEntry
+	if req.RedeemPoints > 0 {
+		entry, pointsDiscount, err := s.loyaltyService.redeemEntry(
+			ctx, req.CustomerID, req.RedeemPoints, subtotal+taxAmount-discountAmount, transactionID, userID, now,
+		)
+		if err != nil {
+			return nil, err
+		}
+		discountAmount += pointsDiscount
+		loyaltyEntries = append(loyaltyEntries, entry)
+	}
+
+	totalAmount := subtotal + taxAmount - discountAmount
+
+	// Earn points on the amount actually paid
+	pointsEarned := 0
+	if hasCustomer {
+		entry, err := s.loyaltyService.earnEntry(ctx, *req.CustomerID, totalAmount, transactionID, userID, now)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			pointsEarned = entry.Points
+			loyaltyEntries = append(loyaltyEntries, entry)
+		}
+	}
 
 	transaction := &models.Transaction{
 		ID:             transactionID,
@@ -96,27 +277,43 @@ func (s *TransactionService) Create(ctx context.Context, userID string, req *dto
 		InvoiceNumber:  invoiceNumber,
 		Subtotal:       subtotal,
 		TaxAmount:      taxAmount,
-		DiscountAmount: req.DiscountAmount,
+		DiscountAmount: discountAmount,
 		TotalAmount:    totalAmount,
 		PaymentMethod:  req.PaymentMethod,
 		Status:         models.StatusCompleted,
 		Notes:          req.Notes,
+		VoucherCode:    voucherCode,
+		PointsEarned:   pointsEarned,
+		PointsRedeemed: req.RedeemPoints,
+		StoreID:        transactionStoreID,
 		CreatedAt:      now,
 		UpdatedAt:      now,
 		Items:          items,
+		Redemption:     redemption,
+		LoyaltyEntries: loyaltyEntries,
+		StockMovements: stockMovements,
 	}
 
-	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
-		return nil, err
+	// Approvals are used up with the sale, so a sale that fails leaves them unused; items at the
+	// same price may share one
+	authorized := make(map[pendingApproval]bool)
+	for _, approval := range approvals {
+		if authorized[approval] {
+			continue
+		}
+		amount := approval.amount
+		use, err := s.overrideService.Authorize(ctx, userID, role, terminalID, approval.action, transactionID, approval.token, &amount)
+		if err != nil {
+			return nil, err
+		}
+		if use != nil {
+			transaction.Overrides = append(transaction.Overrides, use)
+		}
+		authorized[approval] = true
 	}
 
-	// Add loyalty points to customer if specified
-	if req.CustomerID != nil && *req.CustomerID != "" {
-		customer, err := s.customerRepo.GetByID(ctx, *req.CustomerID)
-		if err == nil && customer != nil {
-			newPoints := customer.LoyaltyPoints + LoyaltyPointsPerTransaction
-			_ = s.customerRepo.UpdateLoyaltyPoints(ctx, *req.CustomerID, newPoints)
-		}
+	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
+		return nil, err
 	}
 
 	return s.toResponse(transaction), nil
@@ -136,7 +333,20 @@ func (s *TransactionService) GetByID(ctx context.Context, id string) (*dto.Trans
 }
 
 // UpdateStatus updates a transaction's status
-func (s *TransactionService) UpdateStatus(ctx context.Context, id string, req *dto.UpdateTransactionStatusRequest) (*dto.TransactionResponse, error) {
+func (s *TransactionService) UpdateStatus(ctx context.Context, userID, role, terminalID, id string, req *dto.UpdateTransactionStatusRequest) (*dto.TransactionResponse, error) {
+	// Refunds need refund permission; every other change needs the permission to void
+	action := models.OverrideVoid
+	if req.Status == models.StatusRefunded {
+		action = models.OverrideRefund
+	}
+
+	// Without a token this only checks the role, so that a missing approval is reported first
+	if req.OverrideToken == "" {
+		if _, err := s.overrideService.Authorize(ctx, userID, role, terminalID, action, id, "", nil); err != nil {
+			return nil, err
+		}
+	}
+
 	transaction, err := s.transactionRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -151,33 +361,38 @@ func (s *TransactionService) UpdateStatus(ctx context.Context, id string, req *d
 		if !transaction.IsCancellable() {
 			return nil, errors.New("transaction cannot be cancelled")
 		}
-		// Restore stock for cancelled transactions
-		for _, item := range transaction.Items {
-			product, err := s.productRepo.GetByID(ctx, item.ProductID)
-			if err == nil && product != nil {
-				newStock := product.Stock + item.Quantity
-				_ = s.productRepo.UpdateStock(ctx, item.ProductID, newStock)
-			}
-		}
 	case models.StatusRefunded:
 		if !transaction.IsRefundable() {
 			return nil, errors.New("transaction cannot be refunded")
 		}
-		// Restore stock for refunded transactions
-		for _, item := range transaction.Items {
-			product, err := s.productRepo.GetByID(ctx, item.ProductID)
-			if err == nil && product != nil {
-				newStock := product.Stock + item.Quantity
-				_ = s.productRepo.UpdateStock(ctx, item.ProductID, newStock)
-			}
+	}
+
+	// The approval is used up with the status change
+	if req.OverrideToken != "" {
+		use, err := s.overrideService.Authorize(ctx, userID, role, terminalID, action, id, req.OverrideToken, nil)
+		if err != nil {
+			return nil, err
+		}
+		if use != nil {
+			transaction.Overrides = append(transaction.Overrides, use)
+		}
+	}
+
+	// Cancelled and refunded sales put back their stock and ingredients, return redeemed points
+	// and take back earned points, together with the status change
+	now := time.Now()
+	if req.Status == models.StatusCancelled || req.Status == models.StatusRefunded {
+		if err := s.prepareReversal(ctx, transaction, userID, now); err != nil {
+			return nil, err
 		}
 	}
 
-	if err := s.transactionRepo.UpdateStatus(ctx, id, req.Status); err != nil {
+	if err := s.transactionRepo.UpdateStatus(ctx, transaction, req.Status, now); err != nil {
 		return nil, err
 	}
 
 	transaction.Status = req.Status
+	transaction.UpdatedAt = now
 	return s.toResponse(transaction), nil
 }
 
@@ -196,6 +411,202 @@ func (s *TransactionService) List(ctx context.Context, filter dto.TransactionLis
 	return responses, total, nil
 }
 
+// resolveOptions checks the chosen variant and modifiers against the product's options and
+// returns the variant (nil for products without variants), the modifiers to record on the
+// item and the unit price including modifier price deltas.
+func (s *TransactionService) resolveOptions(ctx context.Context, product *models.Product, itemReq dto.CreateTransactionItemDTO, itemID string) (*models.ProductVariant, []models.TransactionItemModifier, float64, error) {
+	unitPrice := product.Price
+
+	variants, err := s.optionRepo.ListVariants(ctx, []string{product.ID})
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	var variant *models.ProductVariant
+	if len(variants) > 0 {
+		if itemReq.VariantID == "" {
+			return nil, nil, 0, fmt.Errorf("product %s requires a variant", product.Name)
+		}
+		for _, v := range variants {
+			if v.ID == itemReq.VariantID {
+				variant = v
+				break
+			}
+		}
+		if variant == nil {
+			return nil, nil, 0, fmt.Errorf("variant %s not found for product %s", itemReq.VariantID, product.Name)
+		}
+		if !variant.IsActive {
+			return nil, nil, 0, fmt.Errorf("variant %s of product %s is not available", variant.Name, product.Name)
+		}
+		unitPrice = variant.Price
+	} else if itemReq.VariantID != "" {
+		return nil, nil, 0, fmt.Errorf("product %s has no variants", product.Name)
+	}
+
+	groups, err := s.optionRepo.ListModifierGroups(ctx, []string{product.ID})
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	type choice struct {
+		group    *models.ModifierGroup
+		modifier models.Modifier
+	}
+	available := make(map[string]choice)
+	for _, group := range groups {
+		for _, modifier := range group.Modifiers {
+			available[modifier.ID] = choice{group: group, modifier: modifier}
+		}
+	}
+
+	var modifiers []models.TransactionItemModifier
+	selected := make(map[string]int)
+	seen := make(map[string]bool)
+	for _, modifierID := range itemReq.ModifierIDs {
+		c, ok := available[modifierID]
+		if !ok {
+			return nil, nil, 0, fmt.Errorf("modifier %s not found for product %s", modifierID, product.Name)
+		}
+		if !c.modifier.IsActive {
+			return nil, nil, 0, fmt.Errorf("modifier %s is not available", c.modifier.Name)
+		}
+		if seen[modifierID] {
+			return nil, nil, 0, fmt.Errorf("modifier %s selected more than once", c.modifier.Name)
+		}
+		seen[modifierID] = true
+		selected[c.group.ID]++
+
+		unitPrice += c.modifier.PriceDelta
+		modifiers = append(modifiers, models.TransactionItemModifier{
+			ID:                uuid.New().String(),
+			TransactionItemID: itemID,
+			ModifierID:        modifierID,
+			GroupName:         c.group.Name,
+			Name:              c.modifier.Name,
+			PriceDelta:        c.modifier.PriceDelta,
+		})
+	}
+
+	for _, group := range groups {
+		count := selected[group.ID]
+		if count < group.MinSelect {
+			return nil, nil, 0, fmt.Errorf("%s for product %s requires at least %d selection(s)", group.Name, product.Name, group.MinSelect)
+		}
+		if group.MaxSelect > 0 && count > group.MaxSelect {
+			return nil, nil, 0, fmt.Errorf("%s for product %s allows at most %d selection(s)", group.Name, product.Name, group.MaxSelect)
+		}
+	}
+
+	if unitPrice < 0 {
+		unitPrice = 0
+	}
+
+	return variant, modifiers, unitPrice, nil
+}
+
+// prepareReversal sets the stock movements and loyalty entries that undo a sale on the
+// transaction. Items are put back on their variant or product, at the store the sale was
+// made at; products with a recipe get their ingredients back instead.
+func (s *TransactionService) prepareReversal(ctx context.Context, transaction *models.Transaction, userID string, now time.Time) error {
+	productIDs := make([]string, 0, len(transaction.Items))
+	for _, item := range transaction.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	recipes, err := s.inventoryService.recipesFor(ctx, productIDs)
+	if err != nil {
+		return err
+	}
+
+	productStock := make(map[string]int)
+	variantStock := make(map[string]int)
+	variantProducts := make(map[string]string)
+	for _, item := range transaction.Items {
+		if item.VariantID != nil {
+			variantProducts[*item.VariantID] = item.ProductID
+			variantStock[*item.VariantID] += item.Quantity
+		} else if len(recipes[item.ProductID]) == 0 {
+			productStock[item.ProductID] += item.Quantity
+		}
+	}
+
+	movements, err := s.inventoryService.returnMovements(ctx, transaction, userID, now)
+	if err != nil {
+		return err
+	}
+	movements = append(movements,
+		productMovements(productStock, transaction.StoreID, models.MovementReturn, transaction.ID, transaction.InvoiceNumber, userID, now)...)
+	movements = append(movements,
+		variantMovements(variantStock, variantProducts, transaction.StoreID, models.MovementReturn, transaction.ID, transaction.InvoiceNumber, userID, now)...)
+
+	entries, err := s.loyaltyService.reversalEntries(ctx, transaction)
+	if err != nil {
+		return err
+	}
+
+	transaction.StockMovements = movements
+	transaction.LoyaltyEntries = entries
+	return nil
+}
+
+// productMovements builds the movements that change product stock by signed quantities, keyed by
+// product ID. They are ordered by product so concurrent sales lock products in the same order.
+func productMovements(quantities map[string]int, storeID *string, movementType, transactionID, invoiceNumber, userID string, now time.Time) []*models.StockMovement {
+	productIDs := make([]string, 0, len(quantities))
+	for productID, quantity := range quantities {
+		if quantity != 0 {
+			productIDs = append(productIDs, productID)
+		}
+	}
+	sort.Strings(productIDs)
+
+	movements := make([]*models.StockMovement, 0, len(productIDs))
+	for _, productID := range productIDs {
+		movements = append(movements, &models.StockMovement{
+			ID:          uuid.New().String(),
+			ProductID:   productID,
+			StoreID:     storeID,
+			Type:        movementType,
+			Quantity:    float64(quantities[productID]),
+			ReferenceID: &transactionID,
+			Note:        invoiceNumber,
+			CreatedBy:   userID,
+			CreatedAt:   now,
+		})
+	}
+	return movements
+}
+
+// variantMovements builds the movements that change variant stock by signed quantities, keyed by
+// variant ID, with each variant's product ID in productIDs. They are ordered by variant so
+// concurrent sales lock variants in the same order.
+func variantMovements(quantities map[string]int, productIDs map[string]string, storeID *string, movementType, transactionID, invoiceNumber, userID string, now time.Time) []*models.StockMovement {
+	variantIDs := make([]string, 0, len(quantities))
+	for variantID, quantity := range quantities {
+		if quantity != 0 {
+			variantIDs = append(variantIDs, variantID)
+		}
+	}
+	sort.Strings(variantIDs)
+
+	movements := make([]*models.StockMovement, 0, len(variantIDs))
+	for _, variantID := range variantIDs {
+		movements = append(movements, &models.StockMovement{
+			ID:          uuid.New().String(),
+			ProductID:   productIDs[variantID],
+			VariantID:   &variantID,
+			StoreID:     storeID,
+			Type:        movementType,
+			Quantity:    float64(quantities[variantID]),
+			ReferenceID: &transactionID,
+			Note:        invoiceNumber,
+			CreatedBy:   userID,
+			CreatedAt:   now,
+		})
+	}
+	return movements
+}
+
 func (s *TransactionService) toResponse(transaction *models.Transaction) *dto.TransactionResponse {
 	resp := &dto.TransactionResponse{
 		ID:             transaction.ID,
@@ -209,6 +620,10 @@ func (s *TransactionService) toResponse(transaction *models.Transaction) *dto.Tr
 		PaymentMethod:  transaction.PaymentMethod,
 		Status:         transaction.Status,
 		Notes:          transaction.Notes,
+		VoucherCode:    transaction.VoucherCode,
+		PointsEarned:   transaction.PointsEarned,
+		PointsRedeemed: transaction.PointsRedeemed,
+		StoreID:        transaction.StoreID,
 		CreatedAt:      transaction.CreatedAt,
 		UpdatedAt:      transaction.UpdatedAt,
 	}
@@ -224,14 +639,25 @@ func (s *TransactionService) toResponse(transaction *models.Transaction) *dto.Tr
 	}
 
 	for _, item := range transaction.Items {
-		resp.Items = append(resp.Items, dto.TransactionItemResponse{
+		itemResp := dto.TransactionItemResponse{
 			ID:          item.ID,
 			ProductID:   item.ProductID,
 			ProductName: item.ProductName,
+			VariantID:   item.VariantID,
+			VariantName: item.VariantName,
 			UnitPrice:   item.UnitPrice,
 			Quantity:    item.Quantity,
 			Subtotal:    item.Subtotal,
-		})
+		}
+		for _, modifier := range item.Modifiers {
+			itemResp.Modifiers = append(itemResp.Modifiers, dto.TransactionItemModifierResponse{
+				ModifierID: modifier.ModifierID,
+				GroupName:  modifier.GroupName,
+				Name:       modifier.Name,
+				PriceDelta: modifier.PriceDelta,
+			})
+		}
+		resp.Items = append(resp.Items, itemResp)
 	}
 
 	return resp
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/repository"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// TrashService handles listing, restoring and purging soft-deleted records
+type TrashService struct {
+	trashRepo      repository.TrashRepository
+	licenseService *LicenseService
+	retentionDays  int
+}
+
+// NewTrashService creates a new trash service
+func NewTrashService(trashRepo repository.TrashRepository, licenseService *LicenseService, retentionDays int) *TrashService {
+	return &TrashService{
+		trashRepo:      trashRepo,
+		licenseService: licenseService,
+		retentionDays:  retentionDays,
+	}
+}
+
+// List lists soft-deleted records, newest first
+func (s *TrashService) List(ctx context.Context, filter dto.TrashListFilter, pagination utils.Pagination) ([]*dto.TrashItemResponse, int, error) {
+	items, total, err := s.trashRepo.List(ctx, filter.Type, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*dto.TrashItemResponse, 0, len(items))
+	for _, item := range items {
+		responses = append(responses, s.toResponse(item))
+	}
+
+	return responses, total, nil
+}
+
+// Restore brings a soft-deleted record back. Restoring fails if an active record has taken
+// its SKU, slug or email in the meantime, if a product's category is still deleted, or if a
+// restored user would exceed the license's seats.
+func (s *TrashService) Restore(ctx context.Context, entityType, id string) error {
+	if entityType == models.TrashUsers {
+		if err := s.licenseService.CheckSeat(ctx); err != nil {
+			return err
+		}
+	}
+	return s.trashRepo.Restore(ctx, entityType, id)
+}
+
+// Purge permanently removes the tenant's records deleted more than olderThanDays ago. The
+// configured retention period is used when olderThanDays is nil.
+func (s *TrashService) Purge(ctx context.Context, olderThanDays *int) (*dto.TrashPurgeResponse, error) {
+	days := s.retentionDays
+	if olderThanDays != nil {
+		days = *olderThanDays
+	}
+
+	before := time.Now().AddDate(0, 0, -days)
+	purged, err := s.trashRepo.Purge(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TrashPurgeResponse{DeletedBefore: before, Purged: purged}, nil
+}
+
+// StartPurgeJob starts a background goroutine that periodically purges records past the
+// retention period in every tenant. Nothing is purged when retention is disabled.
+func (s *TrashService) StartPurgeJob(interval time.Duration) {
+	if s.retentionDays <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			before := time.Now().AddDate(0, 0, -s.retentionDays)
+			purged, err := s.trashRepo.PurgeAllTenants(context.Background(), before)
+			if err != nil {
+				log.Printf("Trash purge failed: %v", err)
+				continue
+			}
+			for entityType, count := range purged {
+				if count > 0 {
+					log.Printf("Purged %d deleted %s", count, entityType)
+				}
+			}
+		}
+	}()
+}
+
+func (s *TrashService) toResponse(item *models.TrashItem) *dto.TrashItemResponse {
+	return &dto.TrashItemResponse{
+		ID:        item.ID,
+		Type:      item.Type,
+		Name:      item.Name,
+		DeletedAt: item.DeletedAt,
+	}
+}
@@ -15,17 +15,31 @@ import (
 
 // UserService handles user management operations
 type UserService struct {
-	userRepo repository.UserRepository
+	userRepo       repository.UserRepository
+	roleService    *RoleService
+	licenseService *LicenseService
 }
 
 // NewUserService creates a new user service
-func NewUserService(userRepo repository.UserRepository) *UserService {
-	return &UserService{userRepo: userRepo}
+func NewUserService(userRepo repository.UserRepository, roleService *RoleService, licenseService *LicenseService) *UserService {
+	return &UserService{userRepo: userRepo, roleService: roleService, licenseService: licenseService}
+}
+
+// checkRole fails unless role is a built-in role or one of the tenant's custom roles
+func (s *UserService) checkRole(ctx context.Context, role string) error {
+	exists, err := s.roleService.Exists(ctx, role)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("role not found")
+	}
+	return nil
 }
 
 // List returns paginated list of users
-func (s *UserService) List(ctx context.Context, role string, pagination utils.Pagination) ([]*dto.UserListResponse, int, error) {
-	users, total, err := s.userRepo.List(ctx, role, pagination)
+func (s *UserService) List(ctx context.Context, role, storeID string, pagination utils.Pagination) ([]*dto.UserListResponse, int, error) {
+	users, total, err := s.userRepo.List(ctx, role, storeID, pagination)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -39,6 +53,7 @@ func (s *UserService) List(ctx context.Context, role string, pagination utils.Pa
 			Phone:       u.Phone,
 			Role:        u.Role,
 			IsActive:    u.IsActive,
+			MFAEnabled:  u.TOTPEnabled,
 			LastLoginAt: u.UpdatedAt.Format(time.RFC3339),
 			CreatedAt:   u.CreatedAt.Format(time.RFC3339),
 		})
@@ -64,6 +79,7 @@ func (s *UserService) GetByID(ctx context.Context, id string) (*dto.UserListResp
 		Phone:       user.Phone,
 		Role:        user.Role,
 		IsActive:    user.IsActive,
+		MFAEnabled:  user.TOTPEnabled,
 		LastLoginAt: user.UpdatedAt.Format(time.RFC3339),
 		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
 	}, nil
@@ -77,6 +93,15 @@ func (s *UserService) Create(ctx context.Context, req *dto.CreateUserRequest) (*
 		return nil, errors.New("email already registered")
 	}
 
+	if err := s.checkRole(ctx, req.Role); err != nil {
+		return nil, err
+	}
+
+	// Every active user takes a license seat
+	if err := s.licenseService.CheckSeat(ctx); err != nil {
+		return nil, err
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -125,9 +150,17 @@ func (s *UserService) Update(ctx context.Context, id string, req *dto.UpdateUser
 		user.Phone = req.Phone
 	}
 	if req.Role != "" {
+		if err := s.checkRole(ctx, req.Role); err != nil {
+			return nil, err
+		}
 		user.Role = req.Role
 	}
 	if req.IsActive != nil {
+		if *req.IsActive && !user.IsActive {
+			if err := s.licenseService.CheckSeat(ctx); err != nil {
+				return nil, err
+			}
+		}
 		user.IsActive = *req.IsActive
 	}
 	user.UpdatedAt = time.Now()
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilramdhan/pos-api/internal/dto"
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/repository"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+const (
+	// voucherCodeLength is the number of random characters in a generated code
+	voucherCodeLength = 8
+	// voucherCodeAlphabet omits characters that are easily confused (0/O, 1/I)
+	voucherCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+// VoucherService handles voucher operations
+type VoucherService struct {
+	voucherRepo repository.VoucherRepository
+}
+
+// NewVoucherService creates a new voucher service
+func NewVoucherService(voucherRepo repository.VoucherRepository) *VoucherService {
+	return &VoucherService{
+		voucherRepo: voucherRepo,
+	}
+}
+
+// GenerateBatch creates a batch of unique voucher codes sharing the same rules
+func (s *VoucherService) GenerateBatch(ctx context.Context, userID string, req *dto.GenerateVouchersRequest) (*dto.VoucherBatchResponse, error) {
+	if req.DiscountType == models.DiscountTypePercentage && req.DiscountValue > 100 {
+		return nil, errors.New("percentage discount cannot exceed 100")
+	}
+	if req.ValidFrom != nil && req.ExpiresAt != nil && !req.ExpiresAt.After(*req.ValidFrom) {
+		return nil, errors.New("expires_at must be after valid_from")
+	}
+
+	now := time.Now()
+	batchID := uuid.New().String()
+	prefix := strings.ToUpper(req.Prefix)
+
+	seen := make(map[string]bool, req.Quantity)
+	vouchers := make([]*models.Voucher, 0, req.Quantity)
+	for len(vouchers) < req.Quantity {
+		code, err := generateVoucherCode(prefix)
+		if err != nil {
+			return nil, err
+		}
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		vouchers = append(vouchers, &models.Voucher{
+			ID:              uuid.New().String(),
+			Code:            code,
+			BatchID:         batchID,
+			DiscountType:    req.DiscountType,
+			DiscountValue:   req.DiscountValue,
+			MaxDiscount:     req.MaxDiscount,
+			MinSpend:        req.MinSpend,
+			MaxUses:         req.MaxUses,
+			UsesPerCustomer: req.UsesPerCustomer,
+			ValidFrom:       req.ValidFrom,
+			ExpiresAt:       req.ExpiresAt,
+			IsActive:        true,
+			CreatedBy:       userID,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		})
+	}
+
+	if err := s.voucherRepo.CreateBatch(ctx, vouchers); err != nil {
+		return nil, err
+	}
+
+	resp := &dto.VoucherBatchResponse{
+		BatchID:  batchID,
+		Quantity: len(vouchers),
+	}
+	for _, voucher := range vouchers {
+		resp.Vouchers = append(resp.Vouchers, s.toResponse(voucher))
+	}
+
+	return resp, nil
+}
+
+// GetByID retrieves a voucher by ID
+func (s *VoucherService) GetByID(ctx context.Context, id string) (*dto.VoucherResponse, error) {
+	voucher, err := s.voucherRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if voucher == nil {
+		return nil, errors.New("voucher not found")
+	}
+
+	return s.toResponse(voucher), nil
+}
+
+// Update updates a voucher's status or expiry
+func (s *VoucherService) Update(ctx context.Context, id string, req *dto.UpdateVoucherRequest) (*dto.VoucherResponse, error) {
+	voucher, err := s.voucherRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if voucher == nil {
+		return nil, errors.New("voucher not found")
+	}
+
+	if req.IsActive != nil {
+		voucher.IsActive = *req.IsActive
+	}
+	if req.ExpiresAt != nil {
+		voucher.ExpiresAt = req.ExpiresAt
+	}
+	voucher.UpdatedAt = time.Now()
+
+	if err := s.voucherRepo.Update(ctx, voucher); err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(voucher), nil
+}
+
+// Validate checks whether a voucher can be applied and returns the discount it would give
+func (s *VoucherService) Validate(ctx context.Context, req *dto.ValidateVoucherRequest) (*dto.VoucherValidationResponse, error) {
+	voucher, discount, err := resolveVoucher(ctx, s.voucherRepo, req.Code, req.Subtotal, req.CustomerID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.VoucherValidationResponse{
+		Code:           voucher.Code,
+		DiscountAmount: discount,
+	}, nil
+}
+
+// ListRedemptions lists all redemptions of a voucher
+func (s *VoucherService) ListRedemptions(ctx context.Context, id string) ([]*dto.VoucherRedemptionResponse, error) {
+	voucher, err := s.voucherRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if voucher == nil {
+		return nil, errors.New("voucher not found")
+	}
+
+	redemptions, err := s.voucherRepo.ListRedemptions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.VoucherRedemptionResponse, 0, len(redemptions))
+	for _, r := range redemptions {
+		responses = append(responses, &dto.VoucherRedemptionResponse{
+			ID:             r.ID,
+			VoucherID:      r.VoucherID,
+			TransactionID:  r.TransactionID,
+			InvoiceNumber:  r.InvoiceNumber,
+			CustomerID:     r.CustomerID,
+			UserID:         r.UserID,
+			DiscountAmount: r.DiscountAmount,
+			CreatedAt:      r.CreatedAt,
+		})
+	}
+
+	return responses, nil
+}
+
+// List lists vouchers with pagination and filters
+func (s *VoucherService) List(ctx context.Context, filter dto.VoucherListFilter, pagination utils.Pagination) ([]*dto.VoucherResponse, int, error) {
+	vouchers, total, err := s.voucherRepo.List(ctx, filter, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var responses []*dto.VoucherResponse
+	for _, voucher := range vouchers {
+		responses = append(responses, s.toResponse(voucher))
+	}
+
+	return responses, total, nil
+}
+
+func (s *VoucherService) toResponse(voucher *models.Voucher) *dto.VoucherResponse {
+	return &dto.VoucherResponse{
+		ID:              voucher.ID,
+		Code:            voucher.Code,
+		BatchID:         voucher.BatchID,
+		DiscountType:    voucher.DiscountType,
+		DiscountValue:   voucher.DiscountValue,
+		MaxDiscount:     voucher.MaxDiscount,
+		MinSpend:        voucher.MinSpend,
+		MaxUses:         voucher.MaxUses,
+		UsesPerCustomer: voucher.UsesPerCustomer,
+		UsedCount:       voucher.UsedCount,
+		ValidFrom:       voucher.ValidFrom,
+		ExpiresAt:       voucher.ExpiresAt,
+		IsActive:        voucher.IsActive,
+		CreatedAt:       voucher.CreatedAt,
+		UpdatedAt:       voucher.UpdatedAt,
+	}
+}
+
+// NormalizeVoucherCode trims and upper-cases a voucher code as entered by a cashier
+func NormalizeVoucherCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// resolveVoucher looks up a voucher by code and checks expiry, minimum spend and usage caps.
+// The usage caps are checked again under a row lock when the redemption is written.
+func resolveVoucher(ctx context.Context, voucherRepo repository.VoucherRepository, code string, subtotal float64, customerID *string, now time.Time) (*models.Voucher, float64, error) {
+	voucher, err := voucherRepo.GetByCode(ctx, NormalizeVoucherCode(code))
+	if err != nil {
+		return nil, 0, err
+	}
+	if voucher == nil || !voucher.IsActive {
+		return nil, 0, errors.New("invalid voucher code")
+	}
+	if !voucher.IsStarted(now) {
+		return nil, 0, errors.New("voucher is not valid yet")
+	}
+	if voucher.IsExpired(now) {
+		return nil, 0, errors.New("voucher has expired")
+	}
+	if voucher.IsExhausted() {
+		return nil, 0, errors.New("voucher usage limit reached")
+	}
+	if subtotal < voucher.MinSpend {
+		return nil, 0, fmt.Errorf("minimum spend of %.2f required for this voucher", voucher.MinSpend)
+	}
+
+	if voucher.UsesPerCustomer > 0 {
+		if customerID == nil || *customerID == "" {
+			return nil, 0, errors.New("voucher requires a customer")
+		}
+		used, err := voucherRepo.CountCustomerRedemptions(ctx, voucher.ID, *customerID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if used >= voucher.UsesPerCustomer {
+			return nil, 0, repository.ErrVoucherCustomerLimit
+		}
+	}
+
+	return voucher, voucher.CalculateDiscount(subtotal), nil
+}
+
+func generateVoucherCode(prefix string) (string, error) {
+	max := big.NewInt(int64(len(voucherCodeAlphabet)))
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	for i := 0; i < voucherCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(voucherCodeAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ilramdhan/pos-api/internal/config"
+)
+
+// ErrNotFound is returned when no object exists under the requested key
+var ErrNotFound = errors.New("object not found")
+
+// Object is a stored blob together with its metadata
+type Object struct {
+	Key          string
+	Data         []byte
+	ContentType  string
+	LastModified time.Time
+}
+
+// BlobStore stores binary objects such as product images under slash-separated keys
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (*Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New creates the blob store selected by STORAGE_DRIVER
+func New(cfg *config.StorageConfig) (BlobStore, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStore(cfg.LocalDir)
+	case "s3":
+		return NewS3Store(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q (expected local or s3)", cfg.Driver)
+	}
+}
+
+// CleanKey validates an object key and returns it in canonical form. Keys are relative,
+// slash-separated paths and may not escape the store root.
+func CleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	cleaned := path.Clean(key)
+	if cleaned != key || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+
+// barcodeQuietZone is the blank margin, in modules, printed on each side of the bars
+const barcodeQuietZone = 10
+
+// IsDigits reports whether s is non-empty and made only of ASCII digits
+func IsDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// GTINCheckDigit computes the GS1 mod-10 check digit for the given digits (without the check digit)
+func GTINCheckDigit(digits string) int {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		// Weights alternate 3, 1, 3, ... starting from the digit nearest the check digit
+		if (len(digits)-1-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return (10 - sum%10) % 10
+}
+
+// ValidGTIN reports whether code is all digits and ends with a correct GS1 check digit
+func ValidGTIN(code string) bool {
+	if !IsDigits(code) || len(code) < 2 {
+		return false
+	}
+	return GTINCheckDigit(code[:len(code)-1]) == int(code[len(code)-1]-'0')
+}
+
+var (
+	ean13LeftOdd = [10]string{
+		"0001101", "0011001", "0010011", "0111101", "0100011",
+		"0110001", "0101111", "0111011", "0110111", "0001011",
+	}
+	ean13LeftEven = [10]string{
+		"0100111", "0110011", "0011011", "0100001", "0011101",
+		"0111001", "0000101", "0010001", "0001001", "0010111",
+	}
+	ean13Right = [10]string{
+		"1110010", "1100110", "1101100", "1000010", "1011100",
+		"1001110", "1010000", "1000100", "1001000", "1110100",
+	}
+	// ean13Parity selects odd (L) or even (G) encoding for the left half from the first digit
+	ean13Parity = [10]string{
+		"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG",
+		"LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL",
+	}
+)
+
+// EncodeEAN13 returns the bar modules (true = bar) of a 13-digit EAN-13 code. A 12-digit
+// UPC-A code is encoded as EAN-13 with a leading zero, which prints identical bars.
+func EncodeEAN13(code string) ([]bool, error) {
+	if len(code) == 12 {
+		code = "0" + code
+	}
+	if len(code) != 13 || !ValidGTIN(code) {
+		return nil, errors.New("invalid EAN-13 code")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("101")
+	parity := ean13Parity[code[0]-'0']
+	for i := 1; i <= 6; i++ {
+		d := code[i] - '0'
+		if parity[i-1] == 'L' {
+			sb.WriteString(ean13LeftOdd[d])
+		} else {
+			sb.WriteString(ean13LeftEven[d])
+		}
+	}
+	sb.WriteString("01010")
+	for i := 7; i <= 12; i++ {
+		sb.WriteString(ean13Right[code[i]-'0'])
+	}
+	sb.WriteString("101")
+
+	return modulesFromPattern(sb.String()), nil
+}
+
+// code128Widths holds the bar/space widths of each Code 128 symbol value; 106 is the stop pattern
+var code128Widths = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+// code128StartB is the symbol value that starts a Code 128 set B barcode
+const code128StartB = 104
+
+// EncodeCode128 returns the bar modules of text encoded as Code 128 (set B), which covers
+// printable ASCII and is used for internal product codes
+func EncodeCode128(text string) ([]bool, error) {
+	if text == "" {
+		return nil, errors.New("barcode text is empty")
+	}
+
+	values := []int{code128StartB}
+	checksum := code128StartB
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c < 32 || c > 126 {
+			return nil, fmt.Errorf("character %q cannot be encoded", c)
+		}
+		value := int(c) - 32
+		values = append(values, value)
+		checksum += value * (i + 1)
+	}
+	values = append(values, checksum%103, 106)
+
+	var modules []bool
+	for _, value := range values {
+		bar := true
+		for _, w := range code128Widths[value] {
+			for n := 0; n < int(w-'0'); n++ {
+				modules = append(modules, bar)
+			}
+			bar = !bar
+		}
+	}
+	return modules, nil
+}
+
+// BarcodeSVG renders bar modules as an SVG image with the human-readable text underneath
+func BarcodeSVG(modules []bool, text string, moduleWidth, barHeight int) []byte {
+	textHeight := 0
+	if text != "" {
+		textHeight = 14 + moduleWidth*2
+	}
+	width := (len(modules) + 2*barcodeQuietZone) * moduleWidth
+	height := barHeight + textHeight
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/>`, width, height)
+	for i := 0; i < len(modules); {
+		if !modules[i] {
+			i++
+			continue
+		}
+		start := i
+		for i < len(modules) && modules[i] {
+			i++
+		}
+		fmt.Fprintf(&buf, `<rect x="%d" y="0" width="%d" height="%d" fill="#000"/>`,
+			(start+barcodeQuietZone)*moduleWidth, (i-start)*moduleWidth, barHeight)
+	}
+	if text != "" {
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" font-family="monospace" font-size="%d" text-anchor="middle">%s</text>`,
+			width/2, height-2, 12+moduleWidth, html.EscapeString(text))
+	}
+	buf.WriteString(`</svg>`)
+	return buf.Bytes()
+}
+
+// BarcodePNG renders bar modules as a black and white PNG image
+func BarcodePNG(modules []bool, moduleWidth, barHeight int) ([]byte, error) {
+	width := (len(modules) + 2*barcodeQuietZone) * moduleWidth
+	img := image.NewGray(image.Rect(0, 0, width, barHeight))
+	for x := 0; x < width; x++ {
+		module := x/moduleWidth - barcodeQuietZone
+		c := color.Gray{Y: 0xff}
+		if module >= 0 && module < len(modules) && modules[module] {
+			c = color.Gray{Y: 0}
+		}
+		for y := 0; y < barHeight; y++ {
+			img.SetGray(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func modulesFromPattern(pattern string) []bool {
+	modules := make([]bool, len(pattern))
+	for i, c := range pattern {
+		modules[i] = c == '1'
+	}
+	return modules
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"strings"
+)
+
+// NormalizePhone reduces a phone number to digits so formatting differences compare equal.
+// Indonesian country-code prefixes (+62 / 62) are rewritten to the local leading 0.
+func NormalizePhone(phone string) string {
+	var sb strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+		}
+	}
+
+	digits := sb.String()
+	if strings.HasPrefix(digits, "62") && len(digits) > 2 {
+		digits = "0" + digits[2:]
+	}
+	return digits
+}
+
+// NormalizeEmail lower-cases and trims an email address
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -56,6 +56,30 @@ func SetAuthCookies(c *gin.Context, accessToken, refreshToken string, accessExpi
 	)
 }
 
+// SetAccessCookie sets the access token cookie of a session that has no refresh token, and
+// clears any refresh token left by the previous session on the device
+func SetAccessCookie(c *gin.Context, accessToken string, accessExpiry time.Duration, cfg CookieConfig) {
+	c.SetSameSite(cfg.SameSite)
+	c.SetCookie(
+		AccessTokenCookieName,
+		accessToken,
+		int(accessExpiry.Seconds()),
+		"/",
+		cfg.Domain,
+		cfg.Secure,
+		true, // HttpOnly
+	)
+	c.SetCookie(
+		RefreshTokenCookieName,
+		"",
+		-1,
+		"/api/v1/auth",
+		cfg.Domain,
+		cfg.Secure,
+		true,
+	)
+}
+
 // ClearAuthCookies clears authentication cookies (for logout)
 func ClearAuthCookies(c *gin.Context, cfg CookieConfig) {
 	c.SetSameSite(cfg.SameSite)
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned for a cursor that cannot be decoded or cannot be used
+// with the requested sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a keyset position in a newest-first listing: the (created_at, id) of the last
+// row already seen. The next page continues strictly after it.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// EncodeCursor returns the opaque cursor for the row (createdAt, id)
+func EncodeCursor(createdAt time.Time, id string) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{CreatedAt: t, ID: id}, nil
+}
+
+// IsNewestFirst reports whether the requested order is the default created_at descending,
+// the only order keyset cursors follow
+func (p *Pagination) IsNewestFirst() bool {
+	keys := p.SortKeys()
+	return len(keys) == 1 && keys[0].Field == "created_at" && keys[0].Desc
+}
+
+// After returns the decoded cursor of a cursor request, or nil for offset pagination.
+// Cursors are only accepted with the newest-first order.
+func (p *Pagination) After() (*Cursor, error) {
+	if p.Cursor == "" {
+		return nil, nil
+	}
+	if !p.IsNewestFirst() {
+		return nil, fmt.Errorf("%w: cursors can only be used with the default newest-first sort", ErrInvalidCursor)
+	}
+	return DecodeCursor(p.Cursor)
+}
+
+// EstimateTotal reports whether a list may return the planner's row estimate instead of
+// an exact count: cursor requests skip the count unless include_total=true is given
+func (p *Pagination) EstimateTotal() bool {
+	return p.Cursor != "" && !p.IncludeTotal
+}
@@ -14,6 +14,14 @@ type JWTClaims struct {
 	Email  string `json:"email"`
 	Name   string `json:"name"`
 	Role   string `json:"role"`
+	// StoreID is the store the user signed in to; empty when not working at a store
+	StoreID string `json:"store_id,omitempty"`
+	// TenantID is the business the user belongs to
+	TenantID string `json:"tenant_id,omitempty"`
+	// TerminalID is the POS terminal a PIN session was opened on; empty for password sign-ins
+	TerminalID string `json:"terminal_id,omitempty"`
+	// Purpose restricts a token to one step of signing in; empty for session tokens
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -33,13 +41,15 @@ func NewJWTManager(secret string, expiry, refreshExpiry time.Duration) *JWTManag
 	}
 }
 
-// GenerateToken generates a new JWT token for a user
-func (j *JWTManager) GenerateToken(user *models.User) (string, error) {
+// GenerateToken generates a new JWT token for a user signed in to a store
+func (j *JWTManager) GenerateToken(user *models.User, storeID string) (string, error) {
 	claims := &JWTClaims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Name:   user.Name,
-		Role:   user.Role,
+		UserID:   user.ID,
+		Email:    user.Email,
+		Name:     user.Name,
+		Role:     user.Role,
+		StoreID:  storeID,
+		TenantID: user.TenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -51,13 +61,62 @@ func (j *JWTManager) GenerateToken(user *models.User) (string, error) {
 	return token.SignedString(j.secretKey)
 }
 
+// GenerateTerminalToken generates a short-lived token for a PIN session on a terminal. PIN
+// sessions get no refresh token; the user signs in with their PIN again once it expires.
+func (j *JWTManager) GenerateTerminalToken(user *models.User, storeID, terminalID string, expiry time.Duration) (string, error) {
+	claims := &JWTClaims{
+		UserID:     user.ID,
+		Email:      user.Email,
+		Name:       user.Name,
+		Role:       user.Role,
+		StoreID:    storeID,
+		TenantID:   user.TenantID,
+		TerminalID: terminalID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(j.secretKey)
+}
+
+// PurposeMFA marks challenge tokens, which only prove that a user's password was checked
+const PurposeMFA = "mfa"
+
+// GenerateMFAToken generates a short-lived challenge token for a user whose password was
+// checked but who still has to pass two-factor authentication. It cannot be used as a session.
+func (j *JWTManager) GenerateMFAToken(user *models.User, storeID string, expiry time.Duration) (string, error) {
+	claims := &JWTClaims{
+		UserID:   user.ID,
+		Email:    user.Email,
+		Name:     user.Name,
+		Role:     user.Role,
+		StoreID:  storeID,
+		TenantID: user.TenantID,
+		Purpose:  PurposeMFA,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(j.secretKey)
+}
+
 // GenerateRefreshToken generates a new refresh token for a user
-func (j *JWTManager) GenerateRefreshToken(user *models.User) (string, error) {
+func (j *JWTManager) GenerateRefreshToken(user *models.User, storeID string) (string, error) {
 	claims := &JWTClaims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Name:   user.Name,
-		Role:   user.Role,
+		UserID:   user.ID,
+		Email:    user.Email,
+		Name:     user.Name,
+		Role:     user.Role,
+		StoreID:  storeID,
+		TenantID: user.TenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.refreshExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -69,8 +128,32 @@ func (j *JWTManager) GenerateRefreshToken(user *models.User) (string, error) {
 	return token.SignedString(j.secretKey)
 }
 
-// ValidateToken validates a JWT token and returns the claims
+// ValidateToken validates a session token and returns the claims
 func (j *JWTManager) ValidateToken(tokenString string) (*JWTClaims, error) {
+	claims, err := j.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// ValidateMFAToken validates a challenge token and returns the claims
+func (j *JWTManager) ValidateMFAToken(tokenString string) (*JWTClaims, error) {
+	claims, err := j.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != PurposeMFA {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// parse checks a token's signature and expiry and returns its claims
+func (j *JWTManager) parse(tokenString string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
@@ -99,13 +182,13 @@ type TokenPair struct {
 }
 
 // GenerateTokenPair generates both access and refresh tokens
-func (j *JWTManager) GenerateTokenPair(user *models.User) (*TokenPair, error) {
-	accessToken, err := j.GenerateToken(user)
+func (j *JWTManager) GenerateTokenPair(user *models.User, storeID string) (*TokenPair, error) {
+	accessToken, err := j.GenerateToken(user, storeID)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := j.GenerateRefreshToken(user)
+	refreshToken, err := j.GenerateRefreshToken(user, storeID)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrInvalidLicense is returned for license keys that are malformed or not signed by the issuer
+var ErrInvalidLicense = errors.New("invalid license key")
+
+// License holds the terms encoded in a signed license key
+type License struct {
+	Licensee  string    `json:"licensee"`
+	Plan      string    `json:"plan"`
+	Seats     int       `json:"seats"`  // Active users allowed (0 = unlimited)
+	Stores    int       `json:"stores"` // Active stores allowed (0 = unlimited)
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"` // Zero for a perpetual license
+}
+
+// Expired reports whether the license has expired at now
+func (l *License) Expired(now time.Time) bool {
+	return !l.ExpiresAt.IsZero() && now.After(l.ExpiresAt)
+}
+
+// SignLicense encodes a license as a key signed with the issuer's private key. The key is the
+// base64url-encoded JSON terms and their signature, joined by a dot.
+func SignLicense(privateKey ed25519.PrivateKey, license *License) (string, error) {
+	payload, err := json.Marshal(license)
+	if err != nil {
+		return "", err
+	}
+	signature := ed25519.Sign(privateKey, payload)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// ParseLicense verifies a license key against the issuer's public key and decodes its terms.
+// Expiry is not checked, so that expired licenses can still be reported.
+func ParseLicense(key string, publicKey ed25519.PublicKey) (*License, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, ErrInvalidLicense
+	}
+
+	encodedPayload, encodedSignature, ok := strings.Cut(strings.TrimSpace(key), ".")
+	if !ok {
+		return nil, ErrInvalidLicense
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidLicense
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !ed25519.Verify(publicKey, payload, signature) {
+		return nil, ErrInvalidLicense
+	}
+
+	var license License
+	if err := json.Unmarshal(payload, &license); err != nil {
+		return nil, ErrInvalidLicense
+	}
+	return &license, nil
+}
+
+// ParseLicensePublicKey decodes a base64-encoded Ed25519 public key
+func ParseLicensePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("license public key must be a base64-encoded Ed25519 public key")
+	}
+	return ed25519.PublicKey(key), nil
+}
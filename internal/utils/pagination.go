@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,6 +15,10 @@ type Pagination struct {
 	PerPage int    `json:"per_page"`
 	Sort    string `json:"sort"`
 	Order   string `json:"order"`
+	// Cursor continues a newest-first list after a previous page's next_cursor
+	Cursor string `json:"cursor,omitempty"`
+	// IncludeTotal asks cursor requests for an exact total instead of an estimate
+	IncludeTotal bool `json:"include_total,omitempty"`
 }
 
 // DefaultPagination contains default pagination values
@@ -46,9 +53,11 @@ func GetPagination(c *gin.Context) Pagination {
 		}
 	}
 
-	// Parse sort
+	// Parse sort, e.g. sort=-price,name; keys are checked against each list's fields.
+	// An explicit sort is ascending unless prefixed with "-" or combined with order=desc.
 	if sort := c.Query("sort"); sort != "" {
 		p.Sort = sort
+		p.Order = ""
 	}
 
 	// Parse order
@@ -58,6 +67,13 @@ func GetPagination(c *gin.Context) Pagination {
 		}
 	}
 
+	// Parse keyset cursor; cursor requests always start from the cursor, not a page offset
+	if cursor := c.Query("cursor"); cursor != "" {
+		p.Cursor = cursor
+		p.Page = 1
+	}
+	p.IncludeTotal = c.Query("include_total") == "true"
+
 	return p
 }
 
@@ -71,15 +87,92 @@ func (p *Pagination) Limit() int {
 	return p.PerPage
 }
 
-// OrderBy returns the ORDER BY clause for SQL queries
-func (p *Pagination) OrderBy() string {
-	sort := p.Sort
-	order := p.Order
-	if sort == "" {
-		sort = "created_at"
+// SortFields maps the sort keys a list accepts to the SQL expressions they order by
+type SortFields map[string]string
+
+// With returns a copy of the fields with one more key, for sort keys that only exist
+// for some queries (such as search relevance)
+func (f SortFields) With(key, expr string) SortFields {
+	fields := make(SortFields, len(f)+1)
+	for k, v := range f {
+		fields[k] = v
+	}
+	fields[key] = expr
+	return fields
+}
+
+// SortKey is one key of a multi-key sort
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// InvalidSortError is returned when a sort key is not accepted by the list
+type InvalidSortError struct {
+	Field   string
+	Allowed []string
+}
+
+func (e *InvalidSortError) Error() string {
+	return fmt.Sprintf("cannot sort by %q; allowed fields: %s", e.Field, strings.Join(e.Allowed, ", "))
+}
+
+// SortKeys parses Sort, a comma-separated list such as "-price,name". A "-" prefix sorts
+// descending and a "+" prefix ascending; keys without a prefix follow Order (ascending
+// unless it is "desc"). An empty Sort means newest first.
+func (p *Pagination) SortKeys() []SortKey {
+	if strings.TrimSpace(p.Sort) == "" {
+		return []SortKey{{Field: "created_at", Desc: true}}
+	}
+
+	var keys []SortKey
+	for _, part := range strings.Split(p.Sort, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key := SortKey{Desc: p.Order == "desc"}
+		switch part[0] {
+		case '-':
+			key.Desc = true
+			part = part[1:]
+		case '+':
+			key.Desc = false
+			part = part[1:]
+		}
+		key.Field = part
+		keys = append(keys, key)
 	}
-	if order == "" {
-		order = "desc"
+	return keys
+}
+
+// OrderClause builds an ORDER BY list for the requested sort from the declared fields only,
+// so request input never reaches the SQL. The tiebreaker (normally the primary key) is
+// always appended, in the direction of the last key, to keep the order stable across pages
+// and consistent with keyset cursors. Unknown keys return an *InvalidSortError.
+func (p *Pagination) OrderClause(fields SortFields, tiebreaker string) (string, error) {
+	terms := make([]string, 0, len(fields)+1)
+	tiebreakDirection := "ASC"
+	for _, key := range p.SortKeys() {
+		expr, ok := fields[key.Field]
+		if !ok {
+			// The implicit newest-first default is skipped by lists without created_at
+			if p.Sort == "" {
+				continue
+			}
+			allowed := make([]string, 0, len(fields))
+			for name := range fields {
+				allowed = append(allowed, name)
+			}
+			sort.Strings(allowed)
+			return "", &InvalidSortError{Field: key.Field, Allowed: allowed}
+		}
+		direction := "ASC"
+		if key.Desc {
+			direction = "DESC"
+		}
+		terms = append(terms, expr+" "+direction+" NULLS LAST")
+		tiebreakDirection = direction
 	}
-	return sort + " " + order
+	return strings.Join(append(terms, tiebreaker+" "+tiebreakDirection), ", "), nil
 }
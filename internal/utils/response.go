@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,10 +19,14 @@ type Response struct {
 
 // Meta contains pagination information
 type Meta struct {
-	Page       int `json:"page"`
+	Page       int `json:"page,omitempty"`
 	PerPage    int `json:"per_page"`
 	Total      int `json:"total"`
 	TotalPages int `json:"total_pages"`
+	// TotalEstimated marks Total as the query planner's estimate rather than an exact count
+	TotalEstimated bool `json:"total_estimated,omitempty"`
+	// NextCursor continues a newest-first list after this page
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // FieldError represents a validation error for a specific field
@@ -94,11 +100,43 @@ func NotFound(c *gin.Context, message string) {
 	ErrorResponse(c, http.StatusNotFound, message)
 }
 
+// Conflict sends a 409 conflict response with the conflicting resources
+func Conflict(c *gin.Context, message string, data interface{}) {
+	c.JSON(http.StatusConflict, Response{
+		Success: false,
+		Message: message,
+		Data:    data,
+	})
+}
+
+// UnprocessableEntity sends a 422 response with details of what could not be processed
+func UnprocessableEntity(c *gin.Context, message string, data interface{}) {
+	c.JSON(http.StatusUnprocessableEntity, Response{
+		Success: false,
+		Message: message,
+		Data:    data,
+	})
+}
+
 // InternalServerError sends a 500 internal server error response
 func InternalServerError(c *gin.Context, message string) {
 	ErrorResponse(c, http.StatusInternalServerError, message)
 }
 
+// ListError sends a 400 validation error for an invalid sort and a 500 for anything else
+func ListError(c *gin.Context, err error) {
+	var sortErr *InvalidSortError
+	if errors.As(err, &sortErr) {
+		ValidationErrorResponse(c, []FieldError{{Field: "sort", Message: sortErr.Error()}})
+		return
+	}
+	if errors.Is(err, ErrInvalidCursor) {
+		ValidationErrorResponse(c, []FieldError{{Field: "cursor", Message: err.Error()}})
+		return
+	}
+	InternalServerError(c, err.Error())
+}
+
 // TooManyRequests sends a 429 too many requests response
 func TooManyRequests(c *gin.Context) {
 	ErrorResponse(c, http.StatusTooManyRequests, "Too many requests, please try again later")
@@ -117,3 +155,17 @@ func NewMeta(page, perPage, total int) *Meta {
 		TotalPages: totalPages,
 	}
 }
+
+// WithCursor adds keyset details to the meta of a newest-first page with count rows, the
+// last of which is (createdAt, id). next_cursor is set whenever the page is full; cursor
+// requests have no page number, and their total may be an estimate.
+func (m *Meta) WithCursor(p Pagination, count int, createdAt time.Time, id string) *Meta {
+	if p.Cursor != "" {
+		m.Page = 0
+		m.TotalEstimated = p.EstimateTotal()
+	}
+	if count > 0 && count == p.PerPage && p.IsNewestFirst() {
+		m.NextCursor = EncodeCursor(createdAt, id)
+	}
+	return m
+}
@@ -0,0 +1,29 @@
+package utils
+
+import "context"
+
+// DefaultTenantID is the tenant that owns data created before multi-tenancy and accounts
+// registered without a business name
+const DefaultTenantID = "default"
+
+type tenantContextKey struct{}
+
+// WithTenant returns a copy of ctx scoped to a tenant
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant ctx is scoped to, or an empty string when it is not
+// scoped to one
+func TenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenantID
+}
+
+// TenantID returns the tenant ctx is scoped to, falling back to the default tenant
+func TenantID(ctx context.Context) string {
+	if tenantID := TenantFromContext(ctx); tenantID != "" {
+		return tenantID
+	}
+	return DefaultTenantID
+}
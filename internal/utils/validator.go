@@ -57,6 +57,11 @@ func getErrorMessage(err validator.FieldError) string {
 			return "Must be at most " + err.Param() + " characters"
 		}
 		return "Must be at most " + err.Param()
+	case "len":
+		if err.Type().Kind() == reflect.String {
+			return "Must be exactly " + err.Param() + " characters"
+		}
+		return "Must have exactly " + err.Param() + " items"
 	case "gte":
 		return "Must be greater than or equal to " + err.Param()
 	case "gt":
@@ -158,6 +158,31 @@ func TestAuthRegister_Success(t *testing.T) {
 	}
 }
 
+func TestAuthRegister_JoinsDefaultTenantAsCashier(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	body := map[string]string{
+		"email":    "newadmin@test.local",
+		"password": "NewUser123!",
+		"name":     "New User",
+		"role":     "admin",
+	}
+
+	// Signing up without a business cannot pick a role
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/auth/register", body, nil)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	delete(body, "role")
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/auth/register", body, nil)
+	AssertStatus(t, w, http.StatusCreated)
+
+	user := ParseResponse(t, w)["data"].(map[string]interface{})["user"].(map[string]interface{})
+	if user["role"] != "cashier" {
+		t.Errorf("Expected role 'cashier', got '%v'", user["role"])
+	}
+}
+
 func TestAuthRegister_DuplicateEmail(t *testing.T) {
 	env := SetupTestEnv(t)
 	defer env.Cleanup()
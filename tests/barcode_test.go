@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// ============================================
+// Barcode Tests
+// ============================================
+
+// seedBarcode assigns a barcode to a product and returns its ID
+func seedBarcode(t *testing.T, env *TestEnv, productID, code, barcodeType string) string {
+	t.Helper()
+
+	body := map[string]interface{}{"code": code, "type": barcodeType}
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/products/"+productID+"/barcodes", body, env.LoginAsAdmin(t))
+	AssertStatus(t, w, http.StatusCreated)
+
+	return ParseResponse(t, w)["data"].(map[string]interface{})["id"].(string)
+}
+
+func TestBarcodeLookup_EAN13(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	seedBarcode(t, env, productID, "4006381333931", "ean13")
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/pos/products/barcode/4006381333931", nil, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusOK)
+
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	if data["product"].(map[string]interface{})["id"] != productID {
+		t.Errorf("Expected product %s, got %v", productID, data["product"])
+	}
+}
+
+func TestBarcodeCreate_InvalidCheckDigit(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	body := map[string]interface{}{"code": "4006381333932", "type": "ean13"}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/products/"+productID+"/barcodes", body, env.LoginAsAdmin(t))
+
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestBarcodeLookup_UPCAScannedAsEAN13(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	seedBarcode(t, env, productID, "036000291452", "upca")
+
+	// Many scanners report UPC-A codes as EAN-13 with a leading zero
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/pos/products/barcode/0036000291452", nil, env.LoginAsCashier(t))
+
+	AssertStatus(t, w, http.StatusOK)
+}
+
+func TestBarcodeLookup_WeightEmbedded(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	// The checkout product costs 20000 per kg; the label carries 250 g
+	productID := seedCheckoutProduct(t, env)
+	seedBarcode(t, env, productID, "2512345", "variable")
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/pos/products/barcode/2512345002505", nil, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusOK)
+
+	embedded := ParseResponse(t, w)["data"].(map[string]interface{})["embedded"].(map[string]interface{})
+	if embedded["kind"] != "weight" || embedded["weight_grams"].(float64) != 250 {
+		t.Errorf("Expected 250 g weight, got %v", embedded)
+	}
+	if embedded["price"].(float64) != 5000 {
+		t.Errorf("Expected price 5000, got %v", embedded["price"])
+	}
+}
+
+func TestCheckout_PriceEmbeddedBarcode(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	seedBarcode(t, env, productID, "2012345", "variable")
+
+	body := map[string]interface{}{
+		"payment_method": "cash",
+		"items": []map[string]interface{}{
+			{"product_id": productID, "barcode": "2012345150003", "quantity": 1},
+		},
+	}
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusCreated)
+
+	item := ParseResponse(t, w)["data"].(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})
+	if item["unit_price"].(float64) != 15000 {
+		t.Errorf("Expected unit price 15000 from the label, got %v", item["unit_price"])
+	}
+}
+
+func TestBarcodeLabel_SVGAndPNG(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	barcodeID := seedBarcode(t, env, productID, "4006381333931", "ean13")
+	path := "/api/v1/products/" + productID + "/barcodes/" + barcodeID + "/label"
+	cookies := env.LoginAsCashier(t)
+
+	w := env.MakeRequest(t, http.MethodGet, path, nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "image/svg+xml") || !strings.HasPrefix(w.Body.String(), "<svg") {
+		t.Errorf("Expected an SVG label, got %s", w.Header().Get("Content-Type"))
+	}
+
+	w = env.MakeRequest(t, http.MethodGet, path+"?format=png", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	if !bytes.HasPrefix(w.Body.Bytes(), []byte("\x89PNG")) {
+		t.Errorf("Expected a PNG label")
+	}
+}
+
+func TestBarcodeLookup_NotFound(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/pos/products/barcode/4006381333931", nil, env.LoginAsCashier(t))
+
+	AssertStatus(t, w, http.StatusNotFound)
+}
+
+func TestBarcodeCreate_AsCashier_Forbidden(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	body := map[string]interface{}{"code": "4006381333931", "type": "ean13"}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/products/"+productID+"/barcodes", body, env.LoginAsCashier(t))
+
+	AssertStatus(t, w, http.StatusForbidden)
+}
@@ -0,0 +1,235 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// ============================================
+// Cursor Pagination Tests
+// ============================================
+
+// walkCursor follows next_cursor from the first page of path until the list ends and
+// returns the ids in the order they were listed, along with the meta of every page
+func walkCursor(t *testing.T, env *TestEnv, path string) ([]string, []map[string]interface{}) {
+	t.Helper()
+
+	cookies := env.LoginAsAdmin(t)
+	var ids []string
+	var metas []map[string]interface{}
+	next := path
+	for page := 0; page < 20; page++ {
+		w := env.MakeRequest(t, http.MethodGet, next, nil, cookies)
+		AssertStatus(t, w, http.StatusOK)
+
+		resp := ParseResponse(t, w)
+		for _, item := range resp["data"].([]interface{}) {
+			ids = append(ids, item.(map[string]interface{})["id"].(string))
+		}
+		meta := resp["meta"].(map[string]interface{})
+		metas = append(metas, meta)
+
+		cursor, _ := meta["next_cursor"].(string)
+		if cursor == "" {
+			return ids, metas
+		}
+		next = path + "&cursor=" + url.QueryEscape(cursor)
+	}
+	t.Fatal("Cursor pagination did not terminate")
+	return nil, nil
+}
+
+func TestCursor_WalksTransactionsWithoutGapsOrRepeats(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for i := 0; i < 5; i++ {
+		seedCompletedTransaction(t, env, TestCustomerID, 1000, base.Add(time.Duration(i)*time.Minute))
+	}
+	// Rows sharing a timestamp are ordered by id, so ties never straddle pages wrongly
+	for i := 0; i < 3; i++ {
+		seedCompletedTransaction(t, env, TestCustomerID, 1000, base.Add(-time.Minute))
+	}
+
+	ids, metas := walkCursor(t, env, "/api/v1/transactions?per_page=3")
+	if len(ids) != 8 {
+		t.Fatalf("Expected 8 transactions across pages, got %d", len(ids))
+	}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			t.Errorf("Transaction %s appeared on more than one page", id)
+		}
+		seen[id] = true
+	}
+
+	// Same order as a single offset page
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/transactions?per_page=8", nil, env.LoginAsAdmin(t))
+	AssertStatus(t, w, http.StatusOK)
+	for i, item := range ParseResponse(t, w)["data"].([]interface{}) {
+		if id := item.(map[string]interface{})["id"]; id != ids[i] {
+			t.Errorf("Position %d: expected %v, got %s", i, id, ids[i])
+		}
+	}
+
+	// The first page is an ordinary offset page; later pages are cursor pages
+	if metas[0]["page"] != float64(1) || metas[0]["total"] != float64(8) {
+		t.Errorf("Unexpected first page meta %v", metas[0])
+	}
+	if _, ok := metas[1]["page"]; ok || metas[1]["total_estimated"] != true {
+		t.Errorf("Expected an estimated cursor page meta, got %v", metas[1])
+	}
+}
+
+func TestCursor_ExactTotalOnRequest(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	for i := 0; i < 3; i++ {
+		seedCompletedTransaction(t, env, TestCustomerID, 1000, time.Now().Add(-time.Duration(i)*time.Minute))
+	}
+
+	_, metas := walkCursor(t, env, "/api/v1/transactions?per_page=1&include_total=true")
+	for _, meta := range metas {
+		if meta["total"] != float64(3) {
+			t.Errorf("Expected exact total 3, got %v", meta)
+		}
+		if _, ok := meta["total_estimated"]; ok {
+			t.Errorf("Expected total_estimated to be absent, got %v", meta)
+		}
+	}
+}
+
+func TestCursor_CustomerTransactionsFiltered(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	for i := 0; i < 4; i++ {
+		seedCompletedTransaction(t, env, TestCustomerID, 1000, time.Now().Add(-time.Duration(i)*time.Minute))
+	}
+
+	ids, _ := walkCursor(t, env, fmt.Sprintf("/api/v1/customers/%s/transactions?per_page=3&status=completed", TestCustomerID))
+	if len(ids) != 4 {
+		t.Errorf("Expected 4 customer transactions, got %d", len(ids))
+	}
+}
+
+func TestCursor_LoyaltyLedger(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := env.DB.Exec(`
+			INSERT INTO loyalty_ledger (id, customer_id, type, points, balance_after, remaining, created_at)
+			VALUES ($1, $2, 'adjust', 1, $3, 0, $4)
+		`, GenerateUUID(), TestCustomerID, 100+i, now.Add(-time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatalf("Failed to seed ledger entry: %v", err)
+		}
+	}
+
+	ids, _ := walkCursor(t, env, "/api/v1/customers/"+TestCustomerID+"/loyalty/ledger?per_page=2")
+	if len(ids) != 5 {
+		t.Errorf("Expected 5 ledger entries across pages, got %d", len(ids))
+	}
+}
+
+func TestCursor_NoCursorForCustomSort(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	for i := 0; i < 3; i++ {
+		seedCompletedTransaction(t, env, TestCustomerID, float64(1000*(i+1)), time.Now())
+	}
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/transactions?per_page=2&sort=-total_amount", nil, env.LoginAsAdmin(t))
+	AssertStatus(t, w, http.StatusOK)
+	if cursor, ok := ParseResponse(t, w)["meta"].(map[string]interface{})["next_cursor"]; ok {
+		t.Errorf("Expected no next_cursor for a custom sort, got %v", cursor)
+	}
+}
+
+func TestCursor_InvalidCursorRejected(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	seedCompletedTransaction(t, env, TestCustomerID, 1000, time.Now())
+
+	cookies := env.LoginAsAdmin(t)
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/transactions?per_page=1", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	cursor := ParseResponse(t, w)["meta"].(map[string]interface{})["next_cursor"].(string)
+
+	for _, path := range []string{
+		"/api/v1/transactions?cursor=not-a-cursor",
+		"/api/v1/transactions?cursor=" + url.QueryEscape(cursor) + "&sort=-total_amount",
+		"/api/v1/customers/" + TestCustomerID + "/loyalty/ledger?cursor=%21%21",
+	} {
+		w := env.MakeRequest(t, http.MethodGet, path, nil, cookies)
+		AssertStatus(t, w, http.StatusBadRequest)
+
+		errs, _ := ParseResponse(t, w)["errors"].([]interface{})
+		if len(errs) != 1 || errs[0].(map[string]interface{})["field"] != "cursor" {
+			t.Errorf("%s: expected a cursor validation error, got %v", path, errs)
+		}
+	}
+}
+
+func TestCursor_WalksProductStockMovements(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for i := 0; i < 5; i++ {
+		if _, err := env.DB.Exec(`
+			INSERT INTO stock_movements (id, product_id, type, quantity, balance_after, created_at)
+			VALUES ($1, $2, 'restock', 1, $3, $4)
+		`, GenerateUUID(), TestProductID, 101+i, base.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("Failed to seed stock movement: %v", err)
+		}
+	}
+
+	ids, _ := walkCursor(t, env, "/api/v1/products/stock-movements?per_page=2")
+	if len(ids) != 5 {
+		t.Fatalf("Expected 5 stock movements across pages, got %d", len(ids))
+	}
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/products/stock-movements?per_page=1", nil, env.LoginAsAdmin(t))
+	AssertStatus(t, w, http.StatusOK)
+	latest := ParseResponse(t, w)["data"].([]interface{})[0].(map[string]interface{})
+	if latest["product_name"] != "Test Product" || latest["new_balance"] != float64(105) {
+		t.Errorf("Expected the latest movement of Test Product with balance 105, got %v", latest)
+	}
+}
+
+func TestCategoryActivityLog(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+	body := map[string]interface{}{"name": "Snacks", "slug": "snacks"}
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/categories", body, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+	id := ParseResponse(t, w)["data"].(map[string]interface{})["id"].(string)
+
+	w = env.MakeRequest(t, http.MethodDelete, "/api/v1/categories/"+id, nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/categories/activity-log", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	events := map[string]bool{}
+	for _, item := range ParseResponse(t, w)["data"].([]interface{}) {
+		activity := item.(map[string]interface{})
+		if activity["category_id"] == id {
+			events[activity["event"].(string)] = true
+		}
+	}
+	if !events["created"] || !events["deleted"] {
+		t.Errorf("Expected created and deleted events for the category, got %v", events)
+	}
+}
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// ============================================
+// Customer History & Segmentation Tests
+// ============================================
+
+// seedCompletedTransaction inserts a completed transaction for a customer at the given time
+func seedCompletedTransaction(t *testing.T, env *TestEnv, customerID string, total float64, at time.Time) {
+	t.Helper()
+
+	id := GenerateUUID()
+	_, err := env.DB.Exec(`
+		INSERT INTO transactions (id, user_id, customer_id, invoice_number, subtotal, total_amount,
+		                          payment_method, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5, 'cash', 'completed', $6, $6)
+	`, id, TestAdminID, customerID, fmt.Sprintf("INV-TEST-%s", id[:8]), total, at)
+	if err != nil {
+		t.Fatalf("Failed to seed transaction: %v", err)
+	}
+}
+
+// checkout buys one unit of a product for the test customer through the API
+func checkout(t *testing.T, env *TestEnv, productID string) {
+	t.Helper()
+
+	body := map[string]interface{}{
+		"customer_id":    TestCustomerID,
+		"payment_method": "cash",
+		"items": []map[string]interface{}{
+			{"product_id": productID, "quantity": 1},
+		},
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusCreated)
+}
+
+func TestCustomerTransactions(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	checkout(t, env, productID)
+
+	cookies := env.LoginAsCashier(t)
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/customers/"+TestCustomerID+"/transactions", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	transactions := ParseResponse(t, w)["data"].([]interface{})
+	if len(transactions) != 1 {
+		t.Errorf("Expected 1 transaction, got %d", len(transactions))
+	}
+}
+
+func TestCustomerTransactions_NotFound(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsCashier(t)
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/customers/"+GenerateUUID()+"/transactions", nil, cookies)
+
+	AssertStatus(t, w, http.StatusNotFound)
+}
+
+func TestCustomerStats(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	checkout(t, env, productID)
+	checkout(t, env, productID)
+
+	cookies := env.LoginAsCashier(t)
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/customers/"+TestCustomerID+"/stats", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	if data["visit_count"] != float64(2) {
+		t.Errorf("Expected visit_count 2, got %v", data["visit_count"])
+	}
+	if data["lifetime_spend"] != float64(44000) {
+		t.Errorf("Expected lifetime_spend 44000, got %v", data["lifetime_spend"])
+	}
+	if data["average_basket"] != float64(22000) {
+		t.Errorf("Expected average_basket 22000, got %v", data["average_basket"])
+	}
+	if data["segment"] != "new" {
+		t.Errorf("Expected segment new, got %v", data["segment"])
+	}
+
+	favorites := data["favorite_products"].([]interface{})
+	if len(favorites) != 1 || favorites[0].(map[string]interface{})["quantity"] != float64(2) {
+		t.Errorf("Expected one favorite product bought twice, got %v", favorites)
+	}
+}
+
+func TestCustomerList_FilterBySegment(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	// Ten recent purchases of 600000 each make the test customer a VIP
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		seedCompletedTransaction(t, env, TestCustomerID, 600000, now.AddDate(0, 0, -i*5))
+	}
+
+	cookies := env.LoginAsCashier(t)
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/customers?segment=vip", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	customers := ParseResponse(t, w)["data"].([]interface{})
+	if len(customers) != 1 {
+		t.Fatalf("Expected 1 VIP customer, got %d", len(customers))
+	}
+	if id := customers[0].(map[string]interface{})["id"]; id != TestCustomerID {
+		t.Errorf("Expected VIP customer %s, got %v", TestCustomerID, id)
+	}
+}
+
+func TestCustomerList_SortByLifetimeSpend(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	otherID := GenerateUUID()
+	now := time.Now()
+	if _, err := env.DB.Exec(`
+		INSERT INTO customers (id, name, email, phone, address, loyalty_points, created_at, updated_at)
+		VALUES ($1, 'Big Spender', 'big@test.local', '081299999999', '', 0, $2, $2)
+	`, otherID, now); err != nil {
+		t.Fatalf("Failed to seed customer: %v", err)
+	}
+	seedCompletedTransaction(t, env, TestCustomerID, 10000, now)
+	seedCompletedTransaction(t, env, otherID, 90000, now)
+
+	cookies := env.LoginAsCashier(t)
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/customers?sort=lifetime_spend&order=desc", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	customers := ParseResponse(t, w)["data"].([]interface{})
+	if len(customers) < 2 {
+		t.Fatalf("Expected at least 2 customers, got %d", len(customers))
+	}
+	if id := customers[0].(map[string]interface{})["id"]; id != otherID {
+		t.Errorf("Expected top spender %s first, got %v", otherID, id)
+	}
+}
+
+func TestCustomerList_InvalidSegment(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsCashier(t)
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/customers?segment=whale", nil, cookies)
+
+	AssertStatus(t, w, http.StatusBadRequest)
+}
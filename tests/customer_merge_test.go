@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// ============================================
+// Customer Deduplication & Merge Tests
+// ============================================
+
+// seedDuplicateCustomer inserts a customer with its own contact details and loyalty points
+func seedDuplicateCustomer(t *testing.T, env *TestEnv, points int) string {
+	t.Helper()
+
+	id := GenerateUUID()
+	now := time.Now()
+	_, err := env.DB.Exec(`
+		INSERT INTO customers (id, name, email, phone, address, loyalty_points, phone_normalized, email_normalized,
+		                       created_at, updated_at)
+		VALUES ($1, 'Test Customer (dup)', '', '082200001111', '', $2, '082200001111', '', $3, $3)
+	`, id, points, now)
+	if err != nil {
+		t.Fatalf("Failed to seed customer: %v", err)
+	}
+	return id
+}
+
+func TestCustomerCreate_DuplicatePhone(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsCashier(t)
+
+	// Same number as the seeded customer, written with the country code
+	body := map[string]interface{}{
+		"name":  "Test Customer Again",
+		"phone": "+62 812-3456-7890",
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/customers", body, cookies)
+	AssertStatus(t, w, http.StatusConflict)
+
+	matches := ParseResponse(t, w)["data"].([]interface{})
+	if len(matches) != 1 || matches[0].(map[string]interface{})["id"] != TestCustomerID {
+		t.Errorf("Expected the seeded customer as the only match, got %v", matches)
+	}
+}
+
+func TestCustomerCreate_DuplicateEmail(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsCashier(t)
+
+	body := map[string]interface{}{
+		"name":  "Test Customer Again",
+		"email": "Customer@Test.Local",
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/customers", body, cookies)
+
+	AssertStatus(t, w, http.StatusConflict)
+}
+
+func TestCustomerDuplicates_Lookup(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsCashier(t)
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/customers/duplicates?phone=6281234567890", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	matches := ParseResponse(t, w)["data"].([]interface{})
+	if len(matches) != 1 {
+		t.Errorf("Expected 1 match, got %d", len(matches))
+	}
+}
+
+func TestCustomerMerge_AsAdmin(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	sourceID := seedDuplicateCustomer(t, env, 40)
+	seedCompletedTransaction(t, env, sourceID, 15000, time.Now())
+	cookies := env.LoginAsAdmin(t)
+
+	body := map[string]interface{}{
+		"source_ids": []string{sourceID},
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/customers/"+TestCustomerID+"/merge", body, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	customer := data["customer"].(map[string]interface{})
+	if customer["loyalty_points"] != float64(140) {
+		t.Errorf("Expected summed loyalty_points 140, got %v", customer["loyalty_points"])
+	}
+	merge := data["merges"].([]interface{})[0].(map[string]interface{})
+	if merge["transactions_moved"] != float64(1) {
+		t.Errorf("Expected 1 transaction moved, got %v", merge["transactions_moved"])
+	}
+
+	// The duplicate is gone and the audit record remains
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/customers/"+sourceID, nil, cookies)
+	AssertStatus(t, w, http.StatusNotFound)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/customers/"+TestCustomerID+"/merges", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	merges := ParseResponse(t, w)["data"].([]interface{})
+	if len(merges) != 1 || merges[0].(map[string]interface{})["merged_customer_id"] != sourceID {
+		t.Errorf("Expected one merge record for %s, got %v", sourceID, merges)
+	}
+}
+
+func TestCustomerMerge_AsManager_Forbidden(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	sourceID := seedDuplicateCustomer(t, env, 0)
+	cookies := env.LoginAsManager(t)
+
+	body := map[string]interface{}{
+		"source_ids": []string{sourceID},
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/customers/"+TestCustomerID+"/merge", body, cookies)
+
+	AssertStatus(t, w, http.StatusForbidden)
+}
+
+func TestCustomerMerge_IntoItself(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+
+	body := map[string]interface{}{
+		"source_ids": []string{TestCustomerID},
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/customers/"+TestCustomerID+"/merge", body, cookies)
+
+	AssertStatus(t, w, http.StatusBadRequest)
+}
@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+// ============================================
+// Ingredient & Recipe Tests
+// ============================================
+
+// seedLatteRecipe creates coffee beans (1 kg) and milk (1 l) and gives the product a recipe of
+// 18 g coffee and 150 ml milk, returning the ingredient IDs by name
+func seedLatteRecipe(t *testing.T, env *TestEnv, productID string) map[string]string {
+	t.Helper()
+
+	cookies := env.LoginAsAdmin(t)
+	ids := make(map[string]string)
+
+	for _, i := range []map[string]interface{}{
+		{"sku": "ING-COF-" + productID[:8], "name": "Coffee Beans", "unit": "kg", "stock": 1},
+		{"sku": "ING-MLK-" + productID[:8], "name": "Milk", "unit": "l", "stock": 1},
+	} {
+		w := env.MakeRequest(t, http.MethodPost, "/api/v1/ingredients", i, cookies)
+		AssertStatus(t, w, http.StatusCreated)
+		ids[i["name"].(string)] = ParseResponse(t, w)["data"].(map[string]interface{})["id"].(string)
+	}
+
+	body := map[string]interface{}{
+		"items": []map[string]interface{}{
+			{"ingredient_id": ids["Coffee Beans"], "quantity": 18, "unit": "g"},
+			{"ingredient_id": ids["Milk"], "quantity": 150, "unit": "ml"},
+		},
+	}
+	w := env.MakeRequest(t, http.MethodPut, "/api/v1/products/"+productID+"/recipe", body, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	return ids
+}
+
+func ingredientStock(t *testing.T, env *TestEnv, id string) float64 {
+	t.Helper()
+
+	var stock float64
+	if err := env.DB.QueryRow(`SELECT stock FROM ingredients WHERE id = $1`, id).Scan(&stock); err != nil {
+		t.Fatalf("Failed to read ingredient stock: %v", err)
+	}
+	return stock
+}
+
+func TestRecipe_Portions(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	seedLatteRecipe(t, env, productID)
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/products/"+productID+"/recipe", nil, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusOK)
+
+	// Milk is the limit: 1000 ml / 150 ml = 6 portions
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	if data["portions"].(float64) != 6 {
+		t.Errorf("Expected 6 portions, got %v", data["portions"])
+	}
+}
+
+func TestCheckout_DeductsRecipeIngredients(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	ids := seedLatteRecipe(t, env, productID)
+
+	body := map[string]interface{}{
+		"payment_method": "cash",
+		"items":          []map[string]interface{}{{"product_id": productID, "quantity": 2}},
+	}
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusCreated)
+
+	if stock := ingredientStock(t, env, ids["Coffee Beans"]); stock != 0.964 {
+		t.Errorf("Expected 0.964 kg coffee left, got %v", stock)
+	}
+	if stock := ingredientStock(t, env, ids["Milk"]); stock != 0.7 {
+		t.Errorf("Expected 0.7 l milk left, got %v", stock)
+	}
+
+	// The product's own stock is not used for recipe products
+	var productStock int
+	if err := env.DB.QueryRow(`SELECT stock FROM products WHERE id = $1`, productID).Scan(&productStock); err != nil {
+		t.Fatalf("Failed to read product stock: %v", err)
+	}
+	if productStock != 50 {
+		t.Errorf("Expected product stock to stay 50, got %d", productStock)
+	}
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/ingredients/"+ids["Milk"]+"/movements", nil, env.LoginAsManager(t))
+	AssertStatus(t, w, http.StatusOK)
+	movements := ParseResponse(t, w)["data"].([]interface{})
+	if len(movements) != 2 || movements[0].(map[string]interface{})["type"] != "sale" {
+		t.Errorf("Expected opening restock and a sale movement, got %v", movements)
+	}
+}
+
+func TestCheckout_InsufficientIngredient(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	ids := seedLatteRecipe(t, env, productID)
+
+	body := map[string]interface{}{
+		"payment_method": "cash",
+		"items":          []map[string]interface{}{{"product_id": productID, "quantity": 7}},
+	}
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	if stock := ingredientStock(t, env, ids["Milk"]); stock != 1 {
+		t.Errorf("Expected milk untouched at 1 l, got %v", stock)
+	}
+}
+
+func TestPOSProducts_UnavailableWhenIngredientOut(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	ids := seedLatteRecipe(t, env, productID)
+
+	body := map[string]interface{}{"type": "waste", "quantity": 1, "unit": "l", "note": "Spoiled"}
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/ingredients/"+ids["Milk"]+"/stock", body, env.LoginAsManager(t))
+	AssertStatus(t, w, http.StatusOK)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/pos/products", nil, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusOK)
+
+	for _, p := range ParseResponse(t, w)["data"].([]interface{}) {
+		product := p.(map[string]interface{})
+		if product["id"] != productID {
+			continue
+		}
+		if product["available"] != false || product["stock"].(float64) != 0 {
+			t.Errorf("Expected product to be unavailable, got %v", product)
+		}
+		return
+	}
+	t.Errorf("Product %s missing from POS products", productID)
+}
+
+func TestCancelTransaction_ReturnsIngredients(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	ids := seedLatteRecipe(t, env, productID)
+
+	body := map[string]interface{}{
+		"payment_method": "cash",
+		"items":          []map[string]interface{}{{"product_id": productID, "quantity": 1}},
+	}
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusCreated)
+	transactionID := ParseResponse(t, w)["data"].(map[string]interface{})["id"].(string)
+
+	w = env.MakeRequest(t, http.MethodPatch, "/api/v1/transactions/"+transactionID+"/status",
+		map[string]interface{}{"status": "cancelled"}, env.LoginAsManager(t))
+	AssertStatus(t, w, http.StatusOK)
+
+	if stock := ingredientStock(t, env, ids["Milk"]); stock != 1 {
+		t.Errorf("Expected milk back at 1 l, got %v", stock)
+	}
+}
+
+func TestRecipe_IncompatibleUnit(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	ids := seedLatteRecipe(t, env, productID)
+
+	// Milk is tracked by volume and cannot be measured in grams
+	body := map[string]interface{}{
+		"items": []map[string]interface{}{{"ingredient_id": ids["Milk"], "quantity": 150, "unit": "g"}},
+	}
+	w := env.MakeRequest(t, http.MethodPut, "/api/v1/products/"+productID+"/recipe", body, env.LoginAsAdmin(t))
+
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestIngredientDelete_UsedInRecipe(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	ids := seedLatteRecipe(t, env, productID)
+
+	w := env.MakeRequest(t, http.MethodDelete, "/api/v1/ingredients/"+ids["Coffee Beans"], nil, env.LoginAsAdmin(t))
+
+	AssertStatus(t, w, http.StatusBadRequest)
+}
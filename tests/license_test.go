@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ilramdhan/pos-api/internal/models"
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// ============================================
+// License Tests
+// ============================================
+
+func TestLicense_SeatLimitAndStatus(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	key := issueLicense(t, &utils.License{
+		Licensee:  "Cafe",
+		Plan:      models.PlanPro,
+		Seats:     2,
+		Stores:    1,
+		IssuedAt:  time.Now(),
+		ExpiresAt: time.Now().AddDate(1, 0, 0),
+	})
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/auth/register", map[string]interface{}{
+		"email":        "owner@licensed.local",
+		"password":     "Owner123!",
+		"name":         "Business Owner",
+		"businessName": "Cafe",
+		"licenseKey":   key,
+	}, nil)
+	AssertStatus(t, w, http.StatusCreated)
+	cookies := w.Result().Cookies()
+
+	// The owner takes the first seat
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/users", map[string]interface{}{
+		"email": "cashier1@licensed.local", "password": "Cashier123!", "name": "Cashier One", "role": "cashier",
+	}, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/users", map[string]interface{}{
+		"email": "cashier2@licensed.local", "password": "Cashier123!", "name": "Cashier Two", "role": "cashier",
+	}, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/license", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	status := ParseResponse(t, w)["data"].(map[string]interface{})
+	if status["status"] != models.LicenseActive || status["plan"] != models.PlanPro {
+		t.Errorf("Expected an active pro license, got %v %v", status["status"], status["plan"])
+	}
+	if status["seats"] != float64(2) || status["seats_used"] != float64(2) {
+		t.Errorf("Expected 2 of 2 seats used, got %v of %v", status["seats_used"], status["seats"])
+	}
+
+	// Pro includes exports but not multi-store
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/products/export", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/stock-transfers", nil, cookies)
+	AssertStatus(t, w, http.StatusForbidden)
+}
+
+func TestLicense_UnlicensedBusinessIsOnFreePlan(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies, _ := registerBusiness(t, env, "owner@free.local", "Free Cafe")
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/license", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	status := ParseResponse(t, w)["data"].(map[string]interface{})
+	if status["status"] != models.LicenseMissing || status["plan"] != models.PlanFree {
+		t.Errorf("Expected the free plan without a license, got %v %v", status["status"], status["plan"])
+	}
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/products/export", nil, cookies)
+	AssertStatus(t, w, http.StatusForbidden)
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/stock-transfers", nil, cookies)
+	AssertStatus(t, w, http.StatusForbidden)
+
+	// Activating a license unlocks its features
+	key := issueLicense(t, &utils.License{Licensee: "Free Cafe", Plan: models.PlanEnterprise, IssuedAt: time.Now()})
+	w = env.MakeRequest(t, http.MethodPut, "/api/v1/license", map[string]string{"license_key": key}, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/products/export", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/stock-transfers", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+}
+
+func TestLicense_RejectsForgedAndExpiredKeys(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+
+	w := env.MakeRequest(t, http.MethodPut, "/api/v1/license", map[string]string{"license_key": "not-a-license"}, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	expired := issueLicense(t, &utils.License{
+		Licensee:  "Default",
+		Plan:      models.PlanPro,
+		IssuedAt:  time.Now().AddDate(-1, 0, 0),
+		ExpiresAt: time.Now().AddDate(0, 0, -1),
+	})
+	w = env.MakeRequest(t, http.MethodPut, "/api/v1/license", map[string]string{"license_key": expired}, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/auth/register", map[string]interface{}{
+		"email":        "owner@expired.local",
+		"password":     "Owner123!",
+		"name":         "Business Owner",
+		"businessName": "Expired Cafe",
+		"licenseKey":   expired,
+	}, nil)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	// The default tenant keeps its license
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/license", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	if plan := ParseResponse(t, w)["data"].(map[string]interface{})["plan"]; plan != models.PlanEnterprise {
+		t.Errorf("Expected the enterprise plan to remain, got %v", plan)
+	}
+}
+
+func TestLicense_RestoringUserTakesSeat(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	key := issueLicense(t, &utils.License{Licensee: "Cafe", Plan: models.PlanPro, Seats: 2, IssuedAt: time.Now()})
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/auth/register", map[string]interface{}{
+		"email":        "owner@restore.local",
+		"password":     "Owner123!",
+		"name":         "Business Owner",
+		"businessName": "Cafe",
+		"licenseKey":   key,
+	}, nil)
+	AssertStatus(t, w, http.StatusCreated)
+	cookies := w.Result().Cookies()
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/users", map[string]interface{}{
+		"email": "cashier1@restore.local", "password": "Cashier123!", "name": "Cashier One", "role": "cashier",
+	}, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+	cashierID := ParseResponse(t, w)["data"].(map[string]interface{})["id"].(string)
+
+	w = env.MakeRequest(t, http.MethodDelete, "/api/v1/users/"+cashierID, nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	// The freed seat goes to someone else, so the deleted cashier cannot come back
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/users", map[string]interface{}{
+		"email": "cashier2@restore.local", "password": "Cashier123!", "name": "Cashier Two", "role": "cashier",
+	}, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/users/"+cashierID+"/restore", nil, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestLicense_KeyIsBoundToItsLicensee(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	key := issueLicense(t, &utils.License{Licensee: "Kopi Kita", Plan: models.PlanEnterprise, IssuedAt: time.Now()})
+
+	// Another business cannot register or activate with the key
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/auth/register", map[string]interface{}{
+		"email":        "owner@other.local",
+		"password":     "Owner123!",
+		"name":         "Business Owner",
+		"businessName": "Other Cafe",
+		"licenseKey":   key,
+	}, nil)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	cookies, _ := registerBusiness(t, env, "owner@other.local", "Other Cafe")
+	w = env.MakeRequest(t, http.MethodPut, "/api/v1/license", map[string]string{"license_key": key}, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	// The licensee can
+	cookies, _ = registerBusiness(t, env, "owner@kopikita.local", "Kopi Kita")
+	w = env.MakeRequest(t, http.MethodPut, "/api/v1/license", map[string]string{"license_key": key}, cookies)
+	AssertStatus(t, w, http.StatusOK)
+}
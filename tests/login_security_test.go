@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// ============================================
+// Sign-in Protection Tests
+// ============================================
+
+// loginFrom signs in with a password from an IP address
+func loginFrom(t *testing.T, env *TestEnv, ip, email, password string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]string{"email": email, "password": password})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = ip + ":1234"
+
+	w := httptest.NewRecorder()
+	env.Engine.ServeHTTP(w, req)
+	return w
+}
+
+// skipLoginDelay lifts the delays between wrong passwords, as if the user had waited them out
+func skipLoginDelay(t *testing.T, env *TestEnv) {
+	t.Helper()
+
+	if _, err := env.DB.Exec(`UPDATE login_attempts SET locked_until = NULL WHERE key LIKE 'login:email:%' AND failures > 0`); err != nil {
+		t.Fatalf("Failed to skip the sign-in delay: %v", err)
+	}
+}
+
+func TestLoginSecurity_DelaysThenLocksAccount(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cfg := env.Config.Login
+	for i := 1; i < cfg.MaxAttempts; i++ {
+		w := loginFrom(t, env, "192.0.2.10", "cashier@test.local", "wrong-password")
+		AssertStatus(t, w, http.StatusUnauthorized)
+
+		// From the configured failure on, even the right password has to wait
+		if i == cfg.DelayAfter {
+			w = loginFrom(t, env, "192.0.2.10", "cashier@test.local", "Cashier123!")
+			AssertStatus(t, w, http.StatusTooManyRequests)
+			if w.Header().Get("Retry-After") == "" {
+				t.Error("Expected a Retry-After header")
+			}
+		}
+		skipLoginDelay(t, env)
+	}
+
+	// The last allowed failure locks the account, even for the right password
+	w := loginFrom(t, env, "192.0.2.10", "cashier@test.local", "wrong-password")
+	AssertStatus(t, w, http.StatusTooManyRequests)
+	w = loginFrom(t, env, "192.0.2.11", "cashier@test.local", "Cashier123!")
+	AssertStatus(t, w, http.StatusTooManyRequests)
+
+	// Admins are told about it
+	admin := env.LoginAsAdmin(t)
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/notifications", nil, admin)
+	AssertStatus(t, w, http.StatusOK)
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	notifications := data["notifications"].([]interface{})
+	if len(notifications) != 1 || notifications[0].(map[string]interface{})["type"] != "security" {
+		t.Fatalf("Expected one security notification, got %v", notifications)
+	}
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/users/login-events?event=account_locked&user_id="+TestCashierID, nil, admin)
+	AssertStatus(t, w, http.StatusOK)
+	if total := ParseResponse(t, w)["meta"].(map[string]interface{})["total"]; total != float64(1) {
+		t.Errorf("Expected one account_locked event, got %v", total)
+	}
+
+	// An admin can unlock the account straight away
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/users/"+TestCashierID+"/unlock", nil, admin)
+	AssertStatus(t, w, http.StatusOK)
+	cashier := env.LoginAsCashier(t)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/auth/me/activity", nil, cashier)
+	AssertStatus(t, w, http.StatusOK)
+	events := ParseResponse(t, w)["data"].([]interface{})
+	if latest := events[0].(map[string]interface{})["event"]; latest != "login_succeeded" {
+		t.Errorf("Expected the latest event to be the sign-in, got %v", latest)
+	}
+	if next := events[1].(map[string]interface{})["event"]; next != "account_unlocked" {
+		t.Errorf("Expected the unlock before the sign-in, got %v", next)
+	}
+}
+
+func TestLoginSecurity_LocksIPAddress(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	// Guessing across many accounts from one address locks the address
+	limit := env.Config.Login.IPMaxAttempts
+	for i := 1; i < limit; i++ {
+		w := loginFrom(t, env, "198.51.100.7", fmt.Sprintf("nobody%d@test.local", i), "guess")
+		AssertStatus(t, w, http.StatusUnauthorized)
+	}
+	w := loginFrom(t, env, "198.51.100.7", "nobody@test.local", "guess")
+	AssertStatus(t, w, http.StatusTooManyRequests)
+
+	w = loginFrom(t, env, "198.51.100.7", "admin@test.local", "Admin123!")
+	AssertStatus(t, w, http.StatusTooManyRequests)
+
+	// Other addresses are unaffected
+	w = loginFrom(t, env, "198.51.100.8", "admin@test.local", "Admin123!")
+	AssertStatus(t, w, http.StatusOK)
+}
+
+func TestLoginSecurity_IgnoresForwardedForFromUntrustedClients(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	// Without trusted proxies a client cannot pick the IP address its failures count against
+	body, _ := json.Marshal(map[string]string{"email": "cashier@test.local", "password": "wrong-password"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", "203.0.113.99")
+	req.RemoteAddr = "192.0.2.30:1234"
+	w := httptest.NewRecorder()
+	env.Engine.ServeHTTP(w, req)
+	AssertStatus(t, w, http.StatusUnauthorized)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/users/login-events?event=login_failed&user_id="+TestCashierID, nil, env.LoginAsAdmin(t))
+	AssertStatus(t, w, http.StatusOK)
+	events := ParseResponse(t, w)["data"].([]interface{})
+	if len(events) != 1 || events[0].(map[string]interface{})["ip_address"] != "192.0.2.30" {
+		t.Errorf("Expected the failure from the connection's address, got %v", events)
+	}
+}
@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// ============================================
+// Loyalty Tests
+// ============================================
+
+// customerPoints reads a customer's current loyalty balance
+func customerPoints(t *testing.T, env *TestEnv, customerID string) int {
+	t.Helper()
+
+	var points int
+	if err := env.DB.QueryRow(`SELECT loyalty_points FROM customers WHERE id = $1`, customerID).Scan(&points); err != nil {
+		t.Fatalf("Failed to read loyalty points: %v", err)
+	}
+	return points
+}
+
+func TestLoyaltyEarn_OnCheckout(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	cookies := env.LoginAsCashier(t)
+
+	// 20000 + 10% tax = 22000, earning 2 points at the default 1 point per 10000
+	body := map[string]interface{}{
+		"customer_id":    TestCustomerID,
+		"payment_method": "cash",
+		"items": []map[string]interface{}{
+			{"product_id": productID, "quantity": 1},
+		},
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	if data["points_earned"] != float64(2) {
+		t.Errorf("Expected points_earned 2, got %v", data["points_earned"])
+	}
+	if points := customerPoints(t, env, TestCustomerID); points != 102 {
+		t.Errorf("Expected balance 102, got %d", points)
+	}
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/customers/"+TestCustomerID+"/loyalty/ledger", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	entries := ParseResponse(t, w)["data"].([]interface{})
+	if len(entries) != 1 {
+		t.Errorf("Expected 1 ledger entry, got %d", len(entries))
+	}
+}
+
+func TestLoyaltyRedeem_AsDiscount(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	cookies := env.LoginAsCashier(t)
+
+	// 50 points at the default value of 100 each take 5000 off the 22000 due
+	body := map[string]interface{}{
+		"customer_id":    TestCustomerID,
+		"payment_method": "cash",
+		"redeem_points":  50,
+		"items": []map[string]interface{}{
+			{"product_id": productID, "quantity": 1},
+		},
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	if data["total_amount"] != float64(17000) {
+		t.Errorf("Expected total_amount 17000, got %v", data["total_amount"])
+	}
+	if points := customerPoints(t, env, TestCustomerID); points != 51 {
+		t.Errorf("Expected balance 51 (100 - 50 redeemed + 1 earned), got %d", points)
+	}
+}
+
+func TestLoyaltyRedeem_InsufficientPoints(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	cookies := env.LoginAsCashier(t)
+
+	body := map[string]interface{}{
+		"customer_id":    TestCustomerID,
+		"payment_method": "cash",
+		"redeem_points":  150,
+		"items": []map[string]interface{}{
+			{"product_id": productID, "quantity": 1},
+		},
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, cookies)
+
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestLoyaltyAdjust_AsManager(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsManager(t)
+
+	body := map[string]interface{}{
+		"points":    30,
+		"operation": "deduct",
+		"note":      "Goodwill correction",
+	}
+
+	w := env.MakeRequest(t, http.MethodPatch, "/api/v1/customers/"+TestCustomerID+"/loyalty-points", body, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	if data["balance"] != float64(70) {
+		t.Errorf("Expected balance 70, got %v", data["balance"])
+	}
+}
+
+func TestLoyaltyAdjust_AsCashier_Forbidden(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsCashier(t)
+
+	body := map[string]interface{}{
+		"points":    30,
+		"operation": "add",
+	}
+
+	w := env.MakeRequest(t, http.MethodPatch, "/api/v1/customers/"+TestCustomerID+"/loyalty-points", body, cookies)
+
+	AssertStatus(t, w, http.StatusForbidden)
+}
+
+func TestLoyaltyExpirePoints(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	now := time.Now()
+	_, err := env.DB.Exec(`
+		INSERT INTO loyalty_ledger (id, customer_id, type, points, balance_after, remaining, expires_at, created_at)
+		VALUES ($1, $2, 'earn', 20, 100, 20, $3, $4)
+	`, GenerateUUID(), TestCustomerID, now.Add(-time.Hour), now.AddDate(-1, 0, 0))
+	if err != nil {
+		t.Fatalf("Failed to seed ledger entry: %v", err)
+	}
+
+	expired, err := env.LoyaltyService.ExpirePoints(context.Background())
+	if err != nil {
+		t.Fatalf("ExpirePoints failed: %v", err)
+	}
+	if expired != 20 {
+		t.Errorf("Expected 20 points expired, got %d", expired)
+	}
+	if points := customerPoints(t, env, TestCustomerID); points != 80 {
+		t.Errorf("Expected balance 80, got %d", points)
+	}
+}
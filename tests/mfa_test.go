@@ -0,0 +1,197 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ilramdhan/pos-api/internal/utils"
+)
+
+// ============================================
+// Two-Factor Authentication Tests
+// ============================================
+
+// totpCode returns the authenticator code for a secret at a time step
+func totpCode(t *testing.T, secret string, step int64) string {
+	t.Helper()
+
+	code, err := utils.TOTPCode(secret, step)
+	if err != nil {
+		t.Fatalf("Failed to generate TOTP code: %v", err)
+	}
+	return code
+}
+
+// enrollMFA turns on two-factor authentication for a signed-in user and returns the secret, the
+// time step of the code it enrolled with and the recovery codes
+func enrollMFA(t *testing.T, env *TestEnv, cookies []*http.Cookie) (string, int64, []interface{}) {
+	t.Helper()
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/auth/me/mfa/setup", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	setup := ParseResponse(t, w)["data"].(map[string]interface{})
+	secret := setup["secret"].(string)
+
+	step := utils.TOTPStep(time.Now())
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/auth/me/mfa/enable", map[string]interface{}{
+		"code": totpCode(t, secret, step),
+	}, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	codes := ParseResponse(t, w)["data"].(map[string]interface{})["recovery_codes"].([]interface{})
+	return secret, step, codes
+}
+
+// passwordLogin signs in with a password and returns the MFA challenge
+func passwordLogin(t *testing.T, env *TestEnv, email, password string) map[string]interface{} {
+	t.Helper()
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/auth/login", map[string]interface{}{
+		"email": email, "password": password,
+	}, nil)
+	AssertStatus(t, w, http.StatusOK)
+	if len(w.Result().Cookies()) != 0 {
+		t.Fatal("Expected no session before the second factor")
+	}
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	if data["mfa_required"] != true {
+		t.Fatalf("Expected a two-factor challenge, got %v", data)
+	}
+	return data["mfa"].(map[string]interface{})
+}
+
+func TestMFA_EnrolledUserSignsInWithCode(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	secret, step, recoveryCodes := enrollMFA(t, env, env.LoginAsManager(t))
+	if len(recoveryCodes) != 10 {
+		t.Fatalf("Expected 10 recovery codes, got %d", len(recoveryCodes))
+	}
+
+	challenge := passwordLogin(t, env, "manager@test.local", "Manager123!")
+	token := challenge["token"].(string)
+
+	// The challenge token is not a session
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	env.Engine.ServeHTTP(w, req)
+	AssertStatus(t, w, http.StatusUnauthorized)
+
+	// The code used to enroll cannot be used again; the next one can
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/auth/mfa/verify", map[string]interface{}{
+		"mfa_token": token, "code": totpCode(t, secret, step),
+	}, nil)
+	AssertStatus(t, w, http.StatusUnauthorized)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/auth/mfa/verify", map[string]interface{}{
+		"mfa_token": token, "code": totpCode(t, secret, step+1),
+	}, nil)
+	AssertStatus(t, w, http.StatusOK)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/auth/me", nil, w.Result().Cookies())
+	AssertStatus(t, w, http.StatusOK)
+	if enabled := ParseResponse(t, w)["data"].(map[string]interface{})["mfa_enabled"]; enabled != true {
+		t.Errorf("Expected mfa_enabled, got %v", enabled)
+	}
+
+	// A recovery code works once
+	recovery := recoveryCodes[0].(string)
+	token = passwordLogin(t, env, "manager@test.local", "Manager123!")["token"].(string)
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/auth/mfa/verify", map[string]interface{}{
+		"mfa_token": token, "recovery_code": recovery,
+	}, nil)
+	AssertStatus(t, w, http.StatusOK)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/auth/mfa/verify", map[string]interface{}{
+		"mfa_token": token, "recovery_code": recovery,
+	}, nil)
+	AssertStatus(t, w, http.StatusUnauthorized)
+}
+
+func TestMFA_RoleRequiresEnrollment(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	admin := env.LoginAsAdmin(t)
+	w := env.MakeRequest(t, http.MethodPut, "/api/v1/roles/manager", map[string]interface{}{
+		"require_mfa": true,
+	}, admin)
+	AssertStatus(t, w, http.StatusOK)
+	if required := ParseResponse(t, w)["data"].(map[string]interface{})["require_mfa"]; required != true {
+		t.Fatalf("Expected the manager role to require 2FA, got %v", required)
+	}
+
+	// The manager has to enroll before getting a session
+	challenge := passwordLogin(t, env, "manager@test.local", "Manager123!")
+	if challenge["enrollment_required"] != true {
+		t.Fatalf("Expected enrollment to be required, got %v", challenge)
+	}
+	token := challenge["token"].(string)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/auth/mfa/setup", map[string]interface{}{"mfa_token": token}, nil)
+	AssertStatus(t, w, http.StatusOK)
+	setup := ParseResponse(t, w)["data"].(map[string]interface{})
+	secret := setup["secret"].(string)
+	if uri := setup["provisioning_uri"].(string); !strings.HasPrefix(uri, "otpauth://totp/") {
+		t.Errorf("Expected an otpauth URI, got %s", uri)
+	}
+
+	step := utils.TOTPStep(time.Now())
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/auth/mfa/verify", map[string]interface{}{
+		"mfa_token": token, "code": totpCode(t, secret, step),
+	}, nil)
+	AssertStatus(t, w, http.StatusOK)
+	if codes := ParseResponse(t, w)["data"].(map[string]interface{})["recovery_codes"]; codes == nil {
+		t.Error("Expected recovery codes after enrolling")
+	}
+	manager := w.Result().Cookies()
+
+	// The role's requirement cannot be switched off by the user
+	w = env.MakeRequest(t, http.MethodDelete, "/api/v1/auth/me/mfa", map[string]interface{}{
+		"current_password": "Manager123!", "code": totpCode(t, secret, step+1),
+	}, manager)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	// An admin can reset it for a user who lost their authenticator; they enroll again
+	w = env.MakeRequest(t, http.MethodDelete, "/api/v1/users/"+TestManagerID+"/mfa", nil, admin)
+	AssertStatus(t, w, http.StatusOK)
+	if challenge := passwordLogin(t, env, "manager@test.local", "Manager123!"); challenge["enrollment_required"] != true {
+		t.Errorf("Expected enrollment to be required again, got %v", challenge)
+	}
+
+	// Other roles are unaffected
+	if cashier := env.LoginAsCashier(t); len(cashier) == 0 {
+		t.Error("Expected the cashier to sign in with just a password")
+	}
+}
+
+func TestMFA_WrongCodesLockSecondFactor(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	secret, step, _ := enrollMFA(t, env, env.LoginAsCashier(t))
+	token := passwordLogin(t, env, "cashier@test.local", "Cashier123!")["token"].(string)
+
+	// A wrong code that cannot match any step the server accepts
+	wrong := "000000"
+	for s := step - 1; s <= step+2; s++ {
+		if totpCode(t, secret, s) == wrong {
+			wrong = "111111"
+		}
+	}
+	for i := 0; i < env.Config.MFA.MaxAttempts; i++ {
+		w := env.MakeRequest(t, http.MethodPost, "/api/v1/auth/mfa/verify", map[string]interface{}{
+			"mfa_token": token, "code": wrong,
+		}, nil)
+		AssertStatus(t, w, http.StatusUnauthorized)
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/auth/mfa/verify", map[string]interface{}{
+		"mfa_token": token, "code": totpCode(t, secret, step+1),
+	}, nil)
+	AssertStatus(t, w, http.StatusTooManyRequests)
+}
@@ -0,0 +1,261 @@
+package tests
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+// ============================================
+// Override Tests
+// ============================================
+
+// createSale sells one unit of a product and returns the transaction ID
+func createSale(t *testing.T, env *TestEnv, cookies []*http.Cookie, productID string) string {
+	t.Helper()
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", map[string]interface{}{
+		"payment_method": "cash",
+		"items":          []map[string]interface{}{{"product_id": productID, "quantity": 1}},
+	}, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+	return ParseResponse(t, w)["data"].(map[string]interface{})["id"].(string)
+}
+
+func TestOverride_RefundWithManagerApproval(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cashier := env.LoginAsCashier(t)
+	productID := seedCheckoutProduct(t, env)
+	transactionID := createSale(t, env, cashier, productID)
+
+	w := env.MakeRequest(t, http.MethodPatch, "/api/v1/transactions/"+transactionID+"/status", map[string]interface{}{
+		"status": "refunded",
+	}, cashier)
+	AssertStatus(t, w, http.StatusForbidden)
+
+	// The manager approves the refund on the cashier's terminal
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/overrides", map[string]interface{}{
+		"action":         "refund",
+		"reference_id":   transactionID,
+		"reason":         "Damaged item",
+		"approver_email": "manager@test.local",
+		"password":       "Manager123!",
+	}, cashier)
+	AssertStatus(t, w, http.StatusCreated)
+	token := ParseResponse(t, w)["data"].(map[string]interface{})["token"].(string)
+
+	w = env.MakeRequest(t, http.MethodPatch, "/api/v1/transactions/"+transactionID+"/status", map[string]interface{}{
+		"status": "refunded", "override_token": token,
+	}, cashier)
+	AssertStatus(t, w, http.StatusOK)
+
+	// Approvals are single use
+	otherID := createSale(t, env, cashier, productID)
+	w = env.MakeRequest(t, http.MethodPatch, "/api/v1/transactions/"+otherID+"/status", map[string]interface{}{
+		"status": "refunded", "override_token": token,
+	}, cashier)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/overrides?action=refund", nil, env.LoginAsManager(t))
+	AssertStatus(t, w, http.StatusOK)
+	overrides := ParseResponse(t, w)["data"].([]interface{})
+	if len(overrides) != 1 {
+		t.Fatalf("Expected 1 approval, got %d", len(overrides))
+	}
+	override := overrides[0].(map[string]interface{})
+	if override["requested_by"] != TestCashierID || override["approved_by"] != TestManagerID || override["used_at"] == nil {
+		t.Errorf("Unexpected approval record: %v", override)
+	}
+}
+
+func TestOverride_DiscountApprovedWithPIN(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	w := env.MakeRequest(t, http.MethodPut, "/api/v1/auth/me/pin", map[string]interface{}{
+		"current_password": "Manager123!", "pin": "2468",
+	}, env.LoginAsManager(t))
+	AssertStatus(t, w, http.StatusOK)
+
+	cashier := env.LoginAsCashier(t)
+	sale := map[string]interface{}{
+		"payment_method":  "cash",
+		"discount_amount": 1000,
+		"items":           []map[string]interface{}{{"product_id": seedCheckoutProduct(t, env), "quantity": 1}},
+	}
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", sale, cashier)
+	AssertStatus(t, w, http.StatusForbidden)
+
+	// A wrong PIN is rejected
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/overrides", map[string]interface{}{
+		"action": "discount", "amount": 1000, "approver_email": "manager@test.local", "pin": "1357",
+	}, cashier)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/overrides", map[string]interface{}{
+		"action": "discount", "amount": 1000, "approver_email": "manager@test.local", "pin": "2468",
+	}, cashier)
+	AssertStatus(t, w, http.StatusCreated)
+
+	sale["discount_override_token"] = ParseResponse(t, w)["data"].(map[string]interface{})["token"]
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", sale, cashier)
+	AssertStatus(t, w, http.StatusCreated)
+}
+
+func TestOverride_ApproverNeedsPermission(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	// Cashiers cannot approve actions for others, nor open the drawer alone
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/overrides", map[string]interface{}{
+		"action": "no_sale", "approver_email": "cashier@test.local", "password": "Cashier123!",
+	}, env.LoginAsManager(t))
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/pos/no-sale", map[string]interface{}{
+		"reason": "Change for the float",
+	}, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusForbidden)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/pos/no-sale", map[string]interface{}{
+		"reason": "Change for the float",
+	}, env.LoginAsManager(t))
+	AssertStatus(t, w, http.StatusOK)
+}
+
+func TestOverride_WrongPINsLockApprover(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	w := env.MakeRequest(t, http.MethodPut, "/api/v1/auth/me/pin", map[string]interface{}{
+		"current_password": "Manager123!", "pin": "2468",
+	}, env.LoginAsManager(t))
+	AssertStatus(t, w, http.StatusOK)
+
+	// The manager's PIN locks after PIN_MAX_ATTEMPTS wrong guesses, 5 by default
+	cashier := env.LoginAsCashier(t)
+	for i := 1; i <= 5; i++ {
+		w = env.MakeRequest(t, http.MethodPost, "/api/v1/overrides", map[string]interface{}{
+			"action": "discount", "amount": 1000, "approver_email": "manager@test.local", "pin": "100" + strconv.Itoa(i),
+		}, cashier)
+		if i < 5 {
+			AssertStatus(t, w, http.StatusBadRequest)
+		} else {
+			AssertStatus(t, w, http.StatusTooManyRequests)
+		}
+	}
+
+	// Even the right PIN is refused while locked
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/overrides", map[string]interface{}{
+		"action": "discount", "amount": 1000, "approver_email": "manager@test.local", "pin": "2468",
+	}, cashier)
+	AssertStatus(t, w, http.StatusTooManyRequests)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/users/login-events?event=approval_failed&user_id="+TestManagerID, nil, env.LoginAsAdmin(t))
+	AssertStatus(t, w, http.StatusOK)
+	if events := ParseResponse(t, w)["data"].([]interface{}); len(events) != 5 {
+		t.Errorf("Expected 5 failed approvals in the sign-in audit log, got %d", len(events))
+	}
+}
+
+func TestOverride_TokenKeptWhenSaleFails(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cashier := env.LoginAsCashier(t)
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/overrides", map[string]interface{}{
+		"action": "discount", "amount": 1000, "approver_email": "manager@test.local", "password": "Manager123!",
+	}, cashier)
+	AssertStatus(t, w, http.StatusCreated)
+	token := ParseResponse(t, w)["data"].(map[string]interface{})["token"]
+
+	// The sale fails for lack of stock, so the approval is not used up
+	productID := seedCheckoutProduct(t, env)
+	sale := map[string]interface{}{
+		"payment_method":          "cash",
+		"discount_amount":         1000,
+		"discount_override_token": token,
+		"items":                   []map[string]interface{}{{"product_id": productID, "quantity": 500}},
+	}
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", sale, cashier)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	sale["items"] = []map[string]interface{}{{"product_id": productID, "quantity": 1}}
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", sale, cashier)
+	AssertStatus(t, w, http.StatusCreated)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", sale, cashier)
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestOverride_RequestsAreRateLimited(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	// RATE_LIMIT_OVERRIDES_PER_MINUTE allows 10 requests a minute by default
+	manager := env.LoginAsManager(t)
+	request := map[string]interface{}{
+		"action": "no_sale", "approver_email": "cashier@test.local", "password": "Cashier123!",
+	}
+	for i := 0; i < 10; i++ {
+		w := env.MakeRequest(t, http.MethodPost, "/api/v1/overrides", request, manager)
+		AssertStatus(t, w, http.StatusBadRequest)
+	}
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/overrides", request, manager)
+	AssertStatus(t, w, http.StatusTooManyRequests)
+}
+
+func TestOverride_ApprovalBoundToAmountAndTerminal(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cashier := env.LoginAsCashier(t)
+	productID := seedCheckoutProduct(t, env)
+
+	// Discounts are approved for one amount
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/overrides", map[string]interface{}{
+		"action": "discount", "approver_email": "manager@test.local", "password": "Manager123!",
+	}, cashier)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/overrides", map[string]interface{}{
+		"action": "discount", "amount": 1000, "approver_email": "manager@test.local", "password": "Manager123!",
+	}, cashier)
+	AssertStatus(t, w, http.StatusCreated)
+	sale := map[string]interface{}{
+		"payment_method":          "cash",
+		"discount_amount":         5000,
+		"discount_override_token": ParseResponse(t, w)["data"].(map[string]interface{})["token"],
+		"items":                   []map[string]interface{}{{"product_id": productID, "quantity": 1}},
+	}
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", sale, cashier)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	sale["discount_amount"] = 1000
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", sale, cashier)
+	AssertStatus(t, w, http.StatusCreated)
+
+	// An approval given on a terminal is only used there
+	key := registerTerminal(t, env, "T06")
+	setPIN(t, env, "cashier@test.local", "Cashier123!", "1234")
+	w = pinLogin(env, key, TestCashierID, "1234")
+	AssertStatus(t, w, http.StatusOK)
+	terminal := w.Result().Cookies()
+
+	transactionID := createSale(t, env, cashier, productID)
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/overrides", map[string]interface{}{
+		"action": "refund", "reference_id": transactionID, "approver_email": "manager@test.local", "password": "Manager123!",
+	}, terminal)
+	AssertStatus(t, w, http.StatusCreated)
+	refund := map[string]interface{}{
+		"status": "refunded", "override_token": ParseResponse(t, w)["data"].(map[string]interface{})["token"],
+	}
+
+	w = env.MakeRequest(t, http.MethodPatch, "/api/v1/transactions/"+transactionID+"/status", refund, cashier)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodPatch, "/api/v1/transactions/"+transactionID+"/status", refund, terminal)
+	AssertStatus(t, w, http.StatusOK)
+}
@@ -0,0 +1,278 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ilramdhan/pos-api/internal/config"
+	"github.com/ilramdhan/pos-api/internal/storage"
+)
+
+// ============================================
+// Product Image Tests
+// ============================================
+
+// testPNG renders a solid PNG of the given size
+func testPNG(t *testing.T, width, height int, fill color.Color) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, fill)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// uploadProductImage posts data as the "image" field of a multipart form
+func uploadProductImage(t *testing.T, env *TestEnv, cookies []*http.Cookie, productID, filename string, data []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("image", filename)
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(data)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products/"+productID+"/image", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	w := httptest.NewRecorder()
+	env.Engine.ServeHTTP(w, req)
+	return w
+}
+
+func getMedia(env *TestEnv, url string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	env.Engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestProductImage_Upload(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+	w := uploadProductImage(t, env, cookies, TestProductID, "photo.png", testPNG(t, 800, 400, color.RGBA{200, 30, 30, 255}))
+	AssertStatus(t, w, http.StatusOK)
+
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	imageURL := data["image_url"].(string)
+	if !strings.HasPrefix(imageURL, "/media/products/"+TestProductID+"/") || !strings.HasSuffix(imageURL, ".png") {
+		t.Errorf("Unexpected image URL %s", imageURL)
+	}
+	if data["content_type"] != "image/png" || data["width"].(float64) != 800 || data["height"].(float64) != 400 {
+		t.Errorf("Unexpected image metadata %v", data)
+	}
+
+	// The product now points at the stored image and thumbnail
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/products/"+TestProductID, nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	product := ParseResponse(t, w)["data"].(map[string]interface{})
+	if product["image_url"] != imageURL || product["thumbnail_url"] != data["thumbnail_url"] {
+		t.Errorf("Expected product image URLs to be updated, got %v", product)
+	}
+}
+
+func TestProductImage_ThumbnailIsResized(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	w := uploadProductImage(t, env, env.LoginAsAdmin(t), TestProductID, "photo.png", testPNG(t, 800, 400, color.RGBA{30, 200, 30, 255}))
+	AssertStatus(t, w, http.StatusOK)
+	thumbURL := ParseResponse(t, w)["data"].(map[string]interface{})["thumbnail_url"].(string)
+
+	w = getMedia(env, thumbURL, nil)
+	AssertStatus(t, w, http.StatusOK)
+
+	thumb, format, err := image.Decode(w.Body)
+	if err != nil {
+		t.Fatalf("Failed to decode thumbnail: %v", err)
+	}
+	if format != "png" || thumb.Bounds().Dx() != 320 || thumb.Bounds().Dy() != 160 {
+		t.Errorf("Expected 320x160 PNG thumbnail, got %s %v", format, thumb.Bounds())
+	}
+}
+
+func TestProductImage_ServedWithCacheHeaders(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	w := uploadProductImage(t, env, env.LoginAsAdmin(t), TestProductID, "photo.png", testPNG(t, 64, 64, color.White))
+	AssertStatus(t, w, http.StatusOK)
+	imageURL := ParseResponse(t, w)["data"].(map[string]interface{})["image_url"].(string)
+
+	// Served without authentication
+	w = getMedia(env, imageURL, nil)
+	AssertStatus(t, w, http.StatusOK)
+	if w.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("Expected image/png, got %s", w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("Expected immutable Cache-Control, got %q", w.Header().Get("Cache-Control"))
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag header")
+	}
+
+	w = getMedia(env, imageURL, http.Header{"If-None-Match": {etag}})
+	AssertStatus(t, w, http.StatusNotModified)
+
+	w = getMedia(env, "/media/products/"+TestProductID+"/missing.png", nil)
+	AssertStatus(t, w, http.StatusNotFound)
+}
+
+func TestProductImage_RejectsNonImage(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	// The file name claims PNG but the content is sniffed
+	w := uploadProductImage(t, env, env.LoginAsAdmin(t), TestProductID, "photo.png", []byte("<html><body>not an image</body></html>"))
+	AssertStatus(t, w, http.StatusUnsupportedMediaType)
+}
+
+func TestProductImage_RejectsTooLarge(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	// The test environment limits images to 1 MB
+	data := append(testPNG(t, 8, 8, color.Black), make([]byte, 1<<20)...)
+	w := uploadProductImage(t, env, env.LoginAsAdmin(t), TestProductID, "photo.png", data)
+	AssertStatus(t, w, http.StatusRequestEntityTooLarge)
+}
+
+func TestProductImage_ReplaceAndDeleteRemoveObjects(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+	w := uploadProductImage(t, env, cookies, TestProductID, "a.png", testPNG(t, 32, 32, color.Black))
+	AssertStatus(t, w, http.StatusOK)
+	first := ParseResponse(t, w)["data"].(map[string]interface{})["image_url"].(string)
+
+	w = uploadProductImage(t, env, cookies, TestProductID, "b.png", testPNG(t, 32, 32, color.White))
+	AssertStatus(t, w, http.StatusOK)
+	second := ParseResponse(t, w)["data"].(map[string]interface{})["image_url"].(string)
+
+	if first == second {
+		t.Fatal("Expected different content to get a different URL")
+	}
+	AssertStatus(t, getMedia(env, first, nil), http.StatusNotFound)
+	AssertStatus(t, getMedia(env, second, nil), http.StatusOK)
+
+	w = env.MakeRequest(t, http.MethodDelete, "/api/v1/products/"+TestProductID+"/image", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	AssertStatus(t, getMedia(env, second, nil), http.StatusNotFound)
+
+	var imageURL, thumbURL string
+	if err := env.DB.QueryRow(`SELECT image_url, thumbnail_url FROM products WHERE id = $1`, TestProductID).Scan(&imageURL, &thumbURL); err != nil {
+		t.Fatalf("Failed to read product: %v", err)
+	}
+	if imageURL != "" || thumbURL != "" {
+		t.Errorf("Expected image URLs to be cleared, got %q %q", imageURL, thumbURL)
+	}
+}
+
+func TestProductImage_CashierForbidden(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	w := uploadProductImage(t, env, env.LoginAsCashier(t), TestProductID, "photo.png", testPNG(t, 16, 16, color.Black))
+	AssertStatus(t, w, http.StatusForbidden)
+}
+
+// TestBlobStore_S3 exercises the S3 driver against a real S3-compatible server, e.g.
+// docker run -p 9000:9000 minio/minio server /data, with a bucket created beforehand.
+// It is skipped unless TEST_S3_ENDPOINT is set.
+func TestBlobStore_S3(t *testing.T) {
+	endpoint := os.Getenv("TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("TEST_S3_ENDPOINT not set")
+	}
+
+	store, err := storage.New(&config.StorageConfig{
+		Driver:      "s3",
+		S3Endpoint:  endpoint,
+		S3Region:    "us-east-1",
+		S3Bucket:    os.Getenv("TEST_S3_BUCKET"),
+		S3AccessKey: os.Getenv("TEST_S3_ACCESS_KEY"),
+		S3SecretKey: os.Getenv("TEST_S3_SECRET_KEY"),
+		S3PathStyle: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create S3 store: %v", err)
+	}
+
+	ctx := context.Background()
+	key := "tests/" + GenerateUUID() + "/image file.png"
+	data := testPNG(t, 4, 4, color.Black)
+	if err := store.Put(ctx, key, data, "image/png"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	obj, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !bytes.Equal(obj.Data, data) || obj.ContentType != "image/png" {
+		t.Errorf("Unexpected object %s (%d bytes)", obj.ContentType, len(obj.Data))
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestBlobStore_LocalRejectsEscapingKeys(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create local store: %v", err)
+	}
+
+	ctx := context.Background()
+	for _, key := range []string{"../outside.png", "a/../../outside.png", "", "a//b.png"} {
+		if err := store.Put(ctx, key, []byte("x"), "image/png"); err == nil {
+			t.Errorf("Expected key %q to be rejected", key)
+		}
+	}
+
+	if err := store.Put(ctx, "a/b.png", []byte("x"), "image/png"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	obj, err := store.Get(ctx, "a/b.png")
+	if err != nil || string(obj.Data) != "x" || obj.ContentType != "image/png" {
+		t.Errorf("Unexpected object %+v, %v", obj, err)
+	}
+	if _, err := store.Get(ctx, "a/missing.png"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
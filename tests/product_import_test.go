@@ -0,0 +1,270 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/csv"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ============================================
+// Product Import / Export Tests
+// ============================================
+
+// importProducts posts a raw CSV body to the product import endpoint as admin
+func importProducts(t *testing.T, env *TestEnv, body string, dryRun bool) *httptest.ResponseRecorder {
+	t.Helper()
+
+	path := "/api/v1/products/import"
+	if dryRun {
+		path += "?dry_run=true"
+	}
+	return importProductsAs(env, env.LoginAsAdmin(t), path, body)
+}
+
+// importProductsAs posts a raw CSV body to an import path as the signed-in user
+func importProductsAs(env *TestEnv, cookies []*http.Cookie, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	w := httptest.NewRecorder()
+	env.Engine.ServeHTTP(w, req)
+	return w
+}
+
+func productCount(t *testing.T, env *TestEnv) int {
+	t.Helper()
+
+	var count int
+	if err := env.DB.QueryRow(`SELECT COUNT(*) FROM products WHERE deleted_at IS NULL`).Scan(&count); err != nil {
+		t.Fatalf("Failed to count products: %v", err)
+	}
+	return count
+}
+
+func TestProductImport_CreatesAndUpdates(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	// TEST-001 already exists and is updated; IMP-001 is new
+	body := "category_slug,sku,name,price,stock,is_active\n" +
+		"test-category,TEST-001,Renamed Product,12500,40,true\n" +
+		"test-category,IMP-001,Imported Product,8000,,false\n"
+
+	w := importProducts(t, env, body, false)
+	AssertStatus(t, w, http.StatusOK)
+
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	if data["created"].(float64) != 1 || data["updated"].(float64) != 1 {
+		t.Errorf("Expected 1 created and 1 updated, got %v", data)
+	}
+
+	var name string
+	var price float64
+	var stock int
+	if err := env.DB.QueryRow(`SELECT name, price, stock FROM products WHERE sku = 'TEST-001'`).Scan(&name, &price, &stock); err != nil {
+		t.Fatalf("Failed to read product: %v", err)
+	}
+	if name != "Renamed Product" || price != 12500 || stock != 40 {
+		t.Errorf("Expected updated product, got %s %v %d", name, price, stock)
+	}
+
+	var isActive bool
+	if err := env.DB.QueryRow(`SELECT stock, is_active FROM products WHERE sku = 'IMP-001'`).Scan(&stock, &isActive); err != nil {
+		t.Fatalf("Failed to read imported product: %v", err)
+	}
+	if stock != 0 || isActive {
+		t.Errorf("Expected inactive product with no stock, got stock %d active %v", stock, isActive)
+	}
+}
+
+func TestProductImport_DryRunWritesNothing(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	before := productCount(t, env)
+	body := "category_slug,sku,name,price\ntest-category,IMP-DRY,Dry Run Product,5000\n"
+
+	w := importProducts(t, env, body, true)
+	AssertStatus(t, w, http.StatusOK)
+
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	if data["dry_run"] != true || data["created"].(float64) != 1 {
+		t.Errorf("Expected dry run reporting 1 create, got %v", data)
+	}
+	if after := productCount(t, env); after != before {
+		t.Errorf("Expected %d products after dry run, got %d", before, after)
+	}
+}
+
+func TestProductImport_DryRunReportsRowErrors(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	body := "category_slug,sku,name,price\n" +
+		"test-category,IMP-OK,Good Product,5000\n" +
+		"missing-category,IMP-BAD,Bad Category,5000\n" +
+		"test-category,IMP-NAN,Bad Price,abc\n" +
+		"test-category,IMP-OK,Duplicate,5000\n"
+
+	w := importProducts(t, env, body, true)
+	AssertStatus(t, w, http.StatusOK)
+
+	errs := ParseResponse(t, w)["data"].(map[string]interface{})["errors"].([]interface{})
+	rows := map[float64]string{}
+	for _, e := range errs {
+		rowErr := e.(map[string]interface{})
+		rows[rowErr["row"].(float64)] = rowErr["field"].(string)
+	}
+	if rows[3] != "category_slug" || rows[4] != "price" || rows[5] != "sku" || len(rows) != 3 {
+		t.Errorf("Expected errors on rows 3, 4 and 5, got %v", errs)
+	}
+}
+
+func TestProductImport_InvalidRowRollsBackAll(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	before := productCount(t, env)
+	body := "category_slug,sku,name,price\n" +
+		"test-category,IMP-A,Valid Product,5000\n" +
+		"test-category,IMP-B,X,5000\n"
+
+	w := importProducts(t, env, body, false)
+	AssertStatus(t, w, http.StatusUnprocessableEntity)
+
+	if after := productCount(t, env); after != before {
+		t.Errorf("Expected no products saved, got %d (was %d)", after, before)
+	}
+}
+
+func TestProductImport_MultipartUpload(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	part, _ := form.CreateFormFile("file", "products.csv")
+	part.Write([]byte("category_slug,sku,name,price\ntest-category,IMP-FILE,Uploaded Product,7000\n"))
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products/import", &buf)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	for _, cookie := range env.LoginAsManager(t) {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	env.Engine.ServeHTTP(w, req)
+
+	AssertStatus(t, w, http.StatusOK)
+}
+
+func TestProductExport_CSV(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/products/export", nil, env.LoginAsManager(t))
+	AssertStatus(t, w, http.StatusOK)
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse export: %v", err)
+	}
+	if len(records) < 2 || records[0][0] != "category_slug" || records[0][1] != "sku" {
+		t.Fatalf("Expected header and product rows, got %v", records)
+	}
+	found := false
+	for _, record := range records[1:] {
+		if record[1] == "TEST-001" && record[0] == "test-category" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected TEST-001 in export, got %v", records)
+	}
+}
+
+func TestProductExport_AsCashier_Forbidden(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/products/export", nil, env.LoginAsCashier(t))
+
+	AssertStatus(t, w, http.StatusForbidden)
+}
+
+func TestProductImport_PriceChangeNeedsPermission(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	// A role that manages products but not prices
+	w := env.MakeRequest(t, http.MethodPut, "/api/v1/roles/manager", map[string]interface{}{
+		"permissions": []string{"products.manage"},
+	}, env.LoginAsAdmin(t))
+	AssertStatus(t, w, http.StatusOK)
+	manager := env.LoginAsManager(t)
+
+	// Keeping the stored price is fine; changing it is not
+	w = importProductsAs(env, manager, "/api/v1/products/import", "category_slug,sku,name,price\n"+
+		"test-category,TEST-001,Renamed Product,10000\n")
+	AssertStatus(t, w, http.StatusOK)
+
+	w = importProductsAs(env, manager, "/api/v1/products/import", "category_slug,sku,name,price\n"+
+		"test-category,TEST-001,Renamed Product,1\n")
+	AssertStatus(t, w, http.StatusUnprocessableEntity)
+
+	var price float64
+	if err := env.DB.QueryRow(`SELECT price FROM products WHERE sku = 'TEST-001'`).Scan(&price); err != nil {
+		t.Fatalf("Failed to read product: %v", err)
+	}
+	if price != 10000 {
+		t.Errorf("Expected the price to stay 10000, got %v", price)
+	}
+}
+
+func TestProductImport_StockChangeIsAnAdjustment(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	// Seed stock is 100; the difference is posted as an adjustment
+	w := importProductsAs(env, env.LoginAsAdmin(t), "/api/v1/products/import", "category_slug,sku,name,price,stock\n"+
+		"test-category,TEST-001,Test Product,10000,40\n")
+	AssertStatus(t, w, http.StatusOK)
+
+	var quantity, balance float64
+	err := env.DB.QueryRow(`
+		SELECT m.quantity, m.balance_after FROM stock_movements m
+		JOIN products p ON p.id = m.product_id
+		WHERE p.sku = 'TEST-001' AND m.type = 'adjustment'
+	`).Scan(&quantity, &balance)
+	if err != nil {
+		t.Fatalf("Failed to read the adjustment: %v", err)
+	}
+	if quantity != -60 || balance != 40 {
+		t.Errorf("Expected an adjustment of -60 leaving 40, got %v leaving %v", quantity, balance)
+	}
+
+	// A role that manages products but not inventory cannot change stock
+	w = env.MakeRequest(t, http.MethodPut, "/api/v1/roles/manager", map[string]interface{}{
+		"permissions": []string{"products.manage", "products.price.update"},
+	}, env.LoginAsAdmin(t))
+	AssertStatus(t, w, http.StatusOK)
+
+	w = importProductsAs(env, env.LoginAsManager(t), "/api/v1/products/import", "category_slug,sku,name,price,stock\n"+
+		"test-category,TEST-001,Test Product,10000,90\n")
+	AssertStatus(t, w, http.StatusUnprocessableEntity)
+
+	var stock int
+	if err := env.DB.QueryRow(`SELECT stock FROM products WHERE sku = 'TEST-001'`).Scan(&stock); err != nil {
+		t.Fatalf("Failed to read product: %v", err)
+	}
+	if stock != 40 {
+		t.Errorf("Expected stock to stay 40, got %d", stock)
+	}
+}
@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+// ============================================
+// Product Variant & Modifier Tests
+// ============================================
+
+// seedProductOptions adds two size variants and a sugar level group (pick exactly one) plus an
+// optional extra shot group to a product, returning the created IDs by name
+func seedProductOptions(t *testing.T, env *TestEnv, productID string) map[string]string {
+	t.Helper()
+
+	cookies := env.LoginAsAdmin(t)
+	ids := make(map[string]string)
+
+	for _, v := range []map[string]interface{}{
+		{"sku": "KSGA-REG-" + productID[:8], "name": "Regular", "price": 18000, "stock": 10},
+		{"sku": "KSGA-LRG-" + productID[:8], "name": "Large", "price": 22000, "stock": 1},
+	} {
+		w := env.MakeRequest(t, http.MethodPost, "/api/v1/products/"+productID+"/variants", v, cookies)
+		AssertStatus(t, w, http.StatusCreated)
+		ids[v["name"].(string)] = ParseResponse(t, w)["data"].(map[string]interface{})["id"].(string)
+	}
+
+	for _, g := range []map[string]interface{}{
+		{"name": "Sugar Level", "min_select": 1, "max_select": 1, "modifiers": []map[string]interface{}{
+			{"name": "Normal Sugar", "price_delta": 0},
+			{"name": "Less Sugar", "price_delta": 0},
+		}},
+		{"name": "Add-ons", "min_select": 0, "max_select": 2, "modifiers": []map[string]interface{}{
+			{"name": "Extra Shot", "price_delta": 5000},
+		}},
+	} {
+		w := env.MakeRequest(t, http.MethodPost, "/api/v1/products/"+productID+"/modifier-groups", g, cookies)
+		AssertStatus(t, w, http.StatusCreated)
+		for _, m := range ParseResponse(t, w)["data"].(map[string]interface{})["modifiers"].([]interface{}) {
+			modifier := m.(map[string]interface{})
+			ids[modifier["name"].(string)] = modifier["id"].(string)
+		}
+	}
+
+	return ids
+}
+
+func TestProductOptions_ReturnedWithProduct(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	seedProductOptions(t, env, productID)
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/pos/products", nil, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusOK)
+
+	for _, p := range ParseResponse(t, w)["data"].([]interface{}) {
+		product := p.(map[string]interface{})
+		if product["id"] != productID {
+			continue
+		}
+		if len(product["variants"].([]interface{})) != 2 {
+			t.Errorf("Expected 2 variants, got %v", product["variants"])
+		}
+		if len(product["modifier_groups"].([]interface{})) != 2 {
+			t.Errorf("Expected 2 modifier groups, got %v", product["modifier_groups"])
+		}
+		return
+	}
+	t.Errorf("Product %s missing from POS products", productID)
+}
+
+func TestCheckout_WithVariantAndModifiers(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	ids := seedProductOptions(t, env, productID)
+
+	body := map[string]interface{}{
+		"payment_method": "cash",
+		"items": []map[string]interface{}{
+			{
+				"product_id":   productID,
+				"variant_id":   ids["Regular"],
+				"modifier_ids": []string{ids["Less Sugar"], ids["Extra Shot"]},
+				"quantity":     2,
+			},
+		},
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusCreated)
+
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	item := data["items"].([]interface{})[0].(map[string]interface{})
+	if item["unit_price"].(float64) != 23000 {
+		t.Errorf("Expected unit price 23000 (18000 + 5000), got %v", item["unit_price"])
+	}
+	if item["variant_name"] != "Regular" || len(item["modifiers"].([]interface{})) != 2 {
+		t.Errorf("Expected the variant and both modifiers on the item, got %v", item)
+	}
+
+	var stock int
+	if err := env.DB.QueryRow(`SELECT stock FROM product_variants WHERE id = $1`, ids["Regular"]).Scan(&stock); err != nil {
+		t.Fatalf("Failed to read variant stock: %v", err)
+	}
+	if stock != 8 {
+		t.Errorf("Expected variant stock 8, got %d", stock)
+	}
+}
+
+func TestCheckout_VariantRequired(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	ids := seedProductOptions(t, env, productID)
+
+	body := map[string]interface{}{
+		"payment_method": "cash",
+		"items": []map[string]interface{}{
+			{"product_id": productID, "modifier_ids": []string{ids["Normal Sugar"]}, "quantity": 1},
+		},
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, env.LoginAsCashier(t))
+
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestCheckout_ModifierGroupMinimum(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	ids := seedProductOptions(t, env, productID)
+
+	// Sugar level requires exactly one choice
+	body := map[string]interface{}{
+		"payment_method": "cash",
+		"items": []map[string]interface{}{
+			{"product_id": productID, "variant_id": ids["Regular"], "quantity": 1},
+		},
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, env.LoginAsCashier(t))
+
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestCheckout_VariantOutOfStock(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	ids := seedProductOptions(t, env, productID)
+
+	body := map[string]interface{}{
+		"payment_method": "cash",
+		"items": []map[string]interface{}{
+			{"product_id": productID, "variant_id": ids["Large"], "modifier_ids": []string{ids["Normal Sugar"]}, "quantity": 2},
+		},
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, env.LoginAsCashier(t))
+
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestVariantCreate_AsCashier_Forbidden(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	body := map[string]interface{}{"sku": "VAR-CASHIER", "name": "Regular", "price": 18000}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/products/"+productID+"/variants", body, env.LoginAsCashier(t))
+
+	AssertStatus(t, w, http.StatusForbidden)
+}
+
+func TestVariantCreate_DuplicateSKU(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+
+	// TEST-001 belongs to the seeded product
+	body := map[string]interface{}{"sku": "TEST-001", "name": "Regular", "price": 18000}
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/products/"+productID+"/variants", body, env.LoginAsAdmin(t))
+
+	AssertStatus(t, w, http.StatusBadRequest)
+}
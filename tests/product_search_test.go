@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// ============================================
+// Product Search Tests
+// ============================================
+
+// seedSearchProduct inserts an active product with the given name and description
+func seedSearchProduct(t *testing.T, env *TestEnv, categoryID, sku, name, description string) string {
+	t.Helper()
+
+	id := GenerateUUID()
+	now := time.Now()
+	_, err := env.DB.Exec(`
+		INSERT INTO products (id, category_id, sku, name, description, price, stock, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, 15000, 10, TRUE, $6, $7)
+	`, id, categoryID, sku, name, description, now, now)
+	if err != nil {
+		t.Fatalf("Failed to seed product: %v", err)
+	}
+	return id
+}
+
+// searchProductNames lists products matching a search term and returns their names in order
+func searchProductNames(t *testing.T, env *TestEnv, search string) []string {
+	t.Helper()
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/products?search="+url.QueryEscape(search), nil, env.LoginAsAdmin(t))
+	AssertStatus(t, w, http.StatusOK)
+
+	var names []string
+	data, _ := ParseResponse(t, w)["data"].([]interface{})
+	for _, item := range data {
+		names = append(names, item.(map[string]interface{})["name"].(string))
+	}
+	return names
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestProductSearch_MatchesDescription(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	seedSearchProduct(t, env, TestCategoryID, "SRC-001", "Es Kopi Aren", "Kopi susu dengan gula aren asli")
+
+	names := searchProductNames(t, env, "gula aren")
+	if !containsName(names, "Es Kopi Aren") {
+		t.Errorf("Expected description match, got %v", names)
+	}
+}
+
+func TestProductSearch_Stemming(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	seedSearchProduct(t, env, TestCategoryID, "SRC-002", "Butter Cookie", "")
+
+	// "cookies" and "cookie" share an English stem
+	names := searchProductNames(t, env, "cookies")
+	if !containsName(names, "Butter Cookie") {
+		t.Errorf("Expected stemmed match, got %v", names)
+	}
+}
+
+func TestProductSearch_ToleratesTypos(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	seedSearchProduct(t, env, TestCategoryID, "SRC-003", "Cappuccino", "")
+
+	names := searchProductNames(t, env, "capucino")
+	if !containsName(names, "Cappuccino") {
+		t.Errorf("Expected fuzzy match, got %v", names)
+	}
+	if containsName(names, "Test Product") {
+		t.Errorf("Expected unrelated products to be excluded, got %v", names)
+	}
+}
+
+func TestProductSearch_MatchesCategoryName(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	categoryID := GenerateUUID()
+	now := time.Now()
+	if _, err := env.DB.Exec(`
+		INSERT INTO categories (id, name, description, slug, is_active, created_at, updated_at)
+		VALUES ($1, 'Minuman', '', 'minuman', TRUE, $2, $3)
+	`, categoryID, now, now); err != nil {
+		t.Fatalf("Failed to seed category: %v", err)
+	}
+	seedSearchProduct(t, env, categoryID, "SRC-004", "Es Teh Manis", "")
+
+	names := searchProductNames(t, env, "minuman")
+	if !containsName(names, "Es Teh Manis") {
+		t.Errorf("Expected category name match, got %v", names)
+	}
+}
+
+func TestProductSearch_RanksNameAboveDescription(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	seedSearchProduct(t, env, TestCategoryID, "SRC-005", "Banana Cake", "Best enjoyed with a latte")
+	seedSearchProduct(t, env, TestCategoryID, "SRC-006", "Latte", "Espresso with steamed milk")
+
+	names := searchProductNames(t, env, "latte")
+	if len(names) < 2 || names[0] != "Latte" {
+		t.Errorf("Expected name match first, got %v", names)
+	}
+}
+
+func TestProductSearch_Suggest(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsCashier(t)
+	seedSearchProduct(t, env, TestCategoryID, "SRC-007", "Matcha Latte", "")
+	seedSearchProduct(t, env, TestCategoryID, "SRC-008", "Matcha Cake", "")
+	inactiveID := seedSearchProduct(t, env, TestCategoryID, "SRC-009", "Matcha Cookie", "")
+	if _, err := env.DB.Exec(`UPDATE products SET is_active = FALSE WHERE id = $1`, inactiveID); err != nil {
+		t.Fatalf("Failed to deactivate product: %v", err)
+	}
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/products/search?q=matcha&limit=5", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	data := ParseResponse(t, w)["data"].([]interface{})
+	if len(data) != 2 {
+		t.Fatalf("Expected 2 active suggestions, got %d", len(data))
+	}
+	first := data[0].(map[string]interface{})
+	if first["category_name"] != "Test Category" || first["available"] != true {
+		t.Errorf("Unexpected suggestion %v", first)
+	}
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/products/search?q=matcha&limit=1", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	if data := ParseResponse(t, w)["data"].([]interface{}); len(data) != 1 {
+		t.Errorf("Expected limit to apply, got %d", len(data))
+	}
+}
+
+func TestProductSearch_SuggestRequiresQuery(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/products/search", nil, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusBadRequest)
+}
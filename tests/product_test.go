@@ -186,14 +186,47 @@ func TestProductUpdateStock_AsCashier(t *testing.T) {
 	cookies := env.LoginAsCashier(t)
 
 	body := map[string]interface{}{
-		"operation": "set",
-		"quantity":  90,
+		"operation": "add",
+		"quantity":  5,
 	}
 
 	w := env.MakeRequest(t, http.MethodPatch, "/api/v1/products/"+TestProductID+"/stock", body, cookies)
 
-	// All authenticated users should be able to update stock
+	// Stock changes need the inventory permission, which cashiers lack
+	AssertStatus(t, w, http.StatusForbidden)
+}
+
+func TestProductUpdateStock_RecordsAdjustment(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsManager(t)
+
+	body := map[string]interface{}{
+		"operation": "subtract",
+		"quantity":  5,
+	}
+
+	w := env.MakeRequest(t, http.MethodPatch, "/api/v1/products/"+TestProductID+"/stock", body, cookies)
 	AssertStatus(t, w, http.StatusOK)
+	if stock := ParseResponse(t, w)["data"].(map[string]interface{})["stock"]; stock != float64(95) {
+		t.Errorf("Expected stock 95, got %v", stock)
+	}
+
+	var movementType string
+	var quantity, balance float64
+	if err := env.DB.QueryRow(`
+		SELECT type, quantity, balance_after FROM stock_movements WHERE product_id = $1
+	`, TestProductID).Scan(&movementType, &quantity, &balance); err != nil {
+		t.Fatalf("Failed to read stock movement: %v", err)
+	}
+	if movementType != "adjustment" || quantity != -5 || balance != 95 {
+		t.Errorf("Expected an adjustment of -5 leaving 95, got %s %v leaving %v", movementType, quantity, balance)
+	}
+
+	body["quantity"] = 500
+	w = env.MakeRequest(t, http.MethodPatch, "/api/v1/products/"+TestProductID+"/stock", body, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
 }
 
 func TestProductDelete_AsAdmin(t *testing.T) {
@@ -0,0 +1,308 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// ============================================
+// Purchase Order Tests
+// ============================================
+
+// createSupplier creates a supplier through the API and returns its ID
+func createSupplier(t *testing.T, env *TestEnv, cookies []*http.Cookie, name string) string {
+	t.Helper()
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/suppliers", map[string]interface{}{
+		"name":         name,
+		"contact_name": "Budi",
+		"phone":        "081234567890",
+	}, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+	return ParseResponse(t, w)["data"].(map[string]interface{})["id"].(string)
+}
+
+// seedStockedProduct inserts a product with the given stock and cost price
+func seedStockedProduct(t *testing.T, env *TestEnv, sku string, stock int, costPrice float64) string {
+	t.Helper()
+
+	id := GenerateUUID()
+	now := time.Now()
+	_, err := env.DB.Exec(`
+		INSERT INTO products (id, category_id, sku, name, description, price, cost_price, stock, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, '', 25000, $5, $6, TRUE, $7, $8)
+	`, id, TestCategoryID, sku, "Product "+sku, costPrice, stock, now, now)
+	if err != nil {
+		t.Fatalf("Failed to seed product: %v", err)
+	}
+	return id
+}
+
+// createOrderedPurchaseOrder creates a purchase order with one line per product and places it
+func createOrderedPurchaseOrder(t *testing.T, env *TestEnv, cookies []*http.Cookie, supplierID string, lines []map[string]interface{}) map[string]interface{} {
+	t.Helper()
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/purchase-orders", map[string]interface{}{
+		"supplier_id": supplierID,
+		"lines":       lines,
+	}, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+	order := ParseResponse(t, w)["data"].(map[string]interface{})
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/purchase-orders/"+order["id"].(string)+"/order", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	return ParseResponse(t, w)["data"].(map[string]interface{})
+}
+
+// lineID returns the ID of the order's line for a product
+func lineID(t *testing.T, order map[string]interface{}, productID string) string {
+	t.Helper()
+
+	for _, item := range order["lines"].([]interface{}) {
+		line := item.(map[string]interface{})
+		if line["product_id"] == productID {
+			return line["id"].(string)
+		}
+	}
+	t.Fatalf("No line for product %s", productID)
+	return ""
+}
+
+func productStockAndCost(t *testing.T, env *TestEnv, productID string) (int, float64) {
+	t.Helper()
+
+	var stock int
+	var cost float64
+	if err := env.DB.QueryRow(`SELECT stock, cost_price FROM products WHERE id = $1`, productID).Scan(&stock, &cost); err != nil {
+		t.Fatalf("Failed to read product: %v", err)
+	}
+	return stock, cost
+}
+
+func TestPurchaseOrder_Lifecycle(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsManager(t)
+	supplierID := createSupplier(t, env, cookies, "PT Kopi Nusantara")
+	productID := seedStockedProduct(t, env, "PO-001", 0, 0)
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/purchase-orders", map[string]interface{}{
+		"supplier_id": supplierID,
+		"lines":       []map[string]interface{}{{"product_id": productID, "quantity": 10, "unit_cost": 5000}},
+	}, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+	order := ParseResponse(t, w)["data"].(map[string]interface{})
+	orderID := order["id"].(string)
+	if order["status"] != "draft" || order["expected_cost"] != float64(50000) || order["supplier_name"] != "PT Kopi Nusantara" {
+		t.Fatalf("Unexpected draft %v", order)
+	}
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/purchase-orders/"+orderID+"/order", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	if status := ParseResponse(t, w)["data"].(map[string]interface{})["status"]; status != "ordered" {
+		t.Fatalf("Expected ordered, got %v", status)
+	}
+
+	// Ordered purchase orders can no longer be edited
+	w = env.MakeRequest(t, http.MethodPut, "/api/v1/purchase-orders/"+orderID, map[string]interface{}{"notes": "late"}, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	line := lineID(t, order, productID)
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/purchase-orders/"+orderID+"/receive", map[string]interface{}{
+		"lines": []map[string]interface{}{{"line_id": line, "quantity": 4}},
+	}, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	if status := ParseResponse(t, w)["data"].(map[string]interface{})["status"]; status != "partially_received" {
+		t.Errorf("Expected partially_received, got %v", status)
+	}
+	if stock, _ := productStockAndCost(t, env, productID); stock != 4 {
+		t.Errorf("Expected stock 4, got %d", stock)
+	}
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/purchase-orders/"+orderID+"/receive", map[string]interface{}{
+		"lines": []map[string]interface{}{{"line_id": line, "quantity": 6}},
+	}, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	order = ParseResponse(t, w)["data"].(map[string]interface{})
+	if order["status"] != "received" || order["received_at"] == nil {
+		t.Errorf("Expected a received order, got %v", order)
+	}
+	if stock, _ := productStockAndCost(t, env, productID); stock != 10 {
+		t.Errorf("Expected stock 10, got %d", stock)
+	}
+
+	// Every receipt is a restock movement referencing the order
+	var movements, balance int
+	if err := env.DB.QueryRow(`
+		SELECT COUNT(*), MAX(balance_after) FROM stock_movements
+		WHERE product_id = $1 AND type = 'restock' AND reference_id = $2
+	`, productID, orderID).Scan(&movements, &balance); err != nil {
+		t.Fatalf("Failed to read stock movements: %v", err)
+	}
+	if movements != 2 || balance != 10 {
+		t.Errorf("Expected 2 movements ending at 10, got %d ending at %d", movements, balance)
+	}
+}
+
+func TestPurchaseOrder_MovingAverageCost(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsManager(t)
+	supplierID := createSupplier(t, env, cookies, "CV Susu Segar")
+	productID := seedStockedProduct(t, env, "PO-002", 10, 1000)
+
+	order := createOrderedPurchaseOrder(t, env, cookies, supplierID, []map[string]interface{}{
+		{"product_id": productID, "quantity": 30, "unit_cost": 1500},
+	})
+
+	// The actual cost overrides the expected cost: (10 x 1000 + 30 x 2000) / 40 = 1750
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/purchase-orders/"+order["id"].(string)+"/receive", map[string]interface{}{
+		"lines": []map[string]interface{}{{"line_id": lineID(t, order, productID), "quantity": 30, "unit_cost": 2000}},
+	}, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	stock, cost := productStockAndCost(t, env, productID)
+	if stock != 40 || cost != 1750 {
+		t.Errorf("Expected 40 at 1750, got %d at %v", stock, cost)
+	}
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/products/"+productID, nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	if got := ParseResponse(t, w)["data"].(map[string]interface{})["cost_price"]; got != float64(1750) {
+		t.Errorf("Expected cost_price 1750 in the product response, got %v", got)
+	}
+}
+
+func TestPurchaseOrder_RejectsOverReceipt(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsManager(t)
+	supplierID := createSupplier(t, env, cookies, "UD Gula Aren")
+	productID := seedStockedProduct(t, env, "PO-003", 5, 800)
+
+	order := createOrderedPurchaseOrder(t, env, cookies, supplierID, []map[string]interface{}{
+		{"product_id": productID, "quantity": 3, "unit_cost": 800},
+	})
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/purchase-orders/"+order["id"].(string)+"/receive", map[string]interface{}{
+		"lines": []map[string]interface{}{{"line_id": lineID(t, order, productID), "quantity": 4}},
+	}, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	if stock, _ := productStockAndCost(t, env, productID); stock != 5 {
+		t.Errorf("Expected stock to stay at 5, got %d", stock)
+	}
+}
+
+func TestPurchaseOrder_OnlyOrderedCanBeReceived(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsManager(t)
+	supplierID := createSupplier(t, env, cookies, "PT Roti Enak")
+	productID := seedStockedProduct(t, env, "PO-004", 0, 0)
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/purchase-orders", map[string]interface{}{
+		"supplier_id": supplierID,
+		"lines":       []map[string]interface{}{{"product_id": productID, "quantity": 2, "unit_cost": 100}},
+	}, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+	draft := ParseResponse(t, w)["data"].(map[string]interface{})
+	receipt := map[string]interface{}{
+		"lines": []map[string]interface{}{{"line_id": lineID(t, draft, productID), "quantity": 1}},
+	}
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/purchase-orders/"+draft["id"].(string)+"/receive", receipt, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/purchase-orders/"+draft["id"].(string)+"/cancel", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/purchase-orders/"+draft["id"].(string)+"/receive", receipt, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	if stock, _ := productStockAndCost(t, env, productID); stock != 0 {
+		t.Errorf("Expected no stock received, got %d", stock)
+	}
+}
+
+func TestPurchaseOrder_OutstandingReport(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsManager(t)
+	bigSupplier := createSupplier(t, env, cookies, "Big Supplier")
+	smallSupplier := createSupplier(t, env, cookies, "Small Supplier")
+	productA := seedStockedProduct(t, env, "PO-005", 0, 0)
+	productB := seedStockedProduct(t, env, "PO-006", 0, 0)
+
+	order := createOrderedPurchaseOrder(t, env, cookies, bigSupplier, []map[string]interface{}{
+		{"product_id": productA, "quantity": 10, "unit_cost": 1000},
+		{"product_id": productB, "quantity": 5, "unit_cost": 2000},
+	})
+	createOrderedPurchaseOrder(t, env, cookies, smallSupplier, []map[string]interface{}{
+		{"product_id": productA, "quantity": 1, "unit_cost": 1000},
+	})
+
+	// Received goods and drafts are not outstanding
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/purchase-orders/"+order["id"].(string)+"/receive", map[string]interface{}{
+		"lines": []map[string]interface{}{{"line_id": lineID(t, order, productA), "quantity": 10}},
+	}, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/purchase-orders", map[string]interface{}{
+		"supplier_id": smallSupplier,
+		"lines":       []map[string]interface{}{{"product_id": productB, "quantity": 99, "unit_cost": 1000}},
+	}, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/reports/purchase-orders/outstanding", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	reports := ParseResponse(t, w)["data"].([]interface{})
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 suppliers, got %d", len(reports))
+	}
+	first := reports[0].(map[string]interface{})
+	if first["supplier_name"] != "Big Supplier" || first["open_orders"] != float64(1) ||
+		first["outstanding_quantity"] != float64(5) || first["outstanding_value"] != float64(10000) {
+		t.Errorf("Unexpected report row %v", first)
+	}
+	second := reports[1].(map[string]interface{})
+	if second["supplier_name"] != "Small Supplier" || second["outstanding_quantity"] != float64(1) {
+		t.Errorf("Unexpected report row %v", second)
+	}
+}
+
+func TestPurchaseOrder_SupplierWithOrdersCannotBeDeleted(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+	supplierID := createSupplier(t, env, cookies, "Keep Me")
+	productID := seedStockedProduct(t, env, "PO-007", 0, 0)
+	createOrderedPurchaseOrder(t, env, cookies, supplierID, []map[string]interface{}{
+		{"product_id": productID, "quantity": 1, "unit_cost": 100},
+	})
+
+	w := env.MakeRequest(t, http.MethodDelete, "/api/v1/suppliers/"+supplierID, nil, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	unused := createSupplier(t, env, cookies, "Delete Me")
+	w = env.MakeRequest(t, http.MethodDelete, "/api/v1/suppliers/"+unused, nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+}
+
+func TestPurchaseOrder_CashierForbidden(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsCashier(t)
+	for _, path := range []string{"/api/v1/suppliers", "/api/v1/purchase-orders", "/api/v1/reports/purchase-orders/outstanding"} {
+		w := env.MakeRequest(t, http.MethodGet, path, nil, cookies)
+		AssertStatus(t, w, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+// ============================================
+// Role Tests
+// ============================================
+
+func TestRole_CustomRoleGrantsItsPermissions(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	admin := env.LoginAsAdmin(t)
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/roles", map[string]interface{}{
+		"name":        "Shift_Lead",
+		"description": "Runs the floor",
+		"permissions": []string{"vouchers.manage", "transactions.refund"},
+	}, admin)
+	AssertStatus(t, w, http.StatusCreated)
+	if name := ParseResponse(t, w)["data"].(map[string]interface{})["name"]; name != "shift_lead" {
+		t.Errorf("Expected the role name to be normalized, got %v", name)
+	}
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/users", map[string]interface{}{
+		"email": "lead@test.local", "password": "Lead1234!", "name": "Shift Lead", "role": "shift_lead",
+	}, admin)
+	AssertStatus(t, w, http.StatusCreated)
+
+	lead := env.LoginAs(t, "lead@test.local", "Lead1234!")
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/vouchers", nil, lead)
+	AssertStatus(t, w, http.StatusOK)
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/suppliers", nil, lead)
+	AssertStatus(t, w, http.StatusForbidden)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/auth/me", nil, lead)
+	AssertStatus(t, w, http.StatusOK)
+	permissions := ParseResponse(t, w)["data"].(map[string]interface{})["permissions"].([]interface{})
+	if len(permissions) != 2 {
+		t.Errorf("Expected 2 permissions, got %v", permissions)
+	}
+
+	// A role cannot be deleted while users hold it
+	w = env.MakeRequest(t, http.MethodDelete, "/api/v1/roles/shift_lead", nil, admin)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	// Users can only be given roles that exist
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/users", map[string]interface{}{
+		"email": "ghost@test.local", "password": "Ghost123!", "name": "Ghost", "role": "ghost",
+	}, admin)
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestRole_ChangingBuiltInRoleTakesEffectImmediately(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	admin := env.LoginAsAdmin(t)
+	cashier := env.LoginAsCashier(t)
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/vouchers", nil, cashier)
+	AssertStatus(t, w, http.StatusForbidden)
+
+	w = env.MakeRequest(t, http.MethodPut, "/api/v1/roles/cashier", map[string]interface{}{
+		"permissions": []string{"vouchers.manage"},
+	}, admin)
+	AssertStatus(t, w, http.StatusOK)
+	if customized := ParseResponse(t, w)["data"].(map[string]interface{})["customized"]; customized != true {
+		t.Errorf("Expected the cashier role to be customized, got %v", customized)
+	}
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/vouchers", nil, cashier)
+	AssertStatus(t, w, http.StatusOK)
+
+	// Deleting the change restores the defaults
+	w = env.MakeRequest(t, http.MethodDelete, "/api/v1/roles/cashier", nil, admin)
+	AssertStatus(t, w, http.StatusOK)
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/vouchers", nil, cashier)
+	AssertStatus(t, w, http.StatusForbidden)
+}
+
+func TestRole_InvalidChangesAreRejected(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	admin := env.LoginAsAdmin(t)
+
+	w := env.MakeRequest(t, http.MethodPut, "/api/v1/roles/admin", map[string]interface{}{
+		"permissions": []string{},
+	}, admin)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/roles", map[string]interface{}{
+		"name": "auditor", "permissions": []string{"everything.do"},
+	}, admin)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/roles", map[string]interface{}{
+		"name": "manager", "permissions": []string{},
+	}, admin)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodDelete, "/api/v1/roles/manager", nil, admin)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	// Only roles with roles.manage may edit roles
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/roles", nil, env.LoginAsManager(t))
+	AssertStatus(t, w, http.StatusForbidden)
+}
+
+func TestRole_PriceChangesNeedPermission(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	admin := env.LoginAsAdmin(t)
+	w := env.MakeRequest(t, http.MethodPut, "/api/v1/roles/manager", map[string]interface{}{
+		"permissions": []string{"products.manage"},
+	}, admin)
+	AssertStatus(t, w, http.StatusOK)
+
+	manager := env.LoginAsManager(t)
+	w = env.MakeRequest(t, http.MethodPut, "/api/v1/products/"+TestProductID, map[string]interface{}{
+		"price": 12000,
+	}, manager)
+	AssertStatus(t, w, http.StatusForbidden)
+
+	// Other fields can be edited when the price is resent unchanged
+	w = env.MakeRequest(t, http.MethodPut, "/api/v1/products/"+TestProductID, map[string]interface{}{
+		"name": "Renamed Product", "price": 10000,
+	}, manager)
+	AssertStatus(t, w, http.StatusOK)
+}
@@ -2,7 +2,9 @@ package tests
 
 import (
 	"bytes"
+	"crypto/ed25519"
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -19,6 +21,7 @@ import (
 	"github.com/ilramdhan/pos-api/internal/models"
 	"github.com/ilramdhan/pos-api/internal/repository"
 	"github.com/ilramdhan/pos-api/internal/service"
+	"github.com/ilramdhan/pos-api/internal/storage"
 	"github.com/ilramdhan/pos-api/internal/utils"
 	"golang.org/x/crypto/bcrypt"
 
@@ -34,12 +37,23 @@ type TestEnv struct {
 	Cookies []*http.Cookie
 
 	// Services
-	AuthService        *service.AuthService
-	UserService        *service.UserService
-	CategoryService    *service.CategoryService
-	ProductService     *service.ProductService
-	CustomerService    *service.CustomerService
-	TransactionService *service.TransactionService
+	AuthService          *service.AuthService
+	UserService          *service.UserService
+	CategoryService      *service.CategoryService
+	ProductService       *service.ProductService
+	CustomerService      *service.CustomerService
+	TransactionService   *service.TransactionService
+	VoucherService       *service.VoucherService
+	LoyaltyService       *service.LoyaltyService
+	TrashService         *service.TrashService
+	InventoryService     *service.InventoryService
+	MediaService         *service.MediaService
+	PurchaseService      *service.PurchaseService
+	StockTakeService     *service.StockTakeService
+	StoreService         *service.StoreService
+	StockTransferService *service.StockTransferService
+	LicenseService       *service.LicenseService
+	RoleService          *service.RoleService
 
 	// Cleanup function
 	Cleanup func()
@@ -90,6 +104,9 @@ func SetupTestEnv(t *testing.T) *TestEnv {
 	// Setup dependencies
 	gin.SetMode(gin.TestMode)
 	engine := gin.New()
+	if err := engine.SetTrustedProxies(cfg.App.TrustedProxies); err != nil {
+		t.Fatalf("Invalid trusted proxies: %v", err)
+	}
 
 	jwtManager := utils.NewJWTManager(
 		cfg.JWT.Secret,
@@ -101,32 +118,96 @@ func SetupTestEnv(t *testing.T) *TestEnv {
 	userRepo := repository.NewUserRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
 	productRepo := repository.NewProductRepository(db)
+	productOptionRepo := repository.NewProductOptionRepository(db)
 	customerRepo := repository.NewCustomerRepository(db)
 	transactionRepo := repository.NewTransactionRepository(db)
+	voucherRepo := repository.NewVoucherRepository(db)
+	loyaltyRepo := repository.NewLoyaltyRepository(db)
+	trashRepo := repository.NewTrashRepository(db)
+	ingredientRepo := repository.NewIngredientRepository(db)
+	supplierRepo := repository.NewSupplierRepository(db)
+	purchaseOrderRepo := repository.NewPurchaseOrderRepository(db)
+	stockTakeRepo := repository.NewStockTakeRepository(db)
+	storeRepo := repository.NewStoreRepository(db)
+	stockTransferRepo := repository.NewStockTransferRepository(db)
+	tenantRepo := repository.NewTenantRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	overrideRepo := repository.NewOverrideRepository(db)
+	terminalRepo := repository.NewTerminalRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	heldTransactionRepo := repository.NewHeldTransactionRepository(db)
+	mfaRecoveryCodeRepo := repository.NewMFARecoveryCodeRepository(db)
+	loginEventRepo := repository.NewLoginEventRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+
+	// Licensing is enforced with the test signing key; the default tenant holds an unlimited license
+	cfg.License.PublicKey = base64.StdEncoding.EncodeToString(testLicenseKey.Public().(ed25519.PublicKey))
 
 	// Services
-	authService := service.NewAuthService(userRepo, jwtManager)
-	userService := service.NewUserService(userRepo)
+	roleService := service.NewRoleService(roleRepo)
+	licenseService := service.NewLicenseService(tenantRepo, userRepo, storeRepo, cfg.License)
+	mfaService := service.NewMFAService(userRepo, mfaRecoveryCodeRepo, loginAttemptRepo, roleService, cfg.MFA)
+	notificationService := service.NewNotificationService(notificationRepo)
+	loginSecurityService := service.NewLoginSecurityService(loginAttemptRepo, loginEventRepo, userRepo, notificationService, cfg.Login, cfg.Terminal)
+	authService := service.NewAuthService(userRepo, userRepo, storeRepo, tenantRepo, roleService, licenseService, mfaService, loginSecurityService, jwtManager)
+	userService := service.NewUserService(userRepo, roleService, licenseService)
 	categoryService := service.NewCategoryService(categoryRepo)
-	productService := service.NewProductService(productRepo, categoryRepo)
+	productService := service.NewProductService(productRepo, categoryRepo, productOptionRepo)
 	customerService := service.NewCustomerService(customerRepo)
-	transactionService := service.NewTransactionService(transactionRepo, productRepo, customerRepo)
+	loyaltyService := service.NewLoyaltyService(loyaltyRepo, customerRepo, cfg.Loyalty)
+	inventoryService := service.NewInventoryService(ingredientRepo, productRepo)
+	overrideService := service.NewOverrideService(overrideRepo, userRepo, roleService, loginSecurityService)
+	transactionService := service.NewTransactionService(transactionRepo, productRepo, productOptionRepo, customerRepo, voucherRepo, loyaltyService, inventoryService, overrideService)
+	voucherService := service.NewVoucherService(voucherRepo)
+	trashService := service.NewTrashService(trashRepo, licenseService, cfg.Trash.RetentionDays)
+	purchaseService := service.NewPurchaseService(supplierRepo, purchaseOrderRepo, productRepo, storeRepo)
+	reportService := service.NewReportService(transactionRepo, purchaseOrderRepo, stockTransferRepo)
+	stockTakeService := service.NewStockTakeService(stockTakeRepo, categoryRepo, storeRepo)
+	storeService := service.NewStoreService(storeRepo, userRepo, productRepo, licenseService)
+	stockTransferService := service.NewStockTransferService(stockTransferRepo, storeRepo, productRepo)
+	terminalService := service.NewTerminalService(terminalRepo, terminalRepo, userRepo, storeRepo, loginAttemptRepo, mfaService, jwtManager, cfg.Terminal)
+	heldTransactionService := service.NewHeldTransactionService(heldTransactionRepo)
+
+	// Uploaded files go to a per-test directory with a 1 MB image limit
+	cfg.Storage.Driver = "local"
+	cfg.Storage.LocalDir = t.TempDir()
+	cfg.Storage.PublicURL = "/media"
+	cfg.Storage.MaxImageSize = 1 << 20
+	store, err := storage.New(&cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create blob store: %v", err)
+	}
+	mediaService := service.NewMediaService(productRepo, store, cfg.Storage)
+	systemService := service.NewSystemService(db, store, terminalService, cfg)
 
 	// Setup routes
 	setupTestRoutes(engine, cfg, jwtManager, authService, userService, categoryService,
-		productService, customerService, transactionService, db)
+		productService, customerService, transactionService, voucherService, loyaltyService, trashService, inventoryService, mediaService,
+		purchaseService, reportService, stockTakeService, storeService, stockTransferService, licenseService, roleService, overrideService,
+		terminalService, heldTransactionService, mfaService, loginSecurityService, notificationService, systemService, db)
 
 	return &TestEnv{
-		Config:             cfg,
-		DB:                 db,
-		Engine:             engine,
-		JWT:                jwtManager,
-		AuthService:        authService,
-		UserService:        userService,
-		CategoryService:    categoryService,
-		ProductService:     productService,
-		CustomerService:    customerService,
-		TransactionService: transactionService,
+		Config:               cfg,
+		DB:                   db,
+		Engine:               engine,
+		JWT:                  jwtManager,
+		AuthService:          authService,
+		UserService:          userService,
+		CategoryService:      categoryService,
+		ProductService:       productService,
+		CustomerService:      customerService,
+		TransactionService:   transactionService,
+		VoucherService:       voucherService,
+		LoyaltyService:       loyaltyService,
+		TrashService:         trashService,
+		InventoryService:     inventoryService,
+		MediaService:         mediaService,
+		PurchaseService:      purchaseService,
+		StockTakeService:     stockTakeService,
+		StoreService:         storeService,
+		StockTransferService: stockTransferService,
+		LicenseService:       licenseService,
+		RoleService:          roleService,
 		Cleanup: func() {
 			cleanTestDatabase(t, db)
 			db.Close()
@@ -140,13 +221,46 @@ func cleanTestDatabase(t *testing.T, db *sql.DB) {
 
 	// Drop tables in correct order due to foreign keys
 	tables := []string{
+		"login_events",
+		"mfa_recovery_codes",
+		"held_transaction_items",
+		"held_transactions",
+		"login_attempts",
+		"terminals",
+		"overrides",
+		"role_permissions",
+		"roles",
+		"stock_transfer_items",
+		"stock_transfers",
+		"stock_take_items",
+		"stock_takes",
+		"purchase_order_lines",
+		"purchase_orders",
+		"suppliers",
+		"product_barcodes",
+		"stock_movements",
+		"recipe_items",
+		"ingredients",
+		"transaction_item_modifiers",
+		"modifiers",
+		"modifier_groups",
+		"store_product_variants",
+		"product_variants",
+		"customer_merges",
+		"loyalty_ledger",
+		"voucher_redemptions",
+		"vouchers",
 		"transaction_items",
 		"transactions",
 		"notifications",
+		"store_products",
 		"products",
 		"categories",
 		"customers",
+		"user_stores",
+		"stores",
 		"users",
+		"tenants",
 	}
 
 	for _, table := range tables {
@@ -162,55 +276,123 @@ func runMigrations(t *testing.T, db *sql.DB) {
 	t.Helper()
 
 	migration := `
+		CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+		CREATE TABLE IF NOT EXISTS tenants (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			license_key TEXT DEFAULT '',
+			is_active BOOLEAN DEFAULT TRUE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		INSERT INTO tenants (id, name) VALUES ('default', 'Default');
+
 		CREATE TABLE IF NOT EXISTS users (
 			id TEXT PRIMARY KEY,
-			email TEXT NOT NULL UNIQUE,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			email TEXT NOT NULL,
 			password_hash TEXT NOT NULL,
 			name TEXT NOT NULL,
 			phone TEXT DEFAULT '',
-			role TEXT NOT NULL DEFAULT 'cashier' CHECK (role IN ('admin', 'manager', 'cashier')),
+			role TEXT NOT NULL DEFAULT 'cashier',
+			pin_hash TEXT DEFAULT '',
+			totp_secret TEXT DEFAULT '',
+			totp_enabled BOOLEAN DEFAULT FALSE,
+			totp_last_step BIGINT DEFAULT 0,
 			is_active BOOLEAN DEFAULT TRUE,
+			deleted_at TIMESTAMP,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		);
 
+		CREATE TABLE IF NOT EXISTS stores (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			code TEXT NOT NULL,
+			name TEXT NOT NULL,
+			address TEXT DEFAULT '',
+			phone TEXT DEFAULT '',
+			is_active BOOLEAN DEFAULT TRUE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS user_stores (
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			store_id TEXT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, store_id)
+		);
+
 		CREATE TABLE IF NOT EXISTS categories (
 			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
 			name TEXT NOT NULL,
 			description TEXT DEFAULT '',
-			slug TEXT NOT NULL UNIQUE,
+			slug TEXT NOT NULL,
 			is_active BOOLEAN DEFAULT TRUE,
+			deleted_at TIMESTAMP,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		);
 
 		CREATE TABLE IF NOT EXISTS products (
 			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
 			category_id TEXT NOT NULL REFERENCES categories(id) ON DELETE RESTRICT,
-			sku TEXT NOT NULL UNIQUE,
+			sku TEXT NOT NULL,
 			name TEXT NOT NULL,
 			description TEXT DEFAULT '',
 			price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+			cost_price DECIMAL(12, 2) NOT NULL DEFAULT 0,
 			stock INTEGER NOT NULL DEFAULT 0,
 			image_url TEXT DEFAULT '',
+			thumbnail_url TEXT DEFAULT '',
 			is_active BOOLEAN DEFAULT TRUE,
+			deleted_at TIMESTAMP,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			search_vector tsvector GENERATED ALWAYS AS (
+				setweight(to_tsvector('simple', COALESCE(sku, '')), 'A') ||
+				setweight(to_tsvector('simple', COALESCE(name, '')), 'A') ||
+				setweight(to_tsvector('indonesian', COALESCE(name, '')), 'A') ||
+				setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+				setweight(to_tsvector('indonesian', COALESCE(description, '')), 'C') ||
+				setweight(to_tsvector('english', COALESCE(description, '')), 'C')
+			) STORED
+		);
+
+		CREATE TABLE IF NOT EXISTS store_products (
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			store_id TEXT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+			product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			stock INTEGER NOT NULL DEFAULT 0,
+			price DECIMAL(10, 2) CHECK (price >= 0),
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (store_id, product_id)
 		);
 
 		CREATE TABLE IF NOT EXISTS customers (
 			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
 			name TEXT NOT NULL,
 			email TEXT DEFAULT '',
 			phone TEXT DEFAULT '',
 			address TEXT DEFAULT '',
 			loyalty_points INTEGER DEFAULT 0,
+			phone_normalized TEXT DEFAULT '',
+			email_normalized TEXT DEFAULT '',
+			deleted_at TIMESTAMP,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		);
 
 		CREATE TABLE IF NOT EXISTS transactions (
 			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
 			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
 			customer_id TEXT REFERENCES customers(id) ON DELETE SET NULL,
 			invoice_number TEXT NOT NULL UNIQUE,
@@ -221,15 +403,22 @@ func runMigrations(t *testing.T, db *sql.DB) {
 			payment_method TEXT NOT NULL CHECK (payment_method IN ('cash', 'card', 'qris', 'transfer')),
 			status TEXT NOT NULL DEFAULT 'completed' CHECK (status IN ('pending', 'completed', 'cancelled', 'refunded')),
 			notes TEXT DEFAULT '',
+			voucher_code TEXT DEFAULT '',
+			points_earned INTEGER DEFAULT 0,
+			points_redeemed INTEGER DEFAULT 0,
+			store_id TEXT REFERENCES stores(id) ON DELETE RESTRICT,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		);
 
 		CREATE TABLE IF NOT EXISTS transaction_items (
 			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
 			transaction_id TEXT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
 			product_id TEXT NOT NULL,
 			product_name TEXT NOT NULL,
+			variant_id TEXT,
+			variant_name TEXT DEFAULT '',
 			unit_price DECIMAL(10, 2) NOT NULL,
 			quantity INTEGER NOT NULL,
 			subtotal DECIMAL(12, 2) NOT NULL,
@@ -238,6 +427,7 @@ func runMigrations(t *testing.T, db *sql.DB) {
 
 		CREATE TABLE IF NOT EXISTS notifications (
 			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
 			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
 			type TEXT NOT NULL,
 			title TEXT NOT NULL,
@@ -246,6 +436,386 @@ func runMigrations(t *testing.T, db *sql.DB) {
 			action_url TEXT DEFAULT '',
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		);
+
+		CREATE TABLE IF NOT EXISTS vouchers (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			code TEXT NOT NULL,
+			batch_id TEXT NOT NULL,
+			discount_type TEXT NOT NULL CHECK (discount_type IN ('percentage', 'fixed')),
+			discount_value DECIMAL(12, 2) NOT NULL,
+			max_discount DECIMAL(12, 2) DEFAULT 0,
+			min_spend DECIMAL(12, 2) DEFAULT 0,
+			max_uses INTEGER NOT NULL DEFAULT 1,
+			uses_per_customer INTEGER NOT NULL DEFAULT 0,
+			used_count INTEGER NOT NULL DEFAULT 0,
+			valid_from TIMESTAMP,
+			expires_at TIMESTAMP,
+			is_active BOOLEAN DEFAULT TRUE,
+			created_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS voucher_redemptions (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			voucher_id TEXT NOT NULL REFERENCES vouchers(id) ON DELETE CASCADE,
+			transaction_id TEXT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+			customer_id TEXT REFERENCES customers(id) ON DELETE SET NULL,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+			discount_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS loyalty_ledger (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			customer_id TEXT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+			transaction_id TEXT REFERENCES transactions(id) ON DELETE SET NULL,
+			type TEXT NOT NULL CHECK (type IN ('earn', 'redeem', 'adjust', 'expire')),
+			points INTEGER NOT NULL,
+			balance_after INTEGER NOT NULL,
+			remaining INTEGER NOT NULL DEFAULT 0,
+			expires_at TIMESTAMP,
+			note TEXT DEFAULT '',
+			created_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS customer_merges (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			target_customer_id TEXT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+			merged_customer_id TEXT NOT NULL,
+			merged_name TEXT NOT NULL,
+			merged_email TEXT DEFAULT '',
+			merged_phone TEXT DEFAULT '',
+			points_merged INTEGER NOT NULL DEFAULT 0,
+			transactions_moved INTEGER NOT NULL DEFAULT 0,
+			merged_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS product_variants (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			sku TEXT NOT NULL,
+			name TEXT NOT NULL,
+			price DECIMAL(10, 2) NOT NULL,
+			stock INTEGER NOT NULL DEFAULT 0,
+			is_active BOOLEAN DEFAULT TRUE,
+			sort_order INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS store_product_variants (
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			store_id TEXT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+			variant_id TEXT NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+			stock INTEGER NOT NULL DEFAULT 0,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (store_id, variant_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS modifier_groups (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			min_select INTEGER NOT NULL DEFAULT 0,
+			max_select INTEGER NOT NULL DEFAULT 0,
+			sort_order INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS modifiers (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			group_id TEXT NOT NULL REFERENCES modifier_groups(id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			price_delta DECIMAL(10, 2) NOT NULL DEFAULT 0,
+			is_active BOOLEAN DEFAULT TRUE,
+			sort_order INTEGER NOT NULL DEFAULT 0
+		);
+
+		CREATE TABLE IF NOT EXISTS transaction_item_modifiers (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			transaction_item_id TEXT NOT NULL REFERENCES transaction_items(id) ON DELETE CASCADE,
+			modifier_id TEXT NOT NULL,
+			group_name TEXT NOT NULL,
+			name TEXT NOT NULL,
+			price_delta DECIMAL(10, 2) NOT NULL DEFAULT 0
+		);
+
+		CREATE TABLE IF NOT EXISTS ingredients (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			sku TEXT NOT NULL,
+			name TEXT NOT NULL,
+			unit TEXT NOT NULL,
+			stock DECIMAL(14, 3) NOT NULL DEFAULT 0,
+			low_stock_threshold DECIMAL(14, 3) NOT NULL DEFAULT 0,
+			is_active BOOLEAN DEFAULT TRUE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS recipe_items (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			ingredient_id TEXT NOT NULL REFERENCES ingredients(id) ON DELETE RESTRICT,
+			quantity DECIMAL(12, 3) NOT NULL,
+			unit TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS stock_movements (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			ingredient_id TEXT REFERENCES ingredients(id) ON DELETE CASCADE,
+			product_id TEXT REFERENCES products(id) ON DELETE CASCADE,
+			variant_id TEXT REFERENCES product_variants(id) ON DELETE CASCADE,
+			type TEXT NOT NULL CHECK (type IN ('sale', 'return', 'restock', 'adjustment', 'waste', 'transfer_out', 'transfer_in')),
+			quantity DECIMAL(14, 3) NOT NULL,
+			balance_after DECIMAL(14, 3) NOT NULL,
+			reference_id TEXT,
+			note TEXT DEFAULT '',
+			created_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+			store_id TEXT REFERENCES stores(id) ON DELETE CASCADE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS product_barcodes (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			variant_id TEXT REFERENCES product_variants(id) ON DELETE CASCADE,
+			code TEXT NOT NULL,
+			type TEXT NOT NULL CHECK (type IN ('ean13', 'upca', 'internal', 'variable')),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS suppliers (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			name TEXT NOT NULL,
+			contact_name TEXT DEFAULT '',
+			email TEXT DEFAULT '',
+			phone TEXT DEFAULT '',
+			address TEXT DEFAULT '',
+			is_active BOOLEAN DEFAULT TRUE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS purchase_orders (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			po_number TEXT NOT NULL UNIQUE,
+			supplier_id TEXT NOT NULL REFERENCES suppliers(id) ON DELETE RESTRICT,
+			store_id TEXT REFERENCES stores(id) ON DELETE RESTRICT,
+			status TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'ordered', 'partially_received', 'received', 'cancelled')),
+			expected_at TIMESTAMP,
+			notes TEXT DEFAULT '',
+			created_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+			ordered_at TIMESTAMP,
+			received_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS purchase_order_lines (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			purchase_order_id TEXT NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+			product_id TEXT NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+			quantity INTEGER NOT NULL CHECK (quantity > 0),
+			received_quantity INTEGER NOT NULL DEFAULT 0,
+			unit_cost DECIMAL(12, 2) NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS stock_takes (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			reference TEXT UNIQUE NOT NULL,
+			category_id TEXT REFERENCES categories(id) ON DELETE SET NULL,
+			store_id TEXT REFERENCES stores(id) ON DELETE RESTRICT,
+			status TEXT NOT NULL DEFAULT 'counting' CHECK (status IN ('counting', 'approved', 'cancelled')),
+			notes TEXT DEFAULT '',
+			created_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+			approved_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+			approved_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS stock_take_items (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			stock_take_id TEXT NOT NULL REFERENCES stock_takes(id) ON DELETE CASCADE,
+			product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			expected_stock INTEGER NOT NULL,
+			counted_quantity INTEGER CHECK (counted_quantity >= 0),
+			counted_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+			counted_at TIMESTAMP,
+			UNIQUE (stock_take_id, product_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS stock_transfers (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			reference TEXT UNIQUE NOT NULL,
+			from_store_id TEXT NOT NULL REFERENCES stores(id) ON DELETE RESTRICT,
+			to_store_id TEXT NOT NULL REFERENCES stores(id) ON DELETE RESTRICT,
+			status TEXT NOT NULL DEFAULT 'requested' CHECK (status IN ('requested', 'in_transit', 'received', 'cancelled')),
+			notes TEXT DEFAULT '',
+			requested_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+			shipped_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+			shipped_at TIMESTAMP,
+			received_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+			received_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			CHECK (from_store_id <> to_store_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS stock_transfer_items (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			transfer_id TEXT NOT NULL REFERENCES stock_transfers(id) ON DELETE CASCADE,
+			product_id TEXT NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+			requested_quantity INTEGER NOT NULL CHECK (requested_quantity > 0),
+			shipped_quantity INTEGER CHECK (shipped_quantity >= 0),
+			received_quantity INTEGER CHECK (received_quantity >= 0),
+			unit_cost DECIMAL(12, 2) NOT NULL DEFAULT 0,
+			UNIQUE (transfer_id, product_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS roles (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			name TEXT NOT NULL,
+			description TEXT DEFAULT '',
+			require_mfa BOOLEAN DEFAULT FALSE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS role_permissions (
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			role_id TEXT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+			permission TEXT NOT NULL,
+			PRIMARY KEY (role_id, permission)
+		);
+
+		CREATE TABLE IF NOT EXISTS terminals (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			store_id TEXT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			device_type TEXT NOT NULL DEFAULT 'pos' CHECK (device_type IN ('pos', 'kds')),
+			key_hash TEXT NOT NULL UNIQUE,
+			is_active BOOLEAN DEFAULT TRUE,
+			last_used_at TIMESTAMP,
+			app_version TEXT DEFAULT '',
+			latency_ms INTEGER,
+			last_seen_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS login_attempts (
+			key TEXT PRIMARY KEY,
+			failures INTEGER NOT NULL DEFAULT 0,
+			locked_until TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS held_transactions (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			store_id TEXT DEFAULT '',
+			terminal_id TEXT DEFAULT '',
+			hold_number TEXT NOT NULL,
+			customer_id TEXT REFERENCES customers(id) ON DELETE SET NULL,
+			customer_name TEXT DEFAULT '',
+			total_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+			notes TEXT DEFAULT '',
+			held_by TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS held_transaction_items (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			held_transaction_id TEXT NOT NULL REFERENCES held_transactions(id) ON DELETE CASCADE,
+			product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			variant_id TEXT DEFAULT '',
+			quantity INTEGER NOT NULL CHECK (quantity > 0),
+			position INTEGER NOT NULL DEFAULT 0
+		);
+
+		CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			code_hash TEXT NOT NULL,
+			used_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS login_events (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT REFERENCES tenants(id),
+			user_id TEXT REFERENCES users(id) ON DELETE SET NULL,
+			email TEXT NOT NULL,
+			event TEXT NOT NULL,
+			ip_address TEXT DEFAULT '',
+			user_agent TEXT DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS overrides (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id),
+			token_hash TEXT NOT NULL UNIQUE,
+			action TEXT NOT NULL CHECK (action IN ('void', 'refund', 'discount', 'price_override', 'no_sale')),
+			reference_id TEXT DEFAULT '',
+			amount DECIMAL(12, 2),
+			terminal_id TEXT DEFAULT '',
+			requested_by TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			approved_by TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			reason TEXT DEFAULT '',
+			expires_at TIMESTAMP NOT NULL,
+			used_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_tenant_name ON roles(tenant_id, name);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_active ON users(email) WHERE deleted_at IS NULL;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_tenant_slug_active ON categories(tenant_id, slug) WHERE deleted_at IS NULL;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_products_tenant_sku_active ON products(tenant_id, sku) WHERE deleted_at IS NULL;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_tenant_phone_active ON customers(tenant_id, phone_normalized)
+			WHERE phone_normalized <> '' AND deleted_at IS NULL;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_tenant_email_active ON customers(tenant_id, email_normalized)
+			WHERE email_normalized <> '' AND deleted_at IS NULL;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_tenant_sku ON product_variants(tenant_id, sku);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_stores_tenant_code ON stores(tenant_id, code);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_vouchers_tenant_code ON vouchers(tenant_id, code);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_ingredients_tenant_sku ON ingredients(tenant_id, sku);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_product_barcodes_tenant_code ON product_barcodes(tenant_id, code);
+		CREATE INDEX IF NOT EXISTS idx_products_search ON products USING GIN (search_vector);
+		CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops);
+		CREATE INDEX IF NOT EXISTS idx_transactions_tenant_keyset ON transactions(tenant_id, created_at DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_stock_movements_tenant_keyset ON stock_movements(tenant_id, created_at DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_loyalty_ledger_tenant_keyset ON loyalty_ledger(tenant_id, customer_id, created_at DESC, id DESC);
 	`
 
 	_, err := db.Exec(migration)
@@ -259,21 +829,56 @@ func setupTestRoutes(engine *gin.Engine, cfg *config.Config, jwtManager *utils.J
 	authService *service.AuthService, userService *service.UserService,
 	categoryService *service.CategoryService, productService *service.ProductService,
 	customerService *service.CustomerService, transactionService *service.TransactionService,
-	db *sql.DB) {
+	voucherService *service.VoucherService, loyaltyService *service.LoyaltyService,
+	trashService *service.TrashService, inventoryService *service.InventoryService,
+	mediaService *service.MediaService, purchaseService *service.PurchaseService,
+	reportService *service.ReportService, stockTakeService *service.StockTakeService,
+	storeService *service.StoreService, stockTransferService *service.StockTransferService,
+	licenseService *service.LicenseService, roleService *service.RoleService,
+	overrideService *service.OverrideService, terminalService *service.TerminalService,
+	heldTransactionService *service.HeldTransactionService, mfaService *service.MFAService,
+	loginSecurityService *service.LoginSecurityService, notificationService *service.NotificationService,
+	systemService *service.SystemService, db *sql.DB) {
+
+	overrideLimiter := middleware.NewPerMinuteRateLimiter(cfg.RateLimit.OverridesPerMinute)
 
 	// Handlers
 	healthHandler := handler.NewHealthHandler(cfg)
 	authHandler := handler.NewAuthHandler(authService, cfg)
-	userHandler := handler.NewUserHandler(userService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	dashboardHandler := handler.NewDashboardHandler(transactionService, productService, categoryService, customerService, reportService, systemService)
+	userHandler := handler.NewUserHandler(userService, loginSecurityService)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
-	productHandler := handler.NewProductHandler(productService)
-	customerHandler := handler.NewCustomerHandler(customerService)
+	productHandler := handler.NewProductHandler(productService, roleService)
+	customerHandler := handler.NewCustomerHandler(customerService, transactionService)
 	transactionHandler := handler.NewTransactionHandler(transactionService)
-	posHandler := handler.NewPOSHandler(productService, transactionService)
+	posHandler := handler.NewPOSHandler(productService, transactionService, inventoryService, heldTransactionService)
+	voucherHandler := handler.NewVoucherHandler(voucherService)
+	loyaltyHandler := handler.NewLoyaltyHandler(loyaltyService)
+	trashHandler := handler.NewTrashHandler(trashService)
+	inventoryHandler := handler.NewInventoryHandler(inventoryService)
+	mediaHandler := handler.NewMediaHandler(mediaService)
+	purchaseHandler := handler.NewPurchaseHandler(purchaseService)
+	stockTakeHandler := handler.NewStockTakeHandler(stockTakeService)
+	reportHandler := handler.NewReportHandler(reportService)
+	storeHandler := handler.NewStoreHandler(storeService)
+	stockTransferHandler := handler.NewStockTransferHandler(stockTransferService)
+	licenseHandler := handler.NewLicenseHandler(licenseService)
+	roleHandler := handler.NewRoleHandler(roleService)
+	overrideHandler := handler.NewOverrideHandler(overrideService)
+	terminalHandler := handler.NewTerminalHandler(terminalService, cfg)
+	mfaHandler := handler.NewMFAHandler(mfaService)
+
+	can := func(permission string) gin.HandlerFunc {
+		return middleware.RequirePermission(roleService, permission)
+	}
 
 	// Health
 	engine.GET("/health", healthHandler.Check)
 
+	// Uploaded media
+	engine.GET("/media/*key", mediaHandler.Serve)
+
 	// API v1
 	v1 := engine.Group("/api/v1")
 	{
@@ -284,55 +889,179 @@ func setupTestRoutes(engine *gin.Engine, cfg *config.Config, jwtManager *utils.J
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/refresh", authHandler.Refresh)
 			auth.POST("/logout", authHandler.Logout)
+			auth.GET("/terminal/users", terminalHandler.Users)
+			auth.POST("/pin-login", terminalHandler.PINLogin)
+			auth.POST("/mfa/setup", authHandler.SetupMFA)
+			auth.POST("/mfa/verify", authHandler.VerifyMFA)
 		}
 
+		v1.POST("/terminal/heartbeat", terminalHandler.Heartbeat)
+
 		// Protected routes
 		protected := v1.Group("")
 		protected.Use(middleware.AuthMiddleware(jwtManager))
 		{
 			protected.GET("/auth/me", authHandler.Me)
 			protected.PUT("/auth/me", authHandler.UpdateProfile)
+			protected.PUT("/auth/me/pin", authHandler.SetPIN)
+			protected.GET("/auth/me/activity", authHandler.GetActivityLog)
+			protected.GET("/auth/me/mfa", mfaHandler.Status)
+			protected.POST("/auth/me/mfa/setup", mfaHandler.Setup)
+			protected.POST("/auth/me/mfa/enable", mfaHandler.Enable)
+			protected.POST("/auth/me/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+			protected.DELETE("/auth/me/mfa", mfaHandler.Disable)
+			protected.POST("/auth/store", authHandler.SwitchStore)
+
+			// Notifications
+			notifications := protected.Group("/notifications")
+			{
+				notifications.GET("", notificationHandler.GetNotifications)
+				notifications.PUT("/:id/read", notificationHandler.MarkAsRead)
+				notifications.PUT("/read-all", notificationHandler.MarkAllAsRead)
+				notifications.DELETE("/:id", notificationHandler.DeleteNotification)
+			}
 
-			// Users (Admin only)
+			// Users
 			users := protected.Group("/users")
-			users.Use(middleware.RequireRole(models.RoleAdmin))
+			users.Use(can(models.PermUsersManage))
 			{
 				users.GET("", userHandler.List)
+				users.GET("/login-events", userHandler.LoginEvents)
 				users.GET("/:id", userHandler.Get)
 				users.POST("", userHandler.Create)
 				users.PUT("/:id", userHandler.Update)
 				users.DELETE("/:id", userHandler.Delete)
+				users.POST("/:id/restore", trashHandler.Restore(models.TrashUsers))
+				users.PUT("/:id/stores", storeHandler.AssignUserStores)
+				users.DELETE("/:id/mfa", mfaHandler.Reset)
+				users.POST("/:id/unlock", userHandler.Unlock)
+			}
+
+			// Roles
+			roles := protected.Group("/roles")
+			roles.Use(can(models.PermRolesManage))
+			{
+				roles.GET("", roleHandler.List)
+				roles.GET("/permissions", roleHandler.Permissions)
+				roles.GET("/:name", roleHandler.Get)
+				roles.POST("", roleHandler.Create)
+				roles.PUT("/:name", roleHandler.Update)
+				roles.DELETE("/:name", roleHandler.Delete)
+			}
+
+			// Terminals
+			terminals := protected.Group("/terminals")
+			terminals.Use(can(models.PermTerminalsManage))
+			{
+				terminals.GET("", terminalHandler.List)
+				terminals.POST("", terminalHandler.Create)
+				terminals.PUT("/:id", terminalHandler.Update)
+				terminals.POST("/:id/key", terminalHandler.RegenerateKey)
+				terminals.DELETE("/:id", terminalHandler.Delete)
+			}
+
+			// Overrides
+			overrides := protected.Group("/overrides")
+			{
+				overrides.POST("", middleware.UserRateLimitMiddleware(overrideLimiter), overrideHandler.Create)
+				overrides.GET("", can(models.PermOverridesView), overrideHandler.List)
 			}
 
 			// Categories
 			categories := protected.Group("/categories")
 			{
 				categories.GET("", categoryHandler.List)
+				categories.GET("/activity-log", dashboardHandler.GetCategoryActivityLog)
 				categories.GET("/:id", categoryHandler.Get)
-				categories.POST("", middleware.RequireRole(models.RoleAdmin, models.RoleManager), categoryHandler.Create)
-				categories.PUT("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleManager), categoryHandler.Update)
-				categories.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), categoryHandler.Delete)
+				categories.POST("", can(models.PermCategoriesManage), categoryHandler.Create)
+				categories.PUT("/:id", can(models.PermCategoriesManage), categoryHandler.Update)
+				categories.DELETE("/:id", can(models.PermCategoriesDelete), categoryHandler.Delete)
+				categories.POST("/:id/restore", can(models.PermTrashManage), trashHandler.Restore(models.TrashCategories))
 			}
 
 			// Products
 			products := protected.Group("/products")
 			{
 				products.GET("", productHandler.List)
+				products.GET("/search", posHandler.SearchProducts)
+				products.GET("/stock-movements", dashboardHandler.GetStockMovements)
+				products.GET("/export", can(models.PermProductsExport), middleware.RequireFeature(licenseService, models.FeatureExport), productHandler.Export)
+				products.POST("/import", can(models.PermProductsManage), productHandler.Import)
 				products.GET("/:id", productHandler.Get)
-				products.POST("", middleware.RequireRole(models.RoleAdmin, models.RoleManager), productHandler.Create)
-				products.PUT("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleManager), productHandler.Update)
-				products.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), productHandler.Delete)
-				products.PATCH("/:id/stock", productHandler.UpdateStock)
+				products.POST("", can(models.PermProductsManage), productHandler.Create)
+				products.PUT("/:id", can(models.PermProductsManage), productHandler.Update)
+				products.DELETE("/:id", can(models.PermProductsDelete), productHandler.Delete)
+				products.POST("/:id/restore", can(models.PermTrashManage), trashHandler.Restore(models.TrashProducts))
+				products.PATCH("/:id/stock", can(models.PermInventoryManage), productHandler.UpdateStock)
+				products.POST("/:id/image", can(models.PermProductsManage), mediaHandler.UploadProductImage)
+				products.DELETE("/:id/image", can(models.PermProductsManage), mediaHandler.DeleteProductImage)
+				products.POST("/:id/variants", can(models.PermProductsManage), productHandler.CreateVariant)
+				products.PUT("/:id/variants/:variantId", can(models.PermProductsManage), productHandler.UpdateVariant)
+				products.PATCH("/:id/variants/:variantId/stock", can(models.PermInventoryManage), productHandler.UpdateVariantStock)
+				products.DELETE("/:id/variants/:variantId", can(models.PermProductsManage), productHandler.DeleteVariant)
+				products.POST("/:id/modifier-groups", can(models.PermProductsManage), productHandler.CreateModifierGroup)
+				products.PUT("/:id/modifier-groups/:groupId", can(models.PermProductsManage), productHandler.UpdateModifierGroup)
+				products.DELETE("/:id/modifier-groups/:groupId", can(models.PermProductsManage), productHandler.DeleteModifierGroup)
+				products.POST("/:id/barcodes", can(models.PermProductsManage), productHandler.CreateBarcode)
+				products.DELETE("/:id/barcodes/:barcodeId", can(models.PermProductsManage), productHandler.DeleteBarcode)
+				products.GET("/:id/barcodes/:barcodeId/label", productHandler.BarcodeLabel)
+				products.GET("/:id/recipe", inventoryHandler.GetRecipe)
+				products.PUT("/:id/recipe", can(models.PermProductsManage), inventoryHandler.SetRecipe)
+			}
+
+			// Ingredients
+			ingredients := protected.Group("/ingredients")
+			{
+				ingredients.GET("", inventoryHandler.List)
+				ingredients.GET("/:id", inventoryHandler.Get)
+				ingredients.GET("/:id/movements", inventoryHandler.Movements)
+				ingredients.POST("", can(models.PermInventoryManage), inventoryHandler.Create)
+				ingredients.PUT("/:id", can(models.PermInventoryManage), inventoryHandler.Update)
+				ingredients.DELETE("/:id", can(models.PermInventoryManage), inventoryHandler.Delete)
+				ingredients.POST("/:id/stock", can(models.PermInventoryManage), inventoryHandler.AdjustStock)
+			}
+
+			protected.GET("/units", inventoryHandler.Units)
+
+			// Stock transfers
+			stockTransfers := protected.Group("/stock-transfers")
+			stockTransfers.Use(can(models.PermStockTransfersManage), middleware.RequireFeature(licenseService, models.FeatureMultiStore))
+			{
+				stockTransfers.GET("", stockTransferHandler.List)
+				stockTransfers.GET("/:id", stockTransferHandler.Get)
+				stockTransfers.POST("", stockTransferHandler.Create)
+				stockTransfers.POST("/:id/ship", stockTransferHandler.Ship)
+				stockTransfers.POST("/:id/receive", stockTransferHandler.Receive)
+				stockTransfers.POST("/:id/cancel", stockTransferHandler.Cancel)
+			}
+
+			stockTakes := protected.Group("/stock-takes")
+			{
+				stockTakes.GET("", stockTakeHandler.List)
+				stockTakes.GET("/:id", stockTakeHandler.Get)
+				stockTakes.POST("/:id/counts", stockTakeHandler.SubmitCounts)
+				stockTakes.POST("", can(models.PermStockTakesManage), stockTakeHandler.Create)
+				stockTakes.POST("/:id/approve", can(models.PermStockTakesManage), stockTakeHandler.Approve)
+				stockTakes.POST("/:id/cancel", can(models.PermStockTakesManage), stockTakeHandler.Cancel)
 			}
 
 			// Customers
 			customers := protected.Group("/customers")
 			{
 				customers.GET("", customerHandler.List)
+				customers.GET("/duplicates", customerHandler.Duplicates)
 				customers.GET("/:id", customerHandler.Get)
 				customers.POST("", customerHandler.Create)
 				customers.PUT("/:id", customerHandler.Update)
-				customers.DELETE("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleManager), customerHandler.Delete)
+				customers.DELETE("/:id", can(models.PermCustomersDelete), customerHandler.Delete)
+				customers.POST("/:id/restore", can(models.PermTrashManage), trashHandler.Restore(models.TrashCustomers))
+				customers.GET("/:id/stats", customerHandler.Stats)
+				customers.POST("/:id/merge", can(models.PermCustomersMerge), customerHandler.Merge)
+				customers.GET("/:id/merges", can(models.PermCustomersMergesView), customerHandler.Merges)
+				customers.GET("/:id/transactions", customerHandler.Transactions)
+				customers.GET("/:id/loyalty", loyaltyHandler.Summary)
+				customers.GET("/:id/loyalty/ledger", loyaltyHandler.Ledger)
+				customers.PATCH("/:id/loyalty-points", can(models.PermLoyaltyAdjust), loyaltyHandler.Adjust)
 			}
 
 			// Transactions
@@ -341,18 +1070,91 @@ func setupTestRoutes(engine *gin.Engine, cfg *config.Config, jwtManager *utils.J
 				transactions.GET("", transactionHandler.List)
 				transactions.GET("/:id", transactionHandler.Get)
 				transactions.POST("", transactionHandler.Create)
-				transactions.PATCH("/:id/status", middleware.RequireRole(models.RoleAdmin, models.RoleManager), transactionHandler.UpdateStatus)
+				transactions.PATCH("/:id/status", transactionHandler.UpdateStatus)
 			}
 
 			// POS
 			pos := protected.Group("/pos")
 			{
 				pos.GET("/products", posHandler.GetProducts)
+				pos.GET("/products/barcode/:code", posHandler.LookupBarcode)
 				pos.POST("/checkout", posHandler.CreateTransaction)
 				pos.GET("/held", posHandler.GetHeldTransactions)
 				pos.POST("/hold", posHandler.HoldTransactionCreate)
 				pos.DELETE("/held/:id", posHandler.DeleteHeldTransaction)
+				pos.POST("/vouchers/validate", voucherHandler.Validate)
+				pos.POST("/no-sale", overrideHandler.NoSale)
+			}
+
+			// Vouchers
+			vouchers := protected.Group("/vouchers")
+			vouchers.Use(can(models.PermVouchersManage))
+			{
+				vouchers.GET("", voucherHandler.List)
+				vouchers.POST("/batch", voucherHandler.Generate)
+				vouchers.GET("/:id", voucherHandler.Get)
+				vouchers.PUT("/:id", voucherHandler.Update)
+				vouchers.GET("/:id/redemptions", voucherHandler.Redemptions)
 			}
+
+			// Trash
+			trash := protected.Group("/trash")
+			trash.Use(can(models.PermTrashManage))
+			{
+				trash.GET("", trashHandler.List)
+				trash.POST("/purge", trashHandler.Purge)
+			}
+
+			// Stores
+			stores := protected.Group("/stores")
+			{
+				stores.GET("", storeHandler.List)
+				stores.GET("/:id", storeHandler.Get)
+				stores.POST("", can(models.PermStoresManage), storeHandler.Create)
+				stores.PUT("/:id", can(models.PermStoresManage), storeHandler.Update)
+				stores.PUT("/:id/prices", can(models.PermProductsPriceUpdate), storeHandler.SetPrices)
+			}
+
+			// Suppliers
+			suppliers := protected.Group("/suppliers")
+			suppliers.Use(can(models.PermPurchasingManage))
+			{
+				suppliers.GET("", purchaseHandler.ListSuppliers)
+				suppliers.POST("", purchaseHandler.CreateSupplier)
+				suppliers.GET("/:id", purchaseHandler.GetSupplier)
+				suppliers.PUT("/:id", purchaseHandler.UpdateSupplier)
+				suppliers.DELETE("/:id", purchaseHandler.DeleteSupplier)
+			}
+
+			// Purchase orders
+			purchaseOrders := protected.Group("/purchase-orders")
+			purchaseOrders.Use(can(models.PermPurchasingManage))
+			{
+				purchaseOrders.GET("", purchaseHandler.ListPurchaseOrders)
+				purchaseOrders.POST("", purchaseHandler.CreatePurchaseOrder)
+				purchaseOrders.GET("/:id", purchaseHandler.GetPurchaseOrder)
+				purchaseOrders.PUT("/:id", purchaseHandler.UpdatePurchaseOrder)
+				purchaseOrders.POST("/:id/order", purchaseHandler.SubmitPurchaseOrder)
+				purchaseOrders.POST("/:id/cancel", purchaseHandler.CancelPurchaseOrder)
+				purchaseOrders.POST("/:id/receive", purchaseHandler.ReceivePurchaseOrder)
+			}
+
+			// Reports
+			reports := protected.Group("/reports")
+			{
+				reports.GET("/purchase-orders/outstanding", can(models.PermReportsInventory), reportHandler.OutstandingPurchases)
+				reports.GET("/stock-transfers/in-transit", can(models.PermReportsInventory), middleware.RequireFeature(licenseService, models.FeatureMultiStore), reportHandler.InTransitTransfers)
+			}
+
+			// License
+			license := protected.Group("/license")
+			{
+				license.GET("", can(models.PermLicenseView), licenseHandler.Status)
+				license.PUT("", can(models.PermLicenseManage), licenseHandler.Activate)
+			}
+
+			// System
+			protected.GET("/system/health/detailed", can(models.PermSystemHealth), dashboardHandler.GetSystemHealth)
 		}
 	}
 }
@@ -367,11 +1169,31 @@ var (
 	TestCustomerID = "d0000000-0000-0000-0000-000000000001"
 )
 
+// testLicenseKey signs the license keys used in tests
+var testLicenseKey = ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+
+// issueLicense signs a license key with the test signing key
+func issueLicense(t *testing.T, license *utils.License) string {
+	t.Helper()
+
+	key, err := utils.SignLicense(testLicenseKey, license)
+	if err != nil {
+		t.Fatalf("Failed to sign license: %v", err)
+	}
+	return key
+}
+
 // seedTestData adds initial test data to the database
 func seedTestData(t *testing.T, db *sql.DB) {
 	t.Helper()
 	now := time.Now()
 
+	// License the default tenant for every feature without limits
+	license := issueLicense(t, &utils.License{Licensee: "Default", Plan: models.PlanEnterprise, IssuedAt: now})
+	if _, err := db.Exec(`UPDATE tenants SET license_key = $1 WHERE id = 'default'`, license); err != nil {
+		t.Fatalf("Failed to license default tenant: %v", err)
+	}
+
 	// Create test users
 	users := []struct {
 		id       string
@@ -416,9 +1238,11 @@ func seedTestData(t *testing.T, db *sql.DB) {
 
 	// Create test customer
 	_, err = db.Exec(`
-		INSERT INTO customers (id, name, email, phone, address, loyalty_points, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`, TestCustomerID, "Test Customer", "customer@test.local", "081234567890", "Test Address", 100, now, now)
+		INSERT INTO customers (id, name, email, phone, address, loyalty_points, phone_normalized, email_normalized,
+		                       created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, TestCustomerID, "Test Customer", "customer@test.local", "081234567890", "Test Address", 100,
+		"081234567890", "customer@test.local", now, now)
 	if err != nil {
 		t.Fatalf("Failed to seed customer: %v", err)
 	}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// ============================================
+// Sorting Tests
+// ============================================
+
+// seedSortProduct inserts a product with the given name and price
+func seedSortProduct(t *testing.T, env *TestEnv, sku, name string, price float64) {
+	t.Helper()
+
+	now := time.Now()
+	_, err := env.DB.Exec(`
+		INSERT INTO products (id, category_id, sku, name, description, price, stock, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, '', $5, 10, TRUE, $6, $7)
+	`, GenerateUUID(), TestCategoryID, sku, name, price, now, now)
+	if err != nil {
+		t.Fatalf("Failed to seed product: %v", err)
+	}
+}
+
+// listProductField lists products with the given query string and returns one field per row
+func listProductField(t *testing.T, env *TestEnv, query, field string) []interface{} {
+	t.Helper()
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/products?"+query, nil, env.LoginAsAdmin(t))
+	AssertStatus(t, w, http.StatusOK)
+
+	var values []interface{}
+	for _, item := range ParseResponse(t, w)["data"].([]interface{}) {
+		values = append(values, item.(map[string]interface{})[field])
+	}
+	return values
+}
+
+func TestSort_MultiKey(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	seedSortProduct(t, env, "SRT-001", "Bravo", 5000)
+	seedSortProduct(t, env, "SRT-002", "Alpha", 5000)
+	seedSortProduct(t, env, "SRT-003", "Charlie", 90000)
+
+	// Price descending, then name ascending within equal prices
+	names := listProductField(t, env, "sort=-price,name&per_page=3", "name")
+	want := []interface{}{"Charlie", "Test Product", "Alpha"}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, names)
+	}
+
+	names = listProductField(t, env, "sort=price,-name&per_page=2", "name")
+	want = []interface{}{"Bravo", "Alpha"}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, names)
+	}
+}
+
+func TestSort_LegacyOrderParameter(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	seedSortProduct(t, env, "SRT-004", "Zulu", 1000)
+
+	names := listProductField(t, env, "sort=name&order=desc&per_page=1", "name")
+	if len(names) != 1 || names[0] != "Zulu" {
+		t.Errorf("Expected Zulu first, got %v", names)
+	}
+}
+
+func TestSort_UnknownFieldRejected(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+	for _, path := range []string{
+		"/api/v1/products?sort=password_hash",
+		"/api/v1/products?sort=name%3BDROP%20TABLE%20users",
+		"/api/v1/products?sort=relevance",
+		"/api/v1/users?sort=price",
+		"/api/v1/transactions?sort=-unknown",
+		"/api/v1/customers?sort=name,bogus",
+	} {
+		w := env.MakeRequest(t, http.MethodGet, path, nil, cookies)
+		AssertStatus(t, w, http.StatusBadRequest)
+
+		errs, _ := ParseResponse(t, w)["errors"].([]interface{})
+		if len(errs) != 1 || errs[0].(map[string]interface{})["field"] != "sort" {
+			t.Errorf("%s: expected a sort validation error, got %v", path, errs)
+		}
+	}
+
+	// The users table survived
+	var count int
+	if err := env.DB.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&count); err != nil || count == 0 {
+		t.Errorf("Expected users to remain, got %d (%v)", count, err)
+	}
+}
+
+func TestSort_StableAcrossPages(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	for i := 0; i < 6; i++ {
+		seedSortProduct(t, env, fmt.Sprintf("TIE-%03d", i), "Same Name", 7000)
+	}
+
+	// Equal sort keys fall back to id, so pages neither overlap nor skip rows
+	seen := map[interface{}]bool{}
+	for page := 1; page <= 4; page++ {
+		for _, id := range listProductField(t, env, fmt.Sprintf("sort=price&per_page=2&page=%d", page), "id") {
+			if seen[id] {
+				t.Errorf("Product %v appeared on more than one page", id)
+			}
+			seen[id] = true
+		}
+	}
+	if len(seen) != 7 {
+		t.Errorf("Expected all 7 products across pages, got %d", len(seen))
+	}
+}
+
+func TestSort_OtherLists(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+	for _, path := range []string{
+		"/api/v1/categories?sort=name",
+		"/api/v1/users?sort=role,-email",
+		"/api/v1/transactions?sort=-total_amount",
+		"/api/v1/customers?sort=-lifetime_spend,name",
+		"/api/v1/vouchers?sort=expires_at",
+	} {
+		w := env.MakeRequest(t, http.MethodGet, path, nil, cookies)
+		AssertStatus(t, w, http.StatusOK)
+	}
+}
@@ -0,0 +1,269 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+// ============================================
+// Stock Take Tests
+// ============================================
+
+// openStockTake opens a stock take of one category, or of the whole store when categoryID is empty
+func openStockTake(t *testing.T, env *TestEnv, cookies []*http.Cookie, categoryID string) string {
+	t.Helper()
+
+	body := map[string]interface{}{"notes": "Monthly count"}
+	if categoryID != "" {
+		body["category_id"] = categoryID
+	}
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/stock-takes", body, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+	return ParseResponse(t, w)["data"].(map[string]interface{})["id"].(string)
+}
+
+// submitCounts posts counted quantities keyed by product ID
+func submitCounts(t *testing.T, env *TestEnv, cookies []*http.Cookie, stockTakeID, mode string, counts map[string]int) map[string]interface{} {
+	t.Helper()
+
+	var items []map[string]interface{}
+	for productID, quantity := range counts {
+		items = append(items, map[string]interface{}{"product_id": productID, "quantity": quantity})
+	}
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/stock-takes/"+stockTakeID+"/counts", map[string]interface{}{
+		"mode":  mode,
+		"items": items,
+	}, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	return ParseResponse(t, w)["data"].(map[string]interface{})
+}
+
+// stockTakeItem returns a product's row in a stock take response
+func stockTakeItem(t *testing.T, take map[string]interface{}, productID string) map[string]interface{} {
+	t.Helper()
+
+	for _, item := range take["items"].([]interface{}) {
+		row := item.(map[string]interface{})
+		if row["product_id"] == productID {
+			return row
+		}
+	}
+	t.Fatalf("Product %s is not in the stock take", productID)
+	return nil
+}
+
+// sellStock takes stock away as a sale made while the count is in progress would
+func sellStock(t *testing.T, env *TestEnv, productID string, quantity int) {
+	t.Helper()
+
+	if _, err := env.DB.Exec(`UPDATE products SET stock = stock - $1 WHERE id = $2`, quantity, productID); err != nil {
+		t.Fatalf("Failed to sell stock: %v", err)
+	}
+}
+
+func TestStockTake_CountAndApprove(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsManager(t)
+	productID := seedStockedProduct(t, env, "ST-001", 20, 500)
+
+	id := openStockTake(t, env, cookies, TestCategoryID)
+	take := submitCounts(t, env, cookies, id, "", map[string]int{TestProductID: 97, productID: 25})
+
+	if take["status"] != "counting" || take["item_count"] != float64(2) || take["counted_count"] != float64(2) {
+		t.Fatalf("Unexpected stock take %v", take)
+	}
+	if take["variance_quantity"] != float64(2) || take["variance_value"] != float64(2500) {
+		t.Errorf("Expected variance 2 worth 2500, got %v worth %v", take["variance_quantity"], take["variance_value"])
+	}
+	if item := stockTakeItem(t, take, TestProductID); item["expected_stock"] != float64(100) || item["variance"] != float64(-3) {
+		t.Errorf("Unexpected item %v", item)
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/stock-takes/"+id+"/approve", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	take = ParseResponse(t, w)["data"].(map[string]interface{})
+	if take["status"] != "approved" || take["approved_at"] == nil {
+		t.Errorf("Expected an approved stock take, got %v", take)
+	}
+
+	if stock, _ := productStockAndCost(t, env, TestProductID); stock != 97 {
+		t.Errorf("Expected stock 97, got %d", stock)
+	}
+	if stock, _ := productStockAndCost(t, env, productID); stock != 25 {
+		t.Errorf("Expected stock 25, got %d", stock)
+	}
+
+	// Approval leaves an adjustment trail referencing the stock take
+	var movements, total int
+	if err := env.DB.QueryRow(`
+		SELECT COUNT(*), SUM(quantity) FROM stock_movements WHERE type = 'adjustment' AND reference_id = $1
+	`, id).Scan(&movements, &total); err != nil {
+		t.Fatalf("Failed to read stock movements: %v", err)
+	}
+	if movements != 2 || total != 2 {
+		t.Errorf("Expected 2 adjustments totalling 2, got %d totalling %d", movements, total)
+	}
+}
+
+func TestStockTake_SalesDuringCount(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsManager(t)
+	exact := seedStockedProduct(t, env, "ST-002", 50, 0)
+	short := seedStockedProduct(t, env, "ST-003", 50, 0)
+
+	id := openStockTake(t, env, cookies, TestCategoryID)
+
+	// Sold before being counted: the count is measured against the stock at count time
+	sellStock(t, env, exact, 5)
+	sellStock(t, env, short, 5)
+	take := submitCounts(t, env, cookies, id, "", map[string]int{exact: 45, short: 40})
+	if item := stockTakeItem(t, take, exact); item["expected_stock"] != float64(45) || item["variance"] != float64(0) {
+		t.Errorf("Expected no variance, got %v", item)
+	}
+
+	// Sold after being counted: approval keeps these sales
+	sellStock(t, env, exact, 2)
+	sellStock(t, env, short, 2)
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/stock-takes/"+id+"/approve", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	if stock, _ := productStockAndCost(t, env, exact); stock != 43 {
+		t.Errorf("Expected stock 43, got %d", stock)
+	}
+	if stock, _ := productStockAndCost(t, env, short); stock != 38 {
+		t.Errorf("Expected stock 38, got %d", stock)
+	}
+
+	var movements int
+	if err := env.DB.QueryRow(`SELECT COUNT(*) FROM stock_movements WHERE reference_id = $1`, id).Scan(&movements); err != nil {
+		t.Fatalf("Failed to read stock movements: %v", err)
+	}
+	if movements != 1 {
+		t.Errorf("Expected only the short product to be adjusted, got %d movements", movements)
+	}
+}
+
+func TestStockTake_MultipleDevices(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	manager := env.LoginAsManager(t)
+	cashier := env.LoginAsCashier(t)
+	productID := seedStockedProduct(t, env, "ST-004", 30, 0)
+
+	id := openStockTake(t, env, manager, "")
+
+	// Shelf and back room are counted on different devices and added up
+	submitCounts(t, env, cashier, id, "add", map[string]int{productID: 12})
+	take := submitCounts(t, env, manager, id, "add", map[string]int{productID: 15, TestProductID: 100})
+	if item := stockTakeItem(t, take, productID); item["counted_quantity"] != float64(27) {
+		t.Errorf("Expected added counts of 27, got %v", item["counted_quantity"])
+	}
+
+	// A recount replaces the previous count
+	take = submitCounts(t, env, cashier, id, "replace", map[string]int{productID: 28})
+	item := stockTakeItem(t, take, productID)
+	if item["counted_quantity"] != float64(28) || item["counted_by"] != TestCashierID {
+		t.Errorf("Expected a recount of 28 by the cashier, got %v", item)
+	}
+}
+
+func TestStockTake_RejectsInvalidCounts(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsManager(t)
+	id := openStockTake(t, env, cookies, TestCategoryID)
+
+	for _, body := range []map[string]interface{}{
+		{"items": []map[string]interface{}{{"product_id": GenerateUUID(), "quantity": 1}}},
+		{"items": []map[string]interface{}{{"product_id": TestProductID, "quantity": -1}}},
+		{"items": []map[string]interface{}{{"product_id": TestProductID, "quantity": 1}, {"product_id": TestProductID, "quantity": 2}}},
+		{"mode": "merge", "items": []map[string]interface{}{{"product_id": TestProductID, "quantity": 1}}},
+	} {
+		w := env.MakeRequest(t, http.MethodPost, "/api/v1/stock-takes/"+id+"/counts", body, cookies)
+		AssertStatus(t, w, http.StatusBadRequest)
+	}
+
+	// Nothing counted yet, so there is nothing to approve
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/stock-takes/"+id+"/approve", nil, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestStockTake_ClosedStockTakes(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsManager(t)
+	id := openStockTake(t, env, cookies, TestCategoryID)
+	submitCounts(t, env, cookies, id, "", map[string]int{TestProductID: 90})
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/stock-takes/"+id+"/cancel", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/stock-takes/"+id+"/counts", map[string]interface{}{
+		"items": []map[string]interface{}{{"product_id": TestProductID, "quantity": 80}},
+	}, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/stock-takes/"+id+"/approve", nil, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	if stock, _ := productStockAndCost(t, env, TestProductID); stock != 100 {
+		t.Errorf("Expected a cancelled stock take to leave stock at 100, got %d", stock)
+	}
+}
+
+func TestStockTake_OverlappingRejected(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+	storeID := openStockTake(t, env, cookies, "")
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/stock-takes", map[string]interface{}{"category_id": TestCategoryID}, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/stock-takes/"+storeID+"/cancel", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	openStockTake(t, env, cookies, TestCategoryID)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/stock-takes?status=counting", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	if data := ParseResponse(t, w)["data"].([]interface{}); len(data) != 1 {
+		t.Errorf("Expected 1 open stock take, got %d", len(data))
+	}
+}
+
+func TestStockTake_CashierCannotOpenOrApprove(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	id := openStockTake(t, env, env.LoginAsManager(t), TestCategoryID)
+
+	cookies := env.LoginAsCashier(t)
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/stock-takes", map[string]interface{}{}, cookies)
+	AssertStatus(t, w, http.StatusForbidden)
+
+	submitCounts(t, env, cookies, id, "", map[string]int{TestProductID: 99})
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/stock-takes/"+id+"/approve", nil, cookies)
+	AssertStatus(t, w, http.StatusForbidden)
+}
+
+func TestStockTake_ReplacesSetStockOperation(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	w := env.MakeRequest(t, http.MethodPatch, "/api/v1/products/"+TestProductID+"/stock", map[string]interface{}{
+		"operation": "set",
+		"quantity":  90,
+	}, env.LoginAsAdmin(t))
+	AssertStatus(t, w, http.StatusBadRequest)
+}
@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+// ============================================
+// Stock Transfer Tests
+// ============================================
+
+// stockStore puts stock of a product at a store
+func stockStore(t *testing.T, env *TestEnv, storeID, productID string, stock int) {
+	t.Helper()
+
+	if _, err := env.DB.Exec(`
+		INSERT INTO store_products (store_id, product_id, stock) VALUES ($1, $2, $3)
+		ON CONFLICT (store_id, product_id) DO UPDATE SET stock = EXCLUDED.stock
+	`, storeID, productID, stock); err != nil {
+		t.Fatalf("Failed to stock store: %v", err)
+	}
+}
+
+// requestTransfer requests a transfer of one product and returns its ID
+func requestTransfer(t *testing.T, env *TestEnv, cookies []*http.Cookie, fromStoreID, toStoreID, productID string, quantity int) string {
+	t.Helper()
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/stock-transfers", map[string]interface{}{
+		"from_store_id": fromStoreID,
+		"to_store_id":   toStoreID,
+		"items":         []map[string]interface{}{{"product_id": productID, "quantity": quantity}},
+	}, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+	return ParseResponse(t, w)["data"].(map[string]interface{})["id"].(string)
+}
+
+func TestStockTransfer_ShipAndReceiveWithDiscrepancy(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	admin := env.LoginAsAdmin(t)
+	source := createStore(t, env, admin, "JKT-01")
+	destination := createStore(t, env, admin, "BDG-01")
+	stockStore(t, env, source, TestProductID, 20)
+	if _, err := env.DB.Exec(`UPDATE products SET cost_price = 500 WHERE id = $1`, TestProductID); err != nil {
+		t.Fatalf("Failed to set cost: %v", err)
+	}
+
+	cookies := env.LoginAsManager(t)
+	id := requestTransfer(t, env, cookies, source, destination, TestProductID, 10)
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/stock-transfers/"+id+"/ship", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	transfer := ParseResponse(t, w)["data"].(map[string]interface{})
+	if transfer["status"] != "in_transit" || transfer["shipped_quantity"] != float64(10) || transfer["shipped_value"] != float64(5000) {
+		t.Fatalf("Unexpected shipped transfer %v", transfer)
+	}
+	if stock := storeStock(t, env, source, TestProductID); stock != 10 {
+		t.Errorf("Expected 10 left at the source store, got %d", stock)
+	}
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/reports/stock-transfers/in-transit", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	rows := ParseResponse(t, w)["data"].([]interface{})
+	if len(rows) != 1 || rows[0].(map[string]interface{})["value"] != float64(5000) {
+		t.Fatalf("Expected 5000 in transit, got %v", rows)
+	}
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/stock-transfers/"+id+"/receive", map[string]interface{}{
+		"items": []map[string]interface{}{{"product_id": TestProductID, "quantity": 11}},
+	}, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+	if stock := storeStock(t, env, destination, TestProductID); stock != 0 {
+		t.Errorf("Expected nothing received beyond the shipment, got %d", stock)
+	}
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/stock-transfers/"+id+"/receive", map[string]interface{}{
+		"items": []map[string]interface{}{{"product_id": TestProductID, "quantity": 9}},
+	}, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	transfer = ParseResponse(t, w)["data"].(map[string]interface{})
+	item := transfer["items"].([]interface{})[0].(map[string]interface{})
+	if transfer["status"] != "received" || item["discrepancy"] != float64(-1) || item["discrepancy_value"] != float64(-500) {
+		t.Errorf("Expected a received transfer with one missing unit, got %v", transfer)
+	}
+	if stock := storeStock(t, env, destination, TestProductID); stock != 9 {
+		t.Errorf("Expected 9 at the destination store, got %d", stock)
+	}
+
+	var out, in int
+	err := env.DB.QueryRow(`
+		SELECT COALESCE(SUM(quantity) FILTER (WHERE type = 'transfer_out'), 0),
+		       COALESCE(SUM(quantity) FILTER (WHERE type = 'transfer_in'), 0)
+		FROM stock_movements WHERE reference_id = $1
+	`, id).Scan(&out, &in)
+	if err != nil {
+		t.Fatalf("Failed to read movements: %v", err)
+	}
+	if out != -10 || in != 9 {
+		t.Errorf("Expected movements of -10 out and 9 in, got %d and %d", out, in)
+	}
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/reports/stock-transfers/in-transit", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	if rows := ParseResponse(t, w)["data"].([]interface{}); len(rows) != 0 {
+		t.Errorf("Expected nothing in transit after receiving, got %v", rows)
+	}
+}
+
+func TestStockTransfer_ShipNeedsSourceStock(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	admin := env.LoginAsAdmin(t)
+	source := createStore(t, env, admin, "JKT-01")
+	destination := createStore(t, env, admin, "BDG-01")
+	stockStore(t, env, source, TestProductID, 5)
+
+	id := requestTransfer(t, env, admin, source, destination, TestProductID, 10)
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/stock-transfers/"+id+"/ship", nil, admin)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/stock-transfers/"+id+"/ship", map[string]interface{}{
+		"items": []map[string]interface{}{{"product_id": TestProductID, "quantity": 11}},
+	}, admin)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	if stock := storeStock(t, env, source, TestProductID); stock != 5 {
+		t.Errorf("Expected source stock to stay 5, got %d", stock)
+	}
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/stock-transfers/"+id+"/ship", map[string]interface{}{
+		"items": []map[string]interface{}{{"product_id": TestProductID, "quantity": 4}},
+	}, admin)
+	AssertStatus(t, w, http.StatusOK)
+	if shipped := ParseResponse(t, w)["data"].(map[string]interface{})["shipped_quantity"]; shipped != float64(4) {
+		t.Errorf("Expected 4 shipped, got %v", shipped)
+	}
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/stock-transfers/"+id+"/cancel", nil, admin)
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestStockTransfer_StoresActOnTheirSide(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	admin := env.LoginAsAdmin(t)
+	source := createStore(t, env, admin, "JKT-01")
+	destination := createStore(t, env, admin, "BDG-01")
+	stockStore(t, env, source, TestProductID, 10)
+
+	w := loginAtStore(t, env, "manager@test.local", "Manager123!", destination)
+	AssertStatus(t, w, http.StatusOK)
+	atDestination := w.Result().Cookies()
+
+	// Without a destination the transfer is requested for the signed-in store
+	id := requestTransfer(t, env, atDestination, source, "", TestProductID, 3)
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/stock-transfers/"+id, nil, atDestination)
+	AssertStatus(t, w, http.StatusOK)
+	if to := ParseResponse(t, w)["data"].(map[string]interface{})["to_store_id"]; to != destination {
+		t.Fatalf("Expected destination %s, got %v", destination, to)
+	}
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/stock-transfers/"+id+"/ship", nil, atDestination)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = loginAtStore(t, env, "manager@test.local", "Manager123!", source)
+	AssertStatus(t, w, http.StatusOK)
+	atSource := w.Result().Cookies()
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/stock-transfers/"+id+"/ship", nil, atSource)
+	AssertStatus(t, w, http.StatusOK)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/stock-transfers/"+id+"/receive", nil, atSource)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/stock-transfers/"+id+"/receive", nil, atDestination)
+	AssertStatus(t, w, http.StatusOK)
+	if stock := storeStock(t, env, destination, TestProductID); stock != 3 {
+		t.Errorf("Expected 3 at the destination store, got %d", stock)
+	}
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/stock-transfers?status=received", nil, atSource)
+	AssertStatus(t, w, http.StatusOK)
+	if total := ParseResponse(t, w)["meta"].(map[string]interface{})["total"]; total != float64(1) {
+		t.Errorf("Expected the source store to see 1 received transfer, got %v", total)
+	}
+}
+
+func TestStockTransfer_KeepsProductOutOfPurge(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	admin := env.LoginAsAdmin(t)
+	source := createStore(t, env, admin, "JKT-01")
+	destination := createStore(t, env, admin, "BDG-01")
+	stockStore(t, env, source, TestProductID, 20)
+	requestTransfer(t, env, env.LoginAsManager(t), source, destination, TestProductID, 10)
+
+	w := env.MakeRequest(t, http.MethodDelete, "/api/v1/products/"+TestProductID, nil, admin)
+	AssertStatus(t, w, http.StatusOK)
+
+	// The transfer line still refers to the product, so the purge leaves it in the trash
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/trash/purge", map[string]interface{}{"older_than_days": 0}, admin)
+	AssertStatus(t, w, http.StatusOK)
+	purged := ParseResponse(t, w)["data"].(map[string]interface{})["purged"].(map[string]interface{})
+	if purged["products"] != float64(0) {
+		t.Errorf("Expected the transferred product to be kept, got %v", purged)
+	}
+}
@@ -0,0 +1,319 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// ============================================
+// Store Tests
+// ============================================
+
+// createStore creates an active store as admin
+func createStore(t *testing.T, env *TestEnv, cookies []*http.Cookie, code string) string {
+	t.Helper()
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/stores", map[string]interface{}{
+		"code": code,
+		"name": "Store " + code,
+	}, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+	return ParseResponse(t, w)["data"].(map[string]interface{})["id"].(string)
+}
+
+// loginAtStore signs in to a store and returns the response recorder
+func loginAtStore(t *testing.T, env *TestEnv, email, password, storeID string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]string{
+		"email":    email,
+		"password": password,
+		"store_id": storeID,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	env.Engine.ServeHTTP(w, req)
+	return w
+}
+
+// storeStock reads a product's stock at a store
+func storeStock(t *testing.T, env *TestEnv, storeID, productID string) int {
+	t.Helper()
+
+	var stock int
+	err := env.DB.QueryRow(`SELECT stock FROM store_products WHERE store_id = $1 AND product_id = $2`, storeID, productID).Scan(&stock)
+	if err != nil {
+		t.Fatalf("Failed to read store stock: %v", err)
+	}
+	return stock
+}
+
+// sellAtStore sells one product through the transactions endpoint and returns the transaction
+func sellAtStore(t *testing.T, env *TestEnv, cookies []*http.Cookie, productID string, quantity int) map[string]interface{} {
+	t.Helper()
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", map[string]interface{}{
+		"payment_method": "cash",
+		"items":          []map[string]interface{}{{"product_id": productID, "quantity": quantity}},
+	}, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+	return ParseResponse(t, w)["data"].(map[string]interface{})
+}
+
+func TestStore_CreateRequiresAdmin(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	admin := env.LoginAsAdmin(t)
+	id := createStore(t, env, admin, "jkt-01")
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/stores/"+id, nil, admin)
+	AssertStatus(t, w, http.StatusOK)
+	if code := ParseResponse(t, w)["data"].(map[string]interface{})["code"]; code != "JKT-01" {
+		t.Errorf("Expected code JKT-01, got %v", code)
+	}
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/stores", map[string]interface{}{"code": "JKT-01", "name": "Again"}, admin)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/stores", map[string]interface{}{"code": "BDG-01", "name": "Bandung"}, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusForbidden)
+}
+
+func TestStore_SaleUsesStoreStockAndPrice(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	storeID := createStore(t, env, env.LoginAsAdmin(t), "JKT-01")
+
+	w := loginAtStore(t, env, "manager@test.local", "Manager123!", storeID)
+	AssertStatus(t, w, http.StatusOK)
+	manager := w.Result().Cookies()
+
+	w = env.MakeRequest(t, http.MethodPatch, "/api/v1/products/"+TestProductID+"/stock", map[string]interface{}{
+		"quantity": 10, "operation": "add",
+	}, manager)
+	AssertStatus(t, w, http.StatusOK)
+
+	w = env.MakeRequest(t, http.MethodPut, "/api/v1/stores/"+storeID+"/prices", map[string]interface{}{
+		"items": []map[string]interface{}{{"product_id": TestProductID, "price": 8000}},
+	}, manager)
+	AssertStatus(t, w, http.StatusOK)
+
+	w = loginAtStore(t, env, "cashier@test.local", "Cashier123!", storeID)
+	AssertStatus(t, w, http.StatusOK)
+	if data := ParseResponse(t, w)["data"].(map[string]interface{}); data["store_id"] != storeID {
+		t.Fatalf("Expected login at store %s, got %v", storeID, data["store_id"])
+	}
+
+	transaction := sellAtStore(t, env, w.Result().Cookies(), TestProductID, 2)
+	if transaction["subtotal"] != float64(16000) || transaction["store_id"] != storeID {
+		t.Errorf("Expected store sale with subtotal 16000, got %v at %v", transaction["subtotal"], transaction["store_id"])
+	}
+
+	if stock := storeStock(t, env, storeID, TestProductID); stock != 8 {
+		t.Errorf("Expected store stock 8, got %d", stock)
+	}
+	if stock, _ := productStockAndCost(t, env, TestProductID); stock != 100 {
+		t.Errorf("Expected stock outside stores to stay 100, got %d", stock)
+	}
+}
+
+func TestStore_ListsAreScopedToSignedInStore(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	admin := env.LoginAsAdmin(t)
+	storeID := createStore(t, env, admin, "JKT-01")
+	if _, err := env.DB.Exec(`INSERT INTO store_products (store_id, product_id, stock) VALUES ($1, $2, 5)`, storeID, TestProductID); err != nil {
+		t.Fatalf("Failed to stock store: %v", err)
+	}
+
+	w := loginAtStore(t, env, "cashier@test.local", "Cashier123!", storeID)
+	AssertStatus(t, w, http.StatusOK)
+	atStore := w.Result().Cookies()
+	sellAtStore(t, env, atStore, TestProductID, 1)
+	sellAtStore(t, env, env.LoginAsCashier(t), TestProductID, 1)
+
+	totals := map[string]float64{
+		"/api/v1/transactions":                     2,
+		"/api/v1/transactions?store_id=" + storeID: 1,
+	}
+	for path, expected := range totals {
+		w = env.MakeRequest(t, http.MethodGet, path, nil, admin)
+		AssertStatus(t, w, http.StatusOK)
+		if total := ParseResponse(t, w)["meta"].(map[string]interface{})["total"]; total != expected {
+			t.Errorf("%s: expected %v transactions, got %v", path, expected, total)
+		}
+	}
+
+	// The store's cashier cannot widen the scope with a query parameter
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/transactions?store_id=", nil, atStore)
+	AssertStatus(t, w, http.StatusOK)
+	if total := ParseResponse(t, w)["meta"].(map[string]interface{})["total"]; total != float64(1) {
+		t.Errorf("Expected the store cashier to see 1 transaction, got %v", total)
+	}
+}
+
+func TestStore_LoginRespectsAssignments(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	admin := env.LoginAsAdmin(t)
+	assigned := createStore(t, env, admin, "JKT-01")
+	other := createStore(t, env, admin, "BDG-01")
+
+	w := env.MakeRequest(t, http.MethodPut, "/api/v1/users/"+TestCashierID+"/stores", map[string]interface{}{
+		"store_ids": []string{assigned},
+	}, admin)
+	AssertStatus(t, w, http.StatusOK)
+
+	w = loginAtStore(t, env, "cashier@test.local", "Cashier123!", other)
+	AssertStatus(t, w, http.StatusUnauthorized)
+
+	w = loginAtStore(t, env, "cashier@test.local", "Cashier123!", "")
+	AssertStatus(t, w, http.StatusOK)
+	cashier := w.Result().Cookies()
+	if storeID := ParseResponse(t, w)["data"].(map[string]interface{})["store_id"]; storeID != assigned {
+		t.Errorf("Expected the assigned store %s by default, got %v", assigned, storeID)
+	}
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/auth/store", map[string]interface{}{"store_id": other}, cashier)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	w = env.MakeRequest(t, http.MethodPut, "/api/v1/stores/"+assigned, map[string]interface{}{"is_active": false}, admin)
+	AssertStatus(t, w, http.StatusOK)
+
+	w = loginAtStore(t, env, "cashier@test.local", "Cashier123!", assigned)
+	AssertStatus(t, w, http.StatusUnauthorized)
+}
+
+func TestStore_SwitchStore(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	admin := env.LoginAsAdmin(t)
+	first := createStore(t, env, admin, "JKT-01")
+	second := createStore(t, env, admin, "BDG-01")
+	if _, err := env.DB.Exec(`INSERT INTO store_products (store_id, product_id, stock) VALUES ($1, $3, 5), ($2, $3, 7)`, first, second, TestProductID); err != nil {
+		t.Fatalf("Failed to stock stores: %v", err)
+	}
+
+	w := loginAtStore(t, env, "manager@test.local", "Manager123!", first)
+	AssertStatus(t, w, http.StatusOK)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/auth/store", map[string]interface{}{"store_id": second}, w.Result().Cookies())
+	AssertStatus(t, w, http.StatusOK)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/products/"+TestProductID, nil, w.Result().Cookies())
+	AssertStatus(t, w, http.StatusOK)
+	if stock := ParseResponse(t, w)["data"].(map[string]interface{})["stock"]; stock != float64(7) {
+		t.Errorf("Expected stock 7 at the second store, got %v", stock)
+	}
+}
+
+func TestStore_ClearPriceOverride(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	admin := env.LoginAsAdmin(t)
+	storeID := createStore(t, env, admin, "JKT-01")
+	path := "/api/v1/stores/" + storeID + "/prices"
+
+	w := env.MakeRequest(t, http.MethodPut, path, map[string]interface{}{
+		"items": []map[string]interface{}{{"product_id": TestProductID, "price": 8000}},
+	}, admin)
+	AssertStatus(t, w, http.StatusOK)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/products/"+TestProductID+"?store_id="+storeID, nil, admin)
+	AssertStatus(t, w, http.StatusOK)
+	if price := ParseResponse(t, w)["data"].(map[string]interface{})["price"]; price != float64(8000) {
+		t.Fatalf("Expected store price 8000, got %v", price)
+	}
+
+	w = env.MakeRequest(t, http.MethodPut, path, map[string]interface{}{
+		"items": []map[string]interface{}{{"product_id": TestProductID, "price": nil}},
+	}, admin)
+	AssertStatus(t, w, http.StatusOK)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/products/"+TestProductID+"?store_id="+storeID, nil, admin)
+	AssertStatus(t, w, http.StatusOK)
+	if price := ParseResponse(t, w)["data"].(map[string]interface{})["price"]; price != float64(10000) {
+		t.Errorf("Expected the product price 10000 again, got %v", price)
+	}
+
+	w = env.MakeRequest(t, http.MethodPut, path, map[string]interface{}{
+		"items": []map[string]interface{}{{"product_id": "missing", "price": 1000}},
+	}, admin)
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestStore_VariantSaleUsesStoreVariantStock(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	storeID := createStore(t, env, env.LoginAsAdmin(t), "JKT-01")
+	productID := seedCheckoutProduct(t, env)
+	ids := seedProductOptions(t, env, productID)
+
+	w := loginAtStore(t, env, "manager@test.local", "Manager123!", storeID)
+	AssertStatus(t, w, http.StatusOK)
+	w = env.MakeRequest(t, http.MethodPatch, "/api/v1/products/"+productID+"/variants/"+ids["Regular"]+"/stock", map[string]interface{}{
+		"quantity": 3, "operation": "add",
+	}, w.Result().Cookies())
+	AssertStatus(t, w, http.StatusOK)
+
+	w = loginAtStore(t, env, "cashier@test.local", "Cashier123!", storeID)
+	AssertStatus(t, w, http.StatusOK)
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", map[string]interface{}{
+		"payment_method": "cash",
+		"items": []map[string]interface{}{{
+			"product_id": productID, "variant_id": ids["Regular"], "modifier_ids": []string{ids["Normal Sugar"]}, "quantity": 2,
+		}},
+	}, w.Result().Cookies())
+	AssertStatus(t, w, http.StatusCreated)
+	transactionID := ParseResponse(t, w)["data"].(map[string]interface{})["id"].(string)
+
+	variantStock := func() (atStore, global int) {
+		t.Helper()
+		if err := env.DB.QueryRow(`SELECT stock FROM store_product_variants WHERE store_id = $1 AND variant_id = $2`,
+			storeID, ids["Regular"]).Scan(&atStore); err != nil {
+			t.Fatalf("Failed to read store variant stock: %v", err)
+		}
+		if err := env.DB.QueryRow(`SELECT stock FROM product_variants WHERE id = $1`, ids["Regular"]).Scan(&global); err != nil {
+			t.Fatalf("Failed to read variant stock: %v", err)
+		}
+		return atStore, global
+	}
+	if atStore, global := variantStock(); atStore != 1 || global != 10 {
+		t.Errorf("Expected store variant stock 1 and 10 outside stores, got %d and %d", atStore, global)
+	}
+
+	// The refund puts the variant back at the store; both are recorded as movements
+	w = env.MakeRequest(t, http.MethodPatch, "/api/v1/transactions/"+transactionID+"/status", map[string]interface{}{
+		"status": "refunded",
+	}, env.LoginAsAdmin(t))
+	AssertStatus(t, w, http.StatusOK)
+	if atStore, _ := variantStock(); atStore != 3 {
+		t.Errorf("Expected store variant stock 3 after the refund, got %d", atStore)
+	}
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/products/stock-movements?product_id="+productID, nil, env.LoginAsAdmin(t))
+	AssertStatus(t, w, http.StatusOK)
+	changes := map[string]float64{}
+	for _, m := range ParseResponse(t, w)["data"].([]interface{}) {
+		movement := m.(map[string]interface{})
+		if movement["variant_id"] != ids["Regular"] || movement["store_id"] != storeID {
+			t.Errorf("Expected a movement of the variant at the store, got %v", movement)
+		}
+		changes[movement["type"].(string)] += movement["quantity_change"].(float64)
+	}
+	if changes["adjustment"] != 3 || changes["sale"] != -2 || changes["return"] != 2 {
+		t.Errorf("Expected adjustment 3, sale -2 and return 2, got %v", changes)
+	}
+}
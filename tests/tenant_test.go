@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+// ============================================
+// Tenant Tests
+// ============================================
+
+// registerBusiness registers the admin of a new business and returns their cookies and tenant ID
+func registerBusiness(t *testing.T, env *TestEnv, email, businessName string) ([]*http.Cookie, string) {
+	t.Helper()
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/auth/register", map[string]interface{}{
+		"email":        email,
+		"password":     "Owner123!",
+		"name":         "Business Owner",
+		"businessName": businessName,
+	}, nil)
+	AssertStatus(t, w, http.StatusCreated)
+
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	if role := data["user"].(map[string]interface{})["role"]; role != "admin" {
+		t.Fatalf("Expected the business owner to be an admin, got %v", role)
+	}
+	tenantID, _ := data["tenant_id"].(string)
+	if tenantID == "" || tenantID == "default" {
+		t.Fatalf("Expected a new tenant, got %q", tenantID)
+	}
+	return w.Result().Cookies(), tenantID
+}
+
+func TestTenant_BusinessesCannotSeeEachOthersData(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies, _ := registerBusiness(t, env, "owner@cafe.local", "Cafe")
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/products/"+TestProductID, nil, cookies)
+	AssertStatus(t, w, http.StatusNotFound)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/categories/"+TestCategoryID, nil, cookies)
+	AssertStatus(t, w, http.StatusNotFound)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/products", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	if total := ParseResponse(t, w)["meta"].(map[string]interface{})["total"]; total != float64(0) {
+		t.Errorf("Expected a new business to have no products, got %v", total)
+	}
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/categories", map[string]interface{}{
+		"name": "Drinks", "slug": "drinks", "is_active": true,
+	}, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+	categoryID := ParseResponse(t, w)["data"].(map[string]interface{})["id"].(string)
+
+	admin := env.LoginAsAdmin(t)
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/categories/"+categoryID, nil, admin)
+	AssertStatus(t, w, http.StatusNotFound)
+
+	// Signing in again keeps the user in their business
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/auth/login", map[string]string{
+		"email": "owner@cafe.local", "password": "Owner123!",
+	}, nil)
+	AssertStatus(t, w, http.StatusOK)
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/categories/"+categoryID, nil, w.Result().Cookies())
+	AssertStatus(t, w, http.StatusOK)
+}
+
+func TestTenant_SKUsAndSlugsAreUniquePerTenant(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies, _ := registerBusiness(t, env, "owner@bakery.local", "Bakery")
+
+	// The default tenant already uses this slug and SKU
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/categories", map[string]interface{}{
+		"name": "Test Category", "slug": "test-category", "is_active": true,
+	}, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+	categoryID := ParseResponse(t, w)["data"].(map[string]interface{})["id"].(string)
+
+	product := map[string]interface{}{
+		"category_id": categoryID,
+		"sku":         "TEST-001",
+		"name":        "Sourdough",
+		"price":       45000,
+		"stock":       10,
+		"is_active":   true,
+	}
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/products", product, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/products", product, cookies)
+	if w.Code == http.StatusCreated {
+		t.Error("Expected a duplicate SKU within the tenant to be rejected")
+	}
+
+	// Another tenant's category cannot be used either
+	product["sku"] = "TEST-002"
+	product["category_id"] = TestCategoryID
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/products", product, cookies)
+	if w.Code == http.StatusCreated {
+		t.Error("Expected another tenant's category to be rejected")
+	}
+}
+
+func TestTenant_RegisterWithoutBusinessJoinsDefaultTenant(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/auth/register", map[string]string{
+		"email":    "cashier2@test.local",
+		"password": "Cashier123!",
+		"name":     "Second Cashier",
+	}, nil)
+	AssertStatus(t, w, http.StatusCreated)
+
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	if data["tenant_id"] != "default" {
+		t.Errorf("Expected the default tenant, got %v", data["tenant_id"])
+	}
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/products/"+TestProductID, nil, w.Result().Cookies())
+	AssertStatus(t, w, http.StatusOK)
+}
+
+func TestTenant_PurgeOnlyTouchesOwnTrash(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	admin := env.LoginAsAdmin(t)
+	w := env.MakeRequest(t, http.MethodDelete, "/api/v1/products/"+TestProductID, nil, admin)
+	AssertStatus(t, w, http.StatusOK)
+
+	cookies, _ := registerBusiness(t, env, "owner@cafe.local", "Cafe")
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/trash/purge", map[string]interface{}{"older_than_days": 0}, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	if purged := ParseResponse(t, w)["data"].(map[string]interface{})["purged"].(map[string]interface{}); purged["products"] != float64(0) {
+		t.Errorf("Expected another business's purge to leave the product alone, got %v", purged)
+	}
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/products/"+TestProductID+"/restore", nil, admin)
+	AssertStatus(t, w, http.StatusOK)
+}
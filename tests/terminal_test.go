@@ -0,0 +1,261 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ilramdhan/pos-api/internal/models"
+)
+
+// ============================================
+// Terminal Tests
+// ============================================
+
+// registerTerminal registers a terminal at a new store as admin and returns its key
+func registerTerminal(t *testing.T, env *TestEnv, code string) string {
+	t.Helper()
+
+	admin := env.LoginAsAdmin(t)
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/terminals", map[string]interface{}{
+		"store_id": createStore(t, env, admin, code),
+		"name":     "Till 1",
+	}, admin)
+	AssertStatus(t, w, http.StatusCreated)
+	return ParseResponse(t, w)["data"].(map[string]interface{})["key"].(string)
+}
+
+// setPIN sets a user's PIN after a password sign-in
+func setPIN(t *testing.T, env *TestEnv, email, password, pin string) {
+	t.Helper()
+
+	w := env.MakeRequest(t, http.MethodPut, "/api/v1/auth/me/pin", map[string]interface{}{
+		"current_password": password, "pin": pin,
+	}, env.LoginAs(t, email, password))
+	AssertStatus(t, w, http.StatusOK)
+}
+
+// pinLogin signs in to a terminal with a PIN
+func pinLogin(env *TestEnv, key, userID, pin string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]string{"user_id": userID, "pin": pin})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/pin-login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Terminal-Key", key)
+
+	w := httptest.NewRecorder()
+	env.Engine.ServeHTTP(w, req)
+	return w
+}
+
+// heartbeat sends a terminal heartbeat
+func heartbeat(env *TestEnv, key string, body map[string]interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/terminal/heartbeat", bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Terminal-Key", key)
+
+	w := httptest.NewRecorder()
+	env.Engine.ServeHTTP(w, req)
+	return w
+}
+
+// systemHealth returns the detailed system health and the terminal in it with the id
+func systemHealth(t *testing.T, env *TestEnv, cookies []*http.Cookie, terminalID string) (map[string]interface{}, map[string]interface{}) {
+	t.Helper()
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/system/health/detailed", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	health := ParseResponse(t, w)["data"].(map[string]interface{})
+	for _, terminal := range health["terminals"].([]interface{}) {
+		if terminal := terminal.(map[string]interface{}); terminal["id"] == terminalID {
+			return health, terminal
+		}
+	}
+	t.Fatalf("Terminal %s not in the health report", terminalID)
+	return nil, nil
+}
+
+func TestTerminal_SwitchingUsersKeepsHeldCart(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	key := registerTerminal(t, env, "T01")
+	setPIN(t, env, "cashier@test.local", "Cashier123!", "1234")
+	setPIN(t, env, "manager@test.local", "Manager123!", "567890")
+
+	// The terminal lists who can sign in to it
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/terminal/users", nil)
+	req.Header.Set("X-Terminal-Key", key)
+	w := httptest.NewRecorder()
+	env.Engine.ServeHTTP(w, req)
+	AssertStatus(t, w, http.StatusOK)
+	if users := ParseResponse(t, w)["data"].([]interface{}); len(users) != 2 {
+		t.Errorf("Expected 2 users with a PIN, got %d", len(users))
+	}
+
+	w = pinLogin(env, key, TestCashierID, "1234")
+	AssertStatus(t, w, http.StatusOK)
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	if data["terminal_id"] == "" || data["token"].(map[string]interface{})["expires_in"].(float64) > 3600 {
+		t.Errorf("Expected a short-lived terminal session, got %v", data)
+	}
+	cashier := w.Result().Cookies()
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/pos/hold", map[string]interface{}{
+		"customer_name": "Table 4",
+		"items":         []map[string]interface{}{{"product_id": TestProductID, "quantity": 2}},
+	}, cashier)
+	AssertStatus(t, w, http.StatusCreated)
+
+	// PIN sessions stay at the terminal's store
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/auth/store", map[string]interface{}{
+		"store_id": GenerateUUID(),
+	}, cashier)
+	AssertStatus(t, w, http.StatusForbidden)
+
+	// The next user on the terminal sees the cart
+	w = pinLogin(env, key, TestManagerID, "567890")
+	AssertStatus(t, w, http.StatusOK)
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/pos/held", nil, w.Result().Cookies())
+	AssertStatus(t, w, http.StatusOK)
+	held := ParseResponse(t, w)["data"].([]interface{})
+	if len(held) != 1 || held[0].(map[string]interface{})["items_count"].(float64) != 1 {
+		t.Fatalf("Expected the held cart on the terminal, got %v", held)
+	}
+
+	// Carts held on a terminal are not mixed with those held elsewhere
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/pos/held", nil, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusOK)
+	if held := ParseResponse(t, w)["data"].([]interface{}); len(held) != 0 {
+		t.Errorf("Expected no carts outside the terminal, got %d", len(held))
+	}
+}
+
+func TestTerminal_WrongPINsLockTheUser(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	key := registerTerminal(t, env, "T02")
+	setPIN(t, env, "cashier@test.local", "Cashier123!", "1234")
+
+	for i := 0; i < env.Config.Terminal.PINMaxAttempts; i++ {
+		AssertStatus(t, pinLogin(env, key, TestCashierID, "9999"), http.StatusUnauthorized)
+	}
+	AssertStatus(t, pinLogin(env, key, TestCashierID, "1234"), http.StatusTooManyRequests)
+
+	// Password sign-in is not affected
+	env.LoginAsCashier(t)
+}
+
+func TestTerminal_PINCannotBypassRequiredMFA(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	key := registerTerminal(t, env, "T05")
+	setPIN(t, env, "manager@test.local", "Manager123!", "567890")
+	AssertStatus(t, pinLogin(env, key, TestManagerID, "567890"), http.StatusOK)
+
+	// Once the role requires 2FA, the PIN no longer gets a session
+	w := env.MakeRequest(t, http.MethodPut, "/api/v1/roles/manager", map[string]interface{}{
+		"require_mfa": true,
+	}, env.LoginAsAdmin(t))
+	AssertStatus(t, w, http.StatusOK)
+
+	w = pinLogin(env, key, TestManagerID, "567890")
+	AssertStatus(t, w, http.StatusForbidden)
+	if token := ParseResponse(t, w)["data"]; token != nil {
+		t.Errorf("Expected no session, got %v", token)
+	}
+}
+
+func TestTerminal_KeyIsRequired(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	setPIN(t, env, "cashier@test.local", "Cashier123!", "1234")
+	AssertStatus(t, pinLogin(env, "not-a-terminal", TestCashierID, "1234"), http.StatusUnauthorized)
+
+	key := registerTerminal(t, env, "T03")
+	admin := env.LoginAsAdmin(t)
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/terminals", nil, admin)
+	AssertStatus(t, w, http.StatusOK)
+	id := ParseResponse(t, w)["data"].([]interface{})[0].(map[string]interface{})["id"].(string)
+
+	w = env.MakeRequest(t, http.MethodPut, "/api/v1/terminals/"+id, map[string]interface{}{"is_active": false}, admin)
+	AssertStatus(t, w, http.StatusOK)
+	AssertStatus(t, pinLogin(env, key, TestCashierID, "1234"), http.StatusUnauthorized)
+
+	// Only users allowed to manage terminals can register them
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/terminals", nil, env.LoginAsManager(t))
+	AssertStatus(t, w, http.StatusForbidden)
+}
+
+func TestTerminal_HeartbeatsReportHealth(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	admin := env.LoginAsAdmin(t)
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/terminals", map[string]interface{}{
+		"store_id": createStore(t, env, admin, "HB01"),
+		"name":     "Kitchen Display",
+		"type":     "kds",
+	}, admin)
+	AssertStatus(t, w, http.StatusCreated)
+	created := ParseResponse(t, w)["data"].(map[string]interface{})
+	id, key := created["id"].(string), created["key"].(string)
+
+	// A terminal that never reported in is offline; the database answers
+	health, terminal := systemHealth(t, env, admin, id)
+	if terminal["status"] != "offline" || terminal["type"] != "kds" {
+		t.Errorf("Expected an offline kds terminal, got %v", terminal)
+	}
+	if db := health["database"].(map[string]interface{}); db["status"] != "ok" {
+		t.Errorf("Expected the database to be ok, got %v", db)
+	}
+
+	w = heartbeat(env, key, map[string]interface{}{"app_version": "2.4.1", "latency_ms": 42})
+	AssertStatus(t, w, http.StatusOK)
+	if next := ParseResponse(t, w)["data"].(map[string]interface{})["next_heartbeat_in"]; next != float64(env.Config.Terminal.HeartbeatSeconds) {
+		t.Errorf("Expected the next heartbeat in %d seconds, got %v", env.Config.Terminal.HeartbeatSeconds, next)
+	}
+
+	health, terminal = systemHealth(t, env, admin, id)
+	if terminal["status"] != "online" || terminal["app_version"] != "2.4.1" || terminal["latency_ms"] != float64(42) {
+		t.Errorf("Expected the heartbeat in the health report, got %v", terminal)
+	}
+	if online := health["terminals_online"]; online != float64(1) {
+		t.Errorf("Expected one terminal online, got %v", online)
+	}
+
+	// Missed heartbeats take it offline
+	if _, err := env.DB.Exec(`UPDATE terminals SET last_seen_at = $1 WHERE id = $2`, time.Now().Add(-time.Hour), id); err != nil {
+		t.Fatalf("Failed to age the heartbeat: %v", err)
+	}
+	if _, terminal = systemHealth(t, env, admin, id); terminal["status"] != "offline" {
+		t.Errorf("Expected the terminal to be offline, got %v", terminal["status"])
+	}
+
+	AssertStatus(t, heartbeat(env, "not-a-key", map[string]interface{}{}), http.StatusUnauthorized)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/system/health/detailed", nil, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusForbidden)
+
+	// The report needs its own permission, not the one to manage terminals
+	w = env.MakeRequest(t, http.MethodPut, "/api/v1/roles/manager", map[string]interface{}{
+		"permissions": []string{models.PermTerminalsManage},
+	}, admin)
+	AssertStatus(t, w, http.StatusOK)
+	manager := env.LoginAsManager(t)
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/system/health/detailed", nil, manager)
+	AssertStatus(t, w, http.StatusForbidden)
+
+	w = env.MakeRequest(t, http.MethodPut, "/api/v1/roles/manager", map[string]interface{}{
+		"permissions": []string{models.PermSystemHealth},
+	}, admin)
+	AssertStatus(t, w, http.StatusOK)
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/system/health/detailed", nil, manager)
+	AssertStatus(t, w, http.StatusOK)
+}
@@ -380,3 +380,50 @@ func TestTransactionCreate_VerifyStockDeduction(t *testing.T) {
 		t.Errorf("Expected stock %d after transaction, got %d", expectedStock, newStock)
 	}
 }
+
+func TestTransactionCancel_RestoresStockOnce(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	productID := seedCheckoutProduct(t, env)
+	body := map[string]interface{}{
+		"payment_method": "cash",
+		"items":          []map[string]interface{}{{"product_id": productID, "quantity": 2}},
+	}
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, env.LoginAsCashier(t))
+	AssertStatus(t, w, http.StatusCreated)
+	transactionID := ParseResponse(t, w)["data"].(map[string]interface{})["id"].(string)
+
+	productStock := func() int {
+		var stock int
+		if err := env.DB.QueryRow(`SELECT stock FROM products WHERE id = $1`, productID).Scan(&stock); err != nil {
+			t.Fatalf("Failed to read product stock: %v", err)
+		}
+		return stock
+	}
+	if stock := productStock(); stock != 48 {
+		t.Fatalf("Expected stock 48 after the sale, got %d", stock)
+	}
+
+	// The sale is in the product's movement ledger
+	var quantity, balance float64
+	err := env.DB.QueryRow(`SELECT quantity, balance_after FROM stock_movements WHERE product_id = $1 AND type = 'sale' AND reference_id = $2`,
+		productID, transactionID).Scan(&quantity, &balance)
+	if err != nil || quantity != -2 || balance != 48 {
+		t.Errorf("Expected a sale movement of -2 leaving 48, got %v %v (%v)", quantity, balance, err)
+	}
+
+	manager := env.LoginAsManager(t)
+	w = env.MakeRequest(t, http.MethodPatch, "/api/v1/transactions/"+transactionID+"/status",
+		map[string]interface{}{"status": "cancelled"}, manager)
+	AssertStatus(t, w, http.StatusOK)
+
+	// A second cancel is refused and puts nothing back
+	w = env.MakeRequest(t, http.MethodPatch, "/api/v1/transactions/"+transactionID+"/status",
+		map[string]interface{}{"status": "cancelled"}, manager)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	if stock := productStock(); stock != 50 {
+		t.Errorf("Expected stock back at 50, got %d", stock)
+	}
+}
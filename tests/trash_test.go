@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+// ============================================
+// Soft Delete & Trash Tests
+// ============================================
+
+func TestProductDelete_HiddenAndRestorable(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+
+	w := env.MakeRequest(t, http.MethodDelete, "/api/v1/products/"+TestProductID, nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/products/"+TestProductID, nil, cookies)
+	AssertStatus(t, w, http.StatusNotFound)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/products", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	if products := ParseResponse(t, w)["data"]; products != nil && len(products.([]interface{})) != 0 {
+		t.Errorf("Expected deleted product to be excluded from list, got %v", products)
+	}
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/products/"+TestProductID+"/restore", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/products/"+TestProductID, nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+}
+
+func TestCustomerDelete_KeepsTransactionHistory(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+	checkout(t, env, seedCheckoutProduct(t, env))
+
+	w := env.MakeRequest(t, http.MethodDelete, "/api/v1/customers/"+TestCustomerID, nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	var count int
+	if err := env.DB.QueryRow(`SELECT COUNT(*) FROM transactions WHERE customer_id = $1`, TestCustomerID).Scan(&count); err != nil {
+		t.Fatalf("Failed to count transactions: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected the transaction to keep its customer, got %d linked transactions", count)
+	}
+}
+
+func TestTrashList(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+
+	env.MakeRequest(t, http.MethodDelete, "/api/v1/products/"+TestProductID, nil, cookies)
+	env.MakeRequest(t, http.MethodDelete, "/api/v1/customers/"+TestCustomerID, nil, cookies)
+
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/trash", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	if items := ParseResponse(t, w)["data"].([]interface{}); len(items) != 2 {
+		t.Errorf("Expected 2 items in trash, got %d", len(items))
+	}
+
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/trash?type=customers", nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+	items := ParseResponse(t, w)["data"].([]interface{})
+	if len(items) != 1 || items[0].(map[string]interface{})["id"] != TestCustomerID {
+		t.Errorf("Expected only the deleted customer, got %v", items)
+	}
+}
+
+func TestTrashList_AsCashier_Forbidden(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsCashier(t)
+	w := env.MakeRequest(t, http.MethodGet, "/api/v1/trash", nil, cookies)
+
+	AssertStatus(t, w, http.StatusForbidden)
+}
+
+func TestProductRestore_SKUTaken(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+
+	w := env.MakeRequest(t, http.MethodDelete, "/api/v1/products/"+TestProductID, nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	// The SKU of a deleted product can be reused
+	body := map[string]interface{}{
+		"category_id": TestCategoryID,
+		"sku":         "TEST-001",
+		"name":        "Replacement Product",
+		"price":       12000,
+		"stock":       5,
+		"is_active":   true,
+	}
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/products", body, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/products/"+TestProductID+"/restore", nil, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestCustomerRestore_PhoneTaken(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+
+	w := env.MakeRequest(t, http.MethodDelete, "/api/v1/customers/"+TestCustomerID, nil, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	// The phone of a deleted customer can be reused
+	body := map[string]interface{}{
+		"name":  "Replacement Customer",
+		"phone": "081234567890",
+	}
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/customers", body, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/customers/"+TestCustomerID+"/restore", nil, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestCategoryDelete_WithProducts(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+	w := env.MakeRequest(t, http.MethodDelete, "/api/v1/categories/"+TestCategoryID, nil, cookies)
+
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestTrashPurge(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+
+	env.MakeRequest(t, http.MethodDelete, "/api/v1/products/"+TestProductID, nil, cookies)
+	env.MakeRequest(t, http.MethodDelete, "/api/v1/categories/"+TestCategoryID, nil, cookies)
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/trash/purge", map[string]interface{}{"older_than_days": 0}, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	purged := ParseResponse(t, w)["data"].(map[string]interface{})["purged"].(map[string]interface{})
+	if purged["products"].(float64) != 1 || purged["categories"].(float64) != 1 {
+		t.Errorf("Expected 1 product and 1 category purged, got %v", purged)
+	}
+
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/products/"+TestProductID+"/restore", nil, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestTrashPurge_KeepsProductsWithStockHistory(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+
+	if _, err := env.DB.Exec(`
+		INSERT INTO stock_movements (id, product_id, type, quantity, balance_after, note)
+		VALUES ($1, $2, 'adjustment', -5, 95, 'damaged')
+	`, GenerateUUID(), TestProductID); err != nil {
+		t.Fatalf("Failed to seed stock movement: %v", err)
+	}
+
+	env.MakeRequest(t, http.MethodDelete, "/api/v1/products/"+TestProductID, nil, cookies)
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/trash/purge", map[string]interface{}{"older_than_days": 0}, cookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	purged := ParseResponse(t, w)["data"].(map[string]interface{})["purged"].(map[string]interface{})
+	if purged["products"].(float64) != 0 {
+		t.Errorf("Expected the product with stock history to be kept, got %v", purged)
+	}
+
+	var count int
+	if err := env.DB.QueryRow(`SELECT COUNT(*) FROM stock_movements WHERE product_id = $1`, TestProductID).Scan(&count); err != nil {
+		t.Fatalf("Failed to count stock movements: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected the stock movement to survive the purge, got %d", count)
+	}
+}
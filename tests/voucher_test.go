@@ -0,0 +1,218 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// ============================================
+// Voucher Tests
+// ============================================
+
+// seedVoucher inserts a voucher directly and returns its ID
+func seedVoucher(t *testing.T, env *TestEnv, code string, maxUses, usesPerCustomer int, minSpend float64) string {
+	t.Helper()
+
+	id := GenerateUUID()
+	now := time.Now()
+	_, err := env.DB.Exec(`
+		INSERT INTO vouchers (id, code, batch_id, discount_type, discount_value, max_discount, min_spend,
+		                      max_uses, uses_per_customer, used_count, is_active, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, 'fixed', 5000, 0, $4, $5, $6, 0, TRUE, $7, $8, $9)
+	`, id, code, GenerateUUID(), minSpend, maxUses, usesPerCustomer, TestAdminID, now, now)
+	if err != nil {
+		t.Fatalf("Failed to seed voucher: %v", err)
+	}
+	return id
+}
+
+// seedCheckoutProduct inserts a product with a valid UUID so checkout validation passes
+func seedCheckoutProduct(t *testing.T, env *TestEnv) string {
+	t.Helper()
+
+	id := GenerateUUID()
+	now := time.Now()
+	_, err := env.DB.Exec(`
+		INSERT INTO products (id, category_id, sku, name, description, price, stock, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, 'Checkout Product', '', 20000, 50, TRUE, $4, $5)
+	`, id, TestCategoryID, "CHK-"+id[:8], now, now)
+	if err != nil {
+		t.Fatalf("Failed to seed product: %v", err)
+	}
+	return id
+}
+
+func TestVoucherGenerate_AsAdmin(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+
+	body := map[string]interface{}{
+		"prefix":         "PROMO",
+		"quantity":       5,
+		"discount_type":  "percentage",
+		"discount_value": 10,
+		"max_discount":   20000,
+		"max_uses":       1,
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/vouchers/batch", body, cookies)
+
+	AssertStatus(t, w, http.StatusCreated)
+
+	response := ParseResponse(t, w)
+	data := response["data"].(map[string]interface{})
+	vouchers := data["vouchers"].([]interface{})
+	if len(vouchers) != 5 {
+		t.Errorf("Expected 5 vouchers, got %d", len(vouchers))
+	}
+}
+
+func TestVoucherGenerate_AsCashier_Forbidden(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsCashier(t)
+
+	body := map[string]interface{}{
+		"quantity":       1,
+		"discount_type":  "fixed",
+		"discount_value": 5000,
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/vouchers/batch", body, cookies)
+
+	AssertStatus(t, w, http.StatusForbidden)
+}
+
+func TestVoucherGenerate_InvalidPercentage(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	cookies := env.LoginAsAdmin(t)
+
+	body := map[string]interface{}{
+		"quantity":       1,
+		"discount_type":  "percentage",
+		"discount_value": 150,
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/vouchers/batch", body, cookies)
+
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestVoucherValidate_MinimumSpend(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	seedVoucher(t, env, "MINSPEND", 1, 0, 50000)
+	cookies := env.LoginAsCashier(t)
+
+	body := map[string]interface{}{
+		"code":     "minspend",
+		"subtotal": 10000,
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/pos/vouchers/validate", body, cookies)
+
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestVoucherRedeem_SingleUse(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	voucherID := seedVoucher(t, env, "ONCEONLY", 1, 0, 0)
+	productID := seedCheckoutProduct(t, env)
+	cookies := env.LoginAsCashier(t)
+
+	body := map[string]interface{}{
+		"payment_method": "cash",
+		"voucher_code":   "ONCEONLY",
+		"items": []map[string]interface{}{
+			{"product_id": productID, "quantity": 1},
+		},
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	if data["discount_amount"] != float64(5000) {
+		t.Errorf("Expected discount_amount 5000, got %v", data["discount_amount"])
+	}
+
+	// Second redemption of a single-use code must fail
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	// Redemption is visible to admins
+	adminCookies := env.LoginAsAdmin(t)
+	w = env.MakeRequest(t, http.MethodGet, "/api/v1/vouchers/"+voucherID+"/redemptions", nil, adminCookies)
+	AssertStatus(t, w, http.StatusOK)
+
+	redemptions := ParseResponse(t, w)["data"].([]interface{})
+	if len(redemptions) != 1 {
+		t.Errorf("Expected 1 redemption, got %d", len(redemptions))
+	}
+}
+
+func TestVoucherRedeem_PerCustomerRequiresCustomer(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	seedVoucher(t, env, "MEMBERONLY", 0, 1, 0)
+	productID := seedCheckoutProduct(t, env)
+	cookies := env.LoginAsCashier(t)
+
+	body := map[string]interface{}{
+		"payment_method": "cash",
+		"voucher_code":   "MEMBERONLY",
+		"items": []map[string]interface{}{
+			{"product_id": productID, "quantity": 1},
+		},
+	}
+
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, cookies)
+
+	AssertStatus(t, w, http.StatusBadRequest)
+}
+
+func TestVoucherRedeem_DiscountsCappedAtAmountDue(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup()
+
+	seedVoucher(t, env, "TOPUP", 0, 0, 0)
+	productID := seedCheckoutProduct(t, env)
+	cookies := env.LoginAsAdmin(t)
+
+	// 20000 plus 10% tax leaves 22000 due
+	body := map[string]interface{}{
+		"payment_method":  "cash",
+		"discount_amount": 30000,
+		"items": []map[string]interface{}{
+			{"product_id": productID, "quantity": 1},
+		},
+	}
+	w := env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	// Nothing is left for the voucher to take off
+	body["discount_amount"] = 22000
+	body["voucher_code"] = "TOPUP"
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, cookies)
+	AssertStatus(t, w, http.StatusBadRequest)
+
+	// The 5000 voucher only takes off the 3000 still due
+	body["discount_amount"] = 19000
+	w = env.MakeRequest(t, http.MethodPost, "/api/v1/transactions", body, cookies)
+	AssertStatus(t, w, http.StatusCreated)
+
+	data := ParseResponse(t, w)["data"].(map[string]interface{})
+	if data["discount_amount"] != float64(22000) || data["total_amount"] != float64(0) {
+		t.Errorf("Expected a 22000 discount and nothing to pay, got %v and %v", data["discount_amount"], data["total_amount"])
+	}
+}